@@ -0,0 +1,237 @@
+// Package main provides the admin command, which drives the kill switch and
+// the tool switch of a running SSE/HTTP server through its admin endpoints.
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/ylchen07/mcp-executor/internal/config"
+	"github.com/ylchen07/mcp-executor/internal/server"
+)
+
+// adminRequestTimeout bounds each request to the admin endpoints.
+const adminRequestTimeout = 30 * time.Second
+
+// adminCmd groups the kill switch and tool switch subcommands
+var adminCmd = &cobra.Command{
+	Use:   "admin",
+	Short: "Stop or re-enable the executions or tools of a running server",
+	Long: `Drive the kill switch and the tool switch of a running SSE or HTTP server
+through its admin endpoints, for emergency response when an agent misbehaves on
+a shared host.
+
+The server URL and token are taken from the same --config and --profile as serve
+(the first of transport.auth_tokens or ` + server.AuthTokensEnvVar + `), unless
+--url and --token are given. A TLS certificate in the configuration is trusted.`,
+}
+
+// adminKillCmd cancels the running executions
+var adminKillCmd = &cobra.Command{
+	Use:           "kill",
+	Short:         "Kill all running executions",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		disable, _ := cmd.Flags().GetBool("disable")
+		path := "/kill"
+		if disable {
+			path += "?disable=true"
+		}
+		var status server.KillSwitchStatus
+		if err := adminRequest(cmd, http.MethodPost, path, &status); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Killed %d executions\n", status.Killed)
+		printAdminStatus(cmd, status)
+		return nil
+	},
+}
+
+// adminEnableCmd accepts executions again
+var adminEnableCmd = &cobra.Command{
+	Use:           "enable",
+	Short:         "Accept executions again after kill --disable",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var status server.KillSwitchStatus
+		if err := adminRequest(cmd, http.MethodPost, "/enable", &status); err != nil {
+			return err
+		}
+		printAdminStatus(cmd, status)
+		return nil
+	},
+}
+
+// adminStatusCmd reports the running executions
+var adminStatusCmd = &cobra.Command{
+	Use:           "status",
+	Short:         "Show the running executions and whether executions are disabled",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var status server.KillSwitchStatus
+		if err := adminRequest(cmd, http.MethodGet, "/executions", &status); err != nil {
+			return err
+		}
+		printAdminStatus(cmd, status)
+		return nil
+	},
+}
+
+// adminToolsCmd reports the registered tools
+var adminToolsCmd = &cobra.Command{
+	Use:           "tools",
+	Short:         "Show the enabled tools and those disabled at runtime",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var status server.ToolStatus
+		if err := adminRequest(cmd, http.MethodGet, "/tools", &status); err != nil {
+			return err
+		}
+		printToolStatus(cmd, status)
+		return nil
+	},
+}
+
+// adminDisableToolCmd unregisters tools until enable-tool
+var adminDisableToolCmd = &cobra.Command{
+	Use:           "disable-tool TOOL...",
+	Short:         "Disable execute tools, e.g. bash, until enable-tool",
+	Args:          cobra.MinimumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return switchTools(cmd, "/tools/disable", args)
+	},
+}
+
+// adminEnableToolCmd registers tools disabled by disable-tool again
+var adminEnableToolCmd = &cobra.Command{
+	Use:           "enable-tool TOOL...",
+	Short:         "Enable execute tools disabled by disable-tool again",
+	Args:          cobra.MinimumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return switchTools(cmd, "/tools/enable", args)
+	},
+}
+
+// switchTools posts tools to the tool switch endpoint path.
+func switchTools(cmd *cobra.Command, path string, tools []string) error {
+	query := url.Values{"tool": tools}
+	var status server.ToolStatus
+	if err := adminRequest(cmd, http.MethodPost, path+"?"+query.Encode(), &status); err != nil {
+		return err
+	}
+	if !status.Changed {
+		fmt.Fprintln(cmd.OutOrStdout(), "Tools unchanged")
+	}
+	printToolStatus(cmd, status)
+	return nil
+}
+
+// printToolStatus prints the tools reported by the server.
+func printToolStatus(cmd *cobra.Command, status server.ToolStatus) {
+	fmt.Fprintf(cmd.OutOrStdout(), "Enabled tools: %s\n", listOrNone(status.Enabled))
+	fmt.Fprintf(cmd.OutOrStdout(), "Disabled at runtime: %s\n", listOrNone(status.Disabled))
+}
+
+// listOrNone joins items, or returns "none".
+func listOrNone(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
+
+// printAdminStatus prints the state reported by the server.
+func printAdminStatus(cmd *cobra.Command, status server.KillSwitchStatus) {
+	state := "enabled"
+	if status.Disabled {
+		state = "disabled"
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Executions %s, %d running\n", state, status.Running)
+}
+
+// adminRequest sends a request to the admin endpoint path of the server and
+// decodes its answer into status.
+func adminRequest(cmd *cobra.Command, method, path string, status any) error {
+	cfg, err := config.Load(configFile, profile)
+	if err != nil {
+		return err
+	}
+	baseURL, _ := cmd.Flags().GetString("url")
+	if baseURL == "" {
+		if baseURL, err = server.AdminURL(cfg.Transport); err != nil {
+			return fmt.Errorf("%v; pass --url", err)
+		}
+	}
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		tokens := append(server.ParseAuthTokens(os.Getenv(server.AuthTokensEnvVar)), cfg.Transport.AuthTokens...)
+		if len(tokens) > 0 {
+			token = tokens[0]
+		}
+	}
+
+	client := &http.Client{Timeout: adminRequestTimeout}
+	if cfg.Transport.TLSCert != "" {
+		pem, err := os.ReadFile(cfg.Transport.TLSCert)
+		if err != nil {
+			return fmt.Errorf("failed to read TLS certificate: %v", err)
+		}
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		roots.AppendCertsFromPEM(pem)
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}
+	}
+
+	request, err := http.NewRequestWithContext(cmd.Context(), method, strings.TrimSuffix(baseURL, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to reach the server: %v", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+		return fmt.Errorf("server answered %s: %s", response.Status, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(response.Body).Decode(status); err != nil {
+		return fmt.Errorf("invalid response from the server: %v", err)
+	}
+	return nil
+}
+
+func init() {
+	adminCmd.PersistentFlags().String("url", "", "admin endpoint URL, e.g. https://executor.internal:8081/admin (default from the configuration)")
+	adminCmd.PersistentFlags().String("token", "", "auth token (default from the configuration or "+server.AuthTokensEnvVar+")")
+	adminKillCmd.Flags().Bool("disable", false, "refuse new executions until admin enable")
+	adminCmd.AddCommand(adminKillCmd)
+	adminCmd.AddCommand(adminEnableCmd)
+	adminCmd.AddCommand(adminStatusCmd)
+	adminCmd.AddCommand(adminToolsCmd)
+	adminCmd.AddCommand(adminDisableToolCmd)
+	adminCmd.AddCommand(adminEnableToolCmd)
+	rootCmd.AddCommand(adminCmd)
+}
@@ -0,0 +1,79 @@
+// Package main provides the build-images command for baking preinstalled
+// packages into the Docker images of the executions ahead of time.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/ylchen07/mcp-executor/internal/config"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+	"github.com/ylchen07/mcp-executor/internal/server"
+)
+
+// buildImagesCmd builds the derived images of the Docker executors
+var buildImagesCmd = &cobra.Command{
+	Use:   "build-images",
+	Short: "Build the Docker images with preinstalled packages",
+	Long: `Build the local Docker images derived from the configured language images: one
+per language listed in images.preinstall, with those packages installed, and
+one per environment with packages. Docker-mode executions run in them, so
+frequently used dependencies are installed once instead of on every call.
+
+Images are tagged after their base image and packages; those already built are
+kept, and changing the configuration builds new ones. Without this command the
+first execution needing an image builds it.
+
+Examples:
+  mcp-executor build-images -c mcp-executor.yaml
+  mcp-executor build-images -c mcp-executor.yaml --lang python`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(configFile, profile)
+		if err != nil {
+			return err
+		}
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("invalid configuration: %v", err)
+		}
+		logger.SetVerbose(verbose || cfg.Logging.Verbose)
+
+		langs, _ := cmd.Flags().GetStringSlice("lang")
+		languages, err := server.ParseToolList(langs)
+		notLanguage := func(selector string) bool { return !slices.Contains(server.Languages, selector) }
+		if err != nil || slices.ContainsFunc(languages, notLanguage) {
+			return fmt.Errorf("--lang: expected languages among %s", strings.Join(server.Languages, ", "))
+		}
+
+		serverOpts, err := serverOptions(cfg)
+		if err != nil {
+			return fmt.Errorf("invalid configuration: %v", err)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		images, err := server.BuildImages(ctx, languages, serverOpts...)
+		for _, image := range images {
+			state := "up to date"
+			if image.Built {
+				state = "built"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%-10s %s (%s)\n", state, image.Tag, image.Subject)
+		}
+		if err == nil && len(images) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No images to build: list packages in images.preinstall or in environments")
+		}
+		return err
+	},
+}
+
+func init() {
+	buildImagesCmd.Flags().StringSliceP("lang", "l", nil, "Languages whose images to build (default all)")
+
+	rootCmd.AddCommand(buildImagesCmd)
+}
@@ -0,0 +1,49 @@
+// Package main registers shell completions for the flags that take a fixed set
+// of values. Scripts are generated with "mcp-executor completion bash|zsh|fish|powershell".
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/ylchen07/mcp-executor/internal/config"
+	"github.com/ylchen07/mcp-executor/internal/server"
+)
+
+// registerCompletions adds value completions to the enumerated flags of every command.
+func registerCompletions() {
+	fixed := func(values ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return cobra.FixedCompletions(values, cobra.ShellCompDirectiveNoFileComp)
+	}
+	flagValues := map[string]func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective){
+		"mode":           fixed("stdio", "sse", "http"),
+		"execution-mode": fixed("subprocess", "docker", "hybrid", "nix"),
+		"tools":          fixed(server.ToolSelectors...),
+		"readonly-tools": fixed(server.Languages...),
+		"lang":           fixed(server.Languages...),
+		"network":        fixed("bridge", "none", "host"),
+	}
+
+	_ = rootCmd.MarkPersistentFlagFilename("config", "yaml", "yml", "toml")
+	_ = rootCmd.RegisterFlagCompletionFunc("profile", completeProfiles)
+
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		for name, complete := range flagValues {
+			if cmd.LocalNonPersistentFlags().Lookup(name) != nil {
+				_ = cmd.RegisterFlagCompletionFunc(name, complete)
+			}
+		}
+		for _, child := range cmd.Commands() {
+			walk(child)
+		}
+	}
+	walk(rootCmd)
+}
+
+// completeProfiles completes the profiles defined in the file given with --config.
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names, err := config.ProfileNames(configFile)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,97 @@
+// Package main provides the config command for generating and validating
+// mcp-executor configuration files.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/ylchen07/mcp-executor/internal/config"
+	"github.com/ylchen07/mcp-executor/internal/prompts"
+	"github.com/ylchen07/mcp-executor/internal/server"
+)
+
+// configCmd groups the configuration file subcommands
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Generate and validate configuration files",
+}
+
+// configInitCmd writes a commented default configuration
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write a commented default configuration file",
+	Long: `Write a commented YAML configuration file holding the defaults.
+
+The file is printed to stdout unless --output is given.`,
+	// Errors are reported once by Execute, without the usage text
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if output == "" {
+			_, err := fmt.Fprint(cmd.OutOrStdout(), config.Template())
+			return err
+		}
+
+		if _, err := os.Stat(output); err == nil && !force {
+			return fmt.Errorf("%s already exists (use --force to overwrite)", output)
+		}
+		if err := os.WriteFile(output, []byte(config.Template()), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %v", output, err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s\n", output)
+		return nil
+	},
+}
+
+// configValidateCmd checks a configuration file without starting the server
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check a configuration file before starting the server",
+	Long: `Check the file given with --config (and the profile given with --profile) for
+syntax errors, unknown keys, invalid image names, port collisions and unsafe
+policies. Problems that do not prevent startup are reported as warnings.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if configFile == "" {
+			return fmt.Errorf("no configuration file given (use --config)")
+		}
+
+		cfg, err := config.Load(configFile, profile)
+		if err != nil {
+			return err
+		}
+		if _, err := server.ParseToolList(cfg.Execution.Tools); err != nil {
+			return fmt.Errorf("execution.tools: %v", err)
+		}
+		if _, err := server.ParseToolList(cfg.Execution.ReadOnlyTools); err != nil {
+			return fmt.Errorf("execution.readonly_tools: %v", err)
+		}
+		if _, err := cfg.Execution.DefaultEnv(); err != nil {
+			return fmt.Errorf("execution.env_files: %v", err)
+		}
+		if err := prompts.CheckNames(cfg.Prompts.Disabled); err != nil {
+			return fmt.Errorf("prompts.disabled: %v", err)
+		}
+
+		for _, warning := range cfg.Warnings() {
+			fmt.Fprintf(cmd.OutOrStdout(), "warning: %s\n", warning)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s is valid\n", configFile)
+		return nil
+	},
+}
+
+func init() {
+	configInitCmd.Flags().StringP("output", "o", "", "File to write instead of stdout")
+	configInitCmd.Flags().Bool("force", false, "Overwrite an existing file")
+
+	configCmd.AddCommand(configInitCmd)
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
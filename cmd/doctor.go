@@ -0,0 +1,288 @@
+// Package main provides the doctor command, which diagnoses the environment
+// problems behind most first-run failures.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/ylchen07/mcp-executor/internal/config"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/server"
+)
+
+// dockerCheckTimeout bounds each docker CLI call made by doctor.
+const dockerCheckTimeout = 10 * time.Second
+
+// checkResult is the outcome of a single doctor check.
+type checkResult struct {
+	name    string
+	problem string // Empty when the check passed
+	fix     string // Suggested fix for a problem
+	warning bool   // The problem does not prevent the server from working
+	detail  string // Extra information for passed checks
+}
+
+// doctorCmd checks the environment the server would run in
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the environment for common setup problems",
+	Long: `Check the configuration, the Docker daemon and images (docker mode), the host
+runtimes (subprocess mode) and the listen ports (sse/http mode), and print a fix
+for every problem found. The command exits with status 1 when a check fails.
+
+Use the same --config, --profile and mode flags as serve.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, results := checkConfig(cmd)
+
+		languages, err := server.ParseToolList(cfg.Execution.Tools)
+		if err != nil || len(languages) == 0 {
+			languages = server.Languages
+		}
+		switch cfg.Execution.Mode {
+		case "docker", "hybrid":
+			results = append(results, checkDockerHost(executor.DetectDockerHost(cmd.Context()), cfg.Policy.AllowedMounts)...)
+			results = append(results, checkDocker(cmd.Context(), cfg.Images, languages)...)
+		case "nix":
+			results = append(results, checkNix())
+		default:
+			results = append(results, checkRuntimes(languages, cfg.Execution.Binaries)...)
+		}
+		switch cfg.Transport.Mode {
+		case "sse":
+			results = append(results, checkPort("SSE", cfg.Transport.SSEAddr))
+		case "http":
+			results = append(results, checkPort("HTTP", cfg.Transport.HTTPAddr))
+		}
+
+		if failed := printResults(cmd.OutOrStdout(), results); failed > 0 {
+			return fmt.Errorf("%d check(s) failed", failed)
+		}
+		return nil
+	},
+}
+
+// checkConfig loads the configuration, applies the mode flags and validates the
+// result. On failure the defaults are returned so the remaining checks still run.
+func checkConfig(cmd *cobra.Command) (config.Config, []checkResult) {
+	name := "configuration"
+	if configFile != "" {
+		name = "configuration " + configFile
+	}
+	cfg, err := config.Load(configFile, profile)
+	if err != nil {
+		cfg = config.Default()
+	}
+	if cmd.Flags().Changed("mode") {
+		cfg.Transport.Mode, _ = cmd.Flags().GetString("mode")
+	}
+	if cmd.Flags().Changed("execution-mode") {
+		cfg.Execution.Mode, _ = cmd.Flags().GetString("execution-mode")
+	}
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		return cfg, []checkResult{{name: name, problem: err.Error(), fix: "run 'mcp-executor config validate' and correct the reported setting"}}
+	}
+	if _, err := server.ParseToolList(cfg.Execution.Tools); err != nil {
+		return cfg, []checkResult{{name: name, problem: "execution.tools: " + err.Error(), fix: "list only python, bash, typescript or go"}}
+	}
+	if _, err := cfg.Execution.DefaultEnv(); err != nil {
+		return cfg, []checkResult{{name: name, problem: "execution.env_files: " + err.Error(), fix: "create the file or remove it from env_files"}}
+	}
+
+	results := []checkResult{{name: name, detail: cfg.Transport.Mode + " transport, " + cfg.Execution.Mode + " execution"}}
+	for _, warning := range cfg.Warnings() {
+		results = append(results, checkResult{name: "configuration", problem: warning, warning: true})
+	}
+	return cfg, results
+}
+
+// checkDockerHost checks that a server running in a container can reach a
+// Docker daemon and that the allowed mount roots exist on the daemon host.
+func checkDockerHost(host executor.DockerHost, allowedMounts []string) []checkResult {
+	if !host.InContainer {
+		return nil
+	}
+	result := checkResult{name: "containerized server"}
+	switch {
+	case host.Socket != "":
+		result.detail = "Docker daemon socket " + host.Socket
+	case host.Remote != "":
+		result.detail = "Docker daemon at " + host.Remote
+	default:
+		result.problem = "running in a container without a Docker daemon socket"
+		result.fix = "mount the host socket with -v " + executor.DefaultDockerSocket + ":" + executor.DefaultDockerSocket + ", set DOCKER_HOST, or use --execution-mode subprocess"
+		return []checkResult{result}
+	}
+	results := []checkResult{result}
+
+	if len(allowedMounts) > 0 && host.VolumesErr != nil {
+		results = append(results, checkResult{
+			name:    "mount paths",
+			problem: "mount sources are passed to the Docker daemon unchanged: " + host.VolumesErr.Error(),
+			fix:     "make policy.allowed_mounts available to the daemon at the same paths",
+			warning: true,
+		})
+	}
+	for _, root := range allowedMounts {
+		if _, err := host.HostPath(root); err != nil {
+			results = append(results, checkResult{
+				name:    "allowed mount " + root,
+				problem: err.Error(),
+				fix:     "start the server container with -v " + root + ":" + root,
+			})
+		}
+	}
+	return results
+}
+
+// checkDocker checks that the daemon is reachable and the images of the enabled tools are present.
+func checkDocker(ctx context.Context, images config.ImageConfig, languages []string) []checkResult {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return []checkResult{{name: "docker", problem: "docker CLI not found in PATH", fix: "install Docker (https://docs.docker.com/get-docker/) or use --execution-mode subprocess"}}
+	}
+	out, err := dockerOutput(ctx, "info", "--format", "{{.ServerVersion}}")
+	if err != nil {
+		return []checkResult{{name: "docker daemon", problem: "not reachable: " + err.Error(), fix: "start the Docker daemon and make sure your user may access it (e.g. add it to the docker group)"}}
+	}
+	results := []checkResult{{name: "docker daemon", detail: "version " + out}}
+
+	byLanguage := map[string]string{
+		"python":     images.Python,
+		"bash":       images.Bash,
+		"typescript": images.TypeScript,
+		"go":         images.Go,
+	}
+	for _, language := range languages {
+		results = append(results, checkImage(ctx, byLanguage[language], "the first "+language+" execution"))
+		if browser := images.Browser[language]; browser != "" {
+			results = append(results, checkImage(ctx, browser, "the first "+language+" execution using a browser"))
+		}
+	}
+	return results
+}
+
+// checkImage checks that image is present, which first downloads it otherwise.
+func checkImage(ctx context.Context, image, first string) checkResult {
+	result := checkResult{name: "image " + image}
+	if _, err := dockerOutput(ctx, "image", "inspect", "--format", "{{.Id}}", image); err != nil {
+		result.problem = "not pulled yet, " + first + " will download it"
+		result.fix = "docker pull " + image
+		result.warning = true
+	}
+	return result
+}
+
+func dockerOutput(ctx context.Context, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, dockerCheckTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "docker", args...).CombinedOutput()
+	if err != nil {
+		if message := strings.TrimSpace(string(out)); message != "" {
+			return "", fmt.Errorf("%s", firstLine(message))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// checkNix checks that nix-shell, which provides the runtimes in nix mode, is installed.
+func checkNix() checkResult {
+	result := checkResult{name: "nix-shell"}
+	if path, err := exec.LookPath("nix-shell"); err == nil {
+		result.detail = path
+	} else {
+		result.problem = "nix-shell not found in PATH"
+		result.fix = "install Nix (https://nixos.org/download) or use --execution-mode subprocess"
+	}
+	return result
+}
+
+// checkRuntimes checks the host interpreters used by the enabled subprocess
+// tools, as configured in binaries or discovered in PATH.
+func checkRuntimes(languages []string, binaries map[string]string) []checkResult {
+	var results []checkResult
+	runtimes := []struct {
+		language string
+		fix      string
+	}{
+		{"python", "install Python 3 so that python3 is in PATH"},
+		{"bash", "install bash"},
+		{"typescript", "install Node.js and tsx (npm install -g tsx)"},
+		{"go", "install Go (https://go.dev/dl/)"},
+	}
+	for _, runtime := range runtimes {
+		if !slices.Contains(languages, runtime.language) {
+			continue
+		}
+		result := checkResult{name: runtime.language + " runtime"}
+		if path, err := executor.FindBinary(runtime.language, binaries[runtime.language]); err == nil {
+			result.detail = path
+		} else {
+			result.problem = err.Error()
+			result.fix = runtime.fix + ", set execution.binaries." + runtime.language + ", or disable the tool with --tools"
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// checkPort checks that addr is free to listen on.
+func checkPort(transport, addr string) checkResult {
+	result := checkResult{name: transport + " address " + addr}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		result.problem = "cannot listen: " + err.Error()
+		result.fix = "stop the process using the port or choose another address in the configuration"
+		return result
+	}
+	listener.Close()
+	return result
+}
+
+// printResults writes one line per check and returns the number of failures.
+func printResults(w io.Writer, results []checkResult) int {
+	failed := 0
+	for _, result := range results {
+		switch {
+		case result.problem == "":
+			line := "[ok]   " + result.name
+			if result.detail != "" {
+				line += " (" + result.detail + ")"
+			}
+			fmt.Fprintln(w, line)
+		case result.warning:
+			fmt.Fprintf(w, "[warn] %s: %s\n", result.name, result.problem)
+		default:
+			failed++
+			fmt.Fprintf(w, "[fail] %s: %s\n", result.name, result.problem)
+		}
+		if result.problem != "" && result.fix != "" {
+			fmt.Fprintf(w, "       fix: %s\n", result.fix)
+		}
+	}
+	return failed
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+func init() {
+	doctorCmd.Flags().StringP("mode", "m", "", "Transport mode to check: stdio, sse, or http (default from the configuration)")
+	doctorCmd.Flags().StringP("execution-mode", "e", "", "Execution mode to check: subprocess, docker, hybrid or nix (default from the configuration)")
+
+	rootCmd.AddCommand(doctorCmd)
+}
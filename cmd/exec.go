@@ -0,0 +1,148 @@
+// Package main provides the exec command for running code once through the
+// configured executors without an MCP client.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/ylchen07/mcp-executor/internal/config"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+	"github.com/ylchen07/mcp-executor/internal/server"
+)
+
+// execCmd runs a single program through the same executor stack as the server
+var execCmd = &cobra.Command{
+	Use:   "exec",
+	Short: "Run code once through the configured executors",
+	Long: `Run a program through the same executors, images, limits, timeouts and default
+environment as the MCP server, without an MCP client. Use it to test images,
+policies and limits from the shell.
+
+The code is read from --file, or from stdin when no file (or "-") is given.
+
+Examples:
+  mcp-executor exec --lang python --file script.py --packages requests
+  echo 'uname -a' | mcp-executor exec --lang bash -e docker --network none`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(configFile, profile)
+		if err != nil {
+			return err
+		}
+		if cmd.Flags().Changed("execution-mode") {
+			cfg.Execution.Mode, _ = cmd.Flags().GetString("execution-mode")
+		}
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("invalid configuration: %v", err)
+		}
+		logger.SetVerbose(verbose || cfg.Logging.Verbose)
+
+		lang, _ := cmd.Flags().GetString("lang")
+		languages, err := server.ParseToolList([]string{lang})
+		if err != nil || len(languages) != 1 {
+			return fmt.Errorf("--lang: expected one of %s", strings.Join(server.Languages, ", "))
+		}
+
+		file, _ := cmd.Flags().GetString("file")
+		code, err := readCode(cmd.InOrStdin(), file)
+		if err != nil {
+			return err
+		}
+
+		packages, _ := cmd.Flags().GetStringSlice("packages")
+		envPairs, _ := cmd.Flags().GetStringSlice("env")
+		envVars, err := parseEnvPairs(envPairs)
+		if err != nil {
+			return err
+		}
+		var mounts []executor.Mount
+		mountSpecs, _ := cmd.Flags().GetStringSlice("mount")
+		for _, spec := range mountSpecs {
+			mount, err := executor.ParseMount(spec)
+			if err != nil {
+				return err
+			}
+			mounts = append(mounts, mount)
+		}
+		network, _ := cmd.Flags().GetString("network")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		runtimeVersion, _ := cmd.Flags().GetString("runtime-version")
+		dependencyFile := ""
+		if path, _ := cmd.Flags().GetString("dependency-file"); path != "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			dependencyFile = string(data)
+		}
+
+		serverOpts, err := serverOptions(cfg)
+		if err != nil {
+			return fmt.Errorf("invalid configuration: %v", err)
+		}
+		exec, err := server.NewExecutor(cfg.Execution.Mode, languages[0], serverOpts...)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		output, err := exec.Execute(ctx, code, packages, envVars,
+			executor.WithMounts(mounts), executor.WithNetwork(network), executor.WithTimeout(timeout),
+			executor.WithRuntimeVersion(runtimeVersion), executor.WithDependencyFile(dependencyFile))
+		fmt.Fprint(cmd.OutOrStdout(), output)
+		return err
+	},
+}
+
+// readCode reads the program from file, or from stdin when file is empty or "-".
+func readCode(stdin io.Reader, file string) (string, error) {
+	var data []byte
+	var err error
+	if file == "" || file == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read code: %v", err)
+	}
+	return string(data), nil
+}
+
+// parseEnvPairs converts KEY=VALUE entries into an environment map.
+func parseEnvPairs(pairs []string) (map[string]string, error) {
+	env := make(map[string]string)
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("--env: expected KEY=VALUE, got %q", pair)
+		}
+		env[strings.TrimSpace(key)] = value
+	}
+	return env, nil
+}
+
+func init() {
+	execCmd.Flags().StringP("lang", "l", "", "Language to run: python, bash, typescript or go")
+	execCmd.Flags().StringP("file", "f", "", "File holding the code (default stdin)")
+	execCmd.Flags().StringSlice("packages", nil, "Comma-separated dependencies to install first (Docker mode)")
+	execCmd.Flags().StringSlice("env", nil, "KEY=VALUE environment variable for the execution (repeatable)")
+	execCmd.Flags().StringSlice("mount", nil, "host_path:container_path[:ro|rw] mount (Docker mode, repeatable)")
+	execCmd.Flags().String("network", "", "Container network: bridge, none or host (Docker mode)")
+	execCmd.Flags().Duration("timeout", 0, "Execution timeout, e.g. 30s (default from the configuration)")
+	execCmd.Flags().String("dependency-file", "", "requirements.txt, package.json or go.mod whose dependencies are installed first")
+	execCmd.Flags().String("runtime-version", "", "Language version, e.g. 3.12 (configured image in Docker mode, host toolchain otherwise)")
+	execCmd.Flags().StringP("execution-mode", "e", "subprocess", "Execution mode: subprocess, docker, hybrid or nix")
+	_ = execCmd.MarkFlagRequired("lang")
+
+	rootCmd.AddCommand(execCmd)
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+// Package main leaves servers on systems without SIGUSR1 with the admin
+// endpoints as their only kill switch.
+package main
+
+import "github.com/ylchen07/mcp-executor/internal/server"
+
+// handleKillSignals does nothing: only Unix systems have SIGUSR1.
+func handleKillSignals(killSwitch *server.KillSwitch) {}
@@ -0,0 +1,24 @@
+//go:build unix
+
+// Package main provides the SIGUSR1 kill switch of servers on Unix systems.
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ylchen07/mcp-executor/internal/logger"
+	"github.com/ylchen07/mcp-executor/internal/server"
+)
+
+// handleKillSignals kills the running executions whenever the process receives
+// SIGUSR1, the kill switch of servers without admin endpoints.
+func handleKillSignals(killSwitch *server.KillSwitch) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	for range signals {
+		logger.Info("Received SIGUSR1, killing running executions")
+		killSwitch.Kill(false)
+	}
+}
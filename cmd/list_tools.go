@@ -0,0 +1,132 @@
+// Package main provides the list-tools command, which prints what the server
+// would expose to MCP clients.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/spf13/cobra"
+	"github.com/ylchen07/mcp-executor/internal/config"
+	"github.com/ylchen07/mcp-executor/internal/server"
+)
+
+// listToolsCmd prints the tools, prompts and resources of the configured server
+var listToolsCmd = &cobra.Command{
+	Use:   "list-tools",
+	Short: "Print the tools, prompts and resources the server would register",
+	Long: `Print the tools (with their parameters), prompts and resources the server would
+register for the configuration given with --config/--profile and the execution
+mode, exactly as clients receive them from the MCP list methods.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(configFile, profile)
+		if err != nil {
+			return err
+		}
+		if cmd.Flags().Changed("execution-mode") {
+			cfg.Execution.Mode, _ = cmd.Flags().GetString("execution-mode")
+		}
+		if cmd.Flags().Changed("tools") {
+			cfg.Execution.Tools, _ = cmd.Flags().GetStringSlice("tools")
+		}
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("invalid configuration: %v", err)
+		}
+		serverOpts, err := serverOptions(cfg)
+		if err != nil {
+			return fmt.Errorf("invalid configuration: %v", err)
+		}
+
+		catalog, err := server.ListCatalog(cmd.Context(), server.NewMCPServer(cfg.Execution.Mode, serverOpts...))
+		if err != nil {
+			return err
+		}
+
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(catalog)
+		}
+		printCatalog(cmd.OutOrStdout(), catalog)
+		return nil
+	},
+}
+
+// printCatalog writes a human-readable summary of catalog.
+func printCatalog(w io.Writer, catalog server.Catalog) {
+	fmt.Fprintf(w, "Tools (%d):\n", len(catalog.Tools))
+	for _, tool := range catalog.Tools {
+		fmt.Fprintf(w, "\n  %s\n", tool.Name)
+		printIndented(w, "    ", tool.Description)
+		printParameters(w, tool.InputSchema)
+	}
+
+	fmt.Fprintf(w, "\nPrompts (%d):\n", len(catalog.Prompts))
+	for _, prompt := range catalog.Prompts {
+		fmt.Fprintf(w, "\n  %s\n", prompt.Name)
+		printIndented(w, "    ", prompt.Description)
+		for _, argument := range prompt.Arguments {
+			fmt.Fprintf(w, "    - %s%s: %s\n", argument.Name, requiredMark(argument.Required), argument.Description)
+		}
+	}
+
+	fmt.Fprintf(w, "\nResources (%d):\n", len(catalog.Resources)+len(catalog.ResourceTemplates))
+	for _, resource := range catalog.Resources {
+		fmt.Fprintf(w, "\n  %s (%s)\n", resource.URI, resource.Name)
+		printIndented(w, "    ", resource.Description)
+	}
+	for _, template := range catalog.ResourceTemplates {
+		uri := ""
+		if template.URITemplate != nil {
+			uri = template.URITemplate.Raw()
+		}
+		fmt.Fprintf(w, "\n  %s (%s)\n", uri, template.Name)
+		printIndented(w, "    ", template.Description)
+	}
+}
+
+// printParameters lists the properties of a tool's input schema, sorted by name.
+func printParameters(w io.Writer, schema mcp.ToolInputSchema) {
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "    Parameters:")
+	for _, name := range names {
+		property, _ := schema.Properties[name].(map[string]any)
+		kind, _ := property["type"].(string)
+		description, _ := property["description"].(string)
+		description = strings.Join(strings.Fields(description), " ")
+		fmt.Fprintf(w, "    - %s (%s)%s: %s\n", name, kind, requiredMark(slices.Contains(schema.Required, name)), description)
+	}
+}
+
+func printIndented(w io.Writer, indent, text string) {
+	for line := range strings.SplitSeq(strings.TrimSpace(text), "\n") {
+		fmt.Fprintln(w, indent+line)
+	}
+}
+
+func requiredMark(required bool) string {
+	if required {
+		return ", required"
+	}
+	return ""
+}
+
+func init() {
+	listToolsCmd.Flags().StringP("execution-mode", "e", "subprocess", "Execution mode: subprocess, docker, hybrid or nix")
+	listToolsCmd.Flags().StringSlice("tools", nil, "Comma-separated execute tools to expose: python, bash, typescript, go (default all)")
+	listToolsCmd.Flags().Bool("json", false, "Print the catalog as JSON")
+
+	rootCmd.AddCommand(listToolsCmd)
+}
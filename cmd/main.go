@@ -1,6 +1,7 @@
 // Package main provides the entry point for the mcp-executor application,
-// an MCP (Model Context Protocol) server that executes Python and Bash code
-// in isolated Docker containers.
+// an MCP (Model Context Protocol) server that executes Python, Bash, TypeScript
+// and Go code on the host or in isolated Docker containers. All commands live
+// in this package as a single cobra command tree rooted at rootCmd.
 package main
 
 func main() {
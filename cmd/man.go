@@ -0,0 +1,46 @@
+// Package main provides the man command for generating the mcp-executor man pages.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+// manCmd writes one man page per command
+var manCmd = &cobra.Command{
+	Use:   "man",
+	Short: "Generate man pages",
+	Long: `Generate a section 1 man page for mcp-executor and each of its subcommands.
+
+Install them with, for example:
+  mcp-executor man --dir /usr/local/share/man/man1`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := cmd.Flags().GetString("dir")
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create %s: %v", dir, err)
+		}
+		header := &doc.GenManHeader{
+			Title:   "MCP-EXECUTOR",
+			Section: "1",
+			Source:  "mcp-executor " + version,
+		}
+		if err := doc.GenManTree(rootCmd, header, dir); err != nil {
+			return fmt.Errorf("failed to generate man pages: %v", err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Wrote man pages to %s\n", dir)
+		return nil
+	},
+}
+
+func init() {
+	manCmd.Flags().String("dir", "man", "Directory to write the man pages to")
+	_ = manCmd.MarkFlagDirname("dir")
+
+	rootCmd.AddCommand(manCmd)
+}
@@ -11,8 +11,11 @@ import (
 
 var (
 	// Global flags
-	verbose bool
-	version = "dev" // Will be set during build
+	verbose    bool
+	configFile string
+	profile    string
+	offline    bool
+	version    = "dev" // Will be set during build
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -29,6 +32,9 @@ It supports multiple transport modes: stdio (default), SSE, and HTTP.`,
 func init() {
 	// Global flags
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "configuration file (.yaml, .yml or .toml)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "named profile from the configuration file (e.g. dev, prod)")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "refuse dependency installation and run Docker executions without a network")
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -37,6 +43,7 @@ func Execute() {
 	if len(os.Args) == 1 {
 		os.Args = append(os.Args, "serve")
 	}
+	registerCompletions()
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
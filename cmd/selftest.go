@@ -0,0 +1,117 @@
+// Package main provides the selftest command, which verifies a deployment by
+// running a trivial program through every registered execute tool.
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/spf13/cobra"
+	"github.com/ylchen07/mcp-executor/internal/config"
+	"github.com/ylchen07/mcp-executor/internal/server"
+)
+
+// selftestMarker is printed by every selftest program.
+const selftestMarker = "mcp-executor selftest ok"
+
+// selftestPrograms holds the argument name and a trivial program for each language.
+var selftestPrograms = map[string]struct {
+	argument string
+	code     string
+}{
+	"python":     {"code", `print("` + selftestMarker + `")`},
+	"bash":       {"script", `echo "` + selftestMarker + `"`},
+	"typescript": {"code", `console.log("` + selftestMarker + `");`},
+	"go":         {"code", "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"" + selftestMarker + "\")\n}\n"},
+}
+
+// selftestCmd runs a smoke test through every registered tool
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Run a trivial program through every registered tool",
+	Long: `Run a trivial program through every execute tool the server would register for
+the configuration and execution mode, going through the same tool handlers,
+middleware and executors as client calls. One line is printed per language and
+the command exits with status 1 when any of them fails.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(configFile, profile)
+		if err != nil {
+			return err
+		}
+		if cmd.Flags().Changed("execution-mode") {
+			cfg.Execution.Mode, _ = cmd.Flags().GetString("execution-mode")
+		}
+		if cmd.Flags().Changed("tools") {
+			cfg.Execution.Tools, _ = cmd.Flags().GetStringSlice("tools")
+		}
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("invalid configuration: %v", err)
+		}
+		serverOpts, err := serverOptions(cfg)
+		if err != nil {
+			return fmt.Errorf("invalid configuration: %v", err)
+		}
+		mcpServer := server.NewMCPServer(cfg.Execution.Mode, serverOpts...)
+
+		catalog, err := server.ListCatalog(cmd.Context(), mcpServer)
+		if err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, tool := range catalog.Tools {
+			language := strings.TrimPrefix(tool.Name, "execute-")
+			program, ok := selftestPrograms[language]
+			if !ok {
+				continue
+			}
+
+			start := time.Now()
+			result, err := server.CallTool(cmd.Context(), mcpServer, tool.Name, map[string]any{program.argument: program.code})
+			elapsed := time.Since(start).Round(time.Millisecond)
+			if problem := selftestProblem(result, err); problem != "" {
+				failed++
+				fmt.Fprintf(cmd.OutOrStdout(), "[fail] %-10s %s: %s\n", language, elapsed, problem)
+				continue
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "[ok]   %-10s %s\n", language, elapsed)
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d tool(s) failed (run 'mcp-executor doctor' for hints)", failed, len(catalog.Tools))
+		}
+		return nil
+	},
+}
+
+// selftestProblem describes why a selftest call failed, or returns "" on success.
+func selftestProblem(result *mcp.CallToolResult, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	var output strings.Builder
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			output.WriteString(text.Text)
+		}
+	}
+	text := strings.TrimSpace(output.String())
+	switch {
+	case result.IsError:
+		return firstLine(text)
+	case !strings.Contains(text, selftestMarker):
+		return fmt.Sprintf("unexpected output %q", firstLine(text))
+	}
+	return ""
+}
+
+func init() {
+	selftestCmd.Flags().StringP("execution-mode", "e", "subprocess", "Execution mode: subprocess, docker, hybrid or nix")
+	selftestCmd.Flags().StringSlice("tools", nil, "Comma-separated execute tools to test: python, bash, typescript, go (default all)")
+
+	rootCmd.AddCommand(selftestCmd)
+}
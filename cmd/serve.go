@@ -5,9 +5,16 @@ package main
 import (
 	"fmt"
 	"os"
+	"os/signal"
+	"slices"
+	"syscall"
 
 	"github.com/spf13/cobra"
+	"github.com/ylchen07/mcp-executor/internal/config"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/history"
 	"github.com/ylchen07/mcp-executor/internal/logger"
+	"github.com/ylchen07/mcp-executor/internal/prompts"
 	"github.com/ylchen07/mcp-executor/internal/server"
 )
 
@@ -27,22 +34,64 @@ Execution modes:
 - subprocess: Run code directly on host (default, faster, less isolated)
 - docker: Run code in Docker containers (slower, fully isolated)`,
 	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.Load(configFile, profile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		applyServeFlags(cmd, &cfg)
+		if err := cfg.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+			os.Exit(1)
+		}
+
 		// Set global verbose flag
-		logger.SetVerbose(verbose)
+		logger.SetVerbose(verbose || cfg.Logging.Verbose)
+		if cfg.Logging.File != "" {
+			logFile, err := openLogFile(cfg.Logging)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			defer logFile.Close()
+			logger.SetOutput(logFile)
+		}
 
-		executionMode, _ := cmd.Flags().GetString("execution-mode")
-		mcpServer := server.NewMCPServer(executionMode)
+		serverOpts, err := serverOptions(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+			os.Exit(1)
+		}
+		killSwitch := server.NewKillSwitch()
+		sessionOwners := server.NewSessionOwners()
+		mcpServer, reloader := server.NewReloadableMCPServer(cfg.Execution.Mode, append(serverOpts, server.WithKillSwitch(killSwitch), server.WithSessionOwners(sessionOwners))...)
+		reload := func() error {
+			return reloadConfig(cmd, reloader)
+		}
+		go handleReloadSignals(reload)
+		go handleKillSignals(killSwitch)
 
-		var err error
-		mode, _ := cmd.Flags().GetString("mode")
+		authTokens := append(cfg.Transport.AuthTokens, server.ParseAuthTokens(os.Getenv(server.AuthTokensEnvVar))...)
+		transportOpts := []server.TransportOption{
+			server.WithAuthTokens(authTokens),
+			server.WithTLS(cfg.Transport.TLSCert, cfg.Transport.TLSKey),
+			server.WithTLSClientCA(cfg.Transport.TLSClientCA),
+			server.WithCORSOrigins(cfg.Transport.CORSOrigins),
+			server.WithBasePath(cfg.Transport.BasePath),
+			server.WithReloadEndpoint(reload),
+			server.WithKillSwitchEndpoints(killSwitch),
+			server.WithToolEndpoints(reloader),
+			server.WithSessionIsolation(sessionOwners),
+			server.WithDebugAddress(cfg.Transport.DebugAddr),
+		}
 
-		switch mode {
+		switch cfg.Transport.Mode {
 		case "http":
-			logger.VerbosePrint("Starting MCP server in HTTP mode on port 8081")
-			err = server.RunHTTP(mcpServer)
+			logger.VerbosePrint("Starting MCP server in HTTP mode on %s", cfg.Transport.HTTPAddr)
+			err = server.RunHTTP(mcpServer, append(transportOpts, server.WithListenAddress(cfg.Transport.HTTPAddr))...)
 		case "sse":
-			logger.VerbosePrint("Starting MCP server in SSE mode on port 8080")
-			err = server.RunSSE(mcpServer)
+			logger.VerbosePrint("Starting MCP server in SSE mode on %s", cfg.Transport.SSEAddr)
+			err = server.RunSSE(mcpServer, append(transportOpts, server.WithListenAddress(cfg.Transport.SSEAddr))...)
 		default:
 			logger.VerbosePrint("Starting MCP server in stdio mode")
 			err = server.RunStdio(mcpServer)
@@ -55,10 +104,202 @@ Execution modes:
 	},
 }
 
+// openLogFile opens the rotating log file described by cfg.
+func openLogFile(cfg config.LoggingConfig) (*logger.RotatingFile, error) {
+	return logger.OpenRotatingFile(cfg.File, logger.Rotation{
+		MaxSize:    int64(cfg.MaxSizeMB) << 20,
+		Interval:   cfg.RotateInterval,
+		MaxBackups: cfg.MaxBackups,
+	})
+}
+
+// serverOptions converts the reloadable parts of cfg into MCP server options.
+func serverOptions(cfg config.Config) ([]server.Option, error) {
+	enabledTools, err := server.ParseToolList(cfg.Execution.Tools)
+	if err != nil {
+		return nil, fmt.Errorf("tools: %v", err)
+	}
+	readOnlyTools, err := server.ParseToolList(cfg.Execution.ReadOnlyTools)
+	if err != nil {
+		return nil, fmt.Errorf("readonly_tools: %v", err)
+	}
+	defaultEnv, err := cfg.Execution.DefaultEnv()
+	if err != nil {
+		return nil, fmt.Errorf("env: %v", err)
+	}
+	if err := prompts.CheckNames(cfg.Prompts.Disabled); err != nil {
+		return nil, fmt.Errorf("prompts.disabled: %v", err)
+	}
+	var runAs *executor.RunAs
+	modes := append([]string{cfg.Execution.Mode}, cfg.Execution.SessionModes...)
+	onHost := slices.ContainsFunc(modes, func(mode string) bool { return !executor.ContainerMode(mode) })
+	if cfg.Execution.RunAs != "" && onHost {
+		if runAs, err = executor.LookupRunAs(cfg.Execution.RunAs); err != nil {
+			return nil, fmt.Errorf("run_as: %v", err)
+		}
+	}
+	return []server.Option{
+		server.WithAllowedMountRoots(cfg.Policy.AllowedMounts),
+		server.WithUnconfirmedPrivileges(cfg.Policy.AllowUnconfirmedPrivileges),
+		server.WithEnabledTools(enabledTools),
+		server.WithSessionModes(cfg.Execution.SessionModes),
+		server.WithReadOnlyTools(readOnlyTools),
+		server.WithDisabledPrompts(cfg.Prompts.Disabled),
+		server.WithHistorySize(cfg.Execution.HistorySize),
+		server.WithAutoFix(cfg.Execution.AutoFix),
+		server.WithPythonInstaller(cfg.Execution.PythonInstaller),
+		server.WithRunAs(runAs),
+		server.WithEnvPassthrough(cfg.Execution.EnvPassthrough),
+		server.WithPriority(executor.Priority{Nice: cfg.Execution.Nice, IOPriority: cfg.Execution.IOPriority}),
+		server.WithTimeLocale(executor.TimeLocale{
+			Timezone:        cfg.Execution.Timezone,
+			Locale:          cfg.Execution.Locale,
+			FakeTimeLibrary: cfg.Execution.FakeTimeLibrary,
+		}),
+		server.WithBinaries(cfg.Execution.Binaries),
+		server.WithEnvironments(cfg.Environments),
+		server.WithRegistries(cfg.Registries),
+		server.WithOffline(offline || cfg.Execution.Offline),
+		server.WithQuotas(cfg.Quotas),
+		server.WithOutput(cfg.Output),
+		server.WithPersistentIdle(cfg.Execution.PersistentIdle),
+		server.WithDependencyDetection(cfg.Execution.DetectDependencies, executor.ImportPolicy{
+			Packages:      cfg.Execution.ImportPackages,
+			AllowUnmapped: cfg.Policy.InstallUnmappedImports,
+		}),
+		server.WithWorkspaceRoot(cfg.Execution.WorkspaceDir),
+		server.WithWorkspaceIdle(cfg.Execution.WorkspaceIdle),
+		server.WithGoCacheDir(cfg.Execution.GoCacheDir),
+		server.WithCache(cfg.Cache),
+		server.WithSchedules(cfg.Schedule),
+		server.WithImages(cfg.Images),
+		server.WithResourceLimits(cfg.Limits),
+		server.WithDefaultEnv(defaultEnv),
+	}, nil
+}
+
+// reloadConfig re-reads the configuration file, re-applies the command-line flags
+// and applies the result to the running server.
+func reloadConfig(cmd *cobra.Command, reloader *server.Reloader) error {
+	cfg, err := config.Load(configFile, profile)
+	if err != nil {
+		return err
+	}
+	applyServeFlags(cmd, &cfg)
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	serverOpts, err := serverOptions(cfg)
+	if err != nil {
+		return err
+	}
+	reloader.Reload(serverOpts...)
+	return nil
+}
+
+// handleReloadSignals calls reload whenever the process receives SIGHUP.
+func handleReloadSignals(reload func() error) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	for range signals {
+		logger.Info("Received SIGHUP, reloading configuration")
+		if err := reload(); err != nil {
+			logger.Error("Reload failed, keeping the current configuration: %v", err)
+		}
+	}
+}
+
+// applyServeFlags overrides configuration values with the flags set on the command line.
+func applyServeFlags(cmd *cobra.Command, cfg *config.Config) {
+	flags := cmd.Flags()
+	if flags.Changed("mode") {
+		cfg.Transport.Mode, _ = flags.GetString("mode")
+	}
+	if flags.Changed("execution-mode") {
+		cfg.Execution.Mode, _ = flags.GetString("execution-mode")
+	}
+	if flags.Changed("tools") {
+		cfg.Execution.Tools, _ = flags.GetStringSlice("tools")
+	}
+	if flags.Changed("readonly-tools") {
+		cfg.Execution.ReadOnlyTools, _ = flags.GetStringSlice("readonly-tools")
+	}
+	if flags.Changed("run-as") {
+		cfg.Execution.RunAs, _ = flags.GetString("run-as")
+	}
+	if flags.Changed("env-passthrough") {
+		cfg.Execution.EnvPassthrough, _ = flags.GetStringSlice("env-passthrough")
+	}
+	if flags.Changed("history-size") {
+		cfg.Execution.HistorySize, _ = flags.GetInt("history-size")
+	}
+	if flags.Changed("auto-fix") {
+		cfg.Execution.AutoFix, _ = flags.GetInt("auto-fix")
+	}
+	if flags.Changed("allow-mount") {
+		cfg.Policy.AllowedMounts, _ = flags.GetStringSlice("allow-mount")
+	}
+	if flags.Changed("allow-unconfirmed-privileges") {
+		cfg.Policy.AllowUnconfirmedPrivileges, _ = flags.GetBool("allow-unconfirmed-privileges")
+	}
+	if flags.Changed("auth-token") {
+		cfg.Transport.AuthTokens, _ = flags.GetStringSlice("auth-token")
+	}
+	if flags.Changed("tls-cert") {
+		cfg.Transport.TLSCert, _ = flags.GetString("tls-cert")
+	}
+	if flags.Changed("tls-key") {
+		cfg.Transport.TLSKey, _ = flags.GetString("tls-key")
+	}
+	if flags.Changed("tls-client-ca") {
+		cfg.Transport.TLSClientCA, _ = flags.GetString("tls-client-ca")
+	}
+	if flags.Changed("cors-origin") {
+		cfg.Transport.CORSOrigins, _ = flags.GetStringSlice("cors-origin")
+	}
+	if flags.Changed("base-path") {
+		cfg.Transport.BasePath, _ = flags.GetString("base-path")
+	}
+	if flags.Changed("debug-addr") {
+		cfg.Transport.DebugAddr, _ = flags.GetString("debug-addr")
+	}
+	if flags.Changed("log-file") {
+		cfg.Logging.File, _ = flags.GetString("log-file")
+	}
+	if flags.Changed("log-max-size") {
+		cfg.Logging.MaxSizeMB, _ = flags.GetInt("log-max-size")
+	}
+	if flags.Changed("log-rotate-interval") {
+		cfg.Logging.RotateInterval, _ = flags.GetDuration("log-rotate-interval")
+	}
+	if flags.Changed("log-max-backups") {
+		cfg.Logging.MaxBackups, _ = flags.GetInt("log-max-backups")
+	}
+}
+
 func init() {
 	// Serve command flags
 	serveCmd.Flags().StringP("mode", "m", "stdio", "Transport mode: stdio, sse, or http")
-	serveCmd.Flags().StringP("execution-mode", "e", "subprocess", "Execution mode: subprocess or docker")
+	serveCmd.Flags().StringP("execution-mode", "e", "subprocess", "Execution mode: subprocess, docker, hybrid or nix")
+	serveCmd.Flags().StringSlice("tools", nil, "Comma-separated execute tools to expose: python, bash, typescript, go, tests, benchmark, browse, render, http (default all)")
+	serveCmd.Flags().StringSlice("readonly-tools", nil, "Comma-separated languages also exposed as read-only execute-<language>-readonly tools")
+	serveCmd.Flags().String("run-as", "", "Run subprocess and nix executions as this host user or user:group (requires running as root)")
+	serveCmd.Flags().StringSlice("env-passthrough", nil, "Comma-separated server variables, or prefixes ending in *, also passed to subprocess and nix executions (\"*\" passes all)")
+	serveCmd.Flags().Int("history-size", history.DefaultCapacity, "Number of recent executions kept as execution:// resources")
+	serveCmd.Flags().Int("auto-fix", 0, "Ask the client LLM (via MCP sampling) to fix failed executions and re-run up to N times (0 disables)")
+	serveCmd.Flags().StringSlice("allow-mount", nil, "Host directory that Docker-mode tools may mount (repeatable)")
+	serveCmd.Flags().Bool("allow-unconfirmed-privileges", false, "Run host mounts, host networking and sudo-like scripts for clients that cannot confirm them")
+	serveCmd.Flags().StringSlice("auth-token", nil, "Bearer token / API key required by SSE and HTTP transports (repeatable, also read from "+server.AuthTokensEnvVar+")")
+	serveCmd.Flags().String("tls-cert", "", "TLS certificate file for serving SSE/HTTP over HTTPS")
+	serveCmd.Flags().String("tls-key", "", "TLS private key file for serving SSE/HTTP over HTTPS")
+	serveCmd.Flags().StringSlice("cors-origin", nil, "Browser origin allowed to call SSE/HTTP endpoints (repeatable, '*' for any)")
+	serveCmd.Flags().String("base-path", "", "URL path prefix for SSE/HTTP endpoints when behind a reverse proxy (e.g. /executor)")
+	serveCmd.Flags().String("tls-client-ca", "", "CA bundle for verifying client certificates (enables mutual TLS)")
+	serveCmd.Flags().String("debug-addr", "", "Serve pprof and expvar on this admin address for SSE/HTTP, protected by the auth tokens (e.g. 127.0.0.1:6060)")
+	serveCmd.Flags().String("log-file", "", "Write logs to this file instead of stderr, with rotation")
+	serveCmd.Flags().Int("log-max-size", 100, "Rotate the log file when it exceeds this many megabytes (0 disables)")
+	serveCmd.Flags().Duration("log-rotate-interval", 0, "Rotate the log file after this long, e.g. 24h (0 disables)")
+	serveCmd.Flags().Int("log-max-backups", 5, "Rotated log files to keep (0 keeps all)")
 
 	// Add serve command to root
 	rootCmd.AddCommand(serveCmd)
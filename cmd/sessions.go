@@ -0,0 +1,72 @@
+// Package main provides the sessions command for inspecting and terminating
+// running execution containers.
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+)
+
+// sessionsCmd groups the session management subcommands
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "Inspect and terminate running execution containers",
+	Long: `Inspect and terminate the containers of running Docker-mode executions.
+
+Each execution currently runs in its own container, mcp-exec-<language>-<execution ID>,
+labeled ` + executor.LanguageLabel + ` and with its execution, session and client IDs,
+which is removed when the execution ends; stuck ones show up here until killed.`,
+}
+
+// sessionsListCmd prints the running execution containers
+var sessionsListCmd = &cobra.Command{
+	Use:           "list",
+	Short:         "List running execution containers",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		containers, err := executor.ListContainers(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if len(containers) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No running execution containers")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tLANGUAGE\tIMAGE\tCLIENT\tSTARTED")
+		for _, container := range containers {
+			fmt.Fprintf(w, "%.12s\t%s\t%s\t%s\t%s\t%s\n", container.ID, container.Name, container.Language, container.Image, cmp.Or(container.Client, "-"), container.Running)
+		}
+		return w.Flush()
+	},
+}
+
+// sessionsKillCmd terminates execution containers
+var sessionsKillCmd = &cobra.Command{
+	Use:           "kill <id|name>...",
+	Short:         "Kill running execution containers",
+	Args:          cobra.MinimumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, id := range args {
+			if err := executor.KillContainer(cmd.Context(), id); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Killed %s\n", id)
+		}
+		return nil
+	},
+}
+
+func init() {
+	sessionsCmd.AddCommand(sessionsListCmd)
+	sessionsCmd.AddCommand(sessionsKillCmd)
+	rootCmd.AddCommand(sessionsCmd)
+}
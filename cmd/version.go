@@ -0,0 +1,128 @@
+// Package main provides the version command and the build information reported
+// by it and by --version. The variables are injected at build time with -ldflags.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/server"
+)
+
+var (
+	// Set with -ldflags "-X main.commit=... -X main.buildDate=..."
+	commit    = ""
+	buildDate = ""
+)
+
+// mcpGoModule is the MCP SDK whose version is reported.
+const mcpGoModule = "github.com/mark3labs/mcp-go"
+
+// buildInfo describes the running binary for bug reports.
+type buildInfo struct {
+	Version      string   `json:"version"`
+	Commit       string   `json:"commit"`
+	BuildDate    string   `json:"build_date"`
+	GoVersion    string   `json:"go_version"`
+	MCPGoVersion string   `json:"mcp_go_version"`
+	Platform     string   `json:"platform"`
+	Features     []string `json:"features"`
+}
+
+// currentBuildInfo combines the ldflags values with the information embedded by
+// the Go toolchain, which fills in the commit and date of plain "go build"s.
+// The features list the execution modes, languages and other tools the binary
+// supports.
+func currentBuildInfo() buildInfo {
+	tools := slices.DeleteFunc(slices.Clone(server.ToolSelectors), func(selector string) bool {
+		return slices.Contains(server.Languages, selector)
+	})
+	info := buildInfo{
+		Version:      version,
+		Commit:       commit,
+		BuildDate:    buildDate,
+		GoVersion:    runtime.Version(),
+		MCPGoVersion: "unknown",
+		Platform:     runtime.GOOS + "/" + runtime.GOARCH,
+		Features: []string{
+			"transports=stdio,sse,http",
+			"execution=" + strings.Join(executor.ExecutionModes, ","),
+			"languages=" + strings.Join(server.Languages, ","),
+			"tools=" + strings.Join(tools, ","),
+			"config=yaml,toml",
+		},
+	}
+
+	if embedded, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range embedded.Deps {
+			if dep.Path == mcpGoModule {
+				info.MCPGoVersion = dep.Version
+			}
+		}
+		for _, setting := range embedded.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = setting.Value
+			case setting.Key == "vcs.modified" && setting.Value == "true" && commit == "":
+				info.Commit += "-dirty"
+			}
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildDate == "" {
+		info.BuildDate = "unknown"
+	}
+	return info
+}
+
+// String formats the build information as printed by the version command.
+func (b buildInfo) String() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "mcp-executor %s\n", b.Version)
+	fmt.Fprintf(&s, "  commit:     %s\n", b.Commit)
+	fmt.Fprintf(&s, "  built:      %s\n", b.BuildDate)
+	fmt.Fprintf(&s, "  go:         %s\n", b.GoVersion)
+	fmt.Fprintf(&s, "  mcp-go:     %s\n", b.MCPGoVersion)
+	fmt.Fprintf(&s, "  platform:   %s\n", b.Platform)
+	fmt.Fprintf(&s, "  features:   %s\n", strings.Join(b.Features, " "))
+	return s.String()
+}
+
+// versionCmd prints the build information
+var versionCmd = &cobra.Command{
+	Use:           "version",
+	Short:         "Print version and build information",
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		info := currentBuildInfo()
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(info)
+		}
+		_, err := fmt.Fprint(cmd.OutOrStdout(), info)
+		return err
+	},
+}
+
+func init() {
+	versionCmd.Flags().Bool("json", false, "Print the build information as JSON")
+
+	// --version prints the same information as the version command
+	cobra.AddTemplateFunc("buildInfo", func() string { return currentBuildInfo().String() })
+	rootCmd.SetVersionTemplate("{{buildInfo}}")
+
+	rootCmd.AddCommand(versionCmd)
+}
@@ -0,0 +1,97 @@
+// Package benchmark times code snippets in the sandbox: it builds the program
+// that runs each snippet repeatedly with timeit, go test -bench or a timed
+// loop, and turns the timings into statistics comparing the snippets.
+package benchmark
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Marker separates the output of the benchmark program from its timings.
+const Marker = "--- mcp-executor benchmark ---"
+
+// Run limits: every snippet is timed at least MinRuns times, for a standard
+// deviation, and at most MaxRuns times.
+const (
+	DefaultRuns = 10
+	MinRuns     = 2
+	MaxRuns     = 100
+)
+
+// maxNameLength is the length of the longest snippet name.
+const maxNameLength = 64
+
+// Methods are the timing methods by language.
+var Methods = map[string]string{
+	"python":     "timeit",
+	"go":         "go test -bench",
+	"typescript": "hrtime loop",
+}
+
+// Languages returns the languages that can be benchmarked, sorted.
+func Languages() []string {
+	languages := make([]string, 0, len(Methods))
+	for language := range Methods {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// snippet is a named snippet in the timings and the program data.
+type snippet struct {
+	Name string `json:"name"`
+	Code string `json:"code"`
+}
+
+// Program returns the program of language timing each of snippets, keyed by
+// name, runs times. setup runs once before the timings: in Python it is the
+// setup of timeit, in Go and TypeScript source placed before the snippets,
+// e.g. imports and helpers. Each timing repeats the snippet in a loop lasting
+// at least about 100ms, after a warm-up. The program prints the output of the
+// code, then Marker and the timings, see Parse.
+func Program(language, setup string, snippets map[string]string, runs int) (string, error) {
+	if _, ok := Methods[language]; !ok {
+		return "", fmt.Errorf("%s cannot be benchmarked (expected %s)", language, strings.Join(Languages(), ", "))
+	}
+	if len(snippets) == 0 {
+		return "", fmt.Errorf("no snippets to benchmark")
+	}
+	if runs < MinRuns || runs > MaxRuns {
+		return "", fmt.Errorf("invalid runs %d: must be between %d and %d", runs, MinRuns, MaxRuns)
+	}
+	ordered := make([]snippet, 0, len(snippets))
+	for name, code := range snippets {
+		if name == "" || len(name) > maxNameLength || strings.ContainsAny(name, "\r\n") {
+			return "", fmt.Errorf("invalid snippet name %q: use a single line of at most %d characters", name, maxNameLength)
+		}
+		if strings.TrimSpace(code) == "" {
+			return "", fmt.Errorf("snippet %q has no code", name)
+		}
+		ordered = append(ordered, snippet{Name: name, Code: code})
+	}
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].Name < ordered[j].Name })
+
+	switch language {
+	case "python":
+		return pythonProgram(setup, ordered, runs)
+	case "go":
+		return goProgram(setup, ordered, runs)
+	default:
+		return typeScriptProgram(setup, ordered, runs)
+	}
+}
+
+// encode returns value as base64-encoded JSON, embedded in the programs so
+// that code never needs quoting.
+func encode(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode snippets: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
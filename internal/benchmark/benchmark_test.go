@@ -0,0 +1,158 @@
+package benchmark
+
+import (
+	"context"
+	"math"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/ylchen07/mcp-executor/internal/executor"
+)
+
+func TestProgram(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		snippets map[string]string
+		runs     int
+		wantErr  bool
+	}{
+		{"python", "python", map[string]string{"join": "''.join(parts)"}, 10, false},
+		{"go", "go", map[string]string{"sprintf": `sink = fmt.Sprint(1)`}, 5, false},
+		{"typescript", "typescript", map[string]string{"spread": "[...items]"}, 2, false},
+		{"unsupported language", "bash", map[string]string{"echo": "echo"}, 10, true},
+		{"no snippets", "python", nil, 10, true},
+		{"too few runs", "python", map[string]string{"a": "pass"}, 1, true},
+		{"too many runs", "python", map[string]string{"a": "pass"}, MaxRuns + 1, true},
+		{"empty snippet", "python", map[string]string{"a": " "}, 10, true},
+		{"multi-line name", "python", map[string]string{"a\nb": "pass"}, 10, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Program(tt.language, "", tt.snippets, tt.runs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Program() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (strings.Contains(program, "{{") || !strings.Contains(program, Marker)) {
+				t.Errorf("Program() left placeholders or has no marker:\n%s", program)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	output := "setup done\n\n" + Marker + `
+[{"name": "slow", "loops": 1000, "samples_ns": [210, 190, 200, 200]},
+ {"name": "fast", "loops": 2000, "samples_ns": [100, 101, 99, 100]},
+ {"name": "noisy", "loops": 2000, "samples_ns": [60, 100, 80, 80]}]
+`
+	result, codeOutput, err := Parse("python", output)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	if codeOutput != "setup done\n" || result.Method != "timeit" || result.Runs != 4 {
+		t.Errorf("Parse() = %+v, output %q", result, codeOutput)
+	}
+	var names []string
+	for _, snippet := range result.Snippets {
+		names = append(names, snippet.Name)
+	}
+	if strings.Join(names, ",") != "noisy,fast,slow" {
+		t.Fatalf("Snippets = %v, want them sorted by median", names)
+	}
+
+	slow := result.Snippets[2]
+	if slow.MeanNs != 200 || slow.MedianNs != 200 || slow.MinNs != 190 || slow.MaxNs != 210 || slow.Loops != 1000 {
+		t.Errorf("slow = %+v", slow)
+	}
+	if stddev := math.Sqrt(200.0 / 3); math.Abs(slow.StddevNs-stddev) > 1e-9 || math.Abs(slow.CI95Ns-3.182*stddev/2) > 1e-9 {
+		t.Errorf("slow spread = %v ± %v", slow.StddevNs, slow.CI95Ns)
+	}
+	if slow.Relative != 2.5 || !slow.Significant {
+		t.Errorf("slow = %+v, want 2.5x the median of the fastest, beyond noise", slow)
+	}
+	if fast := result.Snippets[1]; fast.Relative != 1.25 || fast.Significant {
+		t.Errorf("fast = %+v, want 1.25x, within the noise of the fastest", fast)
+	}
+
+	if summary := result.Summary(); !strings.Contains(summary, "slow: 200ns ± ") || !strings.Contains(summary, "2.50x slower") {
+		t.Errorf("Summary() = %q", summary)
+	}
+
+	if _, _, err := Parse("python", "Traceback (most recent call last):\nZeroDivisionError\n"); err == nil {
+		t.Error("Parse() without timings returned no error")
+	}
+}
+
+func TestFormatNanoseconds(t *testing.T) {
+	tests := []struct {
+		ns   float64
+		want string
+	}{
+		{0.3124, "0.312ns"},
+		{105.2, "105ns"},
+		{1250, "1.25µs"},
+		{2.5e6, "2.5ms"},
+		{3.1e9, "3.1s"},
+	}
+	for _, tt := range tests {
+		if got := formatNanoseconds(tt.ns); got != tt.want {
+			t.Errorf("formatNanoseconds(%v) = %q, want %q", tt.ns, got, tt.want)
+		}
+	}
+}
+
+func TestProgram_Run(t *testing.T) {
+	tests := []struct {
+		language string
+		binary   string
+		exec     executor.Executor
+		setup    string
+		snippets map[string]string
+	}{
+		{
+			language: "python",
+			binary:   "python3",
+			exec:     executor.NewSubprocessPythonExecutor(),
+			setup:    "items = list(range(100))",
+			snippets: map[string]string{"sum": "sum(items)", "loop": "total = 0\nfor item in items: total += item"},
+		},
+		{
+			language: "go",
+			binary:   "go",
+			exec:     executor.NewSubprocessGoExecutor(),
+			setup:    "import \"strings\"\n\nvar sink string",
+			snippets: map[string]string{"repeat": `sink = strings.Repeat("a", 100)`, "concat": "sink = \"\"\nfor range 100 {\n\tsink += \"a\"\n}"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			if _, err := exec.LookPath(tt.binary); err != nil {
+				t.Skipf("%s not installed", tt.binary)
+			}
+			program, err := Program(tt.language, tt.setup, tt.snippets, MinRuns)
+			if err != nil {
+				t.Fatalf("Program() returned error: %v", err)
+			}
+			output, err := tt.exec.Execute(context.Background(), program, nil, map[string]string{"GOPROXY": "off"})
+			if err != nil {
+				t.Fatalf("Execute() returned error: %v", err)
+			}
+			result, _, err := Parse(tt.language, output)
+			if err != nil {
+				t.Fatalf("Parse() returned error: %v\n%s", err, output)
+			}
+			if len(result.Snippets) != 2 || result.Runs != MinRuns {
+				t.Fatalf("Parse() = %+v, want both snippets timed %d times", result, MinRuns)
+			}
+			for _, snippet := range result.Snippets {
+				if snippet.MeanNs <= 0 || snippet.Loops <= 0 {
+					t.Errorf("Snippet %s = %+v, want timings", snippet.Name, snippet)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// Package benchmark provides the benchmark programs of each language. The
+// Python and TypeScript programs find the number of loops of a snippet lasting
+// at least 100ms by doubling it, which also warms the snippet up, then time the
+// snippets in turn, so that drift of the machine affects them alike; go test
+// calibrates its loops itself and times the runs of a benchmark together.
+package benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// pythonTemplate times the snippets with timeit.
+const pythonTemplate = `import base64, json, timeit
+
+benchmark = json.loads(base64.b64decode("{{BENCHMARK}}"))
+timings = []
+for snippet in benchmark["snippets"]:
+    timer = timeit.Timer(snippet["code"], benchmark["setup"] or "pass")
+    loops = 1
+    while timer.timeit(loops) < 0.1 and loops < 1e9:
+        loops *= 2
+    timings.append({"name": snippet["name"], "loops": loops, "samples_ns": [], "timer": timer})
+for _ in range(benchmark["runs"]):
+    for timing in timings:
+        timing["samples_ns"].append(timing["timer"].timeit(timing["loops"]) / timing["loops"] * 1e9)
+print("\n{{MARKER}}")
+print(json.dumps([{key: value for key, value in timing.items() if key != "timer"} for timing in timings]))
+`
+
+func pythonProgram(setup string, snippets []snippet, runs int) (string, error) {
+	data, err := encode(map[string]any{"setup": setup, "snippets": snippets, "runs": runs})
+	if err != nil {
+		return "", err
+	}
+	return strings.NewReplacer("{{BENCHMARK}}", data, "{{MARKER}}", Marker).Replace(pythonTemplate), nil
+}
+
+// goTemplate writes the benchmarks to a module in a temporary directory and
+// runs go test -bench there, each -count being a run.
+const goTemplate = `package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const benchmark = "{{BENCHMARK}}"
+
+// result matches a result line of go test -bench, e.g. "Benchmark0-8   1000000   105.2 ns/op"
+var result = regexp.MustCompile("^Benchmark(\\d+)(?:-\\d+)?\\s+(\\d+)\\s+([\\d.]+) ns/op")
+
+type timing struct {
+	name    string
+	loops   int
+	samples []float64
+}
+
+func main() {
+	data, err := base64.StdEncoding.DecodeString(benchmark)
+	if err != nil {
+		panic(err)
+	}
+	var spec struct {
+		Source string
+		Names  []string
+		Runs   int
+	}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		panic(err)
+	}
+	root, err := os.MkdirTemp("", "mcp-benchmark-*")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(root)
+	module := "module bench\n"
+	if version, ok := strings.CutPrefix(runtime.Version(), "go"); ok {
+		module += "\ngo " + version + "\n"
+	}
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte(module), 0o644); err != nil {
+		panic(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "bench_test.go"), []byte(spec.Source), 0o644); err != nil {
+		panic(err)
+	}
+
+	cmd := exec.Command("go", "test", "-run=^$", "-bench=.", "-benchtime=100ms", "-count="+strconv.Itoa(spec.Runs))
+	cmd.Dir = root
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+	out, _ := cmd.CombinedOutput()
+	os.Stdout.Write(out)
+
+	timings := make([]timing, len(spec.Names))
+	measured := false
+	for i, name := range spec.Names {
+		timings[i].name = name
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		match := result.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		index, _ := strconv.Atoi(match[1])
+		loops, _ := strconv.Atoi(match[2])
+		nanoseconds, _ := strconv.ParseFloat(match[3], 64)
+		if index < len(timings) {
+			timings[index].loops = loops
+			timings[index].samples = append(timings[index].samples, nanoseconds)
+			measured = true
+		}
+	}
+	if measured {
+		var report []map[string]any
+		for _, timing := range timings {
+			report = append(report, map[string]any{"name": timing.name, "loops": timing.loops, "samples_ns": timing.samples})
+		}
+		data, _ := json.Marshal(report)
+		fmt.Println("\n{{MARKER}}")
+		fmt.Println(string(data))
+	}
+}
+`
+
+func goProgram(setup string, snippets []snippet, runs int) (string, error) {
+	var source strings.Builder
+	source.WriteString("package bench\n\nimport \"testing\"\n\n")
+	if setup != "" {
+		source.WriteString(setup + "\n\n")
+	}
+	names := make([]string, len(snippets))
+	for i, snippet := range snippets {
+		names[i] = snippet.Name
+		fmt.Fprintf(&source, "func Benchmark%d(b *testing.B) {\n\tfor benchmarkLoop := 0; benchmarkLoop < b.N; benchmarkLoop++ {\n%s\n\t}\n}\n\n", i, snippet.Code)
+	}
+	data, err := encode(map[string]any{"Source": source.String(), "Names": names, "Runs": runs})
+	if err != nil {
+		return "", err
+	}
+	return strings.NewReplacer("{{BENCHMARK}}", data, "{{MARKER}}", Marker).Replace(goTemplate), nil
+}
+
+// typeScriptHarness follows the setup and the snippets, which are functions
+// in the program itself, so that they share the imports of the setup.
+const typeScriptHarness = `
+function __time(fn: () => void, loops: number): number {
+  const start = process.hrtime.bigint();
+  for (let i = 0; i < loops; i++) {
+    fn();
+  }
+  return Number(process.hrtime.bigint() - start);
+}
+
+const __timings = __snippets.map(([name, fn]) => {
+  let loops = 1;
+  while (__time(fn, loops) < 1e8 && loops < 1e9) {
+    loops *= 2;
+  }
+  return { name, loops, fn, samples_ns: [] as number[] };
+});
+for (let run = 0; run < __runs; run++) {
+  for (const timing of __timings) {
+    timing.samples_ns.push(__time(timing.fn, timing.loops) / timing.loops);
+  }
+}
+console.log("\n{{MARKER}}");
+console.log(JSON.stringify(__timings.map(({ name, loops, samples_ns }) => ({ name, loops, samples_ns }))));
+`
+
+func typeScriptProgram(setup string, snippets []snippet, runs int) (string, error) {
+	var program strings.Builder
+	if setup != "" {
+		program.WriteString(setup + "\n\n")
+	}
+	program.WriteString("const __snippets: [string, () => void][] = [\n")
+	for _, snippet := range snippets {
+		name, err := json.Marshal(snippet.Name)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode snippets: %v", err)
+		}
+		fmt.Fprintf(&program, "  [%s, () => {\n%s\n  }],\n", name, snippet.Code)
+	}
+	fmt.Fprintf(&program, "];\nconst __runs = %d;\n", runs)
+	program.WriteString(strings.ReplaceAll(typeScriptHarness, "{{MARKER}}", Marker))
+	return program.String(), nil
+}
@@ -0,0 +1,159 @@
+// Package benchmark summarizes the timings of the benchmark programs: the
+// mean, median and spread of each snippet, with a 95% confidence interval of
+// the mean telling apart real differences from noise.
+package benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Result compares the snippets of a benchmark.
+type Result struct {
+	Language string    `json:"language"`
+	Method   string    `json:"method"` // Timing method, see Methods
+	Runs     int       `json:"runs"`   // Timings of each snippet
+	Snippets []Summary `json:"snippets"`
+}
+
+// Summary is the statistics of the timings of a snippet, in nanoseconds per
+// loop of the snippet.
+type Summary struct {
+	Name     string  `json:"name"`
+	Loops    int     `json:"loops"` // Loops of a timing
+	MeanNs   float64 `json:"mean_ns"`
+	MedianNs float64 `json:"median_ns"`
+	StddevNs float64 `json:"stddev_ns"`
+	MinNs    float64 `json:"min_ns"`
+	MaxNs    float64 `json:"max_ns"`
+	CI95Ns   float64 `json:"ci95_ns"`  // Half-width of the 95% confidence interval of the mean
+	Relative float64 `json:"relative"` // Median relative to the fastest snippet
+	// Significant reports whether the confidence interval of the snippet
+	// does not overlap that of the fastest, so that it is slower beyond noise.
+	Significant bool `json:"significant"`
+}
+
+// timing is a snippet with its timings, as printed by the programs.
+type timing struct {
+	Name      string    `json:"name"`
+	Loops     int       `json:"loops"`
+	SamplesNs []float64 `json:"samples_ns"`
+}
+
+// tQuantiles are the 97.5% quantiles of Student's t distribution by degrees
+// of freedom, from 1. More runs use the normal distribution.
+var tQuantiles = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// Parse splits the output of the benchmark program of language into the output
+// of the code and the Result, with the snippets sorted from fastest to slowest
+// by median. It fails when the program printed no timings, e.g. because a
+// snippet raised an error or did not compile.
+func Parse(language, output string) (Result, string, error) {
+	codeOutput, data, found := strings.Cut(output, Marker)
+	if !found {
+		return Result{}, output, fmt.Errorf("benchmark did not complete")
+	}
+	var timings []timing
+	if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &timings); err != nil {
+		return Result{}, codeOutput, fmt.Errorf("invalid benchmark timings: %v", err)
+	}
+
+	result := Result{Language: language, Method: Methods[language]}
+	for _, t := range timings {
+		if len(t.SamplesNs) == 0 {
+			return Result{}, codeOutput, fmt.Errorf("snippet %q was not timed", t.Name)
+		}
+		result.Runs = max(result.Runs, len(t.SamplesNs))
+		result.Snippets = append(result.Snippets, summarize(t))
+	}
+	if len(result.Snippets) == 0 {
+		return Result{}, codeOutput, fmt.Errorf("benchmark timed no snippets")
+	}
+	sort.SliceStable(result.Snippets, func(i, j int) bool { return result.Snippets[i].MedianNs < result.Snippets[j].MedianNs })
+	fastest := result.Snippets[0]
+	for i := range result.Snippets {
+		snippet := &result.Snippets[i]
+		snippet.Relative = 1
+		if fastest.MedianNs > 0 {
+			snippet.Relative = math.Round(100*snippet.MedianNs/fastest.MedianNs) / 100
+		}
+		snippet.Significant = i > 0 && snippet.MeanNs-snippet.CI95Ns > fastest.MeanNs+fastest.CI95Ns
+	}
+	return result, strings.TrimRight(codeOutput, "\n") + "\n", nil
+}
+
+// summarize returns the statistics of the samples of t.
+func summarize(t timing) Summary {
+	samples := append([]float64(nil), t.SamplesNs...)
+	sort.Float64s(samples)
+	n := float64(len(samples))
+
+	summary := Summary{Name: t.Name, Loops: t.Loops, MinNs: samples[0], MaxNs: samples[len(samples)-1]}
+	for _, sample := range samples {
+		summary.MeanNs += sample / n
+	}
+	if middle := len(samples) / 2; len(samples)%2 == 1 {
+		summary.MedianNs = samples[middle]
+	} else {
+		summary.MedianNs = (samples[middle-1] + samples[middle]) / 2
+	}
+	if len(samples) > 1 {
+		var squares float64
+		for _, sample := range samples {
+			squares += (sample - summary.MeanNs) * (sample - summary.MeanNs)
+		}
+		summary.StddevNs = math.Sqrt(squares / (n - 1))
+		quantile := 1.96
+		if degrees := len(samples) - 1; degrees <= len(tQuantiles) {
+			quantile = tQuantiles[degrees-1]
+		}
+		summary.CI95Ns = quantile * summary.StddevNs / math.Sqrt(n)
+	}
+	return summary
+}
+
+// Summary describes the result in a few lines, fastest snippet first, e.g.
+// "sorted: 1.25µs ± 0.8% (median 1.24µs, 262144 loops)".
+func (r Result) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Benchmark with %s, %d runs per snippet, fastest first:\n", r.Method, r.Runs)
+	for i, snippet := range r.Snippets {
+		spread := 0.0
+		if snippet.MeanNs > 0 {
+			spread = 100 * snippet.CI95Ns / snippet.MeanNs
+		}
+		fmt.Fprintf(&b, "%s: %s ± %.1f%% (median %s, %d loops)", snippet.Name, formatNanoseconds(snippet.MeanNs), spread, formatNanoseconds(snippet.MedianNs), snippet.Loops)
+		switch {
+		case i == 0:
+			b.WriteString(", fastest")
+		case snippet.Significant:
+			fmt.Fprintf(&b, ", %.2fx slower", snippet.Relative)
+		default:
+			fmt.Fprintf(&b, ", %.2fx, within noise of the fastest", snippet.Relative)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// formatNanoseconds formats a duration in nanoseconds with three significant
+// digits, e.g. 0.312ns, 1.25µs or 3.1s.
+func formatNanoseconds(ns float64) string {
+	units := []struct {
+		scale float64
+		name  string
+	}{{1e9, "s"}, {1e6, "ms"}, {1e3, "µs"}}
+	for _, unit := range units {
+		if ns >= unit.scale {
+			return fmt.Sprintf("%.3g%s", ns/unit.scale, unit.name)
+		}
+	}
+	return fmt.Sprintf("%.3gns", ns)
+}
@@ -0,0 +1,179 @@
+// Package browse drives a headless browser in the sandbox: it builds the
+// Playwright program visiting a page, extracting its text or the text of the
+// elements matching a selector and taking a screenshot, and parses the page
+// the program reports.
+package browse
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Marker separates the output of the browse program from the page it reports.
+const Marker = "--- mcp-executor browse ---"
+
+// maxSelectorLength is the length of the longest CSS selector.
+const maxSelectorLength = 1024
+
+// Request selects the page to visit and what to extract from it.
+type Request struct {
+	URL string // http or https URL of the page
+
+	// Selector is the CSS selector of the elements whose text is extracted;
+	// empty extracts the text of the whole page.
+	Selector string
+
+	// WaitFor is the CSS selector of an element waited for after the page
+	// loaded, for pages rendering their content with JavaScript.
+	WaitFor string
+
+	// Screenshot also takes a PNG screenshot: of the first element matching
+	// Selector, otherwise of the viewport, or the whole page with FullPage.
+	Screenshot bool
+	FullPage   bool
+}
+
+// Page is the page visited by the browse program.
+type Page struct {
+	URL     string   `json:"url"` // After redirects
+	Status  int      `json:"status,omitempty"`
+	Title   string   `json:"title"`
+	Text    string   `json:"text,omitempty"`    // Text of the page, without Selector
+	Matches []string `json:"matches,omitempty"` // Text of each element matching Selector
+
+	Screenshot []byte `json:"-"` // PNG
+}
+
+// Program returns the Python program visiting the page of request with
+// Playwright's Chromium. The program prints Marker and the page, see Parse.
+func Program(request Request) (string, error) {
+	if err := checkURL(request.URL); err != nil {
+		return "", err
+	}
+	if err := checkSelectors(map[string]string{"selector": request.Selector, "wait_for": request.WaitFor}); err != nil {
+		return "", err
+	}
+	return program(pythonTemplate, map[string]any{
+		"url":        request.URL,
+		"selector":   request.Selector,
+		"wait_for":   request.WaitFor,
+		"screenshot": request.Screenshot,
+		"full_page":  request.FullPage,
+	})
+}
+
+// checkURL reports why rawURL is not the http or https URL of a page.
+func checkURL(rawURL string) error {
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("invalid url %q: expected an http or https URL", rawURL)
+	}
+	return nil
+}
+
+// checkSelectors reports CSS selectors, keyed by argument name, that are too long.
+func checkSelectors(selectors map[string]string) error {
+	for name, selector := range selectors {
+		if len(selector) > maxSelectorLength {
+			return fmt.Errorf("%s is longer than %d characters", name, maxSelectorLength)
+		}
+	}
+	return nil
+}
+
+// program returns template with the request embedded as base64-encoded JSON,
+// so that it never needs quoting.
+func program(template string, request map[string]any) (string, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode the request: %v", err)
+	}
+	return strings.NewReplacer(
+		"{{REQUEST}}", base64.StdEncoding.EncodeToString(data),
+		"{{MARKER}}", Marker,
+	).Replace(template), nil
+}
+
+// pythonTemplate visits the page with the sync API of Playwright.
+const pythonTemplate = `import base64, json
+from playwright.sync_api import sync_playwright
+
+browse = json.loads(base64.b64decode("{{REQUEST}}"))
+with sync_playwright() as playwright:
+    browser = playwright.chromium.launch()
+    try:
+        page = browser.new_page()
+        response = page.goto(browse["url"])
+        if browse["wait_for"]:
+            page.wait_for_selector(browse["wait_for"])
+        result = {"url": page.url, "status": response.status if response else 0, "title": page.title()}
+        if browse["selector"]:
+            matches = page.locator(browse["selector"])
+            result["matches"] = matches.all_inner_texts()
+        else:
+            result["text"] = page.inner_text("body")
+        if browse["screenshot"]:
+            if browse["selector"] and result["matches"]:
+                png = matches.first.screenshot()
+            else:
+                png = page.screenshot(full_page=browse["full_page"])
+            result["screenshot"] = base64.b64encode(png).decode()
+    finally:
+        browser.close()
+print("\n{{MARKER}}")
+print(json.dumps(result))
+`
+
+// Parse returns the page reported in the output of the browse program and the
+// output printed before it, e.g. warnings of Playwright.
+func Parse(output string) (Page, string, error) {
+	var reported struct {
+		Page
+		Screenshot []byte `json:"screenshot"`
+	}
+	programOutput, err := parse(output, &reported)
+	if err != nil {
+		return Page{}, "", err
+	}
+	page := reported.Page
+	page.Screenshot = reported.Screenshot
+	return page, programOutput, nil
+}
+
+// parse decodes the JSON document following Marker in output into v and
+// returns the output printed before it.
+func parse(output string, v any) (string, error) {
+	index := strings.LastIndex(output, Marker)
+	if index < 0 {
+		return "", fmt.Errorf("the browser program reported no page")
+	}
+	if err := json.Unmarshal([]byte(output[index+len(Marker):]), v); err != nil {
+		return "", fmt.Errorf("failed to parse the page reported by the browser program: %v", err)
+	}
+	return strings.TrimSuffix(output[:index], "\n"), nil
+}
+
+// Summary returns the page as text: its title, URL and status, followed by
+// its text or the text of each matching element.
+func (p Page) Summary() string {
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "%s\n%s", p.Title, p.URL)
+	if p.Status != 0 {
+		fmt.Fprintf(&summary, " (HTTP %d)", p.Status)
+	}
+	summary.WriteString("\n\n")
+	switch {
+	case p.Matches != nil:
+		fmt.Fprintf(&summary, "%d matching element(s)\n", len(p.Matches))
+		for i, match := range p.Matches {
+			fmt.Fprintf(&summary, "\n[%d] %s\n", i+1, match)
+		}
+	default:
+		summary.WriteString(p.Text)
+		summary.WriteString("\n")
+	}
+	return summary.String()
+}
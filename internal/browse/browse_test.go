@@ -0,0 +1,110 @@
+package browse
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgram(t *testing.T) {
+	tests := []struct {
+		name    string
+		request Request
+		wantErr bool
+	}{
+		{"page text", Request{URL: "https://example.com"}, false},
+		{"selector and screenshot", Request{URL: "http://localhost:8080/a?b=c", Selector: `a[href="x"]`, Screenshot: true, FullPage: true}, false},
+		{"missing scheme", Request{URL: "example.com"}, true},
+		{"file url", Request{URL: "file:///etc/passwd"}, true},
+		{"javascript url", Request{URL: "javascript:alert(1)"}, true},
+		{"long selector", Request{URL: "https://example.com", WaitFor: strings.Repeat("a", maxSelectorLength+1)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Program(tt.request)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Program() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (strings.Contains(program, "{{") || !strings.Contains(program, Marker) || strings.Contains(program, tt.request.URL)) {
+				t.Errorf("Program() left placeholders, has no marker or embeds the request unencoded:\n%s", program)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	png := base64.StdEncoding.EncodeToString([]byte("\x89PNG"))
+	output := "warning: slow page\n\n" + Marker + `
+{"url": "https://example.com/", "status": 200, "title": "Example", "matches": ["One", "Two"], "screenshot": "` + png + `"}
+`
+	page, programOutput, err := Parse(output)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	if programOutput != "warning: slow page\n" {
+		t.Errorf("output = %q", programOutput)
+	}
+	if page.URL != "https://example.com/" || page.Status != 200 || page.Title != "Example" || len(page.Matches) != 2 || string(page.Screenshot) != "\x89PNG" {
+		t.Errorf("Parse() = %+v", page)
+	}
+	want := "Example\nhttps://example.com/ (HTTP 200)\n\n2 matching element(s)\n\n[1] One\n\n[2] Two\n"
+	if summary := page.Summary(); summary != want {
+		t.Errorf("Summary() = %q, want %q", summary, want)
+	}
+
+	if _, _, err := Parse("Traceback (most recent call last):\nTimeoutError\n"); err == nil {
+		t.Error("Parse() without a page returned no error")
+	}
+}
+
+func TestRenderProgram(t *testing.T) {
+	tests := []struct {
+		name    string
+		request RenderRequest
+		wantErr bool
+	}{
+		{"defaults", RenderRequest{URL: "https://example.com"}, false},
+		{"pdf", RenderRequest{URL: "https://example.com", Format: FormatPDF, Width: 800, Height: 600, WaitUntil: "networkidle", Delay: time.Second}, false},
+		{"invalid url", RenderRequest{URL: "ftp://example.com"}, true},
+		{"invalid format", RenderRequest{URL: "https://example.com", Format: "gif"}, true},
+		{"viewport too wide", RenderRequest{URL: "https://example.com", Width: MaxViewport + 1}, true},
+		{"negative height", RenderRequest{URL: "https://example.com", Height: -1}, true},
+		{"invalid wait_until", RenderRequest{URL: "https://example.com", WaitUntil: "idle"}, true},
+		{"delay too long", RenderRequest{URL: "https://example.com", Delay: MaxDelay + time.Second}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := RenderProgram(tt.request)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderProgram() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (strings.Contains(program, "{{") || !strings.Contains(program, Marker)) {
+				t.Errorf("RenderProgram() left placeholders or has no marker:\n%s", program)
+			}
+		})
+	}
+}
+
+func TestParseRendering(t *testing.T) {
+	pdf := base64.StdEncoding.EncodeToString([]byte("%PDF-1.4"))
+	output := Marker + `
+{"url": "https://example.com/", "status": 200, "title": "Example", "format": "pdf", "width": 1280, "height": 720, "data": "` + pdf + `"}
+`
+	rendering, programOutput, err := ParseRendering(output)
+	if err != nil {
+		t.Fatalf("ParseRendering() returned error: %v", err)
+	}
+	if programOutput != "" || rendering.MIMEType != "application/pdf" || rendering.Size != 8 || string(rendering.Data) != "%PDF-1.4" {
+		t.Errorf("ParseRendering() = %+v, output %q", rendering, programOutput)
+	}
+	if want := "Example (https://example.com/, HTTP 200) rendered as a 1280x720 PDF of 0.0 KiB\n"; rendering.Summary() != want {
+		t.Errorf("Summary() = %q, want %q", rendering.Summary(), want)
+	}
+
+	if _, _, err := ParseRendering(Marker + `{"url": "https://example.com/", "format": "png"}`); err == nil {
+		t.Error("ParseRendering() without data returned no error")
+	}
+}
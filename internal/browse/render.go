@@ -0,0 +1,187 @@
+// Package browse renders pages in the sandbox: it builds the Playwright
+// program loading a page in a viewport of a given size and rendering it as a
+// PNG screenshot or a PDF document, and parses the rendering it reports.
+package browse
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Formats of rendered pages.
+const (
+	FormatPNG = "png"
+	FormatPDF = "pdf"
+)
+
+// Viewport sizes in CSS pixels.
+const (
+	DefaultWidth  = 1280
+	DefaultHeight = 720
+	MaxViewport   = 4096
+)
+
+// MaxDelay is the longest wait after the page loaded.
+const MaxDelay = 30 * time.Second
+
+// WaitUntil lists the load states a page is rendered after, the first being
+// the default: the load event, the DOMContentLoaded event, or no network
+// connections for 500ms.
+var WaitUntil = []string{"load", "domcontentloaded", "networkidle"}
+
+// mimeTypes maps the formats to the MIME type of the rendering.
+var mimeTypes = map[string]string{
+	FormatPNG: "image/png",
+	FormatPDF: "application/pdf",
+}
+
+// RenderRequest selects the page to render and how.
+type RenderRequest struct {
+	URL    string // http or https URL of the page
+	Format string // FormatPNG or FormatPDF; empty renders a PNG
+
+	// Width and Height are the size of the viewport; zero uses DefaultWidth
+	// and DefaultHeight.
+	Width  int
+	Height int
+
+	// FullPage renders the whole scrollable page instead of the viewport, for
+	// screenshots; PDF documents always hold the whole page.
+	FullPage bool
+
+	// WaitUntil is the load state the page is rendered after, one of
+	// WaitUntil; empty waits for the load event.
+	WaitUntil string
+
+	// WaitFor is the CSS selector of an element waited for after the page
+	// loaded, and Delay a time waited for on top, e.g. for animations.
+	WaitFor string
+	Delay   time.Duration
+}
+
+// Rendering is the page rendered by the render program.
+type Rendering struct {
+	URL      string `json:"url"` // After redirects
+	Status   int    `json:"status,omitempty"`
+	Title    string `json:"title"`
+	Format   string `json:"format"`
+	MIMEType string `json:"mime_type"`
+	Width    int    `json:"width"` // Of the viewport
+	Height   int    `json:"height"`
+	Size     int    `json:"size_bytes"`
+
+	Data []byte `json:"-"`
+}
+
+// RenderProgram returns the Python program rendering the page of request with
+// Playwright's Chromium. The program prints Marker and the rendering, see
+// ParseRendering.
+func RenderProgram(request RenderRequest) (string, error) {
+	if err := checkURL(request.URL); err != nil {
+		return "", err
+	}
+	if request.Format == "" {
+		request.Format = FormatPNG
+	}
+	if mimeTypes[request.Format] == "" {
+		return "", fmt.Errorf("invalid format %q: expected %s or %s", request.Format, FormatPNG, FormatPDF)
+	}
+	if request.Width == 0 {
+		request.Width = DefaultWidth
+	}
+	if request.Height == 0 {
+		request.Height = DefaultHeight
+	}
+	if request.Width < 1 || request.Width > MaxViewport || request.Height < 1 || request.Height > MaxViewport {
+		return "", fmt.Errorf("invalid viewport %dx%d: width and height must be between 1 and %d", request.Width, request.Height, MaxViewport)
+	}
+	if request.WaitUntil == "" {
+		request.WaitUntil = WaitUntil[0]
+	}
+	if !slices.Contains(WaitUntil, request.WaitUntil) {
+		return "", fmt.Errorf("invalid wait_until %q: expected one of %s", request.WaitUntil, strings.Join(WaitUntil, ", "))
+	}
+	if request.Delay < 0 || request.Delay > MaxDelay {
+		return "", fmt.Errorf("invalid delay %v: must be between 0 and %v", request.Delay, MaxDelay)
+	}
+	if err := checkSelectors(map[string]string{"wait_for": request.WaitFor}); err != nil {
+		return "", err
+	}
+	return program(renderTemplate, map[string]any{
+		"url":        request.URL,
+		"format":     request.Format,
+		"width":      request.Width,
+		"height":     request.Height,
+		"full_page":  request.FullPage,
+		"wait_until": request.WaitUntil,
+		"wait_for":   request.WaitFor,
+		"delay_ms":   request.Delay.Milliseconds(),
+	})
+}
+
+// renderTemplate renders the page with the sync API of Playwright. PDF
+// documents are printed with the backgrounds of the page.
+const renderTemplate = `import base64, json
+from playwright.sync_api import sync_playwright
+
+render = json.loads(base64.b64decode("{{REQUEST}}"))
+with sync_playwright() as playwright:
+    browser = playwright.chromium.launch()
+    try:
+        page = browser.new_page(viewport={"width": render["width"], "height": render["height"]})
+        response = page.goto(render["url"], wait_until=render["wait_until"])
+        if render["wait_for"]:
+            page.wait_for_selector(render["wait_for"])
+        if render["delay_ms"]:
+            page.wait_for_timeout(render["delay_ms"])
+        if render["format"] == "pdf":
+            data = page.pdf(print_background=True)
+        else:
+            data = page.screenshot(full_page=render["full_page"])
+        result = {
+            "url": page.url,
+            "status": response.status if response else 0,
+            "title": page.title(),
+            "format": render["format"],
+            "width": render["width"],
+            "height": render["height"],
+            "data": base64.b64encode(data).decode(),
+        }
+    finally:
+        browser.close()
+print("\n{{MARKER}}")
+print(json.dumps(result))
+`
+
+// ParseRendering returns the rendering reported in the output of the render
+// program and the output printed before it.
+func ParseRendering(output string) (Rendering, string, error) {
+	var reported struct {
+		Rendering
+		Data []byte `json:"data"`
+	}
+	programOutput, err := parse(output, &reported)
+	if err != nil {
+		return Rendering{}, "", err
+	}
+	rendering := reported.Rendering
+	if mimeTypes[rendering.Format] == "" || len(reported.Data) == 0 {
+		return Rendering{}, "", fmt.Errorf("the render program reported no %s rendering", rendering.Format)
+	}
+	rendering.MIMEType = mimeTypes[rendering.Format]
+	rendering.Data = reported.Data
+	rendering.Size = len(reported.Data)
+	return rendering, programOutput, nil
+}
+
+// Summary describes the rendering, e.g. "Example (https://example.com/,
+// HTTP 200) rendered as a 1280x720 PNG of 41.2 KiB".
+func (r Rendering) Summary() string {
+	status := ""
+	if r.Status != 0 {
+		status = fmt.Sprintf(", HTTP %d", r.Status)
+	}
+	return fmt.Sprintf("%s (%s%s) rendered as a %dx%d %s of %.1f KiB\n", r.Title, r.URL, status, r.Width, r.Height, strings.ToUpper(r.Format), float64(r.Size)/1024)
+}
@@ -0,0 +1,147 @@
+// Package cache keeps execution results for a limited time in memory and,
+// optionally, on disk, so identical repeat calls can be answered without
+// running the code again.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultMaxEntries is the number of results kept in memory when no limit is configured.
+const DefaultMaxEntries = 256
+
+// entry is a cached value, also the layout of the files in the cache directory.
+type entry struct {
+	Value   []byte    `json:"value"`
+	Expires time.Time `json:"expires"`
+}
+
+// Cache is a concurrency-safe TTL cache. When full, the oldest entry is evicted
+// from memory; entries stored on disk expire but are not evicted.
+type Cache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	dir        string
+	entries    map[string]entry
+	order      []string // Keys, oldest first
+	now        func() time.Time
+}
+
+// New creates a Cache keeping values for ttl, holding up to maxEntries in memory
+// (DefaultMaxEntries when <= 0) and persisting them in dir when it is not empty.
+func New(ttl time.Duration, maxEntries int, dir string) (*Cache, error) {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create cache directory: %v", err)
+		}
+	}
+	return &Cache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		dir:        dir,
+		entries:    make(map[string]entry),
+		now:        time.Now,
+	}, nil
+}
+
+// Key returns a cache key for the given parts.
+func Key(parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		fmt.Fprintf(hash, "%d:%s", len(part), part)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Get returns the unexpired value stored under key.
+func (c *Cache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok && c.dir != "" {
+		e, ok = c.load(key)
+		if ok {
+			c.add(key, e)
+		}
+	}
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(e.Expires) {
+		c.remove(key)
+		return nil, false
+	}
+	return e.Value, true
+}
+
+// Put stores value under key until the TTL elapses. Disk write errors are
+// returned but the value is still cached in memory.
+func (c *Cache) Put(key string, value []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := entry{Value: value, Expires: c.now().Add(c.ttl)}
+	c.add(key, e)
+	if c.dir == "" {
+		return nil
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path(key), data, 0o600)
+}
+
+// add stores e in memory, evicting the oldest entries beyond maxEntries.
+func (c *Cache) add(key string, e entry) {
+	if _, exists := c.entries[key]; !exists {
+		c.order = append(c.order, key)
+	}
+	c.entries[key] = e
+	for len(c.order) > c.maxEntries {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+// remove deletes key from memory and disk.
+func (c *Cache) remove(key string) {
+	delete(c.entries, key)
+	for i, k := range c.order {
+		if k == key {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+	if c.dir != "" {
+		_ = os.Remove(c.path(key))
+	}
+}
+
+func (c *Cache) load(key string) (entry, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return entry{}, false
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return entry{}, false
+	}
+	return e, true
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
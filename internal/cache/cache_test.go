@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache_GetPut(t *testing.T) {
+	c, err := New(time.Minute, 2, "")
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("Get() on an empty cache should miss")
+	}
+	_ = c.Put("a", []byte("1"))
+	if value, ok := c.Get("a"); !ok || string(value) != "1" {
+		t.Errorf("Get(a) = %q, %v, want 1", value, ok)
+	}
+
+	// The oldest entry is evicted beyond maxEntries
+	_ = c.Put("b", []byte("2"))
+	_ = c.Put("c", []byte("3"))
+	if _, ok := c.Get("a"); ok {
+		t.Error("Get(a) should miss after eviction")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := c.Get("c"); ok {
+		t.Error("Get(c) should miss after the TTL")
+	}
+}
+
+func TestCache_Disk(t *testing.T) {
+	dir := t.TempDir()
+	first, err := New(time.Hour, 0, dir)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	if err := first.Put("key", []byte("output")); err != nil {
+		t.Fatalf("Put() returned error: %v", err)
+	}
+
+	// A new cache on the same directory, e.g. after a restart, finds the value
+	second, err := New(time.Hour, 0, dir)
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	if value, ok := second.Get("key"); !ok || string(value) != "output" {
+		t.Errorf("Get() from disk = %q, %v, want output", value, ok)
+	}
+}
+
+func TestKey(t *testing.T) {
+	if Key("ab", "c") == Key("a", "bc") {
+		t.Error("Key() should separate its parts")
+	}
+	if Key("a", "b") != Key("a", "b") {
+		t.Error("Key() should be deterministic")
+	}
+}
@@ -1,7 +1,26 @@
-// Package config provides centralized configuration constants
-// for server identity, ports, transport endpoints, and Docker images.
+// Package config provides the server configuration, its defaults, and the
+// constants for server identity, transport endpoints, and Docker images.
 package config
 
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ylchen07/mcp-executor/internal/cache"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/history"
+	"github.com/ylchen07/mcp-executor/internal/quota"
+	"github.com/ylchen07/mcp-executor/internal/schedule"
+)
+
 const (
 	ServerName    = "mcp-executor"
 	ServerVersion = "1.0.0"
@@ -11,8 +30,765 @@ const (
 	HTTPHost      = "http://localhost:8081"
 
 	// Docker images for code execution
-	PythonDockerImage     = "mcr.microsoft.com/playwright/python:v1.53.0-noble"
+	PythonDockerImage     = "python:3.12-slim"
 	BashDockerImage       = "ubuntu:22.04"
 	TypeScriptDockerImage = "node:22-alpine"
 	GoDockerImage         = "golang:1.23"
 )
+
+// Config is the complete server configuration. Command-line flags override
+// values loaded from a configuration file, which override the defaults.
+type Config struct {
+	Transport TransportConfig `yaml:"transport" toml:"transport"`
+	Execution ExecutionConfig `yaml:"execution" toml:"execution"`
+	Images    ImageConfig     `yaml:"images" toml:"images"`
+	Limits    LimitsConfig    `yaml:"limits" toml:"limits"`
+	Policy    PolicyConfig    `yaml:"policy" toml:"policy"`
+	Logging   LoggingConfig   `yaml:"logging" toml:"logging"`
+	Cache     CacheConfig     `yaml:"cache" toml:"cache"`
+	Schedule  ScheduleConfig  `yaml:"schedule" toml:"schedule"`
+	Prompts   PromptsConfig   `yaml:"prompts" toml:"prompts"`
+
+	// Environments are named Docker-mode environments selected with the
+	// profile tool argument.
+	Environments map[string]EnvironmentConfig `yaml:"environments" toml:"environments"`
+
+	// Registries points dependency installation at package mirrors.
+	Registries RegistriesConfig `yaml:"registries" toml:"registries"`
+
+	// Quotas limit the executions of each client over a time window.
+	Quotas QuotaConfig `yaml:"quotas" toml:"quotas"`
+
+	// Output selects the post-processors cleaning up execution output.
+	Output OutputConfig `yaml:"output" toml:"output"`
+}
+
+// TransportConfig configures how clients connect to the server.
+type TransportConfig struct {
+	Mode        string   `yaml:"mode" toml:"mode"`                   // stdio, sse or http
+	SSEAddr     string   `yaml:"sse_addr" toml:"sse_addr"`           // Listen address of the SSE transport
+	HTTPAddr    string   `yaml:"http_addr" toml:"http_addr"`         // Listen address of the HTTP transport
+	AuthTokens  []string `yaml:"auth_tokens" toml:"auth_tokens"`     // Accepted bearer tokens / API keys
+	TLSCert     string   `yaml:"tls_cert" toml:"tls_cert"`           // Certificate file for HTTPS
+	TLSKey      string   `yaml:"tls_key" toml:"tls_key"`             // Private key file for HTTPS
+	TLSClientCA string   `yaml:"tls_client_ca" toml:"tls_client_ca"` // CA bundle enabling mutual TLS
+	CORSOrigins []string `yaml:"cors_origins" toml:"cors_origins"`   // Browser origins allowed to connect
+	BasePath    string   `yaml:"base_path" toml:"base_path"`         // URL prefix behind a reverse proxy
+	DebugAddr   string   `yaml:"debug_addr" toml:"debug_addr"`       // Listen address of the pprof/expvar endpoint; empty disables it
+}
+
+// ExecutionConfig configures how and which code execution tools run.
+type ExecutionConfig struct {
+	Mode          string   `yaml:"mode" toml:"mode"`                     // subprocess, docker, hybrid or nix
+	Tools         []string `yaml:"tools" toml:"tools"`                   // Enabled execute tools; empty enables all
+	ReadOnlyTools []string `yaml:"readonly_tools" toml:"readonly_tools"` // Languages also offered as execute-<language>-readonly tools
+	HistorySize   int      `yaml:"history_size" toml:"history_size"`     // Recent executions kept as resources
+	AutoFix       int      `yaml:"auto_fix" toml:"auto_fix"`             // Sampling-based repair attempts; 0 disables
+
+	// PythonInstaller installs Python modules: pip, uv for faster installs in
+	// Docker mode and module support via "uv run" in subprocess mode, or venv for
+	// subprocess module support in throwaway virtual environments.
+	PythonInstaller string `yaml:"python_installer" toml:"python_installer"`
+
+	// Binaries selects the binary running subprocess-mode code per language
+	// (python, bash, typescript, go): a name looked up in PATH or an absolute
+	// path. Unlisted languages use the first runtime found, e.g. python3, then
+	// python, then py.
+	Binaries map[string]string `yaml:"binaries" toml:"binaries"`
+
+	// RunAs runs subprocess and Nix executions as this host user, "user" or
+	// "user:group" as names or numeric IDs, which requires the server to run
+	// as root; empty runs them as the server user.
+	RunAs string `yaml:"run_as" toml:"run_as"`
+
+	// Nice is the nice value of subprocess and Nix executions, 1 to 19; 0
+	// runs them at the priority of the server. IOPriority is their IO
+	// priority on Linux: normal, low or idle.
+	Nice       int    `yaml:"nice" toml:"nice"`
+	IOPriority string `yaml:"io_priority" toml:"io_priority"`
+
+	// SessionModes lists the execution modes (subprocess, docker or nix) MCP
+	// sessions may select instead of Mode, when initializing or with the
+	// set-execution-mode tool; empty runs every session in Mode.
+	SessionModes []string `yaml:"session_modes" toml:"session_modes"`
+
+	// PersistentIdle is how long the persistent containers of hybrid mode
+	// live without executions.
+	PersistentIdle time.Duration `yaml:"persistent_idle" toml:"persistent_idle"`
+
+	// Timezone (TZ) and Locale (LANG and LC_ALL) are the defaults of
+	// executions whose call sets neither; empty keeps the environment's.
+	// FakeTimeLibrary is the path of libfaketime in the execution
+	// environment, enabling the fake_time argument.
+	Timezone        string `yaml:"timezone" toml:"timezone"`
+	Locale          string `yaml:"locale" toml:"locale"`
+	FakeTimeLibrary string `yaml:"faketime_library" toml:"faketime_library"`
+
+	// Offline refuses dependency installation and runs Docker executions
+	// without a network, for servers without network access.
+	Offline bool `yaml:"offline" toml:"offline"`
+
+	// DetectDependencies installs the third-party packages imported by Python
+	// and TypeScript code with the requested dependencies, in Docker mode and
+	// with the uv or venv Python installers in subprocess mode.
+	DetectDependencies bool `yaml:"detect_dependencies" toml:"detect_dependencies"`
+	// ImportPackages maps, per language, import names to the packages
+	// detection installs for them, extending the built-in table; an empty
+	// package never installs the import.
+	ImportPackages map[string]map[string]string `yaml:"import_packages" toml:"import_packages"`
+
+	// WorkspaceDir holds the named workspaces shared by the executions of a
+	// session and the artifacts directories collecting their reports; empty
+	// uses a directory below the system temporary directory. In Docker mode it
+	// must be visible to the Docker daemon.
+	WorkspaceDir string `yaml:"workspace_dir" toml:"workspace_dir"`
+
+	// WorkspaceIdle is how long the named workspaces of a session are kept
+	// without executions using them, also when its session never ends.
+	WorkspaceIdle time.Duration `yaml:"workspace_idle" toml:"workspace_idle"`
+
+	// GoCacheDir keeps the Go build and module caches of subprocess
+	// executions, so that they are shared by every execution and apart from
+	// those of the server user; empty uses the caches of the user running the
+	// code. Docker executions use registries.go_cache_volume instead.
+	GoCacheDir string `yaml:"go_cache_dir" toml:"go_cache_dir"`
+
+	// Env is injected into every execution; per-call env values take precedence.
+	Env map[string]string `yaml:"env" toml:"env"`
+	// EnvFiles are .env files read before Env. Relative paths are resolved
+	// against the directory of the configuration file.
+	EnvFiles []string `yaml:"env_files" toml:"env_files"`
+	// EnvPassthrough lists the server variables, names or prefixes ending in
+	// "*", passed to subprocess and Nix executions in addition to the locale,
+	// proxy and toolchain variables always passed; "*" passes the whole
+	// server environment.
+	EnvPassthrough []string `yaml:"env_passthrough" toml:"env_passthrough"`
+}
+
+// ImageConfig selects the Docker image used by each language in Docker mode.
+type ImageConfig struct {
+	Python     string `yaml:"python" toml:"python"`
+	Bash       string `yaml:"bash" toml:"bash"`
+	TypeScript string `yaml:"typescript" toml:"typescript"`
+	Go         string `yaml:"go" toml:"go"`
+
+	// Runtimes maps a language and a runtime_version tool argument to the image
+	// used for it, e.g. runtimes.python["3.10"] = "python:3.10-slim". A language
+	// listed in the configuration file replaces its default versions.
+	Runtimes map[string]map[string]string `yaml:"runtimes" toml:"runtimes"`
+
+	// Browser maps python and typescript to the image of the executions
+	// importing or installing a browser automation package such as
+	// Playwright, which every other execution is spared. An empty image runs
+	// them in the language image.
+	Browser map[string]string `yaml:"browser" toml:"browser"`
+
+	// Preinstall lists the packages baked into an image derived from the image
+	// of a language, e.g. preinstall.python = [pandas, numpy], which executions
+	// run in. build-images builds it ahead; the first execution otherwise.
+	Preinstall map[string][]string `yaml:"preinstall" toml:"preinstall"`
+
+	// Commands overrides the commands of the images of a language, e.g. to
+	// install without dependencies or through a wrapper script.
+	Commands map[string]CommandConfig `yaml:"commands" toml:"commands"`
+}
+
+// CommandConfig overrides the commands run in the image of a language. Empty
+// commands keep the defaults.
+type CommandConfig struct {
+	// Install holds the shell words installing dependencies, which follow as
+	// arguments after "--", e.g. [pip, install, --no-deps]; shell syntax such
+	// as && works.
+	Install []string `yaml:"install" toml:"install"`
+
+	// Execute is the program and arguments running the code read on stdin,
+	// e.g. [python, -u]. Executions with stdin_from pass the path of the code
+	// file as the last argument instead.
+	Execute []string `yaml:"execute" toml:"execute"`
+}
+
+// EnvironmentConfig is a named, pre-baked Docker-mode execution environment of
+// one language.
+type EnvironmentConfig struct {
+	Language    string            `yaml:"language" toml:"language"` // python, bash, typescript or go
+	Description string            `yaml:"description" toml:"description"`
+	Image       string            `yaml:"image" toml:"image"`       // Base image; empty uses the language image
+	Packages    []string          `yaml:"packages" toml:"packages"` // Installed into a derived image on first use
+	Env         map[string]string `yaml:"env" toml:"env"`           // Set unless the call or execution.env sets them
+	Memory      string            `yaml:"memory" toml:"memory"`     // Overrides limits.memory
+	CPUs        string            `yaml:"cpus" toml:"cpus"`         // Overrides limits.cpus
+}
+
+// DefaultRuntimeImages returns the runtime_version images available by default.
+func DefaultRuntimeImages() map[string]map[string]string {
+	return map[string]map[string]string{
+		"python": {
+			"3.10": "python:3.10-slim",
+			"3.11": "python:3.11-slim",
+			"3.12": "python:3.12-slim",
+			"3.13": "python:3.13-slim",
+		},
+		"typescript": {
+			"20": "node:20-alpine",
+			"22": "node:22-alpine",
+		},
+		"go": {
+			"1.22": "golang:1.22",
+			"1.23": "golang:1.23",
+			"1.24": "golang:1.24",
+		},
+	}
+}
+
+// LimitsConfig caps execution resources. Empty memory and CPU values leave Docker's
+// defaults; zero timeouts and counts disable the corresponding limit.
+type LimitsConfig struct {
+	Memory string `yaml:"memory" toml:"memory"` // docker run --memory, e.g. "512m" (Docker mode only)
+	CPUs   string `yaml:"cpus" toml:"cpus"`     // docker run --cpus, e.g. "1.5" (Docker mode only)
+
+	Timeout        time.Duration `yaml:"timeout" toml:"timeout"`                 // Default per-execution timeout
+	MaxTimeout     time.Duration `yaml:"max_timeout" toml:"max_timeout"`         // Ceiling for per-call timeouts
+	InstallTimeout time.Duration `yaml:"install_timeout" toml:"install_timeout"` // Dependency installation limit, on top of the timeout
+
+	MaxConcurrent int `yaml:"max_concurrent" toml:"max_concurrent"` // Executions running at once; further calls wait in the queue
+	MaxQueued     int `yaml:"max_queued" toml:"max_queued"`         // Calls waiting for a slot before new calls are rejected
+
+	// PreemptAfter lets interactive calls waiting for a slot cancel batch
+	// executions that have run this long, which are then queued again. Zero
+	// disables preemption.
+	PreemptAfter time.Duration `yaml:"preempt_after" toml:"preempt_after"`
+
+	MaxCodeSize int `yaml:"max_code_size" toml:"max_code_size"` // Bytes of code accepted per execution
+
+	// MaxDiskMB bounds the scratch and artifacts directories of an execution,
+	// and sizes the tmpfs holding the working directory in Docker mode.
+	// MaxWorkspaceMB bounds the named workspaces of an MCP session. Zero
+	// disables each limit.
+	MaxDiskMB      int `yaml:"max_disk_mb" toml:"max_disk_mb"`
+	MaxWorkspaceMB int `yaml:"max_workspace_mb" toml:"max_workspace_mb"`
+
+	// ContainerMaxLifetime is how long an execution container may exist before
+	// a background reaper kills it, including containers left behind by a
+	// crashed server (Docker mode only). Zero disables the reaper.
+	ContainerMaxLifetime time.Duration `yaml:"container_max_lifetime" toml:"container_max_lifetime"`
+}
+
+// RegistriesConfig selects package mirrors for air-gapped or corporate networks.
+// Empty values keep the public registries.
+type RegistriesConfig struct {
+	PyPIIndexURL       string   `yaml:"pypi_index_url" toml:"pypi_index_url"`               // pip and uv index, e.g. https://pypi.internal/simple
+	PyPIExtraIndexURLs []string `yaml:"pypi_extra_index_urls" toml:"pypi_extra_index_urls"` // Searched in addition to the index
+	NPMRegistry        string   `yaml:"npm_registry" toml:"npm_registry"`                   // npm registry, e.g. https://npm.internal
+	GoProxy            string   `yaml:"goproxy" toml:"goproxy"`                             // GOPROXY list, e.g. https://goproxy.internal,direct
+	APTMirror          string   `yaml:"apt_mirror" toml:"apt_mirror"`                       // Ubuntu archive mirror for the Docker bash image
+	APTCacheVolume     string   `yaml:"apt_cache_volume" toml:"apt_cache_volume"`           // Docker volume sharing apt downloads between bash installs
+	GoCacheVolume      string   `yaml:"go_cache_volume" toml:"go_cache_volume"`             // Docker volume keeping the Go build and module caches
+}
+
+// QuotaConfig limits what each client, identified by its auth token, may run
+// within a sliding window. Zero values disable a limit.
+type QuotaConfig struct {
+	Window        time.Duration `yaml:"window" toml:"window"`                   // Sliding window the limits apply to
+	MaxExecutions int           `yaml:"max_executions" toml:"max_executions"`   // Executions started in the window
+	MaxCPUSeconds int           `yaml:"max_cpu_seconds" toml:"max_cpu_seconds"` // CPU time of the window's executions
+	MaxDownloadMB int           `yaml:"max_download_mb" toml:"max_download_mb"` // Network downloads of the window's executions (Docker mode only)
+}
+
+// OutputConfig selects the post-processors applied, in order, to the output of
+// the execute tools: strip_ansi, collapse_progress, limit_repeats and
+// pretty_json.
+type OutputConfig struct {
+	Processors []string            `yaml:"processors" toml:"processors"` // Applied to every execute tool
+	Tools      map[string][]string `yaml:"tools" toml:"tools"`           // Per language, replacing processors
+
+	// MaxInline is the size in bytes of the longest output returned in tool
+	// results; longer output is previewed and read in full from its output://
+	// resource. Zero returns all output.
+	MaxInline int `yaml:"max_inline" toml:"max_inline"`
+}
+
+// ProcessorsFor returns the post-processors of the execute tool of language.
+func (o OutputConfig) ProcessorsFor(language string) []string {
+	if processors, ok := o.Tools[language]; ok {
+		return processors
+	}
+	return o.Processors
+}
+
+// PolicyConfig holds security policies for executions.
+type PolicyConfig struct {
+	AllowedMounts []string `yaml:"allowed_mounts" toml:"allowed_mounts"` // Host directories Docker tools may mount
+
+	// InstallUnmappedImports lets dependency detection install imports missing
+	// from the package tables under their own name, which may belong to an
+	// unrelated or malicious package.
+	InstallUnmappedImports bool `yaml:"install_unmapped_imports" toml:"install_unmapped_imports"`
+
+	// AllowUnconfirmedPrivileges runs host mounts, host networking and
+	// sudo-like subprocess scripts for clients that cannot confirm them through
+	// elicitation; such calls are refused otherwise.
+	AllowUnconfirmedPrivileges bool `yaml:"allow_unconfirmed_privileges" toml:"allow_unconfirmed_privileges"`
+}
+
+// LoggingConfig configures server logging.
+type LoggingConfig struct {
+	Verbose bool `yaml:"verbose" toml:"verbose"`
+
+	// File receives the logs instead of stderr when set; it is rotated when it
+	// exceeds MaxSizeMB or has been written to for RotateInterval.
+	File           string        `yaml:"file" toml:"file"`
+	MaxSizeMB      int           `yaml:"max_size_mb" toml:"max_size_mb"`         // 0 disables size-based rotation
+	RotateInterval time.Duration `yaml:"rotate_interval" toml:"rotate_interval"` // 0 disables time-based rotation
+	MaxBackups     int           `yaml:"max_backups" toml:"max_backups"`         // Rotated files kept; 0 keeps all
+}
+
+// CacheConfig enables returning cached output for identical repeat executions.
+type CacheConfig struct {
+	TTL        time.Duration `yaml:"ttl" toml:"ttl"`                 // How long results are reused; 0 disables the cache
+	MaxEntries int           `yaml:"max_entries" toml:"max_entries"` // Results kept in memory
+	// Dir persists results across restarts when set. Relative paths are resolved
+	// against the directory of the configuration file.
+	Dir string `yaml:"dir" toml:"dir"`
+}
+
+// ScheduleConfig enables the tools running stored executions on cron schedules.
+type ScheduleConfig struct {
+	Enabled      bool `yaml:"enabled" toml:"enabled"`             // Register schedule-execution, list-schedules and cancel-schedule
+	MaxSchedules int  `yaml:"max_schedules" toml:"max_schedules"` // Schedules allowed at once
+	KeepResults  int  `yaml:"keep_results" toml:"keep_results"`   // Results kept per schedule
+}
+
+// PromptsConfig selects the prompts served in addition to the execution mode's
+// own restrictions.
+type PromptsConfig struct {
+	Disabled []string `yaml:"disabled" toml:"disabled"` // Prompt names not to register, e.g. security-audit
+}
+
+// Default returns the configuration used when no configuration file is given.
+func Default() Config {
+	return Config{
+		Transport: TransportConfig{
+			Mode:     "stdio",
+			SSEAddr:  SSEPort,
+			HTTPAddr: HTTPPort,
+		},
+		Execution: ExecutionConfig{
+			Mode:        "subprocess",
+			HistorySize: history.DefaultCapacity,
+
+			PythonInstaller: "pip",
+			Nice:            executor.DefaultNice,
+			IOPriority:      executor.DefaultIOPriority,
+			PersistentIdle:  executor.DefaultPersistentIdle,
+			WorkspaceIdle:   executor.DefaultWorkspaceIdle,
+		},
+		Images: ImageConfig{
+			Python:     PythonDockerImage,
+			Bash:       BashDockerImage,
+			TypeScript: TypeScriptDockerImage,
+			Go:         GoDockerImage,
+			Runtimes:   DefaultRuntimeImages(),
+			Browser:    map[string]string{"python": executor.BrowserImage},
+		},
+		Limits: LimitsConfig{
+			MaxCodeSize: executor.DefaultMaxCodeSize,
+		},
+		Logging: LoggingConfig{
+			MaxSizeMB:  100,
+			MaxBackups: 5,
+		},
+		Output: OutputConfig{
+			MaxInline: history.DefaultMaxInline,
+		},
+		Cache: CacheConfig{
+			MaxEntries: cache.DefaultMaxEntries,
+		},
+		Schedule: ScheduleConfig{
+			MaxSchedules: schedule.DefaultMaxSchedules,
+			KeepResults:  schedule.DefaultKeepResults,
+		},
+		Quotas: QuotaConfig{
+			Window: quota.DefaultWindow,
+		},
+	}
+}
+
+// Validate reports the first invalid setting in c.
+func (c Config) Validate() error {
+	switch c.Transport.Mode {
+	case "stdio", "sse", "http":
+	default:
+		return fmt.Errorf("transport.mode: unknown mode %q (expected stdio, sse or http)", c.Transport.Mode)
+	}
+	switch c.Execution.Mode {
+	case "subprocess", "docker", "hybrid", "nix":
+	default:
+		return fmt.Errorf("execution.mode: unknown mode %q (expected subprocess, docker, hybrid or nix)", c.Execution.Mode)
+	}
+	for _, mode := range c.Execution.SessionModes {
+		switch mode {
+		case "subprocess", "docker", "nix", c.Execution.Mode:
+		default:
+			return fmt.Errorf("execution.session_modes: unknown mode %q (expected subprocess, docker or nix; hybrid only on hybrid servers)", mode)
+		}
+	}
+	if c.Execution.PersistentIdle < 0 {
+		return fmt.Errorf("execution.persistent_idle: must not be negative")
+	}
+	if c.Execution.WorkspaceIdle < 0 {
+		return fmt.Errorf("execution.workspace_idle: must not be negative")
+	}
+	if c.Execution.HistorySize < 0 {
+		return fmt.Errorf("execution.history_size: must not be negative")
+	}
+	if c.Execution.AutoFix < 0 {
+		return fmt.Errorf("execution.auto_fix: must not be negative")
+	}
+	switch c.Execution.PythonInstaller {
+	case "pip", "uv", "venv":
+	default:
+		return fmt.Errorf("execution.python_installer: unknown installer %q (expected pip, uv or venv)", c.Execution.PythonInstaller)
+	}
+	if c.Limits.Timeout < 0 || c.Limits.MaxTimeout < 0 || c.Limits.InstallTimeout < 0 || c.Limits.ContainerMaxLifetime < 0 || c.Limits.PreemptAfter < 0 {
+		return fmt.Errorf("limits: timeouts, container_max_lifetime and preempt_after must not be negative")
+	}
+	if c.Limits.MaxConcurrent < 0 || c.Limits.MaxQueued < 0 || c.Limits.MaxCodeSize < 0 || c.Limits.MaxDiskMB < 0 || c.Limits.MaxWorkspaceMB < 0 {
+		return fmt.Errorf("limits: max_concurrent, max_queued, max_code_size, max_disk_mb and max_workspace_mb must not be negative")
+	}
+	if c.Quotas.Window < 0 || c.Quotas.MaxExecutions < 0 || c.Quotas.MaxCPUSeconds < 0 || c.Quotas.MaxDownloadMB < 0 {
+		return fmt.Errorf("quotas: window and limits must not be negative")
+	}
+	if c.Limits.MaxTimeout > 0 && c.Limits.Timeout > c.Limits.MaxTimeout {
+		return fmt.Errorf("limits.timeout: %s exceeds limits.max_timeout %s", c.Limits.Timeout, c.Limits.MaxTimeout)
+	}
+	if c.Logging.MaxSizeMB < 0 || c.Logging.RotateInterval < 0 || c.Logging.MaxBackups < 0 {
+		return fmt.Errorf("logging: rotation settings must not be negative")
+	}
+	if c.Cache.TTL < 0 || c.Cache.MaxEntries < 0 {
+		return fmt.Errorf("cache: ttl and max_entries must not be negative")
+	}
+	if c.Schedule.MaxSchedules < 0 || c.Schedule.KeepResults < 0 {
+		return fmt.Errorf("schedule: max_schedules and keep_results must not be negative")
+	}
+	if (c.Transport.TLSCert == "") != (c.Transport.TLSKey == "") {
+		return fmt.Errorf("transport: tls_cert and tls_key must be set together")
+	}
+	if err := validateAddrs(c.Transport.SSEAddr, c.Transport.HTTPAddr); err != nil {
+		return err
+	}
+	if err := validateDebugAddr(c.Transport.DebugAddr, c.Transport.SSEAddr, c.Transport.HTTPAddr); err != nil {
+		return err
+	}
+	for name, image := range map[string]string{
+		"python":     c.Images.Python,
+		"bash":       c.Images.Bash,
+		"typescript": c.Images.TypeScript,
+		"go":         c.Images.Go,
+	} {
+		if !imageReference.MatchString(image) {
+			return fmt.Errorf("images.%s: invalid image reference %q", name, image)
+		}
+	}
+	for language, versions := range c.Images.Runtimes {
+		switch language {
+		case "python", "bash", "typescript", "go":
+		default:
+			return fmt.Errorf("images.runtimes: unknown language %q (expected python, bash, typescript or go)", language)
+		}
+		for version, image := range versions {
+			if !imageReference.MatchString(image) {
+				return fmt.Errorf("images.runtimes.%s.%s: invalid image reference %q", language, version, image)
+			}
+		}
+	}
+	for language, image := range c.Images.Browser {
+		switch language {
+		case "python", "typescript":
+		default:
+			return fmt.Errorf("images.browser: unsupported language %q (expected python or typescript)", language)
+		}
+		if image != "" && !imageReference.MatchString(image) {
+			return fmt.Errorf("images.browser.%s: invalid image reference %q", language, image)
+		}
+	}
+	for language, packages := range c.Images.Preinstall {
+		switch language {
+		case "python", "bash", "typescript", "go":
+		default:
+			return fmt.Errorf("images.preinstall: unknown language %q (expected python, bash, typescript or go)", language)
+		}
+		if _, err := executor.ValidateDependencies(language, packages); err != nil {
+			return fmt.Errorf("images.preinstall.%s: %v", language, err)
+		}
+	}
+	for language, commands := range c.Images.Commands {
+		switch language {
+		case "python", "bash", "typescript", "go":
+		default:
+			return fmt.Errorf("images.commands: unknown language %q (expected python, bash, typescript or go)", language)
+		}
+		for _, word := range append(slices.Clone(commands.Install), commands.Execute...) {
+			if strings.TrimSpace(word) == "" {
+				return fmt.Errorf("images.commands.%s: commands must not contain empty words", language)
+			}
+		}
+	}
+	for language, binary := range c.Execution.Binaries {
+		switch language {
+		case "python", "bash", "typescript", "go":
+		default:
+			return fmt.Errorf("execution.binaries: unknown language %q (expected python, bash, typescript or go)", language)
+		}
+		if binary == "" || (strings.ContainsRune(binary, filepath.Separator) && !filepath.IsAbs(binary)) {
+			return fmt.Errorf("execution.binaries.%s: %q must be a command name or an absolute path", language, binary)
+		}
+	}
+	for language, packages := range c.Execution.ImportPackages {
+		switch language {
+		case "python", "typescript":
+		default:
+			return fmt.Errorf("execution.import_packages: unsupported language %q (expected python or typescript)", language)
+		}
+		for module, pkg := range packages {
+			if pkg == "" {
+				continue
+			}
+			if _, err := executor.ValidateDependencies(language, []string{pkg}); err != nil {
+				return fmt.Errorf("execution.import_packages.%s.%s: %v", language, module, err)
+			}
+		}
+	}
+	for name, environment := range c.Environments {
+		if !environmentName.MatchString(name) {
+			return fmt.Errorf("environments: invalid name %q (lowercase letters, digits, '.', '_' and '-')", name)
+		}
+		switch environment.Language {
+		case "python", "bash", "typescript", "go":
+		default:
+			return fmt.Errorf("environments.%s.language: unknown language %q (expected python, bash, typescript or go)", name, environment.Language)
+		}
+		if environment.Image != "" && !imageReference.MatchString(environment.Image) {
+			return fmt.Errorf("environments.%s.image: invalid image reference %q", name, environment.Image)
+		}
+		if _, err := executor.ValidateDependencies(environment.Language, environment.Packages); err != nil {
+			return fmt.Errorf("environments.%s.packages: %v", name, err)
+		}
+		for key := range environment.Env {
+			if !envName.MatchString(key) {
+				return fmt.Errorf("environments.%s.env: invalid variable name %q", name, key)
+			}
+		}
+	}
+	if c.Output.MaxInline < 0 {
+		return fmt.Errorf("output.max_inline: must not be negative")
+	}
+	if err := executor.ValidateOutputProcessors(c.Output.Processors); err != nil {
+		return fmt.Errorf("output.processors: %v", err)
+	}
+	for language, processors := range c.Output.Tools {
+		switch language {
+		case "python", "bash", "typescript", "go":
+		default:
+			return fmt.Errorf("output.tools: unknown language %q (expected python, bash, typescript or go)", language)
+		}
+		if err := executor.ValidateOutputProcessors(processors); err != nil {
+			return fmt.Errorf("output.tools.%s: %v", language, err)
+		}
+	}
+	if err := validateRegistries(c.Registries); err != nil {
+		return err
+	}
+	for key := range c.Execution.Env {
+		if !envName.MatchString(key) {
+			return fmt.Errorf("execution.env: invalid variable name %q", key)
+		}
+	}
+	if err := executor.ValidatePriority(executor.Priority{Nice: c.Execution.Nice, IOPriority: c.Execution.IOPriority}); err != nil {
+		return fmt.Errorf("execution: %v", err)
+	}
+	if c.Execution.Timezone != "" {
+		if err := executor.CheckTimezone(c.Execution.Timezone); err != nil {
+			return fmt.Errorf("execution.timezone: %v", err)
+		}
+	}
+	if c.Execution.Locale != "" {
+		if err := executor.CheckLocale(c.Execution.Locale); err != nil {
+			return fmt.Errorf("execution.locale: %v", err)
+		}
+	}
+	if c.Execution.FakeTimeLibrary != "" && !path.IsAbs(c.Execution.FakeTimeLibrary) {
+		return fmt.Errorf("execution.faketime_library: %q must be an absolute path", c.Execution.FakeTimeLibrary)
+	}
+	for _, pattern := range c.Execution.EnvPassthrough {
+		if pattern != executor.PassAllEnv && !envName.MatchString(strings.TrimSuffix(pattern, "*")) {
+			return fmt.Errorf("execution.env_passthrough: invalid variable name or prefix %q", pattern)
+		}
+	}
+	for _, root := range c.Policy.AllowedMounts {
+		if !filepath.IsAbs(root) {
+			return fmt.Errorf("policy.allowed_mounts: %q must be an absolute path", root)
+		}
+		if filepath.Clean(root) == string(filepath.Separator) {
+			return fmt.Errorf("policy.allowed_mounts: allowing the filesystem root exposes the whole host")
+		}
+	}
+	return nil
+}
+
+// Warnings reports settings that are valid but probably unintended.
+func (c Config) Warnings() []string {
+	var warnings []string
+	network := c.Transport.Mode == "sse" || c.Transport.Mode == "http"
+	if network && len(c.Transport.AuthTokens) == 0 {
+		warnings = append(warnings, "transport.auth_tokens: no tokens configured, anyone who can reach the server can execute code (unless set via the environment)")
+	}
+	if network && c.Transport.TLSCert == "" {
+		warnings = append(warnings, "transport.tls_cert: TLS is disabled, tokens and code are sent in plain text")
+	}
+	if slices.Contains(c.Transport.CORSOrigins, "*") {
+		warnings = append(warnings, "transport.cors_origins: '*' lets any website call the server from a browser")
+	}
+	if c.Transport.DebugAddr != "" && !network {
+		warnings = append(warnings, "transport.debug_addr: ignored in stdio transport mode")
+	}
+	if c.Transport.DebugAddr != "" && network && len(c.Transport.AuthTokens) == 0 {
+		warnings = append(warnings, "transport.debug_addr: without auth tokens the debug endpoint only starts on a loopback address")
+	}
+	if c.Transport.TLSClientCA != "" && c.Transport.TLSCert == "" {
+		warnings = append(warnings, "transport.tls_client_ca: ignored because TLS is disabled")
+	}
+	if c.Execution.Offline && c.Execution.DetectDependencies {
+		warnings = append(warnings, "execution.detect_dependencies: ignored because the server runs offline")
+	}
+	if c.Execution.Offline && !executor.ContainerMode(c.Execution.Mode) {
+		warnings = append(warnings, "execution.offline: only docker mode takes the network away from executions; host processes keep the host's network")
+	}
+	if c.Execution.DetectDependencies && (c.Execution.Mode == "nix" || (c.Execution.Mode == "subprocess" && c.Execution.PythonInstaller == "pip")) {
+		warnings = append(warnings, "execution.detect_dependencies: only used in docker execution mode and for Python with the uv or venv installers in subprocess mode")
+	}
+	if !executor.ContainerMode(c.Execution.Mode) {
+		if len(c.Policy.AllowedMounts) > 0 {
+			warnings = append(warnings, "policy.allowed_mounts: only used in docker execution mode")
+		}
+		if len(c.Environments) > 0 {
+			warnings = append(warnings, "environments: only available in docker execution mode")
+		}
+		if c.Limits.Memory != "" || c.Limits.CPUs != "" || c.Limits.ContainerMaxLifetime > 0 {
+			warnings = append(warnings, "limits: memory, cpus and container_max_lifetime are only enforced in docker execution mode")
+		}
+		if c.Quotas.MaxDownloadMB > 0 {
+			warnings = append(warnings, "quotas.max_download_mb: downloads are only measured in docker execution mode")
+		}
+	}
+	if c.Execution.RunAs != "" && executor.ContainerMode(c.Execution.Mode) {
+		warnings = append(warnings, "execution.run_as: only used in subprocess and nix execution modes")
+	}
+	if len(c.Execution.EnvPassthrough) > 0 && executor.ContainerMode(c.Execution.Mode) {
+		warnings = append(warnings, "execution.env_passthrough: only used in subprocess and nix execution modes")
+	}
+	if lifetime := c.Limits.ContainerMaxLifetime; lifetime > 0 && (c.Limits.MaxTimeout == 0 || c.Limits.MaxTimeout > lifetime) {
+		warnings = append(warnings, fmt.Sprintf("limits.container_max_lifetime: executions allowed to run longer than %s are killed by the reaper", lifetime))
+	}
+	if c.Limits.PreemptAfter > 0 && c.Limits.MaxConcurrent == 0 {
+		warnings = append(warnings, "limits.preempt_after: ignored because limits.max_concurrent is 0 (executions never wait)")
+	}
+	if c.Cache.TTL == 0 && c.Cache.Dir != "" {
+		warnings = append(warnings, "cache.dir: ignored because cache.ttl is 0 (caching disabled)")
+	}
+	for _, root := range c.Policy.AllowedMounts {
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			warnings = append(warnings, fmt.Sprintf("policy.allowed_mounts: %q is not an existing directory on this host", root))
+		}
+	}
+	return warnings
+}
+
+// environmentName matches environment names, which are part of the image
+// names built for them.
+var environmentName = regexp.MustCompile(`^[a-z0-9]+(?:[._-][a-z0-9]+)*$`)
+
+// volumeName matches the names of Docker volumes.
+var volumeName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+
+// imageReference matches Docker image references such as "ubuntu:22.04" or
+// "registry:5000/team/image:tag@sha256:<digest>".
+var imageReference = regexp.MustCompile(`^(?:[a-zA-Z0-9.-]+(?::[0-9]+)?/)?[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*(?::[\w][\w.-]{0,127})?(?:@sha256:[a-f0-9]{64})?$`)
+
+// validateAddrs checks that the SSE and HTTP listen addresses are valid and do not collide.
+func validateAddrs(sseAddr, httpAddr string) error {
+	sseHost, ssePort, err := net.SplitHostPort(sseAddr)
+	if err != nil {
+		return fmt.Errorf("transport.sse_addr: invalid address %q: %v", sseAddr, err)
+	}
+	httpHost, httpPort, err := net.SplitHostPort(httpAddr)
+	if err != nil {
+		return fmt.Errorf("transport.http_addr: invalid address %q: %v", httpAddr, err)
+	}
+	if ssePort == httpPort && (sseHost == httpHost || wildcardHost(sseHost) || wildcardHost(httpHost)) {
+		return fmt.Errorf("transport: sse_addr and http_addr both use port %s", ssePort)
+	}
+	return nil
+}
+
+// validateDebugAddr checks that the debug listen address, when set, is valid and
+// does not collide with the transport addresses.
+func validateDebugAddr(debugAddr, sseAddr, httpAddr string) error {
+	if debugAddr == "" {
+		return nil
+	}
+	debugHost, debugPort, err := net.SplitHostPort(debugAddr)
+	if err != nil {
+		return fmt.Errorf("transport.debug_addr: invalid address %q: %v", debugAddr, err)
+	}
+	for name, addr := range map[string]string{"sse_addr": sseAddr, "http_addr": httpAddr} {
+		host, port, _ := net.SplitHostPort(addr)
+		if port == debugPort && (host == debugHost || wildcardHost(host) || wildcardHost(debugHost)) {
+			return fmt.Errorf("transport: debug_addr and %s both use port %s", name, port)
+		}
+	}
+	return nil
+}
+
+func wildcardHost(host string) bool {
+	return host == "" || host == "0.0.0.0" || host == "::"
+}
+
+// validateRegistries checks that the registries are http(s) URLs. The APT mirror
+// is written into a sed script, so it must not contain characters special to sed
+// or the shell.
+func validateRegistries(r RegistriesConfig) error {
+	type setting struct{ name, value string }
+	settings := []setting{
+		{"registries.pypi_index_url", r.PyPIIndexURL},
+		{"registries.npm_registry", r.NPMRegistry},
+		{"registries.apt_mirror", r.APTMirror},
+	}
+	for _, extra := range r.PyPIExtraIndexURLs {
+		settings = append(settings, setting{"registries.pypi_extra_index_urls", extra})
+	}
+	// GOPROXY is a list separated by commas and pipes, which may include direct and off
+	for _, entry := range strings.FieldsFunc(r.GoProxy, func(c rune) bool { return c == ',' || c == '|' }) {
+		if entry != "direct" && entry != "off" {
+			settings = append(settings, setting{"registries.goproxy", entry})
+		}
+	}
+	for _, s := range settings {
+		if s.value == "" {
+			continue
+		}
+		u, err := url.Parse(s.value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s: %q is not an http or https URL", s.name, s.value)
+		}
+	}
+	if strings.ContainsAny(r.APTMirror, "|&\\'\" \t$`;") {
+		return fmt.Errorf("registries.apt_mirror: %q contains characters not allowed in a mirror URL", r.APTMirror)
+	}
+	if r.APTCacheVolume != "" && !volumeName.MatchString(r.APTCacheVolume) {
+		return fmt.Errorf("registries.apt_cache_volume: %q is not a Docker volume name", r.APTCacheVolume)
+	}
+	if r.GoCacheVolume != "" && !volumeName.MatchString(r.GoCacheVolume) {
+		return fmt.Errorf("registries.go_cache_volume: %q is not a Docker volume name", r.GoCacheVolume)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Package config reads the default environment variables injected into every
+// execution from the configuration file and .env files.
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// envName matches valid environment variable names.
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// DefaultEnv returns the environment variables injected into every execution:
+// the .env files in order, then the inline env entries, later values winning.
+func (e ExecutionConfig) DefaultEnv() (map[string]string, error) {
+	env := make(map[string]string)
+	for _, path := range e.EnvFiles {
+		fileEnv, err := ReadEnvFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range fileEnv {
+			env[key] = value
+		}
+	}
+	for key, value := range e.Env {
+		env[key] = value
+	}
+	return env, nil
+}
+
+// ReadEnvFile parses a .env file of KEY=VALUE lines. Blank lines, comments and an
+// optional "export " prefix are ignored, and matching surrounding quotes are removed.
+func ReadEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read env file: %v", err)
+	}
+	defer file.Close()
+
+	env := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !envName.MatchString(key) {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, lineNumber)
+		}
+		env[key] = unquote(strings.TrimSpace(value))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read env file %s: %v", path, err)
+	}
+	return env, nil
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
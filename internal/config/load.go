@@ -0,0 +1,200 @@
+// Package config loads YAML and TOML configuration files on top of the defaults,
+// optionally overlaying a named profile.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// yamlFile is the layout of a YAML configuration file: the base settings plus
+// named profiles holding partial settings.
+type yamlFile struct {
+	Config   `yaml:",inline"`
+	Profiles map[string]yaml.Node `yaml:"profiles"`
+}
+
+// tomlFile is the layout of a TOML configuration file.
+type tomlFile struct {
+	Config
+	Profiles map[string]toml.Primitive `toml:"profiles"`
+}
+
+// Load reads the configuration file at path over the defaults, overlays the named
+// profile (if any) and validates the result. The format is chosen by extension
+// (.yaml, .yml or .toml). An empty path returns the defaults.
+func Load(path, profile string) (Config, error) {
+	cfg := Default()
+	if path == "" {
+		if profile != "" {
+			return Config{}, fmt.Errorf("profile %q requires a config file", profile)
+		}
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read config file: %v", err)
+	}
+	if err := decode(path, data, profile, &cfg); err != nil {
+		return Config{}, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	for i, envFile := range cfg.Execution.EnvFiles {
+		if !filepath.IsAbs(envFile) {
+			cfg.Execution.EnvFiles[i] = filepath.Join(filepath.Dir(path), envFile)
+		}
+	}
+	if cfg.Logging.File != "" && !filepath.IsAbs(cfg.Logging.File) {
+		cfg.Logging.File = filepath.Join(filepath.Dir(path), cfg.Logging.File)
+	}
+	if cfg.Execution.WorkspaceDir != "" && !filepath.IsAbs(cfg.Execution.WorkspaceDir) {
+		cfg.Execution.WorkspaceDir = filepath.Join(filepath.Dir(path), cfg.Execution.WorkspaceDir)
+	}
+	if cfg.Execution.GoCacheDir != "" && !filepath.IsAbs(cfg.Execution.GoCacheDir) {
+		cfg.Execution.GoCacheDir = filepath.Join(filepath.Dir(path), cfg.Execution.GoCacheDir)
+	}
+	if cfg.Cache.Dir != "" && !filepath.IsAbs(cfg.Cache.Dir) {
+		cfg.Cache.Dir = filepath.Join(filepath.Dir(path), cfg.Cache.Dir)
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// decode parses data into cfg and overlays profile, rejecting unknown keys so typos
+// are not silently ignored.
+func decode(path string, data []byte, profile string, cfg *Config) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return decodeYAML(data, profile, cfg)
+	case ".toml":
+		return decodeTOML(data, profile, cfg)
+	default:
+		return fmt.Errorf("unsupported format %q (expected .yaml, .yml or .toml)", filepath.Ext(path))
+	}
+}
+
+func decodeYAML(data []byte, profile string, cfg *Config) error {
+	file := yamlFile{Config: *cfg}
+	if err := strictYAML(data, &file); err != nil {
+		return err
+	}
+	*cfg = file.Config
+	if profile == "" {
+		return nil
+	}
+
+	node, ok := file.Profiles[profile]
+	if !ok {
+		return unknownProfile(profile, file.Profiles)
+	}
+	// Re-encode the profile so it is decoded with the same unknown-key checks.
+	overlay, err := yaml.Marshal(&node)
+	if err != nil {
+		return fmt.Errorf("profile %q: %v", profile, err)
+	}
+	if err := strictYAML(overlay, cfg); err != nil {
+		return fmt.Errorf("profile %q: %v", profile, err)
+	}
+	return nil
+}
+
+func strictYAML(data []byte, out any) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+func decodeTOML(data []byte, profile string, cfg *Config) error {
+	file := tomlFile{Config: *cfg}
+	meta, err := toml.Decode(string(data), &file)
+	if err != nil {
+		return err
+	}
+	*cfg = file.Config
+
+	if profile != "" {
+		primitive, ok := file.Profiles[profile]
+		if !ok {
+			return unknownProfile(profile, file.Profiles)
+		}
+		if err := meta.PrimitiveDecode(primitive, cfg); err != nil {
+			return fmt.Errorf("profile %q: %v", profile, err)
+		}
+	}
+
+	for _, key := range meta.Undecoded() {
+		// Keys of profiles that were not selected are never decoded.
+		if len(key) > 1 && key[0] == "profiles" && key[1] != profile {
+			continue
+		}
+		return fmt.Errorf("unknown key %q", key.String())
+	}
+	return nil
+}
+
+// ProfileNames returns the sorted names of the profiles defined in the
+// configuration file at path.
+func ProfileNames(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	var profiles map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var file struct {
+			Profiles map[string]any `yaml:"profiles"`
+		}
+		err = yaml.Unmarshal(data, &file)
+		profiles = file.Profiles
+	case ".toml":
+		var file struct {
+			Profiles map[string]any `toml:"profiles"`
+		}
+		_, err = toml.Decode(string(data), &file)
+		profiles = file.Profiles
+	default:
+		err = fmt.Errorf("unsupported format %q (expected .yaml, .yml or .toml)", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// unknownProfile reports a missing profile along with the available ones.
+func unknownProfile[T any](profile string, profiles map[string]T) error {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return fmt.Errorf("unknown profile %q: no profiles defined", profile)
+	}
+	return fmt.Errorf("unknown profile %q (available: %s)", profile, strings.Join(names, ", "))
+}
@@ -0,0 +1,296 @@
+// Package config renders the commented default configuration file written by
+// "mcp-executor config init".
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Template returns a commented YAML configuration file holding the defaults.
+func Template() string {
+	d := Default()
+	return fmt.Sprintf(`# mcp-executor configuration.
+# Command-line flags override the values below; unset keys keep their defaults.
+
+transport:
+  # How clients connect: stdio, sse or http.
+  mode: %s
+  # Listen addresses of the SSE and HTTP transports.
+  sse_addr: "%s"
+  http_addr: "%s"
+  # Bearer tokens / API keys required by SSE and HTTP (also read from MCP_EXECUTOR_AUTH_TOKENS).
+  auth_tokens: []
+  # Certificate and key enabling HTTPS; a client CA bundle enables mutual TLS.
+  tls_cert: ""
+  tls_key: ""
+  tls_client_ca: ""
+  # Browser origins allowed to call the server ("*" for any).
+  cors_origins: []
+  # URL prefix when served behind a reverse proxy, e.g. /executor.
+  base_path: ""
+  # Admin address serving net/http/pprof and expvar for SSE and HTTP, e.g.
+  # "127.0.0.1:6060"; protected by auth_tokens. Empty disables it.
+  debug_addr: ""
+
+execution:
+  # Where code runs: subprocess (host), docker (isolated containers), hybrid
+  # (docker, with short snippets run in a persistent container per language)
+  # or nix (host, with dependencies from nix-shell).
+  mode: %s
+  # Execute tools to expose (python, bash, typescript, go, tests, benchmark,
+  # browse, render, http); empty enables all.
+  tools: []
+  # Languages also offered as read-only execute-<language>-readonly tools
+  # (python and bash in subprocess mode, all in docker and hybrid mode).
+  readonly_tools: []
+  # Number of recent executions kept as execution:// resources.
+  history_size: %d
+  # Sampling-based repair attempts for failed executions; 0 disables.
+  auto_fix: %d
+  # Python module installer: pip, uv (faster installs in docker mode; modules
+  # run through "uv run" ephemeral environments in subprocess mode) or venv
+  # (subprocess modules installed with pip into a throwaway virtualenv).
+  python_installer: %s
+  # Binary running subprocess-mode code per language, a command name or an
+  # absolute path, e.g. python: /opt/python3.12/bin/python3. Unlisted languages
+  # use the first runtime found (python3, python, py for Python).
+  binaries: {}
+  # Host user ("user" or "user:group") running subprocess and nix executions;
+  # requires the server to run as root. Empty runs them as the server user.
+  run_as: ""
+  # Nice value (1-19, 0 keeps the server's) and IO priority (normal, low or
+  # idle; Linux only) of subprocess and nix executions, so heavy scripts do not
+  # slow down interactive work on the host.
+  nice: %d
+  io_priority: %s
+  # Execution modes (subprocess, docker, nix) MCP sessions may select instead
+  # of mode, with the set-execution-mode tool or when initializing.
+  session_modes: []
+  # How long the persistent containers of hybrid mode live without executions.
+  persistent_idle: 10m
+  # Time zone (TZ) and locale (LANG, LC_ALL) of executions whose call sets
+  # neither, e.g. UTC and C.UTF-8; empty keeps those of the environment.
+  timezone: ""
+  locale: ""
+  # Path of libfaketime in the execution environment (the images in docker
+  # mode), enabling the fake_time argument, e.g.
+  # /usr/lib/x86_64-linux-gnu/faketime/libfaketime.so.1
+  faketime_library: ""
+  # Refuse dependency installation and run docker executions without a network,
+  # for servers without network access; the --offline flag sets it too.
+  offline: false
+  # Install the third-party packages imported by Python and TypeScript code
+  # along with the requested dependencies (docker mode, and uv or venv Python
+  # in subprocess mode). import_packages maps further import names to packages,
+  # e.g. python: {cv2: opencv-python-headless}; an empty package skips an import.
+  detect_dependencies: false
+  import_packages: {}
+  # Directory holding the named workspaces that executions of a session share
+  # through the workspace tool argument, and the artifacts directories of
+  # executions; empty uses the system temp directory.
+  workspace_dir: ""
+  # How long the workspaces of a session are kept without executions using
+  # them, also when the session never ends.
+  workspace_idle: 24h
+  # Directory keeping the Go build and module caches of subprocess executions,
+  # writable by the user running them; empty uses that user's own caches.
+  go_cache_dir: ""
+  # Environment variables injected into every execution (per-call env wins),
+  # e.g. proxies or common credentials. .env files are read first.
+  env: {}
+  env_files: []
+  # Subprocess and nix executions get a scrubbed copy of the server environment:
+  # PATH, HOME, locale, proxy, CA bundle, Go and Nix variables. List further
+  # names or prefixes ending in * to pass, e.g. [AWS_PROFILE, CONDA_*]; "*"
+  # passes everything.
+  env_passthrough: []
+
+# Docker images used in docker mode.
+images:
+  python: %s
+  bash: %s
+  typescript: %s
+  go: %s
+  # Images selected by the runtime_version tool argument, per language and
+  # version. A language listed here replaces its default versions.
+  runtimes:
+%s  # Images of the executions importing or installing a browser automation
+  # package such as Playwright or Selenium, sparing every other execution the
+  # download. An empty image runs them in the language image.
+  browser:
+    python: %s
+    # typescript: mcr.microsoft.com/playwright:v1.53.0-noble
+  # Packages baked into images derived from the language images, which
+  # executions run in. Built by "mcp-executor build-images", or by the first
+  # execution otherwise.
+  preinstall: {}
+#     python: [requests, pandas, numpy]
+  # Commands replacing the defaults per language, e.g. to skip optional
+  # packages or run a wrapper script. The dependencies follow install, and
+  # execute reads the code on stdin.
+  commands: {}
+#     python:
+#       install: [python, -m, pip, install, --quiet, --no-deps]
+#     bash:
+#       install: [apt-get, update, -qq, "&&", apt-get, install, -y, -qq, --no-install-recommends]
+
+# Named docker-mode environments selected with the profile tool argument. The
+# packages are installed once into an image built on first use.
+environments: {}
+#   data-science:
+#     language: python
+#     description: pandas, numpy and scikit-learn
+#     packages: [pandas, numpy, scikit-learn]
+#     env: {MPLBACKEND: Agg}
+#     memory: 2g
+
+# Package mirrors for air-gapped or corporate networks; empty values keep the
+# public registries. Set in every execution as PIP_INDEX_URL, UV_INDEX_URL,
+# NPM_CONFIG_REGISTRY and GOPROXY; apt_mirror replaces archive.ubuntu.com in
+# the docker bash image. Set GOSUMDB=off in execution.env when sum.golang.org
+# is unreachable.
+registries:
+  pypi_index_url: ""         # e.g. https://pypi.internal/simple
+  pypi_extra_index_urls: []
+  npm_registry: ""           # e.g. https://npm.internal
+  goproxy: ""                # e.g. https://goproxy.internal,direct
+  apt_mirror: ""             # e.g. http://mirror.internal/ubuntu
+  apt_cache_volume: ""       # Docker volume keeping apt downloads, e.g. mcp-executor-apt-cache
+  go_cache_volume: ""        # Docker volume keeping the Go caches, e.g. mcp-executor-go-cache
+
+limits:
+  # Resource limits for docker-mode executions; empty leaves Docker's defaults.
+  memory: ""   # e.g. 512m
+  cpus: ""     # e.g. "1.5"
+  # Default execution timeout, ceiling for per-call timeouts, and dependency
+  # installation timeout, which calls installing dependencies get on top of
+  # their timeout (e.g. 30s, 5m); 0s disables each limit.
+  timeout: 0s
+  max_timeout: 0s
+  install_timeout: 0s
+  # Executions running at once; further calls wait in a queue, interactive calls
+  # ahead of batch ones. max_queued caps the waiting calls. 0 disables each limit.
+  max_concurrent: 0
+  max_queued: 0
+  # Let interactive calls waiting for a slot preempt batch executions running
+  # for this long, e.g. 30s; preempted executions are killed and run again
+  # from the queue. 0s disables preemption.
+  preempt_after: 0s
+  # Largest code accepted per execution, in bytes; 0 disables the limit. Code
+  # with NUL bytes or invalid UTF-8 is always rejected.
+  max_code_size: %d
+  # Disk space in MB an execution may use in its scratch and artifacts
+  # directories (a tmpfs holding the working directory in docker mode), and
+  # the named workspaces of a session may hold; executions writing more are
+  # killed. 0 disables each limit.
+  max_disk_mb: 0
+  max_workspace_mb: 0
+  # Kill execution containers older than this, e.g. 1h, including those left
+  # behind by a crashed server (docker mode); 0s disables the reaper.
+  container_max_lifetime: 0s
+
+quotas:
+  # Per-client limits over a sliding window; clients are told by their
+  # auth_tokens entry, and all clients share one quota without tokens.
+  # max_download_mb counts the network traffic received by docker-mode
+  # executions. 0 disables each limit.
+  window: 1h
+  max_executions: 0
+  max_cpu_seconds: 0
+  max_download_mb: 0
+
+output:
+  # Post-processors applied in order to execution output: strip_ansi removes
+  # terminal colors, collapse_progress keeps the last line of progress bars,
+  # limit_repeats summarizes identical consecutive lines and pretty_json
+  # indents output that is a JSON document.
+  processors: []
+  # Per-language lists replacing processors, e.g.
+  # python: [strip_ansi, collapse_progress, pretty_json]
+  tools: {}
+  # Bytes of output returned in tool results; the start and end of longer
+  # output are returned with a link to its output:// resource. 0 returns all.
+  max_inline: %d
+
+policy:
+  # Host directories that docker-mode tools may bind-mount.
+  allowed_mounts: []
+  # Let detect_dependencies install imports missing from its package tables
+  # under their own name, which may belong to an unrelated or malicious package.
+  install_unmapped_imports: false
+  # Run host mounts, host networking and sudo-like subprocess scripts for
+  # clients that cannot confirm them through elicitation, instead of refusing.
+  allow_unconfirmed_privileges: false
+
+logging:
+  verbose: %t
+  # Write logs to this file instead of stderr (useful in stdio mode, where stderr
+  # goes to the MCP client host). The file is rotated by size and/or age.
+  file: ""
+  max_size_mb: %d
+  rotate_interval: 0s   # e.g. 24h
+  max_backups: %d
+
+cache:
+  # Return the cached output of identical repeat executions (same tool, code,
+  # dependencies, env and options) for this long, e.g. 10m; 0s disables caching.
+  ttl: 0s
+  max_entries: %d
+  # Directory persisting cached results across restarts; empty keeps them in memory.
+  dir: ""
+
+schedule:
+  # Register the schedule-execution, list-schedules and cancel-schedule tools,
+  # which run stored code on cron expressions while the server is running.
+  enabled: false
+  max_schedules: %d
+  # Results of each schedule kept as schedule://{id} resources.
+  keep_results: %d
+
+prompts:
+  # Prompts not to register, e.g. [security-audit]. Every prompt is otherwise
+  # registered in the execution modes it supports.
+  disabled: []
+
+# Named profiles overlay partial settings when selected with --profile.
+# profiles:
+#   prod:
+#     execution:
+#       mode: docker
+#     limits:
+#       memory: 512m
+`,
+		d.Transport.Mode, d.Transport.SSEAddr, d.Transport.HTTPAddr,
+		d.Execution.Mode, d.Execution.HistorySize, d.Execution.AutoFix, d.Execution.PythonInstaller,
+		d.Execution.Nice, d.Execution.IOPriority,
+		d.Images.Python, d.Images.Bash, d.Images.TypeScript, d.Images.Go, runtimesYAML(d.Images.Runtimes), d.Images.Browser["python"],
+		d.Limits.MaxCodeSize,
+		d.Output.MaxInline,
+		d.Logging.Verbose, d.Logging.MaxSizeMB, d.Logging.MaxBackups,
+		d.Cache.MaxEntries,
+		d.Schedule.MaxSchedules, d.Schedule.KeepResults,
+	)
+}
+
+// runtimesYAML renders images.runtimes, sorted by language and version.
+func runtimesYAML(runtimes map[string]map[string]string) string {
+	var b strings.Builder
+	for _, language := range sortedKeys(runtimes) {
+		fmt.Fprintf(&b, "    %s:\n", language)
+		for _, version := range sortedKeys(runtimes[language]) {
+			fmt.Fprintf(&b, "      %q: %s\n", version, runtimes[language][version])
+		}
+	}
+	return b.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
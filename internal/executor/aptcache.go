@@ -0,0 +1,40 @@
+// Package executor shares the package lists and archives downloaded by apt
+// between the Docker bash executions installing packages, so that each one
+// only fetches what changed.
+package executor
+
+import "strings"
+
+// APTCacheDir is where the apt cache volume is mounted, holding the package
+// lists and the downloaded archives.
+const APTCacheDir = "/var/cache/apt"
+
+// aptCacheSetup are the shell words keeping the package lists and archives in
+// APTCacheDir instead of deleting them, as the docker-clean configuration of
+// the images does.
+var aptCacheSetup = []string{
+	"rm", "-f", "/etc/apt/apt.conf.d/docker-clean", "&&",
+	"printf", `'%s\n'`, shellQuote(`Dir::State::Lists "` + APTCacheDir + `/lists";`), shellQuote(`APT::Keep-Downloaded-Packages "true";`),
+	">", "/etc/apt/apt.conf.d/90mcp-executor-cache", "&&",
+	"mkdir", "-p", APTCacheDir + "/lists/partial", "&&",
+}
+
+// WithAPTCache mounts the named Docker volume on APTCacheDir of the bash
+// executions installing packages. apt then updates the cached package lists
+// instead of downloading them again and reuses downloaded archives. Empty
+// keeps no cache.
+func WithAPTCache(volume string) DockerOption {
+	return func(c *ExecutorConfig) {
+		if c.ExecutorName == "bash" {
+			c.APTCacheVolume = volume
+		}
+	}
+}
+
+// aptCacheInstall wraps installArgs using the cache, holding a lock on it so
+// that the containers sharing it install one at a time, since apt does not
+// wait for the locks of other containers.
+func aptCacheInstall(installArgs []string) []string {
+	words := append([]string{}, aptCacheSetup...)
+	return append(words, "flock", APTCacheDir+"/mcp-executor.lock", "sh", "-c", shellQuote(strings.Join(installArgs, " ")), "sh", `"$@"`)
+}
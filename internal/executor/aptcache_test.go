@@ -0,0 +1,40 @@
+package executor
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithAPTCache(t *testing.T) {
+	tests := []struct {
+		name     string
+		executor *DockerExecutor
+	}{
+		{"cache", NewBashExecutor(WithAPTCache("apt-cache"))},
+		{"install timeout", NewBashExecutor(WithAPTCache("apt-cache"), WithInstallTimeout(time.Minute))},
+		{"mirror", NewBashExecutor(WithAPTCache("apt-cache"), WithAPTMirror("http://mirror.internal/ubuntu"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command := tt.executor.shellCommand([]string{"curl"}, false, false, false, tt.executor.config.InstallTimeout)
+			script := command[2]
+			for _, want := range []string{"rm -f /etc/apt/apt.conf.d/docker-clean", `Dir::State::Lists "/var/cache/apt/lists";`, "flock /var/cache/apt/mcp-executor.lock sh -c", "--no-install-recommends"} {
+				if !strings.Contains(script, want) {
+					t.Errorf("shellCommand() script = %s, want it to contain %s", script, want)
+				}
+			}
+			if err := exec.Command("sh", "-n", "-c", script).Run(); err != nil {
+				t.Errorf("shellCommand() script is not valid shell: %v\n%s", err, script)
+			}
+		})
+	}
+
+	if script := NewBashExecutor(WithAPTCache("apt-cache")).shellCommand(nil, false, false, false, 0)[2]; strings.Contains(script, "flock") {
+		t.Errorf("shellCommand() without packages uses the cache: %s", script)
+	}
+	if python := NewPythonExecutor(WithAPTCache("apt-cache")); python.config.APTCacheVolume != "" {
+		t.Errorf("WithAPTCache() should only change the bash executor, got %q", python.config.APTCacheVolume)
+	}
+}
@@ -0,0 +1,146 @@
+// Package executor collects the HTML and Markdown reports an execution writes
+// to its artifacts directory, so that they can be published as resources.
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// ContainerArtifacts is the artifacts directory of Docker executions and
+// their MCP_ARTIFACTS.
+const ContainerArtifacts = "/artifacts"
+
+// MaxArtifactSize is the size of the largest report that is collected.
+const MaxArtifactSize = 10 << 20
+
+// artifactTypes maps the extensions of collected files to their MIME type.
+var artifactTypes = map[string]string{
+	".html":     "text/html",
+	".htm":      "text/html",
+	".md":       "text/markdown",
+	".markdown": "text/markdown",
+}
+
+// Artifact is a report written by an execution.
+type Artifact struct {
+	Name     string `json:"name"` // Slash-separated path below the artifacts directory
+	MIMEType string `json:"mime_type"`
+	Size     int    `json:"size_bytes"`
+	Content  string `json:"-"`
+}
+
+type artifactsKey struct{}
+
+// WithArtifacts returns a context whose executions collect their reports into
+// artifacts. Executions without it get no artifacts directory.
+func WithArtifacts(ctx context.Context, artifacts *[]Artifact) context.Context {
+	return context.WithValue(ctx, artifactsKey{}, artifacts)
+}
+
+// ReportedArtifacts returns the reports collected by the executions of ctx.
+func ReportedArtifacts(ctx context.Context) []Artifact {
+	if artifacts, ok := ctx.Value(artifactsKey{}).(*[]Artifact); ok {
+		return *artifacts
+	}
+	return nil
+}
+
+// ArtifactsExecutor gives each execution an empty artifacts directory and
+// collects the HTML and Markdown files found in it afterwards, also when the
+// execution failed. Read-only executions get no artifacts directory.
+type ArtifactsExecutor struct {
+	executor Executor
+	root     string
+}
+
+// NewArtifactsExecutor wraps exec, creating the artifacts directories below
+// root. In Docker mode root must be visible to the Docker daemon, or
+// executions run without an artifacts directory.
+func NewArtifactsExecutor(exec Executor, root string) Executor {
+	return &ArtifactsExecutor{executor: exec, root: root}
+}
+
+func (a *ArtifactsExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	artifacts, ok := ctx.Value(artifactsKey{}).(*[]Artifact)
+	if !ok || NewOptions(opts...).ReadOnly {
+		return a.executor.Execute(ctx, code, dependencies, envVars, opts...)
+	}
+
+	if err := os.MkdirAll(a.root, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create artifacts directory: %v", err)
+	}
+	dir, err := os.MkdirTemp(a.root, "artifacts-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create artifacts directory: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	if err := grantRunAs(ctx, a.root, dir); err != nil {
+		return "", err
+	}
+
+	output, err := a.executor.Execute(ctx, code, dependencies, envVars, append(opts, withArtifactsDir(dir))...)
+	*artifacts = collectArtifacts(dir)
+	return output, err
+}
+
+// collectArtifacts reads the reports below dir, skipping other files and
+// reports larger than MaxArtifactSize.
+func collectArtifacts(dir string) []Artifact {
+	var artifacts []Artifact
+	_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return nil
+		}
+		mimeType, ok := artifactTypes[strings.ToLower(filepath.Ext(path))]
+		if !ok {
+			return nil
+		}
+		name, _ := filepath.Rel(dir, path)
+		if info, err := entry.Info(); err != nil || info.Size() > MaxArtifactSize {
+			logger.Info("Skipping artifact %s: larger than %d MiB", name, MaxArtifactSize>>20)
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			logger.Error("Failed to read artifact %s: %v", name, err)
+			return nil
+		}
+		artifacts = append(artifacts, Artifact{
+			Name:     filepath.ToSlash(name),
+			MIMEType: mimeType,
+			Size:     len(content),
+			Content:  string(content),
+		})
+		return nil
+	})
+	return artifacts
+}
+
+// artifactsMount returns the mount of dir at ContainerArtifacts, or an error
+// when the daemon cannot see dir.
+func artifactsMount(host DockerHost, dir string) (Mount, error) {
+	if host.InContainer && host.Volumes == nil {
+		return Mount{}, fmt.Errorf("the volumes of the server container are unknown: %v", host.VolumesErr)
+	}
+	source, err := host.HostPath(dir)
+	if err != nil {
+		return Mount{}, err
+	}
+	return Mount{Source: source, Target: ContainerArtifacts}, nil
+}
+
+// artifactsReference matches code that may write to the artifacts directory.
+var artifactsReference = regexp.MustCompile(`/artifacts\b|` + ArtifactsEnv)
+
+// usesArtifacts reports whether code refers to the artifacts directory.
+func usesArtifacts(code string) bool {
+	return artifactsReference.MatchString(code)
+}
@@ -0,0 +1,93 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCollectArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"report.md":         "# Report\n",
+		"charts/index.HTML": "<html></html>",
+		"data.csv":          "a,b\n",
+		"big.html":          strings.Repeat("x", MaxArtifactSize+1),
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := map[string]Artifact{}
+	for _, artifact := range collectArtifacts(dir) {
+		got[artifact.Name] = artifact
+	}
+	if len(got) != 2 {
+		t.Fatalf("collectArtifacts() = %v, want the two reports", got)
+	}
+	if report := got["report.md"]; report.MIMEType != "text/markdown" || report.Content != "# Report\n" || report.Size != 9 {
+		t.Errorf("Markdown artifact = %+v", report)
+	}
+	if page := got["charts/index.HTML"]; page.MIMEType != "text/html" {
+		t.Errorf("HTML artifact = %+v, want text/html", page)
+	}
+}
+
+func TestArtifactsExecutor(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "artifacts")
+	recorder := &optionsRecorder{}
+	exec := NewArtifactsExecutor(recorder, root)
+
+	if _, err := exec.Execute(context.Background(), "code", nil, nil); err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+	if recorder.options.ArtifactsDir != "" {
+		t.Errorf("ArtifactsDir = %q without a collecting context, want none", recorder.options.ArtifactsDir)
+	}
+
+	var artifacts []Artifact
+	if _, err := exec.Execute(WithArtifacts(context.Background(), &artifacts), "code", nil, nil); err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+	if dir := recorder.options.ArtifactsDir; filepath.Dir(dir) != root {
+		t.Errorf("ArtifactsDir = %q, want a directory below %s", dir, root)
+	}
+	if _, err := os.Stat(recorder.options.ArtifactsDir); !os.IsNotExist(err) {
+		t.Error("The artifacts directory should be removed after the execution")
+	}
+}
+
+func TestArtifactsMount(t *testing.T) {
+	if mount, err := artifactsMount(DockerHost{}, "/tmp/a/artifacts-1"); err != nil || mount.Source != "/tmp/a/artifacts-1" || mount.Target != ContainerArtifacts {
+		t.Errorf("artifactsMount() on the daemon host = %+v, %v", mount, err)
+	}
+	host := DockerHost{InContainer: true, Volumes: []Mount{{Source: "/srv/data", Target: "/data"}}}
+	if mount, err := artifactsMount(host, "/data/artifacts/artifacts-1"); err != nil || mount.Source != "/srv/data/artifacts/artifacts-1" {
+		t.Errorf("artifactsMount() on a volume = %+v, %v", mount, err)
+	}
+	if _, err := artifactsMount(DockerHost{InContainer: true}, "/tmp/artifacts-1"); err == nil {
+		t.Error("artifactsMount() should fail when the volumes of the server are unknown")
+	}
+}
+
+func TestUsesArtifacts(t *testing.T) {
+	for code, want := range map[string]bool{
+		`open("/artifacts/report.html", "w")`:  true,
+		`os.environ["MCP_ARTIFACTS"]`:          true,
+		`print("no reports")`:                  false,
+		`open("/tmp/my-artifacts-list", "w")`:  false,
+		`echo hi > "$MCP_ARTIFACTS/report.md"`: true,
+	} {
+		if got := usesArtifacts(code); got != want {
+			t.Errorf("usesArtifacts(%q) = %t, want %t", code, got, want)
+		}
+	}
+}
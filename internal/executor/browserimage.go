@@ -0,0 +1,60 @@
+// Package executor selects the heavy browser image for the Docker executions
+// that automate a browser, so that every other execution starts from a slim
+// image.
+package executor
+
+import (
+	"strings"
+	"unicode"
+)
+
+// BrowserImage is the default image of Python executions using a browser, with
+// Playwright and its browsers preinstalled.
+const BrowserImage = "mcr.microsoft.com/playwright/python:v1.53.0-noble"
+
+// browserPackages name the modules and packages automating a browser, per
+// language.
+var browserPackages = map[string]map[string]bool{
+	"python":     setOf(`playwright pytest-playwright selenium pyppeteer splinter`),
+	"typescript": setOf(`playwright playwright-core @playwright/test puppeteer puppeteer-core selenium-webdriver`),
+}
+
+// WithBrowserImage runs the executions that import or install a browser
+// automation package, such as Playwright or Selenium, in image instead of the
+// default image. Empty runs them in the default image too.
+func WithBrowserImage(image string) DockerOption {
+	return func(c *ExecutorConfig) {
+		c.BrowserImage = image
+	}
+}
+
+// usesBrowserImage reports whether the execution runs in the browser image: it
+// uses a browser and selects no image through a runtime version or profile.
+func (d *DockerExecutor) usesBrowserImage(code string, dependencies []string, options Options) bool {
+	return d.config.BrowserImage != "" && options.RuntimeVersion == "" && options.Profile == "" &&
+		usesBrowser(d.config.ExecutorName, code, dependencies, options.DependencyFile)
+}
+
+// usesBrowser reports whether code of language imports a browser automation
+// package, or its dependencies or dependency file list one.
+func usesBrowser(language, code string, dependencies []string, dependencyFile string) bool {
+	packages := browserPackages[language]
+	if len(packages) == 0 {
+		return false
+	}
+	for _, module := range detectImports(language, code) {
+		if packages[module] {
+			return true
+		}
+	}
+	// Requirement lines and the keys of package.json are words of the manifest
+	words := strings.FieldsFunc(dependencyFile, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(`"',:{}[]`, r)
+	})
+	for _, dependency := range append(words, dependencies...) {
+		if packages[packageName(language, dependency)] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package executor
+
+import "testing"
+
+func TestUsesBrowser(t *testing.T) {
+	tests := []struct {
+		name           string
+		language       string
+		code           string
+		dependencies   []string
+		dependencyFile string
+		want           bool
+	}{
+		{"python import", "python", "from playwright.sync_api import sync_playwright\n", nil, "", true},
+		{"python selenium", "python", "import os, selenium.webdriver\n", nil, "", true},
+		{"python dependency", "python", "print(1)", []string{"Pytest_Playwright>=0.5"}, "", true},
+		{"requirements.txt", "python", "print(1)", nil, "requests==2.32.0\nplaywright==1.53.0\n", true},
+		{"python comment", "python", "# import playwright\nprint(1)", nil, "", false},
+		{"python plain", "python", "import json\nprint(json.dumps({}))", []string{"requests"}, "", false},
+		{"typescript import", "typescript", `import { chromium } from "playwright";`, nil, "", true},
+		{"package.json", "typescript", "console.log(1)", nil, `{"dependencies": {"@playwright/test": "^1.53.0"}}`, true},
+		{"typescript dependency", "typescript", "console.log(1)", []string{"puppeteer@22"}, "", true},
+		{"typescript plain", "typescript", `import lodash from "lodash";`, []string{"zod"}, "", false},
+		{"bash", "bash", "playwright --version", []string{"playwright"}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := usesBrowser(tt.language, tt.code, tt.dependencies, tt.dependencyFile); got != tt.want {
+				t.Errorf("usesBrowser() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDockerExecutor_UsesBrowserImage(t *testing.T) {
+	code := "from playwright.sync_api import sync_playwright\n"
+	tests := []struct {
+		name     string
+		executor *DockerExecutor
+		options  Options
+		want     bool
+	}{
+		{"default", NewPythonExecutor(), Options{}, true},
+		{"runtime version", NewPythonExecutor(), NewOptions(WithRuntimeVersion("3.12")), false},
+		{"profile", NewPythonExecutor(), NewOptions(WithProfile("scraping")), false},
+		{"disabled", NewPythonExecutor(WithBrowserImage("")), Options{}, false},
+		{"no default", NewTypeScriptExecutor(), Options{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.executor.usesBrowserImage(code, nil, tt.options); got != tt.want {
+				t.Errorf("usesBrowserImage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if NewPythonExecutor().usesBrowserImage("print(1)", []string{"rich"}, Options{}) {
+		t.Error("usesBrowserImage() = true for code without a browser")
+	}
+}
@@ -0,0 +1,104 @@
+// Package executor checks code without running it: Python is byte-compiled,
+// Bash parsed, Go vetted and TypeScript type-checked, so agents get a fast and
+// safe validation loop before the real run.
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// checkFiles are the names of the files holding the checked code.
+var checkFiles = map[string]string{
+	"python":     "main.py",
+	"bash":       "main.sh",
+	"typescript": "index.ts",
+	"go":         "main.go",
+}
+
+// tscFlags type-check a TypeScript file without a tsconfig.json as tsx runs it.
+var tscFlags = []string{"--noEmit", "--pretty", "false", "--skipLibCheck", "--target", "es2022", "--module", "esnext", "--moduleResolution", "bundler"}
+
+// checkArgs returns the command checking file, written in language, with
+// binary: python -m py_compile, bash -n, go vet or tsc --noEmit.
+func checkArgs(language, binary, file string) []string {
+	switch language {
+	case "python":
+		return []string{binary, "-m", "py_compile", file}
+	case "bash":
+		return []string{binary, "-n", file}
+	case "go":
+		return []string{binary, "vet", file}
+	}
+	return append(append([]string{binary}, tscFlags...), file)
+}
+
+// findTypeScriptChecker returns the tsc binary in binDir or PATH.
+func findTypeScriptChecker(binDir string) (string, error) {
+	if binDir != "" {
+		if path, err := exec.LookPath(filepath.Join(binDir, "tsc")); err == nil {
+			return path, nil
+		}
+	}
+	path, err := exec.LookPath("tsc")
+	if err != nil {
+		return "", fmt.Errorf("tsc not found: install TypeScript (npm install -g typescript) to check TypeScript code")
+	}
+	return path, nil
+}
+
+// checkOnHost checks code written in language with binary in a temporary
+// directory and returns the output of the checker. Code that does not pass is
+// reported as a CompileError of name.
+func checkOnHost(ctx context.Context, name, language, binary, code string, env []string) (string, error) {
+	dir, err := os.MkdirTemp("", "mcp-executor-check-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	file := filepath.Join(dir, checkFiles[language])
+	if err := os.WriteFile(file, []byte(code), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write temp file: %v", err)
+	}
+	if err := grantRunAs(ctx, dir, file); err != nil {
+		return "", err
+	}
+
+	return runCheck(ctx, name, dir, checkArgs(language, binary, checkFiles[language]), env)
+}
+
+// runCheck runs the checker command args in dir and returns its output, or
+// the problems found as a CompileError of name.
+func runCheck(ctx context.Context, name, dir string, args, env []string) (string, error) {
+	logger.Verbose("Checking code with %s", strings.Join(args, " "))
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.WaitDelay = waitDelay
+	out, err := runWithUsage(ctx, cmd)
+	if err != nil {
+		logger.Debug("Check failed: %v", err)
+		if exitError, ok := err.(*exec.ExitError); ok {
+			if exitError.Exited() {
+				return "", newCompileError(name, string(out))
+			}
+			return "", exitErr(name, exitError, string(out))
+		}
+		return "", fmt.Errorf("check failed: %v", err)
+	}
+	return string(out), nil
+}
+
+// withEnvVars returns env followed by the variables of a call.
+func withEnvVars(env []string, envVars map[string]string) []string {
+	for key, value := range envVars {
+		env = append(env, key+"="+value)
+	}
+	return env
+}
@@ -0,0 +1,47 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+)
+
+func TestCheckOnly(t *testing.T) {
+	tests := []struct {
+		name     string
+		binary   string
+		executor Executor
+		code     string
+		wantLine int // Line of the reported problem; zero when the code passes
+	}{
+		{"python passes", "python3", NewSubprocessPythonExecutor(), "import sys\nsys.exit(1)\n", 0},
+		{"python syntax error", "python3", NewSubprocessPythonExecutor(), "x = 1\nif x\n    print(x)\n", 2},
+		{"bash passes", "bash", NewSubprocessBashExecutor(), "exit 1\n", 0},
+		{"bash syntax error", "bash", NewSubprocessBashExecutor(), "echo ok\nif true; then\n", 3},
+		{"go passes", "go", NewSubprocessGoExecutor(), "package main\n\nimport \"os\"\n\nfunc main() { os.Exit(1) }\n", 0},
+		{"go vet problem", "go", NewSubprocessGoExecutor(), "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Printf(\"%d\\n\", \"text\")\n}\n", 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := exec.LookPath(tt.binary); err != nil {
+				t.Skipf("%s not installed", tt.binary)
+			}
+			_, err := tt.executor.Execute(context.Background(), tt.code, nil, nil, WithCheckOnly())
+			if tt.wantLine == 0 {
+				if err != nil {
+					t.Errorf("Execute() returned error: %v, want the code checked without running it", err)
+				}
+				return
+			}
+			var compile *CompileError
+			if !errors.As(err, &compile) {
+				t.Fatalf("Execute() error = %v, want *CompileError", err)
+			}
+			if len(compile.Diagnostics) == 0 || compile.Diagnostics[0].Line != tt.wantLine {
+				t.Errorf("Diagnostics = %+v, want a problem on line %d", compile.Diagnostics, tt.wantLine)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Package executor reports code that did not compile, with the diagnostics of
+// the compiler parsed into file, line and column.
+package executor
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Diagnostic is an error reported by a compiler.
+type Diagnostic struct {
+	File    string `json:"file"` // Base name of the file, e.g. main.go
+	Line    int    `json:"line"`
+	Column  int    `json:"column,omitempty"` // Zero when unknown, as for Python and Bash
+	Message string `json:"message"`
+}
+
+// CompileError reports code that did not compile. Nothing ran, so the output
+// is that of the compiler.
+type CompileError struct {
+	Message     string       // Description of the failure
+	Diagnostics []Diagnostic // Errors parsed from the output, possibly none
+	Output      string       // Output of the compiler
+}
+
+func (e *CompileError) Error() string {
+	return e.Message + ": " + e.Output
+}
+
+// compileErrorMarkers are printed by the toolchains when the code does not
+// compile: go build and go run, ts-node and the esbuild transform of tsx.
+var compileErrorMarkers = []string{
+	"# command-line-arguments\n",
+	"TSError: ⨯ Unable to compile TypeScript",
+	"Error [TransformError]: Transform failed",
+}
+
+var (
+	// file:line:column: message, printed by the Go compiler and esbuild (with
+	// an "ERROR: " prefix)
+	positionDiagnostic = regexp.MustCompile(`^(\S+?):(\d+):(\d+): (?:ERROR: )?(.+)$`)
+	// file(line,column): error TS1234: message, printed by tsc and ts-node
+	typeScriptDiagnostic = regexp.MustCompile(`^(\S+?)\((\d+),(\d+)\): error (TS\d+: .+)$`)
+	// file: line N: message, printed by bash -n
+	bashDiagnostic = regexp.MustCompile(`^(\S+): line (\d+): (.+)$`)
+	// File "file", line N, followed by the source, a caret and the
+	// SyntaxError, printed by Python
+	pythonLocation = regexp.MustCompile(`^\s*File "([^"]+)", line (\d+)`)
+	pythonError    = regexp.MustCompile(`^(\w+Error): (.+)$`)
+)
+
+// compileError returns the CompileError of a run of name that failed with
+// output, or nil when output does not show a compile error.
+func compileError(name, output string) error {
+	for _, marker := range compileErrorMarkers {
+		if strings.Contains(output, marker) {
+			return newCompileError(name, output)
+		}
+	}
+	return nil
+}
+
+// newCompileError returns the CompileError of name with the compiler output.
+func newCompileError(name, output string) *CompileError {
+	return &CompileError{
+		Message:     name + " failed to compile",
+		Diagnostics: parseDiagnostics(output),
+		Output:      output,
+	}
+}
+
+// parseDiagnostics returns the diagnostics in compiler output. Indented lines
+// following a diagnostic, such as the "have" and "want" lines of Go type
+// errors, are appended to its message.
+func parseDiagnostics(output string) []Diagnostic {
+	var diagnostics []Diagnostic
+	var python *Diagnostic // Location of a Python error waiting for its message
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if len(diagnostics) > 0 && strings.HasPrefix(line, "\t") {
+			diagnostics[len(diagnostics)-1].Message += "\n" + strings.TrimSpace(line)
+			continue
+		}
+		if match := typeScriptDiagnostic.FindStringSubmatch(line); match != nil {
+			diagnostics = append(diagnostics, diagnostic(match[1], match[2], match[3], match[4]))
+		} else if match := positionDiagnostic.FindStringSubmatch(line); match != nil {
+			diagnostics = append(diagnostics, diagnostic(match[1], match[2], match[3], match[4]))
+		} else if match := bashDiagnostic.FindStringSubmatch(line); match != nil && !strings.HasPrefix(match[3], "`") {
+			// bash quotes the offending line in a second message starting with `
+			diagnostics = append(diagnostics, diagnostic(match[1], match[2], "0", match[3]))
+		} else if match := pythonLocation.FindStringSubmatch(line); match != nil {
+			location := diagnostic(match[1], match[2], "0", "")
+			python = &location
+		} else if match := pythonError.FindStringSubmatch(line); match != nil && python != nil {
+			python.Message = match[1] + ": " + match[2]
+			diagnostics = append(diagnostics, *python)
+			python = nil
+		}
+	}
+	return diagnostics
+}
+
+// diagnostic returns the Diagnostic of the fields matched in a line.
+func diagnostic(file, line, column, message string) Diagnostic {
+	lineNumber, _ := strconv.Atoi(line)
+	columnNumber, _ := strconv.Atoi(column)
+	return Diagnostic{File: filepath.Base(file), Line: lineNumber, Column: columnNumber, Message: message}
+}
@@ -0,0 +1,85 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDiagnostics(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []Diagnostic
+	}{
+		{
+			name:   "go",
+			output: "# command-line-arguments\n./main.go:3:15: declared and not used: x\n./main.go:4:2: cannot use s (variable of type string) as int value in assignment\n",
+			want: []Diagnostic{
+				{File: "main.go", Line: 3, Column: 15, Message: "declared and not used: x"},
+				{File: "main.go", Line: 4, Column: 2, Message: "cannot use s (variable of type string) as int value in assignment"},
+			},
+		},
+		{
+			name:   "go continuation lines",
+			output: "# command-line-arguments\n/tmp/mcp-go-1/main.go:7:9: too many return values\n\thave (number)\n\twant ()\n",
+			want:   []Diagnostic{{File: "main.go", Line: 7, Column: 9, Message: "too many return values\nhave (number)\nwant ()"}},
+		},
+		{
+			name:   "ts-node",
+			output: "TSError: ⨯ Unable to compile TypeScript:\nindex.ts(1,7): error TS2322: Type 'string' is not assignable to type 'number'.\n",
+			want:   []Diagnostic{{File: "index.ts", Line: 1, Column: 7, Message: "TS2322: Type 'string' is not assignable to type 'number'."}},
+		},
+		{
+			name:   "tsx",
+			output: "Error [TransformError]: Transform failed with 1 error:\n/tmp/mcp-ts-1/index.ts:2:10: ERROR: Expected \";\" but found \"b\"\n    at failureErrorWithLog (/usr/lib/node_modules/tsx/dist/index.js:1:1)\n",
+			want:   []Diagnostic{{File: "index.ts", Line: 2, Column: 10, Message: `Expected ";" but found "b"`}},
+		},
+		{
+			name:   "python",
+			output: "  File \"main.py\", line 2\n    if x\n        ^\nSyntaxError: expected ':'\n",
+			want:   []Diagnostic{{File: "main.py", Line: 2, Message: "SyntaxError: expected ':'"}},
+		},
+		{
+			name:   "bash",
+			output: "main.sh: line 1: syntax error near unexpected token `newline'\nmain.sh: line 1: `echo ('\n",
+			want:   []Diagnostic{{File: "main.sh", Line: 1, Message: "syntax error near unexpected token `newline'"}},
+		},
+		{
+			name:   "no diagnostics",
+			output: "main.go: no required module provides package example.com/missing\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseDiagnostics(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDiagnostics() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoSubprocessExecutor_CompileError(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not installed")
+	}
+	exec := NewSubprocessGoExecutor()
+
+	_, err := exec.Execute(context.Background(), "package main\n\nfunc main() {\n\tx := 1\n}\n", nil, nil)
+	var compile *CompileError
+	if !errors.As(err, &compile) {
+		t.Fatalf("Execute() error = %v, want *CompileError", err)
+	}
+	if got := compile.Diagnostics; len(got) != 1 || got[0].File != "main.go" || got[0].Line != 4 || got[0].Column != 2 || !strings.Contains(got[0].Message, "not used") {
+		t.Errorf("Diagnostics = %+v, want the unused variable at main.go:4:2", got)
+	}
+
+	_, err = exec.Execute(context.Background(), "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"before\")\n\tpanic(1)\n}\n", nil, nil)
+	if errors.As(err, &compile) || err == nil || !strings.Contains(err.Error(), "before") || strings.Contains(err.Error(), "exit status") {
+		t.Errorf("Execute() error = %v, want the runtime failure with the output of the program only", err)
+	}
+}
@@ -0,0 +1,178 @@
+// Package executor lists and terminates the Docker containers started by Docker
+// executors, so stuck executions can be inspected and killed from the command line.
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LanguageLabel is the container label holding the executor name. Every container
+// started by a DockerExecutor carries it.
+const LanguageLabel = "mcp-executor.language"
+
+// Labels identifying the execution of a container, set when known. The session
+// is its SessionTag.
+const (
+	ExecutionLabel = "mcp-executor.execution"
+	SessionLabel   = "mcp-executor.session"
+	ClientLabel    = "mcp-executor.client"
+)
+
+// Container describes a running execution container.
+type Container struct {
+	ID        string
+	Name      string
+	Language  string
+	Image     string
+	Running   string // How long the container has been running, as reported by Docker
+	Created   time.Time
+	Execution string // ID of the execution, if known
+	Session   string // SessionTag of the MCP session, if known
+	Client    string // ID of the client, if known
+}
+
+// containerFormat is the docker ps template parsed by parseContainers.
+const containerFormat = `{{.ID}}\t{{.Names}}\t{{.Label "` + LanguageLabel + `"}}\t{{.Image}}\t{{.RunningFor}}\t{{.CreatedAt}}` +
+	`\t{{.Label "` + ExecutionLabel + `"}}\t{{.Label "` + SessionLabel + `"}}\t{{.Label "` + ClientLabel + `"}}`
+
+// containerName returns the name of the next container of the execution of
+// ctx: mcp-exec-<language>-<execution ID>, followed by a counter from its
+// second container on. Executions without an ID get a random name.
+func containerName(ctx context.Context, language string) string {
+	execution, ok := ctx.Value(executionIDKey{}).(*execution)
+	if !ok || execution.id == "" {
+		return "mcp-exec-" + language + "-" + randomSuffix()
+	}
+	name := "mcp-exec-" + language + "-" + execution.id
+	if n := execution.containers.Add(1); n > 1 {
+		name += "-" + strconv.Itoa(int(n))
+	}
+	return name
+}
+
+// containerLabels returns the docker run arguments labeling the container of
+// an execution of ctx running language.
+func containerLabels(ctx context.Context, language string) []string {
+	args := []string{"--label", LanguageLabel + "=" + language}
+	if id := ExecutionID(ctx); id != "" {
+		args = append(args, "--label", ExecutionLabel+"="+id)
+	}
+	if id := SessionID(ctx); id != "" {
+		args = append(args, "--label", SessionLabel+"="+SessionTag(id))
+	}
+	if id := ClientID(ctx); id != "" {
+		args = append(args, "--label", ClientLabel+"="+id)
+	}
+	return args
+}
+
+// ListContainers returns the running containers started by Docker executors.
+func ListContainers(ctx context.Context) ([]Container, error) {
+	out, err := exec.CommandContext(ctx, "docker", "ps", "--no-trunc", "--filter", "label="+LanguageLabel, "--format", containerFormat).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %v", dockerError(err))
+	}
+	return parseContainers(string(out)), nil
+}
+
+// KillContainer kills the execution container whose ID (or unique ID prefix) or
+// name is id. Containers not started by a Docker executor are refused.
+func KillContainer(ctx context.Context, id string) error {
+	containers, err := ListContainers(ctx)
+	if err != nil {
+		return err
+	}
+	var matches []Container
+	for _, container := range containers {
+		if container.Name == id || strings.HasPrefix(container.ID, id) {
+			matches = append(matches, container)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return fmt.Errorf("no running execution container %q", id)
+	case 1:
+	default:
+		return fmt.Errorf("%q matches %d containers, use a longer ID", id, len(matches))
+	}
+
+	if err := exec.CommandContext(ctx, "docker", "kill", matches[0].ID).Run(); err != nil {
+		return fmt.Errorf("failed to kill container %s: %v", matches[0].Name, dockerError(err))
+	}
+	return nil
+}
+
+// createdAtLayout is the layout of the CreatedAt field of docker ps.
+const createdAtLayout = "2006-01-02 15:04:05 -0700 MST"
+
+// ReapContainers kills the execution containers created more than maxAge
+// before now and returns them. Containers that could not be killed are
+// reported in the error and left out of the result.
+func ReapContainers(ctx context.Context, maxAge time.Duration, now time.Time) ([]Container, error) {
+	containers, err := ListContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var reaped []Container
+	var errs []string
+	for _, container := range expiredContainers(containers, maxAge, now) {
+		if err := exec.CommandContext(ctx, "docker", "kill", container.ID).Run(); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", container.Name, dockerError(err)))
+			continue
+		}
+		reaped = append(reaped, container)
+	}
+	if len(errs) > 0 {
+		return reaped, fmt.Errorf("failed to kill containers: %s", strings.Join(errs, "; "))
+	}
+	return reaped, nil
+}
+
+// expiredContainers returns the containers created more than maxAge before now.
+// Containers whose creation time is unknown are kept.
+func expiredContainers(containers []Container, maxAge time.Duration, now time.Time) []Container {
+	var expired []Container
+	for _, container := range containers {
+		if !container.Created.IsZero() && now.Sub(container.Created) > maxAge {
+			expired = append(expired, container)
+		}
+	}
+	return expired
+}
+
+// parseContainers parses docker ps output in containerFormat.
+func parseContainers(output string) []Container {
+	var containers []Container
+	for line := range strings.SplitSeq(strings.TrimSpace(output), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 9 {
+			continue
+		}
+		created, _ := time.Parse(createdAtLayout, fields[5])
+		containers = append(containers, Container{
+			ID:        fields[0],
+			Name:      fields[1],
+			Language:  fields[2],
+			Image:     fields[3],
+			Running:   fields[4],
+			Created:   created,
+			Execution: fields[6],
+			Session:   fields[7],
+			Client:    fields[8],
+		})
+	}
+	return containers
+}
+
+// dockerError includes the stderr of a failed docker command in err.
+func dockerError(err error) error {
+	if exitError, ok := err.(*exec.ExitError); ok && len(exitError.Stderr) > 0 {
+		return fmt.Errorf("%s", strings.TrimSpace(string(exitError.Stderr)))
+	}
+	return err
+}
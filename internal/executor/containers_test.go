@@ -0,0 +1,77 @@
+package executor
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseContainers(t *testing.T) {
+	output := "3f2a9c\tmcp-exec-python-0123abcd\tpython\tpython:3.12-slim\t2 minutes ago\t2025-03-01 10:58:00 +0000 UTC\t0123abcd\t5e884898\tkey-9f86d081\n" +
+		"9b8e7d\tmcp-executor-4d5e6f\tbash\tubuntu:22.04\t3 hours ago\t2025-03-01 08:00:00 +0000 UTC\t\t\t\n" +
+		"malformed line\n"
+
+	containers := parseContainers(output)
+	if len(containers) != 2 {
+		t.Fatalf("parseContainers() returned %d containers, want 2", len(containers))
+	}
+	created := time.Date(2025, 3, 1, 10, 58, 0, 0, time.UTC)
+	want := Container{
+		ID: "3f2a9c", Name: "mcp-exec-python-0123abcd", Language: "python", Image: "python:3.12-slim", Running: "2 minutes ago", Created: created,
+		Execution: "0123abcd", Session: "5e884898", Client: "key-9f86d081",
+	}
+	if containers[0] != want {
+		t.Errorf("containers[0] = %+v, want %+v", containers[0], want)
+	}
+	if containers[1].Language != "bash" || containers[1].Running != "3 hours ago" || containers[1].Execution != "" {
+		t.Errorf("containers[1] = %+v", containers[1])
+	}
+
+	if empty := parseContainers(""); len(empty) != 0 {
+		t.Errorf("parseContainers(\"\") = %v, want none", empty)
+	}
+}
+
+func TestExpiredContainers(t *testing.T) {
+	now := time.Date(2025, 3, 1, 11, 0, 0, 0, time.UTC)
+	containers := []Container{
+		{Name: "recent", Created: now.Add(-2 * time.Minute)},
+		{Name: "old", Created: now.Add(-3 * time.Hour)},
+		{Name: "unknown"},
+	}
+
+	expired := expiredContainers(containers, time.Hour, now)
+	if len(expired) != 1 || expired[0].Name != "old" {
+		t.Errorf("expiredContainers() = %+v, want only the old container", expired)
+	}
+}
+
+func TestContainerName(t *testing.T) {
+	ctx := WithExecutionID(context.Background(), "0123abcd")
+	for _, want := range []string{"mcp-exec-python-0123abcd", "mcp-exec-python-0123abcd-2", "mcp-exec-python-0123abcd-3"} {
+		if got := containerName(ctx, "python"); got != want {
+			t.Errorf("containerName() = %q, want %q", got, want)
+		}
+	}
+	if got := containerName(context.Background(), "bash"); !strings.HasPrefix(got, "mcp-exec-bash-") || len(got) <= len("mcp-exec-bash-") {
+		t.Errorf("containerName() without execution ID = %q, want a random name", got)
+	}
+}
+
+func TestContainerLabels(t *testing.T) {
+	ctx := WithClientID(WithSessionID(WithExecutionID(context.Background(), "0123abcd"), "session-1"), "key-9f86d081")
+	want := []string{
+		"--label", LanguageLabel + "=go",
+		"--label", ExecutionLabel + "=0123abcd",
+		"--label", SessionLabel + "=" + SessionTag("session-1"),
+		"--label", ClientLabel + "=key-9f86d081",
+	}
+	if got := containerLabels(ctx, "go"); !slices.Equal(got, want) {
+		t.Errorf("containerLabels() = %q, want %q", got, want)
+	}
+	if got := containerLabels(context.Background(), "go"); len(got) != 2 {
+		t.Errorf("containerLabels() without identity = %q, want the language only", got)
+	}
+}
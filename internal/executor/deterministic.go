@@ -0,0 +1,71 @@
+// Package executor provides an executor decorator making repeated executions
+// of the same code reproducible: random seeds are fixed, the clock starts at
+// a fixed time and the network is disabled.
+package executor
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// SeedEnv is the variable holding the seed of deterministic executions, for
+// code seeding its random generators explicitly.
+const SeedEnv = "MCP_EXECUTOR_SEED"
+
+// DeterministicSeed is the seed of deterministic executions.
+const DeterministicSeed = "0"
+
+// DeterministicTime is the time the clock of deterministic executions starts
+// at, unless they request a fake time of their own.
+var DeterministicTime = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// DeterministicExecutor runs the executions requesting it with fixed seeds,
+// the clock started at DeterministicTime and the "none" container network.
+type DeterministicExecutor struct {
+	executor Executor
+	pinClock bool
+}
+
+// NewDeterministicExecutor wraps exec. The clock is only pinned with pinClock,
+// for servers with libfaketime configured, see TimeLocaleExecutor. Host
+// processes in subprocess and Nix mode keep the host's network, which the
+// server cannot take away.
+func NewDeterministicExecutor(exec Executor, pinClock bool) *DeterministicExecutor {
+	return &DeterministicExecutor{executor: exec, pinClock: pinClock}
+}
+
+func (d *DeterministicExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	options := NewOptions(opts...)
+	if !options.Deterministic {
+		return d.executor.Execute(ctx, code, dependencies, envVars, opts...)
+	}
+	if len(dependencies) > 0 || options.DependencyFile != "" {
+		return "", NewExecutionError(ErrorPolicyViolation, "cannot install dependencies in a deterministic execution, which has no network; install them in a workspace first or use preinstalled packages")
+	}
+	if options.Network != "" && options.Network != "none" {
+		return "", NewExecutionError(ErrorPolicyViolation, "network %q is not available in a deterministic execution", options.Network)
+	}
+
+	// Seeds set by the call win, so that a run can be replayed with another one
+	env := make(map[string]string, len(envVars)+3)
+	for key, value := range envVars {
+		env[key] = value
+	}
+	if env[SeedEnv] == "" {
+		env[SeedEnv] = DeterministicSeed
+	}
+	if env["PYTHONHASHSEED"] == "" {
+		env["PYTHONHASHSEED"] = env[SeedEnv]
+	}
+	// The top-level functions of Go's math/rand use a fixed seed
+	if !strings.Contains(env["GODEBUG"], "randautoseed=") {
+		env["GODEBUG"] = strings.TrimPrefix(env["GODEBUG"]+",randautoseed=0", ",")
+	}
+
+	opts = append(opts, WithNetwork("none"))
+	if d.pinClock && options.FakeTime.IsZero() {
+		opts = append(opts, WithFakeTime(DeterministicTime))
+	}
+	return d.executor.Execute(ctx, code, dependencies, env, opts...)
+}
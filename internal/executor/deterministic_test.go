@@ -0,0 +1,95 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDeterministicExecutor(t *testing.T) {
+	tests := []struct {
+		name     string
+		pinClock bool
+		env      map[string]string
+		opts     []Option
+		wantEnv  map[string]string
+		wantTime time.Time
+	}{
+		{
+			name:     "defaults",
+			pinClock: true,
+			wantEnv:  map[string]string{SeedEnv: "0", "PYTHONHASHSEED": "0", "GODEBUG": "randautoseed=0"},
+			wantTime: DeterministicTime,
+		},
+		{
+			name:     "call seed and fake time",
+			pinClock: true,
+			env:      map[string]string{SeedEnv: "42", "GODEBUG": "panicnil=1"},
+			opts:     []Option{WithFakeTime(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC))},
+			wantEnv:  map[string]string{SeedEnv: "42", "PYTHONHASHSEED": "42", "GODEBUG": "panicnil=1,randautoseed=0"},
+			wantTime: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "without libfaketime",
+			env:     map[string]string{"PYTHONHASHSEED": "7"},
+			wantEnv: map[string]string{SeedEnv: "0", "PYTHONHASHSEED": "7", "GODEBUG": "randautoseed=0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &envRecorder{}
+			opts := append([]Option{WithDeterministic()}, tt.opts...)
+			if _, err := NewDeterministicExecutor(env, tt.pinClock).Execute(context.Background(), "", nil, tt.env, opts...); err != nil {
+				t.Fatalf("Execute() returned error: %v", err)
+			}
+			if !reflect.DeepEqual(env.env, tt.wantEnv) {
+				t.Errorf("env = %v, want %v", env.env, tt.wantEnv)
+			}
+
+			recorder := &optionsRecorder{}
+			if _, err := NewDeterministicExecutor(recorder, tt.pinClock).Execute(context.Background(), "", nil, tt.env, opts...); err != nil {
+				t.Fatalf("Execute() returned error: %v", err)
+			}
+			if recorder.options.Network != "none" {
+				t.Errorf("Network = %q, want none", recorder.options.Network)
+			}
+			if !recorder.options.FakeTime.Equal(tt.wantTime) {
+				t.Errorf("FakeTime = %v, want %v", recorder.options.FakeTime, tt.wantTime)
+			}
+		})
+	}
+}
+
+func TestDeterministicExecutor_Refusals(t *testing.T) {
+	exec := NewDeterministicExecutor(&envRecorder{}, true)
+	tests := []struct {
+		name         string
+		dependencies []string
+		opts         []Option
+	}{
+		{"dependencies", []string{"requests"}, nil},
+		{"dependency file", nil, []Option{WithDependencyFile("requests\n")}},
+		{"network", nil, []Option{WithNetwork("bridge")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := exec.Execute(context.Background(), "", tt.dependencies, nil, append(tt.opts, WithDeterministic())...)
+			var execErr *ExecutionError
+			if !errors.As(err, &execErr) || execErr.Code != ErrorPolicyViolation {
+				t.Errorf("Execute() error = %v, want a policy violation", err)
+			}
+		})
+	}
+
+	// Other executions are passed through unchanged
+	env := &envRecorder{}
+	if _, err := NewDeterministicExecutor(env, true).Execute(context.Background(), "", []string{"requests"}, map[string]string{"A": "1"}); err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+	if !reflect.DeepEqual(env.env, map[string]string{"A": "1"}) {
+		t.Errorf("env = %v, want the call's", env.env)
+	}
+}
@@ -0,0 +1,144 @@
+// Package executor enforces disk quotas on the host directories written by
+// executions: the scratch and artifacts directories of each execution and the
+// named workspaces of each MCP session.
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// diskCheckInterval is how often the directories of a running execution are
+// measured.
+const diskCheckInterval = time.Second
+
+// DiskQuota bounds the disk space used by executions. Zero values disable a
+// limit.
+type DiskQuota struct {
+	ExecutionMB int // Scratch and artifacts directories of an execution; a tmpfs size in Docker mode
+	SessionMB   int // Named workspaces of an MCP session
+}
+
+// DiskQuotaExecutor kills executions whose directories outgrow the quota and
+// reports them with a TerminationError of reason TerminationDisk. Host
+// directories are measured every diskCheckInterval and once more when the
+// execution ends; Docker containers are limited by their tmpfs instead.
+type DiskQuotaExecutor struct {
+	executor Executor
+	quota    DiskQuota
+}
+
+// NewDiskQuotaExecutor wraps exec, which must run inside SandboxEnvExecutor,
+// WorkspaceExecutor and ArtifactsExecutor to see their directories. It returns
+// exec unchanged when quota has no limit.
+func NewDiskQuotaExecutor(exec Executor, quota DiskQuota) Executor {
+	if quota.ExecutionMB <= 0 && quota.SessionMB <= 0 {
+		return exec
+	}
+	return &DiskQuotaExecutor{executor: exec, quota: quota}
+}
+
+// diskQuotaError is the cause of cancelling an execution over its quota.
+type diskQuotaError struct {
+	message string
+}
+
+func (e *diskQuotaError) Error() string {
+	return e.message
+}
+
+func (d *DiskQuotaExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	options := NewOptions(opts...)
+	var executionDirs []string
+	for _, dir := range []string{options.ScratchDir, options.ArtifactsDir} {
+		if dir != "" && d.quota.ExecutionMB > 0 {
+			executionDirs = append(executionDirs, dir)
+		}
+	}
+	// All workspaces of the session count; an execution may clean up a session
+	// that is over its quota but not grow it further
+	sessionDir, sessionLimit := "", int64(0)
+	if options.WorkspaceDir != "" && d.quota.SessionMB > 0 {
+		sessionDir = filepath.Dir(options.WorkspaceDir)
+		sessionLimit = max(int64(d.quota.SessionMB)<<20, diskUsage(sessionDir))
+	}
+	if len(executionDirs) == 0 && sessionDir == "" {
+		return d.executor.Execute(ctx, code, dependencies, envVars, opts...)
+	}
+
+	check := func() error {
+		if used := diskUsage(executionDirs...); len(executionDirs) > 0 && used > int64(d.quota.ExecutionMB)<<20 {
+			return &diskQuotaError{fmt.Sprintf("execution exceeded its disk quota of %d MB", d.quota.ExecutionMB)}
+		}
+		if sessionDir != "" && diskUsage(sessionDir) > sessionLimit {
+			return &diskQuotaError{fmt.Sprintf("execution exceeded the workspace disk quota of %d MB", d.quota.SessionMB)}
+		}
+		return nil
+	}
+
+	quotaCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(diskCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := check(); err != nil {
+					cancel(err)
+					return
+				}
+			}
+		}
+	}()
+	output, err := d.executor.Execute(quotaCtx, code, dependencies, envVars, opts...)
+	close(done)
+
+	var exceeded *diskQuotaError
+	if !errors.As(context.Cause(quotaCtx), &exceeded) {
+		// Fast executions may finish between checks
+		if checkErr := check(); checkErr != nil && ctx.Err() == nil {
+			errors.As(checkErr, &exceeded)
+		}
+	}
+	if exceeded == nil {
+		return output, err
+	}
+	logger.WarnContext(ctx, "Execution stopped: %s", exceeded)
+	stopped := &TerminationError{Reason: TerminationDisk, Message: exceeded.Error(), Output: output}
+	var terminated *TerminationError
+	if errors.As(err, &terminated) {
+		stopped.Signal = terminated.Signal
+		stopped.Output = terminated.Output
+	}
+	return output, stopped
+}
+
+// diskUsage returns the bytes allocated to the files below dirs, ignoring
+// files that cannot be read.
+func diskUsage(dirs ...string) int64 {
+	var total int64
+	for _, dir := range dirs {
+		_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return nil
+			}
+			total += allocatedSize(info)
+			return nil
+		})
+	}
+	return total
+}
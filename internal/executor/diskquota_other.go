@@ -0,0 +1,12 @@
+//go:build !unix
+
+// Package executor measures the disk space of files from their size on
+// systems without allocated block counts.
+package executor
+
+import "io/fs"
+
+// allocatedSize returns the size of the file of info.
+func allocatedSize(info fs.FileInfo) int64 {
+	return info.Size()
+}
@@ -0,0 +1,67 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiskQuotaExecutor(t *testing.T) {
+	root := t.TempDir()
+	ctx := WithSessionID(context.Background(), "session-1")
+	quota := DiskQuota{ExecutionMB: 1, SessionMB: 2}
+	exec := NewWorkspaceExecutor(NewSandboxEnvExecutor(NewDiskQuotaExecutor(NewSubprocessBashExecutor(), quota), "subprocess"), root)
+
+	tests := []struct {
+		name       string
+		script     string
+		workspace  string
+		wantReason string
+	}{
+		{"within quota", "head -c 100000 /dev/zero > small && echo done", "", ""},
+		{"exceeded after exit", "head -c 3000000 /dev/zero > big && echo done", "", TerminationDisk},
+		{"killed while running", "head -c 3000000 /dev/zero > big && echo done && sleep 30", "", TerminationDisk},
+		{"workspace within quota", `head -c 1000000 /dev/zero > "$MCP_WORKSPACE/data" && echo done`, "shared", ""},
+		{"workspace exceeded", `head -c 3000000 /dev/zero > "$MCP_WORKSPACE/more" && echo done`, "shared", TerminationDisk},
+		{"over-quota workspace cleaned up", `rm "$MCP_WORKSPACE/more" && echo done`, "shared", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			startedAt := time.Now()
+			output, err := exec.Execute(ctx, tt.script, nil, nil, WithWorkspace(tt.workspace))
+			if elapsed := time.Since(startedAt); elapsed > 10*time.Second {
+				t.Errorf("Execute() took %s, want the execution killed", elapsed)
+			}
+			var terminated *TerminationError
+			if tt.wantReason == "" {
+				if err != nil {
+					t.Fatalf("Execute() returned error: %v", err)
+				}
+			} else if !errors.As(err, &terminated) || terminated.Reason != tt.wantReason {
+				t.Fatalf("Execute() error = %v, want a %s termination", err, tt.wantReason)
+			}
+			if !strings.Contains(output+resultOutput(terminated), "done") {
+				t.Errorf("Output = %q, want the output of the execution", output)
+			}
+		})
+	}
+}
+
+// resultOutput returns the output kept by terminated, if any.
+func resultOutput(terminated *TerminationError) string {
+	if terminated == nil {
+		return ""
+	}
+	return terminated.Output
+}
+
+func TestTmpfsMount(t *testing.T) {
+	if got := tmpfsMount(0); got != "/tmp" {
+		t.Errorf("tmpfsMount(0) = %q, want /tmp", got)
+	}
+	if got := tmpfsMount(512); got != "/tmp:exec,size=512m" {
+		t.Errorf("tmpfsMount(512) = %q", got)
+	}
+}
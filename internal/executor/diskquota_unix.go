@@ -0,0 +1,18 @@
+//go:build unix
+
+// Package executor measures the disk space of files from the blocks allocated
+// to them.
+package executor
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// allocatedSize returns the bytes allocated to the file of info.
+func allocatedSize(info fs.FileInfo) int64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return int64(stat.Blocks) * 512
+	}
+	return info.Size()
+}
@@ -3,10 +3,20 @@
 package executor
 
 import (
+	"cmp"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"math"
 	"os/exec"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/ylchen07/mcp-executor/internal/logger"
 )
@@ -16,97 +26,638 @@ type ExecutorConfig struct {
 	InstallCmd   []string
 	ExecuteCmd   []string
 	ExecutorName string
+
+	// CheckCmd checks the code read from stdin without running it, see
+	// checkArgs. Executors without one reject check-only calls.
+	CheckCmd []string
+
+	// PipeCmd runs the code of executions with input, which reads the code
+	// from the start of stdin, see pipeCmd. Executors without one reject
+	// such executions.
+	PipeCmd []string
+
+	// ManifestFile names the dependency manifest of the language (e.g.
+	// requirements.txt) and ManifestInstallCmd installs it from the working
+	// directory. Executors without a manifest reject dependency files.
+	ManifestFile       string
+	ManifestInstallCmd []string
+
+	// ManifestExecuteCmd runs the code read from stdin when a manifest is
+	// installed, from a file next to it so that its settings, such as the
+	// module type of package.json, apply. Empty uses ExecuteCmd.
+	ManifestExecuteCmd []string
+
+	// Files allows executions to write files next to the code, see WithFiles;
+	// executors without it reject them.
+	Files bool
+
+	// Memory and CPUs cap the container resources (docker run --memory / --cpus).
+	// Empty values leave Docker's defaults.
+	Memory string
+	CPUs   string
+
+	// DiskMB sizes the tmpfs mounted on /tmp, which holds the working
+	// directory; zero keeps /tmp on the container filesystem.
+	DiskMB int
+
+	// InstallTimeout bounds the dependency installation step; zero means no separate limit.
+	InstallTimeout time.Duration
+
+	// AllowedMountRoots lists the host directories that may be bind-mounted
+	// into containers. Host mounts are rejected when empty.
+	AllowedMountRoots []string
+
+	// Host describes where the daemon runs; mount sources are translated to
+	// paths on its host when the server runs in a container.
+	Host DockerHost
+
+	// Environments are the named environments selected by the profile argument.
+	Environments map[string]Environment
+
+	// RuntimeImages maps runtime versions (e.g. "3.12") to the image used when a
+	// call requests that version.
+	RuntimeImages map[string]string
+
+	// BrowserImage replaces Image for the executions using a browser, see
+	// WithBrowserImage.
+	BrowserImage string
+
+	// Preinstalled lists the packages of the image derived from Image that
+	// executions run in, see WithPreinstalledPackages.
+	Preinstalled []string
+
+	// APTCacheVolume names the Docker volume caching apt downloads, see
+	// WithAPTCache.
+	APTCacheVolume string
+
+	// GoCacheVolume names the Docker volume keeping the Go build and module
+	// caches, see WithGoCache.
+	GoCacheVolume string
 }
 
-type DockerExecutor struct {
-	config ExecutorConfig
+// DockerOption customizes the ExecutorConfig of a Docker executor.
+type DockerOption func(*ExecutorConfig)
+
+// WithAllowedMountRoots sets the host directories that may be mounted into containers.
+func WithAllowedMountRoots(roots []string) DockerOption {
+	return func(c *ExecutorConfig) {
+		c.AllowedMountRoots = roots
+	}
+}
+
+// WithImage overrides the default image of the executor. An empty image keeps the default.
+func WithImage(image string) DockerOption {
+	return func(c *ExecutorConfig) {
+		if image != "" {
+			c.Image = image
+		}
+	}
 }
 
-func NewPythonExecutor() *DockerExecutor {
-	return &DockerExecutor{
-		config: ExecutorConfig{
-			Image:        "mcr.microsoft.com/playwright/python:v1.53.0-noble",
-			InstallCmd:   []string{"python", "-m", "pip", "install", "--quiet"},
-			ExecuteCmd:   []string{"python"},
-			ExecutorName: "python",
-		},
+// WithResourceLimits caps the memory and CPUs available to each container.
+func WithResourceLimits(memory, cpus string) DockerOption {
+	return func(c *ExecutorConfig) {
+		c.Memory = memory
+		c.CPUs = cpus
 	}
 }
 
-func NewBashExecutor() *DockerExecutor {
-	return &DockerExecutor{
-		config: ExecutorConfig{
-			Image:        "ubuntu:22.04",
-			InstallCmd:   []string{"apt-get", "update", "-qq", "&&", "apt-get", "install", "-y", "-qq"},
-			ExecuteCmd:   []string{"bash"},
-			ExecutorName: "bash",
-		},
+// WithDiskLimit mounts a tmpfs of diskMB megabytes on /tmp of each container,
+// bounding what executions write to their working directory. Zero disables it.
+func WithDiskLimit(diskMB int) DockerOption {
+	return func(c *ExecutorConfig) {
+		c.DiskMB = diskMB
 	}
 }
 
-func NewTypeScriptExecutor() *DockerExecutor {
-	return &DockerExecutor{
-		config: ExecutorConfig{
-			Image:        "node:22-alpine",
-			InstallCmd:   []string{"npm", "install", "-g"},
-			ExecuteCmd:   []string{"tsx"},
-			ExecutorName: "typescript",
-		},
+// WithInstallTimeout limits how long dependency installation may take inside the container.
+func WithInstallTimeout(timeout time.Duration) DockerOption {
+	return func(c *ExecutorConfig) {
+		c.InstallTimeout = timeout
 	}
 }
 
-func NewGoExecutor() *DockerExecutor {
-	return &DockerExecutor{
-		config: ExecutorConfig{
-			Image:        "golang:1.23",
-			InstallCmd:   []string{"go", "get"},
-			ExecuteCmd:   []string{"go", "run", "-"},
-			ExecutorName: "go",
-		},
+// WithRuntimeImages sets the images selected by the runtime version of a call.
+func WithRuntimeImages(images map[string]string) DockerOption {
+	return func(c *ExecutorConfig) {
+		c.RuntimeImages = images
+	}
+}
+
+// WithCommands overrides the shell words installing dependencies, which
+// follow them as arguments, and the command running the code read on stdin,
+// which also runs the code file of executions with input or a manifest. Empty
+// commands keep the defaults.
+func WithCommands(install, execute []string) DockerOption {
+	return func(c *ExecutorConfig) {
+		if len(install) > 0 {
+			c.InstallCmd = slices.Clone(install)
+		}
+		if len(execute) > 0 {
+			c.ExecuteCmd = slices.Clone(execute)
+			if len(c.PipeCmd) > 0 {
+				c.PipeCmd = pipeCmd(codeFileNames[c.ExecutorName], execute...)
+			}
+			if len(c.ManifestExecuteCmd) > 0 {
+				c.ManifestExecuteCmd = fileCmd(codeFileNames[c.ExecutorName], execute...)
+			}
+		}
 	}
 }
 
-func (d *DockerExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string) (string, error) {
+type DockerExecutor struct {
+	config ExecutorConfig
+}
+
+func newDockerExecutor(config ExecutorConfig, opts []DockerOption) *DockerExecutor {
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return &DockerExecutor{config: config}
+}
+
+func NewPythonExecutor(opts ...DockerOption) *DockerExecutor {
+	return newDockerExecutor(ExecutorConfig{
+		Image:        "python:3.12-slim",
+		BrowserImage: BrowserImage,
+		InstallCmd:   []string{"python", "-m", "pip", "install", "--quiet"},
+		ExecuteCmd:   []string{"python"},
+		ExecutorName: "python",
+		CheckCmd:     fileCmd("main.py", "python", "-m", "py_compile"),
+		PipeCmd:      pipeCmd("main.py", "python"),
+
+		ManifestFile:       "requirements.txt",
+		ManifestInstallCmd: []string{"python", "-m", "pip", "install", "--quiet", "-r", "requirements.txt"},
+	}, opts)
+}
+
+func NewBashExecutor(opts ...DockerOption) *DockerExecutor {
+	return newDockerExecutor(ExecutorConfig{
+		Image:        "ubuntu:22.04",
+		InstallCmd:   []string{"apt-get", "update", "-qq", "&&", "DEBIAN_FRONTEND=noninteractive", "apt-get", "install", "-y", "-qq", "--no-install-recommends"},
+		ExecuteCmd:   []string{"bash"},
+		ExecutorName: "bash",
+		CheckCmd:     fileCmd("main.sh", "bash", "-n"),
+		PipeCmd:      pipeCmd("main.sh", "bash"),
+	}, opts)
+}
+
+func NewTypeScriptExecutor(opts ...DockerOption) *DockerExecutor {
+	return newDockerExecutor(ExecutorConfig{
+		Image:        "node:22-alpine",
+		InstallCmd:   []string{"npm", "install", "-g"},
+		ExecuteCmd:   []string{"tsx"},
+		ExecutorName: "typescript",
+		CheckCmd:     fileCmd("index.ts", append([]string{"npx", "--yes", "--package", "typescript", "tsc"}, tscFlags...)...),
+		PipeCmd:      pipeCmd("index.ts", "tsx"),
+
+		ManifestFile:       "package.json",
+		ManifestInstallCmd: []string{"npm", "install", "--silent"},
+		ManifestExecuteCmd: fileCmd("index.ts", "tsx"),
+	}, opts)
+}
+
+// NewGoExecutor returns the Go executor, which runs the code as the main
+// package of a module, see goModuleCmd.
+func NewGoExecutor(opts ...DockerOption) *DockerExecutor {
+	return newDockerExecutor(ExecutorConfig{
+		Image:        "golang:1.23",
+		InstallCmd:   append(slices.Clone(goModInit), "&&", "go", "get"),
+		ExecuteCmd:   goModuleCmd([]string{"cat", ">", "main.go"}, "go", "run"),
+		ExecutorName: "go",
+		CheckCmd:     goModuleCmd([]string{"cat", ">", "main.go"}, "go", "vet"),
+		PipeCmd:      goModuleCmd(pipeWrite("main.go"), "go", "run"),
+		Files:        true,
+
+		ManifestFile:       "go.mod",
+		ManifestInstallCmd: []string{"go", "mod", "download"},
+	}, opts)
+}
+
+// fileCmd returns the shell words writing the code read from stdin to file
+// and running command on it, e.g. to check it.
+func fileCmd(file string, command ...string) []string {
+	return append(append([]string{"cat", ">", file, "&&"}, command...), file)
+}
+
+// codeSizeEnv holds the size in bytes of the code at the start of stdin in
+// containers of executions with input.
+const codeSizeEnv = "MCP_EXECUTOR_CODE_SIZE"
+
+// pipeCmd returns the shell words writing the code at the start of stdin to
+// file and running it with command, which reads the rest of stdin.
+func pipeCmd(file string, command ...string) []string {
+	return append(slices.Concat(pipeWrite(file), []string{"&&"}, command), file)
+}
+
+// pipeWrite returns the shell words writing the code at the start of stdin to
+// file. dd copies the code a byte at a time, so that none of the input is
+// consumed.
+func pipeWrite(file string) []string {
+	return []string{"dd", "bs=1", `count="$` + codeSizeEnv + `"`, "of=" + file, "2>/dev/null"}
+}
+
+func (d *DockerExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
 	logger.Debug("Starting %s execution", d.config.ExecutorName)
+	options := NewOptions(opts...)
+
+	mounts, err := ValidateMounts(options.Mounts, d.config.AllowedMountRoots)
+	if err != nil {
+		return "", err
+	}
+	for i := range mounts {
+		if mounts[i].Source, err = d.config.Host.HostPath(mounts[i].Source); err != nil {
+			return "", fmt.Errorf("invalid mount source %q: %v", options.Mounts[i].Source, err)
+		}
+	}
+	if options.WorkspaceDir != "" {
+		source, err := d.config.Host.HostPath(options.WorkspaceDir)
+		if err != nil {
+			return "", fmt.Errorf("workspace %s: %v", options.Workspace, err)
+		}
+		mounts = append(mounts, Mount{Source: source, Target: SharedWorkspace})
+	}
+	if options.ReadOnly {
+		mounts = readOnlyMounts(mounts)
+	}
+	if options.ArtifactsDir != "" {
+		// Reports are optional, so code still runs when the daemon cannot see the directory
+		if mount, err := artifactsMount(d.config.Host, options.ArtifactsDir); err != nil {
+			logger.Debug("Not mounting the artifacts directory: %v", err)
+		} else {
+			mounts = append(mounts, mount)
+		}
+	}
+	dependencies, err = ValidateDependencies(d.config.ExecutorName, dependencies)
+	if err != nil {
+		return "", err
+	}
+	if options.Snapshot != "" {
+		if err := CheckSnapshotName(options.Snapshot); err != nil {
+			return "", err
+		}
+	}
+	image, err := d.image(options.RuntimeVersion)
+	if err != nil {
+		return "", err
+	}
+	switch snapshot := SnapshotImage(ctx, d.config.ExecutorName); {
+	case snapshot != "" && options.RuntimeVersion == "" && options.Profile == "":
+		// Sessions that restored a snapshot run in its image
+		image = snapshot
+	case d.usesBrowserImage(code, dependencies, options):
+		logger.DebugContext(ctx, "Running %s execution in browser image %s", d.config.ExecutorName, d.config.BrowserImage)
+		image = d.config.BrowserImage
+	case options.RuntimeVersion == "" && options.Profile == "":
+		if image, _, err = d.preinstalledImage(ctx); err != nil {
+			return "", err
+		}
+	}
+	if options.Profile != "" {
+		if options.RuntimeVersion != "" {
+			return "", NewExecutionError(ErrorPolicyViolation, "profile and runtime_version cannot be combined: the profile selects the image")
+		}
+		environment, err := d.environment(options.Profile)
+		if err != nil {
+			return "", err
+		}
+		if image, err = d.environmentImage(ctx, environment); err != nil {
+			return "", err
+		}
+		envVars = environmentVars(environment.Env, envVars)
+
+		// The limits of the environment apply to this execution only
+		config := d.config
+		config.Memory, config.CPUs = cmp.Or(environment.Memory, config.Memory), cmp.Or(environment.CPUs, config.CPUs)
+		d = &DockerExecutor{config: config}
+	}
 
-	cmdArgs := []string{
-		"run",
-		"--rm",
-		"-i",
+	// Name the container so it can be killed when the execution is cancelled;
+	// killing the docker CLI alone leaves the container running.
+	containerName := containerName(ctx, d.config.ExecutorName)
+	cmdArgs := append([]string{"run", "-i", "--name", containerName}, containerLabels(ctx, d.config.ExecutorName)...)
+	if options.Snapshot == "" {
+		cmdArgs = append(cmdArgs, "--rm")
+	} else {
+		// The container of a snapshot is removed once it is committed
+		defer removeContainer(containerName)
 	}
 
 	// Add environment variables
 	for key, value := range envVars {
 		cmdArgs = append(cmdArgs, "-e", key+"="+value)
 	}
+	cmdArgs = append(cmdArgs, d.config.goCacheArgs(envVars)...)
 
-	cmdArgs = append(cmdArgs, d.config.Image)
-	shArgs := []string{}
+	// Docker creates the working directory, which is the workspace of the execution
+	cmdArgs = append(cmdArgs, "-w", ContainerWorkspace)
 
-	if len(dependencies) > 0 {
-		logger.Debug("Installing dependencies: %v", dependencies)
-		shArgs = append(shArgs, d.config.InstallCmd...)
-		shArgs = append(shArgs, dependencies...)
-		shArgs = append(shArgs, "&&")
+	if options.CheckOnly && len(d.config.CheckCmd) == 0 {
+		return "", NewExecutionError(ErrorPolicyViolation, "check_only is not supported for %s", d.config.ExecutorName)
+	}
+
+	// The manifest is passed in an environment variable and written to the
+	// working directory by the install step, so its content never reaches the shell.
+	if options.DependencyFile != "" {
+		if d.config.ManifestFile == "" {
+			return "", NewExecutionError(ErrorPolicyViolation, "dependency_file is not supported for %s", d.config.ExecutorName)
+		}
+		if err := ValidateManifest(d.config.ManifestFile, options.DependencyFile); err != nil {
+			return "", err
+		}
+		cmdArgs = append(cmdArgs, "-e", dependencyFileEnv+"="+options.DependencyFile)
+	}
+	if len(options.Files) > 0 {
+		if !d.config.Files {
+			return "", NewExecutionError(ErrorPolicyViolation, "files are not supported for %s", d.config.ExecutorName)
+		}
+		if err := ValidateFiles(options.Files, codeFileNames[d.config.ExecutorName], d.config.ManifestFile); err != nil {
+			return "", err
+		}
+		archive, err := filesArchive(options.Files)
+		if err != nil {
+			return "", err
+		}
+		cmdArgs = append(cmdArgs, "-e", filesEnv+"="+archive)
+	}
+
+	// The input follows the code on stdin
+	stdin := io.Reader(strings.NewReader(code))
+	if input, piped := Stdin(ctx); piped && !options.CheckOnly {
+		if len(d.config.PipeCmd) == 0 {
+			return "", NewExecutionError(ErrorPolicyViolation, "stdin_from is not supported for %s", d.config.ExecutorName)
+		}
+		config := d.config
+		config.ExecuteCmd, config.ManifestExecuteCmd = config.PipeCmd, nil
+		d = &DockerExecutor{config: config}
+		cmdArgs = append(cmdArgs, "-e", codeSizeEnv+"="+strconv.Itoa(len(code)))
+		stdin = io.MultiReader(stdin, strings.NewReader(input))
+	}
+
+	if d.config.Memory != "" {
+		cmdArgs = append(cmdArgs, "--memory", d.config.Memory)
+	}
+	if d.config.CPUs != "" {
+		cmdArgs = append(cmdArgs, "--cpus", d.config.CPUs)
+	}
+
+	if options.Network != "" {
+		cmdArgs = append(cmdArgs, "--network", options.Network)
+	}
+
+	if options.ReadOnly {
+		cmdArgs = append(cmdArgs, readOnlyDockerArgs()...)
+	}
+	if options.ReadOnly || d.config.DiskMB > 0 {
+		cmdArgs = append(cmdArgs, "--tmpfs", tmpfsMount(d.config.DiskMB))
 	}
 
-	shArgs = append(shArgs, d.config.ExecuteCmd...)
-	cmdArgs = append(cmdArgs, "sh", "-c", strings.Join(shArgs, " "))
+	// Add host mounts (validated against the allowed roots above)
+	if len(mounts) > 0 {
+		logger.Debug("Mounting host paths: %v", mounts)
+		cmdArgs = append(cmdArgs, mountArgs(mounts)...)
+	}
+
+	installing := len(dependencies) > 0 || options.DependencyFile != ""
+	if len(dependencies) > 0 {
+		logger.InfoContext(ctx, "Installing %s dependencies: %s", d.config.ExecutorName, strings.Join(dependencies, ", "))
+		if d.config.APTCacheVolume != "" {
+			cmdArgs = append(cmdArgs, "--mount", "type=volume,source="+d.config.APTCacheVolume+",target="+APTCacheDir)
+		}
+	}
+	if options.DependencyFile != "" {
+		logger.InfoContext(ctx, "Installing %s dependencies from %s", d.config.ExecutorName, d.config.ManifestFile)
+	}
+	cmdArgs = append(cmdArgs, image)
+	// The install timeout of the call takes precedence over the one of the executor
+	install := cmp.Or(installTimeout(ctx), d.config.InstallTimeout)
+	cmdArgs = append(cmdArgs, d.shellCommand(dependencies, options.DependencyFile != "", options.CheckOnly, len(options.Files) > 0, install)...)
 
 	logger.Verbose("Executing Docker command: docker %s", strings.Join(cmdArgs, " "))
 	logger.Debug("Code to execute:\n%s", code)
 
 	cmd := exec.CommandContext(ctx, "docker", cmdArgs...)
-	cmd.Stdin = strings.NewReader(code)
+	cmd.Cancel = func() error {
+		logger.WarnContext(ctx, "Execution cancelled, killing container %s", containerName)
+		_ = exec.Command("docker", "kill", containerName).Run()
+		return cmd.Process.Kill()
+	}
+	cmd.Stdin = stdin
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	startedAt := time.Now()
 	out, err := cmd.Output()
+	stderrText, usage := splitContainerUsage(stderr.String(), time.Since(startedAt))
+	reportUsage(ctx, usage)
+	installed := false
+	if installing {
+		stderrText, installed = splitInstalled(stderrText)
+	}
 	if err != nil {
 		logger.Debug("Execution failed: %v", err)
 		if exitError, ok := err.(*exec.ExitError); ok {
-			return "", fmt.Errorf("%s exited with code %d: %s", d.config.ExecutorName, exitError.ExitCode(), string(exitError.Stderr))
+			// The code, which only runs once the dependencies are installed,
+			// may exit with the statuses of a failed install too
+			if installing && !installed {
+				if err := d.installErr(ctx, exitError.ExitCode(), install, stderrText); err != nil {
+					return "", err
+				}
+			}
+			// Exit statuses from 125 are those of Docker, the shell and signals
+			if options.CheckOnly && exitError.ExitCode() < 125 {
+				return "", newCompileError(d.config.ExecutorName, string(out)+stderrText)
+			}
+			return "", d.exitErr(exitError.ExitCode(), usage, string(out), stderrText)
 		}
 		return "", fmt.Errorf("execution failed: %v", err)
 	}
 
 	logger.Debug("Execution completed successfully, output length: %d bytes", len(out))
+	if options.Snapshot != "" {
+		if err := commitContainer(ctx, containerName, d.config.ExecutorName, options); err != nil {
+			return "", err
+		}
+	}
 	return string(out), nil
 }
+
+// exitErr describes a failed container from its exit code. Containers exit
+// with 128 plus the signal number when a signal, such as the kernel's OOM
+// killer, stops the code; the output so far is then kept.
+func (d *DockerExecutor) exitErr(code int, usage Usage, stdout, stderr string) error {
+	switch {
+	case usage.OOMKilled:
+		return &TerminationError{
+			Reason:  TerminationOOM,
+			Signal:  syscall.SIGKILL.String(),
+			Message: fmt.Sprintf("%s ran out of memory and was killed (memory limit %s)", d.config.ExecutorName, cmp.Or(d.config.Memory, "unset")),
+			Output:  stdout + stderr,
+		}
+	case d.config.DiskMB > 0 && strings.Contains(stdout+stderr, noSpaceMessage):
+		return &TerminationError{
+			Reason:  TerminationDisk,
+			Message: fmt.Sprintf("%s ran out of disk space (disk limit %d MB)", d.config.ExecutorName, d.config.DiskMB),
+			Output:  stdout + stderr,
+		}
+	case code > 128 && code < 128+65:
+		signal := syscall.Signal(code - 128)
+		return &TerminationError{
+			Reason:  TerminationSignal,
+			Signal:  signal.String(),
+			Message: fmt.Sprintf("%s was killed by signal %s", d.config.ExecutorName, signal),
+			Output:  stdout + stderr,
+		}
+	}
+	if compiled := compileError(d.config.ExecutorName, stderr); compiled != nil {
+		return compiled
+	}
+	return NewExecutionError(ErrorRuntime, "%s exited with code %d: %s", d.config.ExecutorName, code, stderr)
+}
+
+// installFailedStatus is the exit status of containers whose dependencies
+// could not be installed.
+const installFailedStatus = 121
+
+// installedMarker is printed to stderr by containers once their dependencies
+// are installed, which tells a failed or timed out install apart from code
+// exiting with the same status afterwards.
+const installedMarker = "MCP_EXECUTOR_INSTALLED"
+
+// splitInstalled removes the line printed by containers once their
+// dependencies are installed from stderr and reports whether it was found.
+func splitInstalled(stderr string) (string, bool) {
+	before, after, found := strings.Cut(stderr, "\n"+installedMarker+"\n")
+	if !found {
+		return stderr, false
+	}
+	return before + after, true
+}
+
+// installErr returns the error of a container that exited with code before
+// its dependencies were installed within install, unless zero, or nil when the
+// code is not that of a failed install.
+func (d *DockerExecutor) installErr(ctx context.Context, code int, install time.Duration, stderr string) error {
+	switch {
+	case install > 0 && code == 124:
+		logger.WarnContext(ctx, "Dependency installation exceeded the %s install timeout", install)
+		return installTimeoutError(d.config.ExecutorName, install)
+	case code == installFailedStatus:
+		return newInstallError(d.config.ExecutorName, d.config.ExecutorName, stderr)
+	}
+	return nil
+}
+
+// noSpaceMessage is the description of ENOSPC printed by code writing to a
+// full filesystem.
+const noSpaceMessage = "No space left on device"
+
+// tmpfsMount returns the docker run --tmpfs mount of /tmp, sized diskMB
+// megabytes unless zero. Programs built there, e.g. by go run, must be able to
+// run, so the mount allows execution.
+func tmpfsMount(diskMB int) string {
+	if diskMB <= 0 {
+		return "/tmp"
+	}
+	return fmt.Sprintf("/tmp:exec,size=%dm", diskMB)
+}
+
+// dependencyFileEnv holds the dependency manifest content inside the container,
+// which is written to the working directory before installing it.
+const dependencyFileEnv = "MCP_EXECUTOR_DEPENDENCY_FILE"
+
+// shellCommand returns the container command installing dependencies and the
+// manifest (when set), within install unless zero, before running the code,
+// or with check before checking it; with files, the files of the execution are
+// extracted next to the code first. The dependencies are passed to
+// sh as positional parameters after the script, so they reach the installer
+// as discrete arguments and are never parsed by the shell.
+func (d *DockerExecutor) shellCommand(dependencies []string, manifest, check, files bool, install time.Duration) []string {
+	var shArgs []string
+	if len(dependencies) > 0 || manifest {
+		installArgs := d.installArgs(len(dependencies) > 0, manifest)
+		if d.config.APTCacheVolume != "" && len(dependencies) > 0 {
+			installArgs = aptCacheInstall(installArgs)
+		}
+		// A failed install exits with installFailedStatus, the timeout with
+		// 124; a successful one prints installedMarker
+		failed := "exit " + strconv.Itoa(installFailedStatus)
+		if install > 0 {
+			seconds := strconv.Itoa(int(math.Ceil(install.Seconds())))
+			shArgs = append(shArgs, "timeout", seconds, "sh", "-c", shellQuote(strings.Join(installArgs, " ")+" || "+failed), "sh", `"$@"`)
+		} else {
+			shArgs = append(shArgs, installArgs...)
+			shArgs = append(shArgs, "||", "("+failed+")")
+		}
+		shArgs = append(shArgs, "&&", "printf", `'\n`+installedMarker+`\n'`, ">&2", "&&")
+	}
+	if files {
+		shArgs = append(shArgs, filesExtract...)
+	}
+	switch {
+	case check:
+		shArgs = append(shArgs, d.config.CheckCmd...)
+	case manifest && len(d.config.ManifestExecuteCmd) > 0:
+		shArgs = append(shArgs, d.config.ManifestExecuteCmd...)
+	default:
+		shArgs = append(shArgs, d.config.ExecuteCmd...)
+	}
+
+	// The exit status of the execution is kept while the cgroup usage is
+	// reported, and the first argument after the script is $0
+	command := []string{"sh", "-c", "{ " + strings.Join(shArgs, " ") + "; }; status=$?; " + containerUsageScript + "; exit $status"}
+	if len(dependencies) > 0 {
+		command = append(append(command, "sh"), dependencies...)
+	}
+	return command
+}
+
+// installArgs returns the shell words installing the dependencies, passed as
+// the positional parameters after "--" so that none is parsed as an option,
+// and, when manifest is set, the dependency manifest.
+func (d *DockerExecutor) installArgs(dependencies, manifest bool) []string {
+	var args []string
+	if manifest {
+		args = append(args, "printf", "'%s'", `"$`+dependencyFileEnv+`"`, ">", d.config.ManifestFile, "&&")
+		args = append(args, d.config.ManifestInstallCmd...)
+	}
+	if dependencies {
+		if manifest {
+			args = append(args, "&&")
+		}
+		args = append(args, d.config.InstallCmd...)
+		args = append(args, "--", `"$@"`)
+	}
+	return args
+}
+
+// image returns the image for the requested runtime version (the default image
+// when version is empty).
+func (d *DockerExecutor) image(version string) (string, error) {
+	if version == "" {
+		return d.config.Image, nil
+	}
+	if image, ok := d.config.RuntimeImages[version]; ok {
+		return image, nil
+	}
+	versions := make([]string, 0, len(d.config.RuntimeImages))
+	for available := range d.config.RuntimeImages {
+		versions = append(versions, available)
+	}
+	if len(versions) == 0 {
+		return "", NewExecutionError(ErrorPolicyViolation, "runtime_version %q is not available for %s: no runtime versions are configured", version, d.config.ExecutorName)
+	}
+	sort.Strings(versions)
+	return "", NewExecutionError(ErrorPolicyViolation, "runtime_version %q is not available for %s (available: %s)", version, d.config.ExecutorName, strings.Join(versions, ", "))
+}
+
+// randomSuffix returns a short random hex string for container names.
+func randomSuffix() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// shellQuote quotes s as a single POSIX shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -1,8 +1,11 @@
 package executor
 
 import (
+	"context"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewPythonExecutor(t *testing.T) {
@@ -16,8 +19,8 @@ func TestNewPythonExecutor(t *testing.T) {
 		t.Errorf("ExecutorName = %q, want %q", executor.config.ExecutorName, "python")
 	}
 
-	if executor.config.Image != "mcr.microsoft.com/playwright/python:v1.53.0-noble" {
-		t.Errorf("Image = %q, want %q", executor.config.Image, "mcr.microsoft.com/playwright/python:v1.53.0-noble")
+	if executor.config.Image != "python:3.12-slim" {
+		t.Errorf("Image = %q, want %q", executor.config.Image, "python:3.12-slim")
 	}
 
 	expectedInstallCmd := []string{"python", "-m", "pip", "install", "--quiet"}
@@ -51,7 +54,7 @@ func TestNewBashExecutor(t *testing.T) {
 		t.Errorf("Image = %q, want %q", executor.config.Image, "ubuntu:22.04")
 	}
 
-	expectedInstallCmd := []string{"apt-get", "update", "-qq", "&&", "apt-get", "install", "-y", "-qq"}
+	expectedInstallCmd := []string{"apt-get", "update", "-qq", "&&", "DEBIAN_FRONTEND=noninteractive", "apt-get", "install", "-y", "-qq", "--no-install-recommends"}
 	if len(executor.config.InstallCmd) != len(expectedInstallCmd) {
 		t.Errorf("InstallCmd length = %d, want %d", len(executor.config.InstallCmd), len(expectedInstallCmd))
 	}
@@ -81,7 +84,7 @@ func TestDockerExecutor_CommandConstruction_NoDependencies(t *testing.T) {
 			executor:    NewPythonExecutor(),
 			code:        `print("hello")`,
 			envVars:     nil,
-			wantImage:   "mcr.microsoft.com/playwright/python:v1.53.0-noble",
+			wantImage:   "python:3.12-slim",
 			wantEnvVars: nil,
 		},
 		{
@@ -92,7 +95,7 @@ func TestDockerExecutor_CommandConstruction_NoDependencies(t *testing.T) {
 				"API_KEY": "secret",
 				"DEBUG":   "true",
 			},
-			wantImage:   "mcr.microsoft.com/playwright/python:v1.53.0-noble",
+			wantImage:   "python:3.12-slim",
 			wantEnvVars: []string{"API_KEY=secret", "DEBUG=true"},
 		},
 		{
@@ -170,13 +173,13 @@ func TestDockerExecutor_CommandConstruction_WithDependencies(t *testing.T) {
 			name:         "bash single package",
 			executor:     NewBashExecutor(),
 			dependencies: []string{"curl"},
-			wantInstall:  []string{"apt-get", "update", "-qq", "&&", "apt-get", "install", "-y", "-qq", "curl"},
+			wantInstall:  []string{"apt-get", "update", "-qq", "&&", "DEBIAN_FRONTEND=noninteractive", "apt-get", "install", "-y", "-qq", "--no-install-recommends", "curl"},
 		},
 		{
 			name:         "bash multiple packages",
 			executor:     NewBashExecutor(),
 			dependencies: []string{"curl", "wget", "jq"},
-			wantInstall:  []string{"apt-get", "update", "-qq", "&&", "apt-get", "install", "-y", "-qq", "curl", "wget", "jq"},
+			wantInstall:  []string{"apt-get", "update", "-qq", "&&", "DEBIAN_FRONTEND=noninteractive", "apt-get", "install", "-y", "-qq", "--no-install-recommends", "curl", "wget", "jq"},
 		},
 	}
 
@@ -263,58 +266,211 @@ func TestDockerExecutor_ConfigValidation(t *testing.T) {
 	}
 }
 
-func TestDockerExecutor_ShellCommandConstruction(t *testing.T) {
+func TestDockerExecutor_ShellCommand(t *testing.T) {
 	tests := []struct {
 		name         string
 		executor     *DockerExecutor
 		dependencies []string
-		wantContains []string
+		manifest     bool
+		check        bool
+		files        bool
+		want         []string
 	}{
 		{
 			name:         "python with dependencies",
 			executor:     NewPythonExecutor(),
-			dependencies: []string{"requests"},
-			wantContains: []string{"pip install", "requests", "&&", "python"},
+			dependencies: []string{"requests==2.32.0", "rich"},
+			want:         []string{"sh", "-c", withUsageReport(`python -m pip install --quiet -- "$@" || (exit 121) && printf '\nMCP_EXECUTOR_INSTALLED\n' >&2 && python`), "sh", "requests==2.32.0", "rich"},
 		},
 		{
 			name:         "bash with packages",
 			executor:     NewBashExecutor(),
 			dependencies: []string{"curl"},
-			wantContains: []string{"apt-get", "curl", "&&", "bash"},
+			want:         []string{"sh", "-c", withUsageReport(`apt-get update -qq && DEBIAN_FRONTEND=noninteractive apt-get install -y -qq --no-install-recommends -- "$@" || (exit 121) && printf '\nMCP_EXECUTOR_INSTALLED\n' >&2 && bash`), "sh", "curl"},
 		},
 		{
-			name:         "python no dependencies",
-			executor:     NewPythonExecutor(),
-			dependencies: nil,
-			wantContains: []string{"python"},
+			name:     "python no dependencies",
+			executor: NewPythonExecutor(),
+			want:     []string{"sh", "-c", withUsageReport("python")},
 		},
 		{
-			name:         "bash no packages",
-			executor:     NewBashExecutor(),
-			dependencies: nil,
-			wantContains: []string{"bash"},
+			name:     "manifest only",
+			executor: NewGoExecutor(),
+			manifest: true,
+			want:     []string{"sh", "-c", withUsageReport(`printf '%s' "$MCP_EXECUTOR_DEPENDENCY_FILE" > go.mod && go mod download || (exit 121) && printf '\nMCP_EXECUTOR_INSTALLED\n' >&2 && cat > main.go && { test -f go.mod || go mod init sandbox 2>/dev/null; } && go run -mod=mod .`)},
+		},
+		{
+			name:         "go with packages",
+			executor:     NewGoExecutor(),
+			dependencies: []string{"github.com/google/uuid@v1.6.0"},
+			want:         []string{"sh", "-c", withUsageReport(`{ test -f go.mod || go mod init sandbox 2>/dev/null; } && go get -- "$@" || (exit 121) && printf '\nMCP_EXECUTOR_INSTALLED\n' >&2 && cat > main.go && { test -f go.mod || go mod init sandbox 2>/dev/null; } && go run -mod=mod .`), "sh", "github.com/google/uuid@v1.6.0"},
+		},
+		{
+			name:     "go with files",
+			executor: NewGoExecutor(),
+			files:    true,
+			want:     []string{"sh", "-c", withUsageReport(`printf '%s' "$MCP_EXECUTOR_FILES" | base64 -d | tar -x && cat > main.go && { test -f go.mod || go mod init sandbox 2>/dev/null; } && go run -mod=mod .`)},
+		},
+		{
+			name:         "install timeout",
+			executor:     NewTypeScriptExecutor(WithInstallTimeout(90 * time.Second)),
+			dependencies: []string{"zod"},
+			want:         []string{"sh", "-c", withUsageReport(`timeout 90 sh -c 'npm install -g -- "$@" || exit 121' sh "$@" && printf '\nMCP_EXECUTOR_INSTALLED\n' >&2 && tsx`), "sh", "zod"},
+		},
+		{
+			name:     "package.json",
+			executor: NewTypeScriptExecutor(),
+			manifest: true,
+			want:     []string{"sh", "-c", withUsageReport(`printf '%s' "$MCP_EXECUTOR_DEPENDENCY_FILE" > package.json && npm install --silent || (exit 121) && printf '\nMCP_EXECUTOR_INSTALLED\n' >&2 && cat > index.ts && tsx index.ts`)},
+		},
+		{
+			name:     "check",
+			executor: NewGoExecutor(),
+			manifest: true,
+			check:    true,
+			want:     []string{"sh", "-c", withUsageReport(`printf '%s' "$MCP_EXECUTOR_DEPENDENCY_FILE" > go.mod && go mod download || (exit 121) && printf '\nMCP_EXECUTOR_INSTALLED\n' >&2 && cat > main.go && { test -f go.mod || go mod init sandbox 2>/dev/null; } && go vet -mod=mod .`)},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Build shell args as the Execute method would
-			shArgs := []string{}
-			if len(tt.dependencies) > 0 {
-				shArgs = append(shArgs, tt.executor.config.InstallCmd...)
-				shArgs = append(shArgs, tt.dependencies...)
-				shArgs = append(shArgs, "&&")
+			if got := tt.executor.shellCommand(tt.dependencies, tt.manifest, tt.check, tt.files, tt.executor.config.InstallTimeout); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("shellCommand() = %q, want %q", got, tt.want)
 			}
-			shArgs = append(shArgs, tt.executor.config.ExecuteCmd...)
+		})
+	}
+}
 
-			shellCmd := strings.Join(shArgs, " ")
+// withUsageReport wraps a container script as shellCommand does to report the cgroup usage.
+func withUsageReport(script string) string {
+	return "{ " + script + "; }; status=$?; " + containerUsageScript + "; exit $status"
+}
 
-			// Verify expected components are in the shell command
-			for _, want := range tt.wantContains {
-				if !strings.Contains(shellCmd, want) {
-					t.Errorf("Shell command %q should contain %q", shellCmd, want)
-				}
+func TestDockerExecutor_InstallErr(t *testing.T) {
+	marker := "\n" + installedMarker + "\n"
+	tests := []struct {
+		name    string
+		code    int
+		install time.Duration
+		stderr  string
+		wantErr string
+	}{
+		{"failed install", installFailedStatus, 0, "ERROR: No matching distribution found for reqeusts\n", `failed to install python dependencies: package "reqeusts"`},
+		{"install timeout", 124, time.Minute, "Collecting torch\n", "install timeout"},
+		{"timeout status without install timeout", 124, 0, "Collecting torch\n", ""},
+		{"code exiting with the failed install status", installFailedStatus, 0, "Installed rich" + marker + "custom failure\n", ""},
+		{"code stopped by its own timeout", 124, time.Minute, "Installed rich" + marker, ""},
+	}
+	d := NewPythonExecutor()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stderr, installed := splitInstalled(tt.stderr)
+			if strings.Contains(stderr, installedMarker) {
+				t.Errorf("splitInstalled() kept the marker in %q", stderr)
+			}
+			var err error
+			if !installed {
+				err = d.installErr(context.Background(), tt.code, tt.install, stderr)
+			}
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("installErr() = %v, want the status of the code", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("installErr() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDockerOptions(t *testing.T) {
+	executor := NewGoExecutor(
+		WithImage("golang:1.25"),
+		WithResourceLimits("512m", "1.5"),
+	)
+	if executor.config.Image != "golang:1.25" {
+		t.Errorf("Image = %q, want %q", executor.config.Image, "golang:1.25")
+	}
+	if executor.config.Memory != "512m" || executor.config.CPUs != "1.5" {
+		t.Errorf("Limits = %q/%q, want 512m/1.5", executor.config.Memory, executor.config.CPUs)
+	}
+
+	if executor := NewBashExecutor(WithImage("")); executor.config.Image != "ubuntu:22.04" {
+		t.Errorf("Empty image should keep the default, got %q", executor.config.Image)
+	}
+}
+
+func TestDockerExecutor_RuntimeImage(t *testing.T) {
+	executor := NewPythonExecutor(
+		WithImage("python:3.13"),
+		WithRuntimeImages(map[string]string{"3.10": "python:3.10-slim", "3.12": "python:3.12-slim"}),
+	)
+
+	tests := []struct {
+		version   string
+		wantImage string
+		wantErr   string
+	}{
+		{"", "python:3.13", ""},
+		{"3.12", "python:3.12-slim", ""},
+		{"2.7", "", "available: 3.10, 3.12"},
+	}
+	for _, tt := range tests {
+		image, err := executor.image(tt.version)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("image(%q) error = %v, want it to contain %q", tt.version, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || image != tt.wantImage {
+			t.Errorf("image(%q) = %q, %v, want %q", tt.version, image, err, tt.wantImage)
+		}
+	}
+
+	if _, err := NewBashExecutor().image("5.2"); err == nil || !strings.Contains(err.Error(), "no runtime versions") {
+		t.Errorf("image() without runtime images error = %v", err)
+	}
+}
+
+func TestDockerExecutor_InstallArgs(t *testing.T) {
+	tests := []struct {
+		name         string
+		executor     *DockerExecutor
+		dependencies bool
+		manifest     bool
+		want         string
+	}{
+		{"dependencies", NewPythonExecutor(), true, false, `python -m pip install --quiet -- "$@"`},
+		{"requirements.txt", NewPythonExecutor(), false, true, `printf '%s' "$MCP_EXECUTOR_DEPENDENCY_FILE" > requirements.txt && python -m pip install --quiet -r requirements.txt`},
+		{"package.json and packages", NewTypeScriptExecutor(), true, true, `printf '%s' "$MCP_EXECUTOR_DEPENDENCY_FILE" > package.json && npm install --silent && npm install -g -- "$@"`},
+		{"go.mod", NewGoExecutor(), false, true, `printf '%s' "$MCP_EXECUTOR_DEPENDENCY_FILE" > go.mod && go mod download`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Join(tt.executor.installArgs(tt.dependencies, tt.manifest), " "); got != tt.want {
+				t.Errorf("installArgs() = %q, want %q", got, tt.want)
 			}
 		})
 	}
+
+	_, err := NewBashExecutor().Execute(context.Background(), "true", nil, nil, WithDependencyFile("curl\n"))
+	if err == nil || !strings.Contains(err.Error(), "not supported for bash") {
+		t.Errorf("Execute() with a bash dependency file error = %v", err)
+	}
+}
+
+func TestWithCommands(t *testing.T) {
+	executor := NewPythonExecutor(WithCommands([]string{"python", "-m", "pip", "install", "--no-deps"}, []string{"python3", "-u"}))
+	want := []string{"sh", "-c", withUsageReport(`python -m pip install --no-deps -- "$@" || (exit 121) && printf '\nMCP_EXECUTOR_INSTALLED\n' >&2 && python3 -u`), "sh", "rich"}
+	if got := executor.shellCommand([]string{"rich"}, false, false, false, 0); !reflect.DeepEqual(got, want) {
+		t.Errorf("shellCommand() = %q, want %q", got, want)
+	}
+	if got := strings.Join(executor.config.PipeCmd, " "); !strings.HasSuffix(got, "python3 -u main.py") {
+		t.Errorf("PipeCmd = %q, want it to run main.py with the execute command", got)
+	}
+
+	defaults := NewBashExecutor()
+	if executor := NewBashExecutor(WithCommands(nil, nil)); !reflect.DeepEqual(executor.config, defaults.config) {
+		t.Errorf("WithCommands(nil, nil) changed the config to %+v", executor.config)
+	}
 }
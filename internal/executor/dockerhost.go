@@ -0,0 +1,158 @@
+// Package executor detects a server running in a container, talking to the
+// host's Docker daemon through a mounted socket or to a Docker-in-Docker
+// daemon, and translates the paths it bind-mounts into paths on the daemon host.
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DefaultDockerSocket is the daemon socket mounted into containers that start containers.
+const DefaultDockerSocket = "/var/run/docker.sock"
+
+// DockerHost describes where the Docker daemon runs relative to the server.
+// The zero value is a server running directly on the daemon host.
+type DockerHost struct {
+	InContainer bool   // The server runs in a container
+	ContainerID string // ID of that container, when known
+	Socket      string // Daemon socket mounted into the server, if any
+	Remote      string // DOCKER_HOST of a daemon reached over the network, e.g. a Docker-in-Docker sidecar
+
+	// Volumes are the mounts of the server container, with Source on the
+	// daemon host and Target in the server. Nil when they could not be read,
+	// in which case VolumesErr says why.
+	Volumes    []Mount
+	VolumesErr error
+}
+
+// DetectDockerHost inspects the environment of the server. The mounts of the
+// server container are read with docker inspect when the daemon is reached
+// through a mounted socket.
+func DetectDockerHost(ctx context.Context) DockerHost {
+	host := DockerHost{InContainer: inContainer()}
+	if !host.InContainer {
+		return host
+	}
+
+	dockerHost := os.Getenv("DOCKER_HOST")
+	if socket, ok := strings.CutPrefix(dockerHost, "unix://"); ok {
+		dockerHost = ""
+		host.Socket = socket
+	} else if dockerHost == "" && isSocket(DefaultDockerSocket) {
+		host.Socket = DefaultDockerSocket
+	}
+	host.Remote = dockerHost
+
+	if mountinfo, err := os.ReadFile("/proc/self/mountinfo"); err == nil {
+		host.ContainerID = parseContainerID(string(mountinfo))
+	}
+	switch {
+	case host.Remote != "":
+		host.VolumesErr = fmt.Errorf("the daemon at %s runs in another container", host.Remote)
+	case host.ContainerID == "":
+		host.VolumesErr = fmt.Errorf("the ID of the server container is unknown")
+	default:
+		host.Volumes, host.VolumesErr = inspectVolumes(ctx, host.ContainerID)
+	}
+	return host
+}
+
+// HostPath returns the path of p, a path of the server, on the daemon host. It
+// is p itself unless the server runs in a container whose mounts are known, in
+// which case p must lie on one of them.
+func (h DockerHost) HostPath(p string) (string, error) {
+	if !h.InContainer || h.Volumes == nil {
+		return p, nil
+	}
+
+	var best *Mount
+	for i, volume := range h.Volumes {
+		if withinAnyRoot(p, []string{volume.Target}) && (best == nil || len(volume.Target) > len(best.Target)) {
+			best = &h.Volumes[i]
+		}
+	}
+	if best == nil {
+		return "", fmt.Errorf("%s is not on a volume shared with the Docker host: the server runs in container %s, mount the directory into it", p, shortID(h.ContainerID))
+	}
+	rel, _ := filepath.Rel(best.Target, p)
+	return filepath.Join(best.Source, rel), nil
+}
+
+// WithDockerHost translates bind mount sources for the daemon described by host.
+func WithDockerHost(host DockerHost) DockerOption {
+	return func(c *ExecutorConfig) {
+		c.Host = host
+	}
+}
+
+// inContainer reports whether the server runs in a Docker, Podman or
+// Kubernetes container.
+func inContainer() bool {
+	for _, marker := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(marker); err == nil {
+			return true
+		}
+	}
+	cgroup, _ := os.ReadFile("/proc/1/cgroup")
+	return strings.Contains(string(cgroup), "docker") || strings.Contains(string(cgroup), "kubepods") || strings.Contains(string(cgroup), "containerd")
+}
+
+func isSocket(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode()&os.ModeSocket != 0
+}
+
+// containerIDPattern matches the container directory Docker mounts the
+// hostname, hosts and resolv.conf files from.
+var containerIDPattern = regexp.MustCompile(`/containers/([0-9a-f]{64})/`)
+
+// parseContainerID returns the ID of the container whose /proc/self/mountinfo
+// is mountinfo, or "" when it is not a Docker container.
+func parseContainerID(mountinfo string) string {
+	if match := containerIDPattern.FindStringSubmatch(mountinfo); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// inspectVolumes returns the mounts of the container id.
+func inspectVolumes(ctx context.Context, id string) ([]Mount, error) {
+	out, err := exec.CommandContext(ctx, "docker", "inspect", "--format", "{{json .Mounts}}", id).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container %s: %v", shortID(id), dockerError(err))
+	}
+	return parseVolumes(out)
+}
+
+// parseVolumes parses the .Mounts of docker inspect.
+func parseVolumes(data []byte) ([]Mount, error) {
+	var mounts []struct {
+		Source      string
+		Destination string
+		RW          bool
+	}
+	if err := json.Unmarshal(data, &mounts); err != nil {
+		return nil, fmt.Errorf("invalid container mounts: %v", err)
+	}
+	volumes := []Mount{}
+	for _, m := range mounts {
+		if m.Source != "" {
+			volumes = append(volumes, Mount{Source: m.Source, Target: m.Destination, ReadOnly: !m.RW})
+		}
+	}
+	return volumes, nil
+}
+
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
@@ -0,0 +1,84 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseContainerID(t *testing.T) {
+	id := strings.Repeat("0123456789abcdef", 4)
+	tests := []struct {
+		name      string
+		mountinfo string
+		want      string
+	}{
+		{"docker container", "612 598 254:1 /docker/containers/" + id + "/hostname /etc/hostname rw,relatime - ext4 /dev/vda1 rw\n", id},
+		{"host", "22 1 254:1 / / rw,relatime shared:1 - ext4 /dev/vda1 rw\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseContainerID(tt.mountinfo); got != tt.want {
+				t.Errorf("parseContainerID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVolumes(t *testing.T) {
+	volumes, err := parseVolumes([]byte(`[{"Type":"bind","Source":"/home/dev/data","Destination":"/data","RW":false},` +
+		`{"Type":"volume","Name":"cache","Source":"/var/lib/docker/volumes/cache/_data","Destination":"/cache","RW":true}]`))
+	if err != nil {
+		t.Fatalf("parseVolumes() returned error: %v", err)
+	}
+	want := []Mount{
+		{Source: "/home/dev/data", Target: "/data", ReadOnly: true},
+		{Source: "/var/lib/docker/volumes/cache/_data", Target: "/cache"},
+	}
+	if len(volumes) != len(want) || volumes[0] != want[0] || volumes[1] != want[1] {
+		t.Errorf("parseVolumes() = %+v, want %+v", volumes, want)
+	}
+
+	if _, err := parseVolumes([]byte("not json")); err == nil {
+		t.Error("parseVolumes() should reject invalid output")
+	}
+}
+
+func TestDockerHost_HostPath(t *testing.T) {
+	containerized := DockerHost{
+		InContainer: true,
+		ContainerID: strings.Repeat("ab", 32),
+		Volumes: []Mount{
+			{Source: "/srv/shared", Target: "/data"},
+			{Source: "/srv/projects", Target: "/data/projects"},
+		},
+	}
+	tests := []struct {
+		name    string
+		host    DockerHost
+		path    string
+		want    string
+		wantErr string
+	}{
+		{"on the daemon host", DockerHost{}, "/home/dev/project", "/home/dev/project", ""},
+		{"mounts unknown", DockerHost{InContainer: true}, "/data/input", "/data/input", ""},
+		{"mount target", containerized, "/data", "/srv/shared", ""},
+		{"below a mount", containerized, "/data/input/file.csv", "/srv/shared/input/file.csv", ""},
+		{"longest mount wins", containerized, "/data/projects/app", "/srv/projects/app", ""},
+		{"not shared", containerized, "/home/dev", "", "not on a volume shared with the Docker host: the server runs in container abababababab"},
+		{"sibling prefix", containerized, "/database", "", "not on a volume shared"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.host.HostPath(tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("HostPath(%q) error = %v, want it to contain %q", tt.path, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("HostPath(%q) = %q, %v, want %q", tt.path, got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+// Package executor provides an executor decorator that injects operator-defined
+// default environment variables into every execution.
+package executor
+
+import "context"
+
+// DefaultEnvExecutor adds default environment variables to every execution of
+// the wrapped executor. Per-call variables take precedence over the defaults.
+type DefaultEnvExecutor struct {
+	executor Executor
+	env      map[string]string
+}
+
+// NewDefaultEnvExecutor wraps exec with the given default environment. It returns
+// exec unchanged when env is empty.
+func NewDefaultEnvExecutor(exec Executor, env map[string]string) Executor {
+	if len(env) == 0 {
+		return exec
+	}
+	return &DefaultEnvExecutor{executor: exec, env: env}
+}
+
+func (d *DefaultEnvExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	merged := make(map[string]string, len(d.env)+len(envVars))
+	for key, value := range d.env {
+		merged[key] = value
+	}
+	for key, value := range envVars {
+		merged[key] = value
+	}
+	return d.executor.Execute(ctx, code, dependencies, merged, opts...)
+}
@@ -0,0 +1,35 @@
+package executor
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// envRecorder records the environment of the last execution.
+type envRecorder struct {
+	env map[string]string
+}
+
+func (e *envRecorder) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	e.env = envVars
+	return "", nil
+}
+
+func TestDefaultEnvExecutor(t *testing.T) {
+	recorder := &envRecorder{}
+	exec := NewDefaultEnvExecutor(recorder, map[string]string{"HTTP_PROXY": "http://proxy:3128", "TOKEN": "default"})
+
+	if _, err := exec.Execute(context.Background(), "", nil, map[string]string{"TOKEN": "call"}); err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+
+	want := map[string]string{"HTTP_PROXY": "http://proxy:3128", "TOKEN": "call"}
+	if !reflect.DeepEqual(recorder.env, want) {
+		t.Errorf("env = %v, want %v", recorder.env, want)
+	}
+
+	if unwrapped := NewDefaultEnvExecutor(recorder, nil); unwrapped != Executor(recorder) {
+		t.Error("NewDefaultEnvExecutor() should return the executor unchanged without defaults")
+	}
+}
@@ -0,0 +1,151 @@
+// Package executor provides pre-baked Docker execution environments: named
+// combinations of a base image, packages installed once into a derived image,
+// default variables and resource limits, selected with the profile argument.
+package executor
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// Environment is a named execution environment of one language.
+type Environment struct {
+	Name     string
+	Image    string            // Base image; empty uses the executor's image
+	Packages []string          // Installed into a derived image before the first execution
+	Env      map[string]string // Set unless the call or the operator's defaults set them
+	Memory   string            // Overrides the executor's memory limit when set
+	CPUs     string            // Overrides the executor's CPU limit when set
+}
+
+// EnvironmentImagePrefix starts the names of the images built for environments.
+const EnvironmentImagePrefix = "mcp-executor-env-"
+
+// WithEnvironments sets the environments selectable with the profile argument.
+func WithEnvironments(environments []Environment) DockerOption {
+	return func(c *ExecutorConfig) {
+		c.Environments = make(map[string]Environment, len(environments))
+		for _, environment := range environments {
+			c.Environments[environment.Name] = environment
+		}
+	}
+}
+
+// WithProfile runs the execution in the named environment.
+func WithProfile(name string) Option {
+	return func(o *Options) {
+		o.Profile = name
+	}
+}
+
+// environment returns the environment named name.
+func (d *DockerExecutor) environment(name string) (Environment, error) {
+	if environment, ok := d.config.Environments[name]; ok {
+		return environment, nil
+	}
+	names := make([]string, 0, len(d.config.Environments))
+	for available := range d.config.Environments {
+		names = append(names, available)
+	}
+	if len(names) == 0 {
+		return Environment{}, NewExecutionError(ErrorPolicyViolation, "profile %q is not available for %s: no environments are configured", name, d.config.ExecutorName)
+	}
+	sort.Strings(names)
+	return Environment{}, NewExecutionError(ErrorPolicyViolation, "profile %q is not available for %s (available: %s)", name, d.config.ExecutorName, strings.Join(names, ", "))
+}
+
+// environmentBuilds serializes the builds of each environment image, so
+// concurrent first executions build it once.
+var environmentBuilds sync.Map // Image tag -> *sync.Mutex
+
+// environmentImage returns the image running environment. Its packages are
+// installed into an image tagged after the base image, installer and
+// packages, built on first use and reused, also across restarts, afterwards.
+func (d *DockerExecutor) environmentImage(ctx context.Context, environment Environment) (string, error) {
+	tag, _, err := d.buildEnvironment(ctx, environment)
+	return tag, err
+}
+
+// buildEnvironment returns the image running environment, as
+// environmentImage, and whether it was built now.
+func (d *DockerExecutor) buildEnvironment(ctx context.Context, environment Environment) (string, bool, error) {
+	base := cmp.Or(environment.Image, d.config.Image)
+	if len(environment.Packages) == 0 {
+		return base, false, nil
+	}
+	packages, err := ValidateDependencies(d.config.ExecutorName, environment.Packages)
+	if err != nil {
+		return "", false, fmt.Errorf("profile %s: %v", environment.Name, err)
+	}
+	return d.buildImage(ctx, EnvironmentImagePrefix+environment.Name, base, packages, "profile "+environment.Name)
+}
+
+// buildImage returns the image named name with packages installed on base,
+// tagged after the Dockerfile and reused once built, and whether it was built
+// now. subject names what the image is for in logs and errors.
+func (d *DockerExecutor) buildImage(ctx context.Context, name, base string, packages []string, subject string) (string, bool, error) {
+	dockerfile := d.environmentDockerfile(base, packages)
+	sum := sha256.Sum256([]byte(dockerfile))
+	tag := name + ":" + hex.EncodeToString(sum[:6])
+
+	lock, _ := environmentBuilds.LoadOrStore(tag, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	if exec.CommandContext(ctx, "docker", "image", "inspect", "--format", "{{.Id}}", tag).Run() == nil {
+		return tag, false, nil
+	}
+	logger.InfoContext(ctx, "Building image %s for %s: %s", tag, subject, strings.Join(packages, ", "))
+	cmd := exec.CommandContext(ctx, "docker", "build", "--tag", tag, "--label", LanguageLabel+"="+d.config.ExecutorName, "-")
+	cmd.Stdin = strings.NewReader(dockerfile)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", false, fmt.Errorf("failed to build the image of %s: %v\n%s", subject, err, lastLines(string(out), 20))
+	}
+	return tag, true, nil
+}
+
+// environmentDockerfile returns the Dockerfile installing packages on base.
+// The RUN instruction uses the exec form with the packages as positional
+// parameters, so they never reach a shell parser.
+func (d *DockerExecutor) environmentDockerfile(base string, packages []string) string {
+	run := append([]string{"sh", "-c", strings.Join(d.installArgs(true, false), " "), "sh"}, packages...)
+	var data strings.Builder
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false) // Keep version specifiers such as numpy>=2 readable
+	_ = encoder.Encode(run)
+	return "FROM " + base + "\nRUN " + data.String()
+}
+
+// environmentVars adds the variables of environment that envVars does not set.
+func environmentVars(environment map[string]string, envVars map[string]string) map[string]string {
+	if len(environment) == 0 {
+		return envVars
+	}
+	merged := make(map[string]string, len(environment)+len(envVars))
+	for key, value := range environment {
+		merged[key] = value
+	}
+	for key, value := range envVars {
+		merged[key] = value
+	}
+	return merged
+}
+
+// lastLines returns the last n lines of s.
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
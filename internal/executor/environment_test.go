@@ -0,0 +1,69 @@
+package executor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDockerExecutor_Environment(t *testing.T) {
+	executor := NewPythonExecutor(WithEnvironments([]Environment{
+		{Name: "data-science", Packages: []string{"pandas"}},
+		{Name: "ml", Image: "pytorch/pytorch:latest"},
+	}))
+	tests := []struct {
+		name    string
+		profile string
+		wantErr string
+	}{
+		{"configured", "ml", ""},
+		{"unknown", "web", `profile "web" is not available for python (available: data-science, ml)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			environment, err := executor.environment(tt.profile)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("environment(%q) error = %v, want it to contain %q", tt.profile, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || environment.Name != tt.profile {
+				t.Errorf("environment(%q) = %+v, %v", tt.profile, environment, err)
+			}
+		})
+	}
+
+	if _, err := NewBashExecutor().environment("ml"); err == nil || !strings.Contains(err.Error(), "no environments are configured") {
+		t.Errorf("environment() without environments error = %v", err)
+	}
+}
+
+func TestDockerExecutor_EnvironmentDockerfile(t *testing.T) {
+	got := NewPythonExecutor().environmentDockerfile("python:3.12-slim", []string{"pandas", "numpy>=2"})
+	want := "FROM python:3.12-slim\n" +
+		`RUN ["sh","-c","python -m pip install --quiet -- \"$@\"","sh","pandas","numpy>=2"]` + "\n"
+	if got != want {
+		t.Errorf("environmentDockerfile() = %q, want %q", got, want)
+	}
+}
+
+func TestEnvironmentVars(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment map[string]string
+		envVars     map[string]string
+		want        map[string]string
+	}{
+		{"no environment variables", nil, map[string]string{"A": "1"}, map[string]string{"A": "1"}},
+		{"merged", map[string]string{"MPLBACKEND": "Agg"}, map[string]string{"A": "1"}, map[string]string{"A": "1", "MPLBACKEND": "Agg"}},
+		{"call wins", map[string]string{"A": "env"}, map[string]string{"A": "call"}, map[string]string{"A": "call"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := environmentVars(tt.environment, tt.envVars); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("environmentVars() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Package executor classifies failed executions by error code, so clients can
+// tell a refused request from a failed install, a compile error, a crash of
+// the code, a timeout or a failure of the server itself.
+package executor
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Codes of failed executions, returned by ErrorCode.
+const (
+	ErrorPolicyViolation = "policy_violation"     // The request was refused by validation or the server policy
+	ErrorInstallFailed   = "install_failed"       // The dependencies could not be installed
+	ErrorCompile         = "compile_error"        // The code did not compile
+	ErrorRuntime         = "runtime_error"        // The code failed or was killed while running
+	ErrorTimeout         = "timeout"              // The execution exceeded its timeout
+	ErrorInfrastructure  = "infrastructure_error" // The server could not run the code
+)
+
+// ExecutionError is an error with the code of the failure.
+type ExecutionError struct {
+	Code string // One of the Error* codes
+	Err  error
+}
+
+func (e *ExecutionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExecutionError) Unwrap() error {
+	return e.Err
+}
+
+// NewExecutionError returns an error of code with a message formatted as by
+// fmt.Errorf.
+func NewExecutionError(code, format string, args ...any) error {
+	return &ExecutionError{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// ErrorCode returns the code of a failed execution. Terminations report
+// ErrorTimeout or ErrorRuntime, and errors without a code ErrorInfrastructure.
+func ErrorCode(err error) string {
+	var terminated *TerminationError
+	var compile *CompileError
+	var install *InstallError
+	var coded *ExecutionError
+	switch {
+	case errors.As(err, &compile):
+		return ErrorCompile
+	case errors.As(err, &install):
+		return ErrorInstallFailed
+	case errors.As(err, &terminated):
+		if terminated.Reason == TerminationTimeout {
+			return ErrorTimeout
+		}
+		return ErrorRuntime
+	case errors.As(err, &coded):
+		return coded.Code
+	}
+	return ErrorInfrastructure
+}
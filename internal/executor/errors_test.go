@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+)
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"policy", NewExecutionError(ErrorPolicyViolation, "code is not valid UTF-8"), ErrorPolicyViolation},
+		{"wrapped", fmt.Errorf("profile data: %w", NewExecutionError(ErrorInstallFailed, "pip failed")), ErrorInstallFailed},
+		{"timeout", &TerminationError{Reason: TerminationTimeout}, ErrorTimeout},
+		{"out of memory", &TerminationError{Reason: TerminationOOM}, ErrorRuntime},
+		{"disk quota", &TerminationError{Reason: TerminationDisk}, ErrorRuntime},
+		{"unclassified", errors.New("execution failed: docker not found"), ErrorInfrastructure},
+		{"compile", newCompileError("go", "# command-line-arguments\n./main.go:3:2: declared and not used: x\n"), ErrorCompile},
+		{"install", newInstallError("python", "python", "ERROR: No matching distribution found for nope\n"), ErrorInstallFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorCode(tt.err); got != tt.want {
+				t.Errorf("ErrorCode(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorCode_Executions(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		executor Executor
+		code     string
+		want     string
+	}{
+		{"invalid code", NewValidatingExecutor(NewSubprocessBashExecutor(), 0), "echo \x00", ErrorPolicyViolation},
+		{"failing script", NewSubprocessBashExecutor(), "exit 3", ErrorRuntime},
+		{"go compile error", NewSubprocessGoExecutor(), "package main\n\nfunc main() { x := 1 }\n", ErrorCompile},
+		{"go panic", NewSubprocessGoExecutor(), "package main\n\nfunc main() { panic(1) }\n", ErrorRuntime},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := tt.executor.(*GoSubprocessExecutor); ok {
+				if _, err := exec.LookPath("go"); err != nil {
+					t.Skip("go not installed")
+				}
+			}
+			_, err := tt.executor.Execute(ctx, tt.code, nil, nil)
+			if got := ErrorCode(err); got != tt.want {
+				t.Errorf("ErrorCode(%v) = %q, want %q", err, got, tt.want)
+			}
+		})
+	}
+}
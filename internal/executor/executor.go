@@ -2,8 +2,203 @@
 // that can run code in isolated environments with dependency management.
 package executor
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Executor interface {
-	Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string) (string, error)
+	Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error)
+}
+
+// Options holds per-call execution settings that go beyond code, dependencies
+// and environment variables. Executors ignore settings they do not support.
+type Options struct {
+	Mounts  []Mount
+	Network string        // Container network mode (bridge, none or host); empty uses the default
+	Timeout time.Duration // Requested execution timeout; zero uses the operator default
+
+	// RuntimeVersion selects a language version, e.g. "3.12"; empty uses the default
+	// image or host toolchain.
+	RuntimeVersion string
+
+	// DependencyFile is the content of a dependency manifest (requirements.txt,
+	// package.json or go.mod) installed before the code runs.
+	DependencyFile string
+
+	// Files are written next to the code, keyed by their relative path, e.g.
+	// the other files and packages of a Go module.
+	Files map[string]string
+
+	// Snapshot names the snapshot the container of a successful execution is
+	// saved as; empty saves none.
+	Snapshot string
+	// Workspace names a directory shared by the executions of the MCP session
+	// requesting the same name; empty gives each execution its own workspace.
+	// WorkspaceDir is its host directory, set by WorkspaceExecutor.
+	Workspace    string
+	WorkspaceDir string
+
+	// Profile names a pre-baked environment of the executor; empty uses the
+	// default image.
+	Profile string
+
+	// ScratchDir is the per-execution temporary directory of host executions,
+	// set by SandboxEnvExecutor and removed after the execution.
+	ScratchDir string
+
+	// ArtifactsDir is the host directory collecting the reports written by
+	// the execution, set by ArtifactsExecutor.
+	ArtifactsDir string
+
+	// ReadOnly runs the execution without write access to the filesystem,
+	// see ReadOnlyExecutor.
+	ReadOnly bool
+
+	// CheckOnly compiles or type-checks the code without running it, see
+	// checkArgs; problems are reported as a CompileError.
+	CheckOnly bool
+
+	// Timezone and Locale override the default time zone and locale of the
+	// execution, and a non-zero FakeTime starts its clock at that time, see
+	// TimeLocaleExecutor.
+	Timezone string
+	Locale   string
+	FakeTime time.Time
+
+	// Deterministic fixes the random seeds, clock and network of the
+	// execution, see DeterministicExecutor.
+	Deterministic bool
+}
+
+// Option configures a single Execute call.
+type Option func(*Options)
+
+// NewOptions applies the given options on top of the zero-value Options.
+func NewOptions(opts ...Option) Options {
+	var o Options
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// WithMounts requests host paths to be mounted into the execution environment.
+func WithMounts(mounts []Mount) Option {
+	return func(o *Options) {
+		o.Mounts = append(o.Mounts, mounts...)
+	}
+}
+
+// WithNetwork selects the container network mode for the execution.
+func WithNetwork(network string) Option {
+	return func(o *Options) {
+		o.Network = network
+	}
+}
+
+// WithTimeout requests a timeout for the execution, bounded by the operator's maximum.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = timeout
+	}
+}
+
+// WithRuntimeVersion requests a specific language runtime version.
+func WithRuntimeVersion(version string) Option {
+	return func(o *Options) {
+		o.RuntimeVersion = version
+	}
+}
+
+// WithDependencyFile requests the dependencies of a manifest file to be installed.
+func WithDependencyFile(content string) Option {
+	return func(o *Options) {
+		o.DependencyFile = content
+	}
+}
+
+// WithSnapshot requests the container of the execution to be saved as the
+// snapshot name once the code succeeded.
+func WithSnapshot(name string) Option {
+	return func(o *Options) {
+		o.Snapshot = name
+	}
+}
+
+// WithFiles requests files, keyed by their path relative to the code, to be
+// written next to it.
+func WithFiles(files map[string]string) Option {
+	return func(o *Options) {
+		o.Files = files
+	}
+}
+
+// WithWorkspace requests the named workspace of the MCP session.
+func WithWorkspace(name string) Option {
+	return func(o *Options) {
+		o.Workspace = name
+	}
+}
+
+// WithReadOnly requests an execution without write access to the filesystem.
+func WithReadOnly() Option {
+	return func(o *Options) {
+		o.ReadOnly = true
+	}
+}
+
+// WithCheckOnly requests the code to be checked instead of run.
+func WithCheckOnly() Option {
+	return func(o *Options) {
+		o.CheckOnly = true
+	}
+}
+
+// WithTimezone requests the time zone of the execution, an IANA name.
+func WithTimezone(timezone string) Option {
+	return func(o *Options) {
+		o.Timezone = timezone
+	}
+}
+
+// WithLocale requests the locale of the execution, e.g. "en_US.UTF-8".
+func WithLocale(locale string) Option {
+	return func(o *Options) {
+		o.Locale = locale
+	}
+}
+
+// WithFakeTime requests the clock of the execution to start at t.
+func WithFakeTime(t time.Time) Option {
+	return func(o *Options) {
+		o.FakeTime = t
+	}
+}
+
+// WithDeterministic requests a reproducible execution.
+func WithDeterministic() Option {
+	return func(o *Options) {
+		o.Deterministic = true
+	}
+}
+
+func withWorkspaceDir(dir string) Option {
+	return func(o *Options) {
+		o.WorkspaceDir = dir
+	}
+}
+
+func withScratchDir(dir string) Option {
+	return func(o *Options) {
+		o.ScratchDir = dir
+	}
+}
+
+func withArtifactsDir(dir string) Option {
+	return func(o *Options) {
+		o.ArtifactsDir = dir
+	}
 }
@@ -0,0 +1,58 @@
+// Package executor keeps the Go build and module caches between executions,
+// in a Docker volume or a host directory, so that repeated Go executions
+// neither recompile the standard library nor download their modules again.
+package executor
+
+import "path/filepath"
+
+// GoCacheDir is where the Go cache volume is mounted in containers, holding
+// the build cache in build and the module cache in mod.
+const GoCacheDir = "/var/cache/mcp-executor-go"
+
+// WithGoCache mounts the named Docker volume on GoCacheDir of the Go
+// executions, including the persistent containers of hybrid mode, and points
+// GOCACHE and GOMODCACHE into it. The go command locks the caches, so
+// concurrent executions share them safely. Empty keeps no cache.
+func WithGoCache(volume string) DockerOption {
+	return func(c *ExecutorConfig) {
+		if c.ExecutorName == "go" {
+			c.GoCacheVolume = volume
+		}
+	}
+}
+
+// WithGoCacheDir keeps the build and module caches of subprocess Go
+// executions in the build and mod directories of dir, which the user of the
+// executions must be able to write, instead of those of the user. Empty keeps
+// the user's caches.
+func WithGoCacheDir(dir string) SubprocessOption {
+	return func(c *SubprocessConfig) {
+		c.GoCacheDir = dir
+	}
+}
+
+// goCacheEnv returns the GOCACHE and GOMODCACHE variables placing the caches
+// below dir, leaving out those set by env.
+func goCacheEnv(dir string, env map[string]string) []string {
+	var vars []string
+	if _, ok := env["GOCACHE"]; !ok {
+		vars = append(vars, "GOCACHE="+filepath.Join(dir, "build"))
+	}
+	if _, ok := env["GOMODCACHE"]; !ok {
+		vars = append(vars, "GOMODCACHE="+filepath.Join(dir, "mod"))
+	}
+	return vars
+}
+
+// goCacheArgs returns the docker run arguments mounting the Go cache volume
+// and pointing the caches into it, unless env sets them.
+func (c ExecutorConfig) goCacheArgs(env map[string]string) []string {
+	if c.GoCacheVolume == "" {
+		return nil
+	}
+	args := []string{"--mount", "type=volume,source=" + c.GoCacheVolume + ",target=" + GoCacheDir}
+	for _, variable := range goCacheEnv(GoCacheDir, env) {
+		args = append(args, "-e", variable)
+	}
+	return args
+}
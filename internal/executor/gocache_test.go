@@ -0,0 +1,77 @@
+package executor
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestGoCacheArgs(t *testing.T) {
+	mount := []string{"--mount", "type=volume,source=go-cache,target=" + GoCacheDir}
+	tests := []struct {
+		name     string
+		executor *DockerExecutor
+		env      map[string]string
+		want     []string
+	}{
+		{
+			name:     "no volume",
+			executor: NewGoExecutor(),
+		},
+		{
+			name:     "volume",
+			executor: NewGoExecutor(WithGoCache("go-cache")),
+			want:     append(mount, "-e", "GOCACHE="+GoCacheDir+"/build", "-e", "GOMODCACHE="+GoCacheDir+"/mod"),
+		},
+		{
+			name:     "build cache set by the call",
+			executor: NewGoExecutor(WithGoCache("go-cache")),
+			env:      map[string]string{"GOCACHE": "off"},
+			want:     append(mount, "-e", "GOMODCACHE="+GoCacheDir+"/mod"),
+		},
+		{
+			name:     "other languages",
+			executor: NewPythonExecutor(WithGoCache("go-cache")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.executor.config.goCacheArgs(tt.env); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("goCacheArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoSubprocessExecutor_CacheDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		executor *GoSubprocessExecutor
+		envVars  map[string]string
+		want     map[string]string
+	}{
+		{
+			name:     "cache dir",
+			executor: NewSubprocessGoExecutor(WithGoCacheDir("/var/cache/go")),
+			want:     map[string]string{"GOCACHE": "/var/cache/go/build", "GOMODCACHE": "/var/cache/go/mod"},
+		},
+		{
+			name:     "caches set by the call",
+			executor: NewSubprocessGoExecutor(WithGoCacheDir("/var/cache/go")),
+			envVars:  map[string]string{"GOCACHE": "/tmp/build", "GOMODCACHE": "/tmp/mod"},
+			want:     map[string]string{"GOCACHE": "/tmp/build", "GOMODCACHE": "/tmp/mod"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := tt.executor.env(context.Background(), "", tt.envVars)
+			for key, want := range tt.want {
+				if got := envValue(env, key); got != want {
+					t.Errorf("env() sets %s=%q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
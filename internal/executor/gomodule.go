@@ -0,0 +1,123 @@
+// Package executor runs Go code as a module: the files of a call are written
+// next to the code, and a go.mod is created unless the call gives one, so that
+// programs split into several files and packages build with the module
+// versions they pin.
+package executor
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// GoModule is the module path of Go code executed without a go.mod.
+const GoModule = "sandbox"
+
+// maxFilesSize bounds the total size of the files of a call, which reach
+// containers in an environment variable.
+const maxFilesSize = 64 << 10
+
+// filesEnv holds the files of the execution inside the container, as a
+// base64-encoded tar archive extracted into the working directory.
+const filesEnv = "MCP_EXECUTOR_FILES"
+
+// filesExtract are the shell words extracting the files of the execution into
+// the working directory.
+var filesExtract = []string{"printf", "'%s'", `"$` + filesEnv + `"`, "|", "base64", "-d", "|", "tar", "-x", "&&"}
+
+// goModInit are the shell words creating the go.mod of the module unless one
+// was installed from the dependency file.
+var goModInit = []string{"{", "test", "-f", "go.mod", "||", "go", "mod", "init", GoModule, "2>/dev/null;", "}"}
+
+// goModuleCmd returns the shell words running the Go command on the package
+// in the working directory once write has stored the code in main.go.
+// Modules imported by the code and missing from go.mod are added, as go get
+// would.
+func goModuleCmd(write []string, command ...string) []string {
+	words := slices.Concat(write, []string{"&&"}, goModInit, []string{"&&"}, command)
+	return append(words, "-mod=mod", ".")
+}
+
+// ValidateFiles reports why files, keyed by their path relative to the
+// directory of the code, cannot be written next to it: paths must stay inside
+// that directory and not replace the code or the dependency manifest, named
+// reserved.
+func ValidateFiles(files map[string]string, reserved ...string) error {
+	size := 0
+	for name, content := range files {
+		if name == "" || path.IsAbs(name) || strings.Contains(name, `\`) || path.Clean(name) != name || name == ".." || strings.HasPrefix(name, "../") {
+			return NewExecutionError(ErrorPolicyViolation, "invalid file path %q: use a relative path such as util.go or internal/store/store.go", name)
+		}
+		for _, file := range reserved {
+			if name == file {
+				return NewExecutionError(ErrorPolicyViolation, "invalid file path %q: %s is reserved, pass its content in code or dependency_file", name, file)
+			}
+		}
+		size += len(content)
+	}
+	if size > maxFilesSize {
+		return NewExecutionError(ErrorPolicyViolation, "files total %d bytes, more than the %d allowed", size, maxFilesSize)
+	}
+	return nil
+}
+
+// filesArchive returns the base64-encoded tar archive of files, see filesEnv.
+func filesArchive(files map[string]string) (string, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var archive bytes.Buffer
+	writer := tar.NewWriter(&archive)
+	for _, name := range names {
+		header := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(files[name])), Typeflag: tar.TypeReg}
+		if err := writer.WriteHeader(header); err != nil {
+			return "", fmt.Errorf("failed to archive %s: %v", name, err)
+		}
+		if _, err := writer.Write([]byte(files[name])); err != nil {
+			return "", fmt.Errorf("failed to archive %s: %v", name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to archive files: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(archive.Bytes()), nil
+}
+
+// writeGoModule writes files to dir, next to main.go, and creates the go.mod
+// of the module with goBinary, so that the module builds with go build. The
+// paths created are handed to the user of the execution.
+func writeGoModule(ctx context.Context, dir, goBinary string, files map[string]string, env []string) error {
+	for name, content := range files {
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+			return fmt.Errorf("failed to create the directory of %s: %v", name, err)
+		}
+		if err := os.WriteFile(target, []byte(content), 0o600); err != nil {
+			return fmt.Errorf("failed to write %s: %v", name, err)
+		}
+	}
+	modInit := exec.CommandContext(ctx, goBinary, "mod", "init", GoModule)
+	modInit.Dir = dir
+	modInit.Env = env
+	if out, err := modInit.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create go.mod: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return filepath.WalkDir(dir, func(name string, _ os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return grantRunAs(ctx, name)
+	})
+}
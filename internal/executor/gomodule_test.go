@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// moduleFiles split a program into a second file of package main and a
+// package in a subdirectory, imported by its path in the module.
+var moduleFiles = map[string]string{
+	"util.go":        "package main\n\nimport \"sandbox/store\"\n\nfunc greeting() string { return \"hello \" + store.Name() }\n",
+	"store/store.go": "package store\n\nfunc Name() string { return \"module\" }\n",
+}
+
+const moduleCode = "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println(greeting()) }\n"
+
+func TestValidateFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{name: "none"},
+		{name: "files and packages", files: moduleFiles},
+		{name: "absolute", files: map[string]string{"/etc/passwd": ""}, wantErr: "invalid file path"},
+		{name: "parent", files: map[string]string{"../main.go": ""}, wantErr: "invalid file path"},
+		{name: "unclean", files: map[string]string{"store/../util.go": ""}, wantErr: "invalid file path"},
+		{name: "code", files: map[string]string{"main.go": ""}, wantErr: "main.go is reserved"},
+		{name: "manifest", files: map[string]string{"go.mod": ""}, wantErr: "go.mod is reserved"},
+		{name: "too large", files: map[string]string{"data.go": strings.Repeat("a", maxFilesSize+1)}, wantErr: "more than the"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFiles(tt.files, "main.go", "go.mod")
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("ValidateFiles() returned error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("ValidateFiles() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGoModuleCmd_Files(t *testing.T) {
+	for _, binary := range []string{"go", "tar", "base64"} {
+		if _, err := exec.LookPath(binary); err != nil {
+			t.Skipf("%s not installed", binary)
+		}
+	}
+	archive, err := filesArchive(moduleFiles)
+	if err != nil {
+		t.Fatalf("filesArchive() returned error: %v", err)
+	}
+	script := NewGoExecutor().shellCommand(nil, false, false, true, 0)[2]
+	cmd := exec.Command("sh", "-c", script)
+	cmd.Dir = t.TempDir()
+	cmd.Env = append(cmd.Environ(), filesEnv+"="+archive, "GOPROXY=off")
+	cmd.Stdin = strings.NewReader(moduleCode)
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("container command failed: %v", err)
+	}
+	if string(output) != "hello module\n" {
+		t.Errorf("container command output = %q, want %q", output, "hello module\n")
+	}
+}
+
+func TestGoSubprocessExecutor_Files(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not installed")
+	}
+	executor := NewSubprocessGoExecutor()
+	env := map[string]string{"GOPROXY": "off"}
+
+	output, err := executor.Execute(context.Background(), moduleCode, nil, env, WithFiles(moduleFiles))
+	if err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+	if output != "hello module\n" {
+		t.Errorf("Execute() = %q, want %q", output, "hello module\n")
+	}
+
+	broken := map[string]string{"util.go": "package main\n\nfunc greeting() string { return 1 }\n"}
+	_, err = executor.Execute(context.Background(), "package main\n\nfunc main() { println(greeting()) }\n", nil, env, WithFiles(broken), WithCheckOnly())
+	if _, ok := err.(*CompileError); !ok {
+		t.Errorf("Execute() with check_only error = %v, want a CompileError", err)
+	}
+}
@@ -0,0 +1,82 @@
+// Package executor scrubs the server environment handed to host executions,
+// so that tokens and keys of the operator do not leak to executed code.
+package executor
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strings"
+)
+
+// DefaultEnvPassthrough lists the server variables passed to host executions:
+// locale, terminal and user variables, the proxies and CA bundles needed by
+// installers, and the variables locating the Go and Nix toolchains. A pattern
+// ending in "*" matches every variable with that prefix.
+var DefaultEnvPassthrough = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TERM", "TZ", "TMPDIR", "LANG", "LANGUAGE", "LC_*", "XDG_*",
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy",
+	"SSL_CERT_FILE", "SSL_CERT_DIR", "REQUESTS_CA_BUNDLE", "NODE_EXTRA_CA_CERTS",
+	"GOROOT", "GOPATH", "GOCACHE", "GOMODCACHE", "GOPROXY", "GOSUMDB", "GOPRIVATE", "GOFLAGS", "GOTOOLCHAIN",
+	"NIX_*",
+}
+
+// PassAllEnv is the pattern passing the whole server environment to host
+// executions.
+const PassAllEnv = "*"
+
+type envPassthroughKey struct{}
+
+// EnvPassthroughExecutor passes the server variables matching configured
+// patterns to the host processes of its executions, in addition to
+// DefaultEnvPassthrough.
+type EnvPassthroughExecutor struct {
+	executor Executor
+	patterns []string
+}
+
+// NewEnvPassthroughExecutor wraps exec so that its executions also receive the
+// server variables matching patterns, names or prefixes ending in "*", with
+// PassAllEnv passing every variable. It returns exec unchanged when patterns
+// is empty. Per-call and default variables are always set.
+func NewEnvPassthroughExecutor(exec Executor, patterns []string) Executor {
+	if len(patterns) == 0 {
+		return exec
+	}
+	return &EnvPassthroughExecutor{executor: exec, patterns: slices.Concat(DefaultEnvPassthrough, patterns)}
+}
+
+func (e *EnvPassthroughExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	return e.executor.Execute(context.WithValue(ctx, envPassthroughKey{}, e.patterns), code, dependencies, envVars, opts...)
+}
+
+// hostEnv returns the server variables passed to the host processes of the
+// executions of ctx.
+func hostEnv(ctx context.Context) []string {
+	patterns, ok := ctx.Value(envPassthroughKey{}).([]string)
+	if !ok {
+		patterns = DefaultEnvPassthrough
+	}
+	return filterEnv(os.Environ(), patterns)
+}
+
+// filterEnv returns the entries of env whose names match patterns.
+func filterEnv(env []string, patterns []string) []string {
+	var filtered []string
+	for _, entry := range env {
+		name, _, _ := strings.Cut(entry, "=")
+		if slices.ContainsFunc(patterns, func(pattern string) bool { return matchEnvPattern(pattern, name) }) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// matchEnvPattern reports whether the variable name matches pattern, a name or
+// a prefix ending in "*".
+func matchEnvPattern(pattern, name string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(name, prefix)
+	}
+	return name == pattern
+}
@@ -0,0 +1,77 @@
+package executor
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestFilterEnv(t *testing.T) {
+	env := []string{"PATH=/usr/bin", "LC_ALL=C", "GITHUB_TOKEN=secret", "AWS_PROFILE=dev", "AWS_SECRET_ACCESS_KEY=secret"}
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{"defaults", DefaultEnvPassthrough, []string{"PATH=/usr/bin", "LC_ALL=C"}},
+		{"name", []string{"AWS_PROFILE"}, []string{"AWS_PROFILE=dev"}},
+		{"prefix", []string{"AWS_*"}, []string{"AWS_PROFILE=dev", "AWS_SECRET_ACCESS_KEY=secret"}},
+		{"all", []string{PassAllEnv}, env},
+		{"none", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filterEnv(env, tt.patterns); !slices.Equal(got, tt.want) {
+				t.Errorf("filterEnv() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvPassthroughExecutor(t *testing.T) {
+	t.Setenv("MCP_TEST_TOKEN", "secret")
+	t.Setenv("MCP_TEST_PROFILE", "dev")
+	script := `echo "${MCP_TEST_TOKEN:-unset} ${MCP_TEST_PROFILE:-unset} ${MCP_TEST_CALL:-unset}"`
+	call := map[string]string{"MCP_TEST_CALL": "call"}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     string
+	}{
+		{"scrubbed by default", nil, "unset unset call\n"},
+		{"passed through", []string{"MCP_TEST_PROFILE"}, "unset dev call\n"},
+		{"everything", []string{PassAllEnv}, "secret dev call\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := NewEnvPassthroughExecutor(NewSubprocessBashExecutor(), tt.patterns)
+			output, err := exec.Execute(context.Background(), script, nil, call)
+			if err != nil {
+				t.Fatalf("Execute() returned error: %v", err)
+			}
+			if output != tt.want {
+				t.Errorf("Execute() = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvPassthroughExecutor_Install(t *testing.T) {
+	t.Setenv("MCP_TEST_TOKEN", "secret")
+	// The install command fails when it sees the scrubbed variable
+	install := &SubprocessExecutor{config: SubprocessConfig{
+		InstallCmd:   []string{"sh", "-c", `test "${MCP_TEST_TOKEN:-unset}" = unset`, "install"},
+		ExecutorName: "bash",
+		Language:     "bash",
+	}}
+
+	exec := NewEnvPassthroughExecutor(install, nil)
+	if _, err := exec.Execute(context.Background(), "true", []string{"jq"}, nil); err != nil {
+		t.Errorf("Execute() with a scrubbed install environment returned error: %v", err)
+	}
+	exec = NewEnvPassthroughExecutor(install, []string{PassAllEnv})
+	if _, err := exec.Execute(context.Background(), "true", []string{"jq"}, nil); ErrorCode(err) != ErrorInstallFailed {
+		t.Errorf("Execute() with the passed-through variable error = %v, want an install failure", err)
+	}
+}
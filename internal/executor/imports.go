@@ -0,0 +1,239 @@
+// Package executor provides an executor decorator that installs the
+// third-party packages imported by Python and TypeScript code, found by a
+// static scan of its import statements, along with the requested dependencies.
+package executor
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// ImportPolicy selects the packages installed for the imports of submitted code.
+type ImportPolicy struct {
+	// Packages maps, per language, import names to packages, extending and
+	// overriding the built-in tables. An empty package never installs the import.
+	Packages map[string]map[string]string
+
+	// AllowUnmapped installs third-party imports missing from the tables under
+	// their import name. Import and package names often differ ("yaml" is
+	// pyyaml, "cv2" opencv-python) and the import name may belong to an unrelated
+	// or malicious package, so unmapped imports are skipped by default.
+	AllowUnmapped bool
+}
+
+// importPackages are the built-in tables mapping import names to the packages
+// providing them.
+var importPackages = map[string]map[string]string{
+	"python": {
+		"aiohttp": "aiohttp", "altair": "altair", "anthropic": "anthropic", "arrow": "arrow", "attr": "attrs",
+		"boto3": "boto3", "bs4": "beautifulsoup4", "click": "click", "cryptography": "cryptography",
+		"cv2": "opencv-python", "dateutil": "python-dateutil", "docx": "python-docx", "dotenv": "python-dotenv",
+		"duckdb": "duckdb", "faker": "faker", "fastapi": "fastapi", "fitz": "pymupdf", "flask": "flask",
+		"httpx": "httpx", "jinja2": "jinja2", "jwt": "pyjwt", "lxml": "lxml", "markdown": "markdown",
+		"matplotlib": "matplotlib", "networkx": "networkx", "nltk": "nltk", "numpy": "numpy", "openai": "openai",
+		"openpyxl": "openpyxl", "orjson": "orjson", "pandas": "pandas", "paramiko": "paramiko", "PIL": "pillow",
+		"playwright": "playwright", "plotly": "plotly", "polars": "polars", "pptx": "python-pptx",
+		"psutil": "psutil", "psycopg2": "psycopg2-binary", "pyarrow": "pyarrow", "pydantic": "pydantic",
+		"pymongo": "pymongo", "pypdf": "pypdf", "pytz": "pytz", "redis": "redis", "regex": "regex",
+		"requests": "requests", "rich": "rich", "scipy": "scipy", "seaborn": "seaborn", "selenium": "selenium",
+		"shapely": "shapely", "skimage": "scikit-image", "sklearn": "scikit-learn", "sqlalchemy": "sqlalchemy",
+		"statsmodels": "statsmodels", "sympy": "sympy", "tabulate": "tabulate", "tiktoken": "tiktoken",
+		"toml": "toml", "torch": "torch", "tqdm": "tqdm", "websockets": "websockets", "xlrd": "xlrd",
+		"yaml": "pyyaml", "zmq": "pyzmq",
+	},
+	"typescript": {
+		"@anthropic-ai/sdk": "@anthropic-ai/sdk", "@faker-js/faker": "@faker-js/faker", "ajv": "ajv",
+		"axios": "axios", "better-sqlite3": "better-sqlite3", "chalk": "chalk", "cheerio": "cheerio",
+		"commander": "commander", "csv-parse": "csv-parse", "d3": "d3", "date-fns": "date-fns", "dayjs": "dayjs",
+		"decimal.js": "decimal.js", "dotenv": "dotenv", "express": "express", "fast-xml-parser": "fast-xml-parser",
+		"fs-extra": "fs-extra", "ioredis": "ioredis", "jsdom": "jsdom", "jsonwebtoken": "jsonwebtoken",
+		"lodash": "lodash", "luxon": "luxon", "marked": "marked", "mathjs": "mathjs", "moment": "moment",
+		"mongodb": "mongodb", "mysql2": "mysql2", "nanoid": "nanoid", "node-fetch": "node-fetch",
+		"openai": "openai", "papaparse": "papaparse", "pg": "pg", "playwright": "playwright",
+		"puppeteer": "puppeteer", "ramda": "ramda", "redis": "redis", "rxjs": "rxjs", "semver": "semver",
+		"sharp": "sharp", "uuid": "uuid", "ws": "ws", "xml2js": "xml2js", "yaml": "yaml", "zod": "zod",
+	},
+}
+
+// pythonStdlib lists the top-level modules of the Python standard library
+// (sys.stdlib_module_names without private modules).
+var pythonStdlib = setOf(`__future__ abc aifc antigravity argparse array ast asynchat asyncio asyncore atexit audioop
+base64 bdb binascii bisect builtins bz2 cProfile calendar cgi cgitb chunk cmath cmd code codecs codeop collections
+colorsys compileall concurrent configparser contextlib contextvars copy copyreg crypt csv ctypes curses dataclasses
+datetime dbm decimal difflib dis distutils doctest email encodings ensurepip enum errno faulthandler fcntl filecmp
+fileinput fnmatch fractions ftplib functools gc genericpath getopt getpass gettext glob graphlib grp gzip hashlib
+heapq hmac html http idlelib imaplib imghdr imp importlib inspect io ipaddress itertools json keyword lib2to3
+linecache locale logging lzma mailbox mailcap marshal math mimetypes mmap modulefinder msilib msvcrt
+multiprocessing netrc nis nntplib nt ntpath nturl2path numbers opcode operator optparse os ossaudiodev pathlib
+pdb pickle pickletools pipes pkgutil platform plistlib poplib posix posixpath pprint profile pstats pty pwd
+py_compile pyclbr pydoc pydoc_data pyexpat queue quopri random re readline reprlib resource rlcompleter runpy
+sched secrets select selectors shelve shlex shutil signal site smtpd smtplib sndhdr socket socketserver spwd
+sqlite3 sre_compile sre_constants sre_parse ssl stat statistics string stringprep struct subprocess sunau
+symtable sys sysconfig syslog tabnanny tarfile telnetlib tempfile termios textwrap this threading time timeit
+tkinter token tokenize tomllib trace traceback tracemalloc tty turtle turtledemo types typing unicodedata
+unittest urllib uu uuid venv warnings wave weakref webbrowser winreg winsound wsgiref xdrlib xml xmlrpc
+zipapp zipfile zipimport zlib zoneinfo`)
+
+// nodeBuiltins lists the built-in modules of Node.js, importable with or
+// without the node: prefix.
+var nodeBuiltins = setOf(`assert async_hooks buffer child_process cluster console constants crypto dgram
+diagnostics_channel dns domain events fs http http2 https inspector module net os path perf_hooks process
+punycode querystring readline repl stream string_decoder sys timers tls trace_events tty url util v8 vm wasi
+worker_threads zlib`)
+
+func setOf(names string) map[string]bool {
+	set := make(map[string]bool)
+	for _, name := range strings.Fields(names) {
+		set[name] = true
+	}
+	return set
+}
+
+var (
+	pythonImport     = regexp.MustCompile(`^import\s+(.+)$`)
+	pythonFromImport = regexp.MustCompile(`^from\s+([A-Za-z_][\w.]*)\s+import\b`)
+	typeScriptImport = regexp.MustCompile(`(?:^|[^\w$.])(?:(?:import|export)\s+(?:type\s+)?(?:[\w$*{}\s,]+\s+from\s+)?|require\s*\(\s*|import\s*\(\s*)['"]([^'"\n]+)['"]`)
+)
+
+// detectImports returns the sorted top-level third-party modules imported by
+// code: Python modules outside the standard library, or TypeScript packages
+// other than Node.js built-ins and relative paths. Other languages have none.
+func detectImports(language, code string) []string {
+	found := make(map[string]bool)
+	switch language {
+	case "python":
+		for _, line := range strings.Split(code, "\n") {
+			line, _, _ = strings.Cut(line, "#")
+			line = strings.TrimSpace(line)
+			if match := pythonFromImport.FindStringSubmatch(line); match != nil {
+				found[strings.Split(match[1], ".")[0]] = true
+			} else if match := pythonImport.FindStringSubmatch(line); match != nil {
+				for _, module := range strings.Split(match[1], ",") {
+					if fields := strings.Fields(module); len(fields) > 0 {
+						found[strings.Split(fields[0], ".")[0]] = true
+					}
+				}
+			}
+		}
+		for module := range found {
+			if pythonStdlib[module] {
+				delete(found, module)
+			}
+		}
+	case "typescript":
+		for _, match := range typeScriptImport.FindAllStringSubmatch(code, -1) {
+			if name := packageOfSpecifier(match[1]); name != "" {
+				found[name] = true
+			}
+		}
+	}
+
+	modules := make([]string, 0, len(found))
+	for module := range found {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	return modules
+}
+
+// packageOfSpecifier returns the npm package of a module specifier, e.g. lodash
+// for "lodash/fp" or @scope/pkg for "@scope/pkg/sub", or "" for relative paths,
+// URLs and Node.js built-ins.
+func packageOfSpecifier(specifier string) string {
+	if strings.HasPrefix(specifier, ".") || strings.HasPrefix(specifier, "/") || strings.Contains(specifier, ":") {
+		return ""
+	}
+	parts := strings.Split(specifier, "/")
+	if strings.HasPrefix(specifier, "@") {
+		if len(parts) < 2 {
+			return ""
+		}
+		return parts[0] + "/" + parts[1]
+	}
+	if nodeBuiltins[parts[0]] {
+		return ""
+	}
+	return parts[0]
+}
+
+// ImportDetector adds the packages imported by the code to the dependencies of
+// every execution, following an ImportPolicy.
+type ImportDetector struct {
+	executor      Executor
+	language      string
+	packages      map[string]string
+	allowUnmapped bool
+}
+
+// NewImportDetector wraps exec, running code of language, so that the packages
+// providing the imports of the code are installed. Executions with a
+// dependency file install only that file, since it pins the intended versions,
+// and deterministic executions only the dependencies they request.
+func NewImportDetector(exec Executor, language string, policy ImportPolicy) Executor {
+	packages := make(map[string]string)
+	for module, pkg := range importPackages[language] {
+		packages[module] = pkg
+	}
+	for module, pkg := range policy.Packages[language] {
+		packages[module] = pkg
+	}
+	return &ImportDetector{executor: exec, language: language, packages: packages, allowUnmapped: policy.AllowUnmapped}
+}
+
+func (d *ImportDetector) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	// Deterministic executions have no network to install packages with
+	if options := NewOptions(opts...); options.DependencyFile != "" || options.Deterministic {
+		return d.executor.Execute(ctx, code, dependencies, envVars, opts...)
+	}
+
+	requested := make(map[string]bool, len(dependencies))
+	for _, dependency := range dependencies {
+		requested[packageName(d.language, dependency)] = true
+	}
+	var added, skipped []string
+	for _, module := range detectImports(d.language, code) {
+		pkg, mapped := d.packages[module]
+		if !mapped && d.allowUnmapped {
+			pkg = module
+		}
+		if pkg == "" {
+			skipped = append(skipped, module)
+			continue
+		}
+		if !requested[packageName(d.language, pkg)] {
+			requested[packageName(d.language, pkg)] = true
+			added = append(added, pkg)
+		}
+	}
+	if len(skipped) > 0 {
+		logger.DebugContext(ctx, "Not installing imports missing from the package table: %s", strings.Join(skipped, ", "))
+	}
+	if len(added) > 0 {
+		logger.InfoContext(ctx, "Installing packages imported by the code: %s", strings.Join(added, ", "))
+		dependencies = append(append([]string(nil), dependencies...), added...)
+	}
+	return d.executor.Execute(ctx, code, dependencies, envVars, opts...)
+}
+
+// packageName returns the normalized name of a dependency without its version,
+// extras or markers, e.g. python-dateutil for "Python_Dateutil[tz]>=2.8" or
+// @scope/pkg for "@scope/pkg@1.2".
+func packageName(language, dependency string) string {
+	switch language {
+	case "python":
+		name := dependency
+		if i := strings.IndexAny(name, "<>=!~[;@ "); i >= 0 {
+			name = name[:i]
+		}
+		return strings.ReplaceAll(strings.ReplaceAll(strings.ToLower(name), "_", "-"), ".", "-")
+	case "typescript":
+		if i := strings.LastIndex(dependency, "@"); i > 0 {
+			return dependency[:i]
+		}
+	}
+	return dependency
+}
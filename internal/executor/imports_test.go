@@ -0,0 +1,118 @@
+package executor
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestDetectImports(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		code     string
+		want     []string
+	}{
+		{
+			name:     "python",
+			language: "python",
+			code: "import os, sys\nimport numpy as np, pandas.io.json as pj\nfrom sklearn.linear_model import LinearRegression\n" +
+				"from . import helpers\nfrom collections import Counter\n  import yaml  # indented\n# import requests\nprint('import torch')\n",
+			want: []string{"numpy", "pandas", "sklearn", "yaml"},
+		},
+		{
+			name:     "typescript",
+			language: "typescript",
+			code: "import fs from 'fs';\nimport { readFile } from \"node:fs/promises\";\nimport _ from 'lodash/fp';\n" +
+				"import type { Schema } from 'zod';\nimport * as sdk from '@anthropic-ai/sdk/resources';\nimport './setup';\n" +
+				"const axios = require('axios');\nconst { load } = await import(\"cheerio\");\nexport { x } from './x';\nimport 'dotenv/config';\n",
+			want: []string{"@anthropic-ai/sdk", "axios", "cheerio", "dotenv", "lodash", "zod"},
+		},
+		{"other languages", "bash", "import numpy", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectImports(tt.language, tt.code); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectImports() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackageName(t *testing.T) {
+	tests := []struct {
+		language   string
+		dependency string
+		want       string
+	}{
+		{"python", "Python_Dateutil[tz]>=2.8", "python-dateutil"},
+		{"python", "requests", "requests"},
+		{"typescript", "@scope/pkg@1.2", "@scope/pkg"},
+		{"typescript", "@scope/pkg", "@scope/pkg"},
+		{"typescript", "zod@3", "zod"},
+	}
+	for _, tt := range tests {
+		if got := packageName(tt.language, tt.dependency); got != tt.want {
+			t.Errorf("packageName(%q, %q) = %q, want %q", tt.language, tt.dependency, got, tt.want)
+		}
+	}
+}
+
+// dependencyRecorder records the dependencies it is asked to install.
+type dependencyRecorder struct {
+	dependencies []string
+}
+
+func (d *dependencyRecorder) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	d.dependencies = dependencies
+	return "", nil
+}
+
+func TestImportDetector(t *testing.T) {
+	code := "import numpy\nimport yaml\nimport cv2\nimport mylib\n"
+	tests := []struct {
+		name         string
+		policy       ImportPolicy
+		dependencies []string
+		opts         []Option
+		want         []string
+	}{
+		{
+			name:         "mapped imports are added",
+			dependencies: []string{"NumPy==1.26"},
+			want:         []string{"NumPy==1.26", "opencv-python", "pyyaml"},
+		},
+		{
+			name:   "operator mappings",
+			policy: ImportPolicy{Packages: map[string]map[string]string{"python": {"cv2": "opencv-python-headless", "yaml": "", "mylib": "mylib-client"}}},
+			want:   []string{"opencv-python-headless", "mylib-client", "numpy"},
+		},
+		{
+			name:   "unmapped imports allowed",
+			policy: ImportPolicy{AllowUnmapped: true},
+			want:   []string{"opencv-python", "mylib", "numpy", "pyyaml"},
+		},
+		{
+			name: "dependency file",
+			opts: []Option{WithDependencyFile("numpy==1.26\n")},
+			want: nil,
+		},
+		{
+			name: "deterministic",
+			opts: []Option{WithDeterministic()},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &dependencyRecorder{}
+			exec := NewImportDetector(recorder, "python", tt.policy)
+			if _, err := exec.Execute(context.Background(), code, tt.dependencies, nil, tt.opts...); err != nil {
+				t.Fatalf("Execute() returned error: %v", err)
+			}
+			if !reflect.DeepEqual(recorder.dependencies, tt.want) {
+				t.Errorf("dependencies = %v, want %v", recorder.dependencies, tt.want)
+			}
+		})
+	}
+}
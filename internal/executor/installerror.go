@@ -0,0 +1,96 @@
+// Package executor reports dependencies that could not be installed with the
+// output of the installer and the package it failed on, parsed from the
+// messages of pip, uv, npm, apt and go, along with the packages likely meant.
+package executor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// InstallError reports dependencies that could not be installed. Nothing ran,
+// so the output is that of the installer.
+type InstallError struct {
+	Message     string   // Description of the failure
+	Package     string   // Package the installer failed on, empty when it names none
+	Reason      string   // Line of the output naming Package
+	Suggestions []string // Packages likely meant instead of Package
+	Output      string   // Output of the installer, its end when long
+}
+
+func (e *InstallError) Error() string {
+	message := e.Message
+	if e.Package != "" {
+		message += fmt.Sprintf(": package %q: %s", e.Package, e.Reason)
+	}
+	if len(e.Suggestions) > 0 {
+		quoted := make([]string, len(e.Suggestions))
+		for i, suggestion := range e.Suggestions {
+			quoted[i] = strconv.Quote(suggestion)
+		}
+		message += fmt.Sprintf("\ndid you mean %s?", strings.Join(quoted, " or "))
+	}
+	if e.Output != "" {
+		message += "\ninstaller output:\n" + e.Output
+	}
+	return message
+}
+
+// maxInstallOutput bounds the installer output kept in an InstallError. The
+// end is kept, as installers report the failure last.
+const maxInstallOutput = 4096
+
+// failedPackagePatterns match the lines of installer output naming the package
+// that could not be installed, in their first group.
+var failedPackagePatterns = []*regexp.Regexp{
+	// pip
+	regexp.MustCompile(`No matching distribution found for ([^\s;]+)`),
+	regexp.MustCompile(`Could not find a version that satisfies the requirement ([^\s;]+)`),
+	// uv
+	regexp.MustCompile(`Because (\S+) was not found in the package registry`),
+	regexp.MustCompile(`Because there are no versions of (\S+) and`),
+	// npm
+	regexp.MustCompile(`'(@?[^@'\s]+)@[^'\s]*' is not in (?:this|the npm) registry`),
+	regexp.MustCompile(`No matching version found for (\S+?)\.?$`),
+	// apt
+	regexp.MustCompile(`E: Unable to locate package (\S+)`),
+	regexp.MustCompile(`E: Package '(\S+)' has no installation candidate`),
+	regexp.MustCompile(`E: Version '\S+' for '(\S+)' was not found`),
+	// go get and go mod download
+	regexp.MustCompile(`^go: (?:module )?([^\s:@]+(?:@[^\s:]+)?): `),
+}
+
+// newInstallError returns the InstallError of the dependencies of name, an
+// executor of language, that the installer failed to install with output.
+func newInstallError(name, language, output string) *InstallError {
+	output = strings.TrimSpace(output)
+	err := &InstallError{Message: fmt.Sprintf("failed to install %s dependencies", name), Output: output}
+	err.Package, err.Reason = failedPackage(output)
+	if err.Package != "" {
+		err.Suggestions = suggestPackages(language, err.Package)
+	}
+	if len(output) > maxInstallOutput {
+		cut := len(output) - maxInstallOutput
+		if newline := strings.IndexByte(output[cut:], '\n'); newline >= 0 {
+			cut += newline + 1
+		}
+		err.Output = "...\n" + output[cut:]
+	}
+	return err
+}
+
+// failedPackage returns the first package named by a failure in installer
+// output and the line naming it.
+func failedPackage(output string) (string, string) {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		for _, pattern := range failedPackagePatterns {
+			if match := pattern.FindStringSubmatch(line); match != nil {
+				return strings.Trim(match[1], `"'`), line
+			}
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,87 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewInstallError(t *testing.T) {
+	tests := []struct {
+		name        string
+		output      string
+		wantPackage string
+		wantReason  string
+	}{
+		{
+			name:        "pip",
+			output:      "ERROR: Could not find a version that satisfies the requirement reqeusts (from versions: none)\nERROR: No matching distribution found for reqeusts\n",
+			wantPackage: "reqeusts",
+			wantReason:  "ERROR: Could not find a version that satisfies the requirement reqeusts (from versions: none)",
+		},
+		{
+			name:        "uv",
+			output:      "  × No solution found when resolving `--with` dependencies:\n  ╰─▶ Because reqeusts was not found in the package registry and you require reqeusts, we can conclude that your requirements are unsatisfiable.\n",
+			wantPackage: "reqeusts",
+			wantReason:  "╰─▶ Because reqeusts was not found in the package registry and you require reqeusts, we can conclude that your requirements are unsatisfiable.",
+		},
+		{
+			name:        "npm",
+			output:      "npm error code E404\nnpm error 404 Not Found - GET https://registry.npmjs.org/@acme%2fnope - Not found\nnpm error 404  '@acme/nope@*' is not in this registry.\n",
+			wantPackage: "@acme/nope",
+			wantReason:  "npm error 404  '@acme/nope@*' is not in this registry.",
+		},
+		{
+			name:        "npm version",
+			output:      "npm ERR! code ETARGET\nnpm ERR! notarget No matching version found for lodash@99.\n",
+			wantPackage: "lodash@99",
+			wantReason:  "npm ERR! notarget No matching version found for lodash@99.",
+		},
+		{
+			name:        "apt",
+			output:      "Reading package lists...\nE: Unable to locate package curll\n",
+			wantPackage: "curll",
+			wantReason:  "E: Unable to locate package curll",
+		},
+		{
+			name:        "apt version",
+			output:      "E: Version '9.9' for 'curl' was not found\n",
+			wantPackage: "curl",
+			wantReason:  "E: Version '9.9' for 'curl' was not found",
+		},
+		{
+			name:        "go",
+			output:      "go: downloading github.com/google/uuid v1.6.0\ngo: github.com/acme/nope@latest: module github.com/acme/nope: git ls-remote -q origin: exit status 128\n",
+			wantPackage: "github.com/acme/nope@latest",
+			wantReason:  "go: github.com/acme/nope@latest: module github.com/acme/nope: git ls-remote -q origin: exit status 128",
+		},
+		{
+			name:   "unrecognized output",
+			output: "Segmentation fault\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newInstallError("test", "bash", tt.output)
+			if err.Package != tt.wantPackage || err.Reason != tt.wantReason {
+				t.Errorf("newInstallError() = package %q, reason %q, want %q, %q", err.Package, err.Reason, tt.wantPackage, tt.wantReason)
+			}
+			if err.Output != strings.TrimSpace(tt.output) || !strings.HasSuffix(err.Error(), err.Output) {
+				t.Errorf("Error() = %q, want the installer output", err.Error())
+			}
+			if tt.wantPackage != "" && !strings.HasPrefix(err.Error(), `failed to install test dependencies: package "`+tt.wantPackage+`": `) {
+				t.Errorf("Error() = %q, want the failed package first", err.Error())
+			}
+		})
+	}
+}
+
+func TestNewInstallError_LongOutput(t *testing.T) {
+	output := strings.Repeat("Collecting dependency\n", 1000) + "ERROR: No matching distribution found for nope"
+	err := newInstallError("python", "python", output)
+	if len(err.Output) > maxInstallOutput+4 || !strings.HasPrefix(err.Output, "...\nCollecting") || !strings.HasSuffix(err.Output, "nope") {
+		t.Errorf("Output = %d bytes starting %q, want the end of the output cut at a line", len(err.Output), err.Output[:20])
+	}
+	if err.Package != "nope" {
+		t.Errorf("Package = %q, want nope", err.Package)
+	}
+}
@@ -0,0 +1,32 @@
+// Package executor sets the IO priority of host executions with ioprio_set,
+// which only Linux provides.
+package executor
+
+import "syscall"
+
+// ioprio_set(2) values
+const (
+	ioprioWhoPgrp     = 2
+	ioprioClassShift  = 13
+	ioprioClassBE     = 2
+	ioprioClassIdle   = 3
+	ioprioLowestLevel = 7
+)
+
+// setIOPriority sets the IO priority of the process group pgid to
+// ioPriority. IOPriorityNormal and empty leave it unchanged.
+func setIOPriority(pgid int, ioPriority string) error {
+	var value int
+	switch ioPriority {
+	case IOPriorityLow:
+		value = ioprioClassBE<<ioprioClassShift | ioprioLowestLevel
+	case IOPriorityIdle:
+		value = ioprioClassIdle << ioprioClassShift
+	default:
+		return nil
+	}
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, ioprioWhoPgrp, uintptr(pgid), uintptr(value)); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+// Package executor leaves the IO priority of host executions unchanged on
+// systems without ioprio_set.
+package executor
+
+// setIOPriority does nothing: only Linux supports IO priorities.
+func setIOPriority(pgid int, ioPriority string) error {
+	return nil
+}
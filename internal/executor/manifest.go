@@ -0,0 +1,53 @@
+// Package executor checks the dependency manifests passed to Docker
+// executions, so that malformed ones are refused before a container starts
+// instead of failing inside the install step.
+package executor
+
+import "encoding/json"
+
+// ValidateManifest reports why content is not a valid manifest named file.
+// Manifests without checks, such as requirements.txt, are left to their
+// installer.
+func ValidateManifest(file, content string) error {
+	switch file {
+	case "package.json":
+		return validatePackageJSON(content)
+	}
+	return nil
+}
+
+// packageJSONDependencies are the fields of package.json mapping package names
+// to version ranges.
+var packageJSONDependencies = []string{"dependencies", "devDependencies", "optionalDependencies", "peerDependencies"}
+
+// validatePackageJSON checks that content is a JSON object whose dependency
+// fields map package names to version strings, and whose type, if any, is
+// module or commonjs.
+func validatePackageJSON(content string) error {
+	var manifest map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(content), &manifest); err != nil {
+		return NewExecutionError(ErrorPolicyViolation, "invalid package.json: %v", err)
+	}
+	for _, field := range packageJSONDependencies {
+		raw, ok := manifest[field]
+		if !ok {
+			continue
+		}
+		var versions map[string]string
+		if err := json.Unmarshal(raw, &versions); err != nil {
+			return NewExecutionError(ErrorPolicyViolation, "invalid package.json: %s must map package names to versions", field)
+		}
+		for name := range versions {
+			if valid, err := ValidateDependencies("typescript", []string{name}); err != nil || len(valid) == 0 {
+				return NewExecutionError(ErrorPolicyViolation, "invalid package.json: %s: %q is not an npm package name", field, name)
+			}
+		}
+	}
+	if raw, ok := manifest["type"]; ok {
+		var moduleType string
+		if err := json.Unmarshal(raw, &moduleType); err != nil || (moduleType != "module" && moduleType != "commonjs") {
+			return NewExecutionError(ErrorPolicyViolation, "invalid package.json: type must be %q or %q", "module", "commonjs")
+		}
+	}
+	return nil
+}
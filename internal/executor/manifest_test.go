@@ -0,0 +1,38 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateManifest(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{"package.json", "package.json", `{"type": "module", "dependencies": {"zod": "^3.23.0", "@types/node": "20.x"}, "devDependencies": {"tsx": "4"}}`, ""},
+		{"unchecked manifest", "requirements.txt", "{not json", ""},
+		{"not json", "package.json", `{"dependencies": `, "invalid package.json"},
+		{"not an object", "package.json", `["zod"]`, "invalid package.json"},
+		{"dependency list", "package.json", `{"dependencies": ["zod"]}`, "dependencies must map package names to versions"},
+		{"invalid package name", "package.json", `{"dependencies": {"Zod; rm -rf /": "1"}}`, "is not an npm package name"},
+		{"empty package name", "package.json", `{"optionalDependencies": {"": "1"}}`, "is not an npm package name"},
+		{"unknown type", "package.json", `{"type": "esm"}`, "type must be"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateManifest(tt.file, tt.content)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateManifest() returned error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateManifest() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+// Package executor implements host volume mount parsing and allowlist validation
+// for Docker-based executions.
+package executor
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Mount maps a host path into the execution container.
+type Mount struct {
+	Source   string // Host path
+	Target   string // Absolute path inside the container
+	ReadOnly bool
+}
+
+// ParseMount parses a mount specification of the form
+// "host_path:container_path[:ro|rw]". Mounts are read-only unless "rw" is given.
+func ParseMount(spec string) (Mount, error) {
+	parts := strings.Split(strings.TrimSpace(spec), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Mount{}, fmt.Errorf("invalid mount %q: expected host_path:container_path[:ro|rw]", spec)
+	}
+
+	m := Mount{
+		Source:   strings.TrimSpace(parts[0]),
+		Target:   strings.TrimSpace(parts[1]),
+		ReadOnly: true,
+	}
+	if len(parts) == 3 {
+		switch strings.ToLower(strings.TrimSpace(parts[2])) {
+		case "ro":
+			m.ReadOnly = true
+		case "rw":
+			m.ReadOnly = false
+		default:
+			return Mount{}, fmt.Errorf("invalid mount mode %q in %q: expected ro or rw", parts[2], spec)
+		}
+	}
+
+	if m.Source == "" || m.Target == "" {
+		return Mount{}, fmt.Errorf("invalid mount %q: host and container paths must not be empty", spec)
+	}
+	if strings.Contains(m.Source, ",") || strings.Contains(m.Target, ",") {
+		return Mount{}, fmt.Errorf("invalid mount %q: paths must not contain commas", spec)
+	}
+	if !path.IsAbs(m.Target) {
+		return Mount{}, fmt.Errorf("invalid mount %q: container path must be absolute", spec)
+	}
+	m.Target = path.Clean(m.Target)
+
+	return m, nil
+}
+
+// ValidateMounts resolves each mount source to an absolute, symlink-free path and
+// verifies that it lies within one of the allowed roots. It returns the resolved mounts.
+func ValidateMounts(mounts []Mount, allowedRoots []string) ([]Mount, error) {
+	if len(mounts) == 0 {
+		return nil, nil
+	}
+	if len(allowedRoots) == 0 {
+		return nil, NewExecutionError(ErrorPolicyViolation, "host mounts are disabled: no allowed mount roots are configured")
+	}
+
+	roots := make([]string, 0, len(allowedRoots))
+	for _, root := range allowedRoots {
+		resolved, err := resolvePath(root)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed mount root %q: %v", root, err)
+		}
+		roots = append(roots, resolved)
+	}
+
+	resolved := make([]Mount, 0, len(mounts))
+	for _, m := range mounts {
+		source, err := resolvePath(m.Source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mount source %q: %v", m.Source, err)
+		}
+		if !withinAnyRoot(source, roots) {
+			return nil, NewExecutionError(ErrorPolicyViolation, "mount source %q is outside the allowed mount roots", m.Source)
+		}
+		m.Source = source
+		resolved = append(resolved, m)
+	}
+
+	return resolved, nil
+}
+
+// resolvePath returns the absolute path with all symlinks evaluated.
+func resolvePath(p string) (string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(abs); err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+func withinAnyRoot(p string, roots []string) bool {
+	for _, root := range roots {
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			continue
+		}
+		if rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))) {
+			return true
+		}
+	}
+	return false
+}
+
+// mountArgs converts mounts into docker run --mount arguments.
+func mountArgs(mounts []Mount) []string {
+	var args []string
+	for _, m := range mounts {
+		spec := "type=bind,source=" + m.Source + ",target=" + m.Target
+		if m.ReadOnly {
+			spec += ",readonly"
+		}
+		args = append(args, "--mount", spec)
+	}
+	return args
+}
@@ -0,0 +1,114 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseMount(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    Mount
+		wantErr bool
+	}{
+		{
+			name: "defaults to read-only",
+			spec: "/data:/mnt/data",
+			want: Mount{Source: "/data", Target: "/mnt/data", ReadOnly: true},
+		},
+		{
+			name: "explicit read-write",
+			spec: "/data:/mnt/data:rw",
+			want: Mount{Source: "/data", Target: "/mnt/data", ReadOnly: false},
+		},
+		{
+			name: "container path is cleaned",
+			spec: " ./data : /mnt/../mnt/data/ ",
+			want: Mount{Source: "./data", Target: "/mnt/data", ReadOnly: true},
+		},
+		{name: "missing container path", spec: "/data", wantErr: true},
+		{name: "relative container path", spec: "/data:data", wantErr: true},
+		{name: "unknown mode", spec: "/data:/mnt:rx", wantErr: true},
+		{name: "too many parts", spec: "/a:/b:ro:extra", wantErr: true},
+		{name: "empty host path", spec: ":/mnt", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMount(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMount(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseMount(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateMounts(t *testing.T) {
+	root := t.TempDir()
+	allowed := filepath.Join(root, "allowed")
+	outside := filepath.Join(root, "outside")
+	for _, dir := range []string{allowed, filepath.Join(allowed, "sub"), outside} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	escape := filepath.Join(allowed, "escape")
+	if err := os.Symlink(outside, escape); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		source  string
+		roots   []string
+		wantErr string
+	}{
+		{name: "root itself", source: allowed, roots: []string{allowed}},
+		{name: "subdirectory", source: filepath.Join(allowed, "sub"), roots: []string{allowed}},
+		{name: "outside root", source: outside, roots: []string{allowed}, wantErr: "outside the allowed mount roots"},
+		{name: "dot-dot traversal", source: filepath.Join(allowed, "..", "outside"), roots: []string{allowed}, wantErr: "outside the allowed mount roots"},
+		{name: "symlink escape", source: escape, roots: []string{allowed}, wantErr: "outside the allowed mount roots"},
+		{name: "no roots configured", source: allowed, roots: nil, wantErr: "host mounts are disabled"},
+		{name: "missing source", source: filepath.Join(allowed, "missing"), roots: []string{allowed}, wantErr: "invalid mount source"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mounts := []Mount{{Source: tt.source, Target: "/mnt/data", ReadOnly: true}}
+			got, err := ValidateMounts(mounts, tt.roots)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ValidateMounts() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateMounts() unexpected error: %v", err)
+			}
+			if !filepath.IsAbs(got[0].Source) {
+				t.Errorf("Resolved source %q should be absolute", got[0].Source)
+			}
+		})
+	}
+}
+
+func TestMountArgs(t *testing.T) {
+	args := mountArgs([]Mount{
+		{Source: "/data", Target: "/mnt/data", ReadOnly: true},
+		{Source: "/out", Target: "/mnt/out", ReadOnly: false},
+	})
+
+	want := []string{
+		"--mount", "type=bind,source=/data,target=/mnt/data,readonly",
+		"--mount", "type=bind,source=/out,target=/mnt/out",
+	}
+	if strings.Join(args, " ") != strings.Join(want, " ") {
+		t.Errorf("mountArgs() = %v, want %v", args, want)
+	}
+}
@@ -0,0 +1,194 @@
+// Package executor implements Nix-based code execution, which runs code on the
+// host inside a nix-shell providing the interpreter and requested packages for
+// reproducible, cache-friendly dependency resolution without Docker.
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// nixAttribute matches nixpkgs attribute paths such as python3Packages.requests.
+// Anything else could inject Nix expressions into nix-shell -p.
+var nixAttribute = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_'+-]*(\.[A-Za-z_][A-Za-z0-9_'+-]*)*$`)
+
+// NixConfig describes how a language runs inside nix-shell.
+type NixConfig struct {
+	// Packages returns the nixpkgs attributes providing the interpreter for a
+	// runtime version ("" for the default) and the prefix of dependency attributes.
+	Packages     func(version string) (base []string, dependencyPrefix string)
+	RunCmd       string // Command run on the code file, e.g. "python3"
+	CheckCmd     string // Command checking the code file, see checkArgs
+	CheckPackage string // Attribute providing CheckCmd when the interpreter does not
+	FileName     string // Name of the code file
+	ExecutorName string
+}
+
+type NixExecutor struct {
+	config NixConfig
+}
+
+func NewNixPythonExecutor() *NixExecutor {
+	return &NixExecutor{config: NixConfig{
+		Packages: func(version string) ([]string, string) {
+			python := "python3"
+			if version != "" {
+				// nixpkgs names interpreters by major and minor version, e.g. python312
+				parts := strings.Split(version, ".")
+				python = "python" + strings.Join(parts[:min(2, len(parts))], "")
+			}
+			return []string{python}, python + "Packages."
+		},
+		RunCmd:       "python3",
+		CheckCmd:     "python3 -m py_compile",
+		FileName:     "main.py",
+		ExecutorName: "python-nix",
+	}}
+}
+
+func NewNixBashExecutor() *NixExecutor {
+	return &NixExecutor{config: NixConfig{
+		Packages:     func(string) ([]string, string) { return []string{"bash"}, "" },
+		RunCmd:       "bash",
+		CheckCmd:     "bash -n",
+		FileName:     "main.sh",
+		ExecutorName: "bash-nix",
+	}}
+}
+
+func NewNixTypeScriptExecutor() *NixExecutor {
+	return &NixExecutor{config: NixConfig{
+		Packages: func(version string) ([]string, string) {
+			if version == "" {
+				return []string{"nodejs", "tsx"}, ""
+			}
+			return []string{"nodejs_" + version, "tsx"}, ""
+		},
+		RunCmd:       "tsx",
+		CheckCmd:     "tsc " + strings.Join(tscFlags, " "),
+		CheckPackage: "typescript",
+		FileName:     "main.ts",
+		ExecutorName: "typescript-nix",
+	}}
+}
+
+func NewNixGoExecutor() *NixExecutor {
+	return &NixExecutor{config: NixConfig{
+		Packages: func(version string) ([]string, string) {
+			if version == "" {
+				return []string{"go"}, ""
+			}
+			return []string{"go_" + strings.ReplaceAll(version, ".", "_")}, ""
+		},
+		RunCmd:       "go run",
+		CheckCmd:     "go vet",
+		FileName:     "main.go",
+		ExecutorName: "go-nix",
+	}}
+}
+
+func (n *NixExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	logger.Debug("Starting %s execution", n.config.ExecutorName)
+	options := NewOptions(opts...)
+	if options.DependencyFile != "" {
+		return "", NewExecutionError(ErrorPolicyViolation, "dependency_file is not supported by %s, list the packages instead", n.config.ExecutorName)
+	}
+	if len(options.Files) > 0 {
+		return "", NewExecutionError(ErrorPolicyViolation, "files are not supported by %s", n.config.ExecutorName)
+	}
+
+	args, err := n.shellArgs(dependencies, options.RuntimeVersion)
+	if err != nil {
+		return "", err
+	}
+	nixShell, err := exec.LookPath("nix-shell")
+	if err != nil {
+		return "", fmt.Errorf("nix-shell not found on system - please install Nix (https://nixos.org/download) or use another execution mode")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "mcp-executor-nix-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	codeFile := filepath.Join(tmpDir, n.config.FileName)
+	if err := os.WriteFile(codeFile, []byte(code), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write code file: %v", err)
+	}
+	if err := grantRunAs(ctx, tmpDir, codeFile); err != nil {
+		return "", err
+	}
+	runCmd := n.config.RunCmd
+	if options.CheckOnly {
+		runCmd = n.config.CheckCmd
+		if n.config.CheckPackage != "" {
+			args = append(args, n.config.CheckPackage)
+		}
+	}
+	args = append(args, "--run", runCmd+" "+shellQuote(codeFile))
+
+	if len(dependencies) > 0 {
+		logger.InfoContext(ctx, "Providing %s dependencies with nix-shell: %s", n.config.ExecutorName, strings.Join(dependencies, ", "))
+	}
+	logger.Verbose("Running: nix-shell %s", strings.Join(args, " "))
+	logger.Debug("Code to execute:\n%s", code)
+
+	cmd := exec.CommandContext(ctx, nixShell, args...)
+	cmd.Dir = tmpDir
+	if input, piped := Stdin(ctx); piped && !options.CheckOnly {
+		cmd.Stdin = strings.NewReader(input)
+	}
+
+	// Set environment variables
+	cmd.Env = hostEnv(ctx) // Start with the passed-through server environment
+	for key, value := range envVars {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+
+	cmd.WaitDelay = waitDelay
+	out, err := runWithUsage(ctx, cmd)
+	if err != nil {
+		logger.Debug("Execution failed: %v", err)
+		if exitError, ok := err.(*exec.ExitError); ok {
+			if options.CheckOnly && exitError.Exited() {
+				return "", newCompileError(n.config.ExecutorName, string(out))
+			}
+			return "", exitErr(n.config.ExecutorName, exitError, string(out))
+		}
+		return "", fmt.Errorf("execution failed: %v", err)
+	}
+
+	logger.Debug("Execution completed successfully, output length: %d bytes", len(out))
+	return string(out), nil
+}
+
+// shellArgs returns the nix-shell arguments providing the interpreter for version
+// and the dependencies, which are nixpkgs attribute names (Python modules are
+// looked up in the interpreter's package set).
+func (n *NixExecutor) shellArgs(dependencies []string, version string) ([]string, error) {
+	base, prefix := n.config.Packages(version)
+	for _, attribute := range base {
+		if !nixAttribute.MatchString(attribute) {
+			return nil, NewExecutionError(ErrorPolicyViolation, "invalid runtime_version %q for %s", version, n.config.ExecutorName)
+		}
+	}
+	args := append([]string{"--quiet", "-p"}, base...)
+	for _, dependency := range dependencies {
+		dependency = strings.TrimSpace(dependency)
+		if dependency == "" {
+			continue
+		}
+		if !nixAttribute.MatchString(prefix + dependency) {
+			return nil, NewExecutionError(ErrorPolicyViolation, "invalid package %q: expected a nixpkgs attribute name", dependency)
+		}
+		args = append(args, prefix+dependency)
+	}
+	return args, nil
+}
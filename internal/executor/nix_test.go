@@ -0,0 +1,43 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNixExecutor_ShellArgs(t *testing.T) {
+	tests := []struct {
+		name         string
+		executor     *NixExecutor
+		dependencies []string
+		version      string
+		want         string
+		wantErr      string
+	}{
+		{"python", NewNixPythonExecutor(), []string{"requests", " numpy "}, "", "--quiet -p python3 python3Packages.requests python3Packages.numpy", ""},
+		{"python version", NewNixPythonExecutor(), []string{"requests"}, "3.12", "--quiet -p python312 python312Packages.requests", ""},
+		{"bash", NewNixBashExecutor(), []string{"jq", "curl"}, "", "--quiet -p bash jq curl", ""},
+		{"typescript version", NewNixTypeScriptExecutor(), nil, "20", "--quiet -p nodejs_20 tsx", ""},
+		{"go version", NewNixGoExecutor(), nil, "1.22", "--quiet -p go_1_22", ""},
+		{"nix expression", NewNixBashExecutor(), []string{"(import <nixpkgs> {}).hello"}, "", "", "invalid package"},
+		{"shell metacharacters", NewNixBashExecutor(), []string{"jq;rm"}, "", "", "invalid package"},
+		{"invalid version", NewNixGoExecutor(), nil, "1.22; rm", "", "invalid runtime_version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := tt.executor.shellArgs(tt.dependencies, tt.version)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("shellArgs() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("shellArgs() returned error: %v", err)
+			}
+			if got := strings.Join(args, " "); got != tt.want {
+				t.Errorf("shellArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+// Package executor provides an executor decorator for servers without network
+// access: dependency installation is refused and containers get no network.
+package executor
+
+import (
+	"context"
+	"strings"
+)
+
+// OfflineExecutor rejects executions that would install dependencies and runs
+// the others with the "none" container network.
+type OfflineExecutor struct {
+	executor Executor
+}
+
+// NewOfflineExecutor wraps exec for offline use. Host processes in subprocess
+// and Nix mode keep the host's network, which the server cannot take away.
+func NewOfflineExecutor(exec Executor) Executor {
+	return &OfflineExecutor{executor: exec}
+}
+
+func (o *OfflineExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	options := NewOptions(opts...)
+	if len(dependencies) > 0 {
+		return "", NewExecutionError(ErrorPolicyViolation, "cannot install %s: dependency installation is disabled because the server runs offline; use the standard library or preinstalled packages", strings.Join(dependencies, ", "))
+	}
+	if options.DependencyFile != "" {
+		return "", NewExecutionError(ErrorPolicyViolation, "cannot install the dependency file: dependency installation is disabled because the server runs offline")
+	}
+	if options.Network != "" && options.Network != "none" {
+		return "", NewExecutionError(ErrorPolicyViolation, "network %q is not available because the server runs offline", options.Network)
+	}
+	return o.executor.Execute(ctx, code, dependencies, envVars, append(opts, WithNetwork("none"))...)
+}
@@ -0,0 +1,54 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// optionsRecorder records the options of the last execution.
+type optionsRecorder struct {
+	options *Options
+}
+
+func (r *optionsRecorder) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	options := NewOptions(opts...)
+	r.options = &options
+	return "", nil
+}
+
+func TestOfflineExecutor(t *testing.T) {
+	tests := []struct {
+		name         string
+		dependencies []string
+		opts         []Option
+		wantErr      string
+	}{
+		{"no dependencies", nil, nil, ""},
+		{"network none", nil, []Option{WithNetwork("none")}, ""},
+		{"dependencies", []string{"requests"}, nil, "cannot install requests: dependency installation is disabled"},
+		{"dependency file", nil, []Option{WithDependencyFile("requests\n")}, "cannot install the dependency file"},
+		{"bridge network", nil, []Option{WithNetwork("bridge")}, `network "bridge" is not available`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &optionsRecorder{}
+			_, err := NewOfflineExecutor(recorder).Execute(context.Background(), "print(1)", tt.dependencies, nil, tt.opts...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Execute() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				if recorder.options != nil {
+					t.Error("Rejected executions should not reach the wrapped executor")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() returned error: %v", err)
+			}
+			if recorder.options.Network != "none" {
+				t.Errorf("Network = %q, want none", recorder.options.Network)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+// Package executor provides the output post-processors that strip terminal
+// noise such as ANSI escape codes and progress bars from execution output
+// before it reaches the client.
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// OutputProcessor rewrites the output of an execution.
+type OutputProcessor func(output string) string
+
+// outputProcessors are the available post-processors by configuration name.
+var outputProcessors = map[string]OutputProcessor{
+	"strip_ansi":        stripANSI,
+	"collapse_progress": collapseProgress,
+	"limit_repeats":     limitRepeats,
+	"pretty_json":       prettyJSON,
+}
+
+// OutputProcessorNames returns the names of the available post-processors, sorted.
+func OutputProcessorNames() []string {
+	names := make([]string, 0, len(outputProcessors))
+	for name := range outputProcessors {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ValidateOutputProcessors reports the first name that is not an available
+// post-processor.
+func ValidateOutputProcessors(names []string) error {
+	for _, name := range names {
+		if _, ok := outputProcessors[name]; !ok {
+			return fmt.Errorf("unknown output processor %q (expected %s)", name, strings.Join(OutputProcessorNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// OutputExecutor passes the output of the wrapped executor, and the message of
+// its errors, through a pipeline of post-processors.
+type OutputExecutor struct {
+	executor   Executor
+	processors []OutputProcessor
+}
+
+// NewOutputExecutor wraps exec with the named post-processors, applied in the
+// given order. Unknown names are ignored; check them with
+// ValidateOutputProcessors. Without processors exec is returned unchanged.
+func NewOutputExecutor(exec Executor, names []string) Executor {
+	var processors []OutputProcessor
+	for _, name := range names {
+		if processor, ok := outputProcessors[name]; ok {
+			processors = append(processors, processor)
+		}
+	}
+	if len(processors) == 0 {
+		return exec
+	}
+	return &OutputExecutor{executor: exec, processors: processors}
+}
+
+func (o *OutputExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	output, err := o.executor.Execute(ctx, code, dependencies, envVars, opts...)
+	output = o.process(output)
+	if err != nil {
+		if message := o.process(err.Error()); message != err.Error() {
+			err = &processedError{message: message, err: err}
+		}
+	}
+	return output, err
+}
+
+// process runs output through the pipeline.
+func (o *OutputExecutor) process(output string) string {
+	for _, processor := range o.processors {
+		output = processor(output)
+	}
+	return output
+}
+
+// processedError replaces the message of an execution error with its
+// post-processed form while keeping the error itself, e.g. a
+// TerminationError, available to errors.As.
+type processedError struct {
+	message string
+	err     error
+}
+
+func (e *processedError) Error() string { return e.message }
+func (e *processedError) Unwrap() error { return e.err }
+
+// ansiEscape matches CSI sequences (colors, cursor movement), OSC sequences
+// (window titles, hyperlinks) and other two-byte escapes.
+var ansiEscape = regexp.MustCompile("\x1b(?:\\[[0-9:;<=>?]*[ -/]*[@-~]|\\][^\x07\x1b]*(?:\x07|\x1b\\\\)|[@-Z\\\\-_])")
+
+// stripANSI removes ANSI escape sequences.
+func stripANSI(output string) string {
+	return ansiEscape.ReplaceAllString(output, "")
+}
+
+// progressLine matches lines reporting progress: a percentage, a transferred
+// size such as "1.2/3.4 MB", or a bar followed by a number.
+var progressLine = regexp.MustCompile(`\d(?:\.\d+)?\s?%|\d(?:\.\d+)?\s?/\s?\d+(?:\.\d+)?\s?(?:[kKMGT]i?)?B\b|[━█▉▊▋▌▍▎▏#=]{5,}.*\d`)
+
+// collapseProgress keeps what a terminal would show of lines redrawn with
+// carriage returns, and only the last line of consecutive progress lines.
+func collapseProgress(output string) string {
+	lines := strings.Split(output, "\n")
+	kept := lines[:0]
+	previousProgress := false
+	for _, line := range lines {
+		if strings.Contains(line, "\r") {
+			segments := strings.Split(strings.TrimRight(line, "\r"), "\r")
+			line = segments[len(segments)-1]
+		}
+		isProgress := progressLine.MatchString(line)
+		if isProgress && previousProgress {
+			kept[len(kept)-1] = line
+			continue
+		}
+		kept = append(kept, line)
+		previousProgress = isProgress
+	}
+	return strings.Join(kept, "\n")
+}
+
+// maxRepeatedLines is how many identical consecutive lines limitRepeats keeps
+// before summarizing the rest.
+const maxRepeatedLines = 2
+
+// limitRepeats replaces identical consecutive lines beyond maxRepeatedLines
+// with a count.
+func limitRepeats(output string) string {
+	lines := strings.Split(output, "\n")
+	var kept []string
+	for i := 0; i < len(lines); {
+		run := 1
+		for i+run < len(lines) && lines[i+run] == lines[i] && lines[i] != "" {
+			run++
+		}
+		for j := 0; j < min(run, maxRepeatedLines); j++ {
+			kept = append(kept, lines[i])
+		}
+		if run > maxRepeatedLines {
+			kept = append(kept, fmt.Sprintf("[previous line repeated %d more times]", run-maxRepeatedLines))
+		}
+		i += run
+	}
+	return strings.Join(kept, "\n")
+}
+
+// prettyJSON indents output that is a single JSON object or array.
+func prettyJSON(output string) string {
+	trimmed := strings.TrimSpace(output)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return output
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, []byte(trimmed), "", "  "); err != nil {
+		return output
+	}
+	if strings.HasSuffix(output, "\n") {
+		indented.WriteByte('\n')
+	}
+	return indented.String()
+}
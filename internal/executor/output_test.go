@@ -0,0 +1,70 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// fixedExecutor returns the same output and error for every execution.
+type fixedExecutor struct {
+	output string
+	err    error
+}
+
+func (f *fixedExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	return f.output, f.err
+}
+
+func TestOutputProcessors(t *testing.T) {
+	tests := []struct {
+		name      string
+		processor string
+		output    string
+		want      string
+	}{
+		{"ansi colors", "strip_ansi", "\x1b[1;31mError\x1b[0m: failed\n", "Error: failed\n"},
+		{"ansi title and cursor", "strip_ansi", "\x1b]0;title\x07\x1b[2Kdone\x1b[?25h", "done"},
+		{"plain text", "strip_ansi", "50% [ok]\n", "50% [ok]\n"},
+		{"carriage returns", "collapse_progress", "Downloading  10%\rDownloading  60%\rDownloading 100%\r\ndone\n", "Downloading 100%\ndone\n"},
+		{"progress lines", "collapse_progress", "Collecting numpy\n   ━━━━━━━━━━ 1.2/12.8 MB\n   ━━━━━━━━━━ 12.8/12.8 MB\nInstalled\n", "Collecting numpy\n   ━━━━━━━━━━ 12.8/12.8 MB\nInstalled\n"},
+		{"single progress line", "collapse_progress", "loss 0.5\naccuracy 93%\nloss 0.4\n", "loss 0.5\naccuracy 93%\nloss 0.4\n"},
+		{"repeated lines", "limit_repeats", "start\nwarn\nwarn\nwarn\nwarn\nwarn\nend\n", "start\nwarn\nwarn\n[previous line repeated 3 more times]\nend\n"},
+		{"two repeats", "limit_repeats", "a\na\nb\n", "a\na\nb\n"},
+		{"blank lines", "limit_repeats", "a\n\n\n\nb", "a\n\n\n\nb"},
+		{"json object", "pretty_json", `{"a":1,"b":[true,null]}` + "\n", "{\n  \"a\": 1,\n  \"b\": [\n    true,\n    null\n  ]\n}\n"},
+		{"not json", "pretty_json", "{not json}\n", "{not json}\n"},
+		{"json lines", "pretty_json", "{\"a\":1}\n{\"b\":2}\n", "{\"a\":1}\n{\"b\":2}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := outputProcessors[tt.processor](tt.output); got != tt.want {
+				t.Errorf("%s(%q) = %q, want %q", tt.processor, tt.output, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputExecutor(t *testing.T) {
+	terminated := &TerminationError{Reason: TerminationTimeout, Message: "timed out", Output: "\x1b[32mpartial\x1b[0m"}
+	fixed := &fixedExecutor{output: "\x1b[1m{\"a\":1}\x1b[0m", err: terminated}
+	exec := NewOutputExecutor(fixed, []string{"strip_ansi", "pretty_json"})
+
+	output, err := exec.Execute(context.Background(), "code", nil, nil)
+	if output != "{\n  \"a\": 1\n}" {
+		t.Errorf("Execute() output = %q, want stripped and indented JSON", output)
+	}
+	if err == nil || err.Error() != "timed out: partial" {
+		t.Errorf("Execute() error = %v, want the stripped message", err)
+	}
+	if !errors.Is(err, terminated) {
+		t.Error("Execute() error should wrap the TerminationError")
+	}
+
+	if NewOutputExecutor(fixed, nil) != Executor(fixed) {
+		t.Error("NewOutputExecutor() without processors should return the executor unchanged")
+	}
+	if err := ValidateOutputProcessors([]string{"strip_ansi", "colors"}); err == nil {
+		t.Error("ValidateOutputProcessors() should reject unknown names")
+	}
+}
@@ -0,0 +1,60 @@
+// Package executor validates the dependency specifications passed to executions,
+// so pinned versions are accepted while malformed entries, shell metacharacters
+// and installer options are rejected before reaching an installer.
+package executor
+
+import (
+	"regexp"
+	"strings"
+)
+
+// dependencySpecs holds the accepted dependency syntax of each language with an
+// example of it for error messages. No entry may start with "-", which the
+// installer would parse as an option such as --index-url or --unsafe-perm.
+var dependencySpecs = map[string]struct {
+	pattern *regexp.Regexp
+	example string
+}{
+	// PEP 508 names with optional extras and comma-separated version clauses
+	"python": {
+		regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._-]*[A-Za-z0-9])?(\[[A-Za-z0-9._-]+(,[A-Za-z0-9._-]+)*\])?((===?|~=|!=|<=?|>=?)[A-Za-z0-9.*+!_-]+(,(===?|~=|!=|<=?|>=?)[A-Za-z0-9.*+!_-]+)*)?$`),
+		"requests or requests==2.32.0",
+	},
+	// Debian package names with an optional =version
+	"bash": {
+		regexp.MustCompile(`^[a-z0-9][a-z0-9+.-]+(:[a-z0-9-]+)?(=[A-Za-z0-9.+~:-]+)?$`),
+		"curl or curl=7.81.0-1ubuntu1.16",
+	},
+	// npm names, optionally scoped, with an optional @version or range
+	"typescript": {
+		regexp.MustCompile(`^(@[a-z0-9~][a-z0-9._~-]*/)?[a-z0-9~][a-z0-9._~-]*(@[A-Za-z0-9.^~<>=*+-]+)?$`),
+		"lodash or lodash@4",
+	},
+	// Go module or package paths with an optional @version
+	"go": {
+		regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.-]*(/[A-Za-z0-9._~-]+)*(@[A-Za-z0-9._+-]+)?$`),
+		"github.com/google/uuid or github.com/google/uuid@v1.6.0",
+	},
+}
+
+// ValidateDependencies trims the dependency specifications of language, drops
+// empty entries and rejects entries that are not valid for its installer.
+// Languages without a known syntax accept no dependencies.
+func ValidateDependencies(language string, dependencies []string) ([]string, error) {
+	spec, ok := dependencySpecs[language]
+	var valid []string
+	for _, dependency := range dependencies {
+		dependency = strings.TrimSpace(dependency)
+		if dependency == "" {
+			continue
+		}
+		if !ok {
+			return nil, NewExecutionError(ErrorPolicyViolation, "invalid %s dependency %q: dependencies are not supported for %s", language, dependency, language)
+		}
+		if !spec.pattern.MatchString(dependency) {
+			return nil, NewExecutionError(ErrorPolicyViolation, "invalid %s dependency %q: expected a package name with an optional version, e.g. %s", language, dependency, spec.example)
+		}
+		valid = append(valid, dependency)
+	}
+	return valid, nil
+}
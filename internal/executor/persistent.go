@@ -0,0 +1,238 @@
+// Package executor provides the persistent containers of hybrid mode: one
+// long-lived container per client, language and network, into which code is
+// injected with docker exec for subprocess-like latency.
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// PersistentLabel marks the long-lived containers of hybrid mode with their
+// language. They carry no LanguageLabel, so the container reaper and
+// "sessions kill" leave them alone.
+const PersistentLabel = "mcp-executor.persistent"
+
+// DefaultPersistentIdle is how long a persistent container without executions
+// lives when no idle timeout is configured.
+const DefaultPersistentIdle = 10 * time.Minute
+
+// persistentAliveFile is touched by every execution in a persistent container;
+// the container exits once it is older than the idle timeout.
+const persistentAliveFile = "/tmp/.mcp-executor-alive"
+
+// ContainerPool starts and tracks the persistent containers shared by the
+// PersistentExecutors of a server.
+type ContainerPool struct {
+	mu         sync.Mutex
+	idle       time.Duration
+	containers map[string]string // Container name by poolKey
+}
+
+// NewContainerPool creates a pool whose containers exit after running no
+// execution for idle, which also removes them when the server is gone.
+func NewContainerPool(idle time.Duration) *ContainerPool {
+	if idle <= 0 {
+		idle = DefaultPersistentIdle
+	}
+	return &ContainerPool{idle: idle, containers: make(map[string]string)}
+}
+
+// poolKey identifies the persistent container of the client of ctx running
+// config with network. Files and packages left behind by the executions of a
+// container are visible to the next ones, so clients never share containers.
+func poolKey(ctx context.Context, config ExecutorConfig, network string) string {
+	return strings.Join([]string{ClientID(ctx), config.ExecutorName, config.Image, network}, "\x00")
+}
+
+// container returns the name of the running persistent container of the
+// client of ctx for config and network, starting one when there is none.
+func (p *ContainerPool) container(ctx context.Context, config ExecutorConfig, network string) (string, error) {
+	key := poolKey(ctx, config, network)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if name, ok := p.containers[key]; ok {
+		return name, nil
+	}
+
+	name := "mcp-executor-warm-" + config.ExecutorName + "-" + randomSuffix()
+	args := []string{"run", "-d", "--rm", "--name", name, "--label", PersistentLabel + "=" + config.ExecutorName}
+	if client := ClientID(ctx); client != "" {
+		args = append(args, "--label", ClientLabel+"="+client)
+	}
+	if config.Memory != "" {
+		args = append(args, "--memory", config.Memory)
+	}
+	if config.CPUs != "" {
+		args = append(args, "--cpus", config.CPUs)
+	}
+	if config.DiskMB > 0 {
+		// Shared by the executions of the container
+		args = append(args, "--tmpfs", tmpfsMount(config.DiskMB))
+	}
+	if network != "" {
+		args = append(args, "--network", network)
+	}
+	args = append(args, config.goCacheArgs(nil)...)
+	args = append(args, config.Image, "sh", "-c", p.watchdogScript())
+	logger.Info("Starting persistent %s container %s from %s", config.ExecutorName, name, config.Image)
+	if out, err := exec.CommandContext(ctx, "docker", args...).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to start persistent %s container: %v: %s", config.ExecutorName, err, strings.TrimSpace(string(out)))
+	}
+	p.containers[key] = name
+	return name, nil
+}
+
+// forget drops a container that stopped, so the next execution starts a new one.
+func (p *ContainerPool) forget(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, container := range p.containers {
+		if container == name {
+			delete(p.containers, key)
+		}
+	}
+}
+
+// watchdogScript keeps a persistent container running until no execution ran
+// in it for the idle timeout. Running executions keep their directory below
+// ContainerWorkspace and count as activity.
+func (p *ContainerPool) watchdogScript() string {
+	idle := strconv.Itoa(int(p.idle.Seconds()))
+	return `mkdir -p ` + ContainerWorkspace + ` && touch ` + persistentAliveFile + `; ` +
+		`while sleep 5; do ` +
+		`[ -z "$(ls -A ` + ContainerWorkspace + `)" ] || touch ` + persistentAliveFile + `; ` +
+		`[ $(( $(date +%s) - $(stat -c %Y ` + persistentAliveFile + `) )) -lt ` + idle + ` ] || exit 0; ` +
+		`done`
+}
+
+// PersistentExecutor runs code in the persistent container of its language.
+// Calls installing dependencies, writing files, checking code, saving a
+// snapshot or selecting mounts, a workspace, a profile or a runtime version
+// need a container of their own and run in one like in Docker mode, as do the
+// calls of sessions that restored a snapshot, code referring to the artifacts
+// directory, which is mounted into each container, and executions reading
+// input on stdin.
+type PersistentExecutor struct {
+	docker *DockerExecutor
+	pool   *ContainerPool
+}
+
+// NewPersistentExecutor wraps docker, running the calls it supports in the
+// persistent containers of pool.
+func NewPersistentExecutor(docker *DockerExecutor, pool *ContainerPool) Executor {
+	return &PersistentExecutor{docker: docker, pool: pool}
+}
+
+// persistentSupported reports whether a call can run in a persistent container.
+func persistentSupported(dependencies []string, options Options) bool {
+	return len(dependencies) == 0 && options.DependencyFile == "" && len(options.Files) == 0 && len(options.Mounts) == 0 &&
+		options.WorkspaceDir == "" && options.Profile == "" && options.RuntimeVersion == "" && options.Snapshot == "" && !options.ReadOnly && !options.CheckOnly
+}
+
+func (p *PersistentExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	options := NewOptions(opts...)
+	_, piped := Stdin(ctx)
+	if !persistentSupported(dependencies, options) || SnapshotImage(ctx, p.docker.config.ExecutorName) != "" || piped ||
+		(options.ArtifactsDir != "" && usesArtifacts(code)) || p.docker.usesBrowserImage(code, dependencies, options) {
+		logger.DebugContext(ctx, "Running %s execution in a container of its own", p.docker.config.ExecutorName)
+		return p.docker.Execute(ctx, code, dependencies, envVars, opts...)
+	}
+
+	output, stopped, err := p.execute(ctx, code, envVars, options)
+	if stopped {
+		// The container exited while idle or was killed; start a new one once
+		logger.Info("Persistent %s container is gone, starting a new one", p.docker.config.ExecutorName)
+		output, _, err = p.execute(ctx, code, envVars, options)
+	}
+	return output, err
+}
+
+// execute runs code in the persistent container and reports whether the
+// container was not running.
+func (p *PersistentExecutor) execute(ctx context.Context, code string, envVars map[string]string, options Options) (string, bool, error) {
+	image, _, err := p.docker.preinstalledImage(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	config := p.docker.config
+	config.Image = image
+	name, err := p.pool.container(ctx, config, options.Network)
+	if err != nil {
+		return "", false, err
+	}
+
+	// Each execution gets its own directory, which is its MCP_WORKSPACE
+	dir := ContainerWorkspace + "/" + randomSuffix()
+	env := make(map[string]string, len(envVars))
+	for key, value := range envVars {
+		env[key] = value
+	}
+	if env[WorkspaceEnv] == ContainerWorkspace {
+		env[WorkspaceEnv] = dir
+	}
+	delete(env, ArtifactsEnv)
+
+	logger.Debug("Code to execute in %s:\n%s", name, code)
+	// The command is made of shell words, e.g. Go's chains writing main.go and running it
+	command := []string{"sh", "-c", strings.Join(p.docker.config.ExecuteCmd, " ")}
+	cmd := exec.CommandContext(ctx, "docker", persistentExecArgs(name, dir, env, command)...)
+	cmd.Cancel = func() error {
+		// Killing docker exec leaves the code running in the container
+		logger.WarnContext(ctx, "Execution cancelled, killing its processes in container %s", name)
+		_ = exec.Command("docker", "exec", name, "sh", "-c", `kill -s KILL -- -"$(cat "$0/.pid")"`, dir).Run()
+		return cmd.Process.Kill()
+	}
+	cmd.Stdin = strings.NewReader(code)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	startedAt := time.Now()
+	out, err := cmd.Output()
+	// The cgroup of the container is shared by its executions; only the wall time is known
+	reportUsage(ctx, Usage{WallTime: time.Since(startedAt)})
+	if err != nil {
+		if exitError, ok := err.(*exec.ExitError); ok {
+			if containerGone(exitError.ExitCode(), stderr.String()) {
+				p.pool.forget(name)
+				return "", true, fmt.Errorf("persistent %s container %s is not running", p.docker.config.ExecutorName, name)
+			}
+			return "", false, p.docker.exitErr(exitError.ExitCode(), Usage{}, string(out), stderr.String())
+		}
+		return "", false, fmt.Errorf("execution failed: %v", err)
+	}
+	return string(out), false, nil
+}
+
+// persistentExecArgs returns the docker exec arguments running command in dir
+// of container with env. The code runs in a session of its own, whose process
+// group ID is written to dir/.pid so that it can be killed; dir is removed
+// afterwards.
+func persistentExecArgs(container, dir string, env map[string]string, command []string) []string {
+	args := []string{"exec", "-i"}
+	for key, value := range env {
+		args = append(args, "-e", key+"="+value)
+	}
+	script := `dir=$1; shift; mkdir -p "$dir" && cd "$dir" && touch ` + persistentAliveFile + ` || exit 125; ` +
+		`exec 3<&0; setsid "$@" <&3 3<&- & pid=$!; echo $pid > .pid; ` +
+		`wait $pid; status=$?; cd / && rm -rf "$dir"; exit $status`
+	args = append(args, container, "sh", "-c", script, "sh", dir)
+	return append(args, command...)
+}
+
+// containerGone reports whether docker exec itself failed with status because
+// the container does not exist or is not running. The daemon's error then
+// starts stderr, as the code never ran, so code exiting with such a status and
+// printing such a message is not mistaken for it.
+func containerGone(status int, stderr string) bool {
+	if status < 125 || status > 127 || !strings.HasPrefix(stderr, "Error response from daemon: ") {
+		return false
+	}
+	return strings.Contains(stderr, "No such container") || strings.Contains(stderr, "is not running")
+}
@@ -0,0 +1,106 @@
+package executor
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPersistentSupported(t *testing.T) {
+	tests := []struct {
+		name         string
+		dependencies []string
+		opts         []Option
+		want         bool
+	}{
+		{"plain call", nil, nil, true},
+		{"network", nil, []Option{WithNetwork("none"), WithTimeout(time.Second)}, true},
+		{"dependencies", []string{"requests"}, nil, false},
+		{"dependency file", nil, []Option{WithDependencyFile("requests\n")}, false},
+		{"mounts", nil, []Option{WithMounts([]Mount{{Source: "/data", Target: "/data"}})}, false},
+		{"workspace", nil, []Option{withWorkspaceDir("/tmp/workspaces/data")}, false},
+		{"profile", nil, []Option{WithProfile("data-science")}, false},
+		{"runtime version", nil, []Option{WithRuntimeVersion("3.12")}, false},
+		{"snapshot", nil, []Option{WithSnapshot("pandas")}, false},
+		{"read-only", nil, []Option{WithReadOnly()}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := persistentSupported(tt.dependencies, NewOptions(tt.opts...)); got != tt.want {
+				t.Errorf("persistentSupported() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPersistentExecArgs(t *testing.T) {
+	args := persistentExecArgs("mcp-executor-warm-python-abc", "/tmp/mcp-executor/123", map[string]string{"KEY": "value"}, []string{"python"})
+	want := []string{"exec", "-i", "-e", "KEY=value", "mcp-executor-warm-python-abc", "sh", "-c"}
+	if !reflect.DeepEqual(args[:len(want)], want) {
+		t.Errorf("persistentExecArgs() = %q, want it to start with %q", args, want)
+	}
+	if tail := args[len(want)+1:]; !reflect.DeepEqual(tail, []string{"sh", "/tmp/mcp-executor/123", "python"}) {
+		t.Errorf("persistentExecArgs() passes %q to the script, want the directory and command", tail)
+	}
+	script := args[len(want)]
+	for _, part := range []string{`setsid "$@"`, "echo $pid > .pid", `rm -rf "$dir"`, "exit $status"} {
+		if !strings.Contains(script, part) {
+			t.Errorf("Script %q should contain %q", script, part)
+		}
+	}
+}
+
+func TestContainerPool_WatchdogScript(t *testing.T) {
+	if script := NewContainerPool(90 * time.Second).watchdogScript(); !strings.Contains(script, "-lt 90 ]") {
+		t.Errorf("watchdogScript() = %q, want a 90 second idle timeout", script)
+	}
+	if pool := NewContainerPool(0); pool.idle != DefaultPersistentIdle {
+		t.Errorf("NewContainerPool(0) idle = %s, want %s", pool.idle, DefaultPersistentIdle)
+	}
+}
+
+func TestPoolKey(t *testing.T) {
+	config := NewPythonExecutor().config
+	client := WithClientID(context.Background(), "key-9f86d081")
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		config  ExecutorConfig
+		network string
+		same    bool
+	}{
+		{"same client", WithClientID(context.Background(), "key-9f86d081"), config, "", true},
+		{"other client", WithClientID(context.Background(), "key-60303ae2"), config, "", false},
+		{"anonymous client", context.Background(), config, "", false},
+		{"other network", client, config, "none", false},
+		{"other language", client, NewBashExecutor().config, "", false},
+	}
+	for _, tt := range tests {
+		if same := poolKey(tt.ctx, tt.config, tt.network) == poolKey(client, config, ""); same != tt.same {
+			t.Errorf("%s: same container = %t, want %t", tt.name, same, tt.same)
+		}
+	}
+}
+
+func TestContainerGone(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		stderr string
+		want   bool
+	}{
+		{"removed container", 125, "Error response from daemon: No such container: mcp-executor-warm-go-1\n", true},
+		{"stopped container", 126, "Error response from daemon: container 4f2a is not running\n", true},
+		{"output of the code", 1, "Traceback (most recent call last):\n", false},
+		{"code exiting with the status", 125, "is not running\n", false},
+		{"code printing the message", 1, "Error response from daemon: No such container: db\n", false},
+		{"message after the output of the code", 127, "starting\nError response from daemon: No such container: db\n", false},
+	}
+	for _, tt := range tests {
+		if got := containerGone(tt.status, tt.stderr); got != tt.want {
+			t.Errorf("%s: containerGone() = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
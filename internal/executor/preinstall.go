@@ -0,0 +1,75 @@
+// Package executor bakes frequently used packages into derived images of the
+// language images, so that Docker executions stop installing them on every
+// call.
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// PreinstalledImagePrefix starts the names of the language images with
+// preinstalled packages, followed by the language.
+const PreinstalledImagePrefix = "mcp-executor-"
+
+// BuiltImage is an image derived by BuildImages.
+type BuiltImage struct {
+	Tag     string
+	Subject string // What the image is for: the language or "profile <name>"
+	Built   bool   // False when the image was already present
+}
+
+// WithPreinstalledPackages runs executions in an image derived from the
+// executor's image with packages installed, built by BuildImages or before
+// the first execution using it. Executions selecting another image through a
+// runtime version, profile or browser image do not get the packages.
+func WithPreinstalledPackages(packages []string) DockerOption {
+	return func(c *ExecutorConfig) {
+		c.Preinstalled = packages
+	}
+}
+
+// preinstalledImage returns the image of executions running in the
+// executor's image: the derived image with the preinstalled packages, built
+// if missing, or the image itself without any.
+func (d *DockerExecutor) preinstalledImage(ctx context.Context) (string, bool, error) {
+	if len(d.config.Preinstalled) == 0 {
+		return d.config.Image, false, nil
+	}
+	packages, err := ValidateDependencies(d.config.ExecutorName, d.config.Preinstalled)
+	if err != nil {
+		return "", false, fmt.Errorf("preinstalled %s packages: %v", d.config.ExecutorName, err)
+	}
+	return d.buildImage(ctx, PreinstalledImagePrefix+d.config.ExecutorName, d.config.Image, packages, d.config.ExecutorName)
+}
+
+// BuildImages builds the images derived by the executor that are missing: the
+// image with its preinstalled packages and those of its environments with
+// packages, sorted by name.
+func (d *DockerExecutor) BuildImages(ctx context.Context) ([]BuiltImage, error) {
+	var images []BuiltImage
+	if len(d.config.Preinstalled) > 0 {
+		tag, built, err := d.preinstalledImage(ctx)
+		if err != nil {
+			return images, err
+		}
+		images = append(images, BuiltImage{Tag: tag, Subject: d.config.ExecutorName, Built: built})
+	}
+
+	names := make([]string, 0, len(d.config.Environments))
+	for name, environment := range d.config.Environments {
+		if len(environment.Packages) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		tag, built, err := d.buildEnvironment(ctx, d.config.Environments[name])
+		if err != nil {
+			return images, err
+		}
+		images = append(images, BuiltImage{Tag: tag, Subject: "profile " + name, Built: built})
+	}
+	return images, nil
+}
@@ -0,0 +1,34 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDockerExecutor_PreinstalledImage(t *testing.T) {
+	image, built, err := NewPythonExecutor(WithImage("python:3.13-slim")).preinstalledImage(context.Background())
+	if image != "python:3.13-slim" || built || err != nil {
+		t.Errorf("preinstalledImage() without packages = %q, %v, %v, want the image itself", image, built, err)
+	}
+
+	executor := NewPythonExecutor(WithPreinstalledPackages([]string{"pandas", "numpy; rm -rf /"}))
+	if _, _, err := executor.preinstalledImage(context.Background()); err == nil || !strings.Contains(err.Error(), "preinstalled python packages") {
+		t.Errorf("preinstalledImage() with an invalid package error = %v", err)
+	}
+	if _, err := executor.Execute(context.Background(), "print(1)", nil, nil); err == nil || !strings.Contains(err.Error(), "preinstalled python packages") {
+		t.Errorf("Execute() with an invalid preinstalled package error = %v", err)
+	}
+}
+
+func TestDockerExecutor_BuildImages(t *testing.T) {
+	images, err := NewGoExecutor(WithEnvironments([]Environment{{Name: "plain"}})).BuildImages(context.Background())
+	if len(images) != 0 || err != nil {
+		t.Errorf("BuildImages() without packages = %v, %v, want no images", images, err)
+	}
+
+	executor := NewBashExecutor(WithEnvironments([]Environment{{Name: "ops", Packages: []string{"curl", "bad name"}}}))
+	if _, err := executor.BuildImages(context.Background()); err == nil || !strings.Contains(err.Error(), "profile ops") {
+		t.Errorf("BuildImages() with an invalid package error = %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+// Package executor runs host executions at reduced CPU and IO priority, so
+// that heavy scripts do not make the interactive sessions of the host
+// unusable.
+package executor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// IO priorities of host executions.
+const (
+	IOPriorityNormal = "normal" // The IO priority of the server
+	IOPriorityLow    = "low"    // The lowest best-effort priority
+	IOPriorityIdle   = "idle"   // IO only when no other process needs the disk
+)
+
+// Default priority of host executions.
+const (
+	DefaultNice       = 10
+	DefaultIOPriority = IOPriorityLow
+)
+
+// Priority is the scheduling priority of host executions.
+type Priority struct {
+	Nice       int    // Nice value from 1 (slightly lower) to 19 (lowest); 0 keeps the server's
+	IOPriority string // IOPriorityNormal, IOPriorityLow or IOPriorityIdle; empty is normal
+}
+
+// ValidatePriority checks a nice value and an IO priority.
+func ValidatePriority(priority Priority) error {
+	if priority.Nice < 0 || priority.Nice > 19 {
+		return fmt.Errorf("nice value %d out of range (0 to 19)", priority.Nice)
+	}
+	switch priority.IOPriority {
+	case "", IOPriorityNormal, IOPriorityLow, IOPriorityIdle:
+		return nil
+	}
+	return fmt.Errorf("unknown IO priority %q (expected normal, low or idle)", priority.IOPriority)
+}
+
+type priorityKey struct{}
+
+// PriorityExecutor runs the host processes of its executions at a configured
+// priority.
+type PriorityExecutor struct {
+	executor Executor
+	priority Priority
+}
+
+// NewPriorityExecutor wraps exec so that its host processes run at priority,
+// or returns exec unchanged when priority keeps the server's. Docker executions
+// are not affected.
+func NewPriorityExecutor(exec Executor, priority Priority) Executor {
+	if priority.Nice == 0 && (priority.IOPriority == "" || priority.IOPriority == IOPriorityNormal) {
+		return exec
+	}
+	return &PriorityExecutor{executor: exec, priority: priority}
+}
+
+func (p *PriorityExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	return p.executor.Execute(context.WithValue(ctx, priorityKey{}, p.priority), code, dependencies, envVars, opts...)
+}
+
+// applyPriority lowers the priority of the process group pgid to the one of
+// ctx, if any. Failures are logged: the execution still runs.
+func applyPriority(ctx context.Context, pgid int) {
+	priority, ok := ctx.Value(priorityKey{}).(Priority)
+	if !ok {
+		return
+	}
+	if priority.Nice != 0 {
+		if err := setNice(pgid, priority.Nice); err != nil {
+			logger.DebugContext(ctx, "Failed to set nice value %d: %v", priority.Nice, err)
+		}
+	}
+	if err := setIOPriority(pgid, priority.IOPriority); err != nil {
+		logger.DebugContext(ctx, "Failed to set IO priority %s: %v", priority.IOPriority, err)
+	}
+}
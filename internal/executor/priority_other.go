@@ -0,0 +1,10 @@
+//go:build !unix
+
+// Package executor leaves the nice value of host executions unchanged on
+// systems without setpriority.
+package executor
+
+// setNice does nothing: only Unix systems have nice values.
+func setNice(pgid, nice int) error {
+	return nil
+}
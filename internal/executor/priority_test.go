@@ -0,0 +1,54 @@
+package executor
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestValidatePriority(t *testing.T) {
+	tests := []struct {
+		name     string
+		priority Priority
+		wantErr  bool
+	}{
+		{"defaults", Priority{Nice: DefaultNice, IOPriority: DefaultIOPriority}, false},
+		{"unchanged", Priority{}, false},
+		{"idle", Priority{Nice: 19, IOPriority: IOPriorityIdle}, false},
+		{"negative nice", Priority{Nice: -5}, true},
+		{"nice too high", Priority{Nice: 20}, true},
+		{"unknown IO priority", Priority{IOPriority: "realtime"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidatePriority(tt.priority); (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePriority() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPriorityExecutor(t *testing.T) {
+	if _, err := exec.LookPath("nice"); err != nil {
+		t.Skip("nice not installed")
+	}
+	exec := NewPriorityExecutor(NewSubprocessBashExecutor(), Priority{Nice: 7, IOPriority: IOPriorityIdle})
+	output, err := exec.Execute(context.Background(), "nice; (nice); command -v ionice >/dev/null && ionice || echo idle", nil, nil)
+	if err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 3 || lines[0] != "7" || lines[1] != "7" {
+		t.Errorf("Execute() = %q, want nice value 7 for the script and its children", output)
+	}
+	if runtime.GOOS == "linux" && len(lines) == 3 && lines[2] != "idle" {
+		t.Errorf("IO priority = %q, want idle", lines[2])
+	}
+
+	bash := NewSubprocessBashExecutor()
+	if NewPriorityExecutor(bash, Priority{IOPriority: IOPriorityNormal}) != bash {
+		t.Error("NewPriorityExecutor should return the executor unchanged at the server priority")
+	}
+}
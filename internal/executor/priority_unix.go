@@ -0,0 +1,11 @@
+//go:build unix
+
+// Package executor sets the nice value of host executions with setpriority.
+package executor
+
+import "syscall"
+
+// setNice sets the nice value of the process group pgid to nice.
+func setNice(pgid, nice int) error {
+	return syscall.Setpriority(syscall.PRIO_PGRP, pgid, nice)
+}
@@ -0,0 +1,63 @@
+// Package executor runs host processes in a session of their own and kills
+// their whole process group when they exit or are cancelled, so that children
+// they started in the background do not outlive the execution.
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// runProcessGroup runs cmd like CombinedOutput as the leader of a new session.
+// Cancelling cmd kills the process group instead of cmd alone, and members of
+// the group still running when cmd exits are killed as well. Descendants that
+// left the group keep the output open for at most waitDelay. The group runs at
+// the priority of ctx, if any.
+func runProcessGroup(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	if cmd.Stdout != nil || cmd.Stderr != nil {
+		return nil, errors.New("exec: Stdout or Stderr already set")
+	}
+	newSession(cmd)
+	if cmd.Cancel != nil {
+		// Set by CommandContext to kill cmd alone
+		cmd.Cancel = func() error {
+			return killProcessGroup(cmd.Process)
+		}
+	}
+
+	// A pipe of our own lets Wait return when cmd exits, before the group
+	// members sharing the output are killed
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	cmd.Stdout, cmd.Stderr = writer, writer
+	err = cmd.Start()
+	writer.Close()
+	if err != nil {
+		return nil, err
+	}
+	applyPriority(ctx, cmd.Process.Pid)
+
+	var output bytes.Buffer
+	copied := make(chan struct{})
+	go func() {
+		_, _ = output.ReadFrom(reader)
+		close(copied)
+	}()
+
+	err = cmd.Wait()
+	_ = killProcessGroup(cmd.Process)
+	select {
+	case <-copied:
+	case <-time.After(waitDelay):
+		reader.Close()
+		<-copied
+	}
+	return output.Bytes(), err
+}
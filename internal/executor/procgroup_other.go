@@ -0,0 +1,18 @@
+//go:build !unix
+
+// Package executor runs host processes on systems without Unix sessions and
+// process groups, where only the process itself can be killed.
+package executor
+
+import (
+	"os"
+	"os/exec"
+)
+
+// newSession does nothing: only Unix systems have sessions.
+func newSession(cmd *exec.Cmd) {}
+
+// killProcessGroup kills process alone, without the children it started.
+func killProcessGroup(process *os.Process) error {
+	return process.Kill()
+}
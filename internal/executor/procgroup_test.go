@@ -0,0 +1,70 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// processGone reports whether pid has exited, waiting up to a second. Killed
+// orphans may remain zombies until their new parent reaps them.
+func processGone(pid int) bool {
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+		if err != nil {
+			return true
+		}
+		if fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:])); len(fields) > 0 && fields[0] == "Z" {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRunProcessGroup(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("requires /proc")
+	}
+	tests := []struct {
+		name    string
+		script  string
+		timeout time.Duration
+		wantErr bool
+	}{
+		{"background child after exit", "sleep 30 & echo $!", 0, false},
+		{"background child on timeout", "sleep 30 & echo $!; sleep 30", 200 * time.Millisecond, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			cmd := exec.CommandContext(ctx, "sh", "-c", tt.script)
+			cmd.WaitDelay = waitDelay
+
+			startedAt := time.Now()
+			out, err := runProcessGroup(ctx, cmd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runProcessGroup() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if elapsed := time.Since(startedAt); elapsed > 5*time.Second {
+				t.Errorf("runProcessGroup() took %s, want it not to wait for the background child", elapsed)
+			}
+			pid, err := strconv.Atoi(strings.TrimSpace(string(out)))
+			if err != nil {
+				t.Fatalf("Output = %q, want the PID of the background child", out)
+			}
+			if !processGone(pid) {
+				t.Errorf("Background child %d is still running", pid)
+			}
+		})
+	}
+}
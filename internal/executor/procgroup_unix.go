@@ -0,0 +1,30 @@
+//go:build unix
+
+// Package executor starts host processes in a session of their own and kills
+// their process group with the signals of Unix systems.
+package executor
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// newSession makes cmd the leader of a new session and process group.
+func newSession(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setsid = true
+}
+
+// killProcessGroup kills the process group led by process, if it still has
+// members.
+func killProcessGroup(process *os.Process) error {
+	err := syscall.Kill(-process.Pid, syscall.SIGKILL)
+	if errors.Is(err, syscall.ESRCH) {
+		return os.ErrProcessDone
+	}
+	return err
+}
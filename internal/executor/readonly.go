@@ -0,0 +1,123 @@
+// Package executor runs read-only executions for safe introspection: Docker
+// containers get a read-only root filesystem and mounts, and subprocess Bash
+// and Python run with restricted permissions.
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// ReadOnlyExecutor runs every execution with WithReadOnly and rejects
+// dependencies, which cannot be installed without write access, and snapshots.
+type ReadOnlyExecutor struct {
+	executor Executor
+}
+
+// NewReadOnlyExecutor wraps exec, which must honor Options.ReadOnly: the Docker
+// executors and the subprocess Bash and Python executors do.
+func NewReadOnlyExecutor(exec Executor) Executor {
+	return &ReadOnlyExecutor{executor: exec}
+}
+
+func (r *ReadOnlyExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	options := NewOptions(opts...)
+	if len(dependencies) > 0 || options.DependencyFile != "" {
+		return "", NewExecutionError(ErrorPolicyViolation, "dependencies cannot be installed by read-only executions; use the tool without -readonly")
+	}
+	if options.Snapshot != "" {
+		return "", NewExecutionError(ErrorPolicyViolation, "read-only executions cannot save a snapshot; use the tool without -readonly")
+	}
+	return r.executor.Execute(ctx, code, dependencies, envVars, append(opts, WithReadOnly())...)
+}
+
+// readOnlyDockerArgs returns the docker run arguments of read-only executions:
+// a read-only root filesystem, with the caches of the language tools in the
+// tmpfs mounted on /tmp, which also holds the working directory.
+func readOnlyDockerArgs() []string {
+	return []string{"--read-only", "-e", "XDG_CACHE_HOME=/tmp/.cache"}
+}
+
+// readOnlyMounts returns mounts with every mount made read-only.
+func readOnlyMounts(mounts []Mount) []Mount {
+	readOnly := make([]Mount, len(mounts))
+	for i, mount := range mounts {
+		mount.ReadOnly = true
+		readOnly[i] = mount
+	}
+	return readOnly
+}
+
+// readOnlyCommands are the commands available to read-only subprocess Bash
+// scripts. Commands that can write files through their arguments, such as
+// sort -o or find -delete, are left out.
+var readOnlyCommands = []string{
+	"cat", "cut", "date", "df", "diff", "du", "grep", "head", "hostname", "id", "ls",
+	"md5sum", "ps", "readlink", "realpath", "sha256sum", "stat", "tail", "tr", "uname",
+	"uptime", "wc", "whoami",
+}
+
+// readOnlyBash returns the command running a read-only subprocess Bash script
+// in a restricted shell, in which output redirection, cd, changing PATH and
+// commands containing slashes are refused, and the variables appended last to
+// its environment. Only builtins and the readOnlyCommands, linked into a
+// temporary PATH directory, run; per-call variables cannot widen PATH or
+// source startup files. The returned function removes the directory.
+func readOnlyBash(ctx context.Context, bash string) (*exec.Cmd, []string, func(), error) {
+	dir, err := os.MkdirTemp("", "mcp-executor-readonly-*")
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create read-only PATH: %v", err)
+	}
+	if err := grantRunAs(ctx, dir); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, nil, nil, err
+	}
+	for _, name := range readOnlyCommands {
+		if path, err := exec.LookPath(name); err == nil {
+			_ = os.Symlink(path, filepath.Join(dir, name))
+		}
+	}
+	cmd := exec.CommandContext(ctx, bash, "--restricted", "--noprofile", "--norc")
+	env := []string{"PATH=" + dir, "BASH_ENV=", "ENV="}
+	return cmd, env, func() { _ = os.RemoveAll(dir) }, nil
+}
+
+// readOnlyPython is the program running read-only Python code from stdin. An
+// audit hook, which cannot be removed, refuses opening files for writing,
+// changing the filesystem, starting processes and loading native libraries
+// through ctypes.
+const readOnlyPython = `import os, sys
+_WRITE_FLAGS = os.O_WRONLY | os.O_RDWR | os.O_APPEND | os.O_CREAT | os.O_TRUNC
+_REFUSED = {
+    "os.chmod", "os.chown", "os.chflags", "os.lchflags", "os.link", "os.mkdir", "os.mkfifo",
+    "os.mknod", "os.remove", "os.rename", "os.rmdir", "os.symlink", "os.truncate", "os.utime",
+    "shutil.rmtree", "os.exec", "os.fork", "os.forkpty", "os.kill", "os.killpg",
+    "os.posix_spawn", "os.spawn", "os.system", "subprocess.Popen", "ctypes.dlopen",
+}
+def _read_only(event, args):
+    if event == "open":
+        mode, flags = args[1], args[2]
+        if (isinstance(mode, str) and any(c in mode for c in "wax+")) or (isinstance(flags, int) and flags & _WRITE_FLAGS):
+            raise PermissionError("read-only execution: cannot open %r for writing" % (args[0],))
+    elif event in _REFUSED:
+        raise PermissionError("read-only execution: %s is not allowed" % event)
+_code = sys.stdin.read()
+sys.addaudithook(_read_only)
+exec(compile(_code, "<stdin>", "exec"), {"__name__": "__main__", "__builtins__": __builtins__})
+`
+
+// readOnlyCommand returns the command running read-only code of language with
+// binary, the variables appended last to its environment and the function
+// cleaning up after it, or an error for languages without a read-only mode.
+func readOnlyCommand(ctx context.Context, language, binary string) (*exec.Cmd, []string, func(), error) {
+	switch language {
+	case "bash":
+		return readOnlyBash(ctx, binary)
+	case "python":
+		return exec.CommandContext(ctx, binary, "-B", "-c", readOnlyPython), nil, func() {}, nil
+	}
+	return nil, nil, nil, NewExecutionError(ErrorPolicyViolation, "read-only executions are not supported for %s in subprocess mode", language)
+}
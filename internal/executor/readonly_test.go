@@ -0,0 +1,94 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadOnlyExecutor_RejectsDependencies(t *testing.T) {
+	recorder := &optionsRecorder{}
+	exec := NewReadOnlyExecutor(recorder)
+
+	if _, err := exec.Execute(context.Background(), "code", []string{"requests"}, nil); err == nil {
+		t.Error("Execute() should reject dependencies")
+	}
+	if _, err := exec.Execute(context.Background(), "code", nil, nil, WithDependencyFile("requests\n")); err == nil {
+		t.Error("Execute() should reject dependency files")
+	}
+	if _, err := exec.Execute(context.Background(), "code", nil, nil, WithSnapshot("pandas")); err == nil {
+		t.Error("Execute() should reject snapshots")
+	}
+	if _, err := exec.Execute(context.Background(), "code", nil, nil); err != nil || !recorder.options.ReadOnly {
+		t.Errorf("Execute() = %v, ReadOnly = %t, want a read-only execution", err, recorder.options.ReadOnly)
+	}
+}
+
+func TestSubprocessExecutor_ReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.txt")
+	if err := os.WriteFile(existing, []byte("content\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	created := filepath.Join(dir, "created.txt")
+
+	tests := []struct {
+		name     string
+		exec     Executor
+		code     string
+		wantErr  bool
+		wantText string
+	}{
+		{"bash reads", NewSubprocessBashExecutor(), "cat " + existing, false, "content"},
+		{"bash builtins", NewSubprocessBashExecutor(), "echo hello", false, "hello"},
+		{"bash redirection", NewSubprocessBashExecutor(), "echo changed > " + created, true, ""},
+		{"bash absolute command", NewSubprocessBashExecutor(), "/bin/rm " + existing, true, ""},
+		{"bash unlisted command", NewSubprocessBashExecutor(), "rm " + existing, true, ""},
+		{"bash PATH", NewSubprocessBashExecutor(), "PATH=/bin:/usr/bin; rm " + existing, true, ""},
+		{"python reads", NewSubprocessPythonExecutor(), "print(open(" + quote(existing) + ").read())", false, "content"},
+		{"python writes", NewSubprocessPythonExecutor(), "open(" + quote(created) + ", 'w').write('changed')", true, "read-only execution"},
+		{"python removes", NewSubprocessPythonExecutor(), "import os; os.remove(" + quote(existing) + ")", true, "read-only execution"},
+		{"python subprocess", NewSubprocessPythonExecutor(), "import subprocess; subprocess.run(['rm', " + quote(existing) + "])", true, "read-only execution"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := tt.exec.Execute(context.Background(), tt.code, nil, nil, WithReadOnly())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() = %q, %v, wantErr %t", output, err, tt.wantErr)
+			}
+			text := output
+			if err != nil {
+				text = err.Error()
+			}
+			if !strings.Contains(text, tt.wantText) {
+				t.Errorf("Execute() = %q, want it to contain %q", text, tt.wantText)
+			}
+		})
+	}
+
+	if _, err := os.Stat(existing); err != nil {
+		t.Errorf("The existing file should be kept: %v", err)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Error("No file should be created")
+	}
+}
+
+func TestReadOnlyMounts(t *testing.T) {
+	mounts := []Mount{{Source: "/data", Target: "/data"}, {Source: "/srv", Target: "/srv", ReadOnly: true}}
+	for _, mount := range readOnlyMounts(mounts) {
+		if !mount.ReadOnly {
+			t.Errorf("Mount %s should be read-only", mount.Target)
+		}
+	}
+	if mounts[0].ReadOnly {
+		t.Error("readOnlyMounts() should not modify its argument")
+	}
+}
+
+// quote returns s as a Python string literal.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
@@ -0,0 +1,64 @@
+// Package executor points dependency installation at package mirrors, for
+// servers in air-gapped or corporate networks without access to the public
+// registries.
+package executor
+
+import "strings"
+
+// Registries selects the package mirrors used by installs. Empty fields keep
+// the public registries.
+type Registries struct {
+	PyPIIndexURL       string   // Replaces https://pypi.org/simple for pip and uv
+	PyPIExtraIndexURLs []string // Searched in addition to the index
+	NPMRegistry        string   // Replaces https://registry.npmjs.org
+	GoProxy            string   // GOPROXY list, e.g. "https://goproxy.internal,direct"
+}
+
+// Env returns the variables pointing pip, uv, npm and go at the registries.
+// They are set in the execution, so code installing packages itself uses the
+// mirrors too.
+func (r Registries) Env() map[string]string {
+	env := make(map[string]string)
+	if r.PyPIIndexURL != "" {
+		env["PIP_INDEX_URL"] = r.PyPIIndexURL
+		env["UV_INDEX_URL"] = r.PyPIIndexURL
+	}
+	if len(r.PyPIExtraIndexURLs) > 0 {
+		extra := strings.Join(r.PyPIExtraIndexURLs, " ")
+		env["PIP_EXTRA_INDEX_URL"] = extra
+		env["UV_EXTRA_INDEX_URL"] = extra
+	}
+	if r.NPMRegistry != "" {
+		env["NPM_CONFIG_REGISTRY"] = r.NPMRegistry
+	}
+	if r.GoProxy != "" {
+		env["GOPROXY"] = r.GoProxy
+	}
+	return env
+}
+
+// ubuntuArchives are the archive URLs of the apt sources of Ubuntu images,
+// which an APT mirror replaces.
+var ubuntuArchives = []string{
+	"http://archive.ubuntu.com/ubuntu",
+	"http://security.ubuntu.com/ubuntu",
+}
+
+// WithAPTMirror installs apt packages from mirror, an Ubuntu archive mirror, by
+// rewriting the apt sources of the image before apt-get update. The mirror
+// must not contain shell or sed metacharacters; the configuration rejects them.
+func WithAPTMirror(mirror string) DockerOption {
+	return func(c *ExecutorConfig) {
+		if mirror == "" || c.ExecutorName != "bash" {
+			return
+		}
+		var script []string
+		for _, archive := range ubuntuArchives {
+			script = append(script, "s|"+archive+"|"+mirror+"|g")
+		}
+		// Images have sources.list, sources.list.d/ubuntu.sources or both; the
+		// error for the missing one is discarded
+		rewrite := []string{"sed", "-i", shellQuote(strings.Join(script, "; ")), "/etc/apt/sources.list", "/etc/apt/sources.list.d/*", "2>/dev/null;"}
+		c.InstallCmd = append(rewrite, c.InstallCmd...)
+	}
+}
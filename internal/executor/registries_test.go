@@ -0,0 +1,45 @@
+package executor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRegistries_Env(t *testing.T) {
+	registries := Registries{
+		PyPIIndexURL:       "https://pypi.internal/simple",
+		PyPIExtraIndexURLs: []string{"https://a.internal/simple", "https://b.internal/simple"},
+		NPMRegistry:        "https://npm.internal",
+		GoProxy:            "https://goproxy.internal,direct",
+	}
+	want := map[string]string{
+		"PIP_INDEX_URL":       "https://pypi.internal/simple",
+		"UV_INDEX_URL":        "https://pypi.internal/simple",
+		"PIP_EXTRA_INDEX_URL": "https://a.internal/simple https://b.internal/simple",
+		"UV_EXTRA_INDEX_URL":  "https://a.internal/simple https://b.internal/simple",
+		"NPM_CONFIG_REGISTRY": "https://npm.internal",
+		"GOPROXY":             "https://goproxy.internal,direct",
+	}
+	if got := registries.Env(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Env() = %v, want %v", got, want)
+	}
+	if got := (Registries{}).Env(); len(got) != 0 {
+		t.Errorf("Env() without registries = %v, want none", got)
+	}
+}
+
+func TestWithAPTMirror(t *testing.T) {
+	bash := NewBashExecutor(WithAPTMirror("http://mirror.internal/ubuntu"))
+	got := strings.Join(bash.installArgs(true, false), " ")
+	want := `sed -i 's|http://archive.ubuntu.com/ubuntu|http://mirror.internal/ubuntu|g; s|http://security.ubuntu.com/ubuntu|http://mirror.internal/ubuntu|g' ` +
+		`/etc/apt/sources.list /etc/apt/sources.list.d/* 2>/dev/null; apt-get update -qq && DEBIAN_FRONTEND=noninteractive apt-get install -y -qq --no-install-recommends -- "$@"`
+	if got != want {
+		t.Errorf("installArgs() = %s, want %s", got, want)
+	}
+
+	python := NewPythonExecutor(WithAPTMirror("http://mirror.internal/ubuntu"))
+	if got := strings.Join(python.installArgs(true, false), " "); strings.Contains(got, "sed") {
+		t.Errorf("WithAPTMirror() should only change the bash executor, got %s", got)
+	}
+}
@@ -0,0 +1,143 @@
+// Package executor runs host executions as a configured unprivileged user, so
+// that a server running as root hands executed code a locked-down account.
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"strings"
+)
+
+// RunAs is the host user and group running subprocess and Nix executions.
+type RunAs struct {
+	User   string
+	Home   string
+	UID    uint32
+	GID    uint32
+	Groups []uint32 // Supplementary groups of the user
+}
+
+// LookupRunAs resolves spec, "user" or "user:group" as names or numeric IDs,
+// to the account running executions. The primary group of the user is used
+// when no group is given. Switching to another user requires the server to
+// run as root.
+func LookupRunAs(spec string) (*RunAs, error) {
+	userName, groupName, hasGroup := strings.Cut(spec, ":")
+	account, err := user.Lookup(userName)
+	if err != nil {
+		if account, err = user.LookupId(userName); err != nil {
+			return nil, fmt.Errorf("unknown user %q", userName)
+		}
+	}
+	uid, err := strconv.ParseUint(account.Uid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("user %s has no numeric ID: %s", account.Username, account.Uid)
+	}
+	gidText := account.Gid
+	if hasGroup {
+		group, err := user.LookupGroup(groupName)
+		if err != nil {
+			if group, err = user.LookupGroupId(groupName); err != nil {
+				return nil, fmt.Errorf("unknown group %q", groupName)
+			}
+		}
+		gidText = group.Gid
+	}
+	gid, err := strconv.ParseUint(gidText, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("group of %s has no numeric ID: %s", account.Username, gidText)
+	}
+
+	runAs := &RunAs{User: account.Username, Home: account.HomeDir, UID: uint32(uid), GID: uint32(gid)}
+	if ids, err := account.GroupIds(); err == nil {
+		for _, id := range ids {
+			if group, err := strconv.ParseUint(id, 10, 32); err == nil {
+				runAs.Groups = append(runAs.Groups, uint32(group))
+			}
+		}
+	}
+	if euid := os.Geteuid(); euid != 0 && uint32(euid) != runAs.UID {
+		return nil, fmt.Errorf("running executions as %s requires the server to run as root", runAs.User)
+	}
+	return runAs, nil
+}
+
+func (r *RunAs) String() string {
+	return fmt.Sprintf("%s (uid %d, gid %d)", r.User, r.UID, r.GID)
+}
+
+type runAsKey struct{}
+
+// RunAsExecutor runs the host processes of its executions as a configured
+// user, handing it the temporary directories and workspaces they use.
+type RunAsExecutor struct {
+	executor Executor
+	runAs    *RunAs
+}
+
+// NewRunAsExecutor wraps exec so that its executions run as runAs, or returns
+// exec unchanged when runAs is nil. Docker executions are not affected.
+func NewRunAsExecutor(exec Executor, runAs *RunAs) Executor {
+	if runAs == nil {
+		return exec
+	}
+	return &RunAsExecutor{executor: exec, runAs: runAs}
+}
+
+func (r *RunAsExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	return r.executor.Execute(context.WithValue(ctx, runAsKey{}, r.runAs), code, dependencies, envVars, opts...)
+}
+
+// runAsFrom returns the user running the executions of ctx, or nil.
+func runAsFrom(ctx context.Context) *RunAs {
+	runAs, _ := ctx.Value(runAsKey{}).(*RunAs)
+	return runAs
+}
+
+// applyRunAs makes cmd run as the user of ctx, with its USER and LOGNAME
+// taking precedence over the other variables, and its HOME unless the
+// execution set another HOME than the server's.
+func applyRunAs(ctx context.Context, cmd *exec.Cmd) {
+	runAs := runAsFrom(ctx)
+	if runAs == nil {
+		return
+	}
+	setCredential(cmd, runAs)
+	if cmd.Env == nil {
+		cmd.Env = hostEnv(ctx)
+	}
+	if envValue(cmd.Env, "HOME") == os.Getenv("HOME") {
+		cmd.Env = append(cmd.Env, "HOME="+runAs.Home)
+	}
+	cmd.Env = append(cmd.Env, "USER="+runAs.User, "LOGNAME="+runAs.User)
+}
+
+// envValue returns the value of key in env, where later entries win.
+func envValue(env []string, key string) string {
+	value := ""
+	for _, entry := range env {
+		if k, v, ok := strings.Cut(entry, "="); ok && k == key {
+			value = v
+		}
+	}
+	return value
+}
+
+// grantRunAs hands paths created by the server for an execution to the user
+// of ctx, if any.
+func grantRunAs(ctx context.Context, paths ...string) error {
+	runAs := runAsFrom(ctx)
+	if runAs == nil {
+		return nil
+	}
+	for _, path := range paths {
+		if err := os.Lchown(path, int(runAs.UID), int(runAs.GID)); err != nil {
+			return fmt.Errorf("failed to hand %s to user %s: %v", path, runAs.User, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+// Package executor cannot switch the user of host processes on systems without
+// Unix credentials.
+package executor
+
+import "os/exec"
+
+// setCredential does nothing: LookupRunAs refuses every account on systems
+// without numeric user IDs and root, so no execution runs as another user.
+func setCredential(cmd *exec.Cmd, runAs *RunAs) {}
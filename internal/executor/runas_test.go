@@ -0,0 +1,71 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLookupRunAs(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantUID uint32
+		wantGID uint32
+		wantErr bool
+	}{
+		{"root", 0, 0, false},
+		{"0", 0, 0, false},
+		{"root:0", 0, 0, false},
+		{"no-such-user-mcp", 0, 0, true},
+		{"root:no-such-group-mcp", 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			if os.Geteuid() != 0 {
+				t.Skip("switching users requires root")
+			}
+			runAs, err := LookupRunAs(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LookupRunAs(%q) error = %v, wantErr %t", tt.spec, err, tt.wantErr)
+			}
+			if err == nil && (runAs.UID != tt.wantUID || runAs.GID != tt.wantGID || runAs.User != "root") {
+				t.Errorf("LookupRunAs(%q) = %+v", tt.spec, runAs)
+			}
+		})
+	}
+}
+
+func TestRunAsExecutor(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("switching users requires root")
+	}
+	runAs, err := LookupRunAs("nobody")
+	if err != nil {
+		t.Skipf("no nobody user: %v", err)
+	}
+	// Below the system temporary directory, which the user can traverse
+	root, err := os.MkdirTemp("", "mcp-executor-runas-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(root) })
+	exec := NewRunAsExecutor(NewWorkspaceExecutor(NewSandboxEnvExecutor(NewSubprocessBashExecutor(), "subprocess"), root), runAs)
+
+	output, err := exec.Execute(context.Background(), `id -u; echo "$USER"; touch "$MCP_WORKSPACE/file" && echo written`, nil, nil)
+	if err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+	if want := "65534\nnobody\nwritten\n"; runAs.UID == 65534 && output != want {
+		t.Errorf("Execute() = %q, want %q", output, want)
+	}
+
+	output, err = exec.Execute(WithSessionID(context.Background(), "session"), `touch "$MCP_WORKSPACE/shared" && echo shared`, nil, nil, WithWorkspace("data"))
+	if err != nil || strings.TrimSpace(output) != "shared" {
+		t.Errorf("Execute() in a named workspace = %q, %v, want it writable by the user", output, err)
+	}
+
+	if NewRunAsExecutor(exec, nil) != exec {
+		t.Error("NewRunAsExecutor() without a user should return the executor unchanged")
+	}
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+// Package executor switches the user of host processes with the credentials of
+// Unix systems.
+package executor
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setCredential makes cmd run as the user and groups of runAs.
+func setCredential(cmd *exec.Cmd, runAs *RunAs) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Credential = &syscall.Credential{Uid: runAs.UID, Gid: runAs.GID, Groups: runAs.Groups}
+}
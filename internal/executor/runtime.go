@@ -0,0 +1,189 @@
+// Package executor discovers host toolchains installed by version managers
+// (pyenv, asdf, mise, nvm and Go's ~/sdk downloads) for subprocess executions
+// that request a specific runtime version.
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// installDir is a directory holding one subdirectory per installed version.
+type installDir struct {
+	path   string // Directory listing the installed versions
+	bin    string // Path of the bin directory inside a version directory
+	prefix string // Prefix of the version directory names, e.g. "v" for nvm
+}
+
+// runtimeInstallDirs returns the version manager directories searched for language.
+func runtimeInstallDirs(language string) []installDir {
+	home, _ := os.UserHomeDir()
+	pyenv := envOr("PYENV_ROOT", filepath.Join(home, ".pyenv"))
+	asdf := filepath.Join(envOr("ASDF_DATA_DIR", filepath.Join(home, ".asdf")), "installs")
+	mise := filepath.Join(envOr("MISE_DATA_DIR", filepath.Join(home, ".local", "share", "mise")), "installs")
+
+	switch language {
+	case "python":
+		return []installDir{
+			{path: filepath.Join(pyenv, "versions"), bin: "bin"},
+			{path: filepath.Join(asdf, "python"), bin: "bin"},
+			{path: filepath.Join(mise, "python"), bin: "bin"},
+		}
+	case "typescript":
+		return []installDir{
+			{path: filepath.Join(asdf, "nodejs"), bin: "bin"},
+			{path: filepath.Join(mise, "node"), bin: "bin"},
+			{path: filepath.Join(envOr("NVM_DIR", filepath.Join(home, ".nvm")), "versions", "node"), bin: "bin", prefix: "v"},
+		}
+	case "go":
+		return []installDir{
+			{path: filepath.Join(asdf, "golang"), bin: filepath.Join("go", "bin")},
+			{path: filepath.Join(mise, "go"), bin: "bin"},
+			{path: filepath.Join(home, "sdk"), bin: "bin", prefix: "go"},
+		}
+	default:
+		return nil
+	}
+}
+
+// FindRuntime returns the bin directory of the newest installed toolchain of
+// language matching version, e.g. "3.12" matches 3.12.4 but not 3.1.
+func FindRuntime(language, version string) (string, error) {
+	dirs := runtimeInstallDirs(language)
+	if len(dirs) == 0 {
+		return "", NewExecutionError(ErrorPolicyViolation, "runtime_version is not supported for %s in subprocess mode", language)
+	}
+
+	var found []string // Installed versions, for the error message
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir.path)
+		if err != nil {
+			continue
+		}
+		best := ""
+		for _, entry := range entries {
+			name, ok := strings.CutPrefix(entry.Name(), dir.prefix)
+			if !entry.IsDir() || !ok {
+				continue
+			}
+			found = append(found, name)
+			if (name == version || strings.HasPrefix(name, version+".")) && (best == "" || compareVersions(name, best) > 0) {
+				best = name
+			}
+		}
+		if best != "" {
+			bin := filepath.Join(dir.path, dir.prefix+best, dir.bin)
+			if info, err := os.Stat(bin); err == nil && info.IsDir() {
+				return bin, nil
+			}
+		}
+	}
+
+	if len(found) == 0 {
+		return "", NewExecutionError(ErrorPolicyViolation, "runtime_version %q is not installed for %s: no pyenv, asdf, mise, nvm or ~/sdk toolchains found", version, language)
+	}
+	sort.Slice(found, func(i, j int) bool { return compareVersions(found[i], found[j]) < 0 })
+	return "", NewExecutionError(ErrorPolicyViolation, "runtime_version %q is not installed for %s (installed: %s)", version, language, strings.Join(found, ", "))
+}
+
+// compareVersions compares dotted version strings numerically where possible.
+func compareVersions(a, b string) int {
+	aParts, bParts := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNum, aErr := strconv.Atoi(aParts[i])
+		bNum, bErr := strconv.Atoi(bParts[i])
+		switch {
+		case aErr == nil && bErr == nil && aNum != bNum:
+			return aNum - bNum
+		case (aErr != nil || bErr != nil) && aParts[i] != bParts[i]:
+			return strings.Compare(aParts[i], bParts[i])
+		}
+	}
+	return len(aParts) - len(bParts)
+}
+
+// runtimeBinaries lists, per language, the binaries tried in order to run code
+// on the host and what to install when none is found.
+var runtimeBinaries = map[string]struct {
+	candidates []string
+	install    string
+}{
+	"python":     {[]string{"python3", "python", "py"}, "Python 3"},
+	"bash":       {[]string{"bash"}, "Bash"},
+	"typescript": {[]string{"ts-node", "tsx", "npx"}, "ts-node, tsx or Node.js"},
+	"go":         {[]string{"go"}, "Go"},
+}
+
+// FindBinary returns the binary running language in subprocess mode without a
+// runtime version: configured when set, else the first runtime found in PATH.
+func FindBinary(language, configured string) (string, error) {
+	return findBinary(language, "", configured)
+}
+
+// findBinary returns the binary running language on the host. A configured
+// binary, a name looked up in PATH or an absolute path, replaces the
+// candidates. Otherwise the candidates are tried in order in binDir when a
+// runtime version was requested, else in PATH.
+func findBinary(language, binDir, configured string) (string, error) {
+	if configured != "" {
+		path, err := exec.LookPath(configured)
+		if err != nil {
+			return "", fmt.Errorf("%s runtime %q configured in execution.binaries not found: %v", language, configured, err)
+		}
+		return path, nil
+	}
+
+	known := runtimeBinaries[language]
+	for _, name := range known.candidates {
+		if binDir != "" {
+			path := filepath.Join(binDir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path, nil
+			}
+		} else if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	if binDir != "" {
+		return "", fmt.Errorf("%s runtime not found in %s (tried %s)", language, binDir, strings.Join(known.candidates, ", "))
+	}
+	return "", fmt.Errorf("%s runtime not found (tried %s): install %s or use docker mode", language, strings.Join(known.candidates, ", "), known.install)
+}
+
+// resolveRuntime returns the bin directory for the requested runtime version, or
+// "" when no version is requested.
+func resolveRuntime(ctx context.Context, language, version string) (string, error) {
+	if version == "" {
+		return "", nil
+	}
+	binDir, err := FindRuntime(language, version)
+	if err != nil {
+		return "", err
+	}
+	logger.DebugContext(ctx, "Using %s %s toolchain from %s", language, version, binDir)
+	return binDir, nil
+}
+
+// runtimeEnv puts binDir first in the PATH of env, so child processes (npx, go
+// tool, pip) use the same toolchain.
+func runtimeEnv(env []string, binDir string) []string {
+	if binDir == "" {
+		return env
+	}
+	return append(env, "PATH="+binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,128 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindRuntime(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PYENV_ROOT", filepath.Join(home, "pyenv"))
+	t.Setenv("NVM_DIR", filepath.Join(home, "nvm"))
+	for _, dir := range []string{
+		"pyenv/versions/3.1.0/bin",
+		"pyenv/versions/3.12.1/bin",
+		"pyenv/versions/3.12.4/bin",
+		"nvm/versions/node/v20.11.0/bin",
+		"sdk/go1.22.5/bin",
+	} {
+		if err := os.MkdirAll(filepath.Join(home, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		language string
+		version  string
+		want     string
+		wantErr  string
+	}{
+		{"python", "3.12", "pyenv/versions/3.12.4/bin", ""},
+		{"python", "3.1", "pyenv/versions/3.1.0/bin", ""},
+		{"python", "3.12.1", "pyenv/versions/3.12.1/bin", ""},
+		{"typescript", "20", "nvm/versions/node/v20.11.0/bin", ""},
+		{"go", "1.22", "sdk/go1.22.5/bin", ""},
+		{"python", "3.9", "", "installed: 3.1.0, 3.12.1, 3.12.4"},
+		{"bash", "5", "", "not supported"},
+	}
+	for _, tt := range tests {
+		got, err := FindRuntime(tt.language, tt.version)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("FindRuntime(%q, %q) error = %v, want it to contain %q", tt.language, tt.version, err, tt.wantErr)
+			}
+			continue
+		}
+		if want := filepath.Join(home, tt.want); err != nil || got != want {
+			t.Errorf("FindRuntime(%q, %q) = %q, %v, want %q", tt.language, tt.version, got, err, want)
+		}
+	}
+}
+
+func TestFindBinary(t *testing.T) {
+	pathDir, binDir := t.TempDir(), t.TempDir()
+	for _, file := range []string{
+		filepath.Join(pathDir, "python"),
+		filepath.Join(pathDir, "py"),
+		filepath.Join(pathDir, "npx"),
+		filepath.Join(binDir, "python3"),
+	} {
+		if err := os.WriteFile(file, []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", pathDir)
+
+	tests := []struct {
+		name       string
+		language   string
+		binDir     string
+		configured string
+		want       string
+		wantErr    string
+	}{
+		{"python falls back to python", "python", "", "", filepath.Join(pathDir, "python"), ""},
+		{"typescript falls back to npx", "typescript", "", "", filepath.Join(pathDir, "npx"), ""},
+		{"runtime version", "python", binDir, "", filepath.Join(binDir, "python3"), ""},
+		{"configured name", "python", "", "py", filepath.Join(pathDir, "py"), ""},
+		{"configured path", "python", binDir, filepath.Join(binDir, "python3"), filepath.Join(binDir, "python3"), ""},
+		{"not found", "go", "", "", "", "go runtime not found (tried go): install Go or use docker mode"},
+		{"not found in runtime version", "typescript", binDir, "", "", "not found in " + binDir},
+		{"configured not found", "bash", "", "bash5", "", `bash runtime "bash5" configured in execution.binaries not found`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findBinary(tt.language, tt.binDir, tt.configured)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("findBinary() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("findBinary() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int // Sign of the result
+	}{
+		{"3.12.4", "3.12.1", 1},
+		{"3.9", "3.12", -1},
+		{"22", "22", 0},
+		{"3.12", "3.12.0", -1},
+	}
+	for _, tt := range tests {
+		got := compareVersions(tt.a, tt.b)
+		if sign(got) != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want sign %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,130 @@
+// Package executor provides an executor decorator that describes the sandbox
+// to the executed code through environment variables.
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync/atomic"
+)
+
+// Environment variables set in every execution by SandboxEnvExecutor.
+const (
+	ModeEnv           = "MCP_EXECUTOR_MODE"   // Execution mode: subprocess, docker, hybrid or nix
+	ExecutionIDEnv    = "MCP_EXECUTION_ID"    // ID of the execution, as in execution://{id}
+	WorkspaceEnv      = "MCP_WORKSPACE"       // Writable scratch directory, removed after the execution
+	TimeoutSecondsEnv = "MCP_TIMEOUT_SECONDS" // Effective timeout in seconds; 0 when unlimited
+	ArtifactsEnv      = "MCP_ARTIFACTS"       // Directory whose HTML and Markdown reports become resources
+)
+
+// ExecutionModes lists the supported execution modes.
+var ExecutionModes = []string{"subprocess", "docker", "hybrid", "nix"}
+
+// ContainerMode reports whether executionMode runs code in Docker containers:
+// docker, or hybrid with its persistent containers.
+func ContainerMode(executionMode string) bool {
+	return executionMode == "docker" || executionMode == "hybrid"
+}
+
+// ContainerWorkspace is the working directory of Docker executions and their
+// MCP_WORKSPACE. It lives in the container, which is removed after the execution.
+const ContainerWorkspace = "/tmp/mcp-executor"
+
+type executionIDKey struct{}
+
+// execution identifies an execution and counts the containers started for it,
+// which auto-fix and preemption run again.
+type execution struct {
+	id         string
+	containers atomic.Int32
+}
+
+// WithExecutionID returns a context whose executions are identified by id.
+func WithExecutionID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, executionIDKey{}, &execution{id: id})
+}
+
+// ExecutionID returns the execution ID of ctx, or an empty string.
+func ExecutionID(ctx context.Context) string {
+	if execution, ok := ctx.Value(executionIDKey{}).(*execution); ok {
+		return execution.id
+	}
+	return ""
+}
+
+type clientIDKey struct{}
+
+// WithClientID returns a context whose executions are made by the client id,
+// e.g. "key-9f86d081".
+func WithClientID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, clientIDKey{}, id)
+}
+
+// ClientID returns the client ID of ctx, or an empty string.
+func ClientID(ctx context.Context) string {
+	id, _ := ctx.Value(clientIDKey{}).(string)
+	return id
+}
+
+// SandboxEnvExecutor sets the MCP_* variables describing the execution mode,
+// execution ID, workspace, timeout and artifacts directory. They take precedence over per-call and
+// default variables of the same name.
+type SandboxEnvExecutor struct {
+	executor Executor
+	mode     string
+}
+
+// NewSandboxEnvExecutor wraps exec for the execution mode. A named workspace
+// is SharedWorkspace in container modes and its host directory otherwise.
+// Without one, the workspace is ContainerWorkspace in container modes (a
+// directory below it in persistent containers); otherwise it is the scratch
+// directory created for each host execution and passed on as ScratchDir.
+func NewSandboxEnvExecutor(exec Executor, mode string) Executor {
+	return &SandboxEnvExecutor{executor: exec, mode: mode}
+}
+
+func (s *SandboxEnvExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	id := ExecutionID(ctx)
+	if id == "" {
+		id = randomSuffix()
+	}
+
+	options := NewOptions(opts...)
+	workspace := ContainerWorkspace
+	if !ContainerMode(s.mode) {
+		dir, err := os.MkdirTemp("", "mcp-executor-workspace-*")
+		if err != nil {
+			return "", fmt.Errorf("failed to create workspace: %v", err)
+		}
+		defer func() { _ = os.RemoveAll(dir) }()
+		if err := grantRunAs(ctx, dir); err != nil {
+			return "", err
+		}
+		workspace = dir
+		opts = append(opts, withScratchDir(dir))
+	}
+	switch {
+	case options.WorkspaceDir != "" && ContainerMode(s.mode):
+		workspace = SharedWorkspace
+	case options.WorkspaceDir != "":
+		workspace = options.WorkspaceDir
+	}
+
+	env := make(map[string]string, len(envVars)+5)
+	for key, value := range envVars {
+		env[key] = value
+	}
+	env[ModeEnv] = s.mode
+	env[ExecutionIDEnv] = id
+	env[WorkspaceEnv] = workspace
+	env[TimeoutSecondsEnv] = strconv.FormatFloat(options.Timeout.Seconds(), 'f', -1, 64)
+	switch {
+	case options.ArtifactsDir != "" && ContainerMode(s.mode):
+		env[ArtifactsEnv] = ContainerArtifacts
+	case options.ArtifactsDir != "":
+		env[ArtifactsEnv] = options.ArtifactsDir
+	}
+	return s.executor.Execute(ctx, code, dependencies, env, opts...)
+}
@@ -0,0 +1,66 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+// workspaceChecker records the environment of the last execution and whether
+// its workspace existed during it.
+type workspaceChecker struct {
+	envRecorder
+	existed bool
+}
+
+func (w *workspaceChecker) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	_, err := os.Stat(envVars[WorkspaceEnv])
+	w.existed = err == nil
+	return w.envRecorder.Execute(ctx, code, dependencies, envVars, opts...)
+}
+
+func TestSandboxEnvExecutor(t *testing.T) {
+	checker := &workspaceChecker{}
+	exec := NewTimeoutExecutor(NewSandboxEnvExecutor(checker, "subprocess"), TimeoutPolicy{Default: 90 * time.Second})
+
+	ctx := WithExecutionID(context.Background(), "abc123")
+	if _, err := exec.Execute(ctx, "", nil, map[string]string{"TOKEN": "x", ModeEnv: "spoofed"}); err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+
+	env := checker.env
+	for key, want := range map[string]string{
+		"TOKEN":           "x",
+		ModeEnv:           "subprocess",
+		ExecutionIDEnv:    "abc123",
+		TimeoutSecondsEnv: "90",
+	} {
+		if env[key] != want {
+			t.Errorf("%s = %q, want %q", key, env[key], want)
+		}
+	}
+	if !checker.existed {
+		t.Errorf("Workspace %q should exist during the execution", env[WorkspaceEnv])
+	}
+	if _, err := os.Stat(env[WorkspaceEnv]); !os.IsNotExist(err) {
+		t.Errorf("Workspace %q should be removed after the execution", env[WorkspaceEnv])
+	}
+}
+
+func TestSandboxEnvExecutor_Docker(t *testing.T) {
+	recorder := &envRecorder{}
+	if _, err := NewSandboxEnvExecutor(recorder, "docker").Execute(context.Background(), "", nil, nil); err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+
+	if recorder.env[WorkspaceEnv] != ContainerWorkspace {
+		t.Errorf("%s = %q, want %q", WorkspaceEnv, recorder.env[WorkspaceEnv], ContainerWorkspace)
+	}
+	if recorder.env[ExecutionIDEnv] == "" {
+		t.Errorf("%s should be generated without an execution ID in the context", ExecutionIDEnv)
+	}
+	if recorder.env[TimeoutSecondsEnv] != "0" {
+		t.Errorf("%s = %q, want 0 without a timeout", TimeoutSecondsEnv, recorder.env[TimeoutSecondsEnv])
+	}
+}
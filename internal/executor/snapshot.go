@@ -0,0 +1,270 @@
+// Package executor provides the snapshots of Docker executions: images
+// committed from the container of a successful execution, with the packages it
+// installed and the files it wrote, in which the later executions of the
+// sessions restoring them run, and tarballs of the named workspace it used.
+package executor
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+const (
+	// SnapshotLabel marks the images of snapshots with their name.
+	SnapshotLabel = "mcp-executor.snapshot"
+	// WorkspaceLabel marks the images of snapshots with the named workspace of
+	// the execution they were saved from, archived next to them.
+	WorkspaceLabel = "mcp-executor.workspace"
+)
+
+var snapshotName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// CheckSnapshotName reports why name cannot name a snapshot.
+func CheckSnapshotName(name string) error {
+	if !snapshotName.MatchString(name) {
+		return NewExecutionError(ErrorPolicyViolation, "invalid snapshot %q: use up to 64 letters, digits, '.', '_' or '-', starting with a letter or digit", name)
+	}
+	return nil
+}
+
+// SnapshotTag identifies client in the image tags and paths of its snapshots
+// without revealing it.
+func SnapshotTag(client string) string {
+	sum := sha256.Sum256([]byte(client))
+	return hex.EncodeToString(sum[:4])
+}
+
+// SnapshotImageName returns the image of the snapshot name saved by client,
+// e.g. "mcp-executor-snapshot:9f86d081-pandas".
+func SnapshotImageName(client, name string) string {
+	return "mcp-executor-snapshot:" + SnapshotTag(client) + "-" + name
+}
+
+type snapshotImagesKey struct{}
+
+// WithSnapshotImages returns a context whose executions of the languages of
+// images run in their restored snapshot image.
+func WithSnapshotImages(ctx context.Context, images map[string]string) context.Context {
+	return context.WithValue(ctx, snapshotImagesKey{}, images)
+}
+
+// SnapshotImages returns the restored snapshot images of ctx by language.
+func SnapshotImages(ctx context.Context) map[string]string {
+	images, _ := ctx.Value(snapshotImagesKey{}).(map[string]string)
+	return images
+}
+
+// SnapshotImage returns the restored snapshot image running the executions of
+// language in ctx, or an empty string.
+func SnapshotImage(ctx context.Context, language string) string {
+	return SnapshotImages(ctx)[language]
+}
+
+// Snapshot describes a saved snapshot.
+type Snapshot struct {
+	Name     string
+	Image    string
+	Language string // Executor name of the execution it was saved from
+	// Workspace is the named workspace of that execution, whose files are
+	// archived at SnapshotArchive, or empty.
+	Workspace string
+}
+
+// SnapshotArchive returns the tarball below the workspace root holding the
+// named workspace of the snapshot name saved by client.
+func SnapshotArchive(root, client, name string) string {
+	return filepath.Join(root, "snapshots", SnapshotTag(client), name+".tar.gz")
+}
+
+// InspectSnapshot returns the snapshot name saved by client. Snapshots of other
+// clients are unknown.
+func InspectSnapshot(ctx context.Context, client, name string) (Snapshot, error) {
+	if err := CheckSnapshotName(name); err != nil {
+		return Snapshot{}, err
+	}
+	snapshot := Snapshot{Name: name, Image: SnapshotImageName(client, name)}
+	format := `{{index .Config.Labels "` + LanguageLabel + `"}} {{index .Config.Labels "` + WorkspaceLabel + `"}}`
+	out, err := exec.CommandContext(ctx, "docker", "image", "inspect", "--format", format, snapshot.Image).CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(out), "No such image") {
+			return Snapshot{}, fmt.Errorf("unknown snapshot %q: save it with the snapshot argument of an execute tool first", name)
+		}
+		return Snapshot{}, fmt.Errorf("failed to inspect snapshot %s: %v: %s", name, err, strings.TrimSpace(string(out)))
+	}
+	snapshot.Language, snapshot.Workspace, _ = strings.Cut(strings.TrimSpace(string(out)), " ")
+	return snapshot, nil
+}
+
+// commitContainer saves container, which ran an execution of language with
+// options, as the snapshot options.Snapshot of the client of ctx, replacing an
+// earlier snapshot of the same name. The workspace label is set also when empty, so snapshots saved
+// in a restored snapshot do not inherit its workspace.
+func commitContainer(ctx context.Context, container, language string, options Options) error {
+	image := SnapshotImageName(ClientID(ctx), options.Snapshot)
+	logger.InfoContext(ctx, "Saving container %s as snapshot %s", container, image)
+	args := []string{"commit",
+		"--change", "LABEL " + SnapshotLabel + "=" + options.Snapshot,
+		"--change", "LABEL " + LanguageLabel + "=" + language,
+		"--change", "LABEL " + WorkspaceLabel + "=" + options.Workspace}
+	// The labels of the saving execution do not describe the containers running the snapshot
+	for _, label := range []string{ExecutionLabel, SessionLabel, ClientLabel} {
+		args = append(args, "--change", "LABEL "+label+"=")
+	}
+	args = append(args, container, image)
+	if out, err := exec.CommandContext(ctx, "docker", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to save snapshot %s: %v: %s", options.Snapshot, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// removeContainer removes a stopped container kept for its snapshot.
+func removeContainer(container string) {
+	if out, err := exec.Command("docker", "rm", "-f", container).CombinedOutput(); err != nil {
+		logger.Error("Failed to remove container %s: %v: %s", container, err, strings.TrimSpace(string(out)))
+	}
+}
+
+// ArchiveWorkspace writes the directories and regular files below dir to the
+// gzipped tarball path, replacing it once complete. Other files, such as
+// symbolic links, are left out.
+func ArchiveWorkspace(dir, path string) (err error) {
+	file, err := os.CreateTemp(filepath.Dir(path), ".snapshot-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+
+	compressed := gzip.NewWriter(file)
+	archive := tar.NewWriter(compressed)
+	err = filepath.WalkDir(dir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || name == dir {
+			return err
+		}
+		if !entry.IsDir() && !entry.Type().IsRegular() {
+			logger.Debug("Not archiving %s: not a regular file", name)
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relative)
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		return copyFile(archive, name)
+	})
+	if err != nil {
+		return err
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	if err := compressed.Close(); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// copyFile writes the content of the file name to w.
+func copyFile(w io.Writer, name string) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(w, file)
+	return err
+}
+
+// ExtractWorkspace unpacks the directories and regular files of a tarball
+// written by ArchiveWorkspace into dir, replacing files of the same name.
+// Entries leading out of dir, also through symbolic links left in dir by
+// executions, are refused.
+func ExtractWorkspace(path, dir string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	compressed, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	archive := tar.NewReader(compressed)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.FromSlash(header.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("refusing snapshot entry %q outside the workspace", header.Name)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = root.MkdirAll(name, 0o700)
+		case tar.TypeReg:
+			if err = root.MkdirAll(filepath.Dir(name), 0o700); err == nil {
+				err = extractFile(root, archive, name, header.FileInfo().Mode().Perm())
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to restore %s: %v", header.Name, err)
+		}
+	}
+}
+
+// extractFile writes the content of r to the file name below root with perm.
+func extractFile(root *os.Root, r io.Reader, name string, perm fs.FileMode) error {
+	file, err := root.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
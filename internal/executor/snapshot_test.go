@@ -0,0 +1,159 @@
+package executor
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckSnapshotName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"pandas", false},
+		{"data-2026.10_v2", false},
+		{"", true},
+		{"-pandas", true},
+		{"../pandas", true},
+		{"pandas:latest", true},
+	}
+	for _, tt := range tests {
+		if err := CheckSnapshotName(tt.name); (err != nil) != tt.wantErr {
+			t.Errorf("CheckSnapshotName(%q) error = %v, want error %t", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSnapshotImageName(t *testing.T) {
+	image := SnapshotImageName("key-9f86d081", "pandas")
+	if want := "mcp-executor-snapshot:" + SnapshotTag("key-9f86d081") + "-pandas"; image != want {
+		t.Errorf("SnapshotImageName() = %q, want %q", image, want)
+	}
+	if other := SnapshotImageName("anonymous", "pandas"); other == image {
+		t.Errorf("Snapshots of different clients share image %q", image)
+	}
+}
+
+func TestSnapshotImage(t *testing.T) {
+	ctx := WithSnapshotImages(context.Background(), map[string]string{"python": "snapshot"})
+	if got := SnapshotImage(ctx, "python"); got != "snapshot" {
+		t.Errorf("SnapshotImage(python) = %q, want %q", got, "snapshot")
+	}
+	if got := SnapshotImage(ctx, "bash"); got != "" {
+		t.Errorf("SnapshotImage(bash) = %q, want none", got)
+	}
+	if got := SnapshotImage(context.Background(), "python"); got != "" {
+		t.Errorf("SnapshotImage() without snapshots = %q, want none", got)
+	}
+}
+
+func TestDockerExecutor_InvalidSnapshot(t *testing.T) {
+	// Invalid names are refused before a container is started
+	docker := NewPythonExecutor()
+	if _, err := docker.Execute(context.Background(), "print(1)", nil, nil, WithSnapshot("../pandas")); err == nil {
+		t.Error("Execute() with an invalid snapshot name returned no error")
+	}
+}
+
+func TestArchiveWorkspace(t *testing.T) {
+	source := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(source, "data", "raw"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"notes.txt":             "notes",
+		"data/raw/measures.csv": "a,b\n1,2\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(source, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("/etc/passwd", filepath.Join(source, "passwd")); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(t.TempDir(), "snapshot.tar.gz")
+	if err := ArchiveWorkspace(source, archive); err != nil {
+		t.Fatalf("ArchiveWorkspace() returned error: %v", err)
+	}
+
+	target := t.TempDir()
+	if err := os.WriteFile(filepath.Join(target, "notes.txt"), []byte("older notes"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExtractWorkspace(archive, target); err != nil {
+		t.Fatalf("ExtractWorkspace() returned error: %v", err)
+	}
+	for name, want := range files {
+		if got, err := os.ReadFile(filepath.Join(target, name)); err != nil || string(got) != want {
+			t.Errorf("Restored %s = %q (%v), want %q", name, got, err, want)
+		}
+	}
+	if _, err := os.Lstat(filepath.Join(target, "passwd")); !os.IsNotExist(err) {
+		t.Errorf("Symbolic links should not be restored, got error %v", err)
+	}
+}
+
+func TestExtractWorkspace_Outside(t *testing.T) {
+	outside := t.TempDir()
+	tests := []struct {
+		name  string
+		entry string
+		link  string // Symbolic link named link in the workspace to outside
+	}{
+		{"parent directory", "../escaped.txt", ""},
+		{"absolute path", outside + "/escaped.txt", ""},
+		{"symbolic link in the workspace", "link/escaped.txt", "link"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := filepath.Join(t.TempDir(), "snapshot.tar.gz")
+			writeTarball(t, archive, tt.entry, "escaped")
+			workspace := filepath.Join(t.TempDir(), "workspace")
+			if err := os.Mkdir(workspace, 0o700); err != nil {
+				t.Fatal(err)
+			}
+			if tt.link != "" {
+				if err := os.Symlink(outside, filepath.Join(workspace, tt.link)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := ExtractWorkspace(archive, workspace); err == nil {
+				t.Errorf("ExtractWorkspace() of entry %q returned no error", tt.entry)
+			}
+			for _, dir := range []string{outside, filepath.Dir(workspace)} {
+				if _, err := os.Stat(filepath.Join(dir, "escaped.txt")); !os.IsNotExist(err) {
+					t.Errorf("Entry %q was written outside the workspace", tt.entry)
+				}
+			}
+		})
+	}
+}
+
+// writeTarball writes a gzipped tarball at path holding the file name with content.
+func writeTarball(t *testing.T, path, name, content string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	compressed := gzip.NewWriter(file)
+	archive := tar.NewWriter(compressed)
+	if err := archive.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := archive.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := compressed.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,54 @@
+// Package executor feeds the output of a previous execution to the code on
+// stdin. Executors passing the code on stdin write it to a file instead when
+// the execution has input.
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type stdinKey struct{}
+
+// WithStdin returns a context whose executions read input on stdin.
+// Executions without it get an empty stdin.
+func WithStdin(ctx context.Context, input string) context.Context {
+	return context.WithValue(ctx, stdinKey{}, input)
+}
+
+// Stdin returns the input of the executions of ctx and whether they have one.
+func Stdin(ctx context.Context) (string, bool) {
+	input, ok := ctx.Value(stdinKey{}).(string)
+	return input, ok
+}
+
+// codeFileNames name the code files of the languages run from a file.
+var codeFileNames = map[string]string{
+	"python":     "main.py",
+	"bash":       "main.sh",
+	"typescript": "index.ts",
+	"go":         "main.go",
+}
+
+// writeCodeFile writes code to a file of language in a new temporary
+// directory, readable by the user of the execution, and returns its path and
+// a function removing it.
+func writeCodeFile(ctx context.Context, language, code string) (string, func(), error) {
+	dir, err := os.MkdirTemp("", "mcp-executor-code-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp directory: %v", err)
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+	path := filepath.Join(dir, codeFileNames[language])
+	if err := os.WriteFile(path, []byte(code), 0o600); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write code file: %v", err)
+	}
+	if err := grantRunAs(ctx, dir, path); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return path, cleanup, nil
+}
@@ -0,0 +1,56 @@
+package executor
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestStdin_Subprocess(t *testing.T) {
+	tests := []struct {
+		name     string
+		binary   string
+		executor Executor
+		code     string
+		want     string
+	}{
+		{"python", "python3", NewSubprocessPythonExecutor(), "import sys\nprint(sys.stdin.read().upper(), end='')", "A\nB\n"},
+		{"bash", "bash", NewSubprocessBashExecutor(), "tr a-z A-Z", "A\nB\n"},
+		{"go", "go", NewSubprocessGoExecutor(), "package main\n\nimport (\n\t\"io\"\n\t\"os\"\n\t\"strings\"\n)\n\nfunc main() {\n\tdata, _ := io.ReadAll(os.Stdin)\n\tos.Stdout.WriteString(strings.ToUpper(string(data)))\n}\n", "A\nB\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := exec.LookPath(tt.binary); err != nil {
+				t.Skipf("%s not installed", tt.binary)
+			}
+			output, err := tt.executor.Execute(WithStdin(context.Background(), "a\nb\n"), tt.code, nil, map[string]string{"GOPROXY": "off"})
+			if err != nil {
+				t.Fatalf("Execute() returned error: %v", err)
+			}
+			if output != tt.want {
+				t.Errorf("Execute() = %q, want %q", output, tt.want)
+			}
+		})
+	}
+}
+
+func TestPipeCmd(t *testing.T) {
+	if _, err := exec.LookPath("dd"); err != nil {
+		t.Skip("dd not installed")
+	}
+	code := "echo code; cat"
+	cmd := exec.Command("sh", "-c", strings.Join(pipeCmd("main.sh", "sh"), " "))
+	cmd.Dir = t.TempDir()
+	cmd.Env = append(cmd.Environ(), codeSizeEnv+"="+strconv.Itoa(len(code)))
+	cmd.Stdin = strings.NewReader(code + "piped input\n")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("pipe command failed: %v: %s", err, output)
+	}
+	if string(output) != "code\npiped input\n" {
+		t.Errorf("pipe command output = %q, want the code to read the input following it", output)
+	}
+}
@@ -8,53 +8,108 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/ylchen07/mcp-executor/internal/logger"
 )
 
+// waitDelay bounds how long a cancelled execution waits for descendants that
+// still hold its output pipes open.
+const waitDelay = time.Second
+
 type SubprocessConfig struct {
-	Binary       string
+	Binary       string // Runs the code instead of the discovered runtime, see WithBinary
 	InstallCmd   []string
 	ExecutorName string
+	Language     string // Used to find toolchains for a requested runtime version
+	GoCacheDir   string // Holds the Go build and module caches, see WithGoCacheDir
+}
+
+// SubprocessOption configures a subprocess executor.
+type SubprocessOption func(*SubprocessConfig)
+
+// WithBinary runs code with binary, a name looked up in PATH or an absolute
+// path, instead of the first runtime found for the language. Empty keeps the
+// discovery.
+func WithBinary(binary string) SubprocessOption {
+	return func(c *SubprocessConfig) {
+		c.Binary = binary
+	}
+}
+
+// configuredBinary returns the binary selected by opts, if any.
+func configuredBinary(opts []SubprocessOption) string {
+	return subprocessConfig(opts).Binary
+}
+
+// subprocessConfig returns the configuration set by opts.
+func subprocessConfig(opts []SubprocessOption) SubprocessConfig {
+	var config SubprocessConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
 }
 
 type SubprocessExecutor struct {
 	config SubprocessConfig
 }
 
-func NewSubprocessPythonExecutor() *SubprocessExecutor {
+// NewSubprocessPythonExecutor runs code with the first of python3, python and
+// py found on the host.
+func NewSubprocessPythonExecutor(opts ...SubprocessOption) *SubprocessExecutor {
 	return &SubprocessExecutor{
 		config: SubprocessConfig{
-			Binary:       "python3",
+			Binary:       configuredBinary(opts),
 			InstallCmd:   nil, // No pip installation in subprocess mode for security
 			ExecutorName: "python-subprocess",
+			Language:     "python",
 		},
 	}
 }
 
-func NewSubprocessBashExecutor() *SubprocessExecutor {
+func NewSubprocessBashExecutor(opts ...SubprocessOption) *SubprocessExecutor {
 	return &SubprocessExecutor{
 		config: SubprocessConfig{
-			Binary:       "bash",
+			Binary:       configuredBinary(opts),
 			InstallCmd:   nil, // Skip dependency installation for bash
 			ExecutorName: "bash-subprocess",
+			Language:     "bash",
 		},
 	}
 }
 
 // TypeScriptSubprocessExecutor is a specialized executor for TypeScript using ts-node
-type TypeScriptSubprocessExecutor struct{}
+type TypeScriptSubprocessExecutor struct {
+	binary string
+}
 
-func NewSubprocessTypeScriptExecutor() *TypeScriptSubprocessExecutor {
-	return &TypeScriptSubprocessExecutor{}
+// NewSubprocessTypeScriptExecutor runs code with the first of ts-node, tsx and
+// "npx tsx" found on the host.
+func NewSubprocessTypeScriptExecutor(opts ...SubprocessOption) *TypeScriptSubprocessExecutor {
+	return &TypeScriptSubprocessExecutor{binary: configuredBinary(opts)}
 }
 
-func (t *TypeScriptSubprocessExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string) (string, error) {
+func (t *TypeScriptSubprocessExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
 	logger.Debug("Starting typescript-subprocess execution")
 
+	options := NewOptions(opts...)
+	binDir, err := resolveRuntime(ctx, "typescript", options.RuntimeVersion)
+	if err != nil {
+		return "", err
+	}
+	if options.CheckOnly {
+		tsc, err := findTypeScriptChecker(binDir)
+		if err != nil {
+			return "", err
+		}
+		return checkOnHost(ctx, "typescript-subprocess", "typescript", tsc, code, withEnvVars(runtimeEnv(hostEnv(ctx), binDir), envVars))
+	}
+
 	if len(dependencies) > 0 {
-		logger.Debug("Skipping dependency installation for typescript-subprocess (not supported in subprocess mode)")
+		logger.WarnContext(ctx, "Ignoring dependencies for typescript-subprocess: installation is not supported in subprocess mode")
 	}
 
 	// Create a temporary directory for the TypeScript file
@@ -69,33 +124,39 @@ func (t *TypeScriptSubprocessExecutor) Execute(ctx context.Context, code string,
 	if err := os.WriteFile(tmpFile, []byte(code), 0600); err != nil {
 		return "", fmt.Errorf("failed to write temp file: %v", err)
 	}
+	if err := grantRunAs(ctx, tmpDir, tmpFile); err != nil {
+		return "", err
+	}
 
 	logger.Verbose("Executing TypeScript code in subprocess")
 	logger.Debug("Code to execute:\n%s", code)
 
 	// Execute with ts-node (falls back to tsx, then npx tsx if not available)
-	var cmd *exec.Cmd
-	if _, err := exec.LookPath("ts-node"); err == nil {
-		cmd = exec.CommandContext(ctx, "ts-node", tmpFile)
-	} else if _, err := exec.LookPath("tsx"); err == nil {
-		cmd = exec.CommandContext(ctx, "tsx", tmpFile)
-	} else if _, err := exec.LookPath("npx"); err == nil {
-		cmd = exec.CommandContext(ctx, "npx", "tsx", tmpFile)
-	} else {
-		return "", fmt.Errorf("neither ts-node, tsx, nor npx found on system - please install one to run TypeScript")
+	runner, err := findBinary("typescript", binDir, t.binary)
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.CommandContext(ctx, runner, tmpFile)
+	if strings.TrimSuffix(filepath.Base(runner), ".exe") == "npx" {
+		cmd = exec.CommandContext(ctx, runner, "tsx", tmpFile)
+	}
+
+	if input, piped := Stdin(ctx); piped {
+		cmd.Stdin = strings.NewReader(input)
 	}
 
 	// Set environment variables
-	cmd.Env = os.Environ() // Start with current environment
+	cmd.Env = runtimeEnv(hostEnv(ctx), binDir) // Start with the passed-through server environment
 	for key, value := range envVars {
 		cmd.Env = append(cmd.Env, key+"="+value)
 	}
 
-	out, err := cmd.CombinedOutput()
+	cmd.WaitDelay = waitDelay
+	out, err := runWithUsage(ctx, cmd)
 	if err != nil {
 		logger.Debug("Execution failed: %v", err)
 		if exitError, ok := err.(*exec.ExitError); ok {
-			return "", fmt.Errorf("typescript-subprocess exited with code %d: %s", exitError.ExitCode(), string(out))
+			return "", exitErr("typescript-subprocess", exitError, string(out))
 		}
 		return "", fmt.Errorf("execution failed: %v", err)
 	}
@@ -105,17 +166,47 @@ func (t *TypeScriptSubprocessExecutor) Execute(ctx context.Context, code string,
 }
 
 // GoSubprocessExecutor is a specialized executor for Go that uses temporary files
-type GoSubprocessExecutor struct{}
+type GoSubprocessExecutor struct {
+	binary   string
+	cacheDir string
+}
 
-func NewSubprocessGoExecutor() *GoSubprocessExecutor {
-	return &GoSubprocessExecutor{}
+func NewSubprocessGoExecutor(opts ...SubprocessOption) *GoSubprocessExecutor {
+	config := subprocessConfig(opts)
+	return &GoSubprocessExecutor{binary: config.Binary, cacheDir: config.GoCacheDir}
 }
 
-func (g *GoSubprocessExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string) (string, error) {
+// env returns the environment of executions with envVars: the passed-through
+// server environment with the toolchain of binDir and the cache directory.
+func (g *GoSubprocessExecutor) env(ctx context.Context, binDir string, envVars map[string]string) []string {
+	env := runtimeEnv(hostEnv(ctx), binDir)
+	if g.cacheDir != "" {
+		env = append(env, goCacheEnv(g.cacheDir, envVars)...)
+	}
+	return withEnvVars(env, envVars)
+}
+
+func (g *GoSubprocessExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
 	logger.Debug("Starting go-subprocess execution")
 
+	options := NewOptions(opts...)
+	binDir, err := resolveRuntime(ctx, "go", options.RuntimeVersion)
+	if err != nil {
+		return "", err
+	}
+	goBinary, err := findBinary("go", binDir, g.binary)
+	if err != nil {
+		return "", err
+	}
+	if err := ValidateFiles(options.Files, "main.go", "go.mod"); err != nil {
+		return "", err
+	}
+	if options.CheckOnly && len(options.Files) == 0 {
+		return checkOnHost(ctx, "go-subprocess", "go", goBinary, code, g.env(ctx, binDir, envVars))
+	}
+
 	if len(dependencies) > 0 {
-		logger.Debug("Skipping dependency installation for go-subprocess (not supported in subprocess mode)")
+		logger.WarnContext(ctx, "Ignoring dependencies for go-subprocess: installation is not supported in subprocess mode")
 	}
 
 	// Create a temporary directory for the Go file
@@ -130,24 +221,60 @@ func (g *GoSubprocessExecutor) Execute(ctx context.Context, code string, depende
 	if err := os.WriteFile(tmpFile, []byte(code), 0600); err != nil {
 		return "", fmt.Errorf("failed to write temp file: %v", err)
 	}
+	if err := grantRunAs(ctx, tmpDir, tmpFile); err != nil {
+		return "", err
+	}
 
 	logger.Verbose("Executing Go code in subprocess")
 	logger.Debug("Code to execute:\n%s", code)
 
-	// Execute with go run
-	cmd := exec.CommandContext(ctx, "go", "run", tmpFile)
+	env := g.env(ctx, binDir, envVars)
 
-	// Set environment variables
-	cmd.Env = os.Environ() // Start with current environment
-	for key, value := range envVars {
-		cmd.Env = append(cmd.Env, key+"="+value)
+	// Code with files builds as the main package of a module
+	target := tmpFile
+	if len(options.Files) > 0 {
+		if err := writeGoModule(ctx, tmpDir, goBinary, options.Files, env); err != nil {
+			return "", err
+		}
+		target = "."
+		if options.CheckOnly {
+			return runCheck(ctx, "go-subprocess", tmpDir, []string{goBinary, "vet", "."}, env)
+		}
 	}
 
-	out, err := cmd.CombinedOutput()
+	// Build first, so compile errors are reported apart from the output of
+	// the program; the build counts in the usage of the execution
+	binary := filepath.Join(tmpDir, "main")
+	build := exec.CommandContext(ctx, goBinary, "build", "-o", binary, target)
+	build.Dir = tmpDir
+	build.Env = env
+	build.WaitDelay = waitDelay
+	var buildUsage Usage
+	out, err := runWithUsage(WithUsage(ctx, &buildUsage), build)
+	if err != nil {
+		reportUsage(ctx, buildUsage)
+		logger.Debug("Build failed: %v", err)
+		if exitError, ok := err.(*exec.ExitError); ok {
+			if exitError.Exited() {
+				return "", newCompileError("go-subprocess", string(out))
+			}
+			return "", exitErr("go-subprocess", exitError, string(out))
+		}
+		return "", fmt.Errorf("execution failed: %v", err)
+	}
+
+	cmd := exec.CommandContext(ctx, binary)
+	cmd.Env = env
+	if input, piped := Stdin(ctx); piped {
+		cmd.Stdin = strings.NewReader(input)
+	}
+	cmd.WaitDelay = waitDelay
+	out, err = runWithUsage(ctx, cmd)
+	addUsage(ctx, buildUsage)
 	if err != nil {
 		logger.Debug("Execution failed: %v", err)
 		if exitError, ok := err.(*exec.ExitError); ok {
-			return "", fmt.Errorf("go-subprocess exited with code %d: %s", exitError.ExitCode(), string(out))
+			return "", exitErr("go-subprocess", exitError, string(out))
 		}
 		return "", fmt.Errorf("execution failed: %v", err)
 	}
@@ -156,37 +283,89 @@ func (g *GoSubprocessExecutor) Execute(ctx context.Context, code string, depende
 	return string(out), nil
 }
 
-func (s *SubprocessExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string) (string, error) {
+func (s *SubprocessExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
 	logger.Debug("Starting %s execution", s.config.ExecutorName)
 
+	options := NewOptions(opts...)
+	binDir, err := resolveRuntime(ctx, s.config.Language, options.RuntimeVersion)
+	if err != nil {
+		return "", err
+	}
+	binary, err := findBinary(s.config.Language, binDir, s.config.Binary)
+	if err != nil {
+		return "", err
+	}
+	if options.CheckOnly {
+		return checkOnHost(ctx, s.config.ExecutorName, s.config.Language, binary, code, withEnvVars(runtimeEnv(hostEnv(ctx), binDir), envVars))
+	}
+
 	// Install dependencies if needed and install command is available
 	if len(dependencies) > 0 && s.config.InstallCmd != nil {
-		logger.Debug("Installing dependencies: %v", dependencies)
-		if err := s.installDependencies(ctx, dependencies); err != nil {
-			return "", fmt.Errorf("failed to install dependencies: %v", err)
+		logger.InfoContext(ctx, "Installing %s dependencies: %s", s.config.ExecutorName, strings.Join(dependencies, ", "))
+		err := installPhase(ctx, s.config.ExecutorName, func(ctx context.Context) error {
+			return s.installDependencies(ctx, binDir, dependencies)
+		})
+		if err != nil {
+			return "", err
 		}
 	} else if len(dependencies) > 0 && s.config.InstallCmd == nil {
-		logger.Debug("Skipping dependency installation for %s (not supported in subprocess mode)", s.config.ExecutorName)
+		logger.WarnContext(ctx, "Ignoring dependencies for %s: installation is not supported in subprocess mode", s.config.ExecutorName)
 	}
 
 	// Execute the code
 	logger.Verbose("Executing %s code in subprocess", s.config.ExecutorName)
 	logger.Debug("Code to execute:\n%s", code)
 
-	cmd := exec.CommandContext(ctx, s.config.Binary)
+	scratch := options.ScratchDir
+	if scratch == "" {
+		dir, err := os.MkdirTemp("", "mcp-executor-workspace-*")
+		if err != nil {
+			return "", fmt.Errorf("failed to create workspace: %v", err)
+		}
+		defer func() { _ = os.RemoveAll(dir) }()
+		if err := grantRunAs(ctx, dir); err != nil {
+			return "", err
+		}
+		scratch = dir
+	}
+
+	cmd := exec.CommandContext(ctx, binary)
+	var restricted []string // Variables of read-only executions, taking precedence
+	if options.ReadOnly {
+		var cleanup func()
+		if cmd, restricted, cleanup, err = readOnlyCommand(ctx, s.config.Language, binary); err != nil {
+			return "", err
+		}
+		defer cleanup()
+	}
 	cmd.Stdin = strings.NewReader(code)
+	if input, piped := Stdin(ctx); piped && !options.ReadOnly {
+		// The code runs from a file, as stdin carries the input
+		file, cleanup, err := writeCodeFile(ctx, s.config.Language, code)
+		if err != nil {
+			return "", err
+		}
+		defer cleanup()
+		cmd.Args = append(cmd.Args, file)
+		cmd.Stdin = strings.NewReader(input)
+	}
+	cmd.Dir = scratch
 
-	// Set environment variables
-	cmd.Env = os.Environ() // Start with current environment
+	// Set environment variables, confining HOME and TMPDIR to the scratch
+	// directory unless the call sets them
+	cmd.Env = runtimeEnv(hostEnv(ctx), binDir) // Start with the passed-through server environment
+	cmd.Env = append(cmd.Env, "HOME="+scratch, "TMPDIR="+scratch)
 	for key, value := range envVars {
 		cmd.Env = append(cmd.Env, key+"="+value)
 	}
+	cmd.Env = append(cmd.Env, restricted...)
 
-	out, err := cmd.CombinedOutput()
+	cmd.WaitDelay = waitDelay
+	out, err := runWithUsage(ctx, cmd)
 	if err != nil {
 		logger.Debug("Execution failed: %v", err)
 		if exitError, ok := err.(*exec.ExitError); ok {
-			return "", fmt.Errorf("%s exited with code %d: %s", s.config.ExecutorName, exitError.ExitCode(), string(out))
+			return "", exitErr(s.config.ExecutorName, exitError, string(out))
 		}
 		return "", fmt.Errorf("execution failed: %v", err)
 	}
@@ -195,17 +374,26 @@ func (s *SubprocessExecutor) Execute(ctx context.Context, code string, dependenc
 	return string(out), nil
 }
 
-func (s *SubprocessExecutor) installDependencies(ctx context.Context, dependencies []string) error {
-	args := append(s.config.InstallCmd, dependencies...)
+// installDependencies runs the install command with the toolchain of binDir,
+// the passed-through server environment and the user and process group of the
+// code.
+func (s *SubprocessExecutor) installDependencies(ctx context.Context, binDir string, dependencies []string) error {
+	// Dependencies follow "--", so that none is parsed as an option
+	args := append(append(slices.Clone(s.config.InstallCmd), "--"), dependencies...)
 	logger.Verbose("Running: %s", strings.Join(args, " "))
 
 	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
-	out, err := cmd.CombinedOutput()
+	cmd.Env = runtimeEnv(hostEnv(ctx), binDir)
+	cmd.WaitDelay = waitDelay
+	out, err := runWithUsage(ctx, cmd)
 	if err != nil {
-		logger.Debug("Dependency installation failed: %v\nOutput: %s", err, string(out))
-		return fmt.Errorf("failed to install dependencies: %v", err)
+		logger.ErrorContext(ctx, "Dependency installation failed: %v\nOutput: %s", err, string(out))
+		if ctx.Err() != nil {
+			return err
+		}
+		return newInstallError(s.config.ExecutorName, s.config.Language, string(out))
 	}
 
-	logger.Debug("Dependencies installed successfully")
+	logger.InfoContext(ctx, "Dependencies installed successfully")
 	return nil
 }
@@ -2,6 +2,7 @@ package executor
 
 import (
 	"context"
+	"os"
 	"strings"
 	"testing"
 )
@@ -154,3 +155,29 @@ func TestSubprocessBashExecutor_SkipsDependencies(t *testing.T) {
 		t.Errorf("Expected output to contain 'test', got: %q", result)
 	}
 }
+
+func TestSubprocessExecutor_ScratchDir(t *testing.T) {
+	ctx := context.Background()
+	script := `echo "$PWD"; echo "$HOME"; echo "$TMPDIR"; echo "$MCP_WORKSPACE"`
+
+	output, err := NewSubprocessBashExecutor().Execute(ctx, script, nil, nil)
+	if err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 3 || lines[0] != lines[1] || lines[1] != lines[2] || !strings.Contains(lines[0], "mcp-executor-workspace-") {
+		t.Fatalf("Execute() = %q, want the working directory, HOME and TMPDIR in one temporary directory", output)
+	}
+	if _, err := os.Stat(lines[0]); !os.IsNotExist(err) {
+		t.Errorf("The scratch directory %s should be removed after the execution", lines[0])
+	}
+
+	output, err = NewSandboxEnvExecutor(NewSubprocessBashExecutor(), "subprocess").Execute(ctx, script, nil, map[string]string{"HOME": "/custom"})
+	if err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+	lines = strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 4 || lines[0] != lines[2] || lines[0] != lines[3] || lines[1] != "/custom" {
+		t.Errorf("Execute() = %q, want the MCP_WORKSPACE as working directory and TMPDIR, and the HOME of the call", output)
+	}
+}
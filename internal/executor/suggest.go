@@ -0,0 +1,92 @@
+// Package executor suggests the packages meant by a dependency that could not
+// be installed: the package providing an import name such as cv2 or bs4, or a
+// known package whose name is a typo away, from the import tables.
+package executor
+
+import (
+	"slices"
+	"strings"
+)
+
+// maxSuggestions bounds the packages suggested for a dependency.
+const maxSuggestions = 3
+
+// suggestPackages returns the packages of language likely meant by the
+// dependency pkg, which could not be installed: the package providing pkg when
+// it is an import name, then the known packages closest to its name. A known
+// package, e.g. requested at a version that does not exist, gets none.
+func suggestPackages(language, pkg string) []string {
+	table := importPackages[language]
+	name := packageName(language, pkg)
+	if name == "" {
+		return nil
+	}
+	var suggestions []string
+	known := make(map[string]bool, len(table))
+	for module, provider := range table {
+		provider = packageName(language, provider)
+		known[provider] = true
+		if packageName(language, module) == name && provider != name {
+			suggestions = append(suggestions, provider)
+		}
+	}
+	if known[name] {
+		return nil
+	}
+
+	// Names of four letters or less are a single typo away from too many others
+	maxDistance := 1
+	if len(name) > 4 {
+		maxDistance = 2
+	}
+	type candidate struct {
+		name     string
+		distance int
+	}
+	var close []candidate
+	for provider := range known {
+		if distance := editDistance(name, provider); distance <= maxDistance && !slices.Contains(suggestions, provider) {
+			close = append(close, candidate{provider, distance})
+		}
+	}
+	slices.SortFunc(close, func(a, b candidate) int {
+		if a.distance != b.distance {
+			return a.distance - b.distance
+		}
+		return strings.Compare(a.name, b.name)
+	})
+	for _, c := range close {
+		suggestions = append(suggestions, c.name)
+	}
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	return suggestions
+}
+
+// editDistance returns the Damerau-Levenshtein distance of a and b: the
+// insertions, deletions, substitutions and transpositions of adjacent bytes
+// turning a into b.
+func editDistance(a, b string) int {
+	previous2 := make([]int, len(b)+1)
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				current[j] = min(current[j], previous2[j-2]+1)
+			}
+		}
+		previous2, previous, current = previous, current, previous2
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSuggestPackages(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		pkg      string
+		want     []string
+	}{
+		{"import name", "python", "cv2", []string{"opencv-python"}},
+		{"import name with version", "python", "bs4==0.0.2", []string{"beautifulsoup4"}},
+		{"import name with other case", "python", "PIL", []string{"pillow"}},
+		{"import name of a differently named package", "python", "sklearn", []string{"scikit-learn"}},
+		{"typo", "python", "reqeusts", []string{"requests"}},
+		{"typo with separator", "python", "python_dateutl", []string{"python-dateutil"}},
+		{"npm typo", "typescript", "loadsh@4", []string{"lodash"}},
+		{"npm scoped typo", "typescript", "@anthropic-ai/skd", []string{"@anthropic-ai/sdk"}},
+		{"known package", "python", "requests==99.0", nil},
+		{"known npm package", "typescript", "lodash@99", nil},
+		{"unknown package", "python", "mcp-executor-no-such-module", nil},
+		{"short names need close matches", "python", "trch", []string{"torch"}},
+		{"language without table", "bash", "curll", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suggestPackages(tt.language, tt.pkg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("suggestPackages(%q, %q) = %q, want %q", tt.language, tt.pkg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"requests", "requests", 0},
+		{"reqeusts", "requests", 1},
+		{"request", "requests", 1},
+		{"numpi", "numpy", 1},
+		{"", "abc", 3},
+		{"pandas", "polars", 4},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNewInstallError_Suggestions(t *testing.T) {
+	err := newInstallError("python", "python", "ERROR: Could not find a version that satisfies the requirement cv2 (from versions: none)\nERROR: No matching distribution found for cv2")
+	if !reflect.DeepEqual(err.Suggestions, []string{"opencv-python"}) || !strings.Contains(err.Error(), "\ndid you mean \"opencv-python\"?\n") {
+		t.Errorf("newInstallError() = %q with suggestions %q, want opencv-python suggested", err.Error(), err.Suggestions)
+	}
+}
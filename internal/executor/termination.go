@@ -0,0 +1,49 @@
+// Package executor reports executions stopped before they finished, keeping
+// the output they produced and the reason they were stopped.
+package executor
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+)
+
+// Reasons of a TerminationError.
+const (
+	TerminationTimeout = "timeout" // The execution exceeded its timeout
+	TerminationOOM     = "oom"     // The container ran out of memory
+	TerminationSignal  = "signal"  // The process was killed by a signal
+	TerminationDisk    = "disk"    // The execution exceeded its disk quota
+)
+
+// TerminationError reports an execution stopped before it finished.
+type TerminationError struct {
+	Reason  string // TerminationTimeout, TerminationOOM, TerminationSignal or TerminationDisk
+	Signal  string // Signal that stopped the process, when known, e.g. "killed"
+	Message string // Description of the termination
+	Output  string // Output produced before the termination
+}
+
+func (e *TerminationError) Error() string {
+	if e.Output == "" {
+		return e.Message
+	}
+	return e.Message + ": " + e.Output
+}
+
+// exitErr describes a failed host process, as a TerminationError when it was
+// killed by a signal and as a CompileError when the code did not compile.
+func exitErr(name string, exitError *exec.ExitError, output string) error {
+	if status, ok := exitError.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return &TerminationError{
+			Reason:  TerminationSignal,
+			Signal:  status.Signal().String(),
+			Message: fmt.Sprintf("%s was killed by signal %s", name, status.Signal()),
+			Output:  output,
+		}
+	}
+	if compiled := compileError(name, output); compiled != nil {
+		return compiled
+	}
+	return NewExecutionError(ErrorRuntime, "%s exited with code %d: %s", name, exitError.ExitCode(), output)
+}
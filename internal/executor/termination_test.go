@@ -0,0 +1,57 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSubprocessExecutor_Signal(t *testing.T) {
+	_, err := NewSubprocessBashExecutor().Execute(context.Background(), "echo before; kill -TERM $$", nil, nil)
+
+	var terminated *TerminationError
+	if !errors.As(err, &terminated) {
+		t.Fatalf("Execute() error = %v, want *TerminationError", err)
+	}
+	if terminated.Reason != TerminationSignal || terminated.Signal != "terminated" || terminated.Output != "before\n" {
+		t.Errorf("TerminationError = %+v, want a SIGTERM after the partial output", terminated)
+	}
+	if want := "bash-subprocess was killed by signal terminated: before\n"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestDockerExecutor_ExitErr(t *testing.T) {
+	executor := NewPythonExecutor(WithResourceLimits("64m", ""))
+	tests := []struct {
+		name       string
+		code       int
+		usage      Usage
+		wantReason string
+		wantSignal string
+	}{
+		{"out of memory", 137, Usage{OOMKilled: true}, TerminationOOM, "killed"},
+		{"killed", 137, Usage{}, TerminationSignal, "killed"},
+		{"interrupted", 130, Usage{}, TerminationSignal, "interrupt"},
+		{"failed", 1, Usage{}, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := executor.exitErr(tt.code, tt.usage, "stdout\n", "stderr\n")
+			var terminated *TerminationError
+			if !errors.As(err, &terminated) {
+				if tt.wantReason != "" {
+					t.Fatalf("exitErr() = %v, want a %s termination", err, tt.wantReason)
+				}
+				if err.Error() != "python exited with code 1: stderr\n" {
+					t.Errorf("exitErr() = %q", err.Error())
+				}
+				return
+			}
+			if terminated.Reason != tt.wantReason || terminated.Signal != tt.wantSignal || terminated.Output != "stdout\nstderr\n" {
+				t.Errorf("TerminationError = %+v, want reason %q and signal %q with the output so far", terminated, tt.wantReason, tt.wantSignal)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Package executor provides an executor decorator setting the time zone and
+// locale of executions and faking their clock with libfaketime, so that
+// date-sensitive code behaves the same on every host.
+package executor
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+)
+
+// TimeLocale holds the time zone and locale of executions and the libfaketime
+// library faking their clock.
+type TimeLocale struct {
+	Timezone string // IANA time zone set as TZ, e.g. "Europe/Berlin"; empty keeps the environment's
+	Locale   string // Locale set as LANG and LC_ALL, e.g. "en_US.UTF-8"; empty keeps the environment's
+
+	// FakeTimeLibrary is the path of libfaketime in the execution
+	// environment, preloaded into executions with a fake time. Fake times are
+	// refused when empty.
+	FakeTimeLibrary string
+}
+
+var (
+	timezoneName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_+-]*(/[A-Za-z0-9_+-]+){0,2}$`)
+	localeName   = regexp.MustCompile(`^(C|POSIX|[a-z]{2,3}(_[A-Z]{2})?)(\.[A-Za-z0-9-]+)?(@[a-z]+)?$`)
+)
+
+// CheckTimezone reports why name is not a time zone name, such as UTC or
+// America/New_York.
+func CheckTimezone(name string) error {
+	if !timezoneName.MatchString(name) {
+		return NewExecutionError(ErrorPolicyViolation, "invalid timezone %q: expected an IANA time zone name such as UTC or Europe/Berlin", name)
+	}
+	return nil
+}
+
+// CheckLocale reports why name is not a locale name, such as C.UTF-8 or
+// de_DE.UTF-8.
+func CheckLocale(name string) error {
+	if !localeName.MatchString(name) {
+		return NewExecutionError(ErrorPolicyViolation, "invalid locale %q: expected a locale name such as C.UTF-8 or en_US.UTF-8", name)
+	}
+	return nil
+}
+
+// TimeLocaleExecutor sets the time zone and locale requested by each call, or
+// the defaults unless the call's environment sets them, and starts the clock
+// of calls with a fake time at that time.
+type TimeLocaleExecutor struct {
+	executor Executor
+	defaults TimeLocale
+}
+
+// NewTimeLocaleExecutor wraps exec, applying defaults to the calls that
+// request no time zone or locale.
+func NewTimeLocaleExecutor(exec Executor, defaults TimeLocale) *TimeLocaleExecutor {
+	return &TimeLocaleExecutor{executor: exec, defaults: defaults}
+}
+
+func (t *TimeLocaleExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	options := NewOptions(opts...)
+	env := make(map[string]string, len(envVars)+4)
+	for key, value := range envVars {
+		env[key] = value
+	}
+
+	switch {
+	case options.Timezone != "":
+		env["TZ"] = options.Timezone
+	case env["TZ"] == "" && t.defaults.Timezone != "":
+		env["TZ"] = t.defaults.Timezone
+	}
+	switch {
+	case options.Locale != "":
+		env["LANG"], env["LC_ALL"] = options.Locale, options.Locale
+	case env["LANG"] == "" && env["LC_ALL"] == "" && t.defaults.Locale != "":
+		env["LANG"], env["LC_ALL"] = t.defaults.Locale, t.defaults.Locale
+	}
+
+	if !options.FakeTime.IsZero() {
+		if t.defaults.FakeTimeLibrary == "" {
+			return "", NewExecutionError(ErrorPolicyViolation, "fake_time is not available: the server has no execution.faketime_library configured")
+		}
+		// The clock starts at the fake time and runs from there; monotonic
+		// clocks keep the real time, so that timeouts and sleeps still work
+		preload := t.defaults.FakeTimeLibrary
+		if existing := env["LD_PRELOAD"]; existing != "" {
+			preload += ":" + existing
+		}
+		env["LD_PRELOAD"] = preload
+		env["FAKETIME"] = "@" + strconv.FormatInt(options.FakeTime.Unix(), 10)
+		env["FAKETIME_FMT"] = "%s"
+		env["FAKETIME_DONT_FAKE_MONOTONIC"] = "1"
+	}
+	return t.executor.Execute(ctx, code, dependencies, env, opts...)
+}
@@ -0,0 +1,107 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTimeLocaleExecutor(t *testing.T) {
+	defaults := TimeLocale{Timezone: "UTC", Locale: "C.UTF-8", FakeTimeLibrary: "/usr/lib/faketime/libfaketime.so.1"}
+	fakeTime := time.Date(2024, 2, 29, 23, 59, 50, 0, time.UTC)
+	tests := []struct {
+		name     string
+		defaults TimeLocale
+		env      map[string]string
+		opts     []Option
+		want     map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "defaults",
+			defaults: defaults,
+			want:     map[string]string{"TZ": "UTC", "LANG": "C.UTF-8", "LC_ALL": "C.UTF-8"},
+		},
+		{
+			name:     "no defaults",
+			defaults: TimeLocale{},
+			env:      map[string]string{"DEBUG": "1"},
+			want:     map[string]string{"DEBUG": "1"},
+		},
+		{
+			name:     "call environment",
+			defaults: defaults,
+			env:      map[string]string{"TZ": "Asia/Tokyo", "LANG": "ja_JP.UTF-8"},
+			want:     map[string]string{"TZ": "Asia/Tokyo", "LANG": "ja_JP.UTF-8"},
+		},
+		{
+			name:     "call arguments",
+			defaults: defaults,
+			env:      map[string]string{"TZ": "Asia/Tokyo"},
+			opts:     []Option{WithTimezone("Europe/Berlin"), WithLocale("de_DE.UTF-8")},
+			want:     map[string]string{"TZ": "Europe/Berlin", "LANG": "de_DE.UTF-8", "LC_ALL": "de_DE.UTF-8"},
+		},
+		{
+			name:     "fake time",
+			defaults: TimeLocale{FakeTimeLibrary: defaults.FakeTimeLibrary},
+			env:      map[string]string{"LD_PRELOAD": "/lib/other.so"},
+			opts:     []Option{WithFakeTime(fakeTime)},
+			want: map[string]string{
+				"LD_PRELOAD":                   "/usr/lib/faketime/libfaketime.so.1:/lib/other.so",
+				"FAKETIME":                     "@1709251190",
+				"FAKETIME_FMT":                 "%s",
+				"FAKETIME_DONT_FAKE_MONOTONIC": "1",
+			},
+		},
+		{
+			name:    "fake time without library",
+			opts:    []Option{WithFakeTime(fakeTime)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &envRecorder{}
+			_, err := NewTimeLocaleExecutor(recorder, tt.defaults).Execute(context.Background(), "", nil, tt.env, tt.opts...)
+			if tt.wantErr {
+				var execErr *ExecutionError
+				if !errors.As(err, &execErr) || execErr.Code != ErrorPolicyViolation {
+					t.Errorf("Execute() error = %v, want a policy violation", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() returned error: %v", err)
+			}
+			if !reflect.DeepEqual(recorder.env, tt.want) {
+				t.Errorf("env = %v, want %v", recorder.env, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckTimezoneAndLocale(t *testing.T) {
+	for _, name := range []string{"UTC", "Europe/Berlin", "America/Argentina/Buenos_Aires", "Etc/GMT+5"} {
+		if err := CheckTimezone(name); err != nil {
+			t.Errorf("CheckTimezone(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "../etc/passwd", "/etc/localtime", "Europe/Berlin\n"} {
+		if CheckTimezone(name) == nil {
+			t.Errorf("CheckTimezone(%q) accepted an invalid name", name)
+		}
+	}
+	for _, name := range []string{"C", "POSIX", "C.UTF-8", "en_US.UTF-8", "de_DE", "sr_RS@latin"} {
+		if err := CheckLocale(name); err != nil {
+			t.Errorf("CheckLocale(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "english", "en_US.UTF-8 x", "$(id)"} {
+		if CheckLocale(name) == nil {
+			t.Errorf("CheckLocale(%q) accepted an invalid name", name)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Package executor provides an executor decorator that enforces the operator's
+// default and maximum execution timeouts, and the install timeout bounding the
+// dependency installation phase of a call separately from its run phase.
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// TimeoutPolicy bounds how long a single execution may run.
+type TimeoutPolicy struct {
+	Default time.Duration // Applied when the call requests no timeout; zero means none
+	Max     time.Duration // Ceiling for requested timeouts; zero means unlimited
+	// Install bounds the dependency installation of calls that install
+	// dependencies, which then have it on top of their timeout to run the
+	// code; zero leaves installation within the timeout.
+	Install time.Duration
+}
+
+// TimeoutExecutor runs the wrapped executor under a deadline derived from the
+// per-call timeout option and the TimeoutPolicy.
+type TimeoutExecutor struct {
+	executor Executor
+	policy   TimeoutPolicy
+}
+
+// NewTimeoutExecutor wraps exec with the given policy. Per-call timeouts are
+// enforced even when the policy is empty.
+func NewTimeoutExecutor(exec Executor, policy TimeoutPolicy) Executor {
+	return &TimeoutExecutor{executor: exec, policy: policy}
+}
+
+// Effective returns the timeout for a call requesting requested (zero for the default).
+func (p TimeoutPolicy) Effective(requested time.Duration) (time.Duration, error) {
+	if requested < 0 {
+		return 0, NewExecutionError(ErrorPolicyViolation, "timeout must not be negative")
+	}
+	if requested == 0 {
+		requested = p.Default
+	}
+	if p.Max > 0 && requested > p.Max {
+		return 0, NewExecutionError(ErrorPolicyViolation, "requested timeout %s exceeds the maximum of %s allowed by the operator", requested, p.Max)
+	}
+	if p.Max > 0 && requested == 0 {
+		requested = p.Max
+	}
+	return requested, nil
+}
+
+func (t *TimeoutExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	timeout, err := t.policy.Effective(NewOptions(opts...).Timeout)
+	if err != nil {
+		return "", err
+	}
+	// Pass the effective timeout on, so wrapped executors see the applied limit
+	opts = append(opts, WithTimeout(timeout))
+	deadline := timeout
+	if t.policy.Install > 0 && (len(dependencies) > 0 || NewOptions(opts...).DependencyFile != "") {
+		ctx = withInstallTimeout(ctx, t.policy.Install)
+		if timeout > 0 {
+			deadline += t.policy.Install
+		}
+	}
+	if deadline == 0 {
+		return t.executor.Execute(ctx, code, dependencies, envVars, opts...)
+	}
+
+	logger.DebugContext(ctx, "Running execution with a %s timeout", timeout)
+	timeoutCtx, cancel := context.WithTimeout(ctx, deadline)
+	defer cancel()
+
+	output, err := t.executor.Execute(timeoutCtx, code, dependencies, envVars, opts...)
+	if err != nil && errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+		logger.WarnContext(ctx, "Execution timed out after %s", timeout)
+		timedOut := &TerminationError{
+			Reason:  TerminationTimeout,
+			Message: fmt.Sprintf("execution timed out after %s", timeout),
+			Output:  output,
+		}
+		// Keep the output and signal of the killed process
+		var terminated *TerminationError
+		if errors.As(err, &terminated) {
+			timedOut.Signal = terminated.Signal
+			timedOut.Output = terminated.Output
+		}
+		return output, timedOut
+	}
+	return output, err
+}
+
+type installTimeoutKey struct{}
+
+// withInstallTimeout returns a context whose dependency installation is
+// bounded by timeout.
+func withInstallTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, installTimeoutKey{}, timeout)
+}
+
+// installTimeout returns the install timeout of ctx, or zero.
+func installTimeout(ctx context.Context) time.Duration {
+	timeout, _ := ctx.Value(installTimeoutKey{}).(time.Duration)
+	return timeout
+}
+
+// installTimeoutError reports that the dependencies of name were not installed
+// within timeout.
+func installTimeoutError(name string, timeout time.Duration) error {
+	return NewExecutionError(ErrorInstallFailed, "install timeout: %s dependency installation did not finish within %s", name, timeout)
+}
+
+// installPhase runs install, the dependency installation of an execution of
+// name, under the install timeout of ctx. Failures are install errors, such as
+// the InstallError returned by install.
+func installPhase(ctx context.Context, name string, install func(context.Context) error) error {
+	installCtx := ctx
+	timeout := installTimeout(ctx)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		installCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	err := install(installCtx)
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() == nil && errors.Is(installCtx.Err(), context.DeadlineExceeded) {
+		logger.WarnContext(ctx, "Dependency installation exceeded the %s install timeout", timeout)
+		return installTimeoutError(name, timeout)
+	}
+	var failed *InstallError
+	if errors.As(err, &failed) {
+		return err
+	}
+	return NewExecutionError(ErrorInstallFailed, "failed to install dependencies: %v", err)
+}
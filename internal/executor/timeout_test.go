@@ -0,0 +1,159 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeoutPolicy_Effective(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    TimeoutPolicy
+		requested time.Duration
+		want      time.Duration
+		wantErr   string
+	}{
+		{"no policy", TimeoutPolicy{}, 0, 0, ""},
+		{"default applies", TimeoutPolicy{Default: 30 * time.Second}, 0, 30 * time.Second, ""},
+		{"requested overrides default", TimeoutPolicy{Default: 30 * time.Second}, time.Minute, time.Minute, ""},
+		{"max caps unset default", TimeoutPolicy{Max: 5 * time.Minute}, 0, 5 * time.Minute, ""},
+		{"within max", TimeoutPolicy{Max: 5 * time.Minute}, time.Minute, time.Minute, ""},
+		{"exceeds max", TimeoutPolicy{Max: 5 * time.Minute}, 10 * time.Minute, 0, "exceeds the maximum of 5m0s"},
+		{"negative", TimeoutPolicy{}, -time.Second, 0, "negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.policy.Effective(tt.requested)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Effective() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Effective() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Effective() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// blockingExecutor waits until its context is done.
+type blockingExecutor struct{}
+
+func (blockingExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestTimeoutExecutor(t *testing.T) {
+	exec := NewTimeoutExecutor(blockingExecutor{}, TimeoutPolicy{Default: 10 * time.Millisecond, Max: time.Second})
+
+	_, err := exec.Execute(context.Background(), "", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "timed out after 10ms") {
+		t.Errorf("Execute() error = %v, want timeout error", err)
+	}
+
+	_, err = exec.Execute(context.Background(), "", nil, nil, WithTimeout(time.Hour))
+	if err == nil || !strings.Contains(err.Error(), "exceeds the maximum") {
+		t.Errorf("Execute() error = %v, want maximum timeout error", err)
+	}
+
+	// Per-call timeouts apply even when the operator sets no policy.
+	unlimited := NewTimeoutExecutor(blockingExecutor{}, TimeoutPolicy{})
+	_, err = unlimited.Execute(context.Background(), "", nil, nil, WithTimeout(10*time.Millisecond))
+	if err == nil || !strings.Contains(err.Error(), "timed out after 10ms") {
+		t.Errorf("Execute() without policy error = %v, want timeout error", err)
+	}
+}
+
+func TestSubprocessExecutor_Timeout(t *testing.T) {
+	exec := NewTimeoutExecutor(NewSubprocessBashExecutor(), TimeoutPolicy{Default: 100 * time.Millisecond})
+
+	start := time.Now()
+	_, err := exec.Execute(context.Background(), "echo partial; sleep 5", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Execute() error = %v, want timeout error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Execution should be killed at the timeout, took %v", elapsed)
+	}
+
+	// The output produced before the timeout is kept
+	var terminated *TerminationError
+	if !errors.As(err, &terminated) {
+		t.Fatalf("Execute() error = %T, want *TerminationError", err)
+	}
+	if terminated.Reason != TerminationTimeout || terminated.Signal != "killed" || terminated.Output != "partial\n" {
+		t.Errorf("TerminationError = %+v, want a timeout killing the process after its partial output", terminated)
+	}
+}
+
+// installingExecutor installs its dependencies in installPhase, which takes
+// installFor, and returns the install timeout it saw.
+type installingExecutor struct {
+	installFor time.Duration
+}
+
+func (e installingExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	if len(dependencies) > 0 {
+		err := installPhase(ctx, "test", func(ctx context.Context) error {
+			select {
+			case <-time.After(e.installFor):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return installTimeout(ctx).String(), nil
+}
+
+func TestTimeoutExecutor_InstallTimeout(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       TimeoutPolicy
+		installFor   time.Duration
+		dependencies []string
+		want         string
+		wantErr      string
+	}{
+		{"stuck install", TimeoutPolicy{Default: 5 * time.Second, Install: 20 * time.Millisecond}, time.Hour, []string{"pkg"}, "", "install timeout: test dependency installation did not finish within 20ms"},
+		{"install does not use the run timeout", TimeoutPolicy{Default: 50 * time.Millisecond, Install: time.Second}, 100 * time.Millisecond, []string{"pkg"}, "1s", ""},
+		{"install within the timeout without install timeout", TimeoutPolicy{Default: 20 * time.Millisecond}, time.Hour, []string{"pkg"}, "", "timed out after 20ms"},
+		{"no dependencies", TimeoutPolicy{Default: time.Second, Install: time.Second}, 0, nil, "0s", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := NewTimeoutExecutor(installingExecutor{installFor: tt.installFor}, tt.policy)
+			start := time.Now()
+			got, err := exec.Execute(context.Background(), "", tt.dependencies, nil)
+			if elapsed := time.Since(start); elapsed > 3*time.Second {
+				t.Errorf("Execute() took %v, want the install to stop at its deadline", elapsed)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Execute() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Execute() = %q, %v, want install timeout %q", got, err, tt.want)
+			}
+		})
+	}
+
+	_, err := NewTimeoutExecutor(installingExecutor{installFor: time.Hour}, TimeoutPolicy{Install: 10 * time.Millisecond}).Execute(context.Background(), "", []string{"pkg"}, nil)
+	if code := ErrorCode(err); code != ErrorInstallFailed {
+		t.Errorf("ErrorCode() of an install timeout = %q, want %q", code, ErrorInstallFailed)
+	}
+}
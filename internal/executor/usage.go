@@ -0,0 +1,122 @@
+// Package executor provides the resource usage reported by executions: wall
+// time, CPU time and peak memory, from getrusage for host processes and from
+// the container's cgroup in Docker mode.
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Usage is the resource usage of one execution. Fields that could not be
+// measured are zero.
+type Usage struct {
+	WallTime time.Duration `json:"wall_time_ns"`
+	CPUTime  time.Duration `json:"cpu_time_ns"`   // User and system time
+	MaxRSS   int64         `json:"max_rss_bytes"` // Peak resident memory
+
+	OOMKilled bool `json:"oom_killed,omitempty"` // A process of the container was killed for running out of memory
+
+	// NetworkRx is the number of bytes the container received over the
+	// network, mostly dependency downloads. Host processes report zero.
+	NetworkRx int64 `json:"network_rx_bytes,omitempty"`
+}
+
+// String formats the usage for logs, e.g. "wall 1.2s, cpu 800ms, max rss 35.2 MiB".
+func (u Usage) String() string {
+	return fmt.Sprintf("wall %s, cpu %s, max rss %.1f MiB",
+		u.WallTime.Round(time.Millisecond), u.CPUTime.Round(time.Millisecond), float64(u.MaxRSS)/(1<<20))
+}
+
+type usageKey struct{}
+
+// WithUsage returns a context whose executions store their resource usage in usage.
+func WithUsage(ctx context.Context, usage *Usage) context.Context {
+	return context.WithValue(ctx, usageKey{}, usage)
+}
+
+// ReportedUsage returns the Usage of ctx set with WithUsage, or nil.
+func ReportedUsage(ctx context.Context) *Usage {
+	usage, _ := ctx.Value(usageKey{}).(*Usage)
+	return usage
+}
+
+// reportUsage stores usage in the context's Usage, if any.
+func reportUsage(ctx context.Context, usage Usage) {
+	if target, ok := ctx.Value(usageKey{}).(*Usage); ok {
+		*target = usage
+	}
+}
+
+// addUsage adds usage, measured in an earlier step of the execution, to the
+// usage stored in the context's Usage, if any.
+func addUsage(ctx context.Context, usage Usage) {
+	if target, ok := ctx.Value(usageKey{}).(*Usage); ok {
+		target.WallTime += usage.WallTime
+		target.CPUTime += usage.CPUTime
+		target.MaxRSS = max(target.MaxRSS, usage.MaxRSS)
+	}
+}
+
+// processUsage returns the usage of an exited host process, including the
+// children it waited for. state is nil when the process did not start.
+func processUsage(state *os.ProcessState, wall time.Duration) Usage {
+	usage := Usage{WallTime: wall}
+	if state == nil {
+		return usage
+	}
+	usage.CPUTime = state.UserTime() + state.SystemTime()
+	usage.MaxRSS = maxRSS(state)
+	return usage
+}
+
+// runWithUsage runs cmd like CombinedOutput in a process group of its own, see
+// runProcessGroup, and reports its resource usage.
+func runWithUsage(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	applyRunAs(ctx, cmd)
+	startedAt := time.Now()
+	out, err := runProcessGroup(ctx, cmd)
+	reportUsage(ctx, processUsage(cmd.ProcessState, time.Since(startedAt)))
+	return out, err
+}
+
+// usageMarker starts the line on which containers report their cgroup usage.
+const usageMarker = "MCP_EXECUTOR_USAGE"
+
+// containerUsageScript prints the peak memory (bytes), CPU time (microseconds)
+// and OOM kill count of the container's cgroup to stderr, reading the cgroup
+// v2 files and falling back to cgroup v1, followed by the bytes received on
+// the network interfaces other than loopback.
+const containerUsageScript = `mem=$(cat /sys/fs/cgroup/memory.peak 2>/dev/null || cat /sys/fs/cgroup/memory/memory.max_usage_in_bytes 2>/dev/null); ` +
+	`cpu=$(sed -n 's/^usage_usec //p' /sys/fs/cgroup/cpu.stat 2>/dev/null); ` +
+	`[ -n "$cpu" ] || cpu=$(( $(cat /sys/fs/cgroup/cpuacct/cpuacct.usage 2>/dev/null || echo 0) / 1000 )); ` +
+	`oom=$(cat /sys/fs/cgroup/memory.events /sys/fs/cgroup/memory/memory.oom_control 2>/dev/null | sed -n 's/^oom_kill //p'); ` +
+	`rx=0; for f in /sys/class/net/*/statistics/rx_bytes; do case $f in */lo/*) ;; *) rx=$((rx + $(cat $f 2>/dev/null || echo 0)));; esac; done; ` +
+	`printf '\n` + usageMarker + ` %s %s %s %s\n' "${mem:-0}" "${cpu:-0}" "${oom:-0}" "$rx" >&2`
+
+// splitContainerUsage removes the usage line printed by containerUsageScript
+// from stderr and returns the remaining output with the parsed usage.
+func splitContainerUsage(stderr string, wall time.Duration) (string, Usage) {
+	usage := Usage{WallTime: wall}
+	index := strings.LastIndex(stderr, "\n"+usageMarker+" ")
+	if index < 0 {
+		return stderr, usage
+	}
+	fields := strings.Fields(stderr[index+len(usageMarker)+2:])
+	if len(fields) >= 3 {
+		usage.MaxRSS, _ = strconv.ParseInt(fields[0], 10, 64)
+		if micros, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			usage.CPUTime = time.Duration(micros) * time.Microsecond
+		}
+		usage.OOMKilled = fields[2] != "0"
+	}
+	if len(fields) >= 4 {
+		usage.NetworkRx, _ = strconv.ParseInt(fields[3], 10, 64)
+	}
+	return stderr[:index], usage
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+// Package executor leaves the peak memory of host processes unmeasured on
+// systems without rusage.
+package executor
+
+import "os"
+
+// maxRSS returns zero: only Unix systems report the peak memory of processes.
+func maxRSS(state *os.ProcessState) int64 {
+	return 0
+}
@@ -0,0 +1,62 @@
+package executor
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitContainerUsage(t *testing.T) {
+	stderr, usage := splitContainerUsage("Traceback\nValueError\n\n"+usageMarker+" 52428800 1250000 0\n", 2*time.Second)
+	if stderr != "Traceback\nValueError\n" {
+		t.Errorf("stderr = %q, want the output without the usage line", stderr)
+	}
+	want := Usage{WallTime: 2 * time.Second, CPUTime: 1250 * time.Millisecond, MaxRSS: 50 << 20}
+	if usage != want {
+		t.Errorf("usage = %+v, want %+v", usage, want)
+	}
+	if got := usage.String(); got != "wall 2s, cpu 1.25s, max rss 50.0 MiB" {
+		t.Errorf("String() = %q", got)
+	}
+	if _, usage := splitContainerUsage("\n"+usageMarker+" 67108864 500 1 4096\n", time.Second); !usage.OOMKilled || usage.NetworkRx != 4096 {
+		t.Errorf("usage = %+v, want an OOM kill", usage)
+	}
+
+	// A killed container prints no usage line
+	stderr, usage = splitContainerUsage("partial output", time.Second)
+	if stderr != "partial output" || usage != (Usage{WallTime: time.Second}) {
+		t.Errorf("splitContainerUsage() = %q, %+v, want the output unchanged and the wall time only", stderr, usage)
+	}
+}
+
+func TestContainerUsageScript(t *testing.T) {
+	// Outside a container the cgroup files may be missing; the line is still printed
+	out, err := exec.Command("sh", "-c", containerUsageScript).CombinedOutput()
+	if err != nil {
+		t.Fatalf("containerUsageScript failed: %v: %s", err, out)
+	}
+	if _, usage := splitContainerUsage(string(out), time.Second); usage.WallTime != time.Second {
+		t.Errorf("usage = %+v", usage)
+	}
+	if !strings.Contains(string(out), "\n"+usageMarker+" ") {
+		t.Errorf("containerUsageScript output = %q, want a %s line", out, usageMarker)
+	}
+}
+
+func TestRunWithUsage(t *testing.T) {
+	var usage Usage
+	ctx := WithUsage(context.Background(), &usage)
+	if _, err := runWithUsage(ctx, exec.Command("sh", "-c", "i=0; while [ $i -lt 20000 ]; do i=$((i+1)); done")); err != nil {
+		t.Fatalf("runWithUsage() returned error: %v", err)
+	}
+	if usage.WallTime <= 0 || usage.MaxRSS <= 0 {
+		t.Errorf("usage = %+v, want wall time and max RSS", usage)
+	}
+
+	// Without a Usage in the context nothing is reported
+	if _, err := runWithUsage(context.Background(), exec.Command("true")); err != nil {
+		t.Fatalf("runWithUsage() returned error: %v", err)
+	}
+}
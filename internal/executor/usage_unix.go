@@ -0,0 +1,25 @@
+//go:build unix
+
+// Package executor reads the peak memory of exited host processes from their
+// rusage.
+package executor
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// maxRSS returns the peak resident memory in bytes of the exited process of
+// state and the children it waited for.
+func maxRSS(state *os.ProcessState) int64 {
+	rusage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return 0
+	}
+	// ru_maxrss is in bytes on macOS and in kilobytes elsewhere
+	if runtime.GOOS == "darwin" {
+		return int64(rusage.Maxrss)
+	}
+	return int64(rusage.Maxrss) * 1024
+}
@@ -0,0 +1,178 @@
+// Package executor runs Python code with uv, which installs dependencies into
+// cached ephemeral environments much faster than pip.
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// uvInstallCmd installs Python dependencies with uv in a Docker container,
+// bootstrapping uv with pip when the image does not ship it.
+var uvInstallCmd = []string{
+	"(command -v uv >/dev/null 2>&1 || python -m pip install --quiet uv)", "&&",
+	"uv", "pip", "install", "--system", "--break-system-packages", "--quiet",
+}
+
+// WithPythonInstaller selects the Python package installer: "uv" installs with
+// uv pip, anything else (including "venv", which only affects subprocess mode)
+// keeps pip.
+func WithPythonInstaller(installer string) DockerOption {
+	return func(c *ExecutorConfig) {
+		if installer == "uv" {
+			c.InstallCmd = uvInstallCmd
+			c.ManifestInstallCmd = append(append([]string{}, uvInstallCmd...), "-r", "requirements.txt")
+		}
+	}
+}
+
+// UVSubprocessExecutor runs Python code on the host with "uv run", installing the
+// requested modules into an ephemeral environment that leaves the host
+// site-packages untouched.
+type UVSubprocessExecutor struct {
+	python string
+}
+
+// NewSubprocessUVPythonExecutor lets uv choose the Python interpreter unless a
+// runtime version or a binary is requested.
+func NewSubprocessUVPythonExecutor(opts ...SubprocessOption) *UVSubprocessExecutor {
+	return &UVSubprocessExecutor{python: configuredBinary(opts)}
+}
+
+func (u *UVSubprocessExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	logger.Debug("Starting python-uv execution")
+
+	options := NewOptions(opts...)
+	dependencies, err := ValidateDependencies("python", dependencies)
+	if err != nil {
+		return "", err
+	}
+
+	binDir, err := resolveRuntime(ctx, "python", options.RuntimeVersion)
+	if err != nil {
+		return "", err
+	}
+	if options.CheckOnly {
+		// Checking needs no dependencies, so the host interpreter is used
+		python, err := findBinary("python", binDir, u.python)
+		if err != nil {
+			return "", err
+		}
+		return checkOnHost(ctx, "python-uv", "python", python, code, withEnvVars(runtimeEnv(hostEnv(ctx), binDir), envVars))
+	}
+	python := ""
+	if binDir != "" || u.python != "" {
+		if python, err = findBinary("python", binDir, u.python); err != nil {
+			return "", err
+		}
+	}
+	uv, err := exec.LookPath("uv")
+	if err != nil {
+		return "", fmt.Errorf("uv not found on system - please install uv to run Python with modules")
+	}
+
+	requirements := ""
+	if options.DependencyFile != "" {
+		if requirements, err = writeRequirements(options.DependencyFile); err != nil {
+			return "", err
+		}
+		defer os.RemoveAll(filepath.Dir(requirements))
+		if err := grantRunAs(ctx, filepath.Dir(requirements), requirements); err != nil {
+			return "", err
+		}
+		logger.InfoContext(ctx, "Installing python-uv dependencies from requirements.txt")
+	}
+
+	args := uvRunArgs(dependencies, requirements, python)
+	if len(dependencies) > 0 {
+		logger.InfoContext(ctx, "Installing python-uv dependencies: %s", strings.Join(dependencies, ", "))
+	}
+	logger.Verbose("Running: uv %s", strings.Join(args, " "))
+	logger.Debug("Code to execute:\n%s", code)
+
+	cmd := exec.CommandContext(ctx, uv, args...)
+	cmd.Stdin = strings.NewReader(code)
+	if input, piped := Stdin(ctx); piped {
+		// The code runs from a file, as stdin carries the input
+		file, cleanup, err := writeCodeFile(ctx, "python", code)
+		if err != nil {
+			return "", err
+		}
+		defer cleanup()
+		cmd.Args[len(cmd.Args)-1] = file
+		cmd.Stdin = strings.NewReader(input)
+	}
+
+	// Set environment variables
+	cmd.Env = runtimeEnv(hostEnv(ctx), binDir) // Start with the passed-through server environment
+	for key, value := range envVars {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+
+	cmd.WaitDelay = waitDelay
+	out, err := runWithUsage(ctx, cmd)
+	if err != nil {
+		logger.Debug("Execution failed: %v", err)
+		if exitError, ok := err.(*exec.ExitError); ok {
+			if (len(dependencies) > 0 || requirements != "") && uvInstallFailed(string(out)) {
+				return "", newInstallError("python-uv", "python", string(out))
+			}
+			return "", exitErr("python-uv", exitError, string(out))
+		}
+		return "", fmt.Errorf("execution failed: %v", err)
+	}
+
+	logger.Debug("Execution completed successfully, output length: %d bytes", len(out))
+	return string(out), nil
+}
+
+// uvInstallFailures are printed by uv when the dependencies of a script could
+// not be resolved or installed, before the script ran.
+var uvInstallFailures = []string{"× No solution found when resolving", "× Failed to "}
+
+// uvInstallFailed reports whether the output of uv run shows that the
+// dependencies were not installed.
+func uvInstallFailed(output string) bool {
+	return slices.ContainsFunc(uvInstallFailures, func(failure string) bool {
+		return strings.Contains(output, failure)
+	})
+}
+
+// uvRunArgs builds the "uv run" arguments executing a script read from stdin
+// with dependencies and the requirements file installed, using the python
+// interpreter when set.
+func uvRunArgs(dependencies []string, requirements, python string) []string {
+	args := []string{"run", "--no-project", "--quiet"}
+	if python != "" {
+		args = append(args, "--python", python)
+	}
+	if requirements != "" {
+		args = append(args, "--with-requirements", requirements)
+	}
+	for _, dependency := range dependencies {
+		args = append(args, "--with", dependency)
+	}
+	return append(args, "python", "-")
+}
+
+// writeRequirements writes a requirements.txt with content to a new temporary
+// directory and returns its path.
+func writeRequirements(content string) (string, error) {
+	dir, err := os.MkdirTemp("", "mcp-executor-requirements-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %v", err)
+	}
+	path := filepath.Join(dir, "requirements.txt")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to write requirements.txt: %v", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,37 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUVRunArgs(t *testing.T) {
+	tests := []struct {
+		name         string
+		dependencies []string
+		requirements string
+		python       string
+		want         string
+	}{
+		{"no dependencies", nil, "", "", "run --no-project --quiet python -"},
+		{"dependencies", []string{"requests", "rich==13.7.1"}, "", "", "run --no-project --quiet --with requests --with rich==13.7.1 python -"},
+		{"requirements file", nil, "/tmp/r/requirements.txt", "", "run --no-project --quiet --with-requirements /tmp/r/requirements.txt python -"},
+		{"runtime version", []string{"pandas"}, "", "/opt/python/3.12/bin/python3", "run --no-project --quiet --python /opt/python/3.12/bin/python3 --with pandas python -"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Join(uvRunArgs(tt.dependencies, tt.requirements, tt.python), " "); got != tt.want {
+				t.Errorf("uvRunArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithPythonInstaller(t *testing.T) {
+	if executor := NewPythonExecutor(WithPythonInstaller("uv")); !strings.Contains(strings.Join(executor.config.InstallCmd, " "), "uv pip install --system") {
+		t.Errorf("uv InstallCmd = %v", executor.config.InstallCmd)
+	}
+	if executor := NewPythonExecutor(WithPythonInstaller("pip")); executor.config.InstallCmd[2] != "pip" {
+		t.Errorf("pip InstallCmd = %v", executor.config.InstallCmd)
+	}
+}
@@ -0,0 +1,61 @@
+// Package executor provides an executor decorator that rejects oversized or
+// malformed code before it reaches a container or host process.
+package executor
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultMaxCodeSize is the default limit on the size of submitted code, in bytes.
+const DefaultMaxCodeSize = 1 << 20
+
+// ValidatingExecutor checks the code of every execution before passing it to
+// the wrapped executor.
+type ValidatingExecutor struct {
+	executor    Executor
+	maxCodeSize int
+}
+
+// NewValidatingExecutor wraps exec so that code larger than maxCodeSize bytes
+// (unlimited when zero), containing NUL bytes or not valid UTF-8 is rejected.
+func NewValidatingExecutor(exec Executor, maxCodeSize int) Executor {
+	return &ValidatingExecutor{executor: exec, maxCodeSize: maxCodeSize}
+}
+
+func (v *ValidatingExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	if err := ValidateCode(code, v.maxCodeSize); err != nil {
+		return "", err
+	}
+	return v.executor.Execute(ctx, code, dependencies, envVars, opts...)
+}
+
+// ValidateCode reports why code cannot be executed: it is larger than
+// maxCodeSize bytes (unlimited when zero), contains a NUL byte or is not valid
+// UTF-8. Positions are reported as 1-based lines.
+func ValidateCode(code string, maxCodeSize int) error {
+	if maxCodeSize > 0 && len(code) > maxCodeSize {
+		return NewExecutionError(ErrorPolicyViolation, "code is %d bytes, exceeding the maximum of %d bytes allowed by the operator", len(code), maxCodeSize)
+	}
+	if i := strings.IndexByte(code, 0); i >= 0 {
+		return NewExecutionError(ErrorPolicyViolation, "code contains a NUL byte on line %d: binary content cannot be executed", lineOf(code, i))
+	}
+	if !utf8.ValidString(code) {
+		i := 0
+		for i < len(code) {
+			r, size := utf8.DecodeRuneInString(code[i:])
+			if r == utf8.RuneError && size == 1 {
+				break
+			}
+			i += size
+		}
+		return NewExecutionError(ErrorPolicyViolation, "code is not valid UTF-8: invalid byte 0x%02x on line %d", code[i], lineOf(code, i))
+	}
+	return nil
+}
+
+// lineOf returns the 1-based line of the byte at offset in s.
+func lineOf(s string, offset int) int {
+	return strings.Count(s[:offset], "\n") + 1
+}
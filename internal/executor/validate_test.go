@@ -0,0 +1,58 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestValidateCode(t *testing.T) {
+	tests := []struct {
+		name        string
+		code        string
+		maxCodeSize int
+		wantErr     string
+	}{
+		{"valid code", "print('héllo')\n", 0, ""},
+		{"at the limit", "12345", 5, ""},
+		{"over the limit", "123456", 5, "code is 6 bytes, exceeding the maximum of 5 bytes"},
+		{"unlimited", strings.Repeat("x", DefaultMaxCodeSize+1), 0, ""},
+		{"NUL byte", "print(1)\nprint(\x00)", 0, "NUL byte on line 2"},
+		{"invalid UTF-8", "a\nb\nc\xff", 0, "invalid byte 0xff on line 3"},
+		{"truncated rune", "caf\xc3", 0, "invalid byte 0xc3 on line 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCode(tt.code, tt.maxCodeSize)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateCode() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateCode() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatingExecutor(t *testing.T) {
+	recorder := &envRecorder{}
+	exec := NewValidatingExecutor(recorder, 4)
+
+	if _, err := exec.Execute(context.Background(), "12345", nil, map[string]string{"A": "1"}); err == nil {
+		t.Fatal("Execute() should reject code over the limit")
+	}
+	if recorder.env != nil {
+		t.Error("Rejected code should not reach the wrapped executor")
+	}
+
+	if _, err := exec.Execute(context.Background(), "1234", nil, map[string]string{"A": "1"}); err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+	if recorder.env["A"] != "1" {
+		t.Error("Valid code should reach the wrapped executor")
+	}
+}
@@ -0,0 +1,141 @@
+// Package executor runs subprocess Python code in throwaway virtual environments,
+// so requested modules can be installed without touching the host site-packages.
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// VenvSubprocessExecutor runs Python code on the host. Executions requesting
+// modules get a virtual environment in a temporary directory, which is deleted
+// afterwards.
+type VenvSubprocessExecutor struct {
+	python string
+}
+
+func NewSubprocessVenvPythonExecutor(opts ...SubprocessOption) *VenvSubprocessExecutor {
+	return &VenvSubprocessExecutor{python: configuredBinary(opts)}
+}
+
+func (v *VenvSubprocessExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	logger.Debug("Starting python-venv execution")
+
+	options := NewOptions(opts...)
+
+	binDir, err := resolveRuntime(ctx, "python", options.RuntimeVersion)
+	if err != nil {
+		return "", err
+	}
+	python, err := findBinary("python", binDir, v.python)
+	if err != nil {
+		return "", err
+	}
+	if options.CheckOnly {
+		// Checking needs no dependencies, so no virtual environment is created
+		return checkOnHost(ctx, "python-venv", "python", python, code, withEnvVars(runtimeEnv(hostEnv(ctx), binDir), envVars))
+	}
+
+	modules, err := ValidateDependencies("python", dependencies)
+	if err != nil {
+		return "", err
+	}
+	if len(modules) > 0 || options.DependencyFile != "" {
+		venvDir, err := os.MkdirTemp("", "mcp-executor-venv-*")
+		if err != nil {
+			return "", fmt.Errorf("failed to create virtual environment directory: %v", err)
+		}
+		defer os.RemoveAll(venvDir)
+		if err := grantRunAs(ctx, venvDir); err != nil {
+			return "", err
+		}
+
+		var installArgs []string
+		if options.DependencyFile != "" {
+			requirements := filepath.Join(venvDir, "requirements.txt")
+			if err := os.WriteFile(requirements, []byte(options.DependencyFile), 0o600); err != nil {
+				return "", fmt.Errorf("failed to write requirements.txt: %v", err)
+			}
+			installArgs = append(installArgs, "-r", requirements)
+			logger.InfoContext(ctx, "Installing python-venv dependencies from requirements.txt")
+		}
+		if len(modules) > 0 {
+			// Modules follow "--", so that none is parsed as an option
+			installArgs = append(append(installArgs, "--"), modules...)
+			logger.InfoContext(ctx, "Installing python-venv dependencies: %s", strings.Join(modules, ", "))
+		}
+		err = installPhase(ctx, "python-venv", func(ctx context.Context) error {
+			return createVenv(ctx, python, venvDir, installArgs)
+		})
+		if err != nil {
+			return "", err
+		}
+		logger.InfoContext(ctx, "Dependencies installed successfully")
+		binDir = filepath.Join(venvDir, "bin")
+		python = filepath.Join(binDir, "python")
+	}
+
+	logger.Verbose("Executing python-venv code in subprocess")
+	logger.Debug("Code to execute:\n%s", code)
+
+	cmd := exec.CommandContext(ctx, python, "-")
+	cmd.Stdin = strings.NewReader(code)
+	if input, piped := Stdin(ctx); piped {
+		// The code runs from a file, as stdin carries the input
+		file, cleanup, err := writeCodeFile(ctx, "python", code)
+		if err != nil {
+			return "", err
+		}
+		defer cleanup()
+		cmd.Args[len(cmd.Args)-1] = file
+		cmd.Stdin = strings.NewReader(input)
+	}
+
+	// Set environment variables
+	cmd.Env = runtimeEnv(hostEnv(ctx), binDir) // Start with the passed-through server environment
+	for key, value := range envVars {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+
+	cmd.WaitDelay = waitDelay
+	out, err := runWithUsage(ctx, cmd)
+	if err != nil {
+		logger.Debug("Execution failed: %v", err)
+		if exitError, ok := err.(*exec.ExitError); ok {
+			return "", exitErr("python-venv", exitError, string(out))
+		}
+		return "", fmt.Errorf("execution failed: %v", err)
+	}
+
+	logger.Debug("Execution completed successfully, output length: %d bytes", len(out))
+	return string(out), nil
+}
+
+// createVenv creates a virtual environment in dir with python and runs pip
+// install with installArgs in it, with the passed-through server environment
+// and the user and process group of the code.
+func createVenv(ctx context.Context, python, dir string, installArgs []string) error {
+	for _, args := range [][]string{
+		{python, "-m", "venv", dir},
+		append([]string{filepath.Join(dir, "bin", "python"), "-m", "pip", "install", "--quiet", "--disable-pip-version-check"}, installArgs...),
+	} {
+		logger.Verbose("Running: %s", strings.Join(args, " "))
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Env = hostEnv(ctx)
+		cmd.WaitDelay = waitDelay
+		if out, err := runWithUsage(ctx, cmd); err != nil {
+			logger.ErrorContext(ctx, "Dependency installation failed: %v\nOutput: %s", err, string(out))
+			if ctx.Err() != nil {
+				return err
+			}
+			return newInstallError("python-venv", "python", string(out))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestVenvSubprocessExecutor_Execute(t *testing.T) {
+	executor := NewSubprocessVenvPythonExecutor()
+
+	out, err := executor.Execute(context.Background(), `import sys; print(sys.prefix == sys.base_prefix)`, nil, nil)
+	if err != nil {
+		t.Fatalf("Execute() without modules returned error: %v", err)
+	}
+	if strings.TrimSpace(out) != "True" {
+		t.Errorf("Execute() without modules should use the host interpreter, got %q", out)
+	}
+
+	// Keep pip offline so the installation fails fast after the venv is created.
+	t.Setenv("PIP_NO_INDEX", "1")
+	_, err = executor.Execute(context.Background(), `print("unreachable")`, nil, nil, WithDependencyFile("mcp-executor-no-such-module==1.0\n"))
+	if err == nil || !strings.Contains(err.Error(), `failed to install python-venv dependencies: package "mcp-executor-no-such-module==1.0"`) {
+		t.Errorf("Execute() with unavailable modules error = %v, want the requirement reported", err)
+	}
+}
@@ -0,0 +1,178 @@
+// Package executor provides named workspaces shared by the executions of an MCP
+// session, so that tools of different languages can hand files to each other,
+// and removes the workspaces that lie idle.
+package executor
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// SharedWorkspace is where Docker executions see the directory of a named workspace.
+const SharedWorkspace = "/workspace"
+
+// DefaultWorkspaceIdle is how long the workspaces of a session are kept
+// without executions using them when no idle timeout is configured.
+const DefaultWorkspaceIdle = 24 * time.Hour
+
+// sessionSalt is hashed with the session IDs, so that a later server run, whose
+// stdio session has the same ID, does not find the workspaces of an earlier one.
+var sessionSalt = rand.Text()
+
+// sessionTag matches the names of the directories of SessionWorkspaces.
+var sessionTag = regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+type sessionIDKey struct{}
+
+// WithSessionID returns a context whose executions belong to the MCP session id.
+func WithSessionID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, sessionIDKey{}, id)
+}
+
+// SessionID returns the MCP session ID of ctx, or an empty string.
+func SessionID(ctx context.Context) string {
+	id, _ := ctx.Value(sessionIDKey{}).(string)
+	return id
+}
+
+// DefaultWorkspaceRoot returns the directory holding the named workspaces when
+// the operator configures none.
+func DefaultWorkspaceRoot() string {
+	return filepath.Join(os.TempDir(), "mcp-executor-workspaces")
+}
+
+var workspaceName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// CheckWorkspaceName reports why name cannot name a workspace.
+func CheckWorkspaceName(name string) error {
+	if !workspaceName.MatchString(name) {
+		return NewExecutionError(ErrorPolicyViolation, "invalid workspace %q: use up to 64 letters, digits, '.', '_' or '-', starting with a letter or digit", name)
+	}
+	return nil
+}
+
+// SessionTag identifies the MCP session id in paths and container labels
+// without revealing it, which would let others join the session. Tags differ
+// between server runs.
+func SessionTag(id string) string {
+	sum := sha256.Sum256([]byte(sessionSalt + id))
+	return hex.EncodeToString(sum[:8])
+}
+
+// SessionWorkspaces returns the directory below root holding the workspaces of
+// the MCP session id. Session IDs are hashed, so they never form paths.
+func SessionWorkspaces(root, id string) string {
+	return filepath.Join(root, SessionTag(id))
+}
+
+// CollectWorkspaces removes the workspaces below root of the sessions that no
+// execution used for idle before now, including those left behind by earlier
+// server runs, and returns their directories.
+func CollectWorkspaces(root string, idle time.Duration, now time.Time) ([]string, error) {
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	var errs []error
+	for _, entry := range entries {
+		if !entry.IsDir() || !sessionTag.MatchString(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || now.Sub(info.ModTime()) < idle {
+			continue
+		}
+		dir := filepath.Join(root, entry.Name())
+		if err := os.RemoveAll(dir); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed = append(removed, dir)
+	}
+	return removed, errors.Join(errs...)
+}
+
+// WorkspaceExecutor resolves the named workspace requested by an execution to
+// a directory of its MCP session, created on first use and kept until the
+// session ends or lies idle, see CollectWorkspaces. The workspace of an
+// execution saving a snapshot is archived with it.
+type WorkspaceExecutor struct {
+	executor Executor
+	root     string
+}
+
+// NewWorkspaceExecutor wraps exec so that named workspaces live below root.
+func NewWorkspaceExecutor(exec Executor, root string) Executor {
+	return &WorkspaceExecutor{executor: exec, root: root}
+}
+
+func (w *WorkspaceExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...Option) (string, error) {
+	options := NewOptions(opts...)
+	var dir string
+	if options.Workspace != "" {
+		if err := CheckWorkspaceName(options.Workspace); err != nil {
+			return "", err
+		}
+		sessionDir := SessionWorkspaces(w.root, SessionID(ctx))
+		dir = filepath.Join(sessionDir, options.Workspace)
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return "", fmt.Errorf("failed to create workspace %s: %v", options.Workspace, err)
+		}
+		if err := grantRunAs(ctx, w.root, sessionDir, dir); err != nil {
+			return "", err
+		}
+		// The modification time of the session directory marks its last use
+		touch(sessionDir)
+		defer touch(sessionDir)
+		opts = append(opts, withWorkspaceDir(dir))
+	}
+
+	output, err := w.executor.Execute(ctx, code, dependencies, envVars, opts...)
+	if err == nil && options.Snapshot != "" {
+		err = w.archive(ClientID(ctx), options.Snapshot, dir)
+	}
+	return output, err
+}
+
+// archive saves dir, the workspace of an execution of client saved as the
+// snapshot name, at its SnapshotArchive. Without a workspace, the archive of an
+// earlier snapshot of the same name is removed.
+func (w *WorkspaceExecutor) archive(client, name, dir string) error {
+	path := SnapshotArchive(w.root, client, name)
+	if dir == "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove the workspace of snapshot %s: %v", name, err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to save the workspace of snapshot %s: %v", name, err)
+	}
+	if err := ArchiveWorkspace(dir, path); err != nil {
+		return fmt.Errorf("failed to save the workspace of snapshot %s: %v", name, err)
+	}
+	return nil
+}
+
+// touch sets the modification time of dir to now.
+func touch(dir string) {
+	now := time.Now()
+	if err := os.Chtimes(dir, now, now); err != nil {
+		logger.Debug("Failed to mark workspaces %s as used: %v", dir, err)
+	}
+}
@@ -0,0 +1,129 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorkspaceExecutor(t *testing.T) {
+	root := t.TempDir()
+	recorder := &envRecorder{}
+	exec := NewWorkspaceExecutor(NewSandboxEnvExecutor(recorder, "subprocess"), root)
+
+	// A file written by one execution is seen by the next one of the same session
+	run := func(ctx context.Context, name string) string {
+		t.Helper()
+		if _, err := exec.Execute(ctx, "", nil, nil, WithWorkspace(name)); err != nil {
+			t.Fatalf("Execute() returned error: %v", err)
+		}
+		return recorder.env[WorkspaceEnv]
+	}
+	session := WithSessionID(context.Background(), "session-1")
+	dir := run(session, "pipeline")
+	if want := filepath.Join(SessionWorkspaces(root, "session-1"), "pipeline"); dir != want {
+		t.Fatalf("%s = %q, want %q", WorkspaceEnv, dir, want)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "data.csv"), []byte("a,b\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if again := run(session, "pipeline"); again != dir {
+		t.Errorf("Second execution got workspace %q, want %q", again, dir)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "data.csv")); err != nil {
+		t.Errorf("Named workspace should persist between executions: %v", err)
+	}
+
+	if other := run(WithSessionID(context.Background(), "session-2"), "pipeline"); other == dir {
+		t.Error("Workspaces of different sessions should not be shared")
+	}
+
+	if _, err := exec.Execute(session, "", nil, nil, WithWorkspace("../escape")); err == nil || !strings.Contains(err.Error(), "invalid workspace") {
+		t.Errorf("Execute() error = %v, want an invalid workspace error", err)
+	}
+}
+
+func TestSandboxEnvExecutor_DockerWorkspace(t *testing.T) {
+	recorder := &envRecorder{}
+	exec := NewWorkspaceExecutor(NewSandboxEnvExecutor(recorder, "docker"), t.TempDir())
+	if _, err := exec.Execute(context.Background(), "", nil, nil, WithWorkspace("shared")); err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+	if recorder.env[WorkspaceEnv] != SharedWorkspace {
+		t.Errorf("%s = %q, want %q", WorkspaceEnv, recorder.env[WorkspaceEnv], SharedWorkspace)
+	}
+}
+
+func TestWorkspaceExecutor_Snapshot(t *testing.T) {
+	root := t.TempDir()
+	recorder := &envRecorder{}
+	exec := NewWorkspaceExecutor(NewSandboxEnvExecutor(recorder, "subprocess"), root)
+	session := WithClientID(WithSessionID(context.Background(), "session-1"), "key-1")
+
+	// The workspace of an execution saving a snapshot is archived with it
+	if _, err := exec.Execute(session, "", nil, nil, WithWorkspace("pipeline")); err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(recorder.env[WorkspaceEnv], "data.csv"), []byte("a,b\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := exec.Execute(session, "", nil, nil, WithWorkspace("pipeline"), WithSnapshot("pandas")); err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+	restored := t.TempDir()
+	if err := ExtractWorkspace(SnapshotArchive(root, "key-1", "pandas"), restored); err != nil {
+		t.Fatalf("ExtractWorkspace() returned error: %v", err)
+	}
+	if got, err := os.ReadFile(filepath.Join(restored, "data.csv")); err != nil || string(got) != "a,b\n" {
+		t.Errorf("Archived workspace file = %q (%v), want %q", got, err, "a,b\n")
+	}
+
+	// Saving the snapshot again without a workspace removes the archive
+	if _, err := exec.Execute(session, "", nil, nil, WithSnapshot("pandas")); err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+	if _, err := os.Stat(SnapshotArchive(root, "key-1", "pandas")); !os.IsNotExist(err) {
+		t.Errorf("Archive of a snapshot saved without workspace: error = %v, want not exist", err)
+	}
+}
+
+func TestCollectWorkspaces(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+	idle, used := SessionWorkspaces(root, "session-1"), SessionWorkspaces(root, "session-2")
+	for _, dir := range []string{filepath.Join(idle, "pipeline"), filepath.Join(used, "pipeline"), filepath.Join(root, "artifacts"), filepath.Join(root, "snapshots")} {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, dir := range []string{idle, used, filepath.Join(root, "artifacts"), filepath.Join(root, "snapshots")} {
+		if err := os.Chtimes(dir, now.Add(-2*time.Hour), now.Add(-2*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// An execution marks the workspaces of its session as used
+	exec := NewWorkspaceExecutor(&envRecorder{}, root)
+	if _, err := exec.Execute(WithSessionID(context.Background(), "session-2"), "", nil, nil, WithWorkspace("pipeline")); err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+
+	removed, err := CollectWorkspaces(root, time.Hour, now)
+	if err != nil {
+		t.Fatalf("CollectWorkspaces() returned error: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != idle {
+		t.Errorf("CollectWorkspaces() = %q, want only the idle session %q", removed, idle)
+	}
+	for _, dir := range []string{used, filepath.Join(root, "artifacts"), filepath.Join(root, "snapshots")} {
+		if _, err := os.Stat(dir); err != nil {
+			t.Errorf("%s should be kept: %v", dir, err)
+		}
+	}
+	if removed, err := CollectWorkspaces(filepath.Join(root, "missing"), time.Hour, now); err != nil || removed != nil {
+		t.Errorf("CollectWorkspaces() of a missing root = %q, %v, want nothing", removed, err)
+	}
+}
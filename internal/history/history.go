@@ -0,0 +1,132 @@
+// Package history keeps a bounded, in-memory record of recent executions
+// so their code and output can be re-read without re-running them.
+package history
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/ylchen07/mcp-executor/internal/executor"
+)
+
+// URIScheme is the MCP resource URI scheme used for stored executions.
+const URIScheme = "execution://"
+
+// ArtifactURIScheme is the MCP resource URI scheme of the reports written by
+// stored executions, followed by the execution ID and the report name.
+const ArtifactURIScheme = "artifact://"
+
+// OutputURIScheme is the MCP resource URI scheme of the full output of stored
+// executions whose tool result only held a preview, followed by the execution ID.
+const OutputURIScheme = "output://"
+
+// DefaultCapacity is the number of executions kept when no capacity is configured.
+const DefaultCapacity = 100
+
+// DefaultMaxInline is the size in bytes of the largest output returned in
+// full in tool results; longer output is previewed.
+const DefaultMaxInline = 64 << 10
+
+// Record describes a single completed execution.
+type Record struct {
+	ID        string        `json:"id"`
+	Tool      string        `json:"tool"`
+	Code      string        `json:"code"`
+	Output    string        `json:"output"`
+	Status    string        `json:"status"` // "success" or "error"
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration_ns"`
+
+	Usage *executor.Usage `json:"usage,omitempty"` // Resource usage of the last execution run for the call
+
+	Artifacts []executor.Artifact `json:"artifacts,omitempty"` // Reports written by the execution
+
+	Truncated bool `json:"truncated,omitempty"` // The tool result held a preview of Output, see OutputURI
+
+	Client  string `json:"client,omitempty"` // Client that made the call, the only one reading the record
+	Session string `json:"-"`                // MCP session that made the call, listing the record
+}
+
+// URI returns the resource URI of the record.
+func (r Record) URI() string {
+	return URIScheme + r.ID
+}
+
+// OutputURI returns the resource URI of the full output of the record.
+func (r Record) OutputURI() string {
+	return OutputURIScheme + r.ID
+}
+
+// ArtifactURI returns the resource URI of the report name of the record.
+func (r Record) ArtifactURI(name string) string {
+	return ArtifactURIScheme + r.ID + "/" + name
+}
+
+// Store is a fixed-capacity, concurrency-safe execution history.
+// When full, the oldest record is evicted.
+type Store struct {
+	mu       sync.RWMutex
+	capacity int
+	records  []Record // oldest first
+}
+
+// NewStore creates a Store holding up to capacity records (DefaultCapacity when <= 0).
+func NewStore(capacity int) *Store {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &Store{capacity: capacity}
+}
+
+// NewID returns a random execution identifier.
+func NewID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Add stores rec, assigning an ID if it has none, and returns the stored record
+// together with any records evicted to make room.
+func (s *Store) Add(rec Record) (Record, []Record) {
+	if rec.ID == "" {
+		rec.ID = NewID()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var evicted []Record
+	if overflow := len(s.records) + 1 - s.capacity; overflow > 0 {
+		evicted = append(evicted, s.records[:overflow]...)
+		s.records = append(s.records[:0:0], s.records[overflow:]...)
+	}
+	s.records = append(s.records, rec)
+	return rec, evicted
+}
+
+// Get returns the record with the given ID.
+func (s *Store) Get(id string) (Record, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, rec := range s.records {
+		if rec.ID == id {
+			return rec, true
+		}
+	}
+	return Record{}, false
+}
+
+// List returns all stored records, newest first.
+func (s *Store) List() []Record {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]Record, 0, len(s.records))
+	for i := len(s.records) - 1; i >= 0; i-- {
+		list = append(list, s.records[i])
+	}
+	return list
+}
@@ -0,0 +1,68 @@
+package history
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestStore_AddAndGet(t *testing.T) {
+	store := NewStore(10)
+
+	rec, evicted := store.Add(Record{Tool: "execute-python", Code: `print("hi")`, Output: "hi\n", Status: "success"})
+	if rec.ID == "" {
+		t.Fatal("Add() should assign an ID")
+	}
+	if len(evicted) != 0 {
+		t.Errorf("Add() evicted %d records from a non-full store", len(evicted))
+	}
+
+	got, ok := store.Get(rec.ID)
+	if !ok {
+		t.Fatalf("Get(%q) not found", rec.ID)
+	}
+	if got.Output != "hi\n" || got.Tool != "execute-python" {
+		t.Errorf("Get() = %+v, want stored record", got)
+	}
+
+	if _, ok := store.Get("missing"); ok {
+		t.Error("Get() should not find unknown IDs")
+	}
+
+	if rec.URI() != "execution://"+rec.ID {
+		t.Errorf("URI() = %q, want execution://%s", rec.URI(), rec.ID)
+	}
+}
+
+func TestStore_EvictsOldest(t *testing.T) {
+	store := NewStore(3)
+
+	var ids []string
+	for i := range 5 {
+		rec, evicted := store.Add(Record{ID: fmt.Sprintf("id-%d", i)})
+		ids = append(ids, rec.ID)
+		if i >= 3 {
+			if len(evicted) != 1 || evicted[0].ID != ids[i-3] {
+				t.Errorf("Add(#%d) evicted %v, want [%s]", i, evicted, ids[i-3])
+			}
+		}
+	}
+
+	list := store.List()
+	if len(list) != 3 {
+		t.Fatalf("List() returned %d records, want 3", len(list))
+	}
+	// Newest first
+	want := []string{"id-4", "id-3", "id-2"}
+	for i, rec := range list {
+		if rec.ID != want[i] {
+			t.Errorf("List()[%d].ID = %q, want %q", i, rec.ID, want[i])
+		}
+	}
+}
+
+func TestNewStore_DefaultCapacity(t *testing.T) {
+	store := NewStore(0)
+	if store.capacity != DefaultCapacity {
+		t.Errorf("capacity = %d, want %d", store.capacity, DefaultCapacity)
+	}
+}
@@ -0,0 +1,244 @@
+// Package httprequest sends HTTP requests in the sandbox: it builds the Bash
+// script sending a request with curl and parses the status, headers and body
+// of the response it reports.
+package httprequest
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Marker separates the output of curl from the response it reports.
+const Marker = "--- mcp-executor http-request ---"
+
+// MaxBodySize is the size of the largest response body returned; longer
+// bodies are truncated.
+const MaxBodySize = 1 << 20
+
+// maxRedirects is the number of redirects followed.
+const maxRedirects = 10
+
+// Methods lists the accepted request methods, the first being the default.
+var Methods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// Request is an HTTP request.
+type Request struct {
+	Method          string // One of Methods; empty sends a GET request
+	URL             string // http or https URL
+	Headers         map[string]string
+	Body            string
+	FollowRedirects bool
+}
+
+// Response is the response to a Request.
+type Response struct {
+	Status  int         `json:"status"`
+	URL     string      `json:"url"` // After redirects
+	Headers http.Header `json:"headers"`
+	Body    string      `json:"body"`
+
+	// BodyEncoding is "base64" for bodies that are not UTF-8 text, which are
+	// returned base64-encoded.
+	BodyEncoding string `json:"body_encoding,omitempty"`
+
+	Size      int  `json:"size_bytes"` // Of the whole body
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Script returns the Bash script sending request with curl. The script prints
+// Marker and the response, see Parse.
+func Script(request Request) (string, error) {
+	if request.Method == "" {
+		request.Method = Methods[0]
+	}
+	request.Method = strings.ToUpper(request.Method)
+	if !slices.Contains(Methods, request.Method) {
+		return "", fmt.Errorf("invalid method %q: expected one of %s", request.Method, strings.Join(Methods, ", "))
+	}
+	target, err := url.Parse(request.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return "", fmt.Errorf("invalid url %q: expected an http or https URL", request.URL)
+	}
+
+	// curl keeps an explicit method on redirects, so the methods it implies
+	// are left to it: a redirected POST continues as a GET, as in browsers
+	var args []string
+	switch {
+	case request.Method == "HEAD":
+		args = []string{"--head"}
+	case request.Method == "GET" && request.Body == "", request.Method == "POST" && request.Body != "":
+	default:
+		args = []string{"--request", request.Method}
+	}
+	if request.FollowRedirects {
+		args = append(args, "--location", "--max-redirs", strconv.Itoa(maxRedirects))
+	}
+	names := make([]string, 0, len(request.Headers))
+	for name := range request.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := request.Headers[name]
+		if name == "" || strings.ContainsAny(name, ": \t\r\n") || strings.ContainsAny(value, "\r\n") {
+			return "", fmt.Errorf("invalid header %q: names must be single tokens and values single lines", name)
+		}
+		args = append(args, "--header", shellQuote(name+": "+value))
+	}
+
+	// The body is embedded base64-encoded, so that it is sent byte for byte
+	writeBody := ""
+	if request.Body != "" {
+		writeBody = fmt.Sprintf("printf '%%s' '%s' | base64 -d > \"$request_dir/body.in\"", base64.StdEncoding.EncodeToString([]byte(request.Body)))
+		args = append(args, "--data-binary", `@"$request_dir/body.in"`)
+	}
+
+	return strings.NewReplacer(
+		"{{WRITE_BODY}}", writeBody,
+		"{{ARGS}}", strings.Join(args, " "),
+		"{{URL}}", shellQuote(request.URL),
+		"{{MARKER}}", Marker,
+		"{{MAX_BODY}}", strconv.Itoa(MaxBodySize),
+	).Replace(scriptTemplate), nil
+}
+
+// scriptTemplate sends the request with curl, installed first in images
+// without it, then prints the status line written by curl and the headers and
+// body, base64-encoded on a line each.
+const scriptTemplate = `set -e
+if ! command -v curl >/dev/null 2>&1; then
+  { apt-get update -qq && apt-get install -y -qq curl || apk add --quiet curl; } >/dev/null 2>&1 || { echo "curl is not installed and could not be installed" >&2; exit 127; }
+fi
+request_dir=$(mktemp -d)
+trap 'rm -rf "$request_dir"' EXIT
+{{WRITE_BODY}}
+written=$(curl --silent --show-error {{ARGS}} --dump-header "$request_dir/headers" --output "$request_dir/body" --write-out '%{http_code} %{url_effective}' -- {{URL}})
+touch "$request_dir/body"
+printf '\n%s\n%s\n' '{{MARKER}}' "$written"
+base64 < "$request_dir/headers" | tr -d '\n'; echo
+wc -c < "$request_dir/body" | tr -d ' '
+head -c {{MAX_BODY}} "$request_dir/body" | base64 | tr -d '\n'; echo
+`
+
+// shellQuote quotes s for the shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Parse returns the response reported in the output of the script and the
+// output printed before it, e.g. warnings of curl.
+func Parse(output string) (Response, string, error) {
+	index := strings.LastIndex(output, Marker)
+	if index < 0 {
+		return Response{}, "", fmt.Errorf("the request script reported no response")
+	}
+	lines := strings.Split(strings.TrimSpace(output[index+len(Marker):]), "\n")
+	if len(lines) < 3 {
+		return Response{}, "", fmt.Errorf("the request script reported an incomplete response")
+	}
+	for len(lines) < 4 {
+		lines = append(lines, "") // An empty body is an empty line
+	}
+
+	var response Response
+	status, effectiveURL, _ := strings.Cut(lines[0], " ")
+	var err error
+	if response.Status, err = strconv.Atoi(status); err != nil {
+		return Response{}, "", fmt.Errorf("invalid status %q", status)
+	}
+	response.URL = effectiveURL
+	headers, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil {
+		return Response{}, "", fmt.Errorf("failed to decode the headers: %v", err)
+	}
+	if response.Headers, err = parseHeaders(string(headers)); err != nil {
+		return Response{}, "", err
+	}
+	if response.Size, err = strconv.Atoi(strings.TrimSpace(lines[2])); err != nil {
+		return Response{}, "", fmt.Errorf("invalid body size %q", lines[2])
+	}
+	body, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil {
+		return Response{}, "", fmt.Errorf("failed to decode the body: %v", err)
+	}
+	response.Truncated = len(body) < response.Size
+	response.Body = string(body)
+	if !utf8.Valid(body) && !(response.Truncated && utf8.Valid(trimPartialRune(body))) {
+		response.Body = base64.StdEncoding.EncodeToString(body)
+		response.BodyEncoding = "base64"
+	}
+	return response, strings.TrimSuffix(output[:index], "\n"), nil
+}
+
+// trimPartialRune removes a UTF-8 sequence cut off at the end of data.
+func trimPartialRune(data []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			return data[:len(data)-i]
+		}
+	}
+	return data
+}
+
+// parseHeaders returns the headers of the last response in the headers
+// written by curl, which holds those of every redirect and interim response.
+func parseHeaders(dump string) (http.Header, error) {
+	var last string
+	for _, block := range strings.Split(strings.ReplaceAll(dump, "\r\n", "\n"), "\n\n") {
+		if strings.TrimSpace(block) != "" {
+			last = block
+		}
+	}
+	if last == "" {
+		return http.Header{}, nil
+	}
+	reader := textproto.NewReader(bufio.NewReader(strings.NewReader(last + "\n\n")))
+	if _, err := reader.ReadLine(); err != nil { // The status line
+		return nil, fmt.Errorf("failed to parse the headers: %v", err)
+	}
+	headers, err := reader.ReadMIMEHeader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the headers: %v", err)
+	}
+	return http.Header(headers), nil
+}
+
+// Summary returns the response as text: the status and URL, the headers
+// sorted by name and the body.
+func (r Response) Summary() string {
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "HTTP %d %s (%s)\n", r.Status, http.StatusText(r.Status), r.URL)
+	names := make([]string, 0, len(r.Headers))
+	for name := range r.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range r.Headers[name] {
+			fmt.Fprintf(&summary, "%s: %s\n", name, value)
+		}
+	}
+	summary.WriteString("\n")
+	switch {
+	case r.BodyEncoding == "base64":
+		fmt.Fprintf(&summary, "[%d bytes of binary data, base64-encoded in the structured content]\n", r.Size)
+	case r.Body != "":
+		summary.WriteString(r.Body)
+		if !strings.HasSuffix(r.Body, "\n") {
+			summary.WriteString("\n")
+		}
+	}
+	if r.Truncated {
+		fmt.Fprintf(&summary, "[body truncated to %d of %d bytes]\n", MaxBodySize, r.Size)
+	}
+	return summary.String()
+}
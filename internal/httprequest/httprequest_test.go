@@ -0,0 +1,109 @@
+package httprequest
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/ylchen07/mcp-executor/internal/executor"
+)
+
+func TestScript(t *testing.T) {
+	tests := []struct {
+		name    string
+		request Request
+		wantErr bool
+	}{
+		{"get", Request{URL: "https://example.com"}, false},
+		{"post", Request{Method: "post", URL: "http://localhost:8080/api", Headers: map[string]string{"Content-Type": "application/json"}, Body: `{"a": "it's"}`}, false},
+		{"unknown method", Request{Method: "TRACE", URL: "https://example.com"}, true},
+		{"file url", Request{URL: "file:///etc/passwd"}, true},
+		{"header injection", Request{URL: "https://example.com", Headers: map[string]string{"X-A": "1\r\nX-B: 2"}}, true},
+		{"invalid header name", Request{URL: "https://example.com", Headers: map[string]string{"X A": "1"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := Script(tt.request)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Script() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (strings.Contains(script, "{{") || !strings.Contains(script, Marker)) {
+				t.Errorf("Script() left placeholders or has no marker:\n%s", script)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	headers := "HTTP/1.1 301 Moved Permanently\r\nLocation: /b\r\n\r\nHTTP/2 200\r\ncontent-type: text/plain\r\nset-cookie: a=1\r\nset-cookie: b=2\r\n\r\n"
+	output := "curl warning\n\n" + Marker + "\n200 https://example.com/b\n" +
+		base64.StdEncoding.EncodeToString([]byte(headers)) + "\n5\n" + base64.StdEncoding.EncodeToString([]byte("hello")) + "\n"
+	response, curlOutput, err := Parse(output)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	if curlOutput != "curl warning\n" || response.Status != 200 || response.URL != "https://example.com/b" || response.Body != "hello" || response.Truncated {
+		t.Errorf("Parse() = %+v, output %q", response, curlOutput)
+	}
+	if response.Headers.Get("Location") != "" || len(response.Headers.Values("Set-Cookie")) != 2 {
+		t.Errorf("Headers = %v, want those of the last response", response.Headers)
+	}
+	want := "HTTP 200 OK (https://example.com/b)\nContent-Type: text/plain\nSet-Cookie: a=1\nSet-Cookie: b=2\n\nhello\n"
+	if summary := response.Summary(); summary != want {
+		t.Errorf("Summary() = %q, want %q", summary, want)
+	}
+
+	binary := Marker + "\n200 https://example.com/\n\n4\n" + base64.StdEncoding.EncodeToString([]byte{0xff, 0xfe, 0, 1}) + "\n"
+	if response, _, err := Parse(binary); err != nil || response.BodyEncoding != "base64" || response.Body != "//4AAQ==" {
+		t.Errorf("Parse() = %+v, %v, want a base64 body", response, err)
+	}
+
+	if _, _, err := Parse("curl: (6) Could not resolve host: example.invalid\n"); err == nil {
+		t.Error("Parse() without a response returned no error")
+	}
+}
+
+func TestScript_Run(t *testing.T) {
+	if _, err := exec.LookPath("curl"); err != nil {
+		t.Skip("curl not installed")
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/echo", http.StatusFound)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Method", r.Method)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(r.Header.Get("X-Token") + " " + string(body)))
+	}))
+	defer server.Close()
+
+	script, err := Script(Request{
+		Method:          "POST",
+		URL:             server.URL + "/redirect",
+		Headers:         map[string]string{"X-Token": "it's secret"},
+		Body:            "a\nb",
+		FollowRedirects: true,
+	})
+	if err != nil {
+		t.Fatalf("Script() returned error: %v", err)
+	}
+	output, err := executor.NewSubprocessBashExecutor().Execute(context.Background(), script, nil, nil)
+	if err != nil {
+		t.Fatalf("Execute() returned error: %v\n%s", err, output)
+	}
+	response, _, err := Parse(output)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v\n%s", err, output)
+	}
+	if response.Status != http.StatusCreated || response.URL != server.URL+"/echo" || response.Headers.Get("X-Method") != "GET" || response.Body != "it's secret " {
+		t.Errorf("response = %+v", response)
+	}
+}
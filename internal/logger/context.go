@@ -0,0 +1,57 @@
+// Package logger provides request-scoped logging: messages logged with a context
+// are also passed to the context's Sink, e.g. to forward them to the MCP client.
+package logger
+
+import (
+	"context"
+	"fmt"
+)
+
+// Log levels passed to a Sink. They match the MCP logging levels.
+const (
+	LevelDebug   = "debug"
+	LevelInfo    = "info"
+	LevelWarning = "warning"
+	LevelError   = "error"
+)
+
+// Sink receives the messages logged for one request.
+type Sink func(level, message string)
+
+type sinkKey struct{}
+
+// WithSink returns a context whose log messages are also passed to sink.
+func WithSink(ctx context.Context, sink Sink) context.Context {
+	return context.WithValue(ctx, sinkKey{}, sink)
+}
+
+// DebugContext logs like Debug and passes the message to the context's sink,
+// regardless of the verbose setting.
+func DebugContext(ctx context.Context, format string, args ...any) {
+	Debug(format, args...)
+	toSink(ctx, LevelDebug, format, args)
+}
+
+// InfoContext logs like Info and passes the message to the context's sink.
+func InfoContext(ctx context.Context, format string, args ...any) {
+	Info(format, args...)
+	toSink(ctx, LevelInfo, format, args)
+}
+
+// WarnContext logs a warning (always shown) and passes it to the context's sink.
+func WarnContext(ctx context.Context, format string, args ...any) {
+	logger.Printf("WARN: "+format, args...)
+	toSink(ctx, LevelWarning, format, args)
+}
+
+// ErrorContext logs like Error and passes the message to the context's sink.
+func ErrorContext(ctx context.Context, format string, args ...any) {
+	Error(format, args...)
+	toSink(ctx, LevelError, format, args)
+}
+
+func toSink(ctx context.Context, level, format string, args []any) {
+	if sink, ok := ctx.Value(sinkKey{}).(Sink); ok {
+		sink(level, fmt.Sprintf(format, args...))
+	}
+}
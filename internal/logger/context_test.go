@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContextSink(t *testing.T) {
+	originalState := verboseEnabled
+	defer func() {
+		verboseEnabled = originalState
+	}()
+	SetVerbose(false)
+
+	var got []string
+	ctx := WithSink(context.Background(), func(level, message string) {
+		got = append(got, level+": "+message)
+	})
+
+	DebugContext(ctx, "installing %s", "requests")
+	InfoContext(ctx, "installed %d packages", 1)
+	WarnContext(ctx, "output truncated")
+	ErrorContext(ctx, "failed: %v", "exit 1")
+	InfoContext(context.Background(), "not forwarded")
+
+	want := []string{
+		"debug: installing requests",
+		"info: installed 1 packages",
+		"warning: output truncated",
+		"error: failed: exit 1",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Sink received %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Message %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
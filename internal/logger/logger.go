@@ -51,9 +51,11 @@ func Debug(format string, args ...any) {
 	}
 }
 
-// VerbosePrint prints to stdout if verbose mode is enabled (for startup messages)
+// VerbosePrint prints a startup message without prefix if verbose mode is
+// enabled. Like all log messages it goes to stderr or the log file, never to
+// stdout, which the stdio transport reserves for protocol frames.
 func VerbosePrint(format string, args ...any) {
 	if verboseEnabled {
-		fmt.Printf(format+"\n", args...)
+		fmt.Fprintf(logger.Writer(), format+"\n", args...)
 	}
 }
@@ -140,10 +140,13 @@ func TestVerbosePrint(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Capture stdout
+			// Capture stdout, which must stay empty, and the log output
 			old := os.Stdout
 			r, w, _ := os.Pipe()
 			os.Stdout = w
+			var buf bytes.Buffer
+			SetOutput(&buf)
+			defer SetOutput(os.Stderr)
 
 			SetVerbose(tt.verboseEnabled)
 			VerbosePrint(tt.format, tt.args...)
@@ -153,10 +156,13 @@ func TestVerbosePrint(t *testing.T) {
 			}
 			os.Stdout = old
 
-			var buf bytes.Buffer
-			if _, err := io.Copy(&buf, r); err != nil {
+			var stdout bytes.Buffer
+			if _, err := io.Copy(&stdout, r); err != nil {
 				t.Fatalf("Failed to copy pipe output: %v", err)
 			}
+			if stdout.Len() > 0 {
+				t.Errorf("VerbosePrint wrote %q to stdout, want the log output", stdout.String())
+			}
 			output := buf.String()
 
 			if tt.wantOutput {
@@ -392,23 +398,13 @@ func TestVerbosePrintWithFormatting(t *testing.T) {
 		verboseEnabled = originalState
 	}()
 
-	// Capture stdout
-	old := os.Stdout
-	r, w, _ := os.Pipe()
-	os.Stdout = w
+	// Capture the log output
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stderr)
 
 	SetVerbose(true)
 	VerbosePrint("Number: %d, String: %s, Bool: %v", 42, "test", true)
-
-	if err := w.Close(); err != nil {
-		t.Fatalf("Failed to close pipe writer: %v", err)
-	}
-	os.Stdout = old
-
-	var buf bytes.Buffer
-	if _, err := io.Copy(&buf, r); err != nil {
-		t.Fatalf("Failed to copy pipe output: %v", err)
-	}
 	output := buf.String()
 
 	expectedParts := []string{"Number:", "42", "String:", "test", "Bool:", "true"}
@@ -0,0 +1,146 @@
+// Package logger provides a log file writer that rotates by size and age, for
+// deployments where stderr is not kept (such as stdio mode under an MCP client).
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the suffix appended to rotated log files.
+const backupTimeFormat = "20060102-150405.000"
+
+// Rotation configures when a log file is rotated and how many old files are kept.
+// Zero values disable the corresponding limit.
+type Rotation struct {
+	MaxSize    int64         // Rotate before a write would exceed this many bytes
+	Interval   time.Duration // Rotate once the file has been open this long
+	MaxBackups int           // Rotated files to keep; older ones are removed
+}
+
+// RotatingFile is a concurrency-safe io.WriteCloser appending to a log file.
+// Rotated files are renamed to <path>.<timestamp>.
+type RotatingFile struct {
+	path     string
+	rotation Rotation
+	now      func() time.Time
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+}
+
+// OpenRotatingFile opens (or creates) the log file at path for appending.
+func OpenRotatingFile(path string, rotation Rotation) (*RotatingFile, error) {
+	f := &RotatingFile{path: path, rotation: rotation, now: time.Now}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write appends p to the file, rotating it first when a limit is reached.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.due(len(p)) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// due reports whether the file must be rotated before writing n bytes. An empty
+// file is never rotated, so single writes larger than MaxSize still succeed.
+func (f *RotatingFile) due(n int) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.rotation.MaxSize > 0 && f.size+int64(n) > f.rotation.MaxSize {
+		return true
+	}
+	return f.rotation.Interval > 0 && f.now().Sub(f.opened) >= f.rotation.Interval
+}
+
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create log directory: %v", err)
+	}
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open log file: %v", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	f.opened = f.now()
+	return nil
+}
+
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	backup := f.path + "." + f.now().Format(backupTimeFormat)
+	if err := os.Rename(f.path, backup); err != nil {
+		return fmt.Errorf("failed to rotate log file: %v", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	f.prune()
+	return nil
+}
+
+// prune removes the oldest backups beyond MaxBackups. Failures are ignored so
+// logging keeps working.
+func (f *RotatingFile) prune() {
+	if f.rotation.MaxBackups <= 0 {
+		return
+	}
+	backups, err := filepath.Glob(f.path + ".*")
+	if err != nil || len(backups) <= f.rotation.MaxBackups {
+		return
+	}
+	// Timestamps sort chronologically, so the oldest backups come first.
+	sort.Strings(backups)
+	for _, backup := range backups[:len(backups)-f.rotation.MaxBackups] {
+		_ = os.Remove(backup)
+	}
+}
+
+// SetOutput redirects all log messages to w.
+func SetOutput(w io.Writer) {
+	logger.SetOutput(w)
+}
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotatingFile_Size(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "server.log")
+	f, err := OpenRotatingFile(path, Rotation{MaxSize: 10, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() returned error: %v", err)
+	}
+	defer f.Close()
+
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	f.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write(%q) returned error: %v", line, err)
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if string(content) != "fourth\n" {
+		t.Errorf("Current log = %q, want %q", content, "fourth\n")
+	}
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 2 {
+		t.Errorf("Backups = %v, want the 2 newest", backups)
+	}
+	if len(backups) == 2 {
+		if oldest, _ := os.ReadFile(backups[0]); string(oldest) != "second\n" {
+			t.Errorf("Oldest kept backup = %q, want %q", oldest, "second\n")
+		}
+	}
+}
+
+func TestRotatingFile_Interval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	if err := os.WriteFile(path, []byte("existing\n"), 0o644); err != nil {
+		t.Fatalf("failed to write log file: %v", err)
+	}
+	f, err := OpenRotatingFile(path, Rotation{Interval: time.Hour})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile() returned error: %v", err)
+	}
+	defer f.Close()
+
+	clock := f.opened
+	f.now = func() time.Time { return clock }
+
+	f.Write([]byte("appended\n"))
+	clock = clock.Add(time.Hour)
+	f.Write([]byte("rotated\n"))
+
+	content, _ := os.ReadFile(path)
+	if string(content) != "rotated\n" {
+		t.Errorf("Current log = %q, want %q", content, "rotated\n")
+	}
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 1 {
+		t.Fatalf("Backups = %v, want 1", backups)
+	}
+	if old, _ := os.ReadFile(backups[0]); string(old) != "existing\nappended\n" {
+		t.Errorf("Backup = %q, want the appended original", old)
+	}
+
+	f.Close()
+	if _, err := f.Write([]byte("late\n")); err == nil {
+		t.Error("Write() after Close() should fail")
+	}
+}
@@ -0,0 +1,194 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ContainerCheckPrompt generates a script reporting what the execution image of a
+// language provides: OS, preinstalled packages, browsers and resource limits.
+// This prompt is only available in docker execution mode, where each language
+// runs in its own image.
+type ContainerCheckPrompt struct{}
+
+// NewContainerCheckPrompt creates a new ContainerCheckPrompt instance.
+func NewContainerCheckPrompt() *ContainerCheckPrompt {
+	return &ContainerCheckPrompt{}
+}
+
+func init() {
+	Register(Registration{
+		Name:  "container-check",
+		Modes: ContainerModes,
+		New:   func(Dependencies) Prompt { return NewContainerCheckPrompt() },
+	})
+}
+
+// containerCheckTools maps each language to the tool whose image is inspected.
+var containerCheckTools = map[string]string{
+	"python":     "execute-python",
+	"bash":       "execute-bash",
+	"typescript": "execute-typescript",
+	"go":         "execute-go",
+}
+
+// CreatePrompt defines the MCP prompt schema with optional language argument.
+func (p *ContainerCheckPrompt) CreatePrompt() mcp.Prompt {
+	return mcp.NewPrompt(
+		"container-check",
+		mcp.WithPromptDescription(
+			"Report what the Docker execution image of a language provides: OS, preinstalled Python and Node.js packages, Go toolchain, browsers and the container's resource limits. Only available in docker execution mode.",
+		),
+		mcp.WithArgument(
+			"language",
+			mcp.ArgumentDescription("Image to inspect: 'python' (default, the Playwright image), 'bash', 'typescript' or 'go'. The script is written for that language's execute tool."),
+		),
+	)
+}
+
+// HandlePrompt processes the prompt request and returns a formatted message with the script.
+func (p *ContainerCheckPrompt) HandlePrompt(
+	ctx context.Context,
+	request mcp.GetPromptRequest,
+) (*mcp.GetPromptResult, error) {
+	// Parse language argument (default to "python")
+	language := "python"
+	if value, ok := request.Params.Arguments["language"]; ok {
+		if _, known := containerCheckTools[strings.ToLower(value)]; known {
+			language = strings.ToLower(value)
+		}
+	}
+	tool := containerCheckTools[language]
+	fence, code := wrapShellScript(language, generateContainerCheckScript())
+
+	message := fmt.Sprintf(
+		"I'll help you find out what the %s execution image provides.\n\n"+
+			"⚠️  **Important**: This prompt is designed for docker execution mode. Every language runs in its own image, "+
+			"so the result only describes the image used by %s.\n\n"+
+			"Execute this code using the %s tool:\n\n"+
+			"```%s\n%s\n```\n\n"+
+			"This will report:\n%s",
+		language,
+		tool,
+		tool,
+		fence,
+		code,
+		"• OS name and version\n• CPU, memory and PID limits of the container (cgroups)\n• Free space in /tmp\n"+
+			"• Python version and preinstalled pip packages\n• Node.js version and global npm packages\n"+
+			"• Go toolchain version\n• Installed browsers (Playwright, Chromium, Firefox)",
+	)
+
+	messages := []mcp.PromptMessage{
+		mcp.NewPromptMessage(
+			mcp.RoleAssistant,
+			mcp.NewTextContent(message),
+		),
+	}
+
+	return mcp.NewGetPromptResult(
+		fmt.Sprintf("Container check script (%s image)", language),
+		messages,
+	), nil
+}
+
+// generateContainerCheckScript creates a POSIX shell script inspecting the container.
+func generateContainerCheckScript() string {
+	var script strings.Builder
+
+	script.WriteString("echo '=== Container Information ==='\n")
+	script.WriteString("echo ''\n\n")
+
+	script.WriteString("echo '--- Operating System ---'\n")
+	script.WriteString("if [ -f /etc/os-release ]; then\n")
+	script.WriteString("  grep -E '^(PRETTY_NAME|VERSION_ID)=' /etc/os-release\n")
+	script.WriteString("fi\n")
+	script.WriteString("uname -srm\n")
+	script.WriteString("echo ''\n\n")
+
+	script.WriteString("echo '--- Resource Limits ---'\n")
+	script.WriteString("echo \"CPUs visible: $(nproc 2>/dev/null || echo unknown)\"\n")
+	script.WriteString("if [ -f /sys/fs/cgroup/memory.max ]; then\n")
+	script.WriteString("  echo \"Memory limit: $(cat /sys/fs/cgroup/memory.max)\"\n")
+	script.WriteString("  echo \"CPU quota (quota period): $(cat /sys/fs/cgroup/cpu.max 2>/dev/null)\"\n")
+	script.WriteString("  echo \"PID limit: $(cat /sys/fs/cgroup/pids.max 2>/dev/null)\"\n")
+	script.WriteString("elif [ -f /sys/fs/cgroup/memory/memory.limit_in_bytes ]; then\n")
+	script.WriteString("  echo \"Memory limit (bytes): $(cat /sys/fs/cgroup/memory/memory.limit_in_bytes)\"\n")
+	script.WriteString("  echo \"CPU quota (us per period): $(cat /sys/fs/cgroup/cpu/cpu.cfs_quota_us 2>/dev/null)\"\n")
+	script.WriteString("else\n")
+	script.WriteString("  echo 'cgroup limits not readable'\n")
+	script.WriteString("fi\n")
+	script.WriteString("df -h /tmp 2>/dev/null | tail -n 1\n")
+	script.WriteString("echo ''\n\n")
+
+	script.WriteString("echo '--- Python ---'\n")
+	script.WriteString("if command -v python3 > /dev/null 2>&1; then\n")
+	script.WriteString("  python3 --version\n")
+	script.WriteString("  python3 -m pip list --format=freeze 2>/dev/null | head -n 100 || echo 'pip not available'\n")
+	script.WriteString("else\n")
+	script.WriteString("  echo 'python3 not installed'\n")
+	script.WriteString("fi\n")
+	script.WriteString("echo ''\n\n")
+
+	script.WriteString("echo '--- Node.js ---'\n")
+	script.WriteString("if command -v node > /dev/null 2>&1; then\n")
+	script.WriteString("  echo \"node $(node --version)\"\n")
+	script.WriteString("  npm ls -g --depth=0 2>/dev/null || echo 'npm not available'\n")
+	script.WriteString("else\n")
+	script.WriteString("  echo 'node not installed'\n")
+	script.WriteString("fi\n")
+	script.WriteString("echo ''\n\n")
+
+	script.WriteString("echo '--- Go ---'\n")
+	script.WriteString("go version 2>/dev/null || echo 'go not installed'\n")
+	script.WriteString("echo ''\n\n")
+
+	script.WriteString("echo '--- Browsers ---'\n")
+	script.WriteString("for dir in \"$PLAYWRIGHT_BROWSERS_PATH\" /ms-playwright \"$HOME/.cache/ms-playwright\"; do\n")
+	script.WriteString("  if [ -n \"$dir\" ] && [ -d \"$dir\" ]; then\n")
+	script.WriteString("    echo \"Playwright browsers in $dir:\"\n")
+	script.WriteString("    ls \"$dir\"\n")
+	script.WriteString("  fi\n")
+	script.WriteString("done\n")
+	script.WriteString("for browser in chromium chromium-browser google-chrome firefox; do\n")
+	script.WriteString("  if command -v \"$browser\" > /dev/null 2>&1; then\n")
+	script.WriteString("    echo \"$browser: $(command -v \"$browser\")\"\n")
+	script.WriteString("  fi\n")
+	script.WriteString("done\n")
+	script.WriteString("echo ''\n")
+
+	script.WriteString("\necho '=== Container Check Complete ==='\n")
+
+	return script.String()
+}
+
+// wrapShellScript returns the code block language and the code running
+// script through the shell from the execute tool of language.
+func wrapShellScript(language, script string) (string, string) {
+	quoted := strconv.Quote(script)
+	switch language {
+	case "python":
+		return "python", "import subprocess\n\n" +
+			"script = " + quoted + "\n" +
+			"subprocess.run([\"sh\", \"-c\", script], check=False)"
+	case "typescript":
+		return "typescript", "import { execSync } from \"child_process\";\n\n" +
+			"const script = " + quoted + ";\n" +
+			"console.log(execSync(script, { shell: \"/bin/sh\", encoding: \"utf8\" }));"
+	case "go":
+		return "go", "package main\n\n" +
+			"import (\n\t\"os\"\n\t\"os/exec\"\n)\n\n" +
+			"const script = " + quoted + "\n\n" +
+			"func main() {\n" +
+			"\tcmd := exec.Command(\"sh\", \"-c\", script)\n" +
+			"\tcmd.Stdout = os.Stdout\n" +
+			"\tcmd.Stderr = os.Stderr\n" +
+			"\t_ = cmd.Run()\n" +
+			"}"
+	default:
+		return "bash", "#!/bin/bash\n" + script
+	}
+}
@@ -0,0 +1,85 @@
+package prompts
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestContainerCheckPrompt_CreatePrompt(t *testing.T) {
+	mcpPrompt := NewContainerCheckPrompt().CreatePrompt()
+
+	if mcpPrompt.Name != "container-check" {
+		t.Errorf("Prompt name = %q, want %q", mcpPrompt.Name, "container-check")
+	}
+	if !strings.Contains(mcpPrompt.Description, "docker") {
+		t.Error("Prompt description should mention 'docker' execution mode")
+	}
+	if len(mcpPrompt.Arguments) != 1 || mcpPrompt.Arguments[0].Name != "language" || mcpPrompt.Arguments[0].Required {
+		t.Errorf("Arguments = %+v, want an optional language argument", mcpPrompt.Arguments)
+	}
+}
+
+func TestContainerCheckPrompt_HandlePrompt(t *testing.T) {
+	testCases := []struct {
+		language    string
+		wantTool    string
+		wantWrapper string
+	}{
+		{"", "execute-python", "subprocess.run"},
+		{"python", "execute-python", "subprocess.run"},
+		{"Bash", "execute-bash", "#!/bin/bash"},
+		{"typescript", "execute-typescript", "execSync"},
+		{"go", "execute-go", "exec.Command"},
+		{"rust", "execute-python", "subprocess.run"}, // Unknown languages fall back to python
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.language, func(t *testing.T) {
+			request := mcp.GetPromptRequest{
+				Params: mcp.GetPromptParams{
+					Name:      "container-check",
+					Arguments: map[string]string{"language": tc.language},
+				},
+			}
+
+			result, err := NewContainerCheckPrompt().HandlePrompt(context.Background(), request)
+			if err != nil {
+				t.Fatalf("HandlePrompt() error = %v, want nil", err)
+			}
+
+			textContent, ok := result.Messages[0].Content.(mcp.TextContent)
+			if !ok {
+				t.Fatal("Message content should be TextContent")
+			}
+			for _, expected := range []string{tc.wantTool, tc.wantWrapper, "Resource Limits", "Browsers"} {
+				if !strings.Contains(textContent.Text, expected) {
+					t.Errorf("Message should contain %q", expected)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateContainerCheckScript(t *testing.T) {
+	script := generateContainerCheckScript()
+
+	expectedSections := []string{
+		"Operating System",
+		"Resource Limits",
+		"memory.max",
+		"pip list",
+		"npm ls -g",
+		"go version",
+		"ms-playwright",
+		"Container Check Complete",
+	}
+
+	for _, section := range expectedSections {
+		if !strings.Contains(script, section) {
+			t.Errorf("Script should contain %q", section)
+		}
+	}
+}
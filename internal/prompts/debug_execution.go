@@ -0,0 +1,249 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/history"
+)
+
+// DebugExecutionPrompt turns a failed execution, looked up in the execution
+// history or pasted by the user, into a debugging plan and instrumented code to
+// re-run with the same execute tool. It is available in every execution mode.
+type DebugExecutionPrompt struct {
+	lookup func(ctx context.Context, id string) (history.Record, bool)
+}
+
+// NewDebugExecutionPrompt creates a DebugExecutionPrompt resolving execution IDs
+// with lookup.
+func NewDebugExecutionPrompt(lookup func(ctx context.Context, id string) (history.Record, bool)) *DebugExecutionPrompt {
+	return &DebugExecutionPrompt{lookup: lookup}
+}
+
+func init() {
+	Register(Registration{
+		Name: "debug-failed-execution",
+		New:  func(deps Dependencies) Prompt { return NewDebugExecutionPrompt(deps.LookupExecution) },
+	})
+}
+
+// failureKind is a class of execution failure with its debugging steps.
+type failureKind struct {
+	name    string
+	pattern *regexp.Regexp
+	steps   []string
+}
+
+// failureKinds are checked in order; the first matching kind classifies the error.
+var failureKinds = []failureKind{
+	{
+		name:    "timeout",
+		pattern: regexp.MustCompile(`(?i)timed out|deadline exceeded`),
+		steps: []string{
+			"Find the slow step from the last output printed before the timeout.",
+			"Reduce the input size or add an early exit, or re-run with a larger `timeout` (up to the server maximum).",
+		},
+	},
+	{
+		name:    "out of memory",
+		pattern: regexp.MustCompile(`(?i)ran out of memory|MemoryError|out of memory|heap out of memory`),
+		steps: []string{
+			"Find the allocation that grows from the last output printed before the kill.",
+			"Process the data in chunks or streams, or ask the operator for a larger `limits.memory`.",
+		},
+	},
+	{
+		name:    "missing dependency",
+		pattern: regexp.MustCompile(`ModuleNotFoundError|ImportError|Cannot find module|cannot find package|no required module provides package|command not found`),
+		steps: []string{
+			"Check the exact name of the missing module, package or command in the error.",
+			"Pass it in `modules` or `packages` (Docker, Nix or uv/venv modes), or use only the standard library in subprocess mode.",
+		},
+	},
+	{
+		name:    "syntax or compile error",
+		pattern: regexp.MustCompile(`SyntaxError|IndentationError|error TS\d+|syntax error|undefined: |declared and not used|imported and not used|expected '`),
+		steps: []string{
+			"Go to the file position named in the error and fix the code there; later errors are often caused by the first one.",
+			"Re-run the corrected code before adding further changes.",
+		},
+	},
+	{
+		name:    "permission or filesystem error",
+		pattern: regexp.MustCompile(`(?i)permission denied|read-only file system|no such file or directory|operation not permitted`),
+		steps: []string{
+			"Check which path is used: the instrumented code prints the working directory.",
+			"Write to a temporary directory, or request a writable mount in Docker mode.",
+		},
+	},
+	{
+		name:    "network error",
+		pattern: regexp.MustCompile(`(?i)connection refused|name resolution|could not resolve|network is unreachable|ENOTFOUND|ECONNREFUSED|no such host`),
+		steps: []string{
+			"Check that the host is reachable from the execution environment and not blocked by `network: none`.",
+			"Retry with a short timeout and print the exact URL that is requested.",
+		},
+	},
+	{
+		name:    "runtime exception",
+		pattern: regexp.MustCompile(`Traceback|Error:|panic:|exit status|Exception`),
+		steps: []string{
+			"Read the innermost frame of the stack trace: it names the failing line and the values involved.",
+			"Print the inputs of that line in the instrumented re-run to confirm the assumption that failed.",
+		},
+	},
+}
+
+// CreatePrompt defines the MCP prompt schema with the execution_id, error, code
+// and language arguments.
+func (p *DebugExecutionPrompt) CreatePrompt() mcp.Prompt {
+	return mcp.NewPrompt(
+		"debug-failed-execution",
+		mcp.WithPromptDescription(
+			"Produce a structured debugging plan and instrumented re-run code for a failed execution, given its execution ID from the execution history or a pasted error.",
+		),
+		mcp.WithArgument(
+			"execution_id",
+			mcp.ArgumentDescription("ID of the failed execution (from its execution:// resource link). Takes precedence over error and code."),
+		),
+		mcp.WithArgument(
+			"error",
+			mcp.ArgumentDescription("Pasted error output, when no execution ID is available."),
+		),
+		mcp.WithArgument(
+			"code",
+			mcp.ArgumentDescription("Pasted code that failed, used to generate the instrumented re-run."),
+		),
+		mcp.WithArgument(
+			"language",
+			mcp.ArgumentDescription("Language of the pasted code: 'python' (default), 'bash', 'typescript' or 'go'."),
+		),
+	)
+}
+
+// HandlePrompt processes the prompt request and returns the debugging plan.
+func (p *DebugExecutionPrompt) HandlePrompt(
+	ctx context.Context,
+	request mcp.GetPromptRequest,
+) (*mcp.GetPromptResult, error) {
+	arguments := request.Params.Arguments
+	language := strings.ToLower(arguments["language"])
+	code, output := arguments["code"], arguments["error"]
+
+	if id := strings.TrimPrefix(strings.TrimSpace(arguments["execution_id"]), history.URIScheme); id != "" {
+		record, ok := p.lookup(ctx, id)
+		if !ok {
+			return nil, fmt.Errorf("execution %q not found (it may have been evicted from history)", id)
+		}
+		language = strings.TrimPrefix(record.Tool, "execute-")
+		code, output = record.Code, record.Output
+	}
+	if strings.TrimSpace(code) == "" && strings.TrimSpace(output) == "" {
+		return nil, fmt.Errorf("either execution_id or error is required")
+	}
+	if _, ok := containerCheckTools[language]; !ok {
+		language = "python"
+	}
+	tool := containerCheckTools[language]
+	kind := classifyFailure(output)
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "I'll help you debug this failed %s execution.\n\n", tool)
+	fmt.Fprintf(&message, "**Failure class**: %s\n\n", kind.name)
+	if output != "" {
+		fmt.Fprintf(&message, "**Error output**:\n\n```\n%s\n```\n\n", strings.TrimSpace(output))
+	}
+
+	message.WriteString("**Debugging plan**:\n\n")
+	steps := append(append([]string{}, kind.steps...),
+		"Re-run the instrumented code below and compare its diagnostics with the assumptions of the original code.",
+		"Apply the smallest fix that explains the diagnostics, re-run the original code without instrumentation, and confirm the output.")
+	for i, step := range steps {
+		fmt.Fprintf(&message, "%d. %s\n", i+1, step)
+	}
+
+	if strings.TrimSpace(code) == "" {
+		message.WriteString("\nNo code is available for an instrumented re-run; pass the failing code in the `code` argument to get one.\n")
+	} else {
+		fence, instrumented, env := instrumentCode(language, code)
+		fmt.Fprintf(&message, "\n**Instrumented re-run** (execute with the %s tool", tool)
+		if env != "" {
+			fmt.Fprintf(&message, " and `env` set to `%s`", env)
+		}
+		fmt.Fprintf(&message, "):\n\n```%s\n%s\n```\n", fence, instrumented)
+	}
+
+	messages := []mcp.PromptMessage{
+		mcp.NewPromptMessage(
+			mcp.RoleAssistant,
+			mcp.NewTextContent(message.String()),
+		),
+	}
+
+	return mcp.NewGetPromptResult(
+		fmt.Sprintf("Debugging plan for a failed %s execution (%s)", tool, kind.name),
+		messages,
+	), nil
+}
+
+// classifyFailure returns the first failure kind matching output.
+func classifyFailure(output string) failureKind {
+	for _, kind := range failureKinds {
+		if kind.pattern.MatchString(output) {
+			return kind
+		}
+	}
+	return failureKind{
+		name: "unknown failure",
+		steps: []string{
+			"The error output does not name a known failure: check the exit status and any partial output for the last step that succeeded.",
+		},
+	}
+}
+
+// instrumentCode wraps code of language with diagnostics printing the runtime,
+// working directory and full failure details. It returns the code block
+// language, the instrumented code and the env value to pass with it.
+func instrumentCode(language, code string) (string, string, string) {
+	switch language {
+	case "bash":
+		return "bash", "#!/bin/bash\n" +
+			"echo \"[debug] bash $BASH_VERSION, user $(id -un 2>/dev/null), cwd $(pwd)\" >&2\n" +
+			"trap 'echo \"[debug] command failed with status $? at line $LINENO: $BASH_COMMAND\" >&2' ERR\n" +
+			"set -x\n\n" +
+			code, ""
+	case "typescript":
+		return "typescript", "console.error(`[debug] node ${process.version}, cwd ${process.cwd()}`);\n" +
+			"process.on(\"uncaughtException\", (error) => {\n" +
+			"  console.error(\"[debug] uncaught exception:\", error);\n" +
+			"  process.exit(1);\n" +
+			"});\n" +
+			"process.on(\"unhandledRejection\", (reason) => {\n" +
+			"  console.error(\"[debug] unhandled rejection:\", reason);\n" +
+			"  process.exit(1);\n" +
+			"});\n\n" +
+			code, ""
+	case "go":
+		// Go code cannot be wrapped without changing its package; the runtime
+		// prints all goroutines on a panic instead.
+		return "go", code, "GOTRACEBACK=all"
+	default:
+		return "python", "import faulthandler, os, sys, traceback\n\n" +
+			"faulthandler.enable()\n" +
+			"print(f\"[debug] python {sys.version.split()[0]}, cwd {os.getcwd()}\", file=sys.stderr)\n" +
+			"source = " + strconv.Quote(code) + "\n" +
+			"try:\n" +
+			"    exec(compile(source, \"<execution>\", \"exec\"), {\"__name__\": \"__main__\"})\n" +
+			"except Exception:\n" +
+			"    traceback.print_exc()\n" +
+			"    frame = sys.exc_info()[2]\n" +
+			"    while frame.tb_next:\n" +
+			"        frame = frame.tb_next\n" +
+			"    print(\"[debug] locals at the failing line:\", {k: repr(v)[:200] for k, v in frame.tb_frame.f_locals.items() if not k.startswith(\"__\")}, file=sys.stderr)\n" +
+			"    sys.exit(1)", "PYTHONFAULTHANDLER=1"
+	}
+}
@@ -0,0 +1,113 @@
+package prompts
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/history"
+)
+
+func TestDebugExecutionPrompt_HandlePrompt(t *testing.T) {
+	store := history.NewStore(10)
+	failed, _ := store.Add(history.Record{
+		Tool:   "execute-bash",
+		Code:   "curl http://example.invalid",
+		Output: "curl: (6) Could not resolve host: example.invalid",
+		Status: "error",
+	})
+	prompt := NewDebugExecutionPrompt(func(_ context.Context, id string) (history.Record, bool) { return store.Get(id) })
+
+	testCases := []struct {
+		name         string
+		arguments    map[string]string
+		wantContains []string
+		wantErr      string
+	}{
+		{
+			name:         "execution from history",
+			arguments:    map[string]string{"execution_id": failed.ID},
+			wantContains: []string{"execute-bash", "network error", "set -x", "curl http://example.invalid"},
+		},
+		{
+			name:         "execution resource URI",
+			arguments:    map[string]string{"execution_id": failed.URI()},
+			wantContains: []string{"execute-bash"},
+		},
+		{
+			name:         "pasted python error and code",
+			arguments:    map[string]string{"error": "ModuleNotFoundError: No module named 'requests'", "code": "import requests"},
+			wantContains: []string{"execute-python", "missing dependency", "traceback.print_exc()", "PYTHONFAULTHANDLER=1", `"import requests"`},
+		},
+		{
+			name:         "pasted go panic",
+			arguments:    map[string]string{"error": "panic: runtime error: index out of range", "code": "package main", "language": "Go"},
+			wantContains: []string{"execute-go", "runtime exception", "GOTRACEBACK=all"},
+		},
+		{
+			name:         "error without code",
+			arguments:    map[string]string{"error": "execution timed out after 30s", "language": "typescript"},
+			wantContains: []string{"execute-typescript", "timeout", "No code is available"},
+		},
+		{
+			name:      "unknown execution",
+			arguments: map[string]string{"execution_id": "missing"},
+			wantErr:   "not found",
+		},
+		{
+			name:      "no arguments",
+			arguments: nil,
+			wantErr:   "required",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := prompt.HandlePrompt(context.Background(), mcp.GetPromptRequest{
+				Params: mcp.GetPromptParams{Name: "debug-failed-execution", Arguments: tc.arguments},
+			})
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("HandlePrompt() error = %v, want error containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("HandlePrompt() error = %v, want nil", err)
+			}
+
+			textContent, ok := result.Messages[0].Content.(mcp.TextContent)
+			if !ok {
+				t.Fatal("Message content should be TextContent")
+			}
+			for _, expected := range tc.wantContains {
+				if !strings.Contains(textContent.Text, expected) {
+					t.Errorf("Message should contain %q, got: %s", expected, textContent.Text)
+				}
+			}
+		})
+	}
+}
+
+func TestClassifyFailure(t *testing.T) {
+	testCases := []struct {
+		output string
+		want   string
+	}{
+		{"execution timed out after 5s", "timeout"},
+		{"python ran out of memory and was killed (memory limit 512m): step 1", "out of memory"},
+		{"bash: line 1: jq: command not found", "missing dependency"},
+		{"./main.go:5:2: \"os\" imported and not used", "syntax or compile error"},
+		{"index.ts(3,1): error TS2304: Cannot find name 'foo'.", "syntax or compile error"},
+		{"open /data/out.csv: permission denied", "permission or filesystem error"},
+		{"Traceback (most recent call last):\nKeyError: 'b'", "runtime exception"},
+		{"", "unknown failure"},
+	}
+
+	for _, tc := range testCases {
+		if got := classifyFailure(tc.output).name; got != tc.want {
+			t.Errorf("classifyFailure(%q) = %q, want %q", tc.output, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GitAnalysisPrompt generates a script summarizing a git repository checked out
+// on the host. This prompt is only available in the host execution modes, where
+// executions can read the checkout.
+type GitAnalysisPrompt struct{}
+
+// NewGitAnalysisPrompt creates a new GitAnalysisPrompt instance.
+func NewGitAnalysisPrompt() *GitAnalysisPrompt {
+	return &GitAnalysisPrompt{}
+}
+
+func init() {
+	Register(Registration{
+		Name:  "git-repository-analysis",
+		Modes: HostModes,
+		New:   func(Dependencies) Prompt { return NewGitAnalysisPrompt() },
+	})
+}
+
+// gitAnalysisTools maps each supported language to the tool running the script.
+var gitAnalysisTools = map[string]string{
+	"bash":   "execute-bash",
+	"python": "execute-python",
+}
+
+// CreatePrompt defines the MCP prompt schema with the path, days and language arguments.
+func (p *GitAnalysisPrompt) CreatePrompt() mcp.Prompt {
+	return mcp.NewPrompt(
+		"git-repository-analysis",
+		mcp.WithPromptDescription(
+			"Summarize a git repository checked out on the host: language breakdown, recent activity, largest files and TODO density. Only available in subprocess execution mode.",
+		),
+		mcp.WithArgument(
+			"path",
+			mcp.ArgumentDescription("Absolute path of the repository checkout on the host."),
+			mcp.RequiredArgument(),
+		),
+		mcp.WithArgument(
+			"days",
+			mcp.ArgumentDescription("Number of days of history summarized as recent activity (default 30)."),
+		),
+		mcp.WithArgument(
+			"language",
+			mcp.ArgumentDescription("Tool running the analysis: 'bash' (default) or 'python'."),
+		),
+	)
+}
+
+// HandlePrompt processes the prompt request and returns a formatted message with the script.
+func (p *GitAnalysisPrompt) HandlePrompt(
+	ctx context.Context,
+	request mcp.GetPromptRequest,
+) (*mcp.GetPromptResult, error) {
+	arguments := request.Params.Arguments
+	path := strings.TrimSpace(arguments["path"])
+	if path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+
+	// Parse the optional arguments, falling back to the defaults for invalid values
+	days := 30
+	if value, err := strconv.Atoi(strings.TrimSpace(arguments["days"])); err == nil && value > 0 {
+		days = value
+	}
+	language := "bash"
+	if _, ok := gitAnalysisTools[strings.ToLower(arguments["language"])]; ok {
+		language = strings.ToLower(arguments["language"])
+	}
+	tool := gitAnalysisTools[language]
+	fence, code := wrapShellScript(language, generateGitAnalysisScript(path, days))
+
+	message := fmt.Sprintf(
+		"I'll help you summarize the git repository at %s.\n\n"+
+			"⚠️  **Important**: This prompt is designed for subprocess execution mode, where executions can read the "+
+			"host checkout. The script only reads the repository and needs git on the host.\n\n"+
+			"Execute this code using the %s tool:\n\n"+
+			"```%s\n%s\n```\n\n"+
+			"This will report:\n%s",
+		path,
+		tool,
+		fence,
+		code,
+		fmt.Sprintf("• Current branch, commit count and tracked files\n"+
+			"• Text lines and files per file extension\n"+
+			"• Commits, top authors and most changed files of the last %d days\n"+
+			"• Largest tracked files\n"+
+			"• TODO/FIXME/XXX/HACK markers per 1000 lines and the files with the most markers",
+			days),
+	)
+
+	messages := []mcp.PromptMessage{
+		mcp.NewPromptMessage(
+			mcp.RoleAssistant,
+			mcp.NewTextContent(message),
+		),
+	}
+
+	return mcp.NewGetPromptResult(
+		fmt.Sprintf("Git repository analysis script (%s)", path),
+		messages,
+	), nil
+}
+
+// generateGitAnalysisScript creates a POSIX shell script summarizing the
+// repository at path with the last days of history.
+func generateGitAnalysisScript(path string, days int) string {
+	var script strings.Builder
+
+	fmt.Fprintf(&script, "REPO=%s\n", singleQuote(path))
+	fmt.Fprintf(&script, "SINCE='%d days ago'\n", days)
+	script.WriteString("cd \"$REPO\" 2>/dev/null || { echo \"Cannot enter $REPO\"; exit 1; }\n")
+	script.WriteString("git rev-parse --is-inside-work-tree > /dev/null 2>&1 || { echo \"$REPO is not a git repository\"; exit 1; }\n\n")
+
+	script.WriteString("echo '=== Git Repository Analysis ==='\n")
+	script.WriteString("echo \"Repository: $(git rev-parse --show-toplevel)\"\n")
+	script.WriteString("echo \"Branch: $(git rev-parse --abbrev-ref HEAD 2>/dev/null)\"\n")
+	script.WriteString("echo \"Commits: $(git rev-list --count HEAD 2>/dev/null || echo 0)\"\n")
+	script.WriteString("echo \"Tracked files: $(git ls-files | wc -l)\"\n")
+	script.WriteString("echo ''\n\n")
+
+	// git grep -I skips binary files; counting the lines matching '^' gives the text lines per file
+	script.WriteString("echo '--- Language Breakdown (text lines by extension) ---'\n")
+	script.WriteString("git grep -I -c -e '^' | awk -F: '{ name = $1; sub(/.*\\//, \"\", name); ext = \"(none)\"; " +
+		"if (name ~ /.\\./) { ext = name; sub(/.*\\./, \"\", ext) } lines[ext] += $NF; files[ext]++ } " +
+		"END { for (ext in lines) printf \"%8d lines %6d files  %s\\n\", lines[ext], files[ext], ext }' | sort -rn | head -n 15\n")
+	script.WriteString("echo ''\n\n")
+
+	script.WriteString("echo \"--- Recent Activity (since $SINCE) ---\"\n")
+	script.WriteString("echo \"Commits: $(git rev-list --count --since=\"$SINCE\" HEAD 2>/dev/null || echo 0)\"\n")
+	script.WriteString("echo 'Top authors:'\n")
+	script.WriteString("git shortlog -sn --since=\"$SINCE\" HEAD 2>/dev/null | head -n 10\n")
+	script.WriteString("echo 'Latest commits:'\n")
+	script.WriteString("git log --since=\"$SINCE\" -n 10 --date=short --format='  %ad %h %an: %s' 2>/dev/null\n")
+	script.WriteString("echo 'Most changed files:'\n")
+	script.WriteString("git log --since=\"$SINCE\" --name-only --format= 2>/dev/null | grep -v '^$' | sort | uniq -c | sort -rn | head -n 10\n")
+	script.WriteString("echo ''\n\n")
+
+	script.WriteString("echo '--- Largest Tracked Files ---'\n")
+	script.WriteString("git ls-files -z | xargs -0 du -k 2>/dev/null | sort -rn | head -n 10 | " +
+		"awk '{ size = $1; sub(/^[0-9]+[ \\t]+/, \"\"); printf \"%8d KB  %s\\n\", size, $0 }'\n")
+	script.WriteString("echo ''\n\n")
+
+	script.WriteString("echo '--- TODO Density ---'\n")
+	script.WriteString("LINES=$(git grep -I -c -e '^' | awk -F: '{ total += $NF } END { print total + 0 }')\n")
+	script.WriteString("git grep -I -c -w -E 'TODO|FIXME|XXX|HACK' | awk -F: -v lines=\"$LINES\" " +
+		"'{ total += $NF } END { printf \"Markers: %d in %d lines (%.2f per 1000 lines)\\n\", total, lines, lines ? total * 1000 / lines : 0 }'\n")
+	script.WriteString("echo 'Files with the most markers:'\n")
+	script.WriteString("git grep -I -c -w -E 'TODO|FIXME|XXX|HACK' | awk -F: '{ print $NF \"  \" $0 }' | sort -rn | head -n 10 | sed 's/:[0-9]*$//'\n")
+
+	script.WriteString("\necho '=== Git Repository Analysis Complete ==='\n")
+
+	return script.String()
+}
+
+// singleQuote quotes s for a POSIX shell.
+func singleQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,87 @@
+package prompts
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestGitAnalysisPrompt_CreatePrompt(t *testing.T) {
+	mcpPrompt := NewGitAnalysisPrompt().CreatePrompt()
+
+	if mcpPrompt.Name != "git-repository-analysis" {
+		t.Errorf("Prompt name = %q, want %q", mcpPrompt.Name, "git-repository-analysis")
+	}
+	if !strings.Contains(mcpPrompt.Description, "subprocess") {
+		t.Error("Prompt description should mention 'subprocess' execution mode")
+	}
+	if len(mcpPrompt.Arguments) == 0 || mcpPrompt.Arguments[0].Name != "path" || !mcpPrompt.Arguments[0].Required {
+		t.Errorf("Arguments = %+v, want a required path argument first", mcpPrompt.Arguments)
+	}
+}
+
+func TestGitAnalysisPrompt_HandlePrompt(t *testing.T) {
+	testCases := []struct {
+		name         string
+		arguments    map[string]string
+		wantContains []string
+		wantErr      bool
+	}{
+		{
+			name:         "defaults",
+			arguments:    map[string]string{"path": "/src/project"},
+			wantContains: []string{"execute-bash", "```bash", "REPO='/src/project'", "SINCE='30 days ago'", "Language Breakdown", "Largest Tracked Files", "TODO Density"},
+		},
+		{
+			name:         "python with days",
+			arguments:    map[string]string{"path": "/src/project", "days": "7", "language": "Python"},
+			wantContains: []string{"execute-python", "subprocess.run", "SINCE='7 days ago'"},
+		},
+		{
+			name:         "invalid days fall back to 30",
+			arguments:    map[string]string{"path": "/src/project", "days": "-3"},
+			wantContains: []string{"SINCE='30 days ago'"},
+		},
+		{
+			name:         "path with quote",
+			arguments:    map[string]string{"path": "/src/it's"},
+			wantContains: []string{`REPO='/src/it'\''s'`},
+		},
+		{
+			name:      "missing path",
+			arguments: map[string]string{},
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := mcp.GetPromptRequest{
+				Params: mcp.GetPromptParams{Name: "git-repository-analysis", Arguments: tc.arguments},
+			}
+
+			result, err := NewGitAnalysisPrompt().HandlePrompt(context.Background(), request)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("HandlePrompt() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("HandlePrompt() error = %v, want nil", err)
+			}
+
+			textContent, ok := result.Messages[0].Content.(mcp.TextContent)
+			if !ok {
+				t.Fatal("Message content should be TextContent")
+			}
+			for _, expected := range tc.wantContains {
+				if !strings.Contains(textContent.Text, expected) {
+					t.Errorf("Message should contain %q", expected)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+// Package prompts provides the MCP prompts of the server and the registry that
+// decides which of them are served in each execution mode.
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/history"
+)
+
+// Prompt is implemented by every prompt served by the MCP server.
+type Prompt interface {
+	CreatePrompt() mcp.Prompt
+	HandlePrompt(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error)
+}
+
+// Dependencies are the server components available to prompts when they are built.
+type Dependencies struct {
+	// LookupExecution returns a recorded execution of the client of ctx by ID.
+	LookupExecution func(ctx context.Context, id string) (history.Record, bool)
+}
+
+// Registration declares a prompt and the execution modes serving it.
+type Registration struct {
+	Name  string                         // Prompt name, unique across the registry
+	Modes []string                       // Execution modes serving the prompt; empty means all
+	New   func(deps Dependencies) Prompt // Builds the prompt for a server
+}
+
+// HostModes are the execution modes running code directly on the host.
+var HostModes = []string{"subprocess", "nix"}
+
+// ContainerModes are the execution modes running code in Docker containers.
+var ContainerModes = []string{"docker", "hybrid"}
+
+// Registry is a concurrency-safe set of prompt registrations, kept in
+// registration order.
+type Registry struct {
+	mu            sync.Mutex
+	registrations []Registration
+}
+
+// defaultRegistry holds the built-in prompts and those registered with Register.
+var defaultRegistry = &Registry{}
+
+// Register adds a prompt to the default registry. Prompts register themselves
+// from init functions, so a duplicate or incomplete registration panics.
+func Register(r Registration) {
+	defaultRegistry.Register(r)
+}
+
+// Names returns the names of the prompts in the default registry.
+func Names() []string {
+	return defaultRegistry.Names()
+}
+
+// CheckNames returns an error for the first name not in the default registry.
+func CheckNames(names []string) error {
+	return defaultRegistry.CheckNames(names)
+}
+
+// ForMode builds the prompts of the default registry served in executionMode,
+// skipping the disabled names.
+func ForMode(executionMode string, disabled []string, deps Dependencies) []Prompt {
+	return defaultRegistry.ForMode(executionMode, disabled, deps)
+}
+
+// Register adds a prompt to the registry, panicking on a duplicate or incomplete registration.
+func (r *Registry) Register(registration Registration) {
+	if registration.Name == "" || registration.New == nil {
+		panic("prompts: registration needs a name and a constructor")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.registrations {
+		if existing.Name == registration.Name {
+			panic(fmt.Sprintf("prompts: %q registered twice", registration.Name))
+		}
+	}
+	r.registrations = append(r.registrations, registration)
+}
+
+// Names returns the names of the registered prompts in registration order.
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.registrations))
+	for _, registration := range r.registrations {
+		names = append(names, registration.Name)
+	}
+	return names
+}
+
+// CheckNames returns an error for the first name that is not registered.
+func (r *Registry) CheckNames(names []string) error {
+	known := r.Names()
+	for _, name := range names {
+		if !slices.Contains(known, strings.TrimSpace(name)) {
+			return fmt.Errorf("unknown prompt %q: expected one of %s", name, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+// ForMode builds the prompts served in executionMode (subprocess when empty),
+// skipping the disabled names.
+func (r *Registry) ForMode(executionMode string, disabled []string, deps Dependencies) []Prompt {
+	if executionMode == "" {
+		executionMode = "subprocess"
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var prompts []Prompt
+	for _, registration := range r.registrations {
+		if len(registration.Modes) > 0 && !slices.Contains(registration.Modes, executionMode) {
+			continue
+		}
+		if slices.ContainsFunc(disabled, func(name string) bool { return strings.TrimSpace(name) == registration.Name }) {
+			continue
+		}
+		prompts = append(prompts, registration.New(deps))
+	}
+	return prompts
+}
@@ -0,0 +1,75 @@
+package prompts
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_ForMode(t *testing.T) {
+	registry := &Registry{}
+	registry.Register(Registration{Name: "host", Modes: HostModes, New: func(Dependencies) Prompt { return NewSystemCheckPrompt() }})
+	registry.Register(Registration{Name: "container", Modes: []string{"docker"}, New: func(Dependencies) Prompt { return NewContainerCheckPrompt() }})
+	registry.Register(Registration{Name: "universal", New: func(Dependencies) Prompt { return NewWebScrapePrompt() }})
+
+	tests := []struct {
+		mode     string
+		disabled []string
+		want     int
+	}{
+		{"subprocess", nil, 2},
+		{"", nil, 2}, // Empty mode defaults to subprocess
+		{"nix", nil, 2},
+		{"docker", nil, 2},
+		{"docker", []string{"universal"}, 1},
+		{"subprocess", []string{" host ", "universal"}, 0},
+	}
+
+	for _, tt := range tests {
+		got := registry.ForMode(tt.mode, tt.disabled, Dependencies{})
+		if len(got) != tt.want {
+			t.Errorf("ForMode(%q, %v) returned %d prompts, want %d", tt.mode, tt.disabled, len(got), tt.want)
+		}
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	registry := &Registry{}
+	registry.Register(Registration{Name: "check", New: func(Dependencies) Prompt { return NewSystemCheckPrompt() }})
+
+	for name, registration := range map[string]Registration{
+		"duplicate":      {Name: "check", New: func(Dependencies) Prompt { return NewSystemCheckPrompt() }},
+		"no constructor": {Name: "other"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Register() should panic")
+				}
+			}()
+			registry.Register(registration)
+		})
+	}
+
+	if err := registry.CheckNames([]string{"check"}); err != nil {
+		t.Errorf("CheckNames() error = %v, want nil", err)
+	}
+	if err := registry.CheckNames([]string{"missing"}); err == nil || !strings.Contains(err.Error(), "check") {
+		t.Errorf("CheckNames() error = %v, want error listing the registered prompts", err)
+	}
+}
+
+func TestBuiltinPrompts(t *testing.T) {
+	names := Names()
+	for _, want := range []string{"system-check", "security-audit", "container-check", "web-scrape", "debug-failed-execution", "git-repository-analysis"} {
+		if !slices.Contains(names, want) {
+			t.Errorf("Names() = %v, want %q registered", names, want)
+		}
+	}
+
+	for _, prompt := range ForMode("docker", nil, Dependencies{}) {
+		if name := prompt.CreatePrompt().Name; name == "system-check" || name == "security-audit" || name == "git-repository-analysis" {
+			t.Errorf("ForMode(docker) includes host-only prompt %q", name)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// SecurityAuditPrompt generates a read-only bash script checking the security
+// posture of the host. This prompt is only available in subprocess execution
+// mode, where scripts run on the host instead of in a container.
+type SecurityAuditPrompt struct{}
+
+// NewSecurityAuditPrompt creates a new SecurityAuditPrompt instance.
+func NewSecurityAuditPrompt() *SecurityAuditPrompt {
+	return &SecurityAuditPrompt{}
+}
+
+func init() {
+	Register(Registration{
+		Name:  "security-audit",
+		Modes: HostModes,
+		New:   func(Dependencies) Prompt { return NewSecurityAuditPrompt() },
+	})
+}
+
+// CreatePrompt defines the MCP prompt schema with optional depth argument.
+func (p *SecurityAuditPrompt) CreatePrompt() mcp.Prompt {
+	return mcp.NewPrompt(
+		"security-audit",
+		mcp.WithPromptDescription(
+			"Check the security posture of the host machine: listening ports, sudoers anomalies, accounts with root privileges, pending updates and world-writable files. Only available in subprocess execution mode.",
+		),
+		mcp.WithArgument(
+			"depth",
+			mcp.ArgumentDescription("Audit depth: 'quick' (default), 'standard', or 'thorough'. Quick covers listening ports, root accounts, sudoers and pending updates. Standard adds SSH settings and world-writable files in system directories. Thorough adds world-writable files on the whole root filesystem, SUID/SGID binaries and firewall status."),
+		),
+	)
+}
+
+// HandlePrompt processes the prompt request and returns a formatted message with the bash script.
+func (p *SecurityAuditPrompt) HandlePrompt(
+	ctx context.Context,
+	request mcp.GetPromptRequest,
+) (*mcp.GetPromptResult, error) {
+	// Parse depth argument (default to "quick")
+	depth := "quick"
+	if request.Params.Arguments != nil {
+		switch value := strings.ToLower(request.Params.Arguments["depth"]); value {
+		case "quick", "standard", "thorough":
+			depth = value
+		}
+	}
+
+	script := generateSecurityAuditScript(depth)
+
+	message := fmt.Sprintf(
+		"I'll help you audit the host's security posture at the '%s' depth.\n\n"+
+			"⚠️  **Important**: This prompt is designed for subprocess execution mode to audit the host system. "+
+			"The script only reads system state and does not use sudo, so checks needing root report partial results "+
+			"unless the server runs as root.\n\n"+
+			"Execute this bash script using the execute-bash tool:\n\n"+
+			"```bash\n%s\n```\n\n"+
+			"This will check:\n%s\n\n"+
+			"Findings are marked with [WARN]; review each one before changing the system.",
+		depth,
+		script,
+		getAuditDepthDescription(depth),
+	)
+
+	messages := []mcp.PromptMessage{
+		mcp.NewPromptMessage(
+			mcp.RoleAssistant,
+			mcp.NewTextContent(message),
+		),
+	}
+
+	return mcp.NewGetPromptResult(
+		fmt.Sprintf("Security audit script (%s depth)", depth),
+		messages,
+	), nil
+}
+
+// generateSecurityAuditScript creates a read-only bash script for the given depth.
+func generateSecurityAuditScript(depth string) string {
+	var script strings.Builder
+
+	// All depths include the quick checks
+	script.WriteString("#!/bin/bash\n")
+	script.WriteString("echo '=== Security Audit ==='\n")
+	script.WriteString("[ \"$(id -u)\" -ne 0 ] && echo 'Not running as root: some checks may be incomplete'\n")
+	script.WriteString("echo ''\n\n")
+
+	script.WriteString("echo '--- Listening Ports ---'\n")
+	script.WriteString("if command -v ss &> /dev/null; then\n")
+	script.WriteString("  ss -tulpn 2>/dev/null\n")
+	script.WriteString("elif command -v netstat &> /dev/null; then\n")
+	script.WriteString("  netstat -an 2>/dev/null | grep -E 'LISTEN|^udp'\n")
+	script.WriteString("elif command -v lsof &> /dev/null; then\n")
+	script.WriteString("  lsof -nP -iTCP -sTCP:LISTEN 2>/dev/null\n")
+	script.WriteString("else\n")
+	script.WriteString("  echo 'No ss, netstat or lsof available'\n")
+	script.WriteString("fi\n")
+	script.WriteString("echo ''\n\n")
+
+	script.WriteString("echo '--- Accounts with UID 0 ---'\n")
+	script.WriteString("awk -F: '$3 == 0 { if ($1 != \"root\") print \"[WARN] \" $1 \" has UID 0\"; else print $1 }' /etc/passwd\n")
+	script.WriteString("awk -F: '($2 == \"\") { print \"[WARN] \" $1 \" has an empty password\" }' /etc/shadow 2>/dev/null\n")
+	script.WriteString("echo ''\n\n")
+
+	script.WriteString("echo '--- Sudoers ---'\n")
+	script.WriteString("if [ -r /etc/sudoers ]; then\n")
+	script.WriteString("  grep -hvE '^\\s*(#|$|Defaults)' /etc/sudoers /etc/sudoers.d/* 2>/dev/null\n")
+	script.WriteString("  grep -hE '^[^#]*NOPASSWD' /etc/sudoers /etc/sudoers.d/* 2>/dev/null | sed 's/^/[WARN] NOPASSWD rule: /'\n")
+	script.WriteString("  grep -hE '^[^#]*ALL\\s*=\\s*\\(ALL(:ALL)?\\)\\s*ALL' /etc/sudoers /etc/sudoers.d/* 2>/dev/null | grep -vE '^\\s*(root|%(sudo|wheel|admin))\\s' | sed 's/^/[WARN] Unrestricted rule: /'\n")
+	script.WriteString("  find /etc/sudoers.d -type f -perm /022 2>/dev/null | sed 's/^/[WARN] Writable by group or others: /'\n")
+	script.WriteString("elif [ -e /etc/sudoers ]; then\n")
+	script.WriteString("  echo '/etc/sudoers not readable (requires root)'\n")
+	script.WriteString("else\n")
+	script.WriteString("  echo 'No /etc/sudoers (sudo not installed)'\n")
+	script.WriteString("fi\n")
+	script.WriteString("echo ''\n\n")
+
+	script.WriteString("echo '--- Pending Updates ---'\n")
+	script.WriteString("if command -v apt &> /dev/null; then\n")
+	script.WriteString("  echo \"apt: $(apt list --upgradable 2>/dev/null | grep -c upgradable) upgradable packages (as of the last apt update)\"\n")
+	script.WriteString("  apt list --upgradable 2>/dev/null | grep -i security | head -n 20 | sed 's/^/[WARN] Security update: /'\n")
+	script.WriteString("elif command -v dnf &> /dev/null; then\n")
+	script.WriteString("  echo \"dnf: $(dnf -q check-update 2>/dev/null | grep -c '^[[:alnum:]]') upgradable packages\"\n")
+	script.WriteString("  dnf -q updateinfo list --security 2>/dev/null | head -n 20 | sed 's/^/[WARN] Security update: /'\n")
+	script.WriteString("elif command -v yum &> /dev/null; then\n")
+	script.WriteString("  echo \"yum: $(yum -q check-update 2>/dev/null | grep -c '^[[:alnum:]]') upgradable packages\"\n")
+	script.WriteString("elif command -v apk &> /dev/null; then\n")
+	script.WriteString("  echo \"apk: $(apk version -l '<' 2>/dev/null | tail -n +2 | wc -l) upgradable packages\"\n")
+	script.WriteString("elif command -v checkupdates &> /dev/null; then\n")
+	script.WriteString("  echo \"pacman: $(checkupdates 2>/dev/null | wc -l) upgradable packages\"\n")
+	script.WriteString("elif command -v softwareupdate &> /dev/null; then\n")
+	script.WriteString("  softwareupdate -l 2>&1 | grep -E '^\\s*\\*' || echo 'No updates listed'\n")
+	script.WriteString("else\n")
+	script.WriteString("  echo 'No supported package manager found'\n")
+	script.WriteString("fi\n")
+	script.WriteString("echo ''\n")
+
+	// Standard depth adds: SSH settings, world-writable files in system directories
+	if depth == "standard" || depth == "thorough" {
+		script.WriteString("\necho '--- SSH Server Settings ---'\n")
+		script.WriteString("if [ -r /etc/ssh/sshd_config ]; then\n")
+		script.WriteString("  grep -hiE '^\\s*(PermitRootLogin|PasswordAuthentication|PermitEmptyPasswords|X11Forwarding)\\s' /etc/ssh/sshd_config /etc/ssh/sshd_config.d/*.conf 2>/dev/null\n")
+		script.WriteString("  grep -hiE '^\\s*(PermitRootLogin\\s+yes|PasswordAuthentication\\s+yes|PermitEmptyPasswords\\s+yes)' /etc/ssh/sshd_config /etc/ssh/sshd_config.d/*.conf 2>/dev/null | sed 's/^/[WARN] /'\n")
+		script.WriteString("else\n")
+		script.WriteString("  echo 'No readable sshd_config'\n")
+		script.WriteString("fi\n")
+		script.WriteString("echo ''\n\n")
+
+		script.WriteString("echo '--- World-Writable Files in System Directories ---'\n")
+		script.WriteString("find /etc /usr /bin /sbin /lib /opt -xdev -type f -perm -0002 2>/dev/null | head -n 50 | sed 's/^/[WARN] /'\n")
+		script.WriteString("find /etc /usr /var /opt -xdev -type d -perm -0002 ! -perm -1000 2>/dev/null | head -n 50 | sed 's/^/[WARN] No sticky bit: /'\n")
+		script.WriteString("echo '(showing at most 50 entries each)'\n")
+		script.WriteString("echo ''\n")
+	}
+
+	// Thorough depth adds: whole root filesystem, SUID/SGID binaries, firewall
+	if depth == "thorough" {
+		script.WriteString("\necho '--- World-Writable Files (Root Filesystem) ---'\n")
+		script.WriteString("find / -xdev \\( -path /proc -o -path /sys -o -path /tmp -o -path /var/tmp -o -path /dev \\) -prune -o -type f -perm -0002 -print 2>/dev/null | head -n 100 | sed 's/^/[WARN] /'\n")
+		script.WriteString("echo '(showing at most 100 entries)'\n")
+		script.WriteString("echo ''\n\n")
+
+		script.WriteString("echo '--- SUID/SGID Binaries ---'\n")
+		script.WriteString("find / -xdev \\( -path /proc -o -path /sys \\) -prune -o -type f \\( -perm -4000 -o -perm -2000 \\) -print 2>/dev/null | head -n 100\n")
+		script.WriteString("echo '(showing at most 100 entries; compare with the packages that install them)'\n")
+		script.WriteString("echo ''\n\n")
+
+		script.WriteString("echo '--- Firewall Status ---'\n")
+		script.WriteString("if command -v ufw &> /dev/null; then\n")
+		script.WriteString("  ufw status 2>/dev/null || echo 'ufw status requires root'\n")
+		script.WriteString("elif command -v firewall-cmd &> /dev/null; then\n")
+		script.WriteString("  firewall-cmd --state 2>/dev/null\n")
+		script.WriteString("elif command -v nft &> /dev/null; then\n")
+		script.WriteString("  nft list ruleset 2>/dev/null | head -n 40 || echo 'nft requires root'\n")
+		script.WriteString("elif command -v iptables &> /dev/null; then\n")
+		script.WriteString("  iptables -S 2>/dev/null | head -n 40 || echo 'iptables requires root'\n")
+		script.WriteString("elif [ -x /usr/libexec/ApplicationFirewall/socketfilterfw ]; then\n")
+		script.WriteString("  /usr/libexec/ApplicationFirewall/socketfilterfw --getglobalstate\n")
+		script.WriteString("else\n")
+		script.WriteString("  echo '[WARN] No firewall tool found'\n")
+		script.WriteString("fi\n")
+		script.WriteString("echo ''\n")
+	}
+
+	script.WriteString("\necho '=== Security Audit Complete ==='\n")
+
+	return script.String()
+}
+
+// getAuditDepthDescription returns a human-readable description of what each depth checks.
+func getAuditDepthDescription(depth string) string {
+	switch depth {
+	case "quick":
+		return "• Listening TCP and UDP ports\n• Accounts other than root with UID 0, and empty passwords\n• Sudoers rules without password or restrictions\n• Pending package updates"
+	case "standard":
+		return "• Everything in 'quick'\n• SSH server settings (root login, password authentication)\n• World-writable files and directories without sticky bit in system directories"
+	case "thorough":
+		return "• Everything in 'standard'\n• World-writable files on the whole root filesystem\n• SUID/SGID binaries\n• Firewall status"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,71 @@
+package prompts
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSecurityAuditPrompt_CreatePrompt(t *testing.T) {
+	mcpPrompt := NewSecurityAuditPrompt().CreatePrompt()
+
+	if mcpPrompt.Name != "security-audit" {
+		t.Errorf("Prompt name = %q, want %q", mcpPrompt.Name, "security-audit")
+	}
+	if !strings.Contains(mcpPrompt.Description, "subprocess") {
+		t.Error("Prompt description should mention 'subprocess' execution mode")
+	}
+	if len(mcpPrompt.Arguments) != 1 || mcpPrompt.Arguments[0].Name != "depth" || mcpPrompt.Arguments[0].Required {
+		t.Errorf("Arguments = %+v, want an optional depth argument", mcpPrompt.Arguments)
+	}
+}
+
+func TestSecurityAuditPrompt_HandlePrompt(t *testing.T) {
+	testCases := []struct {
+		depth          string
+		wantDepth      string
+		wantContains   []string
+		wantNotContain []string
+	}{
+		{"", "quick", []string{"Listening Ports", "UID 0", "Sudoers", "Pending Updates"}, []string{"SSH Server Settings", "SUID/SGID"}},
+		{"STANDARD", "standard", []string{"Pending Updates", "SSH Server Settings", "World-Writable Files in System Directories"}, []string{"SUID/SGID"}},
+		{"thorough", "thorough", []string{"Sudoers", "SSH Server Settings", "SUID/SGID", "Firewall Status"}, nil},
+		{"paranoid", "quick", []string{"Listening Ports"}, []string{"Firewall Status"}}, // Invalid depths fall back to quick
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.depth, func(t *testing.T) {
+			request := mcp.GetPromptRequest{
+				Params: mcp.GetPromptParams{
+					Name:      "security-audit",
+					Arguments: map[string]string{"depth": tc.depth},
+				},
+			}
+
+			result, err := NewSecurityAuditPrompt().HandlePrompt(context.Background(), request)
+			if err != nil {
+				t.Fatalf("HandlePrompt() error = %v, want nil", err)
+			}
+			if !strings.Contains(result.Description, tc.wantDepth) {
+				t.Errorf("Description = %q, want depth %q", result.Description, tc.wantDepth)
+			}
+
+			textContent, ok := result.Messages[0].Content.(mcp.TextContent)
+			if !ok {
+				t.Fatal("Message content should be TextContent")
+			}
+			for _, expected := range tc.wantContains {
+				if !strings.Contains(textContent.Text, expected) {
+					t.Errorf("Message should contain %q", expected)
+				}
+			}
+			for _, unexpected := range tc.wantNotContain {
+				if strings.Contains(textContent.Text, unexpected) {
+					t.Errorf("Message should not contain %q", unexpected)
+				}
+			}
+		})
+	}
+}
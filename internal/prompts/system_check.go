@@ -17,6 +17,14 @@ func NewSystemCheckPrompt() *SystemCheckPrompt {
 	return &SystemCheckPrompt{}
 }
 
+func init() {
+	Register(Registration{
+		Name:  "system-check",
+		Modes: HostModes,
+		New:   func(Dependencies) Prompt { return NewSystemCheckPrompt() },
+	})
+}
+
 // CreatePrompt defines the MCP prompt schema with optional detail_level argument.
 func (p *SystemCheckPrompt) CreatePrompt() mcp.Prompt {
 	return mcp.NewPrompt(
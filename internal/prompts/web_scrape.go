@@ -0,0 +1,178 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WebScrapePrompt generates Playwright scraping code for the Python Docker image,
+// which ships Playwright with its browsers preinstalled. This prompt is only
+// available in docker execution mode.
+type WebScrapePrompt struct{}
+
+// NewWebScrapePrompt creates a new WebScrapePrompt instance.
+func NewWebScrapePrompt() *WebScrapePrompt {
+	return &WebScrapePrompt{}
+}
+
+func init() {
+	Register(Registration{
+		Name:  "web-scrape",
+		Modes: ContainerModes,
+		New:   func(Dependencies) Prompt { return NewWebScrapePrompt() },
+	})
+}
+
+// webScrapeBrowsers maps each browser to its launch arguments. Chromium runs as
+// root in the container, so its sandbox is disabled, and /dev/shm is small by
+// default.
+var webScrapeBrowsers = map[string]string{
+	"chromium": `["--no-sandbox", "--disable-dev-shm-usage"]`,
+	"firefox":  "[]",
+	"webkit":   "[]",
+}
+
+// webScrapeMaxText is the number of characters of page text the generated code
+// prints, keeping the output within the tool's output limits.
+const webScrapeMaxText = 5000
+
+// CreatePrompt defines the MCP prompt schema with the url, selector, wait_until
+// and browser arguments.
+func (p *WebScrapePrompt) CreatePrompt() mcp.Prompt {
+	return mcp.NewPrompt(
+		"web-scrape",
+		mcp.WithPromptDescription(
+			"Generate Playwright code that scrapes a web page with the headless browsers preinstalled in the Python Docker image, printing the result as JSON. Only available in docker execution mode.",
+		),
+		mcp.WithArgument(
+			"url",
+			mcp.ArgumentDescription("Page to scrape (http or https)."),
+			mcp.RequiredArgument(),
+		),
+		mcp.WithArgument(
+			"selector",
+			mcp.ArgumentDescription("CSS selector of the elements to extract. Without it, the page title, text and links are extracted."),
+		),
+		mcp.WithArgument(
+			"wait_until",
+			mcp.ArgumentDescription("When navigation is done: 'networkidle' (default, for pages rendered by JavaScript), 'load' or 'domcontentloaded'."),
+		),
+		mcp.WithArgument(
+			"browser",
+			mcp.ArgumentDescription("Browser engine: 'chromium' (default), 'firefox' or 'webkit'."),
+		),
+	)
+}
+
+// HandlePrompt processes the prompt request and returns a formatted message with the scraping code.
+func (p *WebScrapePrompt) HandlePrompt(
+	ctx context.Context,
+	request mcp.GetPromptRequest,
+) (*mcp.GetPromptResult, error) {
+	arguments := request.Params.Arguments
+	target := strings.TrimSpace(arguments["url"])
+	if target == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	if parsed, err := url.Parse(target); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("url %q must be an absolute http or https URL", target)
+	}
+
+	// Parse the optional arguments, falling back to the defaults for invalid values
+	waitUntil := "networkidle"
+	switch value := strings.ToLower(arguments["wait_until"]); value {
+	case "load", "domcontentloaded", "networkidle":
+		waitUntil = value
+	}
+	browser := "chromium"
+	if _, ok := webScrapeBrowsers[strings.ToLower(arguments["browser"])]; ok {
+		browser = strings.ToLower(arguments["browser"])
+	}
+	selector := strings.TrimSpace(arguments["selector"])
+
+	code := generateWebScrapeCode(target, selector, waitUntil, browser)
+
+	extracted := "the page title, visible text and links"
+	if selector != "" {
+		extracted = fmt.Sprintf("the elements matching `%s`", selector)
+	}
+	message := fmt.Sprintf(
+		"I'll help you scrape %s with Playwright.\n\n"+
+			"⚠️  **Important**: This prompt is designed for docker execution mode. The Python image ships Playwright with "+
+			"Chromium, Firefox and WebKit preinstalled, so no `modules` need to be installed. The container needs network "+
+			"access: do not set `network` to `none`.\n\n"+
+			"Execute this code using the execute-python tool:\n\n"+
+			"```python\n%s\n```\n\n"+
+			"This will:\n%s",
+		target,
+		code,
+		fmt.Sprintf("• Open the page in headless %s and wait for '%s'\n"+
+			"• Extract %s\n"+
+			"• Print the result as JSON on stdout, with text cut to %d characters\n"+
+			"• Print errors as JSON on stderr and exit with status 1",
+			browser, waitUntil, extracted, webScrapeMaxText),
+	)
+
+	messages := []mcp.PromptMessage{
+		mcp.NewPromptMessage(
+			mcp.RoleAssistant,
+			mcp.NewTextContent(message),
+		),
+	}
+
+	return mcp.NewGetPromptResult(
+		fmt.Sprintf("Playwright scraping code for %s", target),
+		messages,
+	), nil
+}
+
+// generateWebScrapeCode creates the Python code scraping target with Playwright's
+// sync API.
+func generateWebScrapeCode(target, selector, waitUntil, browser string) string {
+	var code strings.Builder
+
+	code.WriteString("import json\n")
+	code.WriteString("import sys\n\n")
+	code.WriteString("from playwright.sync_api import Error as PlaywrightError, sync_playwright\n\n")
+
+	fmt.Fprintf(&code, "URL = %s\n", strconv.Quote(target))
+	if selector != "" {
+		fmt.Fprintf(&code, "SELECTOR = %s\n", strconv.Quote(selector))
+	}
+	fmt.Fprintf(&code, "MAX_TEXT = %d\n\n", webScrapeMaxText)
+
+	code.WriteString("with sync_playwright() as playwright:\n")
+	fmt.Fprintf(&code, "    browser = playwright.%s.launch(headless=True, args=%s)\n", browser, webScrapeBrowsers[browser])
+	code.WriteString("    page = browser.new_page(viewport={\"width\": 1280, \"height\": 800})\n")
+	code.WriteString("    try:\n")
+	fmt.Fprintf(&code, "        response = page.goto(URL, wait_until=%q, timeout=30000)\n", waitUntil)
+	code.WriteString("        result = {\n")
+	code.WriteString("            \"url\": page.url,\n")
+	code.WriteString("            \"status\": response.status if response else None,\n")
+	code.WriteString("            \"title\": page.title(),\n")
+	code.WriteString("        }\n")
+	if selector != "" {
+		code.WriteString("        # Content rendered after navigation may still be loading\n")
+		code.WriteString("        page.wait_for_selector(SELECTOR, timeout=10000)\n")
+		code.WriteString("        result[\"items\"] = [text.strip()[:MAX_TEXT] for text in page.locator(SELECTOR).all_inner_texts()]\n")
+	} else {
+		code.WriteString("        result[\"text\"] = page.inner_text(\"body\").strip()[:MAX_TEXT]\n")
+		code.WriteString("        result[\"links\"] = page.eval_on_selector_all(\n")
+		code.WriteString("            \"a[href]\", \"links => links.map(a => ({text: a.innerText.trim(), href: a.href}))\"\n")
+		code.WriteString("        )[:100]\n")
+	}
+	code.WriteString("    except PlaywrightError as error:\n")
+	code.WriteString("        print(json.dumps({\"url\": URL, \"error\": str(error)}), file=sys.stderr)\n")
+	code.WriteString("        sys.exit(1)\n")
+	code.WriteString("    finally:\n")
+	code.WriteString("        browser.close()\n\n")
+
+	code.WriteString("print(json.dumps(result, indent=2, ensure_ascii=False))")
+
+	return code.String()
+}
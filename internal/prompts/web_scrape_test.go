@@ -0,0 +1,99 @@
+package prompts
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestWebScrapePrompt_CreatePrompt(t *testing.T) {
+	mcpPrompt := NewWebScrapePrompt().CreatePrompt()
+
+	if mcpPrompt.Name != "web-scrape" {
+		t.Errorf("Prompt name = %q, want %q", mcpPrompt.Name, "web-scrape")
+	}
+	if !strings.Contains(mcpPrompt.Description, "docker") {
+		t.Error("Prompt description should mention 'docker' execution mode")
+	}
+	if len(mcpPrompt.Arguments) == 0 || mcpPrompt.Arguments[0].Name != "url" || !mcpPrompt.Arguments[0].Required {
+		t.Errorf("Arguments = %+v, want a required url argument first", mcpPrompt.Arguments)
+	}
+}
+
+func TestWebScrapePrompt_HandlePrompt(t *testing.T) {
+	testCases := []struct {
+		name         string
+		arguments    map[string]string
+		wantContains []string
+		wantErr      bool
+	}{
+		{
+			name:      "defaults",
+			arguments: map[string]string{"url": "https://example.com"},
+			wantContains: []string{
+				"execute-python",
+				`URL = "https://example.com"`,
+				`playwright.chromium.launch(headless=True, args=["--no-sandbox", "--disable-dev-shm-usage"])`,
+				`wait_until="networkidle"`,
+				`page.inner_text("body")`,
+				"a[href]",
+			},
+		},
+		{
+			name:      "selector and options",
+			arguments: map[string]string{"url": "http://example.com/news", "selector": "h2 > a", "wait_until": "LOAD", "browser": "firefox"},
+			wantContains: []string{
+				`SELECTOR = "h2 > a"`,
+				"page.wait_for_selector(SELECTOR",
+				"playwright.firefox.launch(headless=True, args=[])",
+				`wait_until="load"`,
+			},
+		},
+		{
+			name:         "invalid options fall back to defaults",
+			arguments:    map[string]string{"url": "https://example.com", "wait_until": "forever", "browser": "lynx"},
+			wantContains: []string{"playwright.chromium.launch", `wait_until="networkidle"`},
+		},
+		{
+			name:      "missing url",
+			arguments: map[string]string{},
+			wantErr:   true,
+		},
+		{
+			name:      "non-http url",
+			arguments: map[string]string{"url": "file:///etc/passwd"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := mcp.GetPromptRequest{
+				Params: mcp.GetPromptParams{Name: "web-scrape", Arguments: tc.arguments},
+			}
+
+			result, err := NewWebScrapePrompt().HandlePrompt(context.Background(), request)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("HandlePrompt() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("HandlePrompt() error = %v, want nil", err)
+			}
+
+			textContent, ok := result.Messages[0].Content.(mcp.TextContent)
+			if !ok {
+				t.Fatal("Message content should be TextContent")
+			}
+			for _, expected := range tc.wantContains {
+				if !strings.Contains(textContent.Text, expected) {
+					t.Errorf("Message should contain %q, got: %s", expected, textContent.Text)
+				}
+			}
+		})
+	}
+}
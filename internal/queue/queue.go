@@ -0,0 +1,255 @@
+// Package queue limits how many executions run at once. Executions beyond the
+// limit wait in a bounded queue, where interactive calls go before batch calls
+// and may preempt long-running batch executions.
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Priority orders waiting executions.
+type Priority int
+
+const (
+	Interactive Priority = iota // Served first; the default
+	Batch                       // Served when no interactive execution waits
+)
+
+// ParsePriority parses "interactive" or "batch"; empty selects Interactive.
+func ParsePriority(s string) (Priority, error) {
+	switch s {
+	case "", "interactive":
+		return Interactive, nil
+	case "batch":
+		return Batch, nil
+	default:
+		return 0, fmt.Errorf("invalid priority %q: expected interactive or batch", s)
+	}
+}
+
+// ErrFull is returned when the queue already holds its maximum of waiting executions.
+var ErrFull = errors.New("execution queue is full, try again later")
+
+// ErrPreempted is the cause of the context of a batch execution cancelled to
+// free its slot for an interactive execution.
+var ErrPreempted = errors.New("preempted by an interactive execution")
+
+type waiter struct {
+	priority Priority
+	ready    chan struct{} // Closed when the waiter holds a slot
+	moved    chan struct{} // Signaled when the waiter's position may have changed
+}
+
+// preemptible is a running batch execution that may be preempted.
+type preemptible struct {
+	startedAt time.Time
+	cancel    context.CancelCauseFunc
+	preempted bool
+}
+
+// Queue is a concurrency-safe execution limiter.
+type Queue struct {
+	mu            sync.Mutex
+	maxConcurrent int
+	maxQueued     int
+	running       int
+	waiting       []*waiter // Interactive before batch, oldest first within a priority
+
+	preemptAfter time.Duration
+	preemptible  []*preemptible // Oldest first
+	preempting   int            // Preempted executions that have not released their slot yet
+}
+
+// New creates a Queue running up to maxConcurrent executions at once and holding
+// up to maxQueued waiting executions (unbounded when <= 0).
+func New(maxConcurrent, maxQueued int) *Queue {
+	return &Queue{maxConcurrent: maxConcurrent, maxQueued: maxQueued}
+}
+
+// PreemptAfter lets interactive executions waiting for a slot preempt batch
+// executions acquired with AcquirePreemptible once these have run for after.
+// Zero disables preemption.
+func (q *Queue) PreemptAfter(after time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.preemptAfter = after
+}
+
+// AcquirePreemptible acquires a slot for a batch execution like Acquire and
+// returns the context to run it with. When an interactive execution waits for
+// a slot, the context of the oldest batch execution that has run for the
+// PreemptAfter duration is cancelled with the cause ErrPreempted; the
+// execution should stop and release its slot.
+func (q *Queue) AcquirePreemptible(ctx context.Context, positions func(int)) (context.Context, func(), error) {
+	release, err := q.Acquire(ctx, Batch, positions)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	p := &preemptible{startedAt: time.Now(), cancel: cancel}
+	q.mu.Lock()
+	q.preemptible = append(q.preemptible, p)
+	q.mu.Unlock()
+
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			q.mu.Lock()
+			if index := slices.Index(q.preemptible, p); index >= 0 {
+				q.preemptible = slices.Delete(q.preemptible, index, index+1)
+			}
+			if p.preempted {
+				q.preempting--
+			}
+			q.mu.Unlock()
+			cancel(nil)
+			release()
+		})
+	}, nil
+}
+
+// Acquire waits for an execution slot and returns the function releasing it.
+// While waiting, positions is called with the 1-based queue position whenever it
+// changes. Acquire fails with ErrFull when the queue is full and with the
+// context's error when ctx ends first.
+func (q *Queue) Acquire(ctx context.Context, priority Priority, positions func(int)) (func(), error) {
+	q.mu.Lock()
+	if q.running < q.maxConcurrent && len(q.waiting) == 0 {
+		q.running++
+		q.mu.Unlock()
+		return q.releaseFunc(), nil
+	}
+	if q.maxQueued > 0 && len(q.waiting) >= q.maxQueued {
+		q.mu.Unlock()
+		return nil, ErrFull
+	}
+	w := &waiter{priority: priority, ready: make(chan struct{}), moved: make(chan struct{}, 1)}
+	index := len(q.waiting)
+	for index > 0 && q.waiting[index-1].priority > priority {
+		index--
+	}
+	q.waiting = slices.Insert(q.waiting, index, w)
+	q.notifyFrom(index + 1)
+	position := index + 1
+
+	// Interactive waiters check again when the next batch execution becomes preemptible
+	var preemptTimer <-chan time.Time
+	if priority == Interactive {
+		preemptTimer = timerAfter(q.preempt())
+	}
+	q.mu.Unlock()
+
+	if positions != nil {
+		positions(position)
+	}
+	for {
+		select {
+		case <-w.ready:
+			return q.releaseFunc(), nil
+		case <-preemptTimer:
+			q.mu.Lock()
+			preemptTimer = timerAfter(q.preempt())
+			q.mu.Unlock()
+		case <-w.moved:
+			q.mu.Lock()
+			position = slices.Index(q.waiting, w) + 1
+			q.mu.Unlock()
+			if position > 0 && positions != nil {
+				positions(position)
+			}
+		case <-ctx.Done():
+			q.mu.Lock()
+			if index := slices.Index(q.waiting, w); index >= 0 {
+				q.waiting = slices.Delete(q.waiting, index, index+1)
+				q.notifyFrom(index)
+				q.mu.Unlock()
+				return nil, ctx.Err()
+			}
+			q.mu.Unlock()
+			// The slot was granted concurrently; hand it on.
+			q.release()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Stats returns the number of running and waiting executions.
+func (q *Queue) Stats() (running, waiting int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.running, len(q.waiting)
+}
+
+func (q *Queue) releaseFunc() func() {
+	var once sync.Once
+	return func() { once.Do(q.release) }
+}
+
+// release frees a slot and grants it to the first waiter.
+func (q *Queue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.running--
+	if len(q.waiting) > 0 && q.running < q.maxConcurrent {
+		next := q.waiting[0]
+		q.waiting = q.waiting[1:]
+		q.running++
+		close(next.ready)
+		q.notifyFrom(0)
+	}
+}
+
+// preempt cancels the oldest batch executions that have run for preemptAfter
+// until every waiting interactive execution has a slot being freed. It
+// returns how long until the next batch execution becomes preemptible when an
+// interactive execution is still left without one, or zero. The caller holds q.mu.
+func (q *Queue) preempt() time.Duration {
+	if q.preemptAfter <= 0 {
+		return 0
+	}
+	interactive := 0
+	for _, w := range q.waiting {
+		if w.priority == Interactive {
+			interactive++
+		}
+	}
+	for _, p := range q.preemptible {
+		if interactive <= q.preempting {
+			return 0
+		}
+		if p.preempted {
+			continue
+		}
+		if age := time.Since(p.startedAt); age < q.preemptAfter {
+			return q.preemptAfter - age
+		}
+		p.preempted = true
+		q.preempting++
+		p.cancel(ErrPreempted)
+	}
+	return 0
+}
+
+// timerAfter returns a channel receiving after wait, or nil for zero.
+func timerAfter(wait time.Duration) <-chan time.Time {
+	if wait <= 0 {
+		return nil
+	}
+	return time.After(wait)
+}
+
+// notifyFrom signals the waiters from index on that their position changed.
+// The caller holds q.mu.
+func (q *Queue) notifyFrom(index int) {
+	for _, w := range q.waiting[index:] {
+		select {
+		case w.moved <- struct{}{}:
+		default:
+		}
+	}
+}
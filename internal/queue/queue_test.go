@@ -0,0 +1,156 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQueue_PriorityOrder(t *testing.T) {
+	q := New(1, 0)
+	release, err := q.Acquire(context.Background(), Interactive, nil)
+	if err != nil {
+		t.Fatalf("Acquire() returned error: %v", err)
+	}
+
+	// Queue a batch call, then an interactive one, which must run first.
+	order := make(chan string, 2)
+	start := func(name string, priority Priority) {
+		go func() {
+			release, err := q.Acquire(context.Background(), priority, nil)
+			if err != nil {
+				t.Errorf("Acquire(%s) returned error: %v", name, err)
+				return
+			}
+			order <- name
+			release()
+		}()
+	}
+	start("batch", Batch)
+	waitFor(t, q, 1)
+	start("interactive", Interactive)
+	waitFor(t, q, 2)
+
+	release()
+	if first, second := <-order, <-order; first != "interactive" || second != "batch" {
+		t.Errorf("Run order = %s, %s, want interactive, batch", first, second)
+	}
+}
+
+func TestQueue_FullAndCancel(t *testing.T) {
+	q := New(1, 1)
+	release, _ := q.Acquire(context.Background(), Interactive, nil)
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	positions := make(chan int, 1)
+	done := make(chan error, 1)
+	go func() {
+		_, err := q.Acquire(ctx, Batch, func(position int) { positions <- position })
+		done <- err
+	}()
+	if position := <-positions; position != 1 {
+		t.Errorf("Queue position = %d, want 1", position)
+	}
+
+	if _, err := q.Acquire(context.Background(), Interactive, nil); !errors.Is(err, ErrFull) {
+		t.Errorf("Acquire() on a full queue error = %v, want ErrFull", err)
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Cancelled Acquire() error = %v, want context.Canceled", err)
+	}
+	if running, waiting := q.Stats(); running != 1 || waiting != 0 {
+		t.Errorf("Stats() = %d running, %d waiting, want 1, 0", running, waiting)
+	}
+}
+
+func TestQueue_Preemption(t *testing.T) {
+	q := New(1, 0)
+	q.PreemptAfter(20 * time.Millisecond)
+	ctx, release, err := q.AcquirePreemptible(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("AcquirePreemptible() returned error: %v", err)
+	}
+
+	// A waiting batch call does not preempt
+	batchDone := make(chan struct{})
+	go func() {
+		release, err := q.Acquire(context.Background(), Batch, nil)
+		if err == nil {
+			release()
+		}
+		close(batchDone)
+	}()
+	waitFor(t, q, 1)
+	time.Sleep(30 * time.Millisecond)
+	if ctx.Err() != nil {
+		t.Fatal("A batch call should not preempt a batch execution")
+	}
+
+	// An interactive call preempts once the execution has run for 20ms
+	acquired := make(chan struct{})
+	go func() {
+		release, err := q.Acquire(context.Background(), Interactive, nil)
+		if err != nil {
+			t.Errorf("Acquire() returned error: %v", err)
+			return
+		}
+		close(acquired)
+		release()
+	}()
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("The batch execution was not preempted")
+	}
+	if cause := context.Cause(ctx); !errors.Is(cause, ErrPreempted) {
+		t.Errorf("Cause of the preempted context = %v, want ErrPreempted", cause)
+	}
+	release()
+	<-acquired
+	<-batchDone
+}
+
+func TestQueue_PreemptionDisabled(t *testing.T) {
+	q := New(1, 0)
+	ctx, release, _ := q.AcquirePreemptible(context.Background(), nil)
+	defer release()
+
+	waitCtx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := q.Acquire(waitCtx, Interactive, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Acquire() error = %v, want context.DeadlineExceeded", err)
+	}
+	if ctx.Err() != nil {
+		t.Error("Batch executions should not be preempted without PreemptAfter")
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	for input, want := range map[string]Priority{"": Interactive, "interactive": Interactive, "batch": Batch} {
+		if got, err := ParsePriority(input); err != nil || got != want {
+			t.Errorf("ParsePriority(%q) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+	if _, err := ParsePriority("urgent"); err == nil {
+		t.Error("ParsePriority() should reject unknown priorities")
+	}
+}
+
+// waitFor waits until n executions are queued.
+func waitFor(t *testing.T, q *Queue, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, waiting := q.Stats(); waiting == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %d queued executions", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,186 @@
+// Package quota limits the executions, CPU time and downloads of each client
+// over a sliding time window, so that one client cannot monopolize a shared
+// server.
+package quota
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultWindow is the time window quotas apply to when none is configured.
+const DefaultWindow = time.Hour
+
+// Limits are the quotas of each client. Zero values disable a limit.
+type Limits struct {
+	Window        time.Duration // Sliding window the limits apply to; DefaultWindow when zero
+	MaxExecutions int           // Executions started in the window
+	MaxCPU        time.Duration // CPU time used by the executions of the window
+	MaxDownload   int64         // Bytes received by the executions of the window
+}
+
+// Enabled reports whether any limit is set.
+func (l Limits) Enabled() bool {
+	return l.MaxExecutions > 0 || l.MaxCPU > 0 || l.MaxDownload > 0
+}
+
+// ExceededError is returned by Begin when a client has used up a quota.
+type ExceededError struct {
+	Client  string
+	Quota   string    // e.g. "100 executions per 1h0m0s"
+	RetryAt time.Time // When enough of the window's usage has expired
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("quota of %s exceeded for client %s, retry after %s",
+		e.Quota, e.Client, e.RetryAt.Format(time.RFC3339))
+}
+
+// execution is the usage of one execution counted in a window. cpu and
+// download stay zero while it runs.
+type execution struct {
+	startedAt time.Time
+	cpu       time.Duration
+	download  int64
+}
+
+// Tracker is a concurrency-safe quota tracker.
+type Tracker struct {
+	mu      sync.Mutex
+	limits  Limits
+	now     func() time.Time
+	clients map[string][]*execution // Oldest first
+}
+
+// New creates a Tracker enforcing limits.
+func New(limits Limits) *Tracker {
+	if limits.Window <= 0 {
+		limits.Window = DefaultWindow
+	}
+	return &Tracker{limits: limits, now: time.Now, clients: make(map[string][]*execution)}
+}
+
+// Begin counts an execution of client and returns the function recording the
+// CPU time and downloaded bytes it used once it ends. It fails with an
+// *ExceededError when the client has used up one of its quotas. Running
+// executions count towards the execution quota only.
+func (t *Tracker) Begin(client string) (func(cpu time.Duration, download int64), error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	executions := t.window(client)
+	if t.limits.MaxExecutions > 0 && len(executions) >= t.limits.MaxExecutions {
+		return nil, t.exceeded(client, fmt.Sprintf("%d executions", t.limits.MaxExecutions),
+			executions[len(executions)-t.limits.MaxExecutions].startedAt)
+	}
+	if t.limits.MaxCPU > 0 {
+		if at, ok := expiry(executions, func(e *execution) int64 { return int64(e.cpu) }, int64(t.limits.MaxCPU)); ok {
+			return nil, t.exceeded(client, fmt.Sprintf("%s of CPU time", t.limits.MaxCPU), at)
+		}
+	}
+	if t.limits.MaxDownload > 0 {
+		if at, ok := expiry(executions, func(e *execution) int64 { return e.download }, t.limits.MaxDownload); ok {
+			return nil, t.exceeded(client, fmt.Sprintf("%.1f MiB of downloads", float64(t.limits.MaxDownload)/(1<<20)), at)
+		}
+	}
+
+	e := &execution{startedAt: t.now()}
+	t.clients[client] = append(executions, e)
+	var once sync.Once
+	return func(cpu time.Duration, download int64) {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			e.cpu, e.download = cpu, download
+		})
+	}, nil
+}
+
+// expiry reports whether the usage of executions, as measured by value, has
+// reached limit, and if so when the oldest executions leave the window far
+// enough to bring it back below limit.
+func expiry(executions []*execution, value func(*execution) int64, limit int64) (time.Time, bool) {
+	var used int64
+	for _, e := range executions {
+		used += value(e)
+	}
+	if used < limit {
+		return time.Time{}, false
+	}
+	for _, e := range executions {
+		if used -= value(e); used < limit {
+			return e.startedAt, true
+		}
+	}
+	return executions[len(executions)-1].startedAt, true
+}
+
+// exceeded returns the error for a used up quota that frees up when the
+// execution started at startedAt leaves the window. The caller holds t.mu.
+func (t *Tracker) exceeded(client, quota string, startedAt time.Time) error {
+	return &ExceededError{
+		Client:  client,
+		Quota:   fmt.Sprintf("%s per %s", quota, t.limits.Window),
+		RetryAt: startedAt.Add(t.limits.Window),
+	}
+}
+
+// window drops the executions of client that left the window and returns the
+// remaining ones. Clients without executions are forgotten. The caller holds t.mu.
+func (t *Tracker) window(client string) []*execution {
+	executions := t.clients[client]
+	cutoff := t.now().Add(-t.limits.Window)
+	expired := sort.Search(len(executions), func(i int) bool { return executions[i].startedAt.After(cutoff) })
+	executions = executions[expired:]
+	if len(executions) == 0 {
+		delete(t.clients, client)
+		return nil
+	}
+	t.clients[client] = executions
+	return executions
+}
+
+// Status is the quota usage of a client in the current window.
+type Status struct {
+	Client string `json:"client"`
+	Window string `json:"window"`
+
+	Executions    int `json:"executions"`
+	MaxExecutions int `json:"max_executions,omitempty"`
+
+	CPUSeconds    float64 `json:"cpu_seconds"`
+	MaxCPUSeconds float64 `json:"max_cpu_seconds,omitempty"`
+
+	DownloadBytes    int64 `json:"download_bytes"`
+	MaxDownloadBytes int64 `json:"max_download_bytes,omitempty"`
+
+	// OldestExpiresAt is when the oldest counted execution leaves the window.
+	OldestExpiresAt *time.Time `json:"oldest_expires_at,omitempty"`
+}
+
+// Status returns the usage of client in the current window.
+func (t *Tracker) Status(client string) Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status := Status{
+		Client:           client,
+		Window:           t.limits.Window.String(),
+		MaxExecutions:    t.limits.MaxExecutions,
+		MaxCPUSeconds:    t.limits.MaxCPU.Seconds(),
+		MaxDownloadBytes: t.limits.MaxDownload,
+	}
+	executions := t.window(client)
+	for _, e := range executions {
+		status.Executions++
+		status.CPUSeconds += e.cpu.Seconds()
+		status.DownloadBytes += e.download
+	}
+	if len(executions) > 0 {
+		expiresAt := executions[0].startedAt.Add(t.limits.Window)
+		status.OldestExpiresAt = &expiresAt
+	}
+	return status
+}
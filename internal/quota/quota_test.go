@@ -0,0 +1,104 @@
+package quota
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock returns a Tracker whose clock is advanced by the returned function.
+func fakeClock(limits Limits) (*Tracker, func(time.Duration)) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := New(limits)
+	tracker.now = func() time.Time { return now }
+	return tracker, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestTracker_Limits(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   Limits
+		cpu      time.Duration
+		download int64
+		allowed  int
+		wantErr  string
+	}{
+		{"executions", Limits{MaxExecutions: 3}, 0, 0, 3, "quota of 3 executions per 1h0m0s exceeded for client key-1"},
+		{"cpu", Limits{MaxCPU: 10 * time.Second}, 4 * time.Second, 0, 3, "10s of CPU time"},
+		{"download", Limits{MaxDownload: 5 << 20}, 0, 2 << 20, 3, "5.0 MiB of downloads"},
+		{"unlimited", Limits{}, time.Minute, 1 << 30, 10, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker, advance := fakeClock(tt.limits)
+			for i := 0; i < tt.allowed; i++ {
+				end, err := tracker.Begin("key-1")
+				if err != nil {
+					t.Fatalf("Begin() #%d returned error: %v", i+1, err)
+				}
+				end(tt.cpu, tt.download)
+				advance(time.Minute)
+			}
+			if tt.wantErr == "" {
+				return
+			}
+
+			_, err := tracker.Begin("key-1")
+			var exceeded *ExceededError
+			if !errors.As(err, &exceeded) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Begin() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+			if _, err := tracker.Begin("key-2"); err != nil {
+				t.Errorf("Begin() for another client returned error: %v", err)
+			}
+
+			// The first execution leaves the window at RetryAt
+			advance(exceeded.RetryAt.Sub(tracker.now()) + time.Nanosecond)
+			if _, err := tracker.Begin("key-1"); err != nil {
+				t.Errorf("Begin() after RetryAt returned error: %v", err)
+			}
+		})
+	}
+}
+
+func TestTracker_RunningExecutions(t *testing.T) {
+	tracker, _ := fakeClock(Limits{MaxExecutions: 1})
+	end, err := tracker.Begin("key-1")
+	if err != nil {
+		t.Fatalf("Begin() returned error: %v", err)
+	}
+	if _, err := tracker.Begin("key-1"); err == nil {
+		t.Error("Begin() should count running executions")
+	}
+	end(time.Second, 0)
+}
+
+func TestTracker_Status(t *testing.T) {
+	tracker, advance := fakeClock(Limits{Window: 10 * time.Minute, MaxExecutions: 5, MaxCPU: time.Minute})
+	startedAt := tracker.now()
+	for _, cpu := range []time.Duration{2 * time.Second, 500 * time.Millisecond} {
+		end, _ := tracker.Begin("key-1")
+		end(cpu, 1024)
+		advance(time.Minute)
+	}
+
+	status := tracker.Status("key-1")
+	if status.Executions != 2 || status.CPUSeconds != 2.5 || status.DownloadBytes != 2048 {
+		t.Errorf("Status() usage = %d executions, %gs, %d bytes, want 2, 2.5s, 2048", status.Executions, status.CPUSeconds, status.DownloadBytes)
+	}
+	if status.MaxExecutions != 5 || status.MaxCPUSeconds != 60 || status.MaxDownloadBytes != 0 || status.Window != "10m0s" {
+		t.Errorf("Status() limits = %+v", status)
+	}
+	if status.OldestExpiresAt == nil || !status.OldestExpiresAt.Equal(startedAt.Add(10*time.Minute)) {
+		t.Errorf("Status().OldestExpiresAt = %v, want %v", status.OldestExpiresAt, startedAt.Add(10*time.Minute))
+	}
+
+	advance(10 * time.Minute)
+	if status := tracker.Status("key-1"); status.Executions != 0 || status.OldestExpiresAt != nil {
+		t.Errorf("Status() after the window = %+v, want no executions", status)
+	}
+	if len(tracker.clients) != 0 {
+		t.Errorf("Tracker keeps %d clients without executions", len(tracker.clients))
+	}
+}
@@ -0,0 +1,235 @@
+// Package schedule keeps the registered schedules and runs each of them in its
+// own goroutine until it is cancelled.
+package schedule
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// URIScheme is the MCP resource URI scheme used for schedules.
+const URIScheme = "schedule://"
+
+const (
+	// DefaultMaxSchedules is the number of schedules allowed when no maximum is configured.
+	DefaultMaxSchedules = 20
+
+	// DefaultKeepResults is the number of results kept per schedule when none is configured.
+	DefaultKeepResults = 10
+)
+
+// ErrNotFound is returned for unknown schedule IDs.
+var ErrNotFound = errors.New("schedule not found")
+
+// Result describes one run of a schedule.
+type Result struct {
+	RunAt    time.Time     `json:"run_at"`
+	Status   string        `json:"status"` // "success" or "error"
+	Output   string        `json:"output"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// Schedule is a stored execution run on a cron expression.
+type Schedule struct {
+	ID        string         `json:"id"`
+	Name      string         `json:"name,omitempty"`
+	Cron      string         `json:"cron"`
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments"`
+	CreatedAt time.Time      `json:"created_at"`
+	NextRun   time.Time      `json:"next_run"`
+	Runs      int            `json:"runs"`
+	Results   []Result       `json:"results"` // Newest first
+}
+
+// URI returns the resource URI of the schedule.
+func (s Schedule) URI() string {
+	return URIScheme + s.ID
+}
+
+// RunFunc runs the tool of a schedule with its arguments.
+type RunFunc func(ctx context.Context, tool string, arguments map[string]any) Result
+
+type entry struct {
+	schedule Schedule
+	cron     Cron
+	cancel   context.CancelFunc
+}
+
+// Scheduler is a concurrency-safe set of running schedules.
+type Scheduler struct {
+	run          RunFunc
+	maxSchedules int
+	keepResults  int
+
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+// New creates a Scheduler running schedules with run. It holds up to
+// maxSchedules schedules (DefaultMaxSchedules when <= 0) and keeps the last
+// keepResults results of each (DefaultKeepResults when <= 0).
+func New(run RunFunc, maxSchedules, keepResults int) *Scheduler {
+	if maxSchedules <= 0 {
+		maxSchedules = DefaultMaxSchedules
+	}
+	if keepResults <= 0 {
+		keepResults = DefaultKeepResults
+	}
+	return &Scheduler{run: run, maxSchedules: maxSchedules, keepResults: keepResults, entries: make(map[string]*entry)}
+}
+
+// Add starts running tool with arguments on the cron expression expr and returns
+// the stored schedule.
+func (s *Scheduler) Add(name, expr, tool string, arguments map[string]any) (Schedule, error) {
+	cron, err := ParseCron(expr)
+	if err != nil {
+		return Schedule{}, err
+	}
+	now := time.Now()
+	next := cron.Next(now)
+	if next.IsZero() {
+		return Schedule{}, fmt.Errorf("cron expression %q never matches", expr)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.entries) >= s.maxSchedules {
+		return Schedule{}, fmt.Errorf("at most %d schedules are allowed, cancel one first", s.maxSchedules)
+	}
+
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &entry{
+		schedule: Schedule{
+			ID:        hex.EncodeToString(b),
+			Name:      name,
+			Cron:      expr,
+			Tool:      tool,
+			Arguments: arguments,
+			CreatedAt: now,
+			NextRun:   next,
+		},
+		cron:   cron,
+		cancel: cancel,
+	}
+	s.entries[e.schedule.ID] = e
+	go s.loop(ctx, e)
+	return e.schedule, nil
+}
+
+// Get returns the schedule with the given ID.
+func (s *Scheduler) Get(id string) (Schedule, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[id]
+	if !ok {
+		return Schedule{}, false
+	}
+	return e.snapshot(), true
+}
+
+// List returns all schedules, oldest first.
+func (s *Scheduler) List() []Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]Schedule, 0, len(s.entries))
+	for _, e := range s.entries {
+		list = append(list, e.snapshot())
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list
+}
+
+// Cancel stops the schedule with the given ID and forgets it. A run in progress
+// is cancelled through its context.
+func (s *Scheduler) Cancel(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[id]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+	e.cancel()
+	delete(s.entries, id)
+	return nil
+}
+
+// Close cancels all schedules.
+func (s *Scheduler) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, e := range s.entries {
+		e.cancel()
+		delete(s.entries, id)
+	}
+}
+
+// loop runs the schedule at each matching time until ctx is cancelled. Runs never
+// overlap; times that pass while a run is in progress are skipped.
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	for {
+		s.mu.Lock()
+		next := e.schedule.NextRun
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.runOnce(ctx, e)
+		if ctx.Err() != nil {
+			return
+		}
+
+		next = e.cron.Next(time.Now())
+		if next.IsZero() {
+			return
+		}
+		s.mu.Lock()
+		e.schedule.NextRun = next
+		s.mu.Unlock()
+	}
+}
+
+// runOnce runs the schedule and records the result, keeping the newest keepResults.
+func (s *Scheduler) runOnce(ctx context.Context, e *entry) {
+	s.mu.Lock()
+	tool, arguments := e.schedule.Tool, e.schedule.Arguments
+	s.mu.Unlock()
+
+	startedAt := time.Now()
+	result := s.run(ctx, tool, arguments)
+	result.RunAt = startedAt
+	result.Duration = time.Since(startedAt)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.schedule.Runs++
+	e.schedule.Results = append([]Result{result}, e.schedule.Results...)
+	if len(e.schedule.Results) > s.keepResults {
+		e.schedule.Results = e.schedule.Results[:s.keepResults]
+	}
+}
+
+// snapshot copies the schedule so callers cannot modify stored results.
+func (e *entry) snapshot() Schedule {
+	schedule := e.schedule
+	schedule.Results = append([]Result(nil), e.schedule.Results...)
+	return schedule
+}
@@ -0,0 +1,67 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestScheduler_AddListCancel(t *testing.T) {
+	s := New(func(ctx context.Context, tool string, arguments map[string]any) Result {
+		return Result{Status: "success"}
+	}, 2, 0)
+	defer s.Close()
+
+	first, err := s.Add("probe", "@daily", "execute-bash", map[string]any{"script": "date"})
+	if err != nil {
+		t.Fatalf("Add() returned error: %v", err)
+	}
+	if first.ID == "" || first.NextRun.IsZero() || first.URI() != URIScheme+first.ID {
+		t.Errorf("Add() = %+v, want an ID and the next run", first)
+	}
+	second, _ := s.Add("", "0 * * * *", "execute-python", map[string]any{"code": "print(1)"})
+
+	if _, err := s.Add("", "@hourly", "execute-go", nil); err == nil {
+		t.Error("Add() should fail beyond the maximum number of schedules")
+	}
+	if _, err := s.Add("", "every minute", "execute-go", nil); err == nil {
+		t.Error("Add() should reject invalid cron expressions")
+	}
+
+	if list := s.List(); len(list) != 2 || list[0].ID != first.ID || list[1].ID != second.ID {
+		t.Errorf("List() = %+v, want both schedules oldest first", list)
+	}
+
+	if err := s.Cancel(first.ID); err != nil {
+		t.Errorf("Cancel() returned error: %v", err)
+	}
+	if _, ok := s.Get(first.ID); ok {
+		t.Error("Get() should not find cancelled schedules")
+	}
+	if err := s.Cancel(first.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Cancel() of an unknown schedule error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestScheduler_KeepsLastResults(t *testing.T) {
+	runs := 0
+	s := New(func(ctx context.Context, tool string, arguments map[string]any) Result {
+		runs++
+		return Result{Status: "success", Output: fmt.Sprintf("run %d", runs)}
+	}, 0, 3)
+	defer s.Close()
+
+	added, _ := s.Add("", "@yearly", "execute-bash", map[string]any{"script": "date"})
+	for range 5 {
+		s.runOnce(context.Background(), s.entries[added.ID])
+	}
+
+	got, _ := s.Get(added.ID)
+	if got.Runs != 5 || len(got.Results) != 3 {
+		t.Fatalf("Get() = %d runs, %d results, want 5 runs, 3 results", got.Runs, len(got.Results))
+	}
+	if got.Results[0].Output != "run 5" || got.Results[2].Output != "run 3" {
+		t.Errorf("Results = %+v, want runs 5 to 3, newest first", got.Results)
+	}
+}
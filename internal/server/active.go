@@ -0,0 +1,127 @@
+// Package server tracks the running execute tool calls and lists them through
+// the list-active-executions tool and the executions://active resource.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+)
+
+// activeExecutionsURI is the resource listing the running executions of the caller.
+const activeExecutionsURI = "executions://active"
+
+// activeExecution is a running execute tool call, including calls waiting for
+// a slot in the queue.
+type activeExecution struct {
+	ID         string    `json:"id"`                // Execution ID, as in execution://{id} and the container labels
+	Tool       string    `json:"tool"`              // Execute tool running the code
+	Session    string    `json:"session,omitempty"` // Tag of the MCP session, as in the container labels
+	Client     string    `json:"client"`            // Client that made the call
+	StartedAt  time.Time `json:"started_at"`
+	RunningFor string    `json:"running_for"` // Time since the call started, e.g. "1m5s"
+}
+
+// activeExecutions tracks the running execute tool calls.
+type activeExecutions struct {
+	mu      sync.Mutex
+	running map[string]activeExecution // By execution ID
+}
+
+func newActiveExecutions() *activeExecutions {
+	return &activeExecutions{running: make(map[string]activeExecution)}
+}
+
+// begin registers the execution of ctx running in tool and returns the
+// function unregistering it. A nil activeExecutions tracks nothing.
+func (a *activeExecutions) begin(ctx context.Context, tool string) func() {
+	if a == nil {
+		return func() {}
+	}
+	execution := activeExecution{
+		ID:        executor.ExecutionID(ctx),
+		Tool:      tool,
+		Client:    clientID(ctx),
+		StartedAt: time.Now(),
+	}
+	if session := executor.SessionID(ctx); session != "" {
+		execution.Session = executor.SessionTag(session)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.running[execution.ID] = execution
+	return func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		delete(a.running, execution.ID)
+	}
+}
+
+// list returns the running executions of client at now, oldest first.
+func (a *activeExecutions) list(client string, now time.Time) []activeExecution {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	executions := []activeExecution{}
+	for _, execution := range a.running {
+		if execution.Client == client {
+			execution.RunningFor = now.Sub(execution.StartedAt).Round(time.Second).String()
+			executions = append(executions, execution)
+		}
+	}
+	slices.SortFunc(executions, func(a, b activeExecution) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+	return executions
+}
+
+// register adds the list-active-executions tool and the executions://active
+// resource to mcpServer.
+func (a *activeExecutions) register(mcpServer *server.MCPServer) {
+	mcpServer.AddTool(mcp.NewTool(
+		"list-active-executions",
+		mcp.WithDescription(`List your executions that are currently running or waiting for a slot, with how long they have been running.
+The IDs match the execution://{id} resources and, in Docker mode, the mcp-exec-<language>-<id> container names.`),
+		mcp.WithReadOnlyHintAnnotation(true),
+	), a.handleList)
+
+	mcpServer.AddResource(
+		mcp.NewResource(
+			activeExecutionsURI,
+			"Active executions",
+			mcp.WithResourceDescription("Executions of the caller currently running or waiting for a slot, with how long they have been running"),
+			mcp.WithMIMEType("application/json"),
+		),
+		a.readResource,
+	)
+}
+
+// handleList returns the running executions of the caller as JSON.
+func (a *activeExecutions) handleList(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	data, err := json.MarshalIndent(a.list(clientID(ctx), time.Now()), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode active executions: %v", err)
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// readResource returns the running executions of the caller as JSON.
+func (a *activeExecutions) readResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	data, err := json.MarshalIndent(a.list(clientID(ctx), time.Now()), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode active executions: %v", err)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: "application/json",
+			Text:     string(data),
+		},
+	}, nil
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+)
+
+func TestActiveExecutions(t *testing.T) {
+	active := newActiveExecutions()
+	call := func(id, client string) context.Context {
+		ctx := executor.WithSessionID(executor.WithExecutionID(context.Background(), id), "session-1")
+		if client != anonymousClient {
+			ctx = withClientID(ctx, client)
+		}
+		return ctx
+	}
+
+	endFirst := active.begin(call("first", "key-1"), "execute-python")
+	endSecond := active.begin(call("second", "key-1"), "execute-bash")
+	endOther := active.begin(call("other", anonymousClient), "execute-go")
+	defer endOther()
+
+	executions := active.list("key-1", time.Now().Add(time.Minute))
+	if len(executions) != 2 || executions[0].ID != "first" || executions[1].ID != "second" {
+		t.Fatalf("list() = %+v, want the two executions of the client, oldest first", executions)
+	}
+	if got := executions[0]; got.Tool != "execute-python" || got.Session != executor.SessionTag("session-1") || got.RunningFor != "1m0s" {
+		t.Errorf("list()[0] = %+v", got)
+	}
+
+	endFirst()
+	endSecond()
+	if executions := active.list("key-1", time.Now()); len(executions) != 0 {
+		t.Errorf("list() after the executions ended = %+v, want none", executions)
+	}
+}
+
+func TestHistoryRecorder_TracksActiveExecutions(t *testing.T) {
+	active := newActiveExecutions()
+	recorder := &historyRecorder{active: active}
+	var during []activeExecution
+	handler := recorder.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		during = active.list(anonymousClient, time.Now())
+		if executor.ClientID(ctx) != anonymousClient {
+			t.Errorf("ClientID() = %q, want %q", executor.ClientID(ctx), anonymousClient)
+		}
+		return nil, nil
+	})
+
+	if _, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "execute-bash"}}); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if len(during) != 1 || during[0].Tool != "execute-bash" || during[0].ID == "" {
+		t.Errorf("Active executions during the call = %+v, want the call", during)
+	}
+	if after := active.list(anonymousClient, time.Now()); len(after) != 0 {
+		t.Errorf("Active executions after the call = %+v, want none", after)
+	}
+}
+
+func TestNewMCPServer_ListActiveExecutions(t *testing.T) {
+	mcpServer := NewMCPServer("subprocess")
+	tool := mcpServer.GetTool("list-active-executions")
+	if tool == nil {
+		t.Fatal("list-active-executions tool not registered")
+	}
+	result, err := tool.Handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil || result.IsError {
+		t.Fatalf("list-active-executions failed: %v %s", err, resultText(result))
+	}
+	var executions []activeExecution
+	if err := json.Unmarshal([]byte(resultText(result)), &executions); err != nil || len(executions) != 0 {
+		t.Errorf("list-active-executions = %q, want an empty JSON list", resultText(result))
+	}
+}
@@ -0,0 +1,98 @@
+// Package server provides bearer-token / API-key authentication middleware
+// for the SSE and streamable HTTP transports, which identifies the clients
+// quotas apply to.
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// AuthTokensEnvVar names the environment variable holding comma-separated API tokens.
+const AuthTokensEnvVar = "MCP_EXECUTOR_AUTH_TOKENS"
+
+// authMiddleware rejects requests that do not present one of the configured tokens,
+// either as "Authorization: Bearer <token>" or as an "X-API-Key" header.
+// With no tokens configured, requests pass through unauthenticated.
+func authMiddleware(tokens []string, next http.Handler) http.Handler {
+	if len(tokens) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !validToken(requestToken(r), tokens) {
+			logger.Debug("Rejected unauthenticated request from %s to %s", r.RemoteAddr, r.URL.Path)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mcp-executor"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(withClientID(r.Context(), tokenClientID(requestToken(r)))))
+	})
+}
+
+// anonymousClient identifies the clients of servers without tokens, including
+// stdio clients.
+const anonymousClient = "anonymous"
+
+type clientIDKey struct{}
+
+// withClientID returns a context whose calls are made by client id.
+func withClientID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, clientIDKey{}, id)
+}
+
+// clientID returns the client making the calls of ctx, or anonymousClient.
+func clientID(ctx context.Context) string {
+	if id, ok := ctx.Value(clientIDKey{}).(string); ok {
+		return id
+	}
+	return anonymousClient
+}
+
+// tokenClientID identifies the client of a token without revealing it, e.g.
+// "key-9f86d081".
+func tokenClientID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "key-" + hex.EncodeToString(sum[:4])
+}
+
+// requestToken extracts the presented credential from the request headers.
+func requestToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if scheme, token, ok := strings.Cut(auth, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+// validToken compares the presented token against every configured token in constant time.
+func validToken(presented string, tokens []string) bool {
+	if presented == "" {
+		return false
+	}
+	valid := false
+	for _, token := range tokens {
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(token)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// ParseAuthTokens splits a comma-separated token list, dropping empty entries.
+func ParseAuthTokens(value string) []string {
+	var tokens []string
+	for token := range strings.SplitSeq(value, ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		tokens     []string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{
+			name:       "no tokens configured allows all",
+			tokens:     nil,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "missing credentials",
+			tokens:     []string{"secret"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "valid bearer token",
+			tokens:     []string{"secret"},
+			headers:    map[string]string{"Authorization": "Bearer secret"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "bearer scheme is case-insensitive",
+			tokens:     []string{"secret"},
+			headers:    map[string]string{"Authorization": "bearer secret"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid bearer token",
+			tokens:     []string{"secret"},
+			headers:    map[string]string{"Authorization": "Bearer wrong"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "basic auth is rejected",
+			tokens:     []string{"secret"},
+			headers:    map[string]string{"Authorization": "Basic secret"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "valid api key header",
+			tokens:     []string{"first", "second"},
+			headers:    map[string]string{"X-API-Key": "second"},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := authMiddleware(tt.tokens, next)
+			req := httptest.NewRequest(http.MethodGet, "/sse", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if rec.Code == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 response should include WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestParseAuthTokens(t *testing.T) {
+	got := ParseAuthTokens(" a , ,b,")
+	if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("ParseAuthTokens() = %v, want [a b]", got)
+	}
+
+	if got := ParseAuthTokens(""); len(got) != 0 {
+		t.Errorf("ParseAuthTokens(\"\") = %v, want empty", got)
+	}
+}
+
+func TestAuthMiddleware_ClientID(t *testing.T) {
+	var got string
+	handler := authMiddleware([]string{"first", "second"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = clientID(r.Context())
+	}))
+
+	ids := make(map[string]bool)
+	for _, token := range []string{"first", "second"} {
+		req := httptest.NewRequest(http.MethodGet, "/mcp", nil)
+		req.Header.Set("X-API-Key", token)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		if !strings.HasPrefix(got, "key-") || len(got) != len("key-")+8 || strings.Contains(got, token) {
+			t.Errorf("clientID() = %q, want key- and 8 hex digits", got)
+		}
+		ids[got] = true
+	}
+	if len(ids) != 2 {
+		t.Errorf("Tokens share the client ID %v", ids)
+	}
+	if got := clientID(context.Background()); got != anonymousClient {
+		t.Errorf("clientID() without a token = %q, want %q", got, anonymousClient)
+	}
+}
@@ -0,0 +1,178 @@
+// Package server implements the opt-in self-healing execution mode, which asks the
+// client LLM via MCP sampling to repair failed code and re-runs it.
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+const autoFixSystemPrompt = `You repair programs that failed to execute.
+Reply with ONLY the complete corrected program, without explanations and without markdown fences.`
+
+// sampler requests an LLM completion from the connected client.
+type sampler interface {
+	RequestSampling(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error)
+}
+
+// autoFixer re-runs failed executions with client-proposed fixes up to maxAttempts times.
+type autoFixer struct {
+	sampler     sampler
+	maxAttempts int
+	guard       *privilegeGuard // Confirmed the original call; fixes needing more are not run
+}
+
+// middleware wraps execute-* tools with the repair loop and appends the repair trail.
+func (a *autoFixer) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, request)
+		if err != nil || result == nil || !result.IsError || a.maxAttempts <= 0 {
+			return result, err
+		}
+
+		codeKey := "code"
+		if _, ok := request.GetArguments()["script"]; ok {
+			codeKey = "script"
+		}
+		code := request.GetString(codeKey, "")
+		if !strings.HasPrefix(request.Params.Name, "execute-") || code == "" {
+			return result, nil
+		}
+
+		var trail []string
+		for attempt := 1; attempt <= a.maxAttempts; attempt++ {
+			errorText := resultText(result)
+			logger.InfoContext(ctx, "Auto-fix attempt %d/%d for %s", attempt, a.maxAttempts, request.Params.Name)
+
+			fixed, sampleErr := a.proposeFix(ctx, request.Params.Name, code, errorText)
+			if sampleErr != nil {
+				logger.WarnContext(ctx, "Auto-fix sampling failed: %v", sampleErr)
+				trail = append(trail, fmt.Sprintf("Attempt %d: no fix proposed (%v)", attempt, sampleErr))
+				break
+			}
+			if fixed == code {
+				trail = append(trail, fmt.Sprintf("Attempt %d: client proposed unchanged code", attempt))
+				break
+			}
+
+			retry := withArgument(request, codeKey, fixed)
+			if reasons := a.unconfirmedReasons(ctx, request, retry); len(reasons) > 0 {
+				trail = append(trail, fmt.Sprintf("Attempt %d: proposed code not run, it %s without confirmation", attempt, strings.Join(reasons, "; ")))
+				break
+			}
+			code = fixed
+			result, err = next(ctx, retry)
+			if err != nil || result == nil {
+				return result, err
+			}
+
+			status := "failed"
+			if !result.IsError {
+				status = "succeeded"
+			}
+			trail = append(trail, fmt.Sprintf("Attempt %d %s with code:\n%s", attempt, status, code))
+			if !result.IsError {
+				break
+			}
+		}
+
+		if len(trail) > 0 {
+			result.Content = append(result.Content, mcp.NewTextContent(
+				"\n--- auto-fix repair trail ---\n"+strings.Join(trail, "\n\n"),
+			))
+		}
+		return result, nil
+	}
+}
+
+// unconfirmedReasons lists why retry, running proposed code, would need a
+// confirmation that the guard, outside the repair loop, did not ask for request.
+func (a *autoFixer) unconfirmedReasons(ctx context.Context, request, retry mcp.CallToolRequest) []string {
+	if a.guard == nil {
+		return nil
+	}
+	onHost := a.guard.onHost(ctx)
+	confirmed := a.guard.privilegedReasons(request, onHost)
+	var reasons []string
+	for _, reason := range a.guard.privilegedReasons(retry, onHost) {
+		if !slices.Contains(confirmed, reason) {
+			reasons = append(reasons, reason)
+		}
+	}
+	return reasons
+}
+
+// proposeFix asks the client LLM for corrected code.
+func (a *autoFixer) proposeFix(ctx context.Context, toolName, code, errorText string) (string, error) {
+	language := strings.TrimSuffix(strings.TrimPrefix(toolName, "execute-"), readOnlySuffix)
+	prompt := fmt.Sprintf("This %s program failed.\n\nProgram:\n%s\n\nError output:\n%s\n\nReturn the corrected program.",
+		language, code, errorText)
+
+	response, err := a.sampler.RequestSampling(ctx, mcp.CreateMessageRequest{
+		CreateMessageParams: mcp.CreateMessageParams{
+			Messages: []mcp.SamplingMessage{
+				{Role: mcp.RoleUser, Content: mcp.NewTextContent(prompt)},
+			},
+			SystemPrompt: autoFixSystemPrompt,
+			MaxTokens:    4096,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	text := samplingText(response)
+	if text == "" {
+		return "", fmt.Errorf("client returned no text")
+	}
+	return stripCodeFence(text), nil
+}
+
+// samplingText extracts the text of a sampling response.
+func samplingText(response *mcp.CreateMessageResult) string {
+	if response == nil {
+		return ""
+	}
+	switch content := response.Content.(type) {
+	case mcp.TextContent:
+		return content.Text
+	case *mcp.TextContent:
+		return content.Text
+	case map[string]any:
+		if text, ok := content["text"].(string); ok {
+			return text
+		}
+	}
+	return ""
+}
+
+// stripCodeFence removes a surrounding markdown code fence, if any.
+func stripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	text = strings.TrimPrefix(text, "```")
+	if newline := strings.Index(text, "\n"); newline >= 0 {
+		text = text[newline+1:] // drop the language tag line
+	}
+	text = strings.TrimSuffix(strings.TrimSpace(text), "```")
+	return strings.TrimSpace(text)
+}
+
+// withArgument returns a copy of request with one argument replaced.
+func withArgument(request mcp.CallToolRequest, key string, value any) mcp.CallToolRequest {
+	args := make(map[string]any, len(request.GetArguments())+1)
+	for k, v := range request.GetArguments() {
+		args[k] = v
+	}
+	args[key] = value
+	request.Params.Arguments = args
+	return request
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// fakeSampler returns canned completions in order.
+type fakeSampler struct {
+	responses []string
+	err       error
+	calls     int
+}
+
+func (f *fakeSampler) RequestSampling(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	text := f.responses[f.calls]
+	f.calls++
+	return &mcp.CreateMessageResult{
+		SamplingMessage: mcp.SamplingMessage{Role: mcp.RoleAssistant, Content: mcp.NewTextContent(text)},
+	}, nil
+}
+
+// fixedWhen fails every execution unless the code contains marker.
+func fixedWhen(marker string, codes *[]string) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		code := request.GetString("code", "")
+		*codes = append(*codes, code)
+		if strings.Contains(code, marker) {
+			return mcp.NewToolResultText("ok"), nil
+		}
+		return mcp.NewToolResultError("NameError: name 'x' is not defined"), nil
+	}
+}
+
+func TestAutoFixer_RepairsFailedExecution(t *testing.T) {
+	sampler := &fakeSampler{responses: []string{"print(y)", "```python\nx = 1\nprint(x)\n```"}}
+	fixer := &autoFixer{sampler: sampler, maxAttempts: 3}
+
+	var codes []string
+	handler := fixer.middleware(fixedWhen("x = 1", &codes))
+
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name:      "execute-python",
+		Arguments: map[string]any{"code": "print(x)"},
+	}}
+	result, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if result.IsError {
+		t.Error("Result should succeed after repair")
+	}
+	if len(codes) != 3 {
+		t.Fatalf("Expected 3 executions (original + 2 fixes), got %d", len(codes))
+	}
+	if codes[2] != "x = 1\nprint(x)" {
+		t.Errorf("Code fence should be stripped, got %q", codes[2])
+	}
+	if trail := resultText(result); !strings.Contains(trail, "auto-fix repair trail") || !strings.Contains(trail, "Attempt 2 succeeded") {
+		t.Errorf("Result should include the repair trail, got: %s", trail)
+	}
+}
+
+func TestAutoFixer_StopsAfterMaxAttempts(t *testing.T) {
+	sampler := &fakeSampler{responses: []string{"a()", "b()", "c()"}}
+	fixer := &autoFixer{sampler: sampler, maxAttempts: 2}
+
+	var codes []string
+	handler := fixer.middleware(fixedWhen("never", &codes))
+
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name:      "execute-python",
+		Arguments: map[string]any{"code": "print(x)"},
+	}}
+	result, _ := handler(context.Background(), request)
+
+	if !result.IsError {
+		t.Error("Result should still be an error")
+	}
+	if sampler.calls != 2 {
+		t.Errorf("Expected 2 sampling requests, got %d", sampler.calls)
+	}
+}
+
+func TestAutoFixer_SamplingUnavailable(t *testing.T) {
+	fixer := &autoFixer{sampler: &fakeSampler{err: errors.New("session does not support sampling")}, maxAttempts: 2}
+
+	var codes []string
+	handler := fixer.middleware(fixedWhen("never", &codes))
+
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name:      "execute-python",
+		Arguments: map[string]any{"code": "print(x)"},
+	}}
+	result, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if len(codes) != 1 {
+		t.Errorf("Code should not be re-run without a fix, ran %d times", len(codes))
+	}
+	if !strings.Contains(resultText(result), "no fix proposed") {
+		t.Errorf("Trail should explain that no fix was proposed, got: %s", resultText(result))
+	}
+}
+
+func TestAutoFixer_UnconfirmedFix(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		fix     string
+		wantRan int
+	}{
+		{"fix adding sudo is not run", "ls /root", "sudo ls /root", 1},
+		{"fix keeping confirmed sudo runs", "sudo ls /rot", "sudo ls /root", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard := &privilegeGuard{elicitor: &fakeElicitor{}, subprocess: true}
+			fixer := &autoFixer{sampler: &fakeSampler{responses: []string{tt.fix}}, maxAttempts: 1, guard: guard}
+			var scripts []string
+			handler := fixer.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				scripts = append(scripts, request.GetString("script", ""))
+				return mcp.NewToolResultError("ls: cannot open directory"), nil
+			})
+
+			result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{
+				Name:      "execute-bash",
+				Arguments: map[string]any{"script": tt.script},
+			}})
+			if err != nil {
+				t.Fatalf("handler returned error: %v", err)
+			}
+			if len(scripts) != tt.wantRan {
+				t.Errorf("Ran %d scripts %q, want %d", len(scripts), scripts, tt.wantRan)
+			}
+			if trail := resultText(result); (tt.wantRan == 1) != strings.Contains(trail, "not run, it runs sudo-like commands on the host without confirmation") {
+				t.Errorf("Trail should explain only unconfirmed fixes were not run, got: %s", trail)
+			}
+		})
+	}
+}
+
+func TestAutoFixer_SuccessPassesThrough(t *testing.T) {
+	sampler := &fakeSampler{}
+	fixer := &autoFixer{sampler: sampler, maxAttempts: 2}
+
+	var codes []string
+	handler := fixer.middleware(fixedWhen("print", &codes))
+
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name:      "execute-python",
+		Arguments: map[string]any{"code": "print(1)"},
+	}}
+	result, _ := handler(context.Background(), request)
+
+	if result.IsError || sampler.calls != 0 || len(result.Content) != 1 {
+		t.Errorf("Successful executions should be returned unchanged")
+	}
+}
+
+func TestStripCodeFence(t *testing.T) {
+	tests := map[string]string{
+		"print(1)":                  "print(1)",
+		"```\nprint(1)\n```":        "print(1)",
+		"```python\nprint(1)\n```":  "print(1)",
+		"  ```bash\necho hi\n```  ": "echo hi",
+	}
+	for input, want := range tests {
+		if got := stripCodeFence(input); got != want {
+			t.Errorf("stripCodeFence(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Package server answers identical repeat execute tool calls of a client from
+// the result cache instead of running the code again, as long as the execution
+// mode of the session and the Docker images have not changed since.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/cache"
+	"github.com/ylchen07/mcp-executor/internal/config"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// resultCache reuses the results of successful execute-* tool calls.
+type resultCache struct {
+	cache *cache.Cache
+	modes *sessionModes // Execution modes of the sessions, running the calls
+
+	mu     sync.RWMutex
+	images string // Docker images running container-mode calls, as JSON
+}
+
+// setImages keys the results of later calls by images, which the reloader
+// may change.
+func (r *resultCache) setImages(images config.ImageConfig) {
+	if r == nil {
+		return
+	}
+	data, _ := json.Marshal(images)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.images = string(data)
+}
+
+// runner identifies what runs the calls of the session of ctx: its execution
+// mode and the images of the container modes.
+func (r *resultCache) runner(ctx context.Context) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.modes.mode(ctx) + " " + r.images
+}
+
+// middleware returns the cached result of an identical earlier call, or runs the
+// call and caches its result when it succeeded. Scheduled runs always run, as
+// do the calls of sessions that restored a snapshot, whose image may differ.
+func (r *resultCache) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !isExecutionTool(request.Params.Name) || isScheduledRun(ctx) || executor.SnapshotImages(ctx) != nil {
+			return next(ctx, request)
+		}
+		key, ok := cacheKey(clientID(ctx), r.runner(ctx), request)
+		if !ok {
+			return next(ctx, request)
+		}
+
+		if data, ok := r.cache.Get(key); ok {
+			raw := json.RawMessage(data)
+			if result, err := mcp.ParseCallToolResult(&raw); err == nil {
+				logger.InfoContext(ctx, "Returning cached result of an identical %s call", request.Params.Name)
+				return result, nil
+			}
+		}
+
+		result, err := next(ctx, request)
+		// Reports belong to the execution that wrote them, so a cached result could not link them
+		if err != nil || result == nil || result.IsError || len(executor.ReportedArtifacts(ctx)) > 0 {
+			return result, err
+		}
+		if data, err := json.Marshal(result); err == nil {
+			if err := r.cache.Put(key, data); err != nil {
+				logger.WarnContext(ctx, "Failed to persist cached result: %v", err)
+			}
+		}
+		return result, nil
+	}
+}
+
+// cacheKey derives the cache key from the client, the runner of its calls, the
+// tool name and its arguments; clients never get the results of each other's
+// calls, nor sessions those of another execution mode or image. The
+// timeout and priority do not change the output of a successful run and are
+// left out. Calls using a named workspace or mounts depend on the files in them
+// and are not cached, nor are calls saving a snapshot, which is saved only when
+// the code runs, benchmarks, whose timings are measured anew on every call, and
+// the web tools, whose pages and responses change.
+func cacheKey(client, runner string, request mcp.CallToolRequest) (string, bool) {
+	arguments := request.GetArguments()
+	if request.GetString("workspace", "") != "" || request.GetString("mounts", "") != "" || request.GetString("snapshot", "") != "" ||
+		request.Params.Name == "execute-benchmark" || isWebTool(request.Params.Name) {
+		return "", false
+	}
+	keyed := make(map[string]any, len(arguments))
+	for name, value := range arguments {
+		if name != "timeout" && name != "priority" {
+			keyed[name] = value
+		}
+	}
+	// Map keys are marshaled in sorted order, so equal arguments give equal keys.
+	data, err := json.Marshal(keyed)
+	if err != nil {
+		return "", false
+	}
+	return cache.Key(client, runner, request.Params.Name, string(data)), true
+}
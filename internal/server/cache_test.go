@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/config"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+)
+
+func TestResultCache_Middleware(t *testing.T) {
+	results := newResultCache(config.CacheConfig{TTL: time.Minute})
+	runs := 0
+	handler := results.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		runs++
+		if request.GetString("code", "") == "fail" {
+			return mcp.NewToolResultError("failed"), nil
+		}
+		return mcp.NewToolResultText("output"), nil
+	})
+
+	call := func(arguments map[string]any) *mcp.CallToolResult {
+		result, err := handler(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "execute-python", Arguments: arguments},
+		})
+		if err != nil {
+			t.Fatalf("handler returned error: %v", err)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name      string
+		arguments map[string]any
+		wantRuns  int
+	}{
+		{"first call runs", map[string]any{"code": "a", "env": "X=1"}, 1},
+		{"identical call is cached", map[string]any{"code": "a", "env": "X=1"}, 1},
+		{"timeout is not part of the key", map[string]any{"code": "a", "env": "X=1", "timeout": 5.0}, 1},
+		{"priority is not part of the key", map[string]any{"code": "a", "env": "X=1", "priority": "batch"}, 1},
+		{"different env runs", map[string]any{"code": "a", "env": "X=2"}, 2},
+		{"different code runs", map[string]any{"code": "b"}, 3},
+		{"errors run", map[string]any{"code": "fail"}, 4},
+		{"errors are not cached", map[string]any{"code": "fail"}, 5},
+		{"calls saving a snapshot run", map[string]any{"code": "a", "env": "X=1", "snapshot": "pandas"}, 6},
+		{"calls saving a snapshot are not cached", map[string]any{"code": "a", "env": "X=1", "snapshot": "pandas"}, 7},
+		{"workspace calls run", map[string]any{"code": "a", "env": "X=1", "workspace": "w"}, 8},
+		{"workspace calls are not cached", map[string]any{"code": "a", "env": "X=1", "workspace": "w"}, 9},
+		{"calls with mounts run", map[string]any{"code": "a", "env": "X=1", "mounts": "/data:/mnt/data"}, 10},
+		{"calls with mounts are not cached", map[string]any{"code": "a", "env": "X=1", "mounts": "/data:/mnt/data"}, 11},
+	}
+	for _, tt := range tests {
+		result := call(tt.arguments)
+		if runs != tt.wantRuns {
+			t.Errorf("%s: runs = %d, want %d", tt.name, runs, tt.wantRuns)
+		}
+		if text := resultText(result); text != "output" && text != "failed" {
+			t.Errorf("%s: result = %q", tt.name, text)
+		}
+	}
+
+	// The image of a restored snapshot may differ from the one of the cached result
+	restored := executor.WithSnapshotImages(context.Background(), map[string]string{"python": "mcp-executor-snapshot:pandas"})
+	if _, err := handler(restored, mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "execute-python", Arguments: map[string]any{"code": "a", "env": "X=1"}},
+	}); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if runs != 12 {
+		t.Errorf("call of a session that restored a snapshot: runs = %d, want 12", runs)
+	}
+
+	benchmark := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "execute-benchmark", Arguments: map[string]any{"snippets": map[string]any{"a": "pass"}}}}
+	for i := 0; i < 2; i++ {
+		if _, err := handler(context.Background(), benchmark); err != nil {
+			t.Fatalf("handler returned error: %v", err)
+		}
+	}
+	if runs != 14 {
+		t.Errorf("runs after two identical benchmarks = %d, want 14: benchmarks are not cached", runs)
+	}
+
+	browse := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "browse-web", Arguments: map[string]any{"url": "https://example.com"}}}
+	for i := 0; i < 2; i++ {
+		if _, err := handler(context.Background(), browse); err != nil {
+			t.Fatalf("handler returned error: %v", err)
+		}
+	}
+	if runs != 16 {
+		t.Errorf("runs after two identical browse calls = %d, want 16: browsed pages are not cached", runs)
+	}
+	render := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "render-page", Arguments: map[string]any{"url": "https://example.com"}}}
+	for i := 0; i < 2; i++ {
+		if _, err := handler(context.Background(), render); err != nil {
+			t.Fatalf("handler returned error: %v", err)
+		}
+	}
+	if runs != 18 {
+		t.Errorf("runs after two identical render calls = %d, want 18: rendered pages are not cached", runs)
+	}
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "http-request", Arguments: map[string]any{"url": "https://example.com"}}}
+	for i := 0; i < 2; i++ {
+		if _, err := handler(context.Background(), request); err != nil {
+			t.Fatalf("handler returned error: %v", err)
+		}
+	}
+	if runs != 20 {
+		t.Errorf("runs after two identical HTTP requests = %d, want 20: responses are not cached", runs)
+	}
+
+	if _, err := handler(withClientID(context.Background(), "key-other"), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "execute-python", Arguments: map[string]any{"code": "a", "env": "X=1"}},
+	}); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if runs != 21 {
+		t.Errorf("runs after an identical call of another client = %d, want 21: clients do not share results", runs)
+	}
+
+	if newResultCache(config.CacheConfig{}) != nil {
+		t.Error("newResultCache() should return nil when the TTL is 0")
+	}
+}
+
+func TestResultCache_Runner(t *testing.T) {
+	results := newResultCache(config.CacheConfig{TTL: time.Minute})
+	results.modes = newSessionModes("docker", []string{"subprocess"})
+	runs := 0
+	handler := results.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		runs++
+		return mcp.NewToolResultText("output"), nil
+	})
+	mcpServer := server.NewMCPServer("test", "1.0")
+	ctx := mcpServer.WithContext(context.Background(), server.NewInProcessSession("session-1", nil))
+
+	tests := []struct {
+		name     string
+		change   func()
+		wantRuns int
+	}{
+		{"first call runs", func() {}, 1},
+		{"identical call is cached", func() {}, 1},
+		{"call in another mode runs", func() { results.modes.set(ctx, "subprocess") }, 2},
+		{"call back in the first mode is cached", func() { results.modes.set(ctx, "docker") }, 2},
+		{"call in another image runs", func() { results.setImages(config.ImageConfig{Python: "python:3.10-slim"}) }, 3},
+	}
+	for _, tt := range tests {
+		tt.change()
+		if _, err := handler(ctx, mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "execute-python", Arguments: map[string]any{"code": "a"}},
+		}); err != nil {
+			t.Fatalf("handler returned error: %v", err)
+		}
+		if runs != tt.wantRuns {
+			t.Errorf("%s: runs = %d, want %d", tt.name, runs, tt.wantRuns)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package server describes the tools, prompts and resources an MCP server exposes
+// and calls its tools, as a client would through the MCP methods.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Catalog lists everything a server exposes to clients.
+type Catalog struct {
+	Tools             []mcp.Tool             `json:"tools"`
+	Prompts           []mcp.Prompt           `json:"prompts"`
+	Resources         []mcp.Resource         `json:"resources"`
+	ResourceTemplates []mcp.ResourceTemplate `json:"resourceTemplates"`
+}
+
+// ListCatalog queries mcpServer with the MCP list methods, so the result matches
+// what a connected client receives.
+func ListCatalog(ctx context.Context, mcpServer *server.MCPServer) (Catalog, error) {
+	var catalog Catalog
+
+	var tools mcp.ListToolsResult
+	if err := call(ctx, mcpServer, mcp.MethodToolsList, nil, &tools); err != nil {
+		return Catalog{}, err
+	}
+	catalog.Tools = tools.Tools
+
+	var prompts mcp.ListPromptsResult
+	if err := call(ctx, mcpServer, mcp.MethodPromptsList, nil, &prompts); err != nil {
+		return Catalog{}, err
+	}
+	catalog.Prompts = prompts.Prompts
+
+	var resources mcp.ListResourcesResult
+	if err := call(ctx, mcpServer, mcp.MethodResourcesList, nil, &resources); err != nil {
+		return Catalog{}, err
+	}
+	catalog.Resources = resources.Resources
+
+	var templates mcp.ListResourceTemplatesResult
+	if err := call(ctx, mcpServer, mcp.MethodResourcesTemplatesList, nil, &templates); err != nil {
+		return Catalog{}, err
+	}
+	catalog.ResourceTemplates = templates.ResourceTemplates
+
+	return catalog, nil
+}
+
+// CallTool calls the named tool of mcpServer with arguments, going through the
+// same middleware as client calls.
+func CallTool(ctx context.Context, mcpServer *server.MCPServer, name string, arguments map[string]any) (*mcp.CallToolResult, error) {
+	var raw json.RawMessage
+	params := map[string]any{"name": name, "arguments": arguments}
+	if err := call(ctx, mcpServer, mcp.MethodToolsCall, params, &raw); err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		return nil, fmt.Errorf("%s: tool %q not found", mcp.MethodToolsCall, name)
+	}
+	return mcp.ParseCallToolResult(&raw)
+}
+
+// call sends a request to mcpServer and decodes its result into out. Methods of
+// capabilities the server does not declare leave out unchanged.
+func call(ctx context.Context, mcpServer *server.MCPServer, method mcp.MCPMethod, params any, out any) error {
+	message := map[string]any{"jsonrpc": mcp.JSONRPC_VERSION, "id": 1, "method": method}
+	if params != nil {
+		message["params"] = params
+	}
+	request, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	response, err := json.Marshal(mcpServer.HandleMessage(ctx, request))
+	if err != nil {
+		return err
+	}
+
+	var reply struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(response, &reply); err != nil {
+		return fmt.Errorf("%s: %v", method, err)
+	}
+	if reply.Error != nil {
+		if reply.Error.Code == mcp.METHOD_NOT_FOUND {
+			return nil
+		}
+		return fmt.Errorf("%s: %s", method, reply.Error.Message)
+	}
+	if err := json.Unmarshal(reply.Result, out); err != nil {
+		return fmt.Errorf("%s: %v", method, err)
+	}
+	return nil
+}
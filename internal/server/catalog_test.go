@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestListCatalog(t *testing.T) {
+	tests := []struct {
+		mode        string
+		wantTools   int
+		wantPrompts int
+	}{
+		{"subprocess", 10, 4},
+		{"docker", 11, 3},
+		{"hybrid", 11, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			catalog, err := ListCatalog(context.Background(), NewMCPServer(tt.mode))
+			if err != nil {
+				t.Fatalf("ListCatalog() returned error: %v", err)
+			}
+			if len(catalog.Tools) != tt.wantTools {
+				t.Errorf("Tools = %d, want %d", len(catalog.Tools), tt.wantTools)
+			}
+			if len(catalog.Prompts) != tt.wantPrompts {
+				t.Errorf("Prompts = %d, want %d", len(catalog.Prompts), tt.wantPrompts)
+			}
+			if len(catalog.ResourceTemplates) != 1 || catalog.ResourceTemplates[0].Name != "Execution history" {
+				t.Errorf("ResourceTemplates = %+v, want the execution history template", catalog.ResourceTemplates)
+			}
+		})
+	}
+}
+
+func TestListCatalog_DisabledPrompts(t *testing.T) {
+	mcpServer := NewMCPServer("subprocess", WithDisabledPrompts([]string{"security-audit"}))
+
+	catalog, err := ListCatalog(context.Background(), mcpServer)
+	if err != nil {
+		t.Fatalf("ListCatalog() returned error: %v", err)
+	}
+	for _, prompt := range catalog.Prompts {
+		if prompt.Name == "security-audit" {
+			t.Error("Prompts should not include the disabled security-audit prompt")
+		}
+	}
+	if len(catalog.Prompts) != 3 {
+		t.Errorf("Prompts = %d, want 3", len(catalog.Prompts))
+	}
+}
+
+func TestCallTool(t *testing.T) {
+	mcpServer := NewMCPServer("subprocess")
+
+	result, err := CallTool(context.Background(), mcpServer, "execute-bash", map[string]any{"script": "echo called"})
+	if err != nil {
+		t.Fatalf("CallTool() returned error: %v", err)
+	}
+	if result.IsError || !strings.Contains(resultText(result), "called") {
+		t.Errorf("CallTool() = %+v, want the script output", result)
+	}
+
+	if _, err := CallTool(context.Background(), mcpServer, "execute-ruby", nil); err == nil {
+		t.Error("CallTool() should fail for unknown tools")
+	}
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name            string
+		origins         []string
+		method          string
+		origin          string
+		preflight       bool
+		wantStatus      int
+		wantAllowOrigin string
+	}{
+		{
+			name:       "no origins configured",
+			origins:    nil,
+			method:     http.MethodPost,
+			origin:     "https://app.example.com",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:            "allowed origin",
+			origins:         []string{"https://app.example.com"},
+			method:          http.MethodPost,
+			origin:          "https://app.example.com",
+			wantStatus:      http.StatusOK,
+			wantAllowOrigin: "https://app.example.com",
+		},
+		{
+			name:       "disallowed origin",
+			origins:    []string{"https://app.example.com"},
+			method:     http.MethodPost,
+			origin:     "https://evil.example.com",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:            "wildcard origin",
+			origins:         []string{"*"},
+			method:          http.MethodGet,
+			origin:          "https://any.example.com",
+			wantStatus:      http.StatusOK,
+			wantAllowOrigin: "https://any.example.com",
+		},
+		{
+			name:            "preflight short-circuits",
+			origins:         []string{"https://app.example.com"},
+			method:          http.MethodOptions,
+			origin:          "https://app.example.com",
+			preflight:       true,
+			wantStatus:      http.StatusNoContent,
+			wantAllowOrigin: "https://app.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Authentication sits behind CORS so preflight requests never need a token
+			handler := corsMiddleware(tt.origins, authMiddleware([]string{"token"}, next))
+			req := httptest.NewRequest(tt.method, "/mcp", nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Authorization", "Bearer token")
+			if tt.preflight {
+				req.Header.Del("Authorization")
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantAllowOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantAllowOrigin)
+			}
+			if tt.preflight && rec.Header().Get("Access-Control-Allow-Headers") == "" {
+				t.Error("preflight response should include Access-Control-Allow-Headers")
+			}
+		})
+	}
+}
+
+func TestNormalizeBasePath(t *testing.T) {
+	tests := map[string]string{
+		"":           "",
+		"/":          "",
+		"executor":   "/executor",
+		"/executor/": "/executor",
+		" /a/b ":     "/a/b",
+	}
+
+	for input, want := range tests {
+		if got := normalizeBasePath(input); got != want {
+			t.Errorf("normalizeBasePath(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
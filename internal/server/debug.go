@@ -0,0 +1,79 @@
+// Package server serves the optional net/http/pprof and expvar endpoints on a
+// separate admin address, for diagnosing leaks in long-running deployments.
+package server
+
+import (
+	"crypto/tls"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sync"
+
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// publishVars registers the server's expvar variables once per process.
+var publishVars = sync.OnceFunc(func() {
+	expvar.Publish("goroutines", expvar.Func(func() any { return runtime.NumGoroutine() }))
+})
+
+// debugHandler serves /debug/pprof/ and /debug/vars.
+func debugHandler() http.Handler {
+	publishVars()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	return mux
+}
+
+// startDebugServer serves the debug endpoints on o.DebugAddr in the background,
+// with the transport's authentication and TLS settings. Without auth tokens only
+// loopback addresses are allowed.
+func startDebugServer(o TransportOptions) error {
+	if o.DebugAddr == "" {
+		return nil
+	}
+	if len(o.AuthTokens) == 0 && !loopbackAddr(o.DebugAddr) {
+		return fmt.Errorf("debug endpoint on %s requires auth tokens unless it listens on a loopback address", o.DebugAddr)
+	}
+
+	listener, err := net.Listen("tcp", o.DebugAddr)
+	if err != nil {
+		return fmt.Errorf("failed to start debug endpoint: %v", err)
+	}
+	if o.tlsEnabled() {
+		tlsConfig, err := buildTLSConfig(o)
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	httpServer := &http.Server{Handler: authMiddleware(o.AuthTokens, debugHandler())}
+	logger.Verbose("Serving pprof and expvar on %s/debug/", o.baseURL(hostURL(o.DebugAddr)))
+	go func() {
+		logger.Error("Debug endpoint stopped: %v", httpServer.Serve(listener))
+	}()
+	return nil
+}
+
+// loopbackAddr reports whether addr listens on a loopback host only.
+func loopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDebugHandler(t *testing.T) {
+	handler := authMiddleware([]string{"secret"}, debugHandler())
+
+	tests := []struct {
+		name       string
+		path       string
+		token      string
+		wantStatus int
+	}{
+		{"pprof index", "/debug/pprof/", "secret", http.StatusOK},
+		{"goroutine profile", "/debug/pprof/goroutine?debug=1", "secret", http.StatusOK},
+		{"expvar", "/debug/vars", "secret", http.StatusOK},
+		{"missing token", "/debug/vars", "", http.StatusUnauthorized},
+		{"unknown path", "/mcp", "secret", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.token != "" {
+				request.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("GET %s status = %d, want %d", tt.path, recorder.Code, tt.wantStatus)
+			}
+		})
+	}
+
+	recorder := httptest.NewRecorder()
+	debugHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+	var vars map[string]any
+	if err := json.Unmarshal(recorder.Body.Bytes(), &vars); err != nil || vars["goroutines"] == nil || vars["memstats"] == nil {
+		t.Errorf("/debug/vars = %.200s, want goroutines and memstats", recorder.Body.String())
+	}
+}
+
+func TestStartDebugServer_RequiresAuthOffLoopback(t *testing.T) {
+	err := startDebugServer(TransportOptions{DebugAddr: "0.0.0.0:0"})
+	if err == nil || !strings.Contains(err.Error(), "requires auth tokens") {
+		t.Errorf("startDebugServer() error = %v, want auth tokens required", err)
+	}
+
+	for addr, want := range map[string]bool{
+		"127.0.0.1:6060": true,
+		"[::1]:6060":     true,
+		"localhost:6060": true,
+		":6060":          false,
+		"10.0.0.5:6060":  false,
+	} {
+		if got := loopbackAddr(addr); got != want {
+			t.Errorf("loopbackAddr(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+// Package server implements MCP elicitation for privileged executions, asking the
+// user to confirm host mounts, host networking and sudo-like scripts and to supply
+// missing secrets before the code runs.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+	"github.com/ylchen07/mcp-executor/internal/tools"
+)
+
+// secretPlaceholder marks an env entry ("API_KEY=?") whose value should be asked from the user.
+const secretPlaceholder = "?"
+
+// privilegeEscalation matches sudo-like commands at the start of a shell command.
+var privilegeEscalation = regexp.MustCompile(`(^|[;&|(\s])(sudo|su|doas|pkexec)(\s|$)`)
+
+// elicitor requests information from the user through the connected client.
+type elicitor interface {
+	RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error)
+}
+
+// clientElicitor forwards elicitation requests to clients that declared the capability.
+type clientElicitor struct {
+	mcpServer *server.MCPServer
+}
+
+// RequestElicitation implements elicitor.
+func (c clientElicitor) RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo)
+	if ok && session.GetClientCapabilities().Elicitation == nil {
+		return nil, server.ErrElicitationNotSupported
+	}
+	return c.mcpServer.RequestElicitation(ctx, request)
+}
+
+// privilegeGuard confirms privileged execute-* calls and fills in missing secrets.
+type privilegeGuard struct {
+	elicitor   elicitor
+	subprocess bool          // Scripts run directly on the host, so sudo-like commands are privileged
+	modes      *sessionModes // Execution modes selected by the sessions, overriding subprocess
+
+	// allowUnconfirmed runs privileged calls that the client cannot confirm,
+	// as the operator allowed
+	allowUnconfirmed bool
+}
+
+// middleware asks the user before running privileged calls. Without client support,
+// privileged calls are refused unless the operator allows them, and missing
+// secrets fail the call. Scheduled runs were confirmed when they were scheduled
+// and always run.
+func (g *privilegeGuard) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !isExecutionTool(request.Params.Name) || isScheduledRun(ctx) {
+			return next(ctx, request)
+		}
+
+		if reasons := g.privilegedReasons(request, g.onHost(ctx)); len(reasons) > 0 {
+			confirmed, err := g.confirm(ctx, request.Params.Name, reasons)
+			switch {
+			case errors.Is(err, server.ErrElicitationNotSupported) || errors.Is(err, server.ErrNoActiveSession):
+				if err := g.unconfirmed(ctx, request.Params.Name, reasons); err != nil {
+					return tools.ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+				}
+			case err != nil:
+				return tools.ErrorResult(executor.ErrorInfrastructure, fmt.Sprintf("confirmation failed: %v", err)), nil
+			case !confirmed:
+				return tools.ErrorResult(executor.ErrorPolicyViolation, "execution declined by user: "+strings.Join(reasons, "; ")), nil
+			}
+		}
+
+		env := request.GetArguments()["env"]
+		if missing := missingSecrets(env); len(missing) > 0 {
+			secrets, err := g.askSecrets(ctx, request.Params.Name, missing)
+			if err != nil {
+				return tools.ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+			}
+			filled, err := fillSecrets(env, secrets)
+			if err != nil {
+				return tools.ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+			}
+			request = withArgument(request, "env", filled)
+		}
+
+		return next(ctx, request)
+	}
+}
+
+// onHost reports whether the calls of the session of ctx run directly on the
+// host.
+func (g *privilegeGuard) onHost(ctx context.Context) bool {
+	if mode := g.modes.mode(ctx); mode != "" {
+		return !executor.ContainerMode(mode)
+	}
+	return g.subprocess
+}
+
+// privilegedReasons lists why a call needs confirmation, if at all, when it
+// runs directly on the host with subprocess.
+func (g *privilegeGuard) privilegedReasons(request mcp.CallToolRequest, subprocess bool) []string {
+	var reasons []string
+	if mounts := strings.TrimSpace(request.GetString("mounts", "")); mounts != "" {
+		reasons = append(reasons, "mounts host paths "+mounts)
+	}
+	if strings.EqualFold(strings.TrimSpace(request.GetString("network", "")), "host") {
+		reasons = append(reasons, "uses the host network")
+	}
+	if subprocess && request.Params.Name == "execute-bash" &&
+		privilegeEscalation.MatchString(request.GetString("script", "")) {
+		reasons = append(reasons, "runs sudo-like commands on the host")
+	}
+	return reasons
+}
+
+// unconfirmed refuses the privileged call of a client that cannot confirm it,
+// unless the operator allows running such calls.
+func (g *privilegeGuard) unconfirmed(ctx context.Context, toolName string, reasons []string) error {
+	if !g.allowUnconfirmed {
+		return fmt.Errorf("privileged %s call refused: the client cannot confirm that it %s, and policy.allow_unconfirmed_privileges is not set",
+			toolName, strings.Join(reasons, "; "))
+	}
+	logger.InfoContext(ctx, "Client cannot confirm privileged %s call (%s); running it as policy.allow_unconfirmed_privileges is set",
+		toolName, strings.Join(reasons, "; "))
+	return nil
+}
+
+// confirm asks the user whether the privileged call may run.
+func (g *privilegeGuard) confirm(ctx context.Context, toolName string, reasons []string) (bool, error) {
+	result, err := g.elicitor.RequestElicitation(ctx, mcp.ElicitationRequest{
+		Params: mcp.ElicitationParams{
+			Message: fmt.Sprintf("%s requests privileged access: %s. Allow this execution?",
+				toolName, strings.Join(reasons, "; ")),
+			RequestedSchema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"confirm": map[string]any{
+						"type":        "boolean",
+						"title":       "Allow execution",
+						"description": "Run the code with the requested privileges",
+					},
+				},
+				"required": []string{"confirm"},
+			},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	if result == nil || result.Action != mcp.ElicitationResponseActionAccept {
+		return false, nil
+	}
+	content, _ := result.Content.(map[string]any)
+	confirmed, _ := content["confirm"].(bool)
+	return confirmed, nil
+}
+
+// askSecrets asks the user for the values of the given environment variables.
+func (g *privilegeGuard) askSecrets(ctx context.Context, toolName string, keys []string) (map[string]string, error) {
+	properties := make(map[string]any, len(keys))
+	for _, key := range keys {
+		properties[key] = map[string]any{
+			"type":  "string",
+			"title": key,
+		}
+	}
+
+	result, err := g.elicitor.RequestElicitation(ctx, mcp.ElicitationRequest{
+		Params: mcp.ElicitationParams{
+			Message: fmt.Sprintf("%s needs values for %s", toolName, strings.Join(keys, ", ")),
+			RequestedSchema: map[string]any{
+				"type":       "object",
+				"properties": properties,
+				"required":   keys,
+			},
+		},
+	})
+	if err != nil {
+		if errors.Is(err, server.ErrElicitationNotSupported) || errors.Is(err, server.ErrNoActiveSession) {
+			return nil, fmt.Errorf("missing values for %s and the client does not support elicitation",
+				strings.Join(keys, ", "))
+		}
+		return nil, fmt.Errorf("failed to request secrets: %v", err)
+	}
+	if result == nil || result.Action != mcp.ElicitationResponseActionAccept {
+		return nil, fmt.Errorf("execution cancelled: values for %s were not provided", strings.Join(keys, ", "))
+	}
+
+	content, _ := result.Content.(map[string]any)
+	secrets := make(map[string]string, len(keys))
+	for _, key := range keys {
+		value, _ := content[key].(string)
+		if value == "" {
+			return nil, fmt.Errorf("no value provided for %s", key)
+		}
+		secrets[key] = value
+	}
+	return secrets, nil
+}
+
+// missingSecrets returns the sorted keys of env entries whose value is the secret
+// placeholder. env is the raw "env" argument, a KEY=VALUE string or an object.
+func missingSecrets(env any) []string {
+	var keys []string
+	switch env := env.(type) {
+	case string:
+		for pair := range strings.SplitSeq(env, ",") {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if ok && strings.TrimSpace(key) != "" && strings.TrimSpace(value) == secretPlaceholder {
+				keys = append(keys, strings.TrimSpace(key))
+			}
+		}
+	case map[string]any:
+		for key, value := range env {
+			if value, ok := value.(string); ok && strings.TrimSpace(key) != "" && strings.TrimSpace(value) == secretPlaceholder {
+				keys = append(keys, strings.TrimSpace(key))
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fillSecrets replaces placeholder values in env with the provided secrets,
+// keeping the form of the argument. Secrets containing commas cannot be written
+// to the KEY=VALUE string form.
+func fillSecrets(env any, secrets map[string]string) (any, error) {
+	switch env := env.(type) {
+	case string:
+		pairs := strings.Split(env, ",")
+		for i, pair := range pairs {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || strings.TrimSpace(value) != secretPlaceholder {
+				continue
+			}
+			if secret, found := secrets[strings.TrimSpace(key)]; found {
+				if strings.Contains(secret, ",") {
+					return nil, fmt.Errorf("value for %s must not contain commas (pass env as an object instead)", strings.TrimSpace(key))
+				}
+				pairs[i] = strings.TrimSpace(key) + "=" + secret
+			}
+		}
+		return strings.Join(pairs, ","), nil
+	case map[string]any:
+		filled := make(map[string]any, len(env))
+		for key, value := range env {
+			filled[key] = value
+			if value, ok := value.(string); ok && strings.TrimSpace(value) == secretPlaceholder {
+				if secret, found := secrets[strings.TrimSpace(key)]; found {
+					filled[key] = secret
+				}
+			}
+		}
+		return filled, nil
+	default:
+		return env, nil
+	}
+}
@@ -0,0 +1,240 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// fakeElicitor answers elicitation requests with a canned result.
+type fakeElicitor struct {
+	result   *mcp.ElicitationResult
+	err      error
+	requests []mcp.ElicitationRequest
+}
+
+func (f *fakeElicitor) RequestElicitation(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	f.requests = append(f.requests, request)
+	return f.result, f.err
+}
+
+func elicitationResult(action mcp.ElicitationResponseAction, content map[string]any) *mcp.ElicitationResult {
+	return &mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{Action: action, Content: content}}
+}
+
+// recordingHandler succeeds and remembers the request it ran.
+func recordingHandler(ran *mcp.CallToolRequest) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		*ran = request
+		return mcp.NewToolResultText("ok"), nil
+	}
+}
+
+func TestPrivilegeGuard_Confirmation(t *testing.T) {
+	tests := []struct {
+		name       string
+		subprocess bool
+		allow      bool
+		scheduled  bool
+		toolName   string
+		args       map[string]any
+		elicitor   *fakeElicitor
+		wantAsked  bool
+		wantRun    bool
+	}{
+		{
+			name:     "unprivileged call runs without asking",
+			toolName: "execute-python",
+			args:     map[string]any{"code": "print(1)"},
+			elicitor: &fakeElicitor{},
+			wantRun:  true,
+		},
+		{
+			name:      "mounts accepted",
+			toolName:  "execute-python",
+			args:      map[string]any{"code": "print(1)", "mounts": "/data:/mnt/data"},
+			elicitor:  &fakeElicitor{result: elicitationResult(mcp.ElicitationResponseActionAccept, map[string]any{"confirm": true})},
+			wantAsked: true,
+			wantRun:   true,
+		},
+		{
+			name:      "host network declined",
+			toolName:  "execute-bash",
+			args:      map[string]any{"script": "curl localhost", "network": "host"},
+			elicitor:  &fakeElicitor{result: elicitationResult(mcp.ElicitationResponseActionDecline, nil)},
+			wantAsked: true,
+		},
+		{
+			name:      "accepted without confirming",
+			toolName:  "execute-python",
+			args:      map[string]any{"code": "print(1)", "network": "host"},
+			elicitor:  &fakeElicitor{result: elicitationResult(mcp.ElicitationResponseActionAccept, map[string]any{"confirm": false})},
+			wantAsked: true,
+		},
+		{
+			name:       "sudo in subprocess bash cancelled",
+			subprocess: true,
+			toolName:   "execute-bash",
+			args:       map[string]any{"script": "echo hi && sudo rm -rf /tmp/x"},
+			elicitor:   &fakeElicitor{result: elicitationResult(mcp.ElicitationResponseActionCancel, nil)},
+			wantAsked:  true,
+		},
+		{
+			name:     "sudo in docker bash is not privileged",
+			toolName: "execute-bash",
+			args:     map[string]any{"script": "sudo apt-get update"},
+			elicitor: &fakeElicitor{},
+			wantRun:  true,
+		},
+		{
+			name:      "unsupported client is refused",
+			toolName:  "execute-python",
+			args:      map[string]any{"code": "print(1)", "network": "host"},
+			elicitor:  &fakeElicitor{err: server.ErrElicitationNotSupported},
+			wantAsked: true,
+		},
+		{
+			name:      "client without session is refused",
+			toolName:  "execute-python",
+			args:      map[string]any{"code": "print(1)", "mounts": "/data:/mnt/data"},
+			elicitor:  &fakeElicitor{err: server.ErrNoActiveSession},
+			wantAsked: true,
+		},
+		{
+			name:      "unsupported client runs when the operator allows it",
+			allow:     true,
+			toolName:  "execute-python",
+			args:      map[string]any{"code": "print(1)", "mounts": "/data:/mnt/data"},
+			elicitor:  &fakeElicitor{err: server.ErrElicitationNotSupported},
+			wantAsked: true,
+			wantRun:   true,
+		},
+		{
+			name:      "scheduled run was confirmed when scheduled",
+			scheduled: true,
+			toolName:  "execute-python",
+			args:      map[string]any{"code": "print(1)", "mounts": "/data:/mnt/data"},
+			elicitor:  &fakeElicitor{err: server.ErrNoActiveSession},
+			wantRun:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard := &privilegeGuard{elicitor: tt.elicitor, subprocess: tt.subprocess, allowUnconfirmed: tt.allow}
+			var ran mcp.CallToolRequest
+			handler := guard.middleware(recordingHandler(&ran))
+			ctx := context.Background()
+			if tt.scheduled {
+				ctx = context.WithValue(ctx, scheduledRunKey{}, true)
+			}
+
+			result, err := handler(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{
+				Name:      tt.toolName,
+				Arguments: tt.args,
+			}})
+			if err != nil {
+				t.Fatalf("handler returned error: %v", err)
+			}
+
+			if asked := len(tt.elicitor.requests) > 0; asked != tt.wantAsked {
+				t.Errorf("Elicitation requested = %v, want %v", asked, tt.wantAsked)
+			}
+			if didRun := ran.Params.Name != ""; didRun != tt.wantRun {
+				t.Errorf("Execution ran = %v, want %v", didRun, tt.wantRun)
+			}
+			if !tt.wantRun && !result.IsError {
+				t.Error("Declined execution should return an error result")
+			}
+		})
+	}
+}
+
+func TestPrivilegeGuard_Secrets(t *testing.T) {
+	elicitor := &fakeElicitor{result: elicitationResult(mcp.ElicitationResponseActionAccept, map[string]any{"API_KEY": "s3cret"})}
+	guard := &privilegeGuard{elicitor: elicitor}
+	var ran mcp.CallToolRequest
+	handler := guard.middleware(recordingHandler(&ran))
+
+	result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name:      "execute-python",
+		Arguments: map[string]any{"code": "print(1)", "env": "DEBUG=true,API_KEY=?"},
+	}})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Execution should succeed, got: %s", resultText(result))
+	}
+
+	if env := ran.GetString("env", ""); env != "DEBUG=true,API_KEY=s3cret" {
+		t.Errorf("Secret should be substituted into env, got %q", env)
+	}
+	if len(elicitor.requests) != 1 || !strings.Contains(elicitor.requests[0].Params.Message, "API_KEY") {
+		t.Errorf("Expected one elicitation naming API_KEY, got %+v", elicitor.requests)
+	}
+}
+
+func TestPrivilegeGuard_SecretsObjectEnv(t *testing.T) {
+	elicitor := &fakeElicitor{result: elicitationResult(mcp.ElicitationResponseActionAccept, map[string]any{"API_KEY": "a,b"})}
+	guard := &privilegeGuard{elicitor: elicitor}
+	var ran mcp.CallToolRequest
+	handler := guard.middleware(recordingHandler(&ran))
+
+	result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name:      "execute-python",
+		Arguments: map[string]any{"code": "print(1)", "env": map[string]any{"IDS": "1,2", "API_KEY": "?"}},
+	}})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Execution should succeed, got: %s", resultText(result))
+	}
+
+	env, _ := ran.GetArguments()["env"].(map[string]any)
+	if env["API_KEY"] != "a,b" || env["IDS"] != "1,2" {
+		t.Errorf("Secret should be substituted into the env object, got %v", env)
+	}
+}
+
+func TestPrivilegeGuard_SecretsUnsupported(t *testing.T) {
+	guard := &privilegeGuard{elicitor: &fakeElicitor{err: server.ErrElicitationNotSupported}}
+	var ran mcp.CallToolRequest
+	handler := guard.middleware(recordingHandler(&ran))
+
+	result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name:      "execute-python",
+		Arguments: map[string]any{"code": "print(1)", "env": "API_KEY=?"},
+	}})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if !result.IsError || ran.Params.Name != "" {
+		t.Error("Missing secrets without elicitation support should fail the call")
+	}
+}
+
+func TestMissingSecrets(t *testing.T) {
+	tests := []struct {
+		env  any
+		want []string
+	}{
+		{"", nil},
+		{nil, nil},
+		{map[string]any{"B": "?", "A": " ? ", "C": "x", "D": 1.0}, []string{"A", "B"}},
+		{"A=1,B=2", nil},
+		{"B=?, A = ?,C=x?", []string{"A", "B"}},
+		{"=?", nil},
+	}
+
+	for _, tt := range tests {
+		got := missingSecrets(tt.env)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("missingSecrets(%v) = %v, want %v", tt.env, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Package server exposes the named Docker-mode environments to the executors of
+// their language and to clients as the environments://list resource.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/config"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+)
+
+// environmentsURI is the resource listing the environments.
+const environmentsURI = "environments://list"
+
+// environmentsOf returns the environments of language.
+func environmentsOf(environments map[string]config.EnvironmentConfig, language string) []executor.Environment {
+	var result []executor.Environment
+	for name, environment := range environments {
+		if environment.Language != language {
+			continue
+		}
+		result = append(result, executor.Environment{
+			Name:     name,
+			Image:    environment.Image,
+			Packages: environment.Packages,
+			Env:      environment.Env,
+			Memory:   environment.Memory,
+			CPUs:     environment.CPUs,
+		})
+	}
+	return result
+}
+
+// environmentSummary describes an environment to clients. Variable values are
+// left out, since they may hold credentials.
+type environmentSummary struct {
+	Name        string   `json:"name"`
+	Language    string   `json:"language"`
+	Tool        string   `json:"tool"`
+	Description string   `json:"description,omitempty"`
+	Image       string   `json:"image,omitempty"`
+	Packages    []string `json:"packages,omitempty"`
+	Env         []string `json:"env,omitempty"`
+	Memory      string   `json:"memory,omitempty"`
+	CPUs        string   `json:"cpus,omitempty"`
+}
+
+// environmentCatalog serves the environments resource; reloads replace its content.
+type environmentCatalog struct {
+	mu           sync.Mutex
+	environments map[string]config.EnvironmentConfig
+}
+
+// register adds the environments resource to mcpServer.
+func (c *environmentCatalog) register(mcpServer *server.MCPServer) {
+	mcpServer.AddResource(
+		mcp.NewResource(
+			environmentsURI,
+			"Execution environments",
+			mcp.WithResourceDescription("Pre-baked environments selectable with the profile argument of the execute tools"),
+			mcp.WithMIMEType("application/json"),
+		),
+		c.readResource,
+	)
+}
+
+func (c *environmentCatalog) set(environments map[string]config.EnvironmentConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.environments = environments
+}
+
+// summaries returns the environments sorted by name.
+func (c *environmentCatalog) summaries() []environmentSummary {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	summaries := make([]environmentSummary, 0, len(c.environments))
+	for name, environment := range c.environments {
+		env := make([]string, 0, len(environment.Env))
+		for key := range environment.Env {
+			env = append(env, key)
+		}
+		sort.Strings(env)
+		summaries = append(summaries, environmentSummary{
+			Name:        name,
+			Language:    environment.Language,
+			Tool:        "execute-" + environment.Language,
+			Description: environment.Description,
+			Image:       environment.Image,
+			Packages:    environment.Packages,
+			Env:         env,
+			Memory:      environment.Memory,
+			CPUs:        environment.CPUs,
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Name < summaries[j].Name })
+	return summaries
+}
+
+// readResource returns the environments as JSON.
+func (c *environmentCatalog) readResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	data, err := json.MarshalIndent(c.summaries(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode environments: %v", err)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: "application/json",
+			Text:     string(data),
+		},
+	}, nil
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/config"
+)
+
+func TestEnvironmentCatalog_ReadResource(t *testing.T) {
+	catalog := &environmentCatalog{}
+	catalog.set(map[string]config.EnvironmentConfig{
+		"web":          {Language: "typescript", Packages: []string{"zod"}},
+		"data-science": {Language: "python", Packages: []string{"pandas"}, Env: map[string]string{"API_TOKEN": "secret"}, Memory: "2g"},
+	})
+
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = environmentsURI
+	contents, err := catalog.readResource(context.Background(), request)
+	if err != nil {
+		t.Fatalf("readResource() returned error: %v", err)
+	}
+	text := contents[0].(mcp.TextResourceContents).Text
+	for _, want := range []string{`"tool": "execute-python"`, `"API_TOKEN"`, `"memory": "2g"`} {
+		if !strings.Contains(text, want) {
+			t.Errorf("readResource() = %s, want it to contain %s", text, want)
+		}
+	}
+	if strings.Contains(text, "secret") {
+		t.Errorf("readResource() leaks variable values: %s", text)
+	}
+	if strings.Index(text, `"data-science"`) > strings.Index(text, `"web"`) {
+		t.Errorf("readResource() should sort environments by name: %s", text)
+	}
+
+	catalog.set(nil)
+	if contents, _ := catalog.readResource(context.Background(), request); contents[0].(mcp.TextResourceContents).Text != "[]" {
+		t.Errorf("readResource() after reload = %s, want []", contents[0].(mcp.TextResourceContents).Text)
+	}
+}
+
+func TestEnvironmentsOf(t *testing.T) {
+	environments := environmentsOf(map[string]config.EnvironmentConfig{
+		"data-science": {Language: "python", Image: "python:3.12", CPUs: "2"},
+		"web":          {Language: "typescript"},
+	}, "python")
+	if len(environments) != 1 || environments[0].Name != "data-science" || environments[0].Image != "python:3.12" || environments[0].CPUs != "2" {
+		t.Errorf("environmentsOf() = %+v", environments)
+	}
+}
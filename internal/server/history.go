@@ -0,0 +1,356 @@
+// Package server records execute tool calls into the execution history and
+// exposes stored executions and the reports they wrote as MCP resources, to
+// the client that made the calls only.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/history"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+	"github.com/ylchen07/mcp-executor/internal/tools"
+)
+
+// historyRecorder stores execute-* tool calls and publishes each one as a resource.
+type historyRecorder struct {
+	store     *history.Store
+	active    *activeExecutions
+	mcpServer *server.MCPServer
+	maxInline int // Bytes of output returned in tool results; zero returns all of it
+}
+
+// middleware records every execute-* tool call and links the stored execution
+// and its reports in the result. Calls with stdin_from read the output of the
+// stored execution it names on stdin. Output longer than maxInline is stored
+// in full and previewed in the result, with a link to the full output.
+func (h *historyRecorder) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !isExecutionTool(request.Params.Name) {
+			return next(ctx, request)
+		}
+
+		// The ID is assigned up front, so the execution sees it as MCP_EXECUTION_ID;
+		// the session ID selects the session's named workspaces, and both label
+		// its containers together with the client ID
+		id := history.NewID()
+		usage := &executor.Usage{}
+		var artifacts []executor.Artifact
+		startedAt := time.Now()
+		ctx = executor.WithSessionID(executor.WithExecutionID(ctx, id), sessionID(ctx))
+		ctx = executor.WithClientID(ctx, clientID(ctx))
+		end := h.active.begin(ctx, request.Params.Name)
+		ctx, result := h.pipeInput(ctx, request)
+		var err error
+		if result == nil {
+			result, err = next(executor.WithArtifacts(executor.WithUsage(ctx, usage), &artifacts), request)
+		}
+		end()
+		if err != nil || result == nil {
+			return result, err
+		}
+
+		status := "success"
+		if result.IsError {
+			status = "error"
+		}
+		output := resultText(result)
+		rec, evicted := h.store.Add(history.Record{
+			ID:        id,
+			Tool:      request.Params.Name,
+			Code:      requestCode(request),
+			Output:    output,
+			Status:    status,
+			StartedAt: startedAt,
+			Duration:  time.Since(startedAt),
+			Usage:     reportedUsage(result, usage),
+			Artifacts: artifacts,
+			Truncated: h.maxInline > 0 && len(output) > h.maxInline,
+			Client:    clientID(ctx),
+			Session:   sessionID(ctx),
+		})
+		logger.Debug("Recorded execution %s (%s, %s)", rec.ID, rec.Tool, rec.Status)
+
+		h.publish(rec, evicted)
+		if rec.Truncated {
+			logger.Debug("Previewing the %d bytes of output of execution %s", len(output), rec.ID)
+			previewResult(result, rec, h.maxInline)
+			result.Content = append(result.Content, mcp.NewResourceLink(
+				rec.OutputURI(),
+				"output "+rec.ID,
+				"Full output of this execution",
+				"text/plain",
+			))
+		}
+		result.Content = append(result.Content, mcp.NewResourceLink(
+			rec.URI(),
+			"execution "+rec.ID,
+			"Stored code and output of this execution",
+			"application/json",
+		))
+		for _, artifact := range rec.Artifacts {
+			result.Content = append(result.Content, mcp.NewResourceLink(
+				rec.ArtifactURI(artifact.Name),
+				artifact.Name,
+				"Report written by this execution",
+				artifact.MIMEType,
+			))
+		}
+		return result, nil
+	}
+}
+
+// pipeInput returns ctx with the output of the execution selected by the
+// stdin_from argument of request as the input of the execution. An unknown
+// or failed execution is reported by the returned tool result instead.
+func (h *historyRecorder) pipeInput(ctx context.Context, request mcp.CallToolRequest) (context.Context, *mcp.CallToolResult) {
+	id := strings.TrimPrefix(strings.TrimSpace(request.GetString(tools.StdinFromArgument, "")), history.URIScheme)
+	if id == "" {
+		return ctx, nil
+	}
+	rec, ok := h.lookup(ctx, id)
+	if !ok {
+		return ctx, tools.ErrorResult(executor.ErrorPolicyViolation, fmt.Sprintf("stdin_from: execution %q not found (it may have been evicted from history)", id))
+	}
+	if rec.Status != "success" {
+		return ctx, tools.ErrorResult(executor.ErrorPolicyViolation, fmt.Sprintf("stdin_from: execution %q failed, only the output of successful executions can be piped", id))
+	}
+	logger.Debug("Piping the output of execution %s (%d bytes) to stdin", rec.ID, len(rec.Output))
+	return executor.WithStdin(ctx, rec.Output), nil
+}
+
+// usageMetaKey holds the resource usage in the _meta of execute tool results.
+const usageMetaKey = "mcp-executor/usage"
+
+// reportedUsage returns usage when an execution reported it (cached results
+// run nothing), logging it and adding it to the _meta of result.
+func reportedUsage(result *mcp.CallToolResult, usage *executor.Usage) *executor.Usage {
+	if usage.WallTime == 0 {
+		return nil
+	}
+	logger.Info("Execution resource usage: %s", usage)
+	if result.Meta == nil {
+		result.Meta = &mcp.Meta{}
+	}
+	if result.Meta.AdditionalFields == nil {
+		result.Meta.AdditionalFields = map[string]any{}
+	}
+	result.Meta.AdditionalFields[usageMetaKey] = usage
+	return usage
+}
+
+// lookup returns the stored execution id of the client of ctx. Executions of
+// other clients are reported as not found.
+func (h *historyRecorder) lookup(ctx context.Context, id string) (history.Record, bool) {
+	rec, ok := h.store.Get(id)
+	if !ok || rec.Client != clientID(ctx) {
+		return history.Record{}, false
+	}
+	return rec, true
+}
+
+// listedPrivately reports whether rec is listed as a resource of its session
+// only: the executions of authenticated clients are hidden from the resource
+// lists of other clients.
+func listedPrivately(rec history.Record) bool {
+	return rec.Client != anonymousClient && rec.Session != ""
+}
+
+// publish registers rec and its reports as listed resources and removes
+// evicted records.
+func (h *historyRecorder) publish(rec history.Record, evicted []history.Record) {
+	if h.mcpServer == nil {
+		return
+	}
+	for _, old := range evicted {
+		uris := []string{old.URI()}
+		if old.Truncated {
+			uris = append(uris, old.OutputURI())
+		}
+		for _, artifact := range old.Artifacts {
+			uris = append(uris, old.ArtifactURI(artifact.Name))
+		}
+		if listedPrivately(old) {
+			// Ended sessions took their resources with them
+			_ = h.mcpServer.DeleteSessionResources(old.Session, uris...)
+		}
+		h.mcpServer.DeleteResources(uris...)
+	}
+
+	resources := []server.ServerResource{{
+		Resource: mcp.NewResource(
+			rec.URI(),
+			fmt.Sprintf("%s execution %s (%s)", rec.Tool, rec.ID, rec.Status),
+			mcp.WithResourceDescription(fmt.Sprintf("Executed at %s", rec.StartedAt.Format(time.RFC3339))),
+			mcp.WithMIMEType("application/json"),
+		),
+		Handler: h.readResource,
+	}}
+	if rec.Truncated {
+		resources = append(resources, server.ServerResource{
+			Resource: mcp.NewResource(
+				rec.OutputURI(),
+				fmt.Sprintf("Output of %s execution %s", rec.Tool, rec.ID),
+				mcp.WithResourceDescription(fmt.Sprintf("%d bytes, previewed in the result", len(rec.Output))),
+				mcp.WithMIMEType("text/plain"),
+			),
+			Handler: h.readOutput,
+		})
+	}
+	for _, artifact := range rec.Artifacts {
+		resources = append(resources, server.ServerResource{
+			Resource: mcp.NewResource(
+				rec.ArtifactURI(artifact.Name),
+				fmt.Sprintf("%s report of execution %s", artifact.Name, rec.ID),
+				mcp.WithResourceDescription(fmt.Sprintf("Written by %s at %s", rec.Tool, rec.StartedAt.Format(time.RFC3339))),
+				mcp.WithMIMEType(artifact.MIMEType),
+			),
+			Handler: h.readArtifact,
+		})
+	}
+	if listedPrivately(rec) {
+		err := h.mcpServer.AddSessionResources(rec.Session, resources...)
+		if err == nil {
+			return
+		}
+		// The handlers still refuse other clients
+		logger.Debug("Listing execution %s for all sessions: %v", rec.ID, err)
+	}
+	h.mcpServer.AddResources(resources...)
+}
+
+// readArtifact returns a report written by a stored execution.
+func (h *historyRecorder) readArtifact(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	id, name, _ := strings.Cut(strings.TrimPrefix(request.Params.URI, history.ArtifactURIScheme), "/")
+	rec, ok := h.lookup(ctx, id)
+	if !ok {
+		return nil, fmt.Errorf("execution %q not found (it may have been evicted from history)", id)
+	}
+	for _, artifact := range rec.Artifacts {
+		if artifact.Name == name {
+			return []mcp.ResourceContents{
+				mcp.TextResourceContents{
+					URI:      request.Params.URI,
+					MIMEType: artifact.MIMEType,
+					Text:     artifact.Content,
+				},
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("execution %q wrote no report %q", id, name)
+}
+
+// readOutput returns the full output of a stored execution.
+func (h *historyRecorder) readOutput(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	id := strings.TrimPrefix(request.Params.URI, history.OutputURIScheme)
+	rec, ok := h.lookup(ctx, id)
+	if !ok {
+		return nil, fmt.Errorf("execution %q not found (it may have been evicted from history)", id)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: "text/plain",
+			Text:     rec.Output,
+		},
+	}, nil
+}
+
+// readResource returns a stored execution as JSON.
+func (h *historyRecorder) readResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	id := strings.TrimPrefix(request.Params.URI, history.URIScheme)
+	rec, ok := h.lookup(ctx, id)
+	if !ok {
+		return nil, fmt.Errorf("execution %q not found (it may have been evicted from history)", id)
+	}
+
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode execution %q: %v", id, err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: "application/json",
+			Text:     string(data),
+		},
+	}, nil
+}
+
+// registerHistoryResources exposes stored executions through the execution://{id} template.
+func (h *historyRecorder) registerHistoryResources() {
+	h.mcpServer.AddResourceTemplate(
+		mcp.NewResourceTemplate(
+			history.URIScheme+"{id}",
+			"Execution history",
+			mcp.WithTemplateDescription("Code, output and status of a recent execution"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		h.readResource,
+	)
+}
+
+// requestCode returns the submitted source from a tool call ("code" or "script").
+func requestCode(request mcp.CallToolRequest) string {
+	if code := request.GetString("code", ""); code != "" {
+		return code
+	}
+	return request.GetString("script", "")
+}
+
+// resultText concatenates the text content of a tool result.
+func resultText(result *mcp.CallToolResult) string {
+	var text strings.Builder
+	for _, content := range result.Content {
+		if textContent, ok := content.(mcp.TextContent); ok {
+			text.WriteString(textContent.Text)
+		}
+	}
+	return text.String()
+}
+
+// previewResult replaces the text of result with a preview of the output of
+// rec, see outputPreview, keeping its other content.
+func previewResult(result *mcp.CallToolResult, rec history.Record, limit int) {
+	content := []mcp.Content{mcp.NewTextContent(outputPreview(rec.Output, limit, rec.OutputURI()))}
+	for _, item := range result.Content {
+		if _, ok := item.(mcp.TextContent); !ok {
+			content = append(content, item)
+		}
+	}
+	result.Content = content
+}
+
+// outputPreview returns the start and the end of output, about half of limit
+// bytes each and cut at line breaks where possible, around a note on the
+// omitted part, whose full output is at uri.
+func outputPreview(output string, limit int, uri string) string {
+	headEnd := limit / 2
+	for headEnd > 0 && !utf8.RuneStart(output[headEnd]) {
+		headEnd--
+	}
+	if i := strings.LastIndexByte(output[:headEnd], '\n'); i >= headEnd/2 {
+		headEnd = i + 1
+	}
+	tailStart := len(output) - limit/2
+	for tailStart < len(output) && !utf8.RuneStart(output[tailStart]) {
+		tailStart++
+	}
+	if i := strings.IndexByte(output[tailStart:], '\n'); i >= 0 && i < (len(output)-tailStart)/2 {
+		tailStart += i + 1
+	}
+
+	head := output[:headEnd]
+	if !strings.HasSuffix(head, "\n") {
+		head += "\n"
+	}
+	return fmt.Sprintf("%s[... %d of %d bytes omitted, read the full output from %s ...]\n%s", head, tailStart-headEnd, len(output), uri, output[tailStart:])
+}
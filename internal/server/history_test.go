@@ -0,0 +1,361 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/history"
+)
+
+func TestHistoryRecorder_Middleware(t *testing.T) {
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+	recorder := &historyRecorder{store: history.NewStore(2), mcpServer: mcpServer}
+
+	var executionID string
+	handler := recorder.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		executionID = executor.ExecutionID(ctx)
+		return mcp.NewToolResultText("hello\n"), nil
+	})
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      "execute-python",
+			Arguments: map[string]any{"code": `print("hello")`},
+		},
+	}
+
+	result, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	// Result should link to the stored execution
+	if len(result.Content) != 2 {
+		t.Fatalf("Expected text and resource link content, got %d items", len(result.Content))
+	}
+	link, ok := result.Content[1].(mcp.ResourceLink)
+	if !ok {
+		t.Fatalf("Second content item should be a resource link, got %T", result.Content[1])
+	}
+	if !strings.HasPrefix(link.URI, history.URIScheme) {
+		t.Errorf("Resource link URI = %q, want %s prefix", link.URI, history.URIScheme)
+	}
+
+	records := recorder.store.List()
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 stored execution, got %d", len(records))
+	}
+	if records[0].Code != `print("hello")` || records[0].Output != "hello\n" || records[0].Status != "success" {
+		t.Errorf("Stored record = %+v", records[0])
+	}
+	if executionID == "" || records[0].ID != executionID {
+		t.Errorf("Execution ID seen by the handler = %q, want the stored ID %q", executionID, records[0].ID)
+	}
+
+	// Reading the resource returns the stored record as JSON
+	contents, err := recorder.readResource(context.Background(), mcp.ReadResourceRequest{
+		Params: mcp.ReadResourceParams{URI: link.URI},
+	})
+	if err != nil {
+		t.Fatalf("readResource() error: %v", err)
+	}
+	text := contents[0].(mcp.TextResourceContents).Text
+	if !strings.Contains(text, `"output": "hello\n"`) {
+		t.Errorf("Resource text should contain output, got: %s", text)
+	}
+}
+
+func TestHistoryRecorder_SkipsNonExecuteTools(t *testing.T) {
+	recorder := &historyRecorder{store: history.NewStore(2)}
+	handler := recorder.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "other-tool"}}
+	if _, err := handler(context.Background(), request); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if len(recorder.store.List()) != 0 {
+		t.Error("Non-execute tools should not be recorded")
+	}
+}
+
+func TestHistoryRecorder_ReadUnknown(t *testing.T) {
+	recorder := &historyRecorder{store: history.NewStore(2)}
+
+	_, err := recorder.readResource(context.Background(), mcp.ReadResourceRequest{
+		Params: mcp.ReadResourceParams{URI: history.URIScheme + "missing"},
+	})
+	if err == nil {
+		t.Error("readResource() should fail for unknown executions")
+	}
+}
+
+func TestHistoryRecorder_Usage(t *testing.T) {
+	recorder := &historyRecorder{store: history.NewStore(2)}
+	handler := recorder.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		output, err := executor.NewSubprocessBashExecutor().Execute(ctx, "echo hello", nil, nil)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(output), nil
+	})
+
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "execute-bash", Arguments: map[string]any{"script": "echo hello"}}}
+	result, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	usage := recorder.store.List()[0].Usage
+	if usage == nil || usage.WallTime <= 0 {
+		t.Fatalf("Stored usage = %+v, want the usage reported by the executor", usage)
+	}
+	if result.Meta == nil || result.Meta.AdditionalFields[usageMetaKey] != usage {
+		t.Errorf("Result _meta = %+v, want the usage under %s", result.Meta, usageMetaKey)
+	}
+}
+
+func TestHistoryRecorder_Artifacts(t *testing.T) {
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+	recorder := &historyRecorder{store: history.NewStore(1), mcpServer: mcpServer}
+	exec := executor.NewArtifactsExecutor(executor.NewSandboxEnvExecutor(executor.NewSubprocessBashExecutor(), "subprocess"), t.TempDir())
+	handler := recorder.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		output, err := exec.Execute(ctx, request.GetString("script", ""), nil, nil)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(output), nil
+	})
+	call := func(script string) *mcp.CallToolResult {
+		result, err := handler(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "execute-bash", Arguments: map[string]any{"script": script}},
+		})
+		if err != nil {
+			t.Fatalf("handler returned error: %v", err)
+		}
+		return result
+	}
+
+	result := call(`printf '# Report\n' > "$MCP_ARTIFACTS/report.md"; echo '<p>x</p>' > "$MCP_ARTIFACTS/page.html"; echo data > "$MCP_ARTIFACTS/data.csv"`)
+	rec := recorder.store.List()[0]
+	if len(rec.Artifacts) != 2 || len(result.Content) != 4 {
+		t.Fatalf("Stored artifacts = %+v with %d result items, want the two reports linked", rec.Artifacts, len(result.Content))
+	}
+	var markdown mcp.ResourceLink
+	for _, content := range result.Content[2:] {
+		if link := content.(mcp.ResourceLink); link.Name == "report.md" {
+			markdown = link
+		}
+	}
+	if markdown.URI != rec.ArtifactURI("report.md") || markdown.MIMEType != "text/markdown" {
+		t.Fatalf("Report link = %+v, want %s with text/markdown", markdown, rec.ArtifactURI("report.md"))
+	}
+	contents, err := recorder.readArtifact(context.Background(), mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: markdown.URI}})
+	if err != nil {
+		t.Fatalf("readArtifact() returned error: %v", err)
+	}
+	if report := contents[0].(mcp.TextResourceContents); report.Text != "# Report\n" || report.MIMEType != "text/markdown" {
+		t.Errorf("Report resource = %+v", report)
+	}
+
+	// The reports are evicted with their execution
+	call("echo done")
+	if _, err := recorder.readArtifact(context.Background(), mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: markdown.URI}}); err == nil {
+		t.Error("readArtifact() should fail for an evicted execution")
+	}
+}
+
+func TestHistoryRecorder_StdinFrom(t *testing.T) {
+	recorder := &historyRecorder{store: history.NewStore(4)}
+	var stdin string
+	var piped bool
+	handler := recorder.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		stdin, piped = executor.Stdin(ctx)
+		if request.GetString("code", "") == "fail" {
+			return mcp.NewToolResultError("boom"), nil
+		}
+		return mcp.NewToolResultText("a,b\n"), nil
+	})
+	call := func(arguments map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		result, err := handler(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "execute-bash", Arguments: arguments},
+		})
+		if err != nil {
+			t.Fatalf("handler returned error: %v", err)
+		}
+		return result
+	}
+
+	call(map[string]any{"code": "echo a,b"})
+	source := recorder.store.List()[0]
+	call(map[string]any{"code": "fail"})
+	failed := recorder.store.List()[0]
+
+	tests := []struct {
+		name      string
+		stdinFrom string
+		wantErr   bool
+	}{
+		{"execution ID", source.ID, false},
+		{"execution URI", source.URI(), false},
+		{"unknown execution", "0123456789abcdef", true},
+		{"failed execution", failed.ID, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdin, piped = "", false
+			result := call(map[string]any{"code": "cat", "stdin_from": tt.stdinFrom})
+			if result.IsError != tt.wantErr {
+				t.Fatalf("IsError = %v, want %v: %v", result.IsError, tt.wantErr, result.Content)
+			}
+			if tt.wantErr && piped {
+				t.Error("Rejected call reached the tool")
+			}
+			if !tt.wantErr && (!piped || stdin != "a,b\n") {
+				t.Errorf("Stdin = %q (%v), want the output of the execution", stdin, piped)
+			}
+		})
+	}
+
+	if call(map[string]any{"code": "cat"}); piped {
+		t.Error("Call without stdin_from has input")
+	}
+}
+
+func TestHistoryRecorder_ClientIsolation(t *testing.T) {
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+	recorder := &historyRecorder{store: history.NewStore(4), mcpServer: mcpServer}
+	var piped bool
+	handler := recorder.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		_, piped = executor.Stdin(ctx)
+		return mcp.NewToolResultText("secret\n"), nil
+	})
+	owner := withClientID(mcpServer.WithContext(context.Background(), server.NewInProcessSession("session-1", nil)), "key-owner")
+	other := withClientID(context.Background(), "key-other")
+
+	if _, err := handler(owner, mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "execute-bash", Arguments: map[string]any{"script": "echo secret"}}}); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	rec := recorder.store.List()[0]
+	if rec.Client != "key-owner" || rec.Session != "session-1" {
+		t.Errorf("Stored record has client %q and session %q, want the caller", rec.Client, rec.Session)
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantErr bool
+	}{
+		{"owner", owner, false},
+		{"other client", other, true},
+		{"anonymous client", context.Background(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := recorder.readResource(tt.ctx, mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: rec.URI()}})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("readResource() error = %v, want error %t", err, tt.wantErr)
+			}
+			if _, ok := recorder.lookup(tt.ctx, rec.ID); ok == tt.wantErr {
+				t.Errorf("lookup() found the execution: %t, want %t", ok, !tt.wantErr)
+			}
+
+			piped = false
+			result, err := handler(tt.ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "execute-bash", Arguments: map[string]any{"script": "cat", "stdin_from": rec.ID}}})
+			if err != nil {
+				t.Fatalf("handler returned error: %v", err)
+			}
+			if result.IsError != tt.wantErr || piped == tt.wantErr {
+				t.Errorf("Piping the execution: IsError = %t and piped = %t, want only the owner to pipe it", result.IsError, piped)
+			}
+		})
+	}
+}
+
+func TestHistoryRecorder_PreviewsLongOutput(t *testing.T) {
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+	recorder := &historyRecorder{store: history.NewStore(2), mcpServer: mcpServer, maxInline: 100}
+	var lines []string
+	for i := range 50 {
+		lines = append(lines, fmt.Sprintf("line %02d", i))
+	}
+	output := strings.Join(lines, "\n") + "\n"
+	handler := recorder.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(request.GetString("code", "")), nil
+	})
+	call := func(code string) *mcp.CallToolResult {
+		t.Helper()
+		result, err := handler(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "execute-python", Arguments: map[string]any{"code": code}},
+		})
+		if err != nil {
+			t.Fatalf("handler returned error: %v", err)
+		}
+		return result
+	}
+
+	if result := call("short\n"); len(result.Content) != 2 || resultText(result) != "short\n" {
+		t.Errorf("Short output was changed: %v", result.Content)
+	}
+
+	result := call(output)
+	rec := recorder.store.List()[0]
+	if !rec.Truncated || rec.Output != output {
+		t.Fatalf("Stored record = %+v, want the full output flagged as truncated", rec)
+	}
+	preview := resultText(result)
+	if !strings.HasPrefix(preview, "line 00\n") || !strings.HasSuffix(preview, "line 49\n") || len(preview) > 200 {
+		t.Errorf("Preview = %q, want the start and end of the output", preview)
+	}
+	if !strings.Contains(preview, "omitted, read the full output from "+rec.OutputURI()) {
+		t.Errorf("Preview = %q, want a note linking the full output", preview)
+	}
+	if len(result.Content) != 3 {
+		t.Fatalf("Expected the preview and two resource links, got %d items", len(result.Content))
+	}
+	if link, ok := result.Content[1].(mcp.ResourceLink); !ok || link.URI != rec.OutputURI() {
+		t.Errorf("Second content item = %v, want a link to %s", result.Content[1], rec.OutputURI())
+	}
+
+	contents, err := recorder.readOutput(context.Background(), mcp.ReadResourceRequest{
+		Params: mcp.ReadResourceParams{URI: rec.OutputURI()},
+	})
+	if err != nil {
+		t.Fatalf("readOutput() error: %v", err)
+	}
+	if text := contents[0].(mcp.TextResourceContents).Text; text != output {
+		t.Errorf("readOutput() = %q, want the full output", text)
+	}
+}
+
+func TestOutputPreview(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		limit  int
+		head   string
+		tail   string
+	}{
+		{"lines", "aaaa\nbbbb\ncccc\ndddd\neeee\n", 12, "aaaa\n", "eeee\n"},
+		{"single line", strings.Repeat("x", 30), 10, "xxxxx\n", "xxxxx"},
+		{"multi-byte runes", strings.Repeat("é", 20), 9, "éé\n", "éé"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preview := outputPreview(tt.output, tt.limit, "output://1")
+			head, rest, _ := strings.Cut(preview, "[... ")
+			_, tail, _ := strings.Cut(rest, "...]\n")
+			if head != tt.head || tail != tt.tail {
+				t.Errorf("outputPreview() = %q, want head %q and tail %q", preview, tt.head, tt.tail)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+// Package server describes the sandbox to clients when they initialize: the
+// execution mode, the enabled tools, what they can install, the limits and
+// what persists between calls, so that models do not have to probe for it.
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+)
+
+// SandboxCapability names the experimental capability of the initialize
+// result describing the sandbox.
+const SandboxCapability = "mcp-executor"
+
+// modeDescriptions describe where the calls of each execution mode run.
+var modeDescriptions = map[string]string{
+	"subprocess": "Every call runs as a process on the server host, with its runtimes and preinstalled packages, in a scratch directory of its own.",
+	"docker":     "Every call runs in a fresh Docker container, removed when the call ends.",
+	"hybrid": "Calls without dependencies, mounts, a workspace, a profile or a runtime version run in a warm Docker container of their language, " +
+		"in a directory of their own removed when the call ends; other calls run in a fresh container removed when the call ends.",
+	"nix": "Every call runs on the server host in a throwaway nix-shell providing the packages it requests, named by their nixpkgs attribute.",
+}
+
+// sandboxDescription is the experimental capability describing the sandbox.
+type sandboxDescription struct {
+	Mode     string   `json:"mode"`
+	Modes    []string `json:"modes,omitempty"` // Modes the session may select with set-execution-mode
+	Tools    []string `json:"tools"`
+	Installs []string `json:"installs,omitempty"` // Tools installing the dependencies of a call
+	Offline  bool     `json:"offline,omitempty"`
+
+	TimeoutSeconds    float64 `json:"timeout_seconds,omitempty"`     // Default timeout of a call
+	MaxTimeoutSeconds float64 `json:"max_timeout_seconds,omitempty"` // Longest timeout a call may request
+	// Limit of the dependency installation of a call, on top of its timeout
+	InstallTimeoutSeconds float64 `json:"install_timeout_seconds,omitempty"`
+	Memory                string  `json:"memory,omitempty"`
+	CPUs                  string  `json:"cpus,omitempty"`
+	MaxConcurrent         int     `json:"max_concurrent,omitempty"`
+	MaxCodeSize           int     `json:"max_code_size,omitempty"` // In bytes
+	MaxDiskMB             int     `json:"max_disk_mb,omitempty"`
+
+	PersistentContainers bool `json:"persistent_containers,omitempty"` // Warm containers are reused across calls
+
+	languageTools int // Enabled execute tools of the languages
+}
+
+// sandboxInstructions sets the instructions and the SandboxCapability of the
+// initialize results from the execution mode of the session, the enabled tools
+// and the current options, which reloads replace.
+type sandboxInstructions struct {
+	modes    *sessionModes
+	registry *toolRegistry
+
+	mu      sync.Mutex
+	options Options
+}
+
+// set replaces the options described to the clients initializing next.
+func (s *sandboxInstructions) set(options Options) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.options = options
+}
+
+// afterInitialize is the hook completing the initialize result.
+func (s *sandboxInstructions) afterInitialize(ctx context.Context, _ any, _ *mcp.InitializeRequest, result *mcp.InitializeResult) {
+	sandbox := s.describe(s.modes.mode(ctx))
+	result.Instructions = sandbox.instructions()
+	if result.Capabilities.Experimental == nil {
+		result.Capabilities.Experimental = map[string]any{}
+	}
+	result.Capabilities.Experimental[SandboxCapability] = sandbox
+}
+
+// describe returns the description of the sandbox running in mode.
+func (s *sandboxInstructions) describe(mode string) sandboxDescription {
+	s.mu.Lock()
+	options := s.options
+	s.mu.Unlock()
+
+	sandbox := sandboxDescription{
+		Mode:                 mode,
+		Offline:              options.Offline,
+		TimeoutSeconds:       options.Limits.Timeout.Seconds(),
+		MaxTimeoutSeconds:    options.Limits.MaxTimeout.Seconds(),
+		MaxConcurrent:        options.Limits.MaxConcurrent,
+		MaxCodeSize:          options.Limits.MaxCodeSize,
+		MaxDiskMB:            options.Limits.MaxDiskMB,
+		PersistentContainers: mode == "hybrid" && options.containerPool != nil,
+	}
+	if s.modes.selectable() {
+		sandbox.Modes = s.modes.allowed
+	}
+	if executor.ContainerMode(mode) {
+		sandbox.Memory, sandbox.CPUs = options.Limits.Memory, options.Limits.CPUs
+	}
+	tools := s.registry.enabledTools(mode)
+	for _, key := range toolKeys() {
+		tool, ok := tools[key]
+		if !ok {
+			continue
+		}
+		sandbox.Tools = append(sandbox.Tools, tool.Name)
+		// The execute tools of the languages declare the dependencies they
+		// install, which offline servers refuse
+		_, packages := tool.InputSchema.Properties["packages"]
+		_, modules := tool.InputSchema.Properties["modules"]
+		if !slices.Contains(Languages, key) {
+			continue
+		}
+		sandbox.languageTools++
+		if (packages || modules) && !options.Offline {
+			sandbox.Installs = append(sandbox.Installs, tool.Name)
+		}
+	}
+	if len(sandbox.Installs) > 0 {
+		sandbox.InstallTimeoutSeconds = options.Limits.InstallTimeout.Seconds()
+	}
+	return sandbox
+}
+
+// instructions returns the description of the sandbox as server instructions.
+func (s sandboxDescription) instructions() string {
+	var b strings.Builder
+	b.WriteString("This server runs code in a sandbox described below; rely on it instead of probing the environment.\n\n")
+	fmt.Fprintf(&b, "Execution mode: %s. %s\n", s.Mode, modeDescriptions[s.Mode])
+	if len(s.Modes) > 0 {
+		fmt.Fprintf(&b, "Execution modes: this session may switch to %s with set-execution-mode; the tools, dependencies and limits below change with the mode.\n", strings.Join(s.Modes, ", "))
+	}
+	if len(s.Tools) > 0 {
+		fmt.Fprintf(&b, "Tools: %s.\n", strings.Join(s.Tools, ", "))
+	}
+
+	switch {
+	case s.Offline:
+		b.WriteString("Dependencies: the server is offline, so nothing can be installed and code has no network access; use the standard library and preinstalled packages.\n")
+	case len(s.Installs) > 0:
+		fmt.Fprintf(&b, "Dependencies: %s install the packages or modules a call lists before running it. Installed packages do NOT persist between calls; list them in every call.", strings.Join(s.Installs, ", "))
+		if len(s.Installs) < s.languageTools {
+			b.WriteString(" The other execute tools only have the standard library and preinstalled packages.")
+		}
+		b.WriteString("\n")
+	default:
+		b.WriteString("Dependencies: nothing can be installed; use the standard library and the packages preinstalled on the host.\n")
+	}
+
+	var limits []string
+	if s.TimeoutSeconds > 0 {
+		limits = append(limits, fmt.Sprintf("a default timeout of %gs", s.TimeoutSeconds))
+	}
+	if s.MaxTimeoutSeconds > 0 {
+		limits = append(limits, fmt.Sprintf("timeouts of at most %gs", s.MaxTimeoutSeconds))
+	}
+	if s.InstallTimeoutSeconds > 0 {
+		limits = append(limits, fmt.Sprintf("dependency installation within %gs, not counted in the timeout", s.InstallTimeoutSeconds))
+	}
+	if s.Memory != "" {
+		limits = append(limits, "memory "+s.Memory)
+	}
+	if s.CPUs != "" {
+		limits = append(limits, s.CPUs+" CPUs")
+	}
+	if s.MaxDiskMB > 0 {
+		limits = append(limits, fmt.Sprintf("%d MB of disk", s.MaxDiskMB))
+	}
+	if s.MaxCodeSize > 0 {
+		limits = append(limits, fmt.Sprintf("code of at most %d bytes", s.MaxCodeSize))
+	}
+	if s.MaxConcurrent > 0 {
+		limits = append(limits, fmt.Sprintf("%d executions at once, further calls wait", s.MaxConcurrent))
+	}
+	if len(limits) > 0 {
+		fmt.Fprintf(&b, "Limits: %s.\n", strings.Join(limits, "; "))
+	}
+
+	b.WriteString("Persistence: files, installed packages and processes of a call are gone when it ends. " +
+		"Calls of a session passing the same workspace name share a directory, $MCP_WORKSPACE, removed when the session ends. " +
+		"The output of recent executions stays readable as execution://{id} resources and can be passed to another call with stdin_from.")
+	if s.PersistentContainers {
+		b.WriteString(" Warm containers are shared by calls, so do not rely on anything a call leaves outside its directory.")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
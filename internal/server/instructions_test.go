@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/config"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+)
+
+// initialize returns the result of initializing a session of mcpServer.
+func initialize(t *testing.T, mcpServer *server.MCPServer) mcp.InitializeResult {
+	t.Helper()
+	message := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`
+	response, ok := mcpServer.HandleMessage(context.Background(), json.RawMessage(message)).(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatal("initialize did not return a result")
+	}
+	result, ok := response.Result.(mcp.InitializeResult)
+	if !ok {
+		t.Fatalf("initialize returned %T, want mcp.InitializeResult", response.Result)
+	}
+	return result
+}
+
+func TestModeDescriptions(t *testing.T) {
+	for _, mode := range executor.ExecutionModes {
+		if modeDescriptions[mode] == "" {
+			t.Errorf("Execution mode %s has no description", mode)
+		}
+	}
+	if len(modeDescriptions) != len(executor.ExecutionModes) {
+		t.Errorf("modeDescriptions describes %d modes, want the %d execution modes", len(modeDescriptions), len(executor.ExecutionModes))
+	}
+}
+
+func TestSandboxInstructions(t *testing.T) {
+	limits := config.LimitsConfig{Memory: "512m", CPUs: "1.5", Timeout: 30 * time.Second, MaxTimeout: 2 * time.Minute, InstallTimeout: time.Minute, MaxConcurrent: 4}
+	tests := []struct {
+		name         string
+		mode         string
+		opts         []Option
+		wantInstalls []string
+		wantText     []string
+		wantNotText  []string
+	}{
+		{
+			name:         "docker",
+			mode:         "docker",
+			opts:         []Option{WithResourceLimits(limits)},
+			wantInstalls: []string{"execute-python", "execute-bash", "execute-typescript", "execute-go"},
+			wantText:     []string{"Execution mode: docker.", "do NOT persist", "memory 512m", "1.5 CPUs", "a default timeout of 30s", "timeouts of at most 120s", "dependency installation within 60s", "4 executions at once"},
+		},
+		{
+			name:        "subprocess",
+			mode:        "subprocess",
+			opts:        []Option{WithResourceLimits(limits), WithEnabledTools([]string{"go"})},
+			wantText:    []string{"Execution mode: subprocess.", "Tools: execute-go.", "nothing can be installed"},
+			wantNotText: []string{"memory 512m", "CPUs", "dependency installation within"},
+		},
+		{
+			name:        "offline",
+			mode:        "docker",
+			opts:        []Option{WithOffline(true)},
+			wantText:    []string{"the server is offline"},
+			wantNotText: []string{"Limits:"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mcpServer, _ := NewReloadableMCPServer(tt.mode, tt.opts...)
+			result := initialize(t, mcpServer)
+			for _, text := range tt.wantText {
+				if !strings.Contains(result.Instructions, text) {
+					t.Errorf("Instructions should contain %q, got:\n%s", text, result.Instructions)
+				}
+			}
+			for _, text := range tt.wantNotText {
+				if strings.Contains(result.Instructions, text) {
+					t.Errorf("Instructions should not contain %q, got:\n%s", text, result.Instructions)
+				}
+			}
+			sandbox, ok := result.Capabilities.Experimental[SandboxCapability].(sandboxDescription)
+			if !ok {
+				t.Fatalf("Experimental capability %s missing", SandboxCapability)
+			}
+			if sandbox.Mode != tt.mode {
+				t.Errorf("Capability mode = %q, want %q", sandbox.Mode, tt.mode)
+			}
+			if !reflect.DeepEqual(sandbox.Installs, tt.wantInstalls) {
+				t.Errorf("Capability installs = %q, want %q", sandbox.Installs, tt.wantInstalls)
+			}
+		})
+	}
+}
+
+func TestSandboxInstructions_Reload(t *testing.T) {
+	mcpServer, reloader := NewReloadableMCPServer("docker")
+	if result := initialize(t, mcpServer); strings.Contains(result.Instructions, "offline") {
+		t.Errorf("Instructions should not mention being offline before the reload")
+	}
+
+	reloader.Reload(WithOffline(true), WithEnabledTools([]string{"python"}))
+	result := initialize(t, mcpServer)
+	if !strings.Contains(result.Instructions, "the server is offline") || !strings.Contains(result.Instructions, "Tools: execute-python.") {
+		t.Errorf("Instructions should describe the reloaded options, got:\n%s", result.Instructions)
+	}
+}
@@ -0,0 +1,179 @@
+// Package server provides the kill switch terminating all running executions,
+// optionally refusing new ones, through the admin endpoints of the SSE/HTTP
+// transports.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/config"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+	"github.com/ylchen07/mcp-executor/internal/tools"
+)
+
+// errKilled is the cancellation cause of executions stopped by the kill switch.
+var errKilled = errors.New("execution killed by the server operator")
+
+// KillSwitch tracks the running execute tool calls so that an operator can
+// cancel them all at once, and refuses new ones while executions are disabled.
+type KillSwitch struct {
+	mu       sync.Mutex
+	disabled bool
+	running  map[uint64]context.CancelCauseFunc // Cancels the running calls by call number
+	calls    uint64
+}
+
+// KillSwitchStatus is the state reported by the admin endpoints.
+type KillSwitchStatus struct {
+	Running  int  `json:"running"`  // Execute calls running or waiting for a slot, including killed ones still stopping
+	Killed   int  `json:"killed"`   // Calls cancelled by this request
+	Disabled bool `json:"disabled"` // New execute calls are refused
+}
+
+// NewKillSwitch creates a kill switch with executions enabled.
+func NewKillSwitch() *KillSwitch {
+	return &KillSwitch{running: make(map[uint64]context.CancelCauseFunc)}
+}
+
+// Kill cancels every running execute call, including calls waiting in the
+// queue, and with disable refuses new calls until Enable.
+func (k *KillSwitch) Kill(disable bool) KillSwitchStatus {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for _, cancel := range k.running {
+		cancel(errKilled)
+	}
+	k.disabled = k.disabled || disable
+	logger.Info("Kill switch: killed %d executions (executions disabled: %t)", len(k.running), k.disabled)
+	return KillSwitchStatus{Running: len(k.running), Killed: len(k.running), Disabled: k.disabled}
+}
+
+// Enable accepts execute calls again after Kill disabled them.
+func (k *KillSwitch) Enable() KillSwitchStatus {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.disabled {
+		logger.Info("Kill switch: executions enabled again")
+	}
+	k.disabled = false
+	return KillSwitchStatus{Running: len(k.running)}
+}
+
+// Status reports the running calls and whether executions are disabled.
+func (k *KillSwitch) Status() KillSwitchStatus {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return KillSwitchStatus{Running: len(k.running), Disabled: k.disabled}
+}
+
+// begin registers an execute call, returning its context and the function
+// unregistering it, or false while executions are disabled.
+func (k *KillSwitch) begin(ctx context.Context) (context.Context, func(), bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.disabled {
+		return nil, nil, false
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	k.calls++
+	call := k.calls
+	k.running[call] = cancel
+	return ctx, func() {
+		k.mu.Lock()
+		delete(k.running, call)
+		k.mu.Unlock()
+		cancel(nil)
+	}, true
+}
+
+// middleware runs execute calls under the kill switch. Calls killed while
+// running fail with a message naming the operator, after any output.
+func (k *KillSwitch) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !isExecutionTool(request.Params.Name) {
+			return next(ctx, request)
+		}
+		ctx, end, ok := k.begin(ctx)
+		if !ok {
+			return tools.ErrorResult(executor.ErrorPolicyViolation, "Execution not started: executions are disabled by the server operator"), nil
+		}
+		defer end()
+
+		result, err := next(ctx, request)
+		if !errors.Is(context.Cause(ctx), errKilled) || result == nil {
+			return result, err
+		}
+		message := errKilled.Error()
+		if text := resultText(result); text != "" {
+			message += "; " + text
+		}
+		return tools.ErrorResult(executor.ErrorInfrastructure, message), nil
+	}
+}
+
+// registerEndpoints serves the kill switch on mux below prefix: POST kill
+// (with ?disable=true), POST enable and GET executions.
+func (k *KillSwitch) registerEndpoints(mux *http.ServeMux, prefix string) {
+	mux.HandleFunc(prefix+"/kill", func(w http.ResponseWriter, r *http.Request) {
+		if !allowMethod(w, r, http.MethodPost) {
+			return
+		}
+		disable, err := strconv.ParseBool(r.URL.Query().Get("disable"))
+		if err != nil && r.URL.Query().Has("disable") {
+			http.Error(w, "invalid disable parameter: expected true or false", http.StatusBadRequest)
+			return
+		}
+		writeStatus(w, k.Kill(disable))
+	})
+	mux.HandleFunc(prefix+"/enable", func(w http.ResponseWriter, r *http.Request) {
+		if allowMethod(w, r, http.MethodPost) {
+			writeStatus(w, k.Enable())
+		}
+	})
+	mux.HandleFunc(prefix+"/executions", func(w http.ResponseWriter, r *http.Request) {
+		if allowMethod(w, r, http.MethodGet) {
+			writeStatus(w, k.Status())
+		}
+	})
+}
+
+// AdminURL returns the local URL of the admin endpoints of a server started
+// with transport, e.g. "http://localhost:8081/admin".
+func AdminURL(transport config.TransportConfig) (string, error) {
+	var addr string
+	switch transport.Mode {
+	case "http":
+		addr = transport.HTTPAddr
+	case "sse":
+		addr = transport.SSEAddr
+	default:
+		return "", fmt.Errorf("the admin endpoints need the sse or http transport, not %s", transport.Mode)
+	}
+	o := TransportOptions{TLSCertFile: transport.TLSCert, BasePath: normalizeBasePath(transport.BasePath)}
+	return o.baseURL(hostURL(addr)) + o.BasePath + "/admin", nil
+}
+
+// allowMethod answers requests using another method than method with 405.
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	return false
+}
+
+// writeStatus writes status as JSON.
+func writeStatus(w http.ResponseWriter, status KillSwitchStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/config"
+)
+
+func TestKillSwitch_Middleware(t *testing.T) {
+	killSwitch := NewKillSwitch()
+	started := make(chan struct{})
+	handler := killSwitch.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-ctx.Done()
+		return mcp.NewToolResultError("execution cancelled: partial output"), nil
+	})
+	call := func(name string) *mcp.CallToolResult {
+		result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: name}})
+		if err != nil {
+			t.Errorf("handler returned error: %v", err)
+		}
+		return result
+	}
+
+	results := make(chan *mcp.CallToolResult)
+	go func() { results <- call("execute-python") }()
+	<-started
+	if status := killSwitch.Status(); status.Running != 1 || status.Disabled {
+		t.Errorf("Status() = %+v, want one running call", status)
+	}
+	if status := killSwitch.Kill(true); status.Killed != 1 || !status.Disabled {
+		t.Errorf("Kill(true) = %+v, want one killed call and executions disabled", status)
+	}
+	result := <-results
+	if text := resultText(result); !result.IsError || !strings.HasPrefix(text, errKilled.Error()) || !strings.Contains(text, "partial output") {
+		t.Errorf("Killed call result = %q, want the kill message and the output", text)
+	}
+	if status := killSwitch.Status(); status.Running != 0 {
+		t.Errorf("Status().Running = %d after the kill, want 0", status.Running)
+	}
+
+	if result := call("execute-bash"); !result.IsError || !strings.Contains(resultText(result), "disabled") {
+		t.Errorf("Call while disabled = %q, want it refused", resultText(result))
+	}
+	if status := killSwitch.Enable(); status.Disabled {
+		t.Error("Enable() should accept executions again")
+	}
+}
+
+func TestKillSwitch_Endpoints(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		wantStatus   int
+		wantDisabled bool
+	}{
+		{"status", http.MethodGet, "/executor/admin/executions", http.StatusOK, false},
+		{"kill", http.MethodPost, "/executor/admin/kill", http.StatusOK, false},
+		{"kill and disable", http.MethodPost, "/executor/admin/kill?disable=true", http.StatusOK, true},
+		{"invalid disable", http.MethodPost, "/executor/admin/kill?disable=maybe", http.StatusBadRequest, false},
+		{"kill with get", http.MethodGet, "/executor/admin/kill", http.StatusMethodNotAllowed, false},
+		{"enable", http.MethodPost, "/executor/admin/enable", http.StatusOK, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			killSwitch := NewKillSwitch()
+			options := newTransportOptions([]TransportOption{
+				WithBasePath("/executor"),
+				WithAuthTokens([]string{"secret"}),
+				WithKillSwitchEndpoints(killSwitch),
+			})
+			handler := options.handler(http.NotFoundHandler())
+
+			request := httptest.NewRequest(tt.method, tt.path, nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			if recorder.Code != http.StatusUnauthorized {
+				t.Errorf("Status without token = %d, want %d", recorder.Code, http.StatusUnauthorized)
+			}
+
+			request.Header.Set("Authorization", "Bearer secret")
+			recorder = httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var status KillSwitchStatus
+			if err := json.Unmarshal(recorder.Body.Bytes(), &status); err != nil {
+				t.Fatalf("Response is not a status: %v", err)
+			}
+			if status.Disabled != tt.wantDisabled || killSwitch.Status().Disabled != tt.wantDisabled {
+				t.Errorf("Disabled = %t, want %t", status.Disabled, tt.wantDisabled)
+			}
+		})
+	}
+}
+
+func TestAdminURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		transport config.TransportConfig
+		want      string
+	}{
+		{"http", config.TransportConfig{Mode: "http", HTTPAddr: ":8081"}, "http://localhost:8081/admin"},
+		{"sse with base path", config.TransportConfig{Mode: "sse", SSEAddr: "127.0.0.1:8080", BasePath: "executor/"}, "http://127.0.0.1:8080/executor/admin"},
+		{"tls", config.TransportConfig{Mode: "http", HTTPAddr: "0.0.0.0:8443", TLSCert: "server.crt"}, "https://0.0.0.0:8443/admin"},
+		{"stdio", config.TransportConfig{Mode: "stdio"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AdminURL(tt.transport)
+			if (err != nil) != (tt.want == "") || got != tt.want {
+				t.Errorf("AdminURL() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
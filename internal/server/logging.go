@@ -0,0 +1,35 @@
+// Package server forwards the log messages of tool calls to the calling client as
+// MCP notifications/message, honoring the level the client set with logging/setLevel.
+package server
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/config"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// logSender sends log notifications to the client of the session in ctx.
+type logSender interface {
+	SendLogMessageToClient(ctx context.Context, notification mcp.LoggingMessageNotification) error
+}
+
+// logForwarder attaches a logger.Sink to every tool call that forwards its messages
+// to the calling client. Messages below the session's level are dropped by mcp-go.
+type logForwarder struct {
+	sender logSender
+}
+
+// middleware installs the forwarding sink in the tool call's context.
+func (f *logForwarder) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		sinkCtx := logger.WithSink(ctx, func(level, message string) {
+			notification := mcp.NewLoggingMessageNotification(mcp.LoggingLevel(level), config.ServerName, message)
+			// Sessions that are not initialized or do not support logging only get the server log.
+			_ = f.sender.SendLogMessageToClient(ctx, notification)
+		})
+		return next(sinkCtx, request)
+	}
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+type fakeLogSender struct {
+	notifications []mcp.LoggingMessageNotification
+}
+
+func (f *fakeLogSender) SendLogMessageToClient(ctx context.Context, notification mcp.LoggingMessageNotification) error {
+	f.notifications = append(f.notifications, notification)
+	return nil
+}
+
+func TestLogForwarder_Middleware(t *testing.T) {
+	sender := &fakeLogSender{}
+	forwarder := &logForwarder{sender: sender}
+
+	handler := forwarder.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger.InfoContext(ctx, "Installing %s dependencies: %s", "python", "requests")
+		logger.WarnContext(ctx, "Execution timed out after 1s")
+		return mcp.NewToolResultText("ok"), nil
+	})
+	if _, err := handler(context.Background(), mcp.CallToolRequest{}); err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if len(sender.notifications) != 2 {
+		t.Fatalf("Forwarded %d notifications, want 2", len(sender.notifications))
+	}
+	tests := []struct {
+		level   mcp.LoggingLevel
+		message string
+	}{
+		{mcp.LoggingLevelInfo, "Installing python dependencies: requests"},
+		{mcp.LoggingLevelWarning, "Execution timed out after 1s"},
+	}
+	for i, tt := range tests {
+		params := sender.notifications[i].Params
+		if params.Level != tt.level || params.Data != tt.message {
+			t.Errorf("Notification %d = %s %v, want %s %q", i, params.Level, params.Data, tt.level, tt.message)
+		}
+		if params.Logger != "mcp-executor" {
+			t.Errorf("Notification %d logger = %q, want mcp-executor", i, params.Logger)
+		}
+	}
+}
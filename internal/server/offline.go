@@ -0,0 +1,22 @@
+// Package server notes in the descriptions of the execute tools that the
+// server runs offline, so clients do not request dependencies or networks.
+package server
+
+import "github.com/mark3labs/mcp-go/mcp"
+
+// offlineTool describes an execute tool of an offline server.
+type offlineTool struct {
+	executionTool
+	docker bool // Containers run without a network
+}
+
+func (t offlineTool) CreateTool() mcp.Tool {
+	tool := t.executionTool.CreateTool()
+	note := "OFFLINE: this server has no network access. Dependencies (modules, packages, dependency_file) cannot be installed; " +
+		"use the standard library and preinstalled packages only."
+	if t.docker {
+		note += " Containers run with network 'none'."
+	}
+	tool.Description += "\n" + note
+	return tool
+}
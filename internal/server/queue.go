@@ -0,0 +1,148 @@
+// Package server makes execute tool calls beyond the concurrency limit wait in
+// the execution queue, reporting their position as MCP progress notifications,
+// and queues preempted batch calls again.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/config"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+	"github.com/ylchen07/mcp-executor/internal/queue"
+	"github.com/ylchen07/mcp-executor/internal/tools"
+)
+
+// notificationSender sends notifications to the client of the session in ctx.
+type notificationSender interface {
+	SendNotificationToClient(ctx context.Context, method string, params map[string]any) error
+}
+
+// executionQueue limits the number of execute-* tool calls running at once.
+type executionQueue struct {
+	queue  *queue.Queue
+	sender notificationSender
+}
+
+// newExecutionQueue builds the execution queue, or returns nil when executions
+// are not limited.
+func newExecutionQueue(limits config.LimitsConfig) *executionQueue {
+	if limits.MaxConcurrent <= 0 {
+		return nil
+	}
+	logger.Debug("Limiting executions to %d at once (%d queued at most)", limits.MaxConcurrent, limits.MaxQueued)
+	q := queue.New(limits.MaxConcurrent, limits.MaxQueued)
+	if limits.PreemptAfter > 0 {
+		logger.Debug("Letting interactive executions preempt batch executions running for %s", limits.PreemptAfter)
+		q.PreemptAfter(limits.PreemptAfter)
+	}
+	return &executionQueue{queue: q}
+}
+
+// middleware holds execute calls until a slot is free, serving interactive calls
+// before batch calls. Batch calls may be preempted.
+func (q *executionQueue) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !isExecutionTool(request.Params.Name) {
+			return next(ctx, request)
+		}
+		priority, err := queue.ParsePriority(request.GetString("priority", ""))
+		if err != nil {
+			return tools.ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+		}
+
+		progress := &queueProgress{q: q, ctx: ctx, request: request}
+		if priority == queue.Batch {
+			return q.runPreemptible(ctx, request, next, progress)
+		}
+
+		release, err := q.queue.Acquire(ctx, priority, progress.queued)
+		if err != nil {
+			return tools.ErrorResult(executor.ErrorInfrastructure, fmt.Sprintf("Execution not started: %v", err)), nil
+		}
+		defer release()
+		progress.started()
+		return next(ctx, request)
+	}
+}
+
+// runPreemptible runs a batch call in a slot that waiting interactive calls may
+// preempt. The output of a preempted execution is discarded and the call is
+// queued again, then runs to completion.
+func (q *executionQueue) runPreemptible(ctx context.Context, request mcp.CallToolRequest, next server.ToolHandlerFunc, progress *queueProgress) (*mcp.CallToolResult, error) {
+	slotCtx, release, err := q.queue.AcquirePreemptible(ctx, progress.queued)
+	if err != nil {
+		return tools.ErrorResult(executor.ErrorInfrastructure, fmt.Sprintf("Execution not started: %v", err)), nil
+	}
+	progress.started()
+	result, err := next(slotCtx, request)
+	release()
+	if ctx.Err() != nil || !errors.Is(context.Cause(slotCtx), queue.ErrPreempted) {
+		return result, err
+	}
+
+	logger.WarnContext(ctx, "Batch execution preempted by an interactive execution, queuing it again")
+	progress.preempted()
+	release, err = q.queue.Acquire(ctx, queue.Batch, progress.queued)
+	if err != nil {
+		return tools.ErrorResult(executor.ErrorInfrastructure, fmt.Sprintf("Execution preempted and not restarted: %v", err)), nil
+	}
+	defer release()
+	progress.started()
+	return next(ctx, request)
+}
+
+// queueProgress reports the progress of a call through the queue. Every
+// notification counts one step, so the progress keeps increasing also when
+// interactive calls jump ahead of a batch call or a preempted call is queued
+// again; the total adds the positions the call still has to move up.
+type queueProgress struct {
+	q       *executionQueue
+	ctx     context.Context
+	request mcp.CallToolRequest
+	steps   int
+}
+
+// queued reports the 1-based queue position of the call.
+func (p *queueProgress) queued(position int) {
+	logger.InfoContext(p.ctx, "Execution queued at position %d", position)
+	p.steps++
+	p.q.notifyProgress(p.ctx, p.request, p.steps, p.steps+position, fmt.Sprintf("Queued at position %d", position))
+}
+
+// started completes the progress of a call that was queued.
+func (p *queueProgress) started() {
+	if p.steps == 0 {
+		return
+	}
+	p.steps++
+	p.q.notifyProgress(p.ctx, p.request, p.steps, p.steps, "Started")
+}
+
+// preempted reports that the call is queued again, at a position not yet known.
+func (p *queueProgress) preempted() {
+	p.steps++
+	p.q.notifyProgress(p.ctx, p.request, p.steps, 0, "Preempted by an interactive execution, queued again")
+}
+
+// notifyProgress reports progress out of total, unless zero, with message as a
+// progress notification when the client asked for progress with a progress
+// token.
+func (q *executionQueue) notifyProgress(ctx context.Context, request mcp.CallToolRequest, progress, total int, message string) {
+	if q.sender == nil || request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return
+	}
+	params := map[string]any{
+		"progressToken": request.Params.Meta.ProgressToken,
+		"progress":      progress,
+		"message":       message,
+	}
+	if total > 0 {
+		params["total"] = total
+	}
+	_ = q.sender.SendNotificationToClient(ctx, "notifications/progress", params)
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/config"
+)
+
+// recordingSender records the notifications sent to the client.
+type recordingSender struct {
+	mu      sync.Mutex
+	methods []string
+	params  []map[string]any
+}
+
+func (s *recordingSender) SendNotificationToClient(ctx context.Context, method string, params map[string]any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.methods = append(s.methods, method)
+	s.params = append(s.params, params)
+	return nil
+}
+
+func TestExecutionQueue_Middleware(t *testing.T) {
+	queued := newExecutionQueue(config.LimitsConfig{MaxConcurrent: 1, MaxQueued: 1})
+	sender := &recordingSender{}
+	queued.sender = sender
+
+	started, unblock := make(chan struct{}), make(chan struct{})
+	handler := queued.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if request.GetString("code", "") == "block" {
+			close(started)
+			<-unblock
+		}
+		return mcp.NewToolResultText("output"), nil
+	})
+	call := func(arguments map[string]any, meta *mcp.Meta) *mcp.CallToolResult {
+		result, err := handler(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "execute-python", Arguments: arguments, Meta: meta},
+		})
+		if err != nil {
+			t.Errorf("handler returned error: %v", err)
+		}
+		return result
+	}
+
+	if result := call(map[string]any{"code": "a", "priority": "urgent"}, nil); !result.IsError {
+		t.Error("Invalid priority should return an error result")
+	}
+
+	go call(map[string]any{"code": "block"}, nil)
+	<-started
+	waited := make(chan *mcp.CallToolResult)
+	go func() {
+		waited <- call(map[string]any{"code": "a", "priority": "batch"}, &mcp.Meta{ProgressToken: "token"})
+	}()
+	waitForQueued(t, queued, 1)
+
+	if result := call(map[string]any{"code": "b"}, nil); !result.IsError || resultText(result) == "output" {
+		t.Errorf("Call beyond the queue limit = %q, want an error result", resultText(result))
+	}
+
+	close(unblock)
+	if result := <-waited; result.IsError {
+		t.Errorf("Queued call failed: %q", resultText(result))
+	}
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+	// Queued at position 1, then started: the progress increases up to the total
+	want := []map[string]any{
+		{"progressToken": "token", "progress": 1, "total": 2, "message": "Queued at position 1"},
+		{"progressToken": "token", "progress": 2, "total": 2, "message": "Started"},
+	}
+	if !reflect.DeepEqual(sender.params, want) || slices.ContainsFunc(sender.methods, func(method string) bool { return method != "notifications/progress" }) {
+		t.Errorf("Notifications = %v %v, want progress notifications %v", sender.methods, sender.params, want)
+	}
+
+	if newExecutionQueue(config.LimitsConfig{}) != nil {
+		t.Error("newExecutionQueue() should return nil without a concurrency limit")
+	}
+}
+
+// waitForQueued waits until n calls wait in q.
+func waitForQueued(t *testing.T, q *executionQueue, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, waiting := q.queue.Stats(); waiting == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %d queued calls", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestExecutionQueue_Preemption(t *testing.T) {
+	queued := newExecutionQueue(config.LimitsConfig{MaxConcurrent: 1, PreemptAfter: 10 * time.Millisecond})
+
+	started := make(chan struct{}, 2)
+	var runs atomic.Int32
+	handler := queued.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if request.GetString("code", "") != "long" {
+			return mcp.NewToolResultText("quick"), nil
+		}
+		started <- struct{}{}
+		if runs.Add(1) == 1 {
+			<-ctx.Done()
+			return mcp.NewToolResultError("killed"), nil
+		}
+		return mcp.NewToolResultText("long"), nil
+	})
+	call := func(code, priority string) *mcp.CallToolResult {
+		result, err := handler(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "execute-python", Arguments: map[string]any{"code": code, "priority": priority}},
+		})
+		if err != nil {
+			t.Errorf("handler returned error: %v", err)
+		}
+		return result
+	}
+
+	batch := make(chan *mcp.CallToolResult)
+	go func() { batch <- call("long", "batch") }()
+	<-started
+	if result := call("quick", "interactive"); resultText(result) != "quick" {
+		t.Errorf("Interactive call = %q, want quick", resultText(result))
+	}
+	if result := <-batch; result.IsError || resultText(result) != "long" {
+		t.Errorf("Preempted batch call = %q, want the output of its second run", resultText(result))
+	}
+	if runs.Load() != 2 {
+		t.Errorf("Batch call ran %d times, want 2", runs.Load())
+	}
+}
@@ -0,0 +1,94 @@
+// Package server enforces the per-client execution quotas on execute tool calls
+// and reports the caller's usage as the quota://status resource.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/config"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+	"github.com/ylchen07/mcp-executor/internal/quota"
+	"github.com/ylchen07/mcp-executor/internal/tools"
+)
+
+// quotaStatusURI is the resource reporting the quota usage of the caller.
+const quotaStatusURI = "quota://status"
+
+// executionQuotas counts the execute-* tool calls of each client against its quotas.
+type executionQuotas struct {
+	tracker *quota.Tracker
+}
+
+// newExecutionQuotas builds the quota tracker, or returns nil when no quota is set.
+func newExecutionQuotas(cfg config.QuotaConfig) *executionQuotas {
+	limits := quota.Limits{
+		Window:        cfg.Window,
+		MaxExecutions: cfg.MaxExecutions,
+		MaxCPU:        time.Duration(cfg.MaxCPUSeconds) * time.Second,
+		MaxDownload:   int64(cfg.MaxDownloadMB) << 20,
+	}
+	if !limits.Enabled() {
+		return nil
+	}
+	logger.Debug("Enforcing per-client execution quotas: %+v", limits)
+	return &executionQuotas{tracker: quota.New(limits)}
+}
+
+// middleware rejects the execute calls of clients that used up a quota and
+// charges the CPU time and downloads of the others. Scheduled runs are not
+// counted; schedules are capped by their own settings.
+func (q *executionQuotas) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !isExecutionTool(request.Params.Name) || isScheduledRun(ctx) {
+			return next(ctx, request)
+		}
+
+		client := clientID(ctx)
+		end, err := q.tracker.Begin(client)
+		if err != nil {
+			logger.InfoContext(ctx, "Rejected %s call: %v", request.Params.Name, err)
+			return tools.ErrorResult(executor.ErrorPolicyViolation, fmt.Sprintf("Execution not started: %v", err)), nil
+		}
+		result, err := next(ctx, request)
+		var usage executor.Usage
+		if reported := executor.ReportedUsage(ctx); reported != nil {
+			usage = *reported
+		}
+		end(usage.CPUTime, usage.NetworkRx)
+		return result, err
+	}
+}
+
+// register adds the quota://status resource.
+func (q *executionQuotas) register(mcpServer *server.MCPServer) {
+	mcpServer.AddResource(
+		mcp.NewResource(
+			quotaStatusURI,
+			"Execution quota status",
+			mcp.WithResourceDescription("Executions, CPU time and downloads used by the caller in the current quota window, with the limits"),
+			mcp.WithMIMEType("application/json"),
+		),
+		q.readResource,
+	)
+}
+
+// readResource returns the quota usage of the caller as JSON.
+func (q *executionQuotas) readResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	data, err := json.MarshalIndent(q.tracker.Status(clientID(ctx)), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode quota status: %v", err)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: "application/json",
+			Text:     string(data),
+		},
+	}, nil
+}
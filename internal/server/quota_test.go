@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/config"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/quota"
+)
+
+func TestExecutionQuotas_Middleware(t *testing.T) {
+	quotas := newExecutionQuotas(config.QuotaConfig{Window: time.Hour, MaxExecutions: 5, MaxCPUSeconds: 3})
+	handler := quotas.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if usage := executor.ReportedUsage(ctx); usage != nil {
+			usage.CPUTime = 2 * time.Second
+			usage.NetworkRx = 1024
+		}
+		return mcp.NewToolResultText("output"), nil
+	})
+	call := func(ctx context.Context, tool string) *mcp.CallToolResult {
+		result, err := handler(executor.WithUsage(ctx, &executor.Usage{}), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: tool, Arguments: map[string]any{"code": "a"}},
+		})
+		if err != nil {
+			t.Errorf("handler returned error: %v", err)
+		}
+		return result
+	}
+
+	alice := withClientID(context.Background(), "key-alice")
+	for i := 0; i < 2; i++ {
+		if result := call(alice, "execute-python"); result.IsError {
+			t.Errorf("Call within the quota failed: %q", resultText(result))
+		}
+	}
+	if result := call(alice, "execute-python"); !result.IsError || resultText(result) == "output" {
+		t.Errorf("Call beyond the CPU quota = %q, want an error result", resultText(result))
+	}
+	if result := call(alice, "list-schedules"); result.IsError {
+		t.Errorf("Other tools should not be limited, got %q", resultText(result))
+	}
+	if result := call(context.Background(), "execute-python"); result.IsError {
+		t.Errorf("Other clients should have their own quota, got %q", resultText(result))
+	}
+
+	contents, err := quotas.readResource(alice, mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: quotaStatusURI}})
+	if err != nil {
+		t.Fatalf("readResource() returned error: %v", err)
+	}
+	var status quota.Status
+	if err := json.Unmarshal([]byte(contents[0].(mcp.TextResourceContents).Text), &status); err != nil {
+		t.Fatalf("Quota status is not JSON: %v", err)
+	}
+	if status.Client != "key-alice" || status.Executions != 2 || status.CPUSeconds != 4 || status.DownloadBytes != 2048 || status.MaxCPUSeconds != 3 {
+		t.Errorf("Quota status = %+v, want 2 executions using 4s of CPU", status)
+	}
+
+	if newExecutionQuotas(config.QuotaConfig{Window: time.Hour}) != nil {
+		t.Error("newExecutionQuotas() should return nil without limits")
+	}
+}
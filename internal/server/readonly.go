@@ -0,0 +1,87 @@
+// Package server provides the opt-in read-only variants of the execute tools,
+// advertised with readOnlyHint so clients can prefer them for introspection.
+package server
+
+import (
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+	"github.com/ylchen07/mcp-executor/internal/tools"
+)
+
+// readOnlySuffix ends the names and registry keys of the read-only tools,
+// e.g. execute-bash-readonly.
+const readOnlySuffix = "-readonly"
+
+// readOnlySubprocessLanguages lists the languages whose subprocess executors
+// can run code without write access.
+var readOnlySubprocessLanguages = []string{"python", "bash"}
+
+// readOnlyOmittedArguments are the arguments installing dependencies, saving a
+// snapshot or piping the output of another execution, which read-only tools do
+// not offer.
+var readOnlyOmittedArguments = []string{"modules", "packages", "dependency_file", "snapshot", tools.StdinFromArgument}
+
+// readOnlyTool is the read-only variant of an execute tool whose executor runs
+// code without write access.
+type readOnlyTool struct {
+	executionTool
+	docker bool
+}
+
+func (t readOnlyTool) CreateTool() mcp.Tool {
+	tool := t.executionTool.CreateTool()
+	tool.Name += readOnlySuffix
+	note := "READ-ONLY: for inspecting files and the system without changing them. "
+	if t.docker {
+		note += "The container filesystem and all mounts are read-only; only /tmp is writable and discarded afterwards."
+	} else {
+		note += "Writing files, changing the filesystem and starting other programs are refused."
+	}
+	tool.Description += "\n" + note
+	for _, name := range readOnlyOmittedArguments {
+		delete(tool.InputSchema.Properties, name)
+	}
+	tool.InputSchema.Required = slices.DeleteFunc(slices.Clone(tool.InputSchema.Required), func(name string) bool {
+		return slices.Contains(readOnlyOmittedArguments, name)
+	})
+	tool.Annotations.ReadOnlyHint = mcp.ToBoolPtr(true)
+	tool.Annotations.DestructiveHint = mcp.ToBoolPtr(false)
+	return tool
+}
+
+// newReadOnlyTools builds the read-only variants of the execute tools of the
+// languages in options.ReadOnlyTools, keyed by language plus readOnlySuffix.
+// Subprocess mode supports Python and Bash; Nix mode supports none.
+func newReadOnlyTools(executionMode string, options Options) map[string]executionTool {
+	if len(options.ReadOnlyTools) == 0 {
+		return nil
+	}
+	docker := executor.ContainerMode(executionMode)
+	if !docker && executionMode == "nix" {
+		logger.Info("Read-only tools are not available in nix mode")
+		return nil
+	}
+
+	// Read-only executions install nothing, and plain subprocess Python
+	// honors the read-only option
+	options.DetectDependencies = false
+	options.PythonInstaller = ""
+	executors := newExecutors(executionMode, options)
+	for language, exec := range executors {
+		executors[language] = executor.NewReadOnlyExecutor(exec)
+	}
+	modeTools := newModeTools(executionMode, options, executors)
+
+	readOnlyTools := make(map[string]executionTool)
+	for _, language := range options.ReadOnlyTools {
+		if !docker && !slices.Contains(readOnlySubprocessLanguages, language) {
+			logger.Info("Read-only %s tool is not available in %s mode", language, executionMode)
+			continue
+		}
+		readOnlyTools[language+readOnlySuffix] = readOnlyTool{executionTool: modeTools[language], docker: docker}
+	}
+	return readOnlyTools
+}
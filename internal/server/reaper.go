@@ -0,0 +1,45 @@
+// Package server runs the background reaper killing execution containers that
+// outlive limits.container_max_lifetime, e.g. those left behind by a crashed server.
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// maxReapInterval bounds how long an expired container may survive its lifetime.
+const maxReapInterval = time.Minute
+
+// reapTimeout bounds one pass of the reaper.
+const reapTimeout = 30 * time.Second
+
+// startContainerReaper kills execution containers older than maxLifetime in the
+// background, checking at most every maxReapInterval.
+func startContainerReaper(maxLifetime time.Duration) {
+	logger.Debug("Killing execution containers older than %s", maxLifetime)
+	go func() {
+		ticker := time.NewTicker(min(max(maxLifetime/2, time.Second), maxReapInterval))
+		defer ticker.Stop()
+		for now := range ticker.C {
+			reapContainers(maxLifetime, now)
+		}
+	}()
+}
+
+// reapContainers kills the expired execution containers and logs each one.
+func reapContainers(maxLifetime time.Duration, now time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), reapTimeout)
+	defer cancel()
+
+	reaped, err := executor.ReapContainers(ctx, maxLifetime, now)
+	for _, container := range reaped {
+		logger.Info("Killed execution container %s (%s, %s) created %s ago: exceeded limits.container_max_lifetime of %s",
+			container.Name, container.Language, container.Image, now.Sub(container.Created).Round(time.Second), maxLifetime)
+	}
+	if err != nil {
+		logger.Error("Failed to reap execution containers: %v", err)
+	}
+}
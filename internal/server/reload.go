@@ -0,0 +1,62 @@
+// Package server implements runtime reloading of tool toggles, Docker images and
+// policies, triggered by SIGHUP or the admin reload endpoint.
+package server
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// Reloader applies new settings to a running MCP server and disables its tools at
+// runtime, see DisableTools. The execution mode, history size, inline output limit,
+// auto-fix settings, prompts, quotas, the container reaper, the execution modes
+// sessions may select and the persistent containers of hybrid mode are fixed at
+// startup.
+type Reloader struct {
+	executionMode string
+	modes         *sessionModes
+	registry      *toolRegistry
+	environments  *environmentCatalog
+	instructions  *sandboxInstructions
+	results       *resultCache
+	containerPool *executor.ContainerPool
+
+	mu sync.Mutex
+}
+
+// Reload rebuilds the execute tools from opts and reports whether the set of
+// enabled tools changed (in which case clients receive tools/list_changed).
+func (r *Reloader) Reload(opts ...Option) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	options := newOptions(opts)
+	options.containerPool = r.containerPool
+	r.registry.setModeTools(newSessionModeTools(r.modes, options))
+	changed := r.registry.apply(newExecutionTools(r.executionMode, options), options.EnabledTools)
+	r.environments.set(options.Environments)
+	r.instructions.set(options)
+	r.results.setImages(options.Images)
+	logger.Info("Configuration reloaded (tool set changed: %t)", changed)
+	return changed
+}
+
+// reloadHandler serves POST requests that trigger reload.
+func reloadHandler(reload func() error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := reload(); err != nil {
+			logger.Error("Reload failed: %v", err)
+			http.Error(w, "reload failed: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
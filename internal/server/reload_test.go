@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestReloader_TogglesTools(t *testing.T) {
+	mcpServer, reloader := NewReloadableMCPServer("subprocess", WithEnabledTools([]string{"python"}))
+	if len(executeTools(mcpServer)) != 1 {
+		t.Fatalf("Expected 1 tool at startup, got %d", len(executeTools(mcpServer)))
+	}
+
+	if changed := reloader.Reload(WithEnabledTools([]string{"python", "go"})); !changed {
+		t.Error("Enabling a tool should report a changed tool set")
+	}
+	tools := executeTools(mcpServer)
+	if len(tools) != 2 || tools["execute-go"] == nil {
+		t.Errorf("Expected execute-python and execute-go after reload, got %d tools", len(tools))
+	}
+
+	if changed := reloader.Reload(WithEnabledTools([]string{"go", "python"})); changed {
+		t.Error("Reloading the same tool set should not report a change")
+	}
+
+	reloader.Reload(WithEnabledTools([]string{"go"}))
+	if tools := executeTools(mcpServer); len(tools) != 1 || tools["execute-python"] != nil {
+		t.Errorf("execute-python should be removed after reload, got %d tools", len(tools))
+	}
+}
+
+func TestReloader_UpdatesChangedTools(t *testing.T) {
+	mcpServer, reloader := NewReloadableMCPServer("docker")
+
+	if changed := reloader.Reload(WithOffline(true)); !changed {
+		t.Error("Going offline should report changed tool definitions")
+	}
+	for name, tool := range executeTools(mcpServer) {
+		if !strings.Contains(tool.Tool.Description, "OFFLINE") {
+			t.Errorf("%s description should note that the server is offline", name)
+		}
+	}
+
+	if changed := reloader.Reload(WithOffline(true)); changed {
+		t.Error("Reloading the same settings should not report a change")
+	}
+}
+
+func TestToolRegistry_HandlerUsesCurrentTool(t *testing.T) {
+	mcpServer, reloader := NewReloadableMCPServer("subprocess")
+	handler := mcpServer.GetTool("execute-python").Handler
+
+	reloader.registry.apply(map[string]executionTool{}, nil)
+	result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "execute-python"}})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if !result.IsError {
+		t.Error("A handler for a removed tool should return an error result")
+	}
+}
+
+func TestReloadEndpoint(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		reloadErr  error
+		wantStatus int
+		wantCalled bool
+	}{
+		{"post reloads", http.MethodPost, "/executor/admin/reload", nil, http.StatusNoContent, true},
+		{"get rejected", http.MethodGet, "/executor/admin/reload", nil, http.StatusMethodNotAllowed, false},
+		{"reload error", http.MethodPost, "/executor/admin/reload", errors.New("bad config"), http.StatusBadRequest, true},
+		{"other paths pass through", http.MethodPost, "/executor/mcp", nil, http.StatusTeapot, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			options := newTransportOptions([]TransportOption{
+				WithBasePath("/executor"),
+				WithListenAddress("127.0.0.1:8081"),
+				WithReloadEndpoint(func() error {
+					called = true
+					return tt.reloadErr
+				}),
+			})
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			})
+
+			recorder := httptest.NewRecorder()
+			options.handler(next).ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("Status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if called != tt.wantCalled {
+				t.Errorf("Reload called = %v, want %v", called, tt.wantCalled)
+			}
+		})
+	}
+}
+
+func TestAdminEndpoints_WithoutAuthTokens(t *testing.T) {
+	tests := []struct {
+		name       string
+		addr       string
+		wantStatus int
+	}{
+		{"loopback address", "127.0.0.1:8081", http.StatusOK},
+		{"localhost", "localhost:8081", http.StatusOK},
+		{"all interfaces", ":8081", http.StatusTeapot},
+		{"public address", "203.0.113.7:8081", http.StatusTeapot},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			killSwitch := NewKillSwitch()
+			options := newTransportOptions([]TransportOption{
+				WithListenAddress(tt.addr),
+				WithReloadEndpoint(func() error { return nil }),
+				WithKillSwitchEndpoints(killSwitch),
+			})
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			})
+
+			recorder := httptest.NewRecorder()
+			options.handler(next).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/admin/kill?disable=true", nil))
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("Status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if disabled := killSwitch.Status().Disabled; disabled != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("Disabled = %t after a kill request on %s", disabled, tt.addr)
+			}
+		})
+	}
+}
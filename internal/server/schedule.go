@@ -0,0 +1,277 @@
+// Package server provides the schedule tools, which run stored code through the
+// execute tools on cron expressions and expose the recent results as resources.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/config"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+	"github.com/ylchen07/mcp-executor/internal/schedule"
+	"github.com/ylchen07/mcp-executor/internal/tools"
+)
+
+// scheduledRunKey marks the context of scheduled runs, whose results must not
+// come from the result cache.
+type scheduledRunKey struct{}
+
+// scheduleTools registers and serves the schedule-* tools.
+type scheduleTools struct {
+	scheduler *schedule.Scheduler
+	guard     *privilegeGuard
+	mcpServer *server.MCPServer
+}
+
+// newScheduleTools builds the schedule tools, or returns nil when they are disabled.
+func newScheduleTools(cfg config.ScheduleConfig, guard *privilegeGuard) *scheduleTools {
+	if !cfg.Enabled {
+		return nil
+	}
+	logger.Debug("Enabling scheduled executions (up to %d schedules)", cfg.MaxSchedules)
+	s := &scheduleTools{guard: guard}
+	s.scheduler = schedule.New(s.run, cfg.MaxSchedules, cfg.KeepResults)
+	return s
+}
+
+// register adds the schedule tools and the schedule://{id} resource template to mcpServer.
+func (s *scheduleTools) register(mcpServer *server.MCPServer) {
+	s.mcpServer = mcpServer
+	mcpServer.AddTool(mcp.NewTool(
+		"schedule-execution",
+		mcp.WithDescription(`Run code repeatedly on a cron schedule while the server is running.
+The code runs through the execute tool of the language with the same policies, at batch priority.
+The latest results are kept as a schedule://{id} resource.`),
+		mcp.WithString(
+			"language",
+			mcp.Required(),
+			mcp.Description("Language of the code, selecting the execute tool that runs it."),
+			mcp.Enum(Languages...),
+		),
+		mcp.WithString(
+			"code",
+			mcp.Required(),
+			mcp.Description("Code or script to run on every scheduled time."),
+		),
+		mcp.WithString(
+			"cron",
+			mcp.Required(),
+			mcp.Description(`Five-field cron expression in server time (e.g. '*/15 * * * *', '0 9 * * mon-fri'),
+a descriptor such as '@hourly' or '@daily', or '@every 30m'.`),
+		),
+		mcp.WithString(
+			"name",
+			mcp.Description("Optional name shown when listing schedules."),
+		),
+		tools.WithEnv(`Environment variables passed to every run, as a JSON object of strings or a comma-separated
+list in KEY=VALUE format.`),
+		tools.WithDependencies(
+			"dependencies",
+			mcp.Description("Modules or packages to install for every run, as a JSON array or a comma-separated list, where the execute tool supports it."),
+		),
+	), s.handleSchedule)
+
+	mcpServer.AddTool(mcp.NewTool(
+		"list-schedules",
+		mcp.WithDescription("List the active schedules with their next run time and latest result."),
+	), s.handleList)
+
+	mcpServer.AddTool(mcp.NewTool(
+		"cancel-schedule",
+		mcp.WithDescription("Cancel a schedule. A run in progress is stopped."),
+		mcp.WithString(
+			"id",
+			mcp.Required(),
+			mcp.Description("ID of the schedule, as returned by schedule-execution or list-schedules."),
+		),
+	), s.handleCancel)
+
+	mcpServer.AddResourceTemplate(
+		mcp.NewResourceTemplate(
+			schedule.URIScheme+"{id}",
+			"Scheduled executions",
+			mcp.WithTemplateDescription("Definition and latest results of a schedule"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		s.readResource,
+	)
+}
+
+// handleSchedule validates the stored call, confirms privileged code with the
+// user now (scheduled runs have no client to ask) and starts the schedule.
+// Secrets cannot be asked for either and must come from the server's env.
+func (s *scheduleTools) handleSchedule(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	language := request.GetString("language", "")
+	code := request.GetString("code", "")
+	if code == "" {
+		return mcp.NewToolResultError("code is required"), nil
+	}
+	toolName := "execute-" + language
+	tool := s.mcpServer.GetTool(toolName)
+	if tool == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%s is not enabled on this server", toolName)), nil
+	}
+
+	arguments := map[string]any{"priority": "batch"}
+	if _, ok := tool.Tool.InputSchema.Properties["script"]; ok {
+		arguments["script"] = code
+	} else {
+		arguments["code"] = code
+	}
+	if env := request.GetArguments()["env"]; env != nil && env != "" {
+		if missing := missingSecrets(env); len(missing) > 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("scheduled executions cannot ask for secrets (%s); set them in execution.env", strings.Join(missing, ", "))), nil
+		}
+		arguments["env"] = env
+	}
+	if dependencies := request.GetArguments()["dependencies"]; dependencies != nil && dependencies != "" {
+		name := dependencyArgument(tool.Tool)
+		if name == "" {
+			return mcp.NewToolResultError(fmt.Sprintf("%s does not install dependencies in this execution mode", toolName)), nil
+		}
+		arguments[name] = dependencies
+	}
+
+	call := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: toolName, Arguments: arguments}}
+	// Scheduled runs have no session, so they run in the mode of the server
+	if reasons := s.guard.privilegedReasons(call, s.guard.subprocess); len(reasons) > 0 {
+		confirmed, err := s.guard.confirm(ctx, toolName, reasons)
+		switch {
+		case errors.Is(err, server.ErrElicitationNotSupported) || errors.Is(err, server.ErrNoActiveSession):
+			if err := s.guard.unconfirmed(ctx, toolName, reasons); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		case err != nil:
+			return mcp.NewToolResultError(fmt.Sprintf("confirmation failed: %v", err)), nil
+		case !confirmed:
+			return mcp.NewToolResultError("schedule declined by user: " + strings.Join(reasons, "; ")), nil
+		}
+	}
+
+	added, err := s.scheduler.Add(request.GetString("name", ""), request.GetString("cron", ""), toolName, arguments)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	logger.InfoContext(ctx, "Scheduled %s %s (%s)", toolName, added.ID, added.Cron)
+	s.publish(added)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.NewTextContent(fmt.Sprintf("Scheduled %s %s on %q, next run at %s", toolName, added.ID, added.Cron, added.NextRun.Format(time.RFC3339))),
+			mcp.NewResourceLink(added.URI(), "schedule "+added.ID, "Latest results of this schedule", "application/json"),
+		},
+	}, nil
+}
+
+// handleList returns the active schedules as JSON.
+func (s *scheduleTools) handleList(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	type summary struct {
+		ID         string           `json:"id"`
+		Name       string           `json:"name,omitempty"`
+		Tool       string           `json:"tool"`
+		Cron       string           `json:"cron"`
+		NextRun    time.Time        `json:"next_run"`
+		Runs       int              `json:"runs"`
+		LastResult *schedule.Result `json:"last_result,omitempty"`
+		URI        string           `json:"uri"`
+	}
+	summaries := []summary{}
+	for _, sched := range s.scheduler.List() {
+		item := summary{ID: sched.ID, Name: sched.Name, Tool: sched.Tool, Cron: sched.Cron, NextRun: sched.NextRun, Runs: sched.Runs, URI: sched.URI()}
+		if len(sched.Results) > 0 {
+			item.LastResult = &sched.Results[0]
+		}
+		summaries = append(summaries, item)
+	}
+	data, err := json.MarshalIndent(summaries, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to encode schedules: %v", err)), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// handleCancel stops a schedule and removes its resource.
+func (s *scheduleTools) handleCancel(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id := request.GetString("id", "")
+	if err := s.scheduler.Cancel(id); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	s.mcpServer.DeleteResources(schedule.URIScheme + id)
+	logger.InfoContext(ctx, "Cancelled schedule %s", id)
+	return mcp.NewToolResultText(fmt.Sprintf("Cancelled schedule %s", id)), nil
+}
+
+// run calls the execute tool of a schedule through the server, so scheduled runs
+// go through the same middleware as client calls.
+func (s *scheduleTools) run(ctx context.Context, tool string, arguments map[string]any) schedule.Result {
+	logger.Debug("Running scheduled %s call", tool)
+	result, err := CallTool(context.WithValue(ctx, scheduledRunKey{}, true), s.mcpServer, tool, arguments)
+	if err != nil {
+		return schedule.Result{Status: "error", Output: err.Error()}
+	}
+	if result.IsError {
+		return schedule.Result{Status: "error", Output: resultText(result)}
+	}
+	return schedule.Result{Status: "success", Output: resultText(result)}
+}
+
+// publish registers a schedule as a listed resource.
+func (s *scheduleTools) publish(sched schedule.Schedule) {
+	name := sched.Tool + " schedule " + sched.ID
+	if sched.Name != "" {
+		name = fmt.Sprintf("%s (%s)", sched.Name, name)
+	}
+	s.mcpServer.AddResource(
+		mcp.NewResource(
+			sched.URI(),
+			name,
+			mcp.WithResourceDescription(fmt.Sprintf("Runs on %q", sched.Cron)),
+			mcp.WithMIMEType("application/json"),
+		),
+		s.readResource,
+	)
+}
+
+// readResource returns a schedule with its latest results as JSON.
+func (s *scheduleTools) readResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	id := strings.TrimPrefix(request.Params.URI, schedule.URIScheme)
+	sched, ok := s.scheduler.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("schedule %q not found (it may have been cancelled)", id)
+	}
+
+	data, err := json.MarshalIndent(sched, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schedule %q: %v", id, err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: "application/json",
+			Text:     string(data),
+		},
+	}, nil
+}
+
+// dependencyArgument returns the dependency parameter of an execute tool, or ""
+// when it installs none.
+func dependencyArgument(tool mcp.Tool) string {
+	for _, name := range []string{"modules", "packages"} {
+		if _, ok := tool.InputSchema.Properties[name]; ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// isScheduledRun reports whether ctx belongs to a scheduled run.
+func isScheduledRun(ctx context.Context) bool {
+	return ctx.Value(scheduledRunKey{}) != nil
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ylchen07/mcp-executor/internal/config"
+)
+
+func TestScheduleTools(t *testing.T) {
+	if newScheduleTools(config.ScheduleConfig{}, nil) != nil {
+		t.Error("newScheduleTools() should return nil when schedules are disabled")
+	}
+
+	schedules := newScheduleTools(config.ScheduleConfig{Enabled: true}, &privilegeGuard{elicitor: &fakeElicitor{}, subprocess: true})
+	defer schedules.scheduler.Close()
+	mcpServer := NewMCPServer("subprocess", WithEnabledTools([]string{"bash", "python"}))
+	schedules.register(mcpServer)
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		arguments map[string]any
+		wantErr   string
+	}{
+		{"disabled tool", map[string]any{"language": "go", "code": "package main", "cron": "@daily"}, "not enabled"},
+		{"invalid cron", map[string]any{"language": "bash", "code": "date", "cron": "daily"}, "invalid cron expression"},
+		{"secret placeholder", map[string]any{"language": "bash", "code": "date", "cron": "@daily", "env": "TOKEN=?"}, "cannot ask for secrets"},
+		{"unsupported dependencies", map[string]any{"language": "python", "code": "print(1)", "cron": "@daily", "dependencies": "requests"}, "does not install dependencies"},
+		{"scheduled", map[string]any{"language": "bash", "code": "echo scheduled $GREETING", "cron": "@hourly", "env": "GREETING=hi"}, ""},
+	}
+	for _, tt := range tests {
+		result, err := CallTool(ctx, mcpServer, "schedule-execution", tt.arguments)
+		if err != nil {
+			t.Fatalf("%s: CallTool() returned error: %v", tt.name, err)
+		}
+		if text := resultText(result); result.IsError != (tt.wantErr != "") || !strings.Contains(text, tt.wantErr) {
+			t.Errorf("%s: result = %q (error %v), want error containing %q", tt.name, text, result.IsError, tt.wantErr)
+		}
+	}
+
+	list := schedules.scheduler.List()
+	if len(list) != 1 {
+		t.Fatalf("List() returned %d schedules, want 1", len(list))
+	}
+	sched := list[0]
+	if sched.Tool != "execute-bash" || sched.Arguments["script"] != "echo scheduled $GREETING" || sched.Arguments["priority"] != "batch" {
+		t.Errorf("Stored schedule = %+v, want a batch execute-bash call", sched)
+	}
+
+	result := schedules.run(ctx, sched.Tool, sched.Arguments)
+	if result.Status != "success" || !strings.Contains(result.Output, "scheduled hi") {
+		t.Errorf("run() = %+v, want the script output", result)
+	}
+
+	listed, _ := CallTool(ctx, mcpServer, "list-schedules", nil)
+	var summaries []struct{ ID string }
+	if err := json.Unmarshal([]byte(resultText(listed)), &summaries); err != nil || len(summaries) != 1 || summaries[0].ID != sched.ID {
+		t.Errorf("list-schedules = %q, want schedule %s", resultText(listed), sched.ID)
+	}
+
+	if result, _ := CallTool(ctx, mcpServer, "cancel-schedule", map[string]any{"id": sched.ID}); result.IsError {
+		t.Errorf("cancel-schedule failed: %q", resultText(result))
+	}
+	if result, _ := CallTool(ctx, mcpServer, "cancel-schedule", map[string]any{"id": sched.ID}); !result.IsError {
+		t.Error("cancel-schedule should fail for cancelled schedules")
+	}
+	if _, ok := schedules.scheduler.Get(sched.ID); ok {
+		t.Errorf("Schedule %s should be removed after cancel-schedule", sched.ID)
+	}
+}
@@ -3,145 +3,1047 @@
 package server
 
 import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/cache"
 	"github.com/ylchen07/mcp-executor/internal/config"
 	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/history"
 	"github.com/ylchen07/mcp-executor/internal/logger"
 	"github.com/ylchen07/mcp-executor/internal/prompts"
 	"github.com/ylchen07/mcp-executor/internal/tools"
 )
 
-func NewMCPServer(executionMode string) *server.MCPServer {
+// Options holds operator-level settings applied when building the MCP server.
+type Options struct {
+	// AllowedMountRoots lists host directories that Docker-mode tools may mount.
+	AllowedMountRoots []string
+
+	// AllowUnconfirmedPrivileges runs privileged calls that the client cannot
+	// confirm through elicitation instead of refusing them. Fixed at startup.
+	AllowUnconfirmedPrivileges bool
+
+	// EnabledTools limits the registered execute tools to these languages. Empty enables all.
+	EnabledTools []string
+
+	// SessionModes lists the execution modes sessions may select instead of
+	// the mode of the server. Hybrid mode keeps persistent containers for the
+	// whole server, so only hybrid servers offer it. Fixed at startup.
+	SessionModes []string
+
+	// RunAs is the host user running subprocess and Nix executions; nil runs
+	// them as the server user.
+	RunAs *executor.RunAs
+
+	// Priority is the scheduling priority of subprocess and Nix executions.
+	Priority executor.Priority
+
+	// TimeLocale is the default time zone and locale of executions and the
+	// libfaketime library of fake times.
+	TimeLocale executor.TimeLocale
+
+	// EnvPassthrough lists the server variables, names or prefixes ending in
+	// "*", passed to subprocess and Nix executions besides
+	// executor.DefaultEnvPassthrough.
+	EnvPassthrough []string
+
+	// ReadOnlyTools lists the languages whose execute tools also get a
+	// read-only variant, e.g. execute-bash-readonly.
+	ReadOnlyTools []string
+
+	// DisabledPrompts lists prompts that are not registered even when they support
+	// the execution mode.
+	DisabledPrompts []string
+
+	// HistorySize is the number of recent executions kept as resources.
+	HistorySize int
+
+	// Images overrides the Docker image of each language. Empty entries keep the defaults.
+	Images config.ImageConfig
+
+	// Limits caps the resources of each Docker-mode execution, the timeouts of all
+	// executions and how many of them run at once.
+	Limits config.LimitsConfig
+
+	// DefaultEnv is injected into every execution; per-call env values take precedence.
+	DefaultEnv map[string]string
+
+	// AutoFixAttempts enables the sampling-based repair loop for failed executions
+	// when greater than zero.
+	AutoFixAttempts int
+
+	// Cache enables reusing the results of identical execute calls when its TTL is positive.
+	Cache config.CacheConfig
+
+	// Schedule enables the tools running stored code on cron expressions.
+	Schedule config.ScheduleConfig
+
+	// PythonInstaller installs Python modules: "uv" uses uv in both modes, "venv"
+	// throwaway virtualenvs in subprocess mode, anything else pip in Docker mode
+	// and no installs in subprocess mode.
+	PythonInstaller string
+
+	// Environments are the named Docker-mode environments selected with the
+	// profile tool argument.
+	Environments map[string]config.EnvironmentConfig
+
+	// WorkspaceRoot holds the named workspaces shared by the executions of a
+	// session; empty uses a directory below the system temporary directory.
+	WorkspaceRoot string
+
+	// WorkspaceIdle is how long the workspaces of a session are kept without
+	// executions using them; zero uses executor.DefaultWorkspaceIdle. Fixed at
+	// startup.
+	WorkspaceIdle time.Duration
+
+	// GoCacheDir keeps the Go build and module caches of subprocess-mode
+	// executions; empty uses those of the user running the code.
+	GoCacheDir string
+
+	// Binaries overrides, per language, the binary running subprocess-mode code.
+	// Languages not listed use the first runtime found on the host.
+	Binaries map[string]string
+
+	// DetectDependencies installs the packages imported by Python and
+	// TypeScript code where dependencies can be installed, following ImportPolicy.
+	DetectDependencies bool
+	ImportPolicy       executor.ImportPolicy
+
+	// Registries points dependency installation at package mirrors.
+	Registries config.RegistriesConfig
+
+	// Offline refuses dependency installation, runs containers without a
+	// network and says so in the tool descriptions.
+	Offline bool
+
+	// Quotas limit the executions, CPU time and downloads of each client over
+	// a time window.
+	Quotas config.QuotaConfig
+
+	// Output selects the post-processors applied to the output of each execute
+	// tool and bounds the output returned in its results.
+	Output config.OutputConfig
+
+	// PersistentIdle is how long the persistent containers of hybrid mode
+	// live without executions; zero uses executor.DefaultPersistentIdle.
+	PersistentIdle time.Duration
+
+	// KillSwitch, when set, can cancel all running executions and refuse new
+	// ones. It is set at startup and ignored by Reload.
+	KillSwitch *KillSwitch
+
+	// SessionOwners, when set, tracks the client owning each session for the
+	// transports to refuse the requests of other clients to it. It is set at
+	// startup and ignored by Reload.
+	SessionOwners *SessionOwners
+
+	// containerPool holds the persistent containers of hybrid mode. It is
+	// created with the server and kept across reloads; without it, hybrid
+	// mode runs every call in a container of its own.
+	containerPool *executor.ContainerPool
+}
+
+// Option configures the MCP server built by NewMCPServer.
+type Option func(*Options)
+
+// WithAllowedMountRoots allows Docker-mode tools to mount paths below the given host directories.
+func WithAllowedMountRoots(roots []string) Option {
+	return func(o *Options) {
+		o.AllowedMountRoots = roots
+	}
+}
+
+// WithUnconfirmedPrivileges runs privileged calls of clients without
+// elicitation support when allow is set.
+func WithUnconfirmedPrivileges(allow bool) Option {
+	return func(o *Options) {
+		o.AllowUnconfirmedPrivileges = allow
+	}
+}
+
+// WithEnabledTools registers only the execute tools for the given languages.
+func WithEnabledTools(languages []string) Option {
+	return func(o *Options) {
+		o.EnabledTools = languages
+	}
+}
+
+// WithRunAs runs subprocess and Nix executions as runAs.
+func WithRunAs(runAs *executor.RunAs) Option {
+	return func(o *Options) {
+		o.RunAs = runAs
+	}
+}
+
+// WithPriority runs subprocess and Nix executions at priority.
+func WithPriority(priority executor.Priority) Option {
+	return func(o *Options) {
+		o.Priority = priority
+	}
+}
+
+// WithTimeLocale sets the default time zone and locale of executions and the
+// libfaketime library faking their clock.
+func WithTimeLocale(timeLocale executor.TimeLocale) Option {
+	return func(o *Options) {
+		o.TimeLocale = timeLocale
+	}
+}
+
+// WithEnvPassthrough passes the server variables matching patterns to
+// subprocess and Nix executions.
+func WithEnvPassthrough(patterns []string) Option {
+	return func(o *Options) {
+		o.EnvPassthrough = patterns
+	}
+}
+
+// WithReadOnlyTools registers read-only variants of the execute tools for the
+// given languages.
+func WithReadOnlyTools(languages []string) Option {
+	return func(o *Options) {
+		o.ReadOnlyTools = languages
+	}
+}
+
+// WithDisabledPrompts leaves the named prompts unregistered.
+func WithDisabledPrompts(names []string) Option {
+	return func(o *Options) {
+		o.DisabledPrompts = names
+	}
+}
+
+// WithHistorySize sets how many recent executions are kept as execution:// resources.
+func WithHistorySize(size int) Option {
+	return func(o *Options) {
+		o.HistorySize = size
+	}
+}
+
+// WithImages runs Docker-mode executions in the given images.
+func WithImages(images config.ImageConfig) Option {
+	return func(o *Options) {
+		o.Images = images
+	}
+}
+
+// WithResourceLimits caps the memory and CPUs of Docker-mode executions and sets
+// the execution timeouts and concurrency limit.
+func WithResourceLimits(limits config.LimitsConfig) Option {
+	return func(o *Options) {
+		o.Limits = limits
+	}
+}
+
+// WithDefaultEnv injects env into every execution in addition to the per-call env.
+func WithDefaultEnv(env map[string]string) Option {
+	return func(o *Options) {
+		o.DefaultEnv = env
+	}
+}
+
+// WithAutoFix lets failed executions be repaired by the client LLM via MCP sampling
+// and re-run up to attempts times.
+func WithAutoFix(attempts int) Option {
+	return func(o *Options) {
+		o.AutoFixAttempts = attempts
+	}
+}
+
+// WithCache returns cached output for identical repeat executions within cfg.TTL.
+func WithCache(cfg config.CacheConfig) Option {
+	return func(o *Options) {
+		o.Cache = cfg
+	}
+}
+
+// WithSchedules registers the schedule-execution, list-schedules and
+// cancel-schedule tools when cfg.Enabled is set.
+func WithSchedules(cfg config.ScheduleConfig) Option {
+	return func(o *Options) {
+		o.Schedule = cfg
+	}
+}
+
+// WithPythonInstaller selects how Python modules are installed ("pip", "uv" or "venv").
+func WithPythonInstaller(installer string) Option {
+	return func(o *Options) {
+		o.PythonInstaller = installer
+	}
+}
+
+// WithBinaries selects the binary running subprocess-mode code for each listed language.
+func WithBinaries(binaries map[string]string) Option {
+	return func(o *Options) {
+		o.Binaries = binaries
+	}
+}
+
+// WithDependencyDetection installs the packages imported by submitted code when
+// enabled, following policy.
+func WithDependencyDetection(enabled bool, policy executor.ImportPolicy) Option {
+	return func(o *Options) {
+		o.DetectDependencies = enabled
+		o.ImportPolicy = policy
+	}
+}
+
+// WithRegistries installs dependencies from the given package mirrors.
+func WithRegistries(registries config.RegistriesConfig) Option {
+	return func(o *Options) {
+		o.Registries = registries
+	}
+}
+
+// WithOffline runs the server without network access when offline is set.
+func WithOffline(offline bool) Option {
+	return func(o *Options) {
+		o.Offline = offline
+	}
+}
+
+// WithOutput post-processes the output of the execute tools and bounds the
+// output returned in their results.
+func WithOutput(output config.OutputConfig) Option {
+	return func(o *Options) {
+		o.Output = output
+	}
+}
+
+// WithKillSwitch runs execute calls under k.
+func WithKillSwitch(k *KillSwitch) Option {
+	return func(o *Options) {
+		o.KillSwitch = k
+	}
+}
+
+// WithSessionOwners tracks the client owning each session in o.
+func WithSessionOwners(o *SessionOwners) Option {
+	return func(opts *Options) {
+		opts.SessionOwners = o
+	}
+}
+
+// WithQuotas enforces per-client execution quotas.
+func WithQuotas(quotas config.QuotaConfig) Option {
+	return func(o *Options) {
+		o.Quotas = quotas
+	}
+}
+
+// WithPersistentIdle stops the persistent containers of hybrid mode after
+// running no execution for idle.
+func WithPersistentIdle(idle time.Duration) Option {
+	return func(o *Options) {
+		o.PersistentIdle = idle
+	}
+}
+
+// WithEnvironments sets the named environments of Docker-mode executions.
+func WithEnvironments(environments map[string]config.EnvironmentConfig) Option {
+	return func(o *Options) {
+		o.Environments = environments
+	}
+}
+
+// WithWorkspaceRoot keeps the named workspaces of sessions below root.
+func WithWorkspaceRoot(root string) Option {
+	return func(o *Options) {
+		o.WorkspaceRoot = root
+	}
+}
+
+// WithWorkspaceIdle removes the workspaces of sessions after no execution used
+// them for idle.
+func WithWorkspaceIdle(idle time.Duration) Option {
+	return func(o *Options) {
+		o.WorkspaceIdle = idle
+	}
+}
+
+// WithSessionModes lets sessions select the execution modes of modes.
+func WithSessionModes(modes []string) Option {
+	return func(o *Options) {
+		o.SessionModes = modes
+	}
+}
+
+// WithGoCacheDir keeps the Go caches of subprocess-mode executions in dir.
+func WithGoCacheDir(dir string) Option {
+	return func(o *Options) {
+		o.GoCacheDir = dir
+	}
+}
+
+func NewMCPServer(executionMode string, opts ...Option) *server.MCPServer {
+	mcpServer, _ := NewReloadableMCPServer(executionMode, opts...)
+	return mcpServer
+}
+
+// NewReloadableMCPServer builds the MCP server like NewMCPServer and also returns a
+// Reloader for applying new tool, image and policy settings at runtime.
+func NewReloadableMCPServer(executionMode string, opts ...Option) (*server.MCPServer, *Reloader) {
 	logger.Debug("Creating new MCP server with execution mode: %s", executionMode)
+	options := newOptions(opts)
+	if executionMode == "hybrid" {
+		options.containerPool = executor.NewContainerPool(options.PersistentIdle)
+	}
+
+	recorder := &historyRecorder{store: history.NewStore(options.HistorySize), active: newActiveExecutions(), maxInline: options.Output.MaxInline}
+	modes := newSessionModes(executionMode, options.SessionModes)
+	guard := &privilegeGuard{subprocess: !executor.ContainerMode(executionMode), modes: modes, allowUnconfirmed: options.AllowUnconfirmedPrivileges}
+	fixer := &autoFixer{maxAttempts: options.AutoFixAttempts, guard: guard}
+	forwarder := &logForwarder{}
+	hooks := &server.Hooks{}
+	hooks.AddOnUnregisterSession(removeWorkspaces(workspaceRoot(options)))
+	startWorkspaceCollector(workspaceRoot(options), cmp.Or(options.WorkspaceIdle, executor.DefaultWorkspaceIdle))
+	instructions := &sandboxInstructions{modes: modes, options: options}
+	hooks.AddAfterInitialize(instructions.afterInitialize)
+	if options.SessionOwners != nil {
+		options.SessionOwners.register(hooks)
+	}
+	serverOpts := []server.ServerOption{
+		server.WithHooks(hooks),
+		server.WithResourceCapabilities(false, true),
+		// Declared even when no tool is enabled yet, since reloads and the
+		// tool switch change the tools of running sessions
+		server.WithToolCapabilities(true),
+		server.WithElicitation(),
+		server.WithLogging(),
+		server.WithToolHandlerMiddleware(forwarder.middleware),
+		server.WithToolHandlerMiddleware(recorder.middleware),
+	}
+	// Inside the recorder, so killed executions are kept in the history, and
+	// before the guard, so refused calls ask for no confirmation
+	if options.KillSwitch != nil {
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(options.KillSwitch.middleware))
+	}
+	serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(guard.middleware))
+	// Inside the guard, which confirmed the original call, so the fixer runs no
+	// proposed code needing a confirmation the guard did not ask for
+	if fixer.maxAttempts > 0 {
+		logger.Debug("Enabling sampling-based auto-fix (up to %d attempts)", fixer.maxAttempts)
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(fixer.middleware))
+	}
+	// Before the cache, which does not answer the calls of sessions that restored a snapshot
+	var snapshots *snapshotTools
+	if executor.ContainerMode(executionMode) {
+		snapshots = newSnapshotTools(workspaceRoot(options))
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(snapshots.middleware))
+	}
+	// Innermost, so privileged calls are still confirmed before a cached result is returned
+	results := newResultCache(options.Cache)
+	if results != nil {
+		results.modes = modes
+		results.setImages(options.Images)
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(results.middleware))
+	}
+	// Quotas after the cache, so cached results are not counted, and before the
+	// queue, so exhausted clients do not wait for a slot
+	quotas := newExecutionQuotas(options.Quotas)
+	if quotas != nil {
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(quotas.middleware))
+	}
+	// Queue after the cache, so cached results are returned without waiting for a slot
+	queued := newExecutionQueue(options.Limits)
+	if queued != nil {
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(queued.middleware))
+	}
+
 	mcpServer := server.NewMCPServer(
 		config.ServerName,
 		config.ServerVersion,
+		serverOpts...,
 	)
+	forwarder.sender = mcpServer
+	recorder.mcpServer = mcpServer
+	recorder.registerHistoryResources()
+	recorder.active.register(mcpServer)
+	guard.elicitor = clientElicitor{mcpServer: mcpServer}
+	if queued != nil {
+		queued.sender = mcpServer
+	}
+	if quotas != nil {
+		quotas.register(mcpServer)
+	}
+	if fixer.maxAttempts > 0 {
+		fixer.sampler = mcpServer
+		mcpServer.EnableSampling()
+	}
 
-	switch executionMode {
-	case "docker":
-		logger.Debug("Using Docker executors with full tool capabilities")
-		pythonExecutor := executor.NewPythonExecutor()
-		bashExecutor := executor.NewBashExecutor()
-		typescriptExecutor := executor.NewTypeScriptExecutor()
-		goExecutor := executor.NewGoExecutor()
+	logger.Debug("Registering execution tools with MCP server")
+	registry := &toolRegistry{mcpServer: mcpServer, modes: modes}
+	registry.setModeTools(newSessionModeTools(modes, options))
+	registry.apply(newExecutionTools(executionMode, options), options.EnabledTools)
+	if modes.selectable() {
+		logger.Debug("Sessions may select the execution modes %v", modes.allowed)
+		modes.register(mcpServer, hooks)
+	}
+	instructions.registry = registry
+	if schedules := newScheduleTools(options.Schedule, guard); schedules != nil {
+		schedules.register(mcpServer)
+	}
+	if snapshots != nil {
+		snapshots.register(mcpServer, hooks)
+	}
+	environments := &environmentCatalog{}
+	if executor.ContainerMode(executionMode) {
+		environments.set(options.Environments)
+		environments.register(mcpServer)
+	}
+	if slices.ContainsFunc(modes.allowed, executor.ContainerMode) && options.Limits.ContainerMaxLifetime > 0 {
+		startContainerReaper(options.Limits.ContainerMaxLifetime)
+	}
+
+	// Register the prompts supporting the execution mode
+	registerPrompts(mcpServer, executionMode, options.DisabledPrompts, prompts.Dependencies{
+		LookupExecution: recorder.lookup,
+	})
+
+	logger.Debug("MCP server initialization complete")
+	return mcpServer, &Reloader{executionMode: executionMode, modes: modes, registry: registry, environments: environments, instructions: instructions, results: results, containerPool: options.containerPool}
+}
 
-		logger.Debug("Initializing Docker Python tool with module installation support")
-		pythonTool := tools.NewPythonTool(pythonExecutor)
+// newResultCache builds the result cache, or returns nil when caching is disabled.
+// A cache directory that cannot be created leaves the cache in memory.
+func newResultCache(cfg config.CacheConfig) *resultCache {
+	if cfg.TTL <= 0 {
+		return nil
+	}
+	logger.Debug("Caching execution results for %s", cfg.TTL)
+	c, err := cache.New(cfg.TTL, cfg.MaxEntries, cfg.Dir)
+	if err != nil {
+		logger.Error("Keeping cached results in memory only: %v", err)
+		c, _ = cache.New(cfg.TTL, cfg.MaxEntries, "")
+	}
+	return &resultCache{cache: c}
+}
 
-		logger.Debug("Initializing Docker Bash tool with package installation support")
-		bashTool := tools.NewBashTool(bashExecutor)
+func newOptions(opts []Option) Options {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
 
-		logger.Debug("Initializing Docker TypeScript tool with package installation support")
-		typescriptTool := tools.NewTypeScriptTool(typescriptExecutor)
+// NewExecutor builds the executor behind the execute tool of language, with the
+// same images, limits, timeouts and default environment as the MCP server.
+func NewExecutor(executionMode, language string, opts ...Option) (executor.Executor, error) {
+	exec, ok := newExecutors(executionMode, newOptions(opts))[language]
+	if !ok {
+		return nil, fmt.Errorf("unknown language %q: expected one of %s", language, strings.Join(Languages, ", "))
+	}
+	return exec, nil
+}
 
-		logger.Debug("Initializing Docker Go tool with package installation support")
-		goTool := tools.NewGoTool(goExecutor)
+// BuildImages builds the missing Docker images derived for languages, all of
+// them when empty: the language images with their preinstalled packages and
+// the images of the environments with packages. Executions build them on
+// first use otherwise.
+func BuildImages(ctx context.Context, languages []string, opts ...Option) ([]executor.BuiltImage, error) {
+	if len(languages) == 0 {
+		languages = Languages
+	}
+	executors := newDockerExecutors(newOptions(opts))
+	var images []executor.BuiltImage
+	for _, language := range languages {
+		built, err := executors[language].BuildImages(ctx)
+		images = append(images, built...)
+		if err != nil {
+			return images, err
+		}
+	}
+	return images, nil
+}
 
-		logger.Debug("Registering Docker tools with MCP server")
-		mcpServer.AddTool(pythonTool.CreateTool(), pythonTool.HandleExecution)
-		mcpServer.AddTool(bashTool.CreateTool(), bashTool.HandleExecution)
-		mcpServer.AddTool(typescriptTool.CreateTool(), typescriptTool.HandleExecution)
-		mcpServer.AddTool(goTool.CreateTool(), goTool.HandleExecution)
+// newExecutionTools builds the execute tools for the execution mode, their
+// read-only variants, the tests and benchmark tools and the web tools: browse
+// and render running Playwright with the Python executor and http running
+// curl with the Bash executor. They are keyed by language (plus
+// readOnlySuffix), TestsTool, BenchmarkTool or the selector of the web tool,
+// noting in their descriptions when the server runs offline.
+func newExecutionTools(executionMode string, options Options) map[string]executionTool {
+	executors := newExecutors(executionMode, options)
+	executionTools := newModeTools(executionMode, options, executors)
+	executionTools[TestsTool] = tools.NewTestsTool(executors)
+	executionTools[BenchmarkTool] = tools.NewBenchmarkTool(executors)
+	executionTools[BrowseTool] = tools.NewBrowseTool(executors["python"])
+	executionTools[RenderTool] = tools.NewRenderTool(executors["python"])
+	executionTools[HTTPTool] = tools.NewHTTPRequestTool(executors["bash"])
+	for key, tool := range newReadOnlyTools(executionMode, options) {
+		executionTools[key] = tool
+	}
+	if options.Offline {
+		for key, tool := range executionTools {
+			executionTools[key] = offlineTool{executionTool: tool, docker: executor.ContainerMode(executionMode)}
+		}
+	}
+	return executionTools
+}
 
-	case "subprocess":
-		logger.Debug("Using subprocess executors (no dependency installation)")
-		pythonExecutor := executor.NewSubprocessPythonExecutor()
-		bashExecutor := executor.NewSubprocessBashExecutor()
-		typescriptExecutor := executor.NewSubprocessTypeScriptExecutor()
-		goExecutor := executor.NewSubprocessGoExecutor()
+// newModeTools builds the execute tools of the execution mode running code
+// with executors, keyed by language.
+func newModeTools(executionMode string, options Options, executors map[string]executor.Executor) map[string]executionTool {
+	if executor.ContainerMode(executionMode) {
+		logger.Debug("Initializing Docker tools with dependency installation support")
+		return map[string]executionTool{
+			"python":     tools.NewPythonTool(executors["python"]),
+			"bash":       tools.NewBashTool(executors["bash"]),
+			"typescript": tools.NewTypeScriptTool(executors["typescript"]),
+			"go":         tools.NewGoTool(executors["go"]),
+		}
+	}
 
-		logger.Debug("Initializing subprocess Python tool (no module installation)")
-		pythonTool := tools.NewSubprocessPythonTool(pythonExecutor)
+	if executionMode == "nix" {
+		logger.Debug("Initializing Nix tools with nix-shell dependency support")
+		return map[string]executionTool{
+			"python":     tools.NewSubprocessPythonToolWithModules(executors["python"]),
+			"bash":       tools.NewSubprocessBashToolWithPackages(executors["bash"]),
+			"typescript": tools.NewSubprocessTypeScriptToolWithPackages(executors["typescript"]),
+			"go":         tools.NewSubprocessGoToolWithPackages(executors["go"]),
+		}
+	}
 
-		logger.Debug("Initializing subprocess Bash tool (no package installation)")
-		bashTool := tools.NewSubprocessBashTool(bashExecutor)
+	logger.Debug("Initializing subprocess tools (no dependency installation)")
+	var python executionTool = tools.NewSubprocessPythonTool(executors["python"])
+	if options.PythonInstaller == "uv" || options.PythonInstaller == "venv" {
+		python = tools.NewSubprocessPythonToolWithModules(executors["python"])
+	}
+	return map[string]executionTool{
+		"python":     python,
+		"bash":       tools.NewSubprocessBashTool(executors["bash"]),
+		"typescript": tools.NewSubprocessTypeScriptTool(executors["typescript"]),
+		"go":         tools.NewSubprocessGoTool(executors["go"]),
+	}
+}
 
-		logger.Debug("Initializing subprocess TypeScript tool (no package installation)")
-		typescriptTool := tools.NewSubprocessTypeScriptTool(typescriptExecutor)
+// newExecutors builds the wrapped executors for the execution mode, keyed by
+// language, with the output post-processors of their tools.
+func newExecutors(executionMode string, options Options) map[string]executor.Executor {
+	executors := newModeExecutors(executionMode, options)
+	for language, exec := range executors {
+		executors[language] = executor.NewOutputExecutor(exec, options.Output.ProcessorsFor(language))
+	}
+	return executors
+}
 
-		logger.Debug("Initializing subprocess Go tool (no package installation)")
-		goTool := tools.NewSubprocessGoTool(goExecutor)
+// newModeExecutors builds the wrapped executors of the execution mode, keyed by language.
+func newModeExecutors(executionMode string, options Options) map[string]executor.Executor {
+	switch executionMode {
+	case "docker", "hybrid":
+		logger.Debug("Using Docker executors with full tool capabilities")
+		docker := newDockerExecutors(options)
+		// Hybrid mode runs the calls it can in persistent containers
+		persistent := func(language string) executor.Executor {
+			if options.containerPool == nil {
+				return docker[language]
+			}
+			return executor.NewPersistentExecutor(docker[language], options.containerPool)
+		}
+		return detectDependencies(map[string]executor.Executor{
+			"python":     wrapExecutor(persistent("python"), executionMode, options),
+			"bash":       wrapExecutor(persistent("bash"), executionMode, options),
+			"typescript": wrapExecutor(persistent("typescript"), executionMode, options),
+			"go":         wrapExecutor(persistent("go"), executionMode, options),
+		}, options, "python", "typescript")
 
-		logger.Debug("Registering subprocess tools with MCP server")
-		mcpServer.AddTool(pythonTool.CreateTool(), pythonTool.HandleExecution)
-		mcpServer.AddTool(bashTool.CreateTool(), bashTool.HandleExecution)
-		mcpServer.AddTool(typescriptTool.CreateTool(), typescriptTool.HandleExecution)
-		mcpServer.AddTool(goTool.CreateTool(), goTool.HandleExecution)
+	case "nix":
+		logger.Debug("Using Nix executors with nix-shell dependencies")
+		return map[string]executor.Executor{
+			"python":     wrapExecutor(executor.NewNixPythonExecutor(), executionMode, options),
+			"bash":       wrapExecutor(executor.NewNixBashExecutor(), executionMode, options),
+			"typescript": wrapExecutor(executor.NewNixTypeScriptExecutor(), executionMode, options),
+			"go":         wrapExecutor(executor.NewNixGoExecutor(), executionMode, options),
+		}
+
+	case "subprocess":
+		logger.Debug("Using subprocess executors (no dependency installation)")
+		return newSubprocessExecutors(options)
 
 	default:
 		logger.Debug("Unknown execution mode '%s', defaulting to subprocess", executionMode)
-		pythonExecutor := executor.NewSubprocessPythonExecutor()
-		bashExecutor := executor.NewSubprocessBashExecutor()
-		typescriptExecutor := executor.NewSubprocessTypeScriptExecutor()
-		goExecutor := executor.NewSubprocessGoExecutor()
+		return newSubprocessExecutors(options)
+	}
+}
+
+// newDockerExecutors builds the Docker executors of the languages with the
+// operator's images, commands, limits and environments.
+func newDockerExecutors(options Options) map[string]*executor.DockerExecutor {
+	if len(options.AllowedMountRoots) > 0 {
+		logger.Debug("Allowing host mounts below: %v", options.AllowedMountRoots)
+	}
+	host := executor.DetectDockerHost(context.Background())
+	if host.InContainer {
+		daemon := host.Socket + host.Remote
+		if daemon == "" {
+			daemon = "no mounted socket or DOCKER_HOST"
+		}
+		logger.Info("Running in a container, Docker daemon: %s", daemon)
+		if host.VolumesErr != nil && len(options.AllowedMountRoots) > 0 {
+			logger.Info("Passing mount sources to the Docker daemon unchanged: %v", host.VolumesErr)
+		}
+	}
+	dockerOpts := []executor.DockerOption{
+		executor.WithAllowedMountRoots(options.AllowedMountRoots),
+		executor.WithDockerHost(host),
+		executor.WithResourceLimits(options.Limits.Memory, options.Limits.CPUs),
+		executor.WithDiskLimit(options.Limits.MaxDiskMB),
+		executor.WithAPTMirror(options.Registries.APTMirror),
+		executor.WithAPTCache(options.Registries.APTCacheVolume),
+		executor.WithGoCache(options.Registries.GoCacheVolume),
+	}
+	// The commands of the operator replace those selected by opts, such as
+	// the Python installer, and the APT mirror rewrites the sources before them
+	languageOpts := func(language, image string, opts ...executor.DockerOption) []executor.DockerOption {
+		commands := options.Images.Commands[language]
+		opts = append(opts, executor.WithCommands(commands.Install, commands.Execute))
+		if browser, ok := options.Images.Browser[language]; ok {
+			opts = append(opts, executor.WithBrowserImage(browser))
+		}
+		return append(append(opts, dockerOpts...),
+			executor.WithImage(image),
+			executor.WithRuntimeImages(options.Images.Runtimes[language]),
+			executor.WithEnvironments(environmentsOf(options.Environments, language)),
+			executor.WithPreinstalledPackages(options.Images.Preinstall[language]),
+		)
+	}
+	return map[string]*executor.DockerExecutor{
+		"python":     executor.NewPythonExecutor(languageOpts("python", options.Images.Python, executor.WithPythonInstaller(options.PythonInstaller))...),
+		"bash":       executor.NewBashExecutor(languageOpts("bash", options.Images.Bash)...),
+		"typescript": executor.NewTypeScriptExecutor(languageOpts("typescript", options.Images.TypeScript)...),
+		"go":         executor.NewGoExecutor(languageOpts("go", options.Images.Go)...),
+	}
+}
 
-		pythonTool := tools.NewSubprocessPythonTool(pythonExecutor)
-		bashTool := tools.NewSubprocessBashTool(bashExecutor)
-		typescriptTool := tools.NewSubprocessTypeScriptTool(typescriptExecutor)
-		goTool := tools.NewSubprocessGoTool(goExecutor)
+// newSubprocessExecutors builds the host executors keyed by language.
+func newSubprocessExecutors(options Options) map[string]executor.Executor {
+	binary := func(language string) executor.SubprocessOption {
+		return executor.WithBinary(options.Binaries[language])
+	}
+	var python executor.Executor = executor.NewSubprocessPythonExecutor(binary("python"))
+	var installing []string // Languages whose executors install dependencies
+	switch options.PythonInstaller {
+	case "uv":
+		logger.Debug("Running subprocess Python through uv with module support")
+		python = executor.NewSubprocessUVPythonExecutor(binary("python"))
+		installing = append(installing, "python")
+	case "venv":
+		logger.Debug("Running subprocess Python in throwaway virtualenvs with module support")
+		python = executor.NewSubprocessVenvPythonExecutor(binary("python"))
+		installing = append(installing, "python")
+	}
+	return detectDependencies(map[string]executor.Executor{
+		"python":     wrapExecutor(python, "subprocess", options),
+		"bash":       wrapExecutor(executor.NewSubprocessBashExecutor(binary("bash")), "subprocess", options),
+		"typescript": wrapExecutor(executor.NewSubprocessTypeScriptExecutor(binary("typescript")), "subprocess", options),
+		"go":         wrapExecutor(executor.NewSubprocessGoExecutor(binary("go"), executor.WithGoCacheDir(options.GoCacheDir)), "subprocess", options),
+	}, options, installing...)
+}
 
-		mcpServer.AddTool(pythonTool.CreateTool(), pythonTool.HandleExecution)
-		mcpServer.AddTool(bashTool.CreateTool(), bashTool.HandleExecution)
-		mcpServer.AddTool(typescriptTool.CreateTool(), typescriptTool.HandleExecution)
-		mcpServer.AddTool(goTool.CreateTool(), goTool.HandleExecution)
+// detectDependencies installs the packages imported by the code of the given
+// languages when dependency detection is enabled and the server is online. Nix
+// mode is left out, since its dependencies are nixpkgs attributes rather than
+// PyPI or npm packages.
+func detectDependencies(executors map[string]executor.Executor, options Options, languages ...string) map[string]executor.Executor {
+	if !options.DetectDependencies || options.Offline {
+		return executors
+	}
+	for _, language := range languages {
+		executors[language] = executor.NewImportDetector(executors[language], language, options.ImportPolicy)
 	}
+	return executors
+}
 
-	// Register prompts based on execution mode
-	registerPrompts(mcpServer, executionMode)
+// wrapExecutor applies the operator's code limits, the offline restrictions,
+// the disk quotas, the sandbox variables of the execution mode, named
+// workspaces, artifacts directories next to them, the timeout policy, the
+// default environment, including the variables selecting package mirrors,
+// the time zone, locale and fake time and deterministic executions to exec,
+// and runs host executions as the configured user at the configured
+// priority, with the passed-through server variables.
+func wrapExecutor(exec executor.Executor, executionMode string, options Options) executor.Executor {
+	exec = executor.NewValidatingExecutor(exec, options.Limits.MaxCodeSize)
+	if options.Offline {
+		exec = executor.NewOfflineExecutor(exec)
+	}
+	exec = executor.NewDiskQuotaExecutor(exec, executor.DiskQuota{
+		ExecutionMB: options.Limits.MaxDiskMB,
+		SessionMB:   options.Limits.MaxWorkspaceMB,
+	})
+	exec = executor.NewSandboxEnvExecutor(exec, executionMode)
+	exec = executor.NewWorkspaceExecutor(exec, workspaceRoot(options))
+	exec = executor.NewArtifactsExecutor(exec, filepath.Join(workspaceRoot(options), "artifacts"))
+	exec = executor.NewTimeoutExecutor(exec, executor.TimeoutPolicy{
+		Default: options.Limits.Timeout,
+		Max:     options.Limits.MaxTimeout,
+		Install: options.Limits.InstallTimeout,
+	})
+	exec = executor.NewDefaultEnvExecutor(exec, defaultEnv(options))
+	exec = executor.NewTimeLocaleExecutor(exec, options.TimeLocale)
+	exec = executor.NewDeterministicExecutor(exec, options.TimeLocale.FakeTimeLibrary != "")
+	if executor.ContainerMode(executionMode) {
+		return exec
+	}
+	exec = executor.NewEnvPassthroughExecutor(exec, options.EnvPassthrough)
+	exec = executor.NewPriorityExecutor(exec, options.Priority)
+	return executor.NewRunAsExecutor(exec, options.RunAs)
+}
 
-	logger.Debug("MCP server initialization complete")
-	return mcpServer
+// defaultEnv returns the variables selecting the package mirrors overridden by
+// the operator's default environment.
+func defaultEnv(options Options) map[string]string {
+	env := executor.Registries{
+		PyPIIndexURL:       options.Registries.PyPIIndexURL,
+		PyPIExtraIndexURLs: options.Registries.PyPIExtraIndexURLs,
+		NPMRegistry:        options.Registries.NPMRegistry,
+		GoProxy:            options.Registries.GoProxy,
+	}.Env()
+	for key, value := range options.DefaultEnv {
+		env[key] = value
+	}
+	return env
+}
+
+// workspaceRoot returns the directory holding the named workspaces.
+func workspaceRoot(options Options) string {
+	if options.WorkspaceRoot != "" {
+		return options.WorkspaceRoot
+	}
+	return executor.DefaultWorkspaceRoot()
+}
+
+// TransportOptions holds settings for the network (SSE and HTTP) transports.
+type TransportOptions struct {
+	// AuthTokens lists accepted bearer tokens / API keys. Empty disables authentication.
+	AuthTokens []string
+
+	// TLSCertFile and TLSKeyFile enable HTTPS when both are set.
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSClientCAFile, when set, requires clients to present a certificate signed by this CA bundle.
+	TLSClientCAFile string
+
+	// Addr overrides the listen address (e.g. ":9000" or "127.0.0.1:8080").
+	Addr string
+
+	// CORSOrigins lists browser origins allowed to call the server ("*" for any).
+	CORSOrigins []string
+	// BasePath prefixes all endpoints, e.g. "/executor" behind a reverse proxy.
+	BasePath string
+
+	// Reload, when set, is served as POST <BasePath>/admin/reload.
+	Reload func() error
+
+	// KillSwitch, when set, is served below <BasePath>/admin.
+	KillSwitch *KillSwitch
+
+	// Tools, when set, serves its tool switch below <BasePath>/admin.
+	Tools *Reloader
+
+	// SessionOwners, when set, refuses requests naming a session of another
+	// client.
+	SessionOwners *SessionOwners
+
+	// DebugAddr, when set, serves net/http/pprof and expvar on a separate address.
+	DebugAddr string
+}
+
+// TransportOption configures RunSSE and RunHTTP.
+type TransportOption func(*TransportOptions)
+
+// WithAuthTokens requires clients to present one of the given tokens.
+func WithAuthTokens(tokens []string) TransportOption {
+	return func(o *TransportOptions) {
+		o.AuthTokens = tokens
+	}
+}
+
+// WithTLS serves the transport over HTTPS using the given certificate and key files.
+func WithTLS(certFile, keyFile string) TransportOption {
+	return func(o *TransportOptions) {
+		o.TLSCertFile = certFile
+		o.TLSKeyFile = keyFile
+	}
+}
+
+// WithTLSClientCA requires client certificates signed by the CA bundle in caFile.
+func WithTLSClientCA(caFile string) TransportOption {
+	return func(o *TransportOptions) {
+		o.TLSClientCAFile = caFile
+	}
+}
+
+// WithListenAddress serves the transport on addr instead of its default port.
+func WithListenAddress(addr string) TransportOption {
+	return func(o *TransportOptions) {
+		o.Addr = addr
+	}
+}
+
+// WithCORSOrigins allows browser-based clients from the given origins.
+func WithCORSOrigins(origins []string) TransportOption {
+	return func(o *TransportOptions) {
+		o.CORSOrigins = origins
+	}
+}
+
+// WithBasePath serves all endpoints below the given URL path prefix.
+func WithBasePath(basePath string) TransportOption {
+	return func(o *TransportOptions) {
+		o.BasePath = normalizeBasePath(basePath)
+	}
+}
+
+// WithReloadEndpoint serves reload as the admin reload endpoint.
+func WithReloadEndpoint(reload func() error) TransportOption {
+	return func(o *TransportOptions) {
+		o.Reload = reload
+	}
 }
 
-func RunStdio(mcpServer *server.MCPServer) error {
-	logger.Debug("Starting stdio server")
-	return server.ServeStdio(mcpServer)
+// WithKillSwitchEndpoints serves the kill switch endpoints of k.
+func WithKillSwitchEndpoints(k *KillSwitch) TransportOption {
+	return func(o *TransportOptions) {
+		o.KillSwitch = k
+	}
+}
+
+// WithToolEndpoints serves the endpoints disabling and enabling the execute
+// tools of r.
+func WithToolEndpoints(r *Reloader) TransportOption {
+	return func(o *TransportOptions) {
+		o.Tools = r
+	}
+}
+
+// WithSessionIsolation refuses requests naming a session that o records as
+// owned by another client.
+func WithSessionIsolation(o *SessionOwners) TransportOption {
+	return func(opts *TransportOptions) {
+		opts.SessionOwners = o
+	}
+}
+
+// WithDebugAddress serves the pprof and expvar endpoints on addr.
+func WithDebugAddress(addr string) TransportOption {
+	return func(o *TransportOptions) {
+		o.DebugAddr = addr
+	}
+}
+
+// handler adds the admin endpoints to next and wraps it with the CORS,
+// authentication and session isolation middleware. The admin endpoints kill
+// executions and change the tools of every client, so without auth tokens they
+// are only served on a loopback listen address, like the debug endpoints.
+func (o TransportOptions) handler(next http.Handler) http.Handler {
+	if o.SessionOwners != nil {
+		next = o.SessionOwners.middleware(next)
+	}
+	admin := o.Reload != nil || o.KillSwitch != nil || o.Tools != nil
+	if admin && len(o.AuthTokens) == 0 && !loopbackAddr(o.Addr) {
+		logger.Info("Admin endpoints disabled: they require auth tokens unless the server listens on a loopback address")
+		admin = false
+	}
+	if admin {
+		mux := http.NewServeMux()
+		if o.Reload != nil {
+			mux.Handle(o.BasePath+"/admin/reload", reloadHandler(o.Reload))
+		}
+		if o.KillSwitch != nil {
+			o.KillSwitch.registerEndpoints(mux, o.BasePath+"/admin")
+		}
+		if o.Tools != nil {
+			o.Tools.registerEndpoints(mux, o.BasePath+"/admin")
+		}
+		mux.Handle("/", next)
+		next = mux
+	}
+	return corsMiddleware(o.CORSOrigins, authMiddleware(o.AuthTokens, next))
+}
+
+// listenAddr returns the configured listen address or fallback.
+func (o TransportOptions) listenAddr(fallback string) string {
+	if o.Addr != "" {
+		return o.Addr
+	}
+	return fallback
 }
 
-func RunSSE(mcpServer *server.MCPServer) error {
+// hostURL returns the local http URL of a listen address such as ":8080".
+func hostURL(addr string) string {
+	if strings.HasPrefix(addr, ":") {
+		return "http://localhost" + addr
+	}
+	return "http://" + addr
+}
+
+func newTransportOptions(opts []TransportOption) TransportOptions {
+	var options TransportOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if len(options.AuthTokens) == 0 {
+		logger.Info("Authentication is disabled: anyone who can reach this server can execute code")
+	}
+	return options
+}
+
+func RunSSE(mcpServer *server.MCPServer, opts ...TransportOption) error {
 	logger.Debug("Setting up SSE server")
-	sseServer := server.NewSSEServer(mcpServer, server.WithBaseURL(config.SSEHost))
-	logger.Verbose("Starting SSE server on localhost:8080")
-	return sseServer.Start(config.SSEPort)
+	options := newTransportOptions(opts)
+	addr := options.listenAddr(config.SSEPort)
+	sseServer := server.NewSSEServer(
+		mcpServer,
+		server.WithBaseURL(options.baseURL(hostURL(addr))),
+		server.WithStaticBasePath(options.BasePath),
+	)
+	httpServer := &http.Server{
+		Addr:    addr,
+		Handler: options.handler(sseServer),
+	}
+	if err := startDebugServer(options); err != nil {
+		return err
+	}
+	logger.Verbose("Starting SSE server on %s%s", options.baseURL(hostURL(addr)), options.BasePath)
+	return listenAndServe(httpServer, options)
 }
 
-func RunHTTP(mcpServer *server.MCPServer) error {
+func RunHTTP(mcpServer *server.MCPServer, opts ...TransportOption) error {
 	logger.Debug("Setting up HTTP server")
-	httpServer := server.NewStreamableHTTPServer(mcpServer)
-	logger.Verbose("Starting HTTP server on localhost:8081")
-	return httpServer.Start(config.HTTPPort)
+	options := newTransportOptions(opts)
+	streamableServer := server.NewStreamableHTTPServer(mcpServer)
+	mux := http.NewServeMux()
+	mux.Handle(options.BasePath+"/mcp", endSessionsOnDelete(mcpServer, streamableServer))
+	addr := options.listenAddr(config.HTTPPort)
+	httpServer := &http.Server{
+		Addr:    addr,
+		Handler: options.handler(mux),
+	}
+	if err := startDebugServer(options); err != nil {
+		return err
+	}
+	logger.Verbose("Starting HTTP server on %s%s/mcp", options.baseURL(hostURL(addr)), options.BasePath)
+	return listenAndServe(httpServer, options)
 }
 
-// registerPrompts registers prompts to the MCP server based on execution mode.
-// Some prompts are only available in specific execution modes:
-// - subprocess: system-check (host system information)
-// - docker: (future prompts that require container isolation)
-// - all modes: (future universal prompts)
-func registerPrompts(mcpServer *server.MCPServer, executionMode string) {
+// registerPrompts registers the prompts of the prompt registry that support the
+// execution mode, except the disabled ones. Each prompt declares its modes when
+// it registers itself (see prompts.Register).
+func registerPrompts(mcpServer *server.MCPServer, executionMode string, disabled []string, deps prompts.Dependencies) {
 	logger.Debug("Registering prompts for execution mode: %s", executionMode)
 
-	switch executionMode {
-	case "subprocess", "": // Empty string is default/unknown mode (defaults to subprocess)
-		logger.Debug("Registering subprocess-mode prompts")
-
-		// System check - only works in subprocess mode for host system info
-		systemCheckPrompt := prompts.NewSystemCheckPrompt()
-		mcpServer.AddPrompt(
-			systemCheckPrompt.CreatePrompt(),
-			systemCheckPrompt.HandlePrompt,
-		)
-		logger.Debug("Registered system-check prompt")
-
-	case "docker":
-		logger.Debug("No prompts registered for Docker mode (container-only context)")
-		// Future: Add Docker-specific prompts here
-		// Example: prompts for exploring container capabilities, installed packages, etc.
+	for _, prompt := range prompts.ForMode(executionMode, disabled, deps) {
+		mcpPrompt := prompt.CreatePrompt()
+		mcpServer.AddPrompt(mcpPrompt, prompt.HandlePrompt)
+		logger.Debug("Registered %s prompt", mcpPrompt.Name)
 	}
-
-	// Future: Register prompts that work in ALL execution modes
-	// Example:
-	// logger.Debug("Registering universal prompts")
-	// helpPrompt := prompts.NewHelpPrompt()
-	// mcpServer.AddPrompt(helpPrompt.CreatePrompt(), helpPrompt.HandlePrompt())
 }
@@ -1,11 +1,29 @@
 package server
 
 import (
+	"context"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/config"
 	"github.com/ylchen07/mcp-executor/internal/executor"
 )
 
+// executeTools returns the execute-* tools of mcpServer, leaving out the tools
+// every server registers, such as list-active-executions.
+func executeTools(mcpServer *server.MCPServer) map[string]*server.ServerTool {
+	tools := mcpServer.ListTools()
+	for name := range tools {
+		if !strings.HasPrefix(name, "execute-") {
+			delete(tools, name)
+		}
+	}
+	return tools
+}
+
 func TestNewMCPServer_DockerMode(t *testing.T) {
 	mcpServer := NewMCPServer("docker")
 
@@ -14,7 +32,7 @@ func TestNewMCPServer_DockerMode(t *testing.T) {
 	}
 
 	// Verify tools are registered (indication of proper initialization)
-	tools := mcpServer.ListTools()
+	tools := executeTools(mcpServer)
 	if len(tools) == 0 {
 		t.Error("Server should have tools registered")
 	}
@@ -28,12 +46,33 @@ func TestNewMCPServer_SubprocessMode(t *testing.T) {
 	}
 
 	// Verify tools are registered
-	tools := mcpServer.ListTools()
+	tools := executeTools(mcpServer)
 	if len(tools) == 0 {
 		t.Error("Server should have tools registered")
 	}
 }
 
+func TestNewMCPServer_NixMode(t *testing.T) {
+	mcpServer := NewMCPServer("nix")
+
+	// Every tool takes its dependencies from nix-shell
+	for name, parameter := range map[string]string{
+		"execute-python":     "modules",
+		"execute-bash":       "packages",
+		"execute-typescript": "packages",
+		"execute-go":         "packages",
+	} {
+		tool := mcpServer.GetTool(name)
+		if tool == nil {
+			t.Errorf("GetTool(%q) should not return nil", name)
+			continue
+		}
+		if _, ok := tool.Tool.InputSchema.Properties[parameter]; !ok {
+			t.Errorf("%s should have a %q parameter in nix mode", name, parameter)
+		}
+	}
+}
+
 func TestNewMCPServer_DefaultMode(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -62,7 +101,7 @@ func TestNewMCPServer_DefaultMode(t *testing.T) {
 			}
 
 			// Should have tools registered even with unknown mode
-			tools := mcpServer.ListTools()
+			tools := executeTools(mcpServer)
 			if len(tools) == 0 {
 				t.Error("Server should have tools registered even with unknown mode")
 			}
@@ -78,22 +117,22 @@ func TestNewMCPServer_ToolRegistration(t *testing.T) {
 	}
 
 	// Verify tools are registered
-	tools := mcpServer.ListTools()
+	tools := executeTools(mcpServer)
 	if len(tools) == 0 {
 		t.Fatal("No tools registered")
 	}
 
 	// Check for expected tools
-	expectedTools := []string{"execute-python", "execute-bash", "execute-typescript", "execute-go"}
+	expectedTools := []string{"execute-python", "execute-bash", "execute-typescript", "execute-go", "execute-tests", "execute-benchmark"}
 	for _, expectedTool := range expectedTools {
 		if _, found := tools[expectedTool]; !found {
 			t.Errorf("Expected tool %q not found in registered tools", expectedTool)
 		}
 	}
 
-	// Should have exactly 4 tools
-	if len(tools) != 4 {
-		t.Errorf("Expected 4 tools, got %d", len(tools))
+	// Should have exactly 6 tools
+	if len(tools) != 6 {
+		t.Errorf("Expected 6 tools, got %d", len(tools))
 	}
 }
 
@@ -127,9 +166,9 @@ func TestNewMCPServer_ExecutorSelection(t *testing.T) {
 			}
 
 			// Verify tools are present
-			tools := mcpServer.ListTools()
-			if len(tools) != 4 {
-				t.Errorf("Expected 4 tools for %s mode, got %d", tt.executionMode, len(tools))
+			tools := executeTools(mcpServer)
+			if len(tools) != 6 {
+				t.Errorf("Expected 6 tools for %s mode, got %d", tt.executionMode, len(tools))
 			}
 		})
 	}
@@ -150,11 +189,11 @@ func TestNewMCPServer_MultipleInstances(t *testing.T) {
 	}
 
 	// Both should have tools registered
-	if len(server1.ListTools()) != 4 {
-		t.Error("Server 1 should have 4 tools")
+	if len(executeTools(server1)) != 6 {
+		t.Error("Server 1 should have 6 tools")
 	}
-	if len(server2.ListTools()) != 4 {
-		t.Error("Server 2 should have 4 tools")
+	if len(executeTools(server2)) != 6 {
+		t.Error("Server 2 should have 6 tools")
 	}
 }
 
@@ -177,7 +216,7 @@ func TestNewMCPServer_ExecutorTypes(t *testing.T) {
 			}
 
 			// Verify tools were registered
-			tools := mcpServer.ListTools()
+			tools := executeTools(mcpServer)
 			if len(tools) == 0 {
 				t.Errorf("NewMCPServer(%q) should have tools registered", mode)
 			}
@@ -192,7 +231,7 @@ func TestNewMCPServer_ToolDetails(t *testing.T) {
 		t.Fatal("NewMCPServer() returned nil")
 	}
 
-	tools := mcpServer.ListTools()
+	tools := executeTools(mcpServer)
 
 	// Check each tool has a handler
 	for toolName, tool := range tools {
@@ -288,3 +327,196 @@ func TestNewMCPServer_NoNilReturns(t *testing.T) {
 		})
 	}
 }
+
+func TestNewMCPServer_EnabledTools(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      string
+		enabled   []string
+		wantTools []string
+	}{
+		{
+			name:      "all tools when none selected",
+			mode:      "subprocess",
+			enabled:   nil,
+			wantTools: []string{"execute-python", "execute-bash", "execute-typescript", "execute-go", "execute-tests", "execute-benchmark"},
+		},
+		{
+			name:      "subset in subprocess mode",
+			mode:      "subprocess",
+			enabled:   []string{"python", "go"},
+			wantTools: []string{"execute-python", "execute-go"},
+		},
+		{
+			name:      "subset in docker mode",
+			mode:      "docker",
+			enabled:   []string{"bash"},
+			wantTools: []string{"execute-bash"},
+		},
+		{
+			name:      "tests tool only",
+			mode:      "subprocess",
+			enabled:   []string{"tests"},
+			wantTools: []string{"execute-tests"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mcpServer := NewMCPServer(tt.mode, WithEnabledTools(tt.enabled))
+
+			tools := executeTools(mcpServer)
+			if len(tools) != len(tt.wantTools) {
+				t.Errorf("Expected %d tools, got %d", len(tt.wantTools), len(tools))
+			}
+			for _, name := range tt.wantTools {
+				if _, found := tools[name]; !found {
+					t.Errorf("Expected tool %q to be registered", name)
+				}
+			}
+		})
+	}
+}
+
+func TestNewMCPServer_DefaultEnv(t *testing.T) {
+	mcpServer := NewMCPServer("subprocess", WithDefaultEnv(map[string]string{
+		"MCP_DEFAULT_GREETING": "hello",
+		"MCP_DEFAULT_TARGET":   "default",
+	}))
+
+	handler := mcpServer.GetTool("execute-bash").Handler
+	result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name: "execute-bash",
+		Arguments: map[string]any{
+			"script": "echo $MCP_DEFAULT_GREETING $MCP_DEFAULT_TARGET",
+			"env":    "MCP_DEFAULT_TARGET=world",
+		},
+	}})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if output := strings.TrimSpace(resultText(result)); output != "hello world" {
+		t.Errorf("Output = %q, want default env with per-call override %q", output, "hello world")
+	}
+}
+
+func TestNewMCPServer_Registries(t *testing.T) {
+	mcpServer := NewMCPServer("subprocess",
+		WithRegistries(config.RegistriesConfig{NPMRegistry: "https://npm.internal", GoProxy: "https://goproxy.internal"}),
+		WithDefaultEnv(map[string]string{"GOPROXY": "https://operator.internal"}),
+	)
+
+	handler := mcpServer.GetTool("execute-bash").Handler
+	result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name:      "execute-bash",
+		Arguments: map[string]any{"script": "echo $NPM_CONFIG_REGISTRY $GOPROXY"},
+	}})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if output := strings.TrimSpace(resultText(result)); output != "https://npm.internal https://operator.internal" {
+		t.Errorf("Output = %q, want the registry variables overridden by the default env", output)
+	}
+}
+
+func TestNewMCPServer_Output(t *testing.T) {
+	mcpServer := NewMCPServer("subprocess", WithOutput(config.OutputConfig{
+		Processors: []string{"pretty_json"},
+		Tools:      map[string][]string{"bash": {"strip_ansi", "limit_repeats"}},
+	}))
+
+	handler := mcpServer.GetTool("execute-bash").Handler
+	result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name:      "execute-bash",
+		Arguments: map[string]any{"script": `for i in 1 2 3 4; do printf '\033[33mwarning\033[0m\n'; done`},
+	}})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+
+	if output := resultText(result); output != "warning\nwarning\n[previous line repeated 2 more times]\n" {
+		t.Errorf("Output = %q, want the bash processors applied", output)
+	}
+}
+
+func TestNewMCPServer_ReadOnlyTools(t *testing.T) {
+	mcpServer := NewMCPServer("subprocess", WithReadOnlyTools([]string{"bash", "go"}), WithEnabledTools([]string{"bash", "python"}))
+
+	tool := mcpServer.GetTool("execute-bash-readonly")
+	if tool == nil {
+		t.Fatal("execute-bash-readonly should be registered")
+	}
+	if hint := tool.Tool.Annotations.ReadOnlyHint; hint == nil || !*hint {
+		t.Error("execute-bash-readonly should be advertised with readOnlyHint")
+	}
+	if hint := mcpServer.GetTool("execute-bash").Tool.Annotations.ReadOnlyHint; hint != nil && *hint {
+		t.Error("execute-bash should not be advertised with readOnlyHint")
+	}
+	for _, name := range []string{"execute-go-readonly", "execute-python-readonly"} {
+		if mcpServer.GetTool(name) != nil {
+			t.Errorf("%s should not be registered", name)
+		}
+	}
+
+	result, err := tool.Handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name:      "execute-bash-readonly",
+		Arguments: map[string]any{"script": "echo changed > " + filepath.Join(t.TempDir(), "file")},
+	}})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("Writing from the read-only tool = %q, want an error", resultText(result))
+	}
+}
+
+func TestNewExecutor(t *testing.T) {
+	exec, err := NewExecutor("subprocess", "bash", WithDefaultEnv(map[string]string{"GREETING": "hello"}))
+	if err != nil {
+		t.Fatalf("NewExecutor() returned error: %v", err)
+	}
+	output, err := exec.Execute(context.Background(), "echo $GREETING", nil, nil)
+	if err != nil || strings.TrimSpace(output) != "hello" {
+		t.Errorf("Execute() = %q, %v, want the default env applied", output, err)
+	}
+
+	if _, err := NewExecutor("docker", "ruby"); err == nil {
+		t.Error("NewExecutor() should reject unknown languages")
+	}
+}
+
+func TestParseToolList(t *testing.T) {
+	tests := []struct {
+		name      string
+		selectors []string
+		want      []string
+		wantErr   bool
+	}{
+		{name: "empty", selectors: nil, want: nil},
+		{name: "language names", selectors: []string{"python", "bash"}, want: []string{"python", "bash"}},
+		{name: "tool names and case", selectors: []string{"Execute-Go", " typescript "}, want: []string{"go", "typescript"}},
+		{name: "duplicates collapsed", selectors: []string{"python", "execute-python"}, want: []string{"python"}},
+		{name: "tests and benchmark tools", selectors: []string{"execute-tests", "bash", "benchmark"}, want: []string{"tests", "bash", "benchmark"}},
+		{name: "web tools", selectors: []string{"browse-web", "Browse", "render-page", "http"}, want: []string{"browse", "render", "http"}},
+		{name: "unknown tool", selectors: []string{"perl"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseToolList(tt.selectors)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseToolList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseToolList() = %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("ParseToolList()[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
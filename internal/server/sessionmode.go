@@ -0,0 +1,158 @@
+// Package server lets MCP sessions select their execution mode among the modes
+// allowed by the operator, when initializing or with the set-execution-mode
+// tool, instead of all clients using the mode of the server.
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+	"github.com/ylchen07/mcp-executor/internal/tools"
+)
+
+// executionModeArgument is the field of the SandboxCapability of the client
+// capabilities selecting the execution mode of the session when initializing.
+const executionModeArgument = "execution_mode"
+
+// sessionModes tracks the execution modes selected by the sessions.
+type sessionModes struct {
+	defaultMode string   // Mode of the server, used by sessions selecting none
+	allowed     []string // Modes sessions may select, including defaultMode
+
+	mu    sync.RWMutex
+	modes map[string]string // Selected mode by session ID
+}
+
+// newSessionModes allows sessions to select modes besides defaultMode.
+func newSessionModes(defaultMode string, modes []string) *sessionModes {
+	allowed := []string{defaultMode}
+	for _, mode := range modes {
+		if mode == "hybrid" && defaultMode != "hybrid" {
+			logger.Info("Sessions cannot select hybrid mode: only hybrid servers keep persistent containers")
+			continue
+		}
+		if !slices.Contains(allowed, mode) {
+			allowed = append(allowed, mode)
+		}
+	}
+	return &sessionModes{defaultMode: defaultMode, allowed: allowed, modes: make(map[string]string)}
+}
+
+// selectable reports whether sessions may select another mode than the
+// default one.
+func (s *sessionModes) selectable() bool {
+	return s != nil && len(s.allowed) > 1
+}
+
+// others returns the modes sessions may select besides the default one.
+func (s *sessionModes) others() []string {
+	if s == nil {
+		return nil
+	}
+	return s.allowed[1:]
+}
+
+// mode returns the execution mode of the session of ctx.
+func (s *sessionModes) mode(ctx context.Context) string {
+	if s == nil {
+		return ""
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if mode, ok := s.modes[sessionID(ctx)]; ok {
+		return mode
+	}
+	return s.defaultMode
+}
+
+// set selects the execution mode of the session of ctx.
+func (s *sessionModes) set(ctx context.Context, mode string) error {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if !slices.Contains(s.allowed, mode) {
+		return fmt.Errorf("execution mode %q is not allowed by the server: expected one of %s", mode, strings.Join(s.allowed, ", "))
+	}
+	session := sessionID(ctx)
+	if session == "" {
+		return fmt.Errorf("selecting an execution mode needs an MCP session")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if mode == s.defaultMode {
+		delete(s.modes, session)
+	} else {
+		s.modes[session] = mode
+	}
+	logger.Info("Session %s runs in %s mode", session, mode)
+	return nil
+}
+
+// beforeInitialize selects the mode requested in the client capabilities, as
+// {"experimental": {"mcp-executor": {"execution_mode": "docker"}}}. Modes the
+// server does not allow are ignored, leaving the default mode.
+func (s *sessionModes) beforeInitialize(ctx context.Context, _ any, request *mcp.InitializeRequest) {
+	capability, _ := request.Params.Capabilities.Experimental[SandboxCapability].(map[string]any)
+	mode, _ := capability[executionModeArgument].(string)
+	if mode == "" {
+		return
+	}
+	if err := s.set(ctx, mode); err != nil {
+		logger.Info("Ignoring the execution mode requested by the client: %v", err)
+	}
+}
+
+// removeSession forgets the mode of each session that ends.
+func (s *sessionModes) removeSession(_ context.Context, session server.ClientSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.modes, session.SessionID())
+}
+
+// register adds the set-execution-mode tool to mcpServer and the hooks
+// selecting and forgetting the modes of the sessions to hooks.
+func (s *sessionModes) register(mcpServer *server.MCPServer, hooks *server.Hooks) {
+	hooks.AddBeforeInitialize(s.beforeInitialize)
+	hooks.AddOnUnregisterSession(s.removeSession)
+
+	var descriptions []string
+	for _, mode := range s.allowed {
+		descriptions = append(descriptions, fmt.Sprintf("- %s: %s", mode, modeDescriptions[mode]))
+	}
+	mcpServer.AddTool(mcp.NewTool(
+		"set-execution-mode",
+		mcp.WithDescription(fmt.Sprintf(`Select where the execute tools run the code of this session. The server runs in %s mode unless a session selects another one; the selection lasts until the session ends.
+%s
+Dependency installation differs between modes: call it before executing code that needs it.`, s.defaultMode, strings.Join(descriptions, "\n"))),
+		mcp.WithString("mode",
+			mcp.Required(),
+			mcp.Description("Execution mode of the session"),
+			mcp.Enum(s.allowed...),
+		),
+		mcp.WithIdempotentHintAnnotation(true),
+	), s.handleSet)
+}
+
+// handleSet selects the execution mode of the calling session.
+func (s *sessionModes) handleSet(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if err := s.set(ctx, request.GetString("mode", "")); err != nil {
+		return tools.ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+	}
+	mode := s.mode(ctx)
+	return mcp.NewToolResultText(fmt.Sprintf("Execution mode of this session: %s. %s", mode, modeDescriptions[mode])), nil
+}
+
+// newSessionModeTools builds the execute tools of the modes sessions may
+// select besides the default one, keyed by mode.
+func newSessionModeTools(modes *sessionModes, options Options) map[string]map[string]executionTool {
+	modeTools := make(map[string]map[string]executionTool)
+	for _, mode := range modes.others() {
+		modeTools[mode] = newExecutionTools(mode, options)
+	}
+	return modeTools
+}
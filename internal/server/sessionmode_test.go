@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestSessionModes_Set(t *testing.T) {
+	modes := newSessionModes("docker", []string{"subprocess", "docker"})
+	mcpServer := server.NewMCPServer("test", "1.0")
+	session := mcpServer.WithContext(context.Background(), server.NewInProcessSession("session-1", nil))
+	other := mcpServer.WithContext(context.Background(), server.NewInProcessSession("session-2", nil))
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		mode     string
+		wantErr  bool
+		wantMode string
+	}{
+		{"allowed mode", session, "Subprocess", false, "subprocess"},
+		{"mode not allowed", session, "nix", true, "subprocess"},
+		{"default mode", session, "docker", false, "docker"},
+		{"without session", context.Background(), "subprocess", true, "docker"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := modes.set(tt.ctx, tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("set(%q) error = %v, want error %t", tt.mode, err, tt.wantErr)
+			}
+			if got := modes.mode(tt.ctx); got != tt.wantMode {
+				t.Errorf("mode() = %q, want %q", got, tt.wantMode)
+			}
+			if got := modes.mode(other); got != "docker" {
+				t.Errorf("mode() of another session = %q, want the default mode", got)
+			}
+		})
+	}
+}
+
+func TestSessionModes_Initialize(t *testing.T) {
+	mcpServer := NewMCPServer("docker", WithSessionModes([]string{"subprocess"}), WithEnabledTools([]string{"bash"}))
+	if mcpServer.GetTool("set-execution-mode") == nil {
+		t.Fatal("set-execution-mode should be registered when sessions may select a mode")
+	}
+	ctx := mcpServer.WithContext(context.Background(), server.NewInProcessSession("session-1", nil))
+
+	message := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{"experimental":{"mcp-executor":{"execution_mode":"subprocess"}}},"clientInfo":{"name":"test","version":"1.0"}}}`
+	response, ok := mcpServer.HandleMessage(ctx, json.RawMessage(message)).(mcp.JSONRPCResponse)
+	if !ok {
+		t.Fatal("initialize did not return a result")
+	}
+	result := response.Result.(mcp.InitializeResult)
+	if !strings.Contains(result.Instructions, "Execution mode: subprocess.") {
+		t.Errorf("Instructions should describe the mode of the session, got:\n%s", result.Instructions)
+	}
+	sandbox := result.Capabilities.Experimental[SandboxCapability].(sandboxDescription)
+	if !reflect.DeepEqual(sandbox.Modes, []string{"docker", "subprocess"}) || len(sandbox.Installs) != 0 {
+		t.Errorf("Capability = %+v, want the selectable modes and no installing tool", sandbox)
+	}
+
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "execute-bash", Arguments: map[string]any{"script": "echo $MCP_EXECUTOR_MODE"}}}
+	toolResult, err := mcpServer.GetTool("execute-bash").Handler(ctx, request)
+	if err != nil {
+		t.Fatalf("execute-bash returned error: %v", err)
+	}
+	if text := resultText(toolResult); toolResult.IsError || !strings.Contains(text, "subprocess") {
+		t.Errorf("execute-bash should run in the mode of the session, got %q", text)
+	}
+}
+
+func TestSessionModes_NotSelectable(t *testing.T) {
+	mcpServer := NewMCPServer("subprocess")
+	if mcpServer.GetTool("set-execution-mode") != nil {
+		t.Error("set-execution-mode should only be registered when sessions may select a mode")
+	}
+}
@@ -0,0 +1,73 @@
+// Package server binds the MCP sessions of the SSE and streamable HTTP
+// transports to the client that opened them, so that a client presenting
+// another token cannot use the session, and with it the workspaces, execution
+// mode and executions of another client.
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// SessionOwners tracks the client owning each MCP session.
+type SessionOwners struct {
+	mu     sync.RWMutex
+	owners map[string]string // Client ID by session ID
+}
+
+// NewSessionOwners creates an empty set of session owners.
+func NewSessionOwners() *SessionOwners {
+	return &SessionOwners{owners: make(map[string]string)}
+}
+
+// owner returns the client owning session, if the session is registered.
+func (o *SessionOwners) owner(session string) (string, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	client, ok := o.owners[session]
+	return client, ok
+}
+
+// claim makes the client opening session its owner.
+func (o *SessionOwners) claim(ctx context.Context, session server.ClientSession) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.owners[session.SessionID()] = clientID(ctx)
+}
+
+// release forgets the owner of each session that ends.
+func (o *SessionOwners) release(_ context.Context, session server.ClientSession) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.owners, session.SessionID())
+}
+
+// register adds the hooks tracking the owners of the sessions to hooks.
+func (o *SessionOwners) register(hooks *server.Hooks) {
+	hooks.AddOnRegisterSession(o.claim)
+	hooks.AddOnUnregisterSession(o.release)
+}
+
+// middleware answers requests naming a session of another client, in the
+// Mcp-Session-Id header of the streamable HTTP transport or the sessionId
+// query parameter of the SSE transport, as if the session did not exist.
+func (o *SessionOwners) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := r.Header.Get(server.HeaderKeySessionID)
+		if session == "" {
+			session = r.URL.Query().Get("sessionId")
+		}
+		if session != "" {
+			if owner, ok := o.owner(session); ok && owner != clientID(r.Context()) {
+				logger.Info("Refused request of client %s to session %s of client %s", clientID(r.Context()), session, owner)
+				http.Error(w, "session not found", http.StatusNotFound)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestSessionOwners_Middleware(t *testing.T) {
+	owners := NewSessionOwners()
+	owners.claim(withClientID(context.Background(), tokenClientID("token-a")), server.NewInProcessSession("session-a", nil))
+	owners.claim(context.Background(), server.NewInProcessSession("session-anonymous", nil))
+	owners.claim(withClientID(context.Background(), tokenClientID("token-a")), server.NewInProcessSession("session-ended", nil))
+	owners.release(context.Background(), server.NewInProcessSession("session-ended", nil))
+	handler := authMiddleware([]string{"token-a", "token-b"}, owners.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})))
+
+	tests := []struct {
+		name       string
+		token      string
+		header     string
+		query      string
+		wantStatus int
+	}{
+		{"owner by header", "token-a", "session-a", "", http.StatusAccepted},
+		{"owner by query", "token-a", "", "session-a", http.StatusAccepted},
+		{"other client by header", "token-b", "session-a", "", http.StatusNotFound},
+		{"other client by query", "token-b", "", "session-a", http.StatusNotFound},
+		{"session of no client", "token-b", "session-anonymous", "", http.StatusNotFound},
+		{"unknown session", "token-b", "session-unknown", "", http.StatusAccepted},
+		{"ended session", "token-b", "session-ended", "", http.StatusAccepted},
+		{"no session", "token-b", "", "", http.StatusAccepted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/mcp?sessionId="+tt.query, nil)
+			request.Header.Set("Authorization", "Bearer "+tt.token)
+			if tt.header != "" {
+				request.Header.Set(server.HeaderKeySessionID, tt.header)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("Status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Package server provides the restore-snapshot tool of Docker mode, with which
+// a session runs its code in a snapshot saved by an earlier execution instead
+// of installing the same packages and downloading the same data again.
+package server
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// snapshotTools serves the restore-snapshot tool and runs the executions of
+// the sessions that restored a snapshot in its image. The archived workspaces
+// of snapshots are restored below the workspace root.
+type snapshotTools struct {
+	inspect func(ctx context.Context, client, name string) (executor.Snapshot, error)
+	root    string
+
+	mu     sync.RWMutex
+	images map[string]map[string]string // Restored snapshot image by session ID and language
+}
+
+func newSnapshotTools(root string) *snapshotTools {
+	return &snapshotTools{inspect: executor.InspectSnapshot, root: root, images: make(map[string]map[string]string)}
+}
+
+// register adds the restore-snapshot tool to mcpServer and the hook forgetting
+// the snapshots restored by each session that ends to hooks.
+func (s *snapshotTools) register(mcpServer *server.MCPServer, hooks *server.Hooks) {
+	hooks.AddOnUnregisterSession(s.removeSession)
+	mcpServer.AddTool(mcp.NewTool(
+		"restore-snapshot",
+		mcp.WithDescription(`Restore a snapshot saved by this client with the snapshot argument of an execute tool: the execute tool of its language runs
+the later code of this session in the saved container, with the packages installed and the files written by the execution that saved it,
+until the session ends. The files of the named workspace of that execution are restored into the workspace of the same name. Calls selecting a profile or runtime version keep running in the image of that environment or version.`),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the snapshot."),
+		),
+	), s.handleRestore)
+}
+
+// handleRestore restores a snapshot in the calling session.
+func (s *snapshotTools) handleRestore(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	snapshot, err := s.restore(ctx, strings.TrimSpace(request.GetString("name", "")))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	text := fmt.Sprintf("Restored snapshot %s: the %s code of this session runs in image %s.", snapshot.Name, snapshot.Language, snapshot.Image)
+	if snapshot.Workspace != "" {
+		text += fmt.Sprintf(" Its files are in workspace %s.", snapshot.Workspace)
+	}
+	return mcp.NewToolResultText(text), nil
+}
+
+// restore runs the executions of the snapshot's language of the session of ctx
+// in the image of the snapshot name saved by its client and restores its
+// workspace.
+func (s *snapshotTools) restore(ctx context.Context, name string) (executor.Snapshot, error) {
+	id := sessionID(ctx)
+	if id == "" {
+		return executor.Snapshot{}, fmt.Errorf("restoring a snapshot needs an MCP session")
+	}
+	snapshot, err := s.inspect(ctx, clientID(ctx), name)
+	if err != nil {
+		return executor.Snapshot{}, err
+	}
+	if snapshot.Workspace != "" {
+		if err := s.restoreWorkspace(id, clientID(ctx), snapshot); err != nil {
+			return executor.Snapshot{}, err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.images[id] == nil {
+		s.images[id] = make(map[string]string)
+	}
+	s.images[id][snapshot.Language] = snapshot.Image
+	logger.Info("Session %s restored snapshot %s", id, name)
+	return snapshot, nil
+}
+
+// restoreWorkspace extracts the archived workspace of snapshot, saved by client,
+// into the workspace of the same name of the session id.
+func (s *snapshotTools) restoreWorkspace(id, client string, snapshot executor.Snapshot) error {
+	if err := executor.CheckWorkspaceName(snapshot.Workspace); err != nil {
+		return fmt.Errorf("snapshot %s: %v", snapshot.Name, err)
+	}
+	dir := filepath.Join(executor.SessionWorkspaces(s.root, id), snapshot.Workspace)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create workspace %s: %v", snapshot.Workspace, err)
+	}
+	if err := executor.ExtractWorkspace(executor.SnapshotArchive(s.root, client, snapshot.Name), dir); err != nil {
+		return fmt.Errorf("failed to restore the workspace of snapshot %s: %v", snapshot.Name, err)
+	}
+	return nil
+}
+
+// restored returns the snapshot images restored by the session of ctx by
+// language, or nil.
+func (s *snapshotTools) restored(ctx context.Context) map[string]string {
+	id := sessionID(ctx)
+	if id == "" {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return maps.Clone(s.images[id])
+}
+
+// middleware runs the executions of sessions that restored snapshots in their
+// images.
+func (s *snapshotTools) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if images := s.restored(ctx); isExecutionTool(request.Params.Name) && images != nil {
+			ctx = executor.WithSnapshotImages(ctx, images)
+		}
+		return next(ctx, request)
+	}
+}
+
+// removeSession forgets the snapshots restored by each session that ends.
+func (s *snapshotTools) removeSession(_ context.Context, session server.ClientSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.images, session.SessionID())
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+)
+
+func TestSnapshotTools_Restore(t *testing.T) {
+	root := t.TempDir()
+	snapshots := newSnapshotTools(root)
+	snapshots.inspect = func(ctx context.Context, client, name string) (executor.Snapshot, error) {
+		if client != anonymousClient || name != "pandas" {
+			return executor.Snapshot{}, fmt.Errorf("unknown snapshot %q", name)
+		}
+		return executor.Snapshot{Name: name, Image: executor.SnapshotImageName(client, name), Language: "python", Workspace: "analysis"}, nil
+	}
+
+	// The snapshot of the anonymous client archived the files of workspace analysis
+	source := t.TempDir()
+	if err := os.WriteFile(filepath.Join(source, "data.csv"), []byte("a,b\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	archive := executor.SnapshotArchive(root, anonymousClient, "pandas")
+	if err := os.MkdirAll(filepath.Dir(archive), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := executor.ArchiveWorkspace(source, archive); err != nil {
+		t.Fatal(err)
+	}
+	mcpServer := server.NewMCPServer("test", "1.0")
+	session := server.NewInProcessSession("session-1", nil)
+	ctx := mcpServer.WithContext(context.Background(), session)
+	other := mcpServer.WithContext(context.Background(), server.NewInProcessSession("session-2", nil))
+
+	restore := func(ctx context.Context, name string) *mcp.CallToolResult {
+		t.Helper()
+		result, err := snapshots.handleRestore(ctx, mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Name: "restore-snapshot", Arguments: map[string]any{"name": name}},
+		})
+		if err != nil {
+			t.Fatalf("handleRestore() returned error: %v", err)
+		}
+		return result
+	}
+	if result := restore(ctx, "polars"); !result.IsError {
+		t.Errorf("Restoring an unknown snapshot = %v, want an error result", result.Content)
+	}
+	if result := restore(context.Background(), "pandas"); !result.IsError {
+		t.Errorf("Restoring without a session = %v, want an error result", result.Content)
+	}
+	if result := restore(withClientID(other, "key-9f86d081"), "pandas"); !result.IsError {
+		t.Errorf("Restoring the snapshot of another client = %v, want an error result", result.Content)
+	}
+	if result := restore(ctx, "pandas"); result.IsError {
+		t.Fatalf("handleRestore() = %v, want a restored snapshot", result.Content)
+	}
+	restored := filepath.Join(executor.SessionWorkspaces(root, "session-1"), "analysis", "data.csv")
+	if got, err := os.ReadFile(restored); err != nil || string(got) != "a,b\n" {
+		t.Errorf("Restored workspace file = %q (%v), want %q", got, err, "a,b\n")
+	}
+
+	// Only the executions of the session run in the snapshot image
+	var images map[string]string
+	handler := snapshots.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		images = executor.SnapshotImages(ctx)
+		return mcp.NewToolResultText("output"), nil
+	})
+	call := func(ctx context.Context, tool string) map[string]string {
+		t.Helper()
+		images = nil
+		if _, err := handler(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{Name: tool}}); err != nil {
+			t.Fatalf("handler returned error: %v", err)
+		}
+		return images
+	}
+	if got, want := call(ctx, "execute-python"), map[string]string{"python": executor.SnapshotImageName(anonymousClient, "pandas")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Snapshot images of the session = %v, want %v", got, want)
+	}
+	if got := call(other, "execute-python"); got != nil {
+		t.Errorf("Snapshot images of another session = %v, want none", got)
+	}
+	if got := call(ctx, "restore-snapshot"); got != nil {
+		t.Errorf("Snapshot images of another tool = %v, want none", got)
+	}
+
+	snapshots.removeSession(context.Background(), session)
+	if got := call(ctx, "execute-python"); got != nil {
+		t.Errorf("Snapshot images after the session ended = %v, want none", got)
+	}
+}
@@ -0,0 +1,36 @@
+// Package server serves MCP over stdio, where the standard output of the
+// process carries the protocol frames and nothing else: log messages go to
+// stderr or the log file, and stray writes to os.Stdout end up on stderr.
+package server
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// RunStdio serves mcpServer on stdin and stdout until stdin is closed or the
+// process receives SIGINT or SIGTERM.
+func RunStdio(mcpServer *server.MCPServer) error {
+	logger.Debug("Starting stdio server")
+	protocol, restore := reserveStdout()
+	defer restore()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+	return server.NewStdioServer(mcpServer).Listen(ctx, os.Stdin, protocol)
+}
+
+// reserveStdout points os.Stdout at stderr, so that output printed by the
+// server or a library cannot corrupt the protocol stream, and returns the
+// standard output of the process for the protocol frames along with a function
+// restoring os.Stdout.
+func reserveStdout() (*os.File, func()) {
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	return stdout, func() { os.Stdout = stdout }
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestReserveStdout(t *testing.T) {
+	stdoutReader, stdoutWriter, _ := os.Pipe()
+	stderrReader, stderrWriter, _ := os.Pipe()
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdoutWriter, stderrWriter
+	defer func() { os.Stdout, os.Stderr = oldStdout, oldStderr }()
+
+	protocol, restore := reserveStdout()
+	fmt.Println("stray output")
+	fmt.Fprintln(protocol, `{"jsonrpc":"2.0"}`)
+	restore()
+	if os.Stdout != stdoutWriter {
+		t.Error("restore() should point os.Stdout at the standard output again")
+	}
+	stdoutWriter.Close()
+	stderrWriter.Close()
+
+	stdout, _ := io.ReadAll(stdoutReader)
+	stderr, _ := io.ReadAll(stderrReader)
+	if string(stdout) != "{\"jsonrpc\":\"2.0\"}\n" {
+		t.Errorf("Standard output = %q, want the protocol frames only", stdout)
+	}
+	if string(stderr) != "stray output\n" {
+		t.Errorf("Stderr = %q, want the stray output", stderr)
+	}
+}
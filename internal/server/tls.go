@@ -0,0 +1,80 @@
+// Package server provides TLS configuration for serving the SSE and
+// streamable HTTP transports over HTTPS.
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// tlsEnabled reports whether a certificate or key was configured.
+func (o TransportOptions) tlsEnabled() bool {
+	return o.TLSCertFile != "" || o.TLSKeyFile != ""
+}
+
+// buildTLSConfig validates the TLS settings and returns the server TLS configuration,
+// requiring verified client certificates when a client CA bundle is configured.
+func buildTLSConfig(o TransportOptions) (*tls.Config, error) {
+	if o.TLSCertFile == "" || o.TLSKeyFile == "" {
+		return nil, fmt.Errorf("both TLS certificate and key must be provided")
+	}
+
+	cert, err := tls.LoadX509KeyPair(o.TLSCertFile, o.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %v", err)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if o.TLSClientCAFile != "" {
+		pem, err := os.ReadFile(o.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS client CA file: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificates found in TLS client CA file %s", o.TLSClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
+// listenAndServe starts httpServer over HTTPS when TLS is configured, plain HTTP otherwise.
+func listenAndServe(httpServer *http.Server, o TransportOptions) error {
+	if !o.tlsEnabled() {
+		if o.TLSClientCAFile != "" {
+			return fmt.Errorf("TLS client CA requires a TLS certificate and key")
+		}
+		return httpServer.ListenAndServe()
+	}
+
+	tlsConfig, err := buildTLSConfig(o)
+	if err != nil {
+		return err
+	}
+	httpServer.TLSConfig = tlsConfig
+	if tlsConfig.ClientAuth == tls.RequireAndVerifyClientCert {
+		logger.Verbose("Requiring TLS client certificates")
+	}
+	return httpServer.ListenAndServeTLS("", "")
+}
+
+// baseURL returns the externally visible URL for host, using https when TLS is enabled.
+func (o TransportOptions) baseURL(host string) string {
+	if o.tlsEnabled() {
+		return "https://" + strings.TrimPrefix(host, "http://")
+	}
+	return host
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeSelfSignedCert writes a throwaway self-signed certificate and key into dir.
+func writeSelfSignedCert(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeSelfSignedCert(t, dir)
+	garbage := filepath.Join(dir, "garbage.pem")
+	if err := os.WriteFile(garbage, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		options        TransportOptions
+		wantErr        bool
+		wantClientAuth tls.ClientAuthType
+	}{
+		{
+			name:    "missing key",
+			options: TransportOptions{TLSCertFile: certFile},
+			wantErr: true,
+		},
+		{
+			name:    "unreadable certificate",
+			options: TransportOptions{TLSCertFile: garbage, TLSKeyFile: keyFile},
+			wantErr: true,
+		},
+		{
+			name:           "server certificate only",
+			options:        TransportOptions{TLSCertFile: certFile, TLSKeyFile: keyFile},
+			wantClientAuth: tls.NoClientCert,
+		},
+		{
+			name:           "mutual TLS",
+			options:        TransportOptions{TLSCertFile: certFile, TLSKeyFile: keyFile, TLSClientCAFile: certFile},
+			wantClientAuth: tls.RequireAndVerifyClientCert,
+		},
+		{
+			name:    "invalid client CA bundle",
+			options: TransportOptions{TLSCertFile: certFile, TLSKeyFile: keyFile, TLSClientCAFile: garbage},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := buildTLSConfig(tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildTLSConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if cfg.ClientAuth != tt.wantClientAuth {
+				t.Errorf("ClientAuth = %v, want %v", cfg.ClientAuth, tt.wantClientAuth)
+			}
+			if cfg.MinVersion != tls.VersionTLS12 {
+				t.Errorf("MinVersion = %x, want TLS 1.2", cfg.MinVersion)
+			}
+		})
+	}
+}
+
+func TestTransportOptions_BaseURL(t *testing.T) {
+	plain := TransportOptions{}
+	if got := plain.baseURL("http://localhost:8080"); got != "http://localhost:8080" {
+		t.Errorf("baseURL() = %q, want http://localhost:8080", got)
+	}
+
+	secure := TransportOptions{TLSCertFile: "cert.pem", TLSKeyFile: "key.pem"}
+	if got := secure.baseURL("http://localhost:8080"); got != "https://localhost:8080" {
+		t.Errorf("baseURL() = %q, want https://localhost:8080", got)
+	}
+}
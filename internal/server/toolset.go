@@ -0,0 +1,239 @@
+// Package server provides tool selection helpers so operators can expose
+// only a subset of the execute tools.
+package server
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+	"github.com/ylchen07/mcp-executor/internal/tools"
+)
+
+// Languages lists the supported execute tool languages in registration order.
+var Languages = []string{"python", "bash", "typescript", "go"}
+
+// Selectors of the execute tools that are not bound to a language, in tool lists.
+const (
+	TestsTool     = "tests"     // execute-tests
+	BenchmarkTool = "benchmark" // execute-benchmark
+	BrowseTool    = "browse"    // browse-web
+	RenderTool    = "render"    // render-page
+	HTTPTool      = "http"      // http-request
+)
+
+// webTools maps the selectors of the tools reaching the web to their names.
+// They run in the sandbox like the execute tools.
+var webTools = map[string]string{
+	BrowseTool: "browse-web",
+	RenderTool: "render-page",
+	HTTPTool:   "http-request",
+}
+
+// ToolSelectors lists the accepted tool selectors: the languages, TestsTool,
+// BenchmarkTool and the selectors of the web tools.
+var ToolSelectors = append(slices.Clone(Languages), TestsTool, BenchmarkTool, BrowseTool, RenderTool, HTTPTool)
+
+// isWebTool reports whether name is the name of a tool reaching the web.
+func isWebTool(name string) bool {
+	for _, webTool := range webTools {
+		if name == webTool {
+			return true
+		}
+	}
+	return false
+}
+
+// isExecutionTool reports whether name is a tool running code in the sandbox,
+// whose calls pass the execution middlewares: the execute tools and the web
+// tools.
+func isExecutionTool(name string) bool {
+	return strings.HasPrefix(name, "execute-") || isWebTool(name)
+}
+
+// executionTool is implemented by every execute-* tool in the tools package.
+type executionTool interface {
+	CreateTool() mcp.Tool
+	HandleExecution(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+}
+
+// ParseToolList normalizes a list of tool selectors ("python", "execute-python", ...)
+// into language names, TestsTool, BenchmarkTool and the selectors of the web
+// tools, rejecting unknown entries.
+func ParseToolList(selectors []string) ([]string, error) {
+	var languages []string
+	for _, selector := range selectors {
+		language := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(selector)), "execute-")
+		for selector, name := range webTools {
+			if language == name {
+				language = selector
+			}
+		}
+		if language == "" {
+			continue
+		}
+		if !slices.Contains(ToolSelectors, language) {
+			return nil, fmt.Errorf("unknown tool %q: expected one of %s", selector, strings.Join(ToolSelectors, ", "))
+		}
+		if !slices.Contains(languages, language) {
+			languages = append(languages, language)
+		}
+	}
+	return languages, nil
+}
+
+// toolRegistry keeps the registered execute tools in sync with the current settings.
+// Registered handlers look up the current tool on every call, so executors can be
+// replaced without re-registering (and re-announcing) unchanged tools.
+type toolRegistry struct {
+	mcpServer *server.MCPServer
+
+	mu        sync.RWMutex
+	tools     map[string]executionTool // Enabled tools keyed by language, plus readOnlySuffix for read-only variants
+	available map[string]executionTool // Tools of the current settings, enabled or not
+	enabled   []string                 // Selectors enabled by the settings, all when empty
+	disabled  []string                 // Selectors disabled at runtime, whatever the settings
+
+	modes     *sessionModes                       // Execution modes selected by the sessions
+	modeTools map[string]map[string]executionTool // Tools of the modes sessions may select, keyed by mode and like tools
+}
+
+// toolKeys lists the keys of the execute tools in registration order: each
+// language followed by its read-only variant, then the tests and benchmark
+// tools and the web tools.
+func toolKeys() []string {
+	keys := make([]string, 0, 2*len(Languages)+5)
+	for _, language := range Languages {
+		keys = append(keys, language, language+readOnlySuffix)
+	}
+	return append(keys, TestsTool, BenchmarkTool, BrowseTool, RenderTool, HTTPTool)
+}
+
+// apply enables the execution tools for the enabled languages (all when enabled is empty),
+// including their read-only variants, except those disabled at runtime. Tools are only
+// added or deleted when the enabled set or a tool definition changes, which notifies
+// clients with tools/list_changed. It reports whether the tool set changed.
+func (r *toolRegistry) apply(executionTools map[string]executionTool, enabled []string) bool {
+	r.mu.Lock()
+	r.available, r.enabled = executionTools, enabled
+	r.mu.Unlock()
+	return r.sync()
+}
+
+// setDisabled disables the tools of selectors at runtime, or enables them
+// again, and reports whether the tool set changed. Disabled tools stay
+// disabled across apply.
+func (r *toolRegistry) setDisabled(selectors []string, disabled bool) bool {
+	r.mu.Lock()
+	for _, selector := range selectors {
+		r.disabled = slices.DeleteFunc(r.disabled, func(s string) bool { return s == selector })
+		if disabled {
+			r.disabled = append(r.disabled, selector)
+		}
+	}
+	r.mu.Unlock()
+	return r.sync()
+}
+
+// sync registers the tools enabled by the settings and not disabled at
+// runtime, and reports whether the tool set changed.
+func (r *toolRegistry) sync() bool {
+	r.mu.Lock()
+	active := make(map[string]executionTool, len(r.available))
+	for _, key := range toolKeys() {
+		tool, ok := r.available[key]
+		if !ok {
+			continue
+		}
+		selector := strings.TrimSuffix(key, readOnlySuffix)
+		if len(r.enabled) > 0 && !slices.Contains(r.enabled, selector) {
+			logger.Debug("Skipping disabled %s tool", key)
+			continue
+		}
+		if slices.Contains(r.disabled, selector) {
+			logger.Debug("Skipping %s tool disabled at runtime", key)
+			continue
+		}
+		active[key] = tool
+	}
+	previous := r.tools
+	r.tools = active
+	r.mu.Unlock()
+
+	var added []server.ServerTool
+	var removed []string
+	for _, key := range toolKeys() {
+		oldTool, wasEnabled := previous[key]
+		newTool, isEnabled := active[key]
+		switch {
+		case isEnabled && !wasEnabled:
+			added = append(added, server.ServerTool{Tool: newTool.CreateTool(), Handler: r.handler(key)})
+		case wasEnabled && !isEnabled:
+			removed = append(removed, oldTool.CreateTool().Name)
+		case isEnabled && !reflect.DeepEqual(oldTool.CreateTool(), newTool.CreateTool()):
+			added = append(added, server.ServerTool{Tool: newTool.CreateTool(), Handler: r.handler(key)})
+		}
+	}
+
+	if len(added) > 0 {
+		r.mcpServer.AddTools(added...)
+	}
+	if len(removed) > 0 {
+		logger.Debug("Removing disabled tools: %v", removed)
+		r.mcpServer.DeleteTools(removed...)
+	}
+	return len(added) > 0 || len(removed) > 0
+}
+
+// setModeTools replaces the tools of the execution modes sessions may select.
+func (r *toolRegistry) setModeTools(modeTools map[string]map[string]executionTool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.modeTools = modeTools
+}
+
+// enabledTools returns the definitions of the enabled tools in mode, keyed
+// like the tools of apply. Modes sessions may not select give the tools of the
+// server mode.
+func (r *toolRegistry) enabledTools(mode string) map[string]mcp.Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	definitions := make(map[string]mcp.Tool, len(r.tools))
+	for key, tool := range r.tools {
+		if modeTools, ok := r.modeTools[mode]; ok {
+			if tool, ok = modeTools[key]; !ok {
+				continue
+			}
+		}
+		definitions[key] = tool.CreateTool()
+	}
+	return definitions
+}
+
+// handler dispatches calls to the current tool implementation for key, in the
+// execution mode of the session.
+func (r *toolRegistry) handler(key string) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mode := r.modes.mode(ctx)
+		r.mu.RLock()
+		tool, ok := r.tools[key]
+		modeTools, selected := r.modeTools[mode]
+		r.mu.RUnlock()
+		if !ok {
+			return tools.ErrorResult(executor.ErrorPolicyViolation, fmt.Sprintf("tool %s is disabled", request.Params.Name)), nil
+		}
+		if selected {
+			if tool, ok = modeTools[key]; !ok {
+				return tools.ErrorResult(executor.ErrorPolicyViolation, fmt.Sprintf("tool %s is not available in %s mode, selected by this session", request.Params.Name, mode)), nil
+			}
+		}
+		return tool.HandleExecution(ctx, request)
+	}
+}
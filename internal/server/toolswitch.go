@@ -0,0 +1,110 @@
+// Package server lets operators disable execute tools of a running server and
+// enable them again, e.g. execute-bash during an incident, without restarting
+// it: clients receive tools/list_changed and keep their sessions.
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// ToolStatus is the state reported by the tool admin endpoints.
+type ToolStatus struct {
+	Enabled  []string `json:"enabled"`           // Names of the registered execute tools
+	Disabled []string `json:"disabled"`          // Tool selectors disabled at runtime
+	Changed  bool     `json:"changed,omitempty"` // The request changed the registered tools
+}
+
+// DisableTools unregisters the execute tools of selectors ("bash",
+// "execute-bash", ...), including their read-only variants, until EnableTools.
+// They stay disabled across reloads of the configuration.
+func (r *Reloader) DisableTools(selectors []string) (ToolStatus, error) {
+	return r.setToolsDisabled(selectors, true)
+}
+
+// EnableTools registers the execute tools of selectors disabled by
+// DisableTools again, when the configuration enables them.
+func (r *Reloader) EnableTools(selectors []string) (ToolStatus, error) {
+	return r.setToolsDisabled(selectors, false)
+}
+
+// ToolStatus reports the registered execute tools and those disabled at
+// runtime.
+func (r *Reloader) ToolStatus() ToolStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.toolStatus()
+}
+
+// setToolsDisabled disables the tools of selectors, or enables them again.
+func (r *Reloader) setToolsDisabled(selectors []string, disabled bool) (ToolStatus, error) {
+	parsed, err := ParseToolList(selectors)
+	if err != nil {
+		return ToolStatus{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	changed := r.registry.setDisabled(parsed, disabled)
+	logger.Info("Tools %v disabled at runtime: %t (tool set changed: %t)", parsed, disabled, changed)
+	status := r.toolStatus()
+	status.Changed = changed
+	return status, nil
+}
+
+// toolStatus returns the state of the tools; r.mu must be held.
+func (r *Reloader) toolStatus() ToolStatus {
+	status := ToolStatus{Enabled: []string{}, Disabled: []string{}}
+	tools := r.registry.enabledTools(r.executionMode)
+	for _, key := range toolKeys() {
+		if tool, ok := tools[key]; ok {
+			status.Enabled = append(status.Enabled, tool.Name)
+		}
+	}
+	r.registry.mu.RLock()
+	status.Disabled = append(status.Disabled, r.registry.disabled...)
+	r.registry.mu.RUnlock()
+	slices.Sort(status.Disabled)
+	return status
+}
+
+// registerEndpoints serves the tool switch on mux below prefix: GET tools,
+// POST tools/disable and POST tools/enable, naming the tools in tool query
+// parameters (?tool=bash&tool=go or ?tool=bash,go).
+func (r *Reloader) registerEndpoints(mux *http.ServeMux, prefix string) {
+	mux.HandleFunc(prefix+"/tools", func(w http.ResponseWriter, req *http.Request) {
+		if allowMethod(w, req, http.MethodGet) {
+			writeToolStatus(w, r.ToolStatus())
+		}
+	})
+	for action, disable := range map[string]bool{"disable": true, "enable": false} {
+		mux.HandleFunc(prefix+"/tools/"+action, func(w http.ResponseWriter, req *http.Request) {
+			if !allowMethod(w, req, http.MethodPost) {
+				return
+			}
+			var selectors []string
+			for _, value := range req.URL.Query()["tool"] {
+				selectors = append(selectors, strings.Split(value, ",")...)
+			}
+			if len(selectors) == 0 {
+				http.Error(w, "missing tool parameter, e.g. ?tool=bash", http.StatusBadRequest)
+				return
+			}
+			status, err := r.setToolsDisabled(selectors, disable)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			writeToolStatus(w, status)
+		})
+	}
+}
+
+// writeToolStatus writes status as JSON.
+func writeToolStatus(w http.ResponseWriter, status ToolStatus) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestReloader_DisableTools(t *testing.T) {
+	mcpServer, reloader := NewReloadableMCPServer("subprocess", WithEnabledTools([]string{"python", "bash"}), WithReadOnlyTools([]string{"bash"}))
+
+	status, err := reloader.DisableTools([]string{"execute-bash"})
+	if err != nil {
+		t.Fatalf("DisableTools() returned error: %v", err)
+	}
+	if !status.Changed || !reflect.DeepEqual(status.Disabled, []string{"bash"}) {
+		t.Errorf("DisableTools() = %+v, want bash disabled and a changed tool set", status)
+	}
+	if tools := executeTools(mcpServer); len(tools) != 1 || tools["execute-python"] == nil {
+		t.Errorf("Only execute-python should stay registered, got %d tools", len(tools))
+	}
+
+	reloader.Reload(WithEnabledTools([]string{"python", "bash", "go"}))
+	if tools := executeTools(mcpServer); len(tools) != 2 || tools["execute-bash"] != nil {
+		t.Errorf("execute-bash should stay disabled across reloads, got %d tools", len(tools))
+	}
+
+	if status, _ := reloader.DisableTools([]string{"bash"}); status.Changed {
+		t.Error("Disabling a disabled tool should not report a change")
+	}
+	if _, err := reloader.DisableTools([]string{"cobol"}); err == nil {
+		t.Error("DisableTools() should reject unknown tools")
+	}
+
+	status, err = reloader.EnableTools([]string{"bash"})
+	if err != nil {
+		t.Fatalf("EnableTools() returned error: %v", err)
+	}
+	if !status.Changed || len(status.Disabled) != 0 {
+		t.Errorf("EnableTools() = %+v, want no disabled tool and a changed tool set", status)
+	}
+	if tools := executeTools(mcpServer); len(tools) != 3 || tools["execute-bash"] == nil {
+		t.Errorf("execute-bash should be registered again, got %d tools", len(tools))
+	}
+}
+
+func TestToolEndpoints(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		wantStatus   int
+		wantEnabled  []string
+		wantDisabled []string
+	}{
+		{"status", http.MethodGet, "/admin/tools", http.StatusOK, []string{"execute-python", "execute-bash", "execute-go"}, []string{}},
+		{"disable", http.MethodPost, "/admin/tools/disable?tool=bash", http.StatusOK, []string{"execute-python", "execute-go"}, []string{"bash"}},
+		{"disable several", http.MethodPost, "/admin/tools/disable?tool=bash,go&tool=python", http.StatusOK, []string{}, []string{"bash", "go", "python"}},
+		{"enable", http.MethodPost, "/admin/tools/enable?tool=typescript", http.StatusOK, []string{"execute-python", "execute-bash", "execute-go"}, []string{}},
+		{"missing tool", http.MethodPost, "/admin/tools/disable", http.StatusBadRequest, nil, nil},
+		{"unknown tool", http.MethodPost, "/admin/tools/disable?tool=cobol", http.StatusBadRequest, nil, nil},
+		{"disable with get", http.MethodGet, "/admin/tools/disable?tool=bash", http.StatusMethodNotAllowed, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, reloader := NewReloadableMCPServer("subprocess", WithEnabledTools([]string{"python", "bash", "go"}))
+			options := newTransportOptions([]TransportOption{WithListenAddress("127.0.0.1:8081"), WithToolEndpoints(reloader)})
+
+			recorder := httptest.NewRecorder()
+			options.handler(http.NotFoundHandler()).ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.path, nil))
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var status ToolStatus
+			if err := json.Unmarshal(recorder.Body.Bytes(), &status); err != nil {
+				t.Fatalf("Response is not a tool status: %v", err)
+			}
+			if !reflect.DeepEqual(status.Enabled, tt.wantEnabled) || !reflect.DeepEqual(status.Disabled, tt.wantDisabled) {
+				t.Errorf("Status = %+v, want enabled %q and disabled %q", status, tt.wantEnabled, tt.wantDisabled)
+			}
+		})
+	}
+}
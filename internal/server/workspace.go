@@ -0,0 +1,96 @@
+// Package server removes the named workspaces of MCP sessions when they end,
+// when streamable HTTP clients delete them or when they lie idle.
+package server
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// maxCollectInterval bounds how long idle workspaces outlive their idle timeout.
+const maxCollectInterval = time.Hour
+
+// sessionID returns the ID of the MCP session of ctx, or an empty string for
+// calls made outside a session, such as scheduled runs.
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+// removeWorkspaces returns a hook deleting the workspaces below root of each
+// session that ends.
+func removeWorkspaces(root string) server.OnUnregisterSessionHookFunc {
+	return func(ctx context.Context, session server.ClientSession) {
+		dir := executor.SessionWorkspaces(root, session.SessionID())
+		if _, err := os.Stat(dir); err != nil {
+			return
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			logger.Error("Failed to remove the workspaces of session %s: %v", session.SessionID(), err)
+			return
+		}
+		logger.Debug("Removed the workspaces of session %s", session.SessionID())
+	}
+}
+
+// startWorkspaceCollector removes the workspaces below root that no execution
+// used for idle in the background: at once, which removes those left behind by
+// earlier runs, then at most every maxCollectInterval.
+func startWorkspaceCollector(root string, idle time.Duration) {
+	go func() {
+		collectWorkspaces(root, idle, time.Now())
+		ticker := time.NewTicker(min(max(idle/2, time.Second), maxCollectInterval))
+		defer ticker.Stop()
+		for now := range ticker.C {
+			collectWorkspaces(root, idle, now)
+		}
+	}()
+}
+
+// collectWorkspaces removes the idle workspaces and logs each session's.
+func collectWorkspaces(root string, idle time.Duration, now time.Time) {
+	removed, err := executor.CollectWorkspaces(root, idle, now)
+	for _, dir := range removed {
+		logger.Info("Removed the workspaces %s: unused for execution.workspace_idle of %s", dir, idle)
+	}
+	if err != nil {
+		logger.Error("Failed to remove idle workspaces: %v", err)
+	}
+}
+
+// endSessionsOnDelete unregisters the sessions that streamable HTTP clients
+// delete, which the transport leaves registered, so that the hooks removing
+// their workspaces, execution mode and owner run.
+func endSessionsOnDelete(mcpServer *server.MCPServer, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := r.Header.Get(server.HeaderKeySessionID)
+		if r.Method != http.MethodDelete || session == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		if recorder.status == http.StatusOK {
+			mcpServer.UnregisterSession(r.Context(), session)
+		}
+	})
+}
+
+// statusRecorder remembers the status code written to its ResponseWriter.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
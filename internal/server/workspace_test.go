@@ -0,0 +1,52 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+)
+
+func TestEndSessionsOnDelete(t *testing.T) {
+	root := t.TempDir()
+	mcpServer := NewMCPServer("subprocess", WithWorkspaceRoot(root), WithEnabledTools([]string{"bash"}))
+	httpServer := httptest.NewServer(endSessionsOnDelete(mcpServer, server.NewStreamableHTTPServer(mcpServer)))
+	defer httpServer.Close()
+
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`
+	request, _ := http.NewRequest(http.MethodPost, httpServer.URL, strings.NewReader(initialize))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json, text/event-stream")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	response.Body.Close()
+	session := response.Header.Get(server.HeaderKeySessionID)
+	if session == "" {
+		t.Fatal("initialize returned no session ID")
+	}
+
+	workspace := filepath.Join(executor.SessionWorkspaces(root, session), "pipeline")
+	if err := os.MkdirAll(workspace, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	request, _ = http.NewRequest(http.MethodDelete, httpServer.URL, nil)
+	request.Header.Set(server.HeaderKeySessionID, session)
+	response, err = http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("DELETE failed: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("DELETE status = %d, want %d", response.StatusCode, http.StatusOK)
+	}
+	if _, err := os.Stat(executor.SessionWorkspaces(root, session)); !os.IsNotExist(err) {
+		t.Errorf("Workspaces of the deleted session should be removed, stat error = %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+// Package testrun parses the coverage data of the test programs: the JSON
+// report of coverage.py, the profile of go test -coverprofile and the JSON
+// summary of c8.
+package testrun
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Coverage is the statement coverage of a test run.
+type Coverage struct {
+	Tool       string         `json:"tool"`    // Tool measuring coverage, e.g. coverage.py
+	Percent    float64        `json:"percent"` // Covered statements of all files, rounded to 0.1
+	Covered    int            `json:"covered"`
+	Statements int            `json:"statements"`
+	Files      []FileCoverage `json:"files"` // Sorted by file
+}
+
+// FileCoverage is the statement coverage of a file.
+type FileCoverage struct {
+	File       string  `json:"file"` // Path relative to the test directory; Go files keep their package path when not in the module
+	Percent    float64 `json:"percent"`
+	Covered    int     `json:"covered"`
+	Statements int     `json:"statements"`
+}
+
+// parseCoverage returns the coverage in the coverage data of language.
+func parseCoverage(language, data string) (*Coverage, error) {
+	var files []FileCoverage
+	var err error
+	switch language {
+	case "python":
+		files, err = parseCoveragePy(data)
+	case "go":
+		files, err = parseCoverProfile(data)
+	case "typescript":
+		files, err = parseC8Summary(data)
+	default:
+		return nil, fmt.Errorf("coverage cannot be measured for %s", language)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	coverage := &Coverage{Files: files}
+	sort.Slice(coverage.Files, func(i, j int) bool { return coverage.Files[i].File < coverage.Files[j].File })
+	for i, file := range coverage.Files {
+		coverage.Files[i].Percent = percent(file.Covered, file.Statements)
+		coverage.Covered += file.Covered
+		coverage.Statements += file.Statements
+	}
+	coverage.Percent = percent(coverage.Covered, coverage.Statements)
+	return coverage, nil
+}
+
+// percent returns the percentage of covered statements rounded to 0.1, 100
+// when there are none.
+func percent(covered, statements int) float64 {
+	if statements == 0 {
+		return 100
+	}
+	return math.Round(1000*float64(covered)/float64(statements)) / 10
+}
+
+// parseCoveragePy reads the files of a coverage.py JSON report.
+func parseCoveragePy(data string) ([]FileCoverage, error) {
+	var report struct {
+		Files map[string]struct {
+			Summary struct {
+				CoveredLines  int `json:"covered_lines"`
+				NumStatements int `json:"num_statements"`
+			} `json:"summary"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal([]byte(data), &report); err != nil {
+		return nil, err
+	}
+	files := make([]FileCoverage, 0, len(report.Files))
+	for name, file := range report.Files {
+		files = append(files, FileCoverage{File: name, Covered: file.Summary.CoveredLines, Statements: file.Summary.NumStatements})
+	}
+	return files, nil
+}
+
+// parseCoverProfile reads the blocks of a go test coverage profile, lines of
+// file:start,end statements count after the mode line. Blocks listed more than
+// once, as when several packages cover a file, count as covered when any run
+// covered them.
+func parseCoverProfile(data string) ([]FileCoverage, error) {
+	type block struct {
+		statements int
+		covered    bool
+	}
+	blocks := map[string]map[string]*block{} // By file and position
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 || !strings.Contains(fields[0], ":") {
+			return nil, fmt.Errorf("invalid profile line %q", line)
+		}
+		colon := strings.LastIndex(fields[0], ":")
+		statements, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid profile line %q", line)
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid profile line %q", line)
+		}
+		file, position := fields[0][:colon], fields[0][colon+1:]
+		if blocks[file] == nil {
+			blocks[file] = map[string]*block{}
+		}
+		if blocks[file][position] == nil {
+			blocks[file][position] = &block{statements: statements}
+		}
+		blocks[file][position].covered = blocks[file][position].covered || count > 0
+	}
+
+	files := make([]FileCoverage, 0, len(blocks))
+	for name, fileBlocks := range blocks {
+		file := FileCoverage{File: name}
+		for _, b := range fileBlocks {
+			file.Statements += b.statements
+			if b.covered {
+				file.Covered += b.statements
+			}
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// parseC8Summary reads the files of a c8 (istanbul) JSON summary, skipping
+// its "total" entry.
+func parseC8Summary(data string) ([]FileCoverage, error) {
+	var summary map[string]struct {
+		Statements struct {
+			Total   int `json:"total"`
+			Covered int `json:"covered"`
+		} `json:"statements"`
+	}
+	if err := json.Unmarshal([]byte(data), &summary); err != nil {
+		return nil, err
+	}
+	files := make([]FileCoverage, 0, len(summary))
+	for name, file := range summary {
+		if name != "total" {
+			files = append(files, FileCoverage{File: name, Covered: file.Statements.Covered, Statements: file.Statements.Total})
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,189 @@
+// Package testrun provides the test programs of each language. They decode the
+// files from base64-encoded JSON, so their content never needs quoting, and
+// remove their temporary directory when the runner exits.
+package testrun
+
+const pythonProgram = `import base64, json, os, shutil, subprocess, sys, tempfile
+
+files = json.loads(base64.b64decode("{{FILES}}"))
+coverage = "{{COVERAGE}}" == "1"
+root = tempfile.mkdtemp(prefix="mcp-tests-")
+
+
+def run(*args, **kwargs):
+    return subprocess.run(
+        [sys.executable, "-m", *args], cwd=root, stdout=subprocess.PIPE, stderr=subprocess.STDOUT, text=True, **kwargs
+    )
+
+
+try:
+    for path, content in files.items():
+        target = os.path.join(root, path)
+        os.makedirs(os.path.dirname(target), exist_ok=True)
+        with open(target, "w") as f:
+            f.write(content)
+    report = os.path.join(root, ".mcp-report.xml")
+    pytest = ["pytest", "-q", "-p", "no:cacheprovider", "--junitxml", report]
+    env = dict(os.environ, COVERAGE_FILE=os.path.join(root, ".mcp-coverage"))
+    if coverage:
+        pytest = ["coverage", "run", "--omit=*test_*.py,*_test.py,*conftest.py", "-m", *pytest]
+    print(run(*pytest, env=env).stdout, end="")
+    if os.path.exists(report):
+        print("\n{{MARKER}}")
+        with open(report) as f:
+            print(f.read())
+    if coverage and os.path.exists(report):
+        data = os.path.join(root, ".mcp-coverage.json")
+        run("coverage", "json", "-o", data, env=env)
+        artifacts = os.environ.get("MCP_ARTIFACTS")
+        if artifacts:
+            table = run("coverage", "report", "--format=markdown", env=env).stdout
+            try:
+                with open(os.path.join(artifacts, "coverage.md"), "w") as f:
+                    f.write("# Coverage\n\n" + table)
+            except OSError:
+                pass
+        if os.path.exists(data):
+            print("{{COVERAGE_MARKER}}")
+            with open(data) as f:
+                print(f.read())
+finally:
+    shutil.rmtree(root, ignore_errors=True)
+`
+
+const goProgram = `package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	files    = "{{FILES}}"
+	coverage = "{{COVERAGE}}" == "1"
+)
+
+func main() {
+	data, err := base64.StdEncoding.DecodeString(files)
+	if err != nil {
+		panic(err)
+	}
+	var sources map[string]string
+	if err := json.Unmarshal(data, &sources); err != nil {
+		panic(err)
+	}
+	root, err := os.MkdirTemp("", "mcp-tests-*")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(root)
+	for name, content := range sources {
+		target := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			panic(err)
+		}
+		if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+			panic(err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "go.mod")); err != nil {
+		module := "module tests\n"
+		if version, ok := strings.CutPrefix(runtime.Version(), "go"); ok {
+			module += "\ngo " + version + "\n"
+		}
+		if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte(module), 0o644); err != nil {
+			panic(err)
+		}
+	}
+
+	args := []string{"test", "-json", "./..."}
+	profile := filepath.Join(root, ".mcp-cover.out")
+	if coverage {
+		args = append(args, "-cover", "-coverprofile="+profile)
+	}
+	cmd := exec.Command("go", args...)
+	cmd.Dir = root
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+	out, _ := cmd.CombinedOutput()
+	fmt.Println("{{MARKER}}")
+	os.Stdout.Write(out)
+
+	measured, err := os.ReadFile(profile)
+	if !coverage || err != nil {
+		return
+	}
+	if artifacts := os.Getenv("MCP_ARTIFACTS"); artifacts != "" {
+		cmd := exec.Command("go", "tool", "cover", "-func="+profile)
+		cmd.Dir = root
+		if table, err := cmd.Output(); err == nil {
+			fence := strings.Repeat("\x60", 3)
+			_ = os.WriteFile(filepath.Join(artifacts, "coverage.md"), []byte("# Coverage\n\n"+fence+"text\n"+string(table)+fence+"\n"), 0o644)
+		}
+	}
+	// Paths relative to the test directory, as in the other languages
+	cmd = exec.Command("go", "list", "-m")
+	cmd.Dir = root
+	if module, err := cmd.Output(); err == nil {
+		measured = []byte(strings.ReplaceAll(string(measured), "\n"+strings.TrimSpace(string(module))+"/", "\n"))
+	}
+	fmt.Println("\n{{COVERAGE_MARKER}}")
+	os.Stdout.Write(measured)
+}
+`
+
+const typeScriptProgram = `import { spawnSync } from "node:child_process";
+import { existsSync, mkdirSync, mkdtempSync, readFileSync, rmSync, writeFileSync } from "node:fs";
+import { delimiter, dirname, join, relative } from "node:path";
+
+const files: Record<string, string> = JSON.parse(Buffer.from("{{FILES}}", "base64").toString("utf8"));
+const coverage: string = "{{COVERAGE}}";
+// Inside the working directory, so the tests resolve the installed packages
+const root = mkdtempSync(join(process.cwd(), ".mcp-tests-"));
+const bin = join(process.cwd(), "node_modules", ".bin");
+const env = { ...process.env, PATH: bin + delimiter + process.env.PATH, CI: "1", NO_COLOR: "1" };
+const run = (args: string[]) => spawnSync("npx", ["--yes", ...args], { cwd: root, encoding: "utf8", env });
+try {
+  for (const [path, content] of Object.entries(files)) {
+    mkdirSync(dirname(join(root, path)), { recursive: true });
+    writeFileSync(join(root, path), content);
+  }
+  const report = join(root, ".mcp-report.xml");
+  const reports = join(root, ".mcp-coverage");
+  const c8 = ["c8", "--reports-dir=" + reports, "--temp-directory=" + join(reports, "tmp")];
+  let vitest = ["vitest", "run", "--reporter=default", "--reporter=junit", "--outputFile.junit=" + report];
+  if (coverage === "1") {
+    vitest = [...c8, "--reporter=json-summary", "npx", ...vitest];
+  }
+  const tests = run(vitest);
+  process.stdout.write((tests.stdout ?? "") + (tests.stderr ?? ""));
+  if (existsSync(report)) {
+    console.log("\n{{MARKER}}");
+    process.stdout.write(readFileSync(report, "utf8"));
+  }
+  const summary = join(reports, "coverage-summary.json");
+  if (coverage === "1" && existsSync(report) && existsSync(summary)) {
+    if (process.env.MCP_ARTIFACTS) {
+      const table = run([...c8, "report", "--reporter=text"]).stdout ?? "";
+      const fence = "\x60".repeat(3);
+      try {
+        writeFileSync(join(process.env.MCP_ARTIFACTS, "coverage.md"), "# Coverage\n\n" + fence + "text\n" + table + fence + "\n");
+      } catch {}
+    }
+    // Paths relative to the test directory, as in the other languages
+    const files: Record<string, unknown> = {};
+    for (const [file, totals] of Object.entries(JSON.parse(readFileSync(summary, "utf8")))) {
+      files[file === "total" ? file : relative(root, file)] = totals;
+    }
+    console.log("\n{{COVERAGE_MARKER}}");
+    console.log(JSON.stringify(files));
+  }
+} finally {
+  rmSync(root, { recursive: true, force: true });
+}
+`
@@ -0,0 +1,231 @@
+// Package testrun parses the reports of the test runners: the JUnit XML of
+// pytest and vitest and the event stream of go test -json.
+package testrun
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Report is the outcome of a test run.
+type Report struct {
+	Framework string    `json:"framework"`
+	Passed    int       `json:"passed"`
+	Failed    int       `json:"failed"`
+	Skipped   int       `json:"skipped"`
+	Errors    int       `json:"errors"` // Tests or packages that could not run, e.g. on import or build errors
+	Total     int       `json:"total"`
+	Failures  []Failure `json:"failures"`           // Failed tests and errors
+	Coverage  *Coverage `json:"coverage,omitempty"` // Measured when requested
+}
+
+// Failure is a failed test or an error of the run.
+type Failure struct {
+	Test    string `json:"test,omitempty"` // Empty for errors of a whole suite or package
+	Suite   string `json:"suite"`          // Test class or module, test file or Go package
+	Message string `json:"message"`
+	Details string `json:"details,omitempty"` // Traceback or output of the test
+}
+
+// Success reports whether every test that ran passed.
+func (r Report) Success() bool {
+	return r.Failed == 0 && r.Errors == 0
+}
+
+// Summary describes the counts of the report, e.g. "pytest: 3 passed, 1 failed".
+func (r Report) Summary() string {
+	counts := []string{fmt.Sprintf("%d passed", r.Passed)}
+	for _, count := range []struct {
+		n    int
+		name string
+	}{{r.Failed, "failed"}, {r.Skipped, "skipped"}, {r.Errors, "errors"}} {
+		if count.n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", count.n, count.name))
+		}
+	}
+	summary := r.Framework + ": " + strings.Join(counts, ", ")
+	if r.Coverage != nil {
+		summary += fmt.Sprintf("; %.1f%% coverage", r.Coverage.Percent)
+	}
+	return summary
+}
+
+// Parse splits the output of the test program of language into the output of
+// the runner and its report, including the coverage data when the program
+// measured coverage. It fails when the runner wrote no report, e.g. because it
+// is not installed.
+func Parse(language, output string) (Report, string, error) {
+	framework, ok := Frameworks[language]
+	if !ok {
+		return Report{}, output, fmt.Errorf("tests cannot run for %s", language)
+	}
+	runnerOutput, data, found := strings.Cut(output, ReportMarker)
+	if !found {
+		return Report{}, output, fmt.Errorf("%s wrote no report", framework.Name)
+	}
+	data, coverageData, measured := strings.Cut(data, CoverageMarker)
+	var report Report
+	var err error
+	if language == "go" {
+		report, runnerOutput = parseGoTest(data)
+	} else if report, err = parseJUnit(data); err != nil {
+		return Report{}, runnerOutput, fmt.Errorf("invalid %s report: %v", framework.Name, err)
+	}
+	if measured {
+		if report.Coverage, err = parseCoverage(language, coverageData); err != nil {
+			return Report{}, runnerOutput, fmt.Errorf("invalid %s data: %v", framework.Coverage, err)
+		}
+		report.Coverage.Tool = framework.Coverage
+	}
+	report.Framework = framework.Name
+	report.Total = report.Passed + report.Failed + report.Skipped + report.Errors
+	return report, strings.TrimRight(runnerOutput, "\n") + "\n", nil
+}
+
+// junitSuite holds the test cases and nested suites of a JUnit XML element,
+// the <testsuites> or <testsuite> root of a report.
+type junitSuite struct {
+	Suites []junitSuite `xml:"testsuite"`
+	Cases  []junitCase  `xml:"testcase"`
+}
+
+type junitCase struct {
+	Class   string        `xml:"classname,attr"`
+	Name    string        `xml:"name,attr"`
+	Failure *junitProblem `xml:"failure"`
+	Error   *junitProblem `xml:"error"`
+	Skipped *junitProblem `xml:"skipped"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// parseJUnit counts the test cases of a JUnit XML report.
+func parseJUnit(data string) (Report, error) {
+	var root junitSuite
+	if err := xml.Unmarshal([]byte(strings.TrimSpace(data)), &root); err != nil {
+		return Report{}, err
+	}
+	var report Report
+	var count func(suites []junitSuite, cases []junitCase)
+	count = func(suites []junitSuite, cases []junitCase) {
+		for _, c := range cases {
+			switch {
+			case c.Failure != nil:
+				report.Failed++
+				report.Failures = append(report.Failures, junitFailure(c, c.Failure))
+			case c.Error != nil:
+				report.Errors++
+				report.Failures = append(report.Failures, junitFailure(c, c.Error))
+			case c.Skipped != nil:
+				report.Skipped++
+			default:
+				report.Passed++
+			}
+		}
+		for _, suite := range suites {
+			count(suite.Suites, suite.Cases)
+		}
+	}
+	count(root.Suites, root.Cases)
+	return report, nil
+}
+
+func junitFailure(c junitCase, problem *junitProblem) Failure {
+	details := strings.TrimSpace(problem.Text)
+	message := problem.Message
+	if message == "" {
+		message, _, _ = strings.Cut(details, "\n")
+	}
+	return Failure{Test: c.Name, Suite: c.Class, Message: message, Details: details}
+}
+
+// goTestEvent is an event of go test -json.
+type goTestEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Output  string
+}
+
+// goTestLocation matches the file:line prefix of a message printed by t.Error.
+var goTestLocation = regexp.MustCompile(`^\s+\S+_test\.go:\d+: `)
+
+// parseGoTest counts the test events of go test -json and returns the output
+// of the tests. Packages failing without a failed test, such as packages that
+// do not build, are errors.
+func parseGoTest(data string) (Report, string) {
+	var report Report
+	var output strings.Builder
+	outputs := map[[2]string]*strings.Builder{} // By package and test
+	failedTests := map[string]bool{}            // Packages with failed tests
+	var unparsed strings.Builder                // Output of the go command, e.g. build errors
+	appendOutput := func(key [2]string, text string) {
+		if outputs[key] == nil {
+			outputs[key] = &strings.Builder{}
+		}
+		outputs[key].WriteString(text)
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for scanner.Scan() {
+		var event goTestEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || event.Action == "" {
+			unparsed.WriteString(scanner.Text() + "\n")
+			output.WriteString(scanner.Text() + "\n")
+			continue
+		}
+		key := [2]string{event.Package, event.Test}
+		switch event.Action {
+		case "output", "build-output":
+			appendOutput(key, event.Output)
+			output.WriteString(event.Output)
+		case "pass":
+			if event.Test != "" {
+				report.Passed++
+			}
+		case "skip":
+			if event.Test != "" {
+				report.Skipped++
+			}
+		case "fail":
+			if event.Test != "" {
+				report.Failed++
+				failedTests[event.Package] = true
+				report.Failures = append(report.Failures, goTestFailure(event, outputs[key]))
+			} else if !failedTests[event.Package] {
+				report.Errors++
+				details := unparsed.String()
+				if packageOutput := outputs[key]; packageOutput != nil {
+					details += packageOutput.String()
+				}
+				report.Failures = append(report.Failures, Failure{Suite: event.Package, Message: "package failed", Details: strings.TrimSpace(details)})
+			}
+		}
+	}
+	return report, output.String()
+}
+
+// goTestFailure returns the Failure of a failed test with its output, whose
+// first t.Error message is the failure message.
+func goTestFailure(event goTestEvent, output *strings.Builder) Failure {
+	failure := Failure{Test: event.Test, Suite: event.Package, Message: "test failed"}
+	if output == nil {
+		return failure
+	}
+	failure.Details = strings.TrimSpace(output.String())
+	for _, line := range strings.Split(output.String(), "\n") {
+		if location := goTestLocation.FindString(line); location != "" {
+			failure.Message = strings.TrimSpace(line)
+			break
+		}
+	}
+	return failure
+}
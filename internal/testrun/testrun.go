@@ -0,0 +1,109 @@
+// Package testrun runs test suites in the sandbox: it builds the program that
+// writes the source and test files of a call and runs pytest, go test or
+// vitest on them, and parses the report of the runner into pass and fail
+// counts with the details of each failure and, optionally, the coverage of
+// each file.
+package testrun
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ReportMarker separates the output of the runner from its machine-readable
+// report in the output of a test program.
+const ReportMarker = "--- mcp-executor test report ---"
+
+// CoverageMarker follows the report and precedes the coverage data of a run
+// measuring coverage.
+const CoverageMarker = "--- mcp-executor coverage report ---"
+
+// Framework describes how the tests of a language run.
+type Framework struct {
+	Name         string   // Test runner, e.g. pytest
+	Dependencies []string // Packages installed with the program to run the tests
+	Coverage     string   // Tool measuring coverage, e.g. coverage.py
+	// Packages also installed when the run measures coverage
+	CoverageDependencies []string
+	program              string // Source of the test program, see Program
+}
+
+// Frameworks are the test runners by language.
+var Frameworks = map[string]Framework{
+	"python": {
+		Name:                 "pytest",
+		Dependencies:         []string{"pytest"},
+		Coverage:             "coverage.py",
+		CoverageDependencies: []string{"coverage"},
+		program:              pythonProgram,
+	},
+	"go": {
+		Name:     "go test",
+		Coverage: "go test -cover",
+		program:  goProgram,
+	},
+	"typescript": {
+		Name:                 "vitest",
+		Dependencies:         []string{"vitest"},
+		Coverage:             "c8",
+		CoverageDependencies: []string{"c8"},
+		program:              typeScriptProgram,
+	},
+}
+
+// Languages returns the languages with a test runner, sorted.
+func Languages() []string {
+	languages := make([]string, 0, len(Frameworks))
+	for language := range Frameworks {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Program returns the program of language writing files, keyed by their path
+// relative to the test directory, to a temporary directory and running the
+// tests there. It prints the output of the runner, then ReportMarker and the
+// report of the runner. With coverage, the program also measures the coverage
+// of the files, then prints CoverageMarker and the coverage data, and writes
+// the report of the coverage tool to coverage.md in MCP_ARTIFACTS.
+func Program(language string, files map[string]string, coverage bool) (string, error) {
+	framework, ok := Frameworks[language]
+	if !ok {
+		return "", fmt.Errorf("tests cannot run for %s (expected %s)", language, strings.Join(Languages(), ", "))
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("no files to test")
+	}
+	for name := range files {
+		if err := checkPath(name); err != nil {
+			return "", err
+		}
+	}
+	data, err := json.Marshal(files)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode files: %v", err)
+	}
+	measure := ""
+	if coverage {
+		measure = "1"
+	}
+	return strings.NewReplacer(
+		"{{FILES}}", base64.StdEncoding.EncodeToString(data),
+		"{{COVERAGE_MARKER}}", CoverageMarker,
+		"{{COVERAGE}}", measure,
+		"{{MARKER}}", ReportMarker,
+	).Replace(framework.program), nil
+}
+
+// checkPath rejects file paths leaving the test directory.
+func checkPath(name string) error {
+	if name == "" || name == "." || path.IsAbs(name) || strings.Contains(name, `\`) || path.Clean(name) != name || name == ".." || strings.HasPrefix(name, "../") {
+		return fmt.Errorf("invalid file path %q: use a relative path inside the test directory, e.g. tests/test_app.py", name)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package testrun
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ylchen07/mcp-executor/internal/executor"
+)
+
+func TestProgram(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		files    map[string]string
+		coverage bool
+		wantErr  bool
+	}{
+		{"python", "python", map[string]string{"calc.py": "", "tests/test_calc.py": ""}, false, false},
+		{"go", "go", map[string]string{"calc.go": "", "calc_test.go": ""}, false, false},
+		{"typescript with coverage", "typescript", map[string]string{"calc.ts": "", "calc.test.ts": ""}, true, false},
+		{"unsupported language", "bash", map[string]string{"test.sh": ""}, false, true},
+		{"no files", "python", nil, false, true},
+		{"absolute path", "python", map[string]string{"/etc/test_x.py": ""}, false, true},
+		{"parent directory", "python", map[string]string{"../test_x.py": ""}, false, true},
+		{"unclean path", "python", map[string]string{"tests/../test_x.py": ""}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Program(tt.language, tt.files, tt.coverage)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Program() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (strings.Contains(program, "{{") || !strings.Contains(program, ReportMarker)) {
+				t.Errorf("Program() left placeholders or has no report marker:\n%s", program)
+			}
+		})
+	}
+}
+
+func TestParse_JUnit(t *testing.T) {
+	output := `.F.s
+1 failed, 2 passed, 1 skipped
+` + ReportMarker + `
+<?xml version="1.0" encoding="utf-8"?><testsuites><testsuite name="pytest" errors="0" failures="1" skipped="1" tests="4">
+<testcase classname="test_calc" name="test_add" time="0.001" />
+<testcase classname="test_calc" name="test_sub" time="0.001"><failure message="assert 1 == 2">def test_sub():
+&gt;       assert sub(3, 1) == 1
+E       assert 2 == 1</failure></testcase>
+<testcase classname="test_calc" name="test_mul" time="0.001" />
+<testcase classname="test_calc" name="test_div" time="0.001"><skipped type="pytest.skip" message="later">skipped</skipped></testcase>
+</testsuite></testsuites>
+`
+	report, runnerOutput, err := Parse("python", output)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	want := Report{Framework: "pytest", Passed: 2, Failed: 1, Skipped: 1, Total: 4, Failures: []Failure{{
+		Test:    "test_sub",
+		Suite:   "test_calc",
+		Message: "assert 1 == 2",
+		Details: "def test_sub():\n>       assert sub(3, 1) == 1\nE       assert 2 == 1",
+	}}}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("Parse() report = %+v, want %+v", report, want)
+	}
+	if runnerOutput != ".F.s\n1 failed, 2 passed, 1 skipped\n" {
+		t.Errorf("Parse() output = %q", runnerOutput)
+	}
+	if got := report.Summary(); got != "pytest: 2 passed, 1 failed, 1 skipped" {
+		t.Errorf("Summary() = %q", got)
+	}
+
+	if _, _, err := Parse("typescript", "npm ERR! could not determine executable to run\n"); err == nil {
+		t.Error("Parse() without a report returned no error")
+	}
+}
+
+func TestParse_GoTest(t *testing.T) {
+	output := ReportMarker + `
+{"Action":"run","Package":"tests","Test":"TestAdd"}
+{"Action":"output","Package":"tests","Test":"TestAdd","Output":"=== RUN   TestAdd\n"}
+{"Action":"pass","Package":"tests","Test":"TestAdd"}
+{"Action":"run","Package":"tests","Test":"TestSub"}
+{"Action":"output","Package":"tests","Test":"TestSub","Output":"=== RUN   TestSub\n"}
+{"Action":"output","Package":"tests","Test":"TestSub","Output":"    calc_test.go:12: Sub(3, 1) = 4, want 2\n"}
+{"Action":"output","Package":"tests","Test":"TestSub","Output":"--- FAIL: TestSub (0.00s)\n"}
+{"Action":"fail","Package":"tests","Test":"TestSub"}
+{"Action":"fail","Package":"tests"}
+# tests/broken
+broken/broken.go:3:1: syntax error: non-declaration statement outside function body
+{"Action":"output","Package":"tests/broken","Output":"FAIL\ttests/broken [setup failed]\n"}
+{"Action":"fail","Package":"tests/broken"}
+`
+	report, _, err := Parse("go", output)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	if report.Passed != 1 || report.Failed != 1 || report.Errors != 1 || report.Total != 3 || len(report.Failures) != 2 {
+		t.Fatalf("Parse() report = %+v, want 1 passed, 1 failed and 1 error", report)
+	}
+	if got := report.Failures[0]; got.Test != "TestSub" || got.Message != "calc_test.go:12: Sub(3, 1) = 4, want 2" {
+		t.Errorf("Failures[0] = %+v", got)
+	}
+	if got := report.Failures[1]; got.Suite != "tests/broken" || !strings.Contains(got.Details, "syntax error") {
+		t.Errorf("Failures[1] = %+v, want the build error of tests/broken", got)
+	}
+}
+
+func TestProgram_GoTest(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not installed")
+	}
+	program, err := Program("go", map[string]string{
+		"calc.go": "package calc\n\nfunc Add(a, b int) int { return a + b }\n",
+		"calc_test.go": `package calc
+
+import "testing"
+
+func TestAdd(t *testing.T) {
+	if Add(1, 2) != 3 {
+		t.Error("Add(1, 2) != 3")
+	}
+}
+
+func TestBroken(t *testing.T) {
+	if Add(2, 2) != 5 {
+		t.Errorf("Add(2, 2) = %d, want 5", Add(2, 2))
+	}
+}
+`,
+	}, true)
+	if err != nil {
+		t.Fatalf("Program() returned error: %v", err)
+	}
+	artifacts := t.TempDir()
+	output, err := executor.NewSubprocessGoExecutor().Execute(context.Background(), program, nil, map[string]string{"GOPROXY": "off", "MCP_ARTIFACTS": artifacts})
+	if err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+	report, _, err := Parse("go", output)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v\n%s", err, output)
+	}
+	if report.Passed != 1 || report.Failed != 1 || report.Failures[0].Message != "calc_test.go:13: Add(2, 2) = 4, want 5" {
+		t.Errorf("Parse() report = %+v, want TestAdd passed and TestBroken failed", report)
+	}
+	wantCoverage := &Coverage{Tool: "go test -cover", Percent: 100, Covered: 1, Statements: 1, Files: []FileCoverage{{File: "calc.go", Percent: 100, Covered: 1, Statements: 1}}}
+	if !reflect.DeepEqual(report.Coverage, wantCoverage) {
+		t.Errorf("Parse() coverage = %+v, want %+v", report.Coverage, wantCoverage)
+	}
+	if table, err := os.ReadFile(filepath.Join(artifacts, "coverage.md")); err != nil || !strings.Contains(string(table), "Add") {
+		t.Errorf("coverage.md = %q (%v), want the coverage of Add", table, err)
+	}
+}
+
+func TestParse_Coverage(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		data     string
+		want     *Coverage
+	}{
+		{
+			name:     "coverage.py",
+			language: "python",
+			data:     `{"meta": {}, "files": {"calc.py": {"summary": {"covered_lines": 5, "num_statements": 6, "percent_covered": 83.33}}, "util.py": {"summary": {"covered_lines": 0, "num_statements": 0}}}}`,
+			want: &Coverage{Tool: "coverage.py", Percent: 83.3, Covered: 5, Statements: 6, Files: []FileCoverage{
+				{File: "calc.py", Percent: 83.3, Covered: 5, Statements: 6},
+				{File: "util.py", Percent: 100},
+			}},
+		},
+		{
+			name:     "go cover profile",
+			language: "go",
+			data:     "mode: set\ncalc.go:3.24,5.2 2 1\ncalc.go:7.24,9.2 1 0\ncalc.go:3.24,5.2 2 0\n",
+			want: &Coverage{Tool: "go test -cover", Percent: 66.7, Covered: 2, Statements: 3, Files: []FileCoverage{
+				{File: "calc.go", Percent: 66.7, Covered: 2, Statements: 3},
+			}},
+		},
+		{
+			name:     "c8 summary",
+			language: "typescript",
+			data:     `{"total": {"statements": {"total": 4, "covered": 3}}, "src/calc.ts": {"statements": {"total": 4, "covered": 3, "pct": 75}}}`,
+			want: &Coverage{Tool: "c8", Percent: 75, Covered: 3, Statements: 4, Files: []FileCoverage{
+				{File: "src/calc.ts", Percent: 75, Covered: 3, Statements: 4},
+			}},
+		},
+	}
+
+	reports := map[string]string{
+		"python":     `<testsuite><testcase classname="test_calc" name="test_add"/></testsuite>`,
+		"go":         `{"Action":"pass","Package":"tests","Test":"TestAdd"}`,
+		"typescript": `<testsuites><testsuite><testcase classname="calc.test.ts" name="adds"/></testsuite></testsuites>`,
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, _, err := Parse(tt.language, ReportMarker+"\n"+reports[tt.language]+"\n"+CoverageMarker+"\n"+tt.data)
+			if err != nil {
+				t.Fatalf("Parse() returned error: %v", err)
+			}
+			if !reflect.DeepEqual(report.Coverage, tt.want) {
+				t.Errorf("Parse() coverage = %+v, want %+v", report.Coverage, tt.want)
+			}
+		})
+	}
+
+	if _, _, err := Parse("go", ReportMarker+"\n"+CoverageMarker+"\ncalc.go:3.24,5.2 two 1\n"); err == nil {
+		t.Error("Parse() of an invalid profile returned no error")
+	}
+}
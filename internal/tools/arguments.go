@@ -0,0 +1,121 @@
+// Package tools provides MCP tool implementations for executing code
+// with a shared parser for the arguments common to every execute tool.
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+)
+
+// argumentSet selects the optional parameters an execute tool declares, so that
+// arguments sent for parameters outside its schema are ignored. The env and
+// timeout, workspace and check_only parameters are declared by every tool.
+type argumentSet struct {
+	dependencies   string // Dependency list parameter, "modules" or "packages"; empty when none are installed
+	container      bool   // The mounts, network, profile and snapshot parameters of Docker mode
+	runtimeVersion bool
+	dependencyFile bool
+	files          bool // The files written next to the code
+	timeLocale     bool // The timezone, locale and fake_time parameters
+	deterministic  bool
+}
+
+// executionArgs are the parsed arguments passed to the executor with the code.
+type executionArgs struct {
+	dependencies []string
+	env          map[string]string
+	options      []executor.Option
+}
+
+// parseExecutionArgs reads the arguments of set from request. Every tool parses
+// them here, so trimming, validation and error messages are the same in all
+// languages; errors are meant to be returned to the client as tool errors.
+func parseExecutionArgs(request mcp.CallToolRequest, set argumentSet) (executionArgs, error) {
+	var args executionArgs
+	var err error
+
+	if set.dependencies != "" {
+		if args.dependencies, err = parseDependencies(request, set.dependencies); err != nil {
+			return executionArgs{}, err
+		}
+	}
+	if args.env, err = parseEnv(request); err != nil {
+		return executionArgs{}, err
+	}
+
+	if set.container {
+		mounts, err := parseMounts(request)
+		if err != nil {
+			return executionArgs{}, err
+		}
+		network, err := parseNetwork(request)
+		if err != nil {
+			return executionArgs{}, err
+		}
+		snapshot := strings.TrimSpace(request.GetString("snapshot", ""))
+		args.options = append(args.options, executor.WithMounts(mounts), executor.WithNetwork(network), executor.WithProfile(parseProfile(request)), executor.WithSnapshot(snapshot))
+	}
+
+	timeout, err := parseTimeout(request)
+	if err != nil {
+		return executionArgs{}, err
+	}
+	args.options = append(args.options, executor.WithTimeout(timeout))
+
+	workspace, err := parseWorkspace(request)
+	if err != nil {
+		return executionArgs{}, err
+	}
+	args.options = append(args.options, executor.WithWorkspace(workspace))
+
+	if request.GetBool("check_only", false) {
+		args.options = append(args.options, executor.WithCheckOnly())
+	}
+
+	if set.runtimeVersion {
+		args.options = append(args.options, executor.WithRuntimeVersion(parseRuntimeVersion(request)))
+	}
+	if set.dependencyFile {
+		args.options = append(args.options, executor.WithDependencyFile(request.GetString("dependency_file", "")))
+	}
+	if _, ok := request.GetArguments()["files"]; set.files && ok {
+		files, err := parseStringObject(request, "files", "file paths to contents")
+		if err != nil {
+			return executionArgs{}, err
+		}
+		args.options = append(args.options, executor.WithFiles(files))
+	}
+	if set.timeLocale {
+		options, err := parseTimeLocale(request)
+		if err != nil {
+			return executionArgs{}, err
+		}
+		args.options = append(args.options, options...)
+	}
+	if set.deterministic && request.GetBool("deterministic", false) {
+		args.options = append(args.options, executor.WithDeterministic())
+	}
+	return args, nil
+}
+
+// parseStringObject reads the required object argument name, whose values
+// must be strings, such as the files of execute-tests. mapping describes its
+// keys and values for the error message, e.g. "file paths to contents".
+func parseStringObject(request mcp.CallToolRequest, name, mapping string) (map[string]string, error) {
+	object, ok := request.GetArguments()[name].(map[string]any)
+	if !ok || len(object) == 0 {
+		return nil, fmt.Errorf("Missing or invalid %s argument: expected an object mapping %s", name, mapping)
+	}
+	values := make(map[string]string, len(object))
+	for key, value := range object {
+		value, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid %s entry %q: expected a string", name, key)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
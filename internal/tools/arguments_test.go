@@ -0,0 +1,180 @@
+package tools
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+)
+
+func TestParseExecutionArgs(t *testing.T) {
+	docker := argumentSet{dependencies: "packages", container: true, runtimeVersion: true, dependencyFile: true, files: true, timeLocale: true, deterministic: true}
+	tests := []struct {
+		name      string
+		set       argumentSet
+		arguments map[string]any
+		wantDeps  []string
+		wantEnv   map[string]string
+		want      executor.Options
+		wantErr   string
+	}{
+		{
+			name:      "no arguments",
+			set:       docker,
+			arguments: map[string]any{},
+			wantEnv:   map[string]string{},
+		},
+		{
+			name: "all arguments",
+			set:  docker,
+			arguments: map[string]any{
+				"packages":        " curl , jq ",
+				"env":             map[string]any{"IDS": "1,2"},
+				"mounts":          "/data:/mnt/data",
+				"network":         " None ",
+				"timeout":         1.5,
+				"runtime_version": " v3.12 ",
+				"dependency_file": "requests\n",
+				"snapshot":        " curl-jq ",
+				"files":           map[string]any{"util.go": "package main\n"},
+				"workspace":       " pipeline ",
+				"profile":         " data-science ",
+				"check_only":      true,
+				"timezone":        " Europe/Berlin ",
+				"locale":          "de_DE.UTF-8",
+				"fake_time":       "2024-02-29T23:59:50Z",
+				"deterministic":   true,
+			},
+			wantDeps: []string{"curl", "jq"},
+			wantEnv:  map[string]string{"IDS": "1,2"},
+			want: executor.Options{
+				Mounts:         []executor.Mount{{Source: "/data", Target: "/mnt/data", ReadOnly: true}},
+				Network:        "none",
+				Timeout:        1500 * time.Millisecond,
+				RuntimeVersion: "3.12",
+				DependencyFile: "requests\n",
+				Snapshot:       "curl-jq",
+				Files:          map[string]string{"util.go": "package main\n"},
+				Workspace:      "pipeline",
+				Profile:        "data-science",
+				CheckOnly:      true,
+				Timezone:       "Europe/Berlin",
+				Locale:         "de_DE.UTF-8",
+				FakeTime:       time.Date(2024, 2, 29, 23, 59, 50, 0, time.UTC),
+				Deterministic:  true,
+			},
+		},
+		{
+			name: "undeclared parameters are ignored",
+			set:  argumentSet{},
+			arguments: map[string]any{
+				"packages":        "curl",
+				"env":             "DEBUG=1",
+				"mounts":          "/data:/mnt/data",
+				"network":         "host",
+				"runtime_version": "3.12",
+				"dependency_file": "requests\n",
+				"files":           map[string]any{"util.go": "package main\n"},
+				"profile":         "data-science",
+				"snapshot":        "curl",
+				"timezone":        "UTC",
+			},
+			wantEnv: map[string]string{"DEBUG": "1"},
+		},
+		{
+			name:      "undeclared invalid arguments are ignored",
+			set:       argumentSet{},
+			arguments: map[string]any{"packages": 1.0, "network": "overlay"},
+			wantEnv:   map[string]string{},
+		},
+		{
+			name:      "invalid dependencies",
+			set:       docker,
+			arguments: map[string]any{"packages": []any{"curl", true}},
+			wantErr:   "invalid packages",
+		},
+		{
+			name:      "invalid env",
+			set:       docker,
+			arguments: map[string]any{"env": map[string]any{"DEBUG": 1.0}},
+			wantErr:   "invalid env",
+		},
+		{
+			name:      "invalid mount",
+			set:       docker,
+			arguments: map[string]any{"mounts": "/data"},
+			wantErr:   "invalid mount",
+		},
+		{
+			name:      "invalid network",
+			set:       docker,
+			arguments: map[string]any{"network": "overlay"},
+			wantErr:   "invalid network",
+		},
+		{
+			name:      "invalid files",
+			set:       docker,
+			arguments: map[string]any{"files": map[string]any{"util.go": 1.0}},
+			wantErr:   "invalid files",
+		},
+		{
+			name:      "invalid timeout",
+			set:       docker,
+			arguments: map[string]any{"timeout": -1.0},
+			wantErr:   "invalid timeout",
+		},
+		{
+			name:      "invalid timezone",
+			set:       docker,
+			arguments: map[string]any{"timezone": "Europe/../etc"},
+			wantErr:   "invalid timezone",
+		},
+		{
+			name:      "invalid locale",
+			set:       docker,
+			arguments: map[string]any{"locale": "en_US.UTF-8; rm -rf /"},
+			wantErr:   "invalid locale",
+		},
+		{
+			name:      "invalid fake time",
+			set:       docker,
+			arguments: map[string]any{"fake_time": "yesterday"},
+			wantErr:   "invalid fake_time",
+		},
+		{
+			name:      "invalid workspace",
+			set:       argumentSet{},
+			arguments: map[string]any{"workspace": "../data"},
+			wantErr:   "invalid workspace",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: tt.arguments}}
+			args, err := parseExecutionArgs(request, tt.set)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseExecutionArgs() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseExecutionArgs() unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(args.dependencies, tt.wantDeps) {
+				t.Errorf("dependencies = %q, want %q", args.dependencies, tt.wantDeps)
+			}
+			if !reflect.DeepEqual(args.env, tt.wantEnv) {
+				t.Errorf("env = %v, want %v", args.env, tt.wantEnv)
+			}
+			if got := executor.NewOptions(args.options...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("options = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,7 +4,6 @@ package tools
 
 import (
 	"context"
-	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/ylchen07/mcp-executor/internal/executor"
@@ -25,7 +24,7 @@ func (b *BashTool) CreateTool() mcp.Tool {
 	description := `Execute bash/shell commands in an isolated Docker container (Ubuntu 22.04). System packages can be dynamically installed.
 Use this tool when you need to run shell commands, system utilities, or require specific command-line tools.
 Only output printed to stdout or stderr is returned so make sure commands produce output!
-Note: Code runs in ephemeral containers - files and state do NOT persist between executions.`
+Note: Code runs in ephemeral containers - files and state do NOT persist between executions, except files in a shared workspace.`
 
 	return mcp.NewTool(
 		"execute-bash",
@@ -35,15 +34,61 @@ Note: Code runs in ephemeral containers - files and state do NOT persist between
 			mcp.Description("The bash script or commands to execute"),
 			mcp.Required(),
 		),
-		mcp.WithString(
+		WithDependencies(
 			"packages",
-			mcp.Description(`Comma-separated list of Ubuntu packages to install (e.g., 'curl,jq,git').
+			mcp.Description(`JSON array or comma-separated list of Ubuntu packages to install (e.g., 'curl,jq,git').
 Packages are installed automatically via apt-get before script execution.`),
 		),
+		WithEnv(envDescription("bash script")),
+		WithTimeLocale(),
+		mcp.WithString(
+			"mounts",
+			mcp.Description(mountsDescription),
+		),
+		mcp.WithString(
+			"network",
+			mcp.Description(networkDescription),
+		),
+		mcp.WithString(
+			"snapshot",
+			mcp.Description(snapshotDescription),
+		),
+		mcp.WithNumber(
+			"timeout",
+			mcp.Description(timeoutDescription),
+		),
+		mcp.WithString(
+			"workspace",
+			mcp.Description(workspaceDescription),
+		),
 		mcp.WithString(
-			"env",
-			mcp.Description(`Comma-separated list of environment variables in KEY=VALUE format (e.g., 'API_KEY=secret,DEBUG=true').
-These will be available to your bash script.`),
+			StdinFromArgument,
+			mcp.Description(stdinFromDescription),
+		),
+		mcp.WithString(
+			"priority",
+			mcp.Description(priorityDescription),
+			mcp.Enum("interactive", "batch"),
+		),
+		mcp.WithBoolean(
+			"parse_output",
+			mcp.Description(parseOutputDescription),
+		),
+		mcp.WithBoolean(
+			"check_only",
+			mcp.Description(checkOnlyDescription),
+		),
+		mcp.WithBoolean(
+			"deterministic",
+			mcp.Description(deterministicDescription),
+		),
+		mcp.WithString(
+			"runtime_version",
+			mcp.Description(runtimeVersionDescription),
+		),
+		mcp.WithString(
+			"profile",
+			mcp.Description(profileDescription),
 		),
 	)
 }
@@ -57,47 +102,36 @@ func (b *BashTool) HandleExecution(
 	script, err := request.RequireString("script")
 	if err != nil {
 		logger.Debug("Bash tool execution failed: missing script argument")
-		return mcp.NewToolResultError("Missing or invalid script argument"), nil
-	}
-
-	var packages []string
-	if packagesStr := request.GetString("packages", ""); packagesStr != "" {
-		packages = strings.Split(packagesStr, ",")
-		// Clean up package names (trim whitespace)
-		for i, pkg := range packages {
-			packages[i] = strings.TrimSpace(pkg)
-		}
-		logger.Debug("Bash packages requested: %v", packages)
-	}
-
-	// Parse environment variables
-	envVars := make(map[string]string)
-	if envStr := request.GetString("env", ""); envStr != "" {
-		envPairs := strings.SplitSeq(envStr, ",")
-		for pair := range envPairs {
-			pair = strings.TrimSpace(pair)
-			if equalIndex := strings.Index(pair, "="); equalIndex > 0 {
-				key := strings.TrimSpace(pair[:equalIndex])
-				value := strings.TrimSpace(pair[equalIndex+1:])
-				envVars[key] = value
-			}
-		}
-		logger.Debug("Bash environment variables: %v", envVars)
-	}
-
-	output, err := b.executor.Execute(ctx, script, packages, envVars)
+		return ErrorResult(executor.ErrorPolicyViolation, "Missing or invalid script argument"), nil
+	}
+
+	args, err := parseExecutionArgs(request, argumentSet{dependencies: "packages", container: true, runtimeVersion: true, timeLocale: true, deterministic: true})
+	if err != nil {
+		logger.Debug("Bash tool execution failed: %v", err)
+		return ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+	}
+	if len(args.dependencies) > 0 {
+		logger.Debug("Bash packages requested: %v", args.dependencies)
+	}
+	if len(args.env) > 0 {
+		logger.Debug("Bash environment variables: %v", args.env)
+	}
+
+	output, err := b.executor.Execute(ctx, script, args.dependencies, args.env, args.options...)
 	if err != nil {
 		logger.Debug("Bash execution failed: %v", err)
-		return mcp.NewToolResultError(err.Error()), nil
+		return executionErrorResult(err), nil
 	}
 
 	logger.Debug("Bash execution completed successfully")
-	return mcp.NewToolResultText(output), nil
+	return executionResult(request, output), nil
 }
 
-// SubprocessBashTool executes bash commands on the host system without package installation support
+// SubprocessBashTool executes bash commands on the host system, with packages only
+// when its executor provides them in a throwaway environment
 type SubprocessBashTool struct {
 	executor executor.Executor
+	packages bool
 }
 
 func NewSubprocessBashTool(exec executor.Executor) *SubprocessBashTool {
@@ -106,26 +140,69 @@ func NewSubprocessBashTool(exec executor.Executor) *SubprocessBashTool {
 	}
 }
 
+// NewSubprocessBashToolWithPackages returns a subprocess Bash tool accepting packages, for
+// executors providing them in throwaway environments such as nix-shell.
+func NewSubprocessBashToolWithPackages(exec executor.Executor) *SubprocessBashTool {
+	return &SubprocessBashTool{
+		executor: exec,
+		packages: true,
+	}
+}
+
 func (b *SubprocessBashTool) CreateTool() mcp.Tool {
 	description := `Execute bash/shell commands directly on the host system. Only pre-installed system utilities are available.
 Use this tool when you need to run shell commands or interact with the host filesystem.
 Only output printed to stdout or stderr is returned so make sure commands produce output!
 Note: Code runs on the host system with user permissions.`
 
-	return mcp.NewTool(
-		"execute-bash",
+	options := []mcp.ToolOption{
 		mcp.WithDescription(description),
 		mcp.WithString(
 			"script",
 			mcp.Description("The bash script or commands to execute"),
 			mcp.Required(),
 		),
+		WithEnv(envDescription("bash script")),
+		WithTimeLocale(),
+		mcp.WithNumber(
+			"timeout",
+			mcp.Description(timeoutDescription),
+		),
 		mcp.WithString(
-			"env",
-			mcp.Description(`Comma-separated list of environment variables in KEY=VALUE format (e.g., 'API_KEY=secret,DEBUG=true').
-These will be available to your bash script.`),
+			"workspace",
+			mcp.Description(workspaceDescription),
 		),
-	)
+		mcp.WithString(
+			StdinFromArgument,
+			mcp.Description(stdinFromDescription),
+		),
+		mcp.WithString(
+			"priority",
+			mcp.Description(priorityDescription),
+			mcp.Enum("interactive", "batch"),
+		),
+		mcp.WithBoolean(
+			"parse_output",
+			mcp.Description(parseOutputDescription),
+		),
+		mcp.WithBoolean(
+			"check_only",
+			mcp.Description(checkOnlyDescription),
+		),
+		mcp.WithBoolean(
+			"deterministic",
+			mcp.Description(deterministicDescription),
+		),
+	}
+	if b.packages {
+		options = append(options, WithDependencies(
+			"packages",
+			mcp.Description(`JSON array or comma-separated list of Nix packages (e.g. 'jq,ripgrep') provided in a throwaway nix-shell.
+They are available to your script and do NOT persist between executions.`),
+		))
+	}
+
+	return mcp.NewTool("execute-bash", options...)
 }
 
 func (b *SubprocessBashTool) HandleExecution(
@@ -137,31 +214,32 @@ func (b *SubprocessBashTool) HandleExecution(
 	script, err := request.RequireString("script")
 	if err != nil {
 		logger.Debug("Subprocess Bash tool execution failed: missing script argument")
-		return mcp.NewToolResultError("Missing or invalid script argument"), nil
-	}
-
-	// Parse environment variables
-	envVars := make(map[string]string)
-	if envStr := request.GetString("env", ""); envStr != "" {
-		envPairs := strings.SplitSeq(envStr, ",")
-		for pair := range envPairs {
-			pair = strings.TrimSpace(pair)
-			if equalIndex := strings.Index(pair, "="); equalIndex > 0 {
-				key := strings.TrimSpace(pair[:equalIndex])
-				value := strings.TrimSpace(pair[equalIndex+1:])
-				envVars[key] = value
-			}
-		}
-		logger.Debug("Subprocess Bash environment variables: %v", envVars)
-	}
-
-	// No package installation for subprocess mode - pass empty slice
-	output, err := b.executor.Execute(ctx, script, nil, envVars)
+		return ErrorResult(executor.ErrorPolicyViolation, "Missing or invalid script argument"), nil
+	}
+
+	// Packages are only provided by executors with throwaway environments
+	set := argumentSet{timeLocale: true, deterministic: true}
+	if b.packages {
+		set.dependencies = "packages"
+	}
+	args, err := parseExecutionArgs(request, set)
+	if err != nil {
+		logger.Debug("Subprocess Bash tool execution failed: %v", err)
+		return ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+	}
+	if len(args.dependencies) > 0 {
+		logger.Debug("Subprocess Bash packages requested: %v", args.dependencies)
+	}
+	if len(args.env) > 0 {
+		logger.Debug("Subprocess Bash environment variables: %v", args.env)
+	}
+
+	output, err := b.executor.Execute(ctx, script, args.dependencies, args.env, args.options...)
 	if err != nil {
 		logger.Debug("Subprocess Bash execution failed: %v", err)
-		return mcp.NewToolResultError(err.Error()), nil
+		return executionErrorResult(err), nil
 	}
 
 	logger.Debug("Subprocess Bash execution completed successfully")
-	return mcp.NewToolResultText(output), nil
+	return executionResult(request, output), nil
 }
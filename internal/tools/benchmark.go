@@ -0,0 +1,140 @@
+// Package tools provides MCP tool implementations for executing code
+// with the execute-benchmark tool timing code snippets against each other.
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/benchmark"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// BenchmarkTool times snippets of code with the executors of the execute tools.
+type BenchmarkTool struct {
+	executors map[string]executor.Executor // By language
+}
+
+func NewBenchmarkTool(executors map[string]executor.Executor) *BenchmarkTool {
+	return &BenchmarkTool{
+		executors: executors,
+	}
+}
+
+// languages returns the languages of the tool: those with a timing method and an executor.
+func (b *BenchmarkTool) languages() []string {
+	var languages []string
+	for _, language := range benchmark.Languages() {
+		if b.executors[language] != nil {
+			languages = append(languages, language)
+		}
+	}
+	return languages
+}
+
+func (b *BenchmarkTool) CreateTool() mcp.Tool {
+	description := `Benchmark code snippets against each other to find out reliably which implementation is faster.
+Each snippet runs in a loop of at least 100ms, repeated runs times after a warm-up: with timeit in Python, go test -bench in Go and a process.hrtime loop in TypeScript.
+The result gives the mean, median, standard deviation and 95% confidence interval per loop of each snippet, fastest first, with its time relative to the fastest
+and whether it is slower beyond noise. Run the snippets of one comparison in a single call, so that they are timed under the same conditions.`
+
+	return mcp.NewTool(
+		"execute-benchmark",
+		mcp.WithDescription(description),
+		mcp.WithString(
+			"language",
+			mcp.Description("Language of the snippets"),
+			mcp.Enum(b.languages()...),
+			mcp.Required(),
+		),
+		mcp.WithObject(
+			"snippets",
+			mcp.Description(`Snippets to time keyed by name, e.g. {"join": "''.join(parts)", "concat": "s = ''\nfor p in parts: s += p"}.
+Python snippets are timeit statements, Go and TypeScript snippets statements of a loop body. Keep results alive (e.g. assign them to a package-level variable in Go) so the compiler does not remove the work.`),
+			mcp.AdditionalProperties(map[string]any{"type": "string"}),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"setup",
+			mcp.Description(`Code run once before the timings, shared by the snippets and not timed: the timeit setup in Python,
+top-level source such as imports, helpers and test data in Go (after package and a "testing" import) and TypeScript`),
+		),
+		mcp.WithNumber(
+			"runs",
+			mcp.Description(fmt.Sprintf("Timed runs of each snippet, between %d and %d (default %d)", benchmark.MinRuns, benchmark.MaxRuns, benchmark.DefaultRuns)),
+		),
+		WithDependencies(
+			"packages",
+			mcp.Description("JSON array or comma-separated list of packages the snippets need, installed as by the execute tool of the language"),
+		),
+		WithEnv(envDescription("snippets")),
+		mcp.WithNumber(
+			"timeout",
+			mcp.Description(timeoutDescription),
+		),
+		mcp.WithString(
+			"workspace",
+			mcp.Description(workspaceDescription),
+		),
+		mcp.WithString(
+			"priority",
+			mcp.Description(priorityDescription),
+			mcp.Enum("interactive", "batch"),
+		),
+	)
+}
+
+func (b *BenchmarkTool) HandleExecution(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	logger.Debug("Benchmark tool execution requested")
+
+	language, err := request.RequireString("language")
+	if err != nil || !slices.Contains(b.languages(), language) {
+		logger.Debug("Benchmark tool execution failed: invalid language argument")
+		return ErrorResult(executor.ErrorPolicyViolation, fmt.Sprintf("Missing or invalid language argument (expected one of %v)", b.languages())), nil
+	}
+	snippets, err := parseStringObject(request, "snippets", "snippet names to code")
+	if err != nil {
+		logger.Debug("Benchmark tool execution failed: %v", err)
+		return ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+	}
+	runs := request.GetFloat("runs", benchmark.DefaultRuns)
+	if runs != float64(int(runs)) {
+		logger.Debug("Benchmark tool execution failed: invalid runs argument")
+		return ErrorResult(executor.ErrorPolicyViolation, fmt.Sprintf("invalid runs %v: must be a whole number", runs)), nil
+	}
+	program, err := benchmark.Program(language, request.GetString("setup", ""), snippets, int(runs))
+	if err != nil {
+		logger.Debug("Benchmark tool execution failed: %v", err)
+		return ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+	}
+
+	args, err := parseExecutionArgs(request, argumentSet{dependencies: "packages"})
+	if err != nil {
+		logger.Debug("Benchmark tool execution failed: %v", err)
+		return ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+	}
+
+	output, err := b.executors[language].Execute(ctx, program, args.dependencies, args.env, args.options...)
+	if err != nil {
+		logger.Debug("Benchmark execution failed: %v", err)
+		return executionErrorResult(err), nil
+	}
+	result, codeOutput, err := benchmark.Parse(language, output)
+	if err != nil {
+		logger.Debug("Benchmark execution failed: %v", err)
+		return executionErrorResult(executor.NewExecutionError(executor.ErrorRuntime, "%v: %s", err, output)), nil
+	}
+
+	logger.Debug("Benchmark execution completed")
+	text := result.Summary()
+	if codeOutput != "\n" {
+		text += "\nOutput:\n" + codeOutput
+	}
+	return mcp.NewToolResultStructured(result, text), nil
+}
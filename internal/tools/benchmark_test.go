@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/benchmark"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+)
+
+func TestBenchmarkTool_HandleExecution(t *testing.T) {
+	mockExec := &mockExecutor{
+		executeFunc: func(ctx context.Context, code string, dependencies []string, envVars map[string]string) (string, error) {
+			return benchmark.Marker + `[{"name": "sum", "loops": 100, "samples_ns": [10, 12]}]`, nil
+		},
+	}
+	tool := NewBenchmarkTool(map[string]executor.Executor{"python": mockExec})
+
+	tests := []struct {
+		name      string
+		arguments map[string]any
+		wantCode  string
+	}{
+		{"timed", map[string]any{"language": "python", "snippets": map[string]any{"sum": "sum(items)"}, "setup": "items = [1]", "runs": 2.0}, ""},
+		{"fractional runs", map[string]any{"language": "python", "snippets": map[string]any{"sum": "sum(items)"}, "runs": 2.5}, executor.ErrorPolicyViolation},
+		{"too many runs", map[string]any{"language": "python", "snippets": map[string]any{"sum": "sum(items)"}, "runs": 1000.0}, executor.ErrorPolicyViolation},
+		{"snippet not a string", map[string]any{"language": "python", "snippets": map[string]any{"sum": 1}}, executor.ErrorPolicyViolation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.HandleExecution(context.Background(), mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: "execute-benchmark", Arguments: tt.arguments},
+			})
+			if err != nil {
+				t.Fatalf("HandleExecution() returned error: %v", err)
+			}
+			if tt.wantCode != "" {
+				if !result.IsError || !reflect.DeepEqual(result.Meta.AdditionalFields[errorMetaKey], map[string]any{"code": tt.wantCode}) {
+					t.Errorf("Result = %+v, want a %s error", result, tt.wantCode)
+				}
+				return
+			}
+			timings, ok := result.StructuredContent.(benchmark.Result)
+			if result.IsError || !ok || len(timings.Snippets) != 1 || timings.Snippets[0].MeanNs != 11 {
+				t.Errorf("Result = %+v, want the timings of sum", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Package tools provides MCP tool implementations for executing code
+// with the browse-web tool visiting web pages with a headless browser.
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/browse"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// BrowseTool visits web pages with Playwright, through the executor of the
+// Python execute tool.
+type BrowseTool struct {
+	executor executor.Executor
+}
+
+func NewBrowseTool(exec executor.Executor) *BrowseTool {
+	return &BrowseTool{
+		executor: exec,
+	}
+}
+
+func (b *BrowseTool) CreateTool() mcp.Tool {
+	description := `Visit a web page with a headless Chromium browser and get its text, the text of the elements matching a CSS selector, or a screenshot.
+Pages are rendered with JavaScript, so use it for pages that plain HTTP requests cannot read. For anything beyond reading a page,
+such as filling in forms or following several links, write a Playwright script for execute-python instead.
+The result gives the title, final URL and HTTP status of the page, followed by the extracted text; screenshots are returned as PNG images.`
+
+	return mcp.NewTool(
+		"browse-web",
+		mcp.WithDescription(description),
+		mcp.WithString(
+			"url",
+			mcp.Description("The http or https URL of the page to visit"),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"selector",
+			mcp.Description("CSS selector of the elements whose text is extracted, e.g. 'h2.title' or 'table tr'. Defaults to the text of the whole page."),
+		),
+		mcp.WithString(
+			"wait_for",
+			mcp.Description("CSS selector of an element to wait for after the page loaded, for pages rendering their content with JavaScript"),
+		),
+		mcp.WithBoolean(
+			"screenshot",
+			mcp.Description("Also take a screenshot: of the first element matching selector, otherwise of the visible part of the page"),
+		),
+		mcp.WithBoolean(
+			"full_page",
+			mcp.Description("Take the screenshot of the whole scrollable page instead of the visible part"),
+		),
+		mcp.WithNumber(
+			"timeout",
+			mcp.Description(timeoutDescription),
+		),
+		mcp.WithString(
+			"priority",
+			mcp.Description(priorityDescription),
+			mcp.Enum("interactive", "batch"),
+		),
+	)
+}
+
+func (b *BrowseTool) HandleExecution(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	logger.Debug("Browse tool execution requested")
+
+	target, err := request.RequireString("url")
+	if err != nil {
+		logger.Debug("Browse tool execution failed: missing url argument")
+		return ErrorResult(executor.ErrorPolicyViolation, "Missing or invalid url argument"), nil
+	}
+	program, err := browse.Program(browse.Request{
+		URL:        strings.TrimSpace(target),
+		Selector:   strings.TrimSpace(request.GetString("selector", "")),
+		WaitFor:    strings.TrimSpace(request.GetString("wait_for", "")),
+		Screenshot: request.GetBool("screenshot", false),
+		FullPage:   request.GetBool("full_page", false),
+	})
+	if err != nil {
+		logger.Debug("Browse tool execution failed: %v", err)
+		return ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+	}
+
+	args, err := parseExecutionArgs(request, argumentSet{})
+	if err != nil {
+		logger.Debug("Browse tool execution failed: %v", err)
+		return ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+	}
+
+	output, err := b.executor.Execute(ctx, program, nil, args.env, args.options...)
+	if err != nil {
+		logger.Debug("Browse execution failed: %v", err)
+		return executionErrorResult(err), nil
+	}
+	page, programOutput, err := browse.Parse(output)
+	if err != nil {
+		logger.Debug("Browse execution failed: %v", err)
+		return executionErrorResult(executor.NewExecutionError(executor.ErrorRuntime, "%v: %s", err, output)), nil
+	}
+
+	logger.Debug("Browse execution completed: %s", page.URL)
+	text := page.Summary()
+	if programOutput != "" {
+		text += "\nOutput:\n" + programOutput + "\n"
+	}
+	result := mcp.NewToolResultStructured(page, text)
+	if page.Screenshot != nil {
+		result.Content = append(result.Content, mcp.NewImageContent(base64.StdEncoding.EncodeToString(page.Screenshot), "image/png"))
+	}
+	return result, nil
+}
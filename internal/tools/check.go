@@ -0,0 +1,24 @@
+// Package tools provides MCP tool implementations for executing code
+// with shared helpers for the check_only parameter.
+package tools
+
+import (
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const checkOnlyDescription = `Check the code without running it: Python is byte-compiled (py_compile), Bash parsed (bash -n),
+Go vetted (go vet) and TypeScript type-checked (tsc --noEmit). Problems fail the call with a compile_error and their
+file, line and column. A fast and safe way to validate code before running it.`
+
+// checkPassedOutput is returned by checks that found no problem and printed nothing.
+const checkPassedOutput = "Check passed: no problems found\n"
+
+// checkOutput returns the output of a check_only call.
+func checkOutput(request mcp.CallToolRequest, output string) string {
+	if request.GetBool("check_only", false) && strings.TrimSpace(output) == "" {
+		return checkPassedOutput
+	}
+	return output
+}
@@ -0,0 +1,53 @@
+// Package tools provides MCP tool implementations for executing code
+// with shared helpers for the dependency list parameters.
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithDependencies adds a dependency list parameter such as "modules" or
+// "packages", which accepts either a JSON array of strings or a comma-separated
+// string. It takes the same property options as mcp.WithString.
+func WithDependencies(name string, opts ...mcp.PropertyOption) mcp.ToolOption {
+	return mcp.WithAny(name, append(opts, func(schema map[string]any) {
+		schema["anyOf"] = []any{
+			map[string]any{"type": "string"},
+			map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		}
+	})...)
+}
+
+// parseDependencies reads the optional dependency list argument name in either
+// of its forms. Entries are trimmed and empty entries are dropped.
+func parseDependencies(request mcp.CallToolRequest, name string) ([]string, error) {
+	var entries []string
+	switch value := request.GetArguments()[name].(type) {
+	case nil:
+	case string:
+		entries = strings.Split(value, ",")
+	case []any:
+		for _, entry := range value {
+			entry, ok := entry.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid %s: entries must be strings", name)
+			}
+			entries = append(entries, entry)
+		}
+	case []string:
+		entries = value
+	default:
+		return nil, fmt.Errorf("invalid %s: expected a comma-separated string or an array of strings", name)
+	}
+
+	var dependencies []string
+	for _, entry := range entries {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			dependencies = append(dependencies, entry)
+		}
+	}
+	return dependencies, nil
+}
@@ -0,0 +1,7 @@
+// Package tools provides MCP tool implementations for executing code
+// with the description of the deterministic parameter.
+package tools
+
+const deterministicDescription = `Make the execution reproducible for debugging: random seeds are fixed (PYTHONHASHSEED, Go's math/rand, and
+MCP_EXECUTOR_SEED for code seeding its own generators), the clock starts at 2000-01-01T00:00:00Z unless fake_time is set
+and containers get no network, so no packages can be installed. Set MCP_EXECUTOR_SEED in env to replay with another seed.`
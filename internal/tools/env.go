@@ -0,0 +1,71 @@
+// Package tools provides MCP tool implementations for executing code
+// with shared helpers for the environment variable parameter.
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// WithEnv adds the "env" parameter, which accepts either a comma-separated
+// KEY=VALUE string or a JSON object mapping names to string values. The object
+// form can hold values containing commas.
+func WithEnv(description string) mcp.ToolOption {
+	return mcp.WithAny(
+		"env",
+		mcp.Description(description),
+		func(schema map[string]any) {
+			schema["anyOf"] = []any{
+				map[string]any{"type": "string"},
+				map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+			}
+		},
+	)
+}
+
+// envDescription describes the env parameter for code of the given kind.
+func envDescription(subject string) string {
+	return fmt.Sprintf(`Environment variables as a JSON object (e.g., {"API_KEY": "secret", "IDS": "1,2,3"}) or
+a comma-separated list in KEY=VALUE format (e.g., 'API_KEY=secret,DEBUG=true'); use the object form for values containing commas.
+These will be available to your %s.`, subject)
+}
+
+// parseEnv reads the optional "env" argument in either of its forms. Entries
+// of the string form without a key are ignored, as are empty keys of the
+// object form.
+func parseEnv(request mcp.CallToolRequest) (map[string]string, error) {
+	envVars := make(map[string]string)
+	switch env := request.GetArguments()["env"].(type) {
+	case nil:
+	case string:
+		for pair := range strings.SplitSeq(env, ",") {
+			pair = strings.TrimSpace(pair)
+			if equalIndex := strings.Index(pair, "="); equalIndex > 0 {
+				key := strings.TrimSpace(pair[:equalIndex])
+				value := strings.TrimSpace(pair[equalIndex+1:])
+				envVars[key] = value
+			}
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(env))
+		for key := range env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, ok := env[key].(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid env: value of %q must be a string", key)
+			}
+			if key = strings.TrimSpace(key); key != "" {
+				envVars[key] = value
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid env: expected a KEY=VALUE string or an object of strings")
+	}
+	return envVars, nil
+}
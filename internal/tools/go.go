@@ -4,13 +4,17 @@ package tools
 
 import (
 	"context"
-	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/ylchen07/mcp-executor/internal/executor"
 	"github.com/ylchen07/mcp-executor/internal/logger"
 )
 
+// filesDescription describes the "files" parameter of the Go tools.
+const filesDescription = `Other files of the program keyed by relative path, e.g. {"util.go": "package main ...", "store/store.go": "package store ..."}.
+They are written next to the code, saved as main.go, and build with it as a module named "sandbox" unless a go.mod in dependency_file names another,
+so packages in subdirectories are imported as "sandbox/store".`
+
 type GoTool struct {
 	executor executor.Executor
 }
@@ -25,8 +29,8 @@ func (g *GoTool) CreateTool() mcp.Tool {
 	description := `Execute Go code in an isolated Docker container.
 External packages can be dynamically installed via go get. Use this tool when you need real-time information or require external Go packages.
 Only output printed to stdout or stderr is returned so ALWAYS use print/fmt.Println statements!
-Note: Code runs in ephemeral containers - packages and state do NOT persist between executions.
-Your code must include a main package and main function.`
+Note: Code runs in ephemeral containers - packages and state do NOT persist between executions, except files in a shared workspace.
+Your code must include a main package and main function. It runs as a Go module: split larger programs with files, and pin module versions with a go.mod in dependency_file.`
 
 	return mcp.NewTool(
 		"execute-go",
@@ -36,15 +40,71 @@ Your code must include a main package and main function.`
 			mcp.Description("The Go code to execute (must include package main and func main)"),
 			mcp.Required(),
 		),
-		mcp.WithString(
+		WithDependencies(
 			"packages",
-			mcp.Description(`Comma-separated list of Go packages to install (e.g., 'github.com/gorilla/mux,github.com/gin-gonic/gin').
+			mcp.Description(`JSON array or comma-separated list of Go packages to install (e.g., 'github.com/gorilla/mux,github.com/gin-gonic/gin').
 Packages are installed automatically via go get before code execution.`),
 		),
+		WithEnv(envDescription("Go code")),
+		WithTimeLocale(),
+		mcp.WithString(
+			"mounts",
+			mcp.Description(mountsDescription),
+		),
 		mcp.WithString(
-			"env",
-			mcp.Description(`Comma-separated list of environment variables in KEY=VALUE format (e.g., 'API_KEY=secret,DEBUG=true').
-These will be available to your Go code.`),
+			"network",
+			mcp.Description(networkDescription),
+		),
+		mcp.WithString(
+			"snapshot",
+			mcp.Description(snapshotDescription),
+		),
+		mcp.WithNumber(
+			"timeout",
+			mcp.Description(timeoutDescription),
+		),
+		mcp.WithString(
+			"workspace",
+			mcp.Description(workspaceDescription),
+		),
+		mcp.WithString(
+			StdinFromArgument,
+			mcp.Description(stdinFromDescription),
+		),
+		mcp.WithString(
+			"priority",
+			mcp.Description(priorityDescription),
+			mcp.Enum("interactive", "batch"),
+		),
+		mcp.WithBoolean(
+			"parse_output",
+			mcp.Description(parseOutputDescription),
+		),
+		mcp.WithBoolean(
+			"check_only",
+			mcp.Description(checkOnlyDescription),
+		),
+		mcp.WithBoolean(
+			"deterministic",
+			mcp.Description(deterministicDescription),
+		),
+		mcp.WithString(
+			"runtime_version",
+			mcp.Description(runtimeVersionDescription),
+		),
+		mcp.WithString(
+			"profile",
+			mcp.Description(profileDescription),
+		),
+		mcp.WithString(
+			"dependency_file",
+			mcp.Description(dependencyFileDescription("go.mod")+`
+Its module path, go version and require directives apply; imports missing from it are added at their latest version.`),
+		),
+		mcp.WithObject(
+			"files",
+			mcp.Description(filesDescription),
+			mcp.AdditionalProperties(map[string]any{"type": "string"}),
 		),
 	)
 }
@@ -58,43 +118,36 @@ func (g *GoTool) HandleExecution(
 	code, err := request.RequireString("code")
 	if err != nil {
 		logger.Debug("Go tool execution failed: missing code argument")
-		return mcp.NewToolResultError("Missing or invalid code argument"), nil
+		return ErrorResult(executor.ErrorPolicyViolation, "Missing or invalid code argument"), nil
 	}
 
-	var packages []string
-	if packagesStr := request.GetString("packages", ""); packagesStr != "" {
-		packages = strings.Split(packagesStr, ",")
-		logger.Debug("Go packages requested: %v", packages)
+	args, err := parseExecutionArgs(request, argumentSet{dependencies: "packages", container: true, runtimeVersion: true, dependencyFile: true, files: true, timeLocale: true, deterministic: true})
+	if err != nil {
+		logger.Debug("Go tool execution failed: %v", err)
+		return ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
 	}
-
-	// Parse environment variables
-	envVars := make(map[string]string)
-	if envStr := request.GetString("env", ""); envStr != "" {
-		envPairs := strings.SplitSeq(envStr, ",")
-		for pair := range envPairs {
-			pair = strings.TrimSpace(pair)
-			if equalIndex := strings.Index(pair, "="); equalIndex > 0 {
-				key := strings.TrimSpace(pair[:equalIndex])
-				value := strings.TrimSpace(pair[equalIndex+1:])
-				envVars[key] = value
-			}
-		}
-		logger.Debug("Go environment variables: %v", envVars)
+	if len(args.dependencies) > 0 {
+		logger.Debug("Go packages requested: %v", args.dependencies)
+	}
+	if len(args.env) > 0 {
+		logger.Debug("Go environment variables: %v", args.env)
 	}
 
-	output, err := g.executor.Execute(ctx, code, packages, envVars)
+	output, err := g.executor.Execute(ctx, code, args.dependencies, args.env, args.options...)
 	if err != nil {
 		logger.Debug("Go execution failed: %v", err)
-		return mcp.NewToolResultError(err.Error()), nil
+		return executionErrorResult(err), nil
 	}
 
 	logger.Debug("Go execution completed successfully")
-	return mcp.NewToolResultText(output), nil
+	return executionResult(request, output), nil
 }
 
-// SubprocessGoTool executes Go code on the host system without package installation support
+// SubprocessGoTool executes Go code on the host system, with packages only
+// when its executor provides them in a throwaway environment
 type SubprocessGoTool struct {
 	executor executor.Executor
+	packages bool
 }
 
 func NewSubprocessGoTool(exec executor.Executor) *SubprocessGoTool {
@@ -103,27 +156,82 @@ func NewSubprocessGoTool(exec executor.Executor) *SubprocessGoTool {
 	}
 }
 
+// NewSubprocessGoToolWithPackages returns a subprocess Go tool accepting packages, for
+// executors providing them in throwaway environments such as nix-shell.
+func NewSubprocessGoToolWithPackages(exec executor.Executor) *SubprocessGoTool {
+	return &SubprocessGoTool{
+		executor: exec,
+		packages: true,
+	}
+}
+
 func (g *SubprocessGoTool) CreateTool() mcp.Tool {
 	description := `Execute Go code directly on the host system. Only standard library and pre-installed packages are available.
 Use this tool when you need real-time information and don't require external dependencies.
 Only output printed to stdout or stderr is returned so ALWAYS use print/fmt.Println statements!
 Note: Code runs on the host system with user permissions.
-Your code must include a main package and main function.`
+Your code must include a main package and main function; split larger programs with files.`
 
-	return mcp.NewTool(
-		"execute-go",
+	options := []mcp.ToolOption{
 		mcp.WithDescription(description),
 		mcp.WithString(
 			"code",
 			mcp.Description("The Go code to execute (must include package main and func main)"),
 			mcp.Required(),
 		),
+		WithEnv(envDescription("Go code")),
+		WithTimeLocale(),
+		mcp.WithNumber(
+			"timeout",
+			mcp.Description(timeoutDescription),
+		),
 		mcp.WithString(
-			"env",
-			mcp.Description(`Comma-separated list of environment variables in KEY=VALUE format (e.g., 'API_KEY=secret,DEBUG=true').
-These will be available to your Go code.`),
+			"workspace",
+			mcp.Description(workspaceDescription),
 		),
-	)
+		mcp.WithString(
+			StdinFromArgument,
+			mcp.Description(stdinFromDescription),
+		),
+		mcp.WithString(
+			"priority",
+			mcp.Description(priorityDescription),
+			mcp.Enum("interactive", "batch"),
+		),
+		mcp.WithBoolean(
+			"parse_output",
+			mcp.Description(parseOutputDescription),
+		),
+		mcp.WithBoolean(
+			"check_only",
+			mcp.Description(checkOnlyDescription),
+		),
+		mcp.WithBoolean(
+			"deterministic",
+			mcp.Description(deterministicDescription),
+		),
+		mcp.WithString(
+			"runtime_version",
+			mcp.Description(runtimeVersionDescription),
+		),
+	}
+	if !g.packages {
+		// nix-shell runs the code file alone
+		options = append(options, mcp.WithObject(
+			"files",
+			mcp.Description(filesDescription),
+			mcp.AdditionalProperties(map[string]any{"type": "string"}),
+		))
+	}
+	if g.packages {
+		options = append(options, WithDependencies(
+			"packages",
+			mcp.Description(`JSON array or comma-separated list of Nix packages (e.g. 'protobuf,sqlite') provided in a throwaway nix-shell.
+They are available to your code and do NOT persist between executions.`),
+		))
+	}
+
+	return mcp.NewTool("execute-go", options...)
 }
 
 func (g *SubprocessGoTool) HandleExecution(
@@ -135,31 +243,34 @@ func (g *SubprocessGoTool) HandleExecution(
 	code, err := request.RequireString("code")
 	if err != nil {
 		logger.Debug("Subprocess Go tool execution failed: missing code argument")
-		return mcp.NewToolResultError("Missing or invalid code argument"), nil
-	}
-
-	// Parse environment variables
-	envVars := make(map[string]string)
-	if envStr := request.GetString("env", ""); envStr != "" {
-		envPairs := strings.SplitSeq(envStr, ",")
-		for pair := range envPairs {
-			pair = strings.TrimSpace(pair)
-			if equalIndex := strings.Index(pair, "="); equalIndex > 0 {
-				key := strings.TrimSpace(pair[:equalIndex])
-				value := strings.TrimSpace(pair[equalIndex+1:])
-				envVars[key] = value
-			}
-		}
-		logger.Debug("Subprocess Go environment variables: %v", envVars)
-	}
-
-	// No package installation for subprocess mode - pass empty slice
-	output, err := g.executor.Execute(ctx, code, nil, envVars)
+		return ErrorResult(executor.ErrorPolicyViolation, "Missing or invalid code argument"), nil
+	}
+
+	// Packages are only provided by executors with throwaway environments
+	set := argumentSet{runtimeVersion: true, timeLocale: true, deterministic: true}
+	if g.packages {
+		set.dependencies = "packages"
+	} else {
+		set.files = true
+	}
+	args, err := parseExecutionArgs(request, set)
+	if err != nil {
+		logger.Debug("Subprocess Go tool execution failed: %v", err)
+		return ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+	}
+	if len(args.dependencies) > 0 {
+		logger.Debug("Subprocess Go packages requested: %v", args.dependencies)
+	}
+	if len(args.env) > 0 {
+		logger.Debug("Subprocess Go environment variables: %v", args.env)
+	}
+
+	output, err := g.executor.Execute(ctx, code, args.dependencies, args.env, args.options...)
 	if err != nil {
 		logger.Debug("Subprocess Go execution failed: %v", err)
-		return mcp.NewToolResultError(err.Error()), nil
+		return executionErrorResult(err), nil
 	}
 
 	logger.Debug("Subprocess Go execution completed successfully")
-	return mcp.NewToolResultText(output), nil
+	return executionResult(request, output), nil
 }
@@ -0,0 +1,30 @@
+package tools
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+)
+
+func TestGoTool_HandleExecution_CompileError(t *testing.T) {
+	diagnostics := []executor.Diagnostic{{File: "main.go", Line: 4, Column: 2, Message: "declared and not used: x"}}
+	mockExec := &mockExecutor{
+		executeFunc: func(ctx context.Context, code string, dependencies []string, envVars map[string]string) (string, error) {
+			return "", &executor.CompileError{Message: "go failed to compile", Diagnostics: diagnostics}
+		},
+	}
+
+	result, err := NewGoTool(mockExec).HandleExecution(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "execute-go", Arguments: map[string]any{"code": "package main"}},
+	})
+	if err != nil {
+		t.Fatalf("HandleExecution() returned error: %v", err)
+	}
+	want := map[string]any{"code": executor.ErrorCompile, "diagnostics": diagnostics}
+	if !result.IsError || result.Meta == nil || !reflect.DeepEqual(result.Meta.AdditionalFields[errorMetaKey], want) {
+		t.Errorf("Result = %+v, want a compile error with its diagnostics in the _meta", result)
+	}
+}
@@ -0,0 +1,123 @@
+// Package tools provides MCP tool implementations for executing code
+// with the http-request tool sending HTTP requests with curl.
+package tools
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/httprequest"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// HTTPRequestTool sends HTTP requests with curl, through the executor of the
+// Bash execute tool.
+type HTTPRequestTool struct {
+	executor executor.Executor
+}
+
+func NewHTTPRequestTool(exec executor.Executor) *HTTPRequestTool {
+	return &HTTPRequestTool{
+		executor: exec,
+	}
+}
+
+func (h *HTTPRequestTool) CreateTool() mcp.Tool {
+	description := `Send an HTTP request with curl from the sandbox and get the status, headers and body of the response separately.
+Use it instead of writing curl commands for execute-bash: the arguments need no shell quoting, and the response is returned as structured content.
+Redirects are followed unless follow_redirects is false. Bodies that are not UTF-8 text are returned base64-encoded, and bodies longer than 1 MiB are truncated.`
+
+	return mcp.NewTool(
+		"http-request",
+		mcp.WithDescription(description),
+		mcp.WithString(
+			"url",
+			mcp.Description("The http or https URL to send the request to"),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"method",
+			mcp.Description("The request method (default GET)"),
+			mcp.Enum(httprequest.Methods...),
+		),
+		mcp.WithObject(
+			"headers",
+			mcp.Description(`Request headers keyed by name, e.g. {"Accept": "application/json", "Authorization": "Bearer ..."}`),
+			mcp.AdditionalProperties(map[string]any{"type": "string"}),
+		),
+		mcp.WithString(
+			"body",
+			mcp.Description("The request body, sent as is; set its Content-Type in headers"),
+		),
+		mcp.WithBoolean(
+			"follow_redirects",
+			mcp.Description("Follow redirects, up to 10 (default true)"),
+		),
+		mcp.WithNumber(
+			"timeout",
+			mcp.Description(timeoutDescription),
+		),
+		mcp.WithString(
+			"priority",
+			mcp.Description(priorityDescription),
+			mcp.Enum("interactive", "batch"),
+		),
+	)
+}
+
+func (h *HTTPRequestTool) HandleExecution(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	logger.Debug("HTTP request tool execution requested")
+
+	target, err := request.RequireString("url")
+	if err != nil {
+		logger.Debug("HTTP request tool execution failed: missing url argument")
+		return ErrorResult(executor.ErrorPolicyViolation, "Missing or invalid url argument"), nil
+	}
+	var headers map[string]string
+	if _, ok := request.GetArguments()["headers"]; ok {
+		if headers, err = parseStringObject(request, "headers", "header names to values"); err != nil {
+			logger.Debug("HTTP request tool execution failed: %v", err)
+			return ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+		}
+	}
+	script, err := httprequest.Script(httprequest.Request{
+		Method:          request.GetString("method", ""),
+		URL:             strings.TrimSpace(target),
+		Headers:         headers,
+		Body:            request.GetString("body", ""),
+		FollowRedirects: request.GetBool("follow_redirects", true),
+	})
+	if err != nil {
+		logger.Debug("HTTP request tool execution failed: %v", err)
+		return ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+	}
+
+	args, err := parseExecutionArgs(request, argumentSet{})
+	if err != nil {
+		logger.Debug("HTTP request tool execution failed: %v", err)
+		return ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+	}
+
+	output, err := h.executor.Execute(ctx, script, nil, args.env, args.options...)
+	if err != nil {
+		logger.Debug("HTTP request execution failed: %v", err)
+		return executionErrorResult(err), nil
+	}
+	response, curlOutput, err := httprequest.Parse(output)
+	if err != nil {
+		logger.Debug("HTTP request execution failed: %v", err)
+		return executionErrorResult(executor.NewExecutionError(executor.ErrorRuntime, "%v: %s", err, output)), nil
+	}
+
+	logger.Debug("HTTP request execution completed: %d", response.Status)
+	text := response.Summary()
+	if curlOutput != "" {
+		text += "\nOutput:\n" + curlOutput + "\n"
+	}
+	return mcp.NewToolResultStructured(response, text), nil
+}
@@ -0,0 +1,12 @@
+// Package tools provides MCP tool implementations for executing code
+// with shared helpers for the dependency manifest parameter.
+package tools
+
+import "fmt"
+
+// dependencyFileDescription describes the "dependency_file" parameter of a
+// language whose manifest is named manifest.
+func dependencyFileDescription(manifest string) string {
+	return fmt.Sprintf(`Content of a %s installed before the code runs, for pinned versions and full dependency resolution.
+Can be combined with the comma-separated list.`, manifest)
+}
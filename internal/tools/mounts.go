@@ -0,0 +1,34 @@
+// Package tools provides MCP tool implementations for executing code
+// with shared helpers for host volume mount parameters.
+package tools
+
+import (
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+)
+
+const mountsDescription = `Comma-separated list of host paths to mount into the container in host_path:container_path[:ro|rw] format
+(e.g., '/data/sales:/mnt/sales,/srv/out:/mnt/out:rw'). Mounts are read-only by default and restricted to operator-allowed host directories.`
+
+// parseMounts reads the optional "mounts" argument into executor mounts.
+func parseMounts(request mcp.CallToolRequest) ([]executor.Mount, error) {
+	mountsStr := request.GetString("mounts", "")
+	if strings.TrimSpace(mountsStr) == "" {
+		return nil, nil
+	}
+
+	var mounts []executor.Mount
+	for spec := range strings.SplitSeq(mountsStr, ",") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		mount, err := executor.ParseMount(spec)
+		if err != nil {
+			return nil, err
+		}
+		mounts = append(mounts, mount)
+	}
+	return mounts, nil
+}
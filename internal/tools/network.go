@@ -0,0 +1,24 @@
+// Package tools provides MCP tool implementations for executing code
+// with shared helpers for the container network parameter.
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const networkDescription = `Container network mode: 'bridge' (default), 'none' to disable networking, or 'host' to share the host network.
+'host' is a privileged request and requires user confirmation; it is refused when the client cannot confirm it, unless the server allows it.`
+
+// parseNetwork reads and validates the optional "network" argument.
+func parseNetwork(request mcp.CallToolRequest) (string, error) {
+	network := strings.ToLower(strings.TrimSpace(request.GetString("network", "")))
+	switch network {
+	case "", "bridge", "none", "host":
+		return network, nil
+	default:
+		return "", fmt.Errorf("invalid network %q: expected bridge, none or host", network)
+	}
+}
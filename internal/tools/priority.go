@@ -0,0 +1,8 @@
+// Package tools provides MCP tool implementations for executing code
+// with shared helpers for the queue priority parameter.
+package tools
+
+const priorityDescription = `Queue priority when the server is at its concurrency limit: 'interactive' (default)
+for calls a user is waiting on, 'batch' for background work that may wait longer. The server may
+stop a long batch execution for waiting interactive calls and run it again from the start, so only
+mark idempotent work as batch.`
@@ -0,0 +1,18 @@
+// Package tools provides MCP tool implementations for executing code
+// with shared helpers for the environment profile parameter.
+package tools
+
+import (
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const profileDescription = `Name of a pre-baked environment configured by the server administrator, listed in the environments://list resource
+(e.g. 'data-science'). Its packages are installed in a cached image, so they need not be passed as dependencies.
+Cannot be combined with runtime_version.`
+
+// parseProfile reads the optional "profile" argument.
+func parseProfile(request mcp.CallToolRequest) string {
+	return strings.TrimSpace(request.GetString("profile", ""))
+}
@@ -4,7 +4,6 @@ package tools
 
 import (
 	"context"
-	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/ylchen07/mcp-executor/internal/executor"
@@ -25,7 +24,7 @@ func (p *PythonTool) CreateTool() mcp.Tool {
 	description := `Execute Python code in an isolated Docker container. Playwright and headless browsers are pre-installed for web scraping.
 External modules can be dynamically installed. Use this tool when you need real-time information or require external Python packages.
 Only output printed to stdout or stderr is returned so ALWAYS use print statements!
-Note: Code runs in ephemeral containers - modules and state do NOT persist between executions.`
+Note: Code runs in ephemeral containers - modules and state do NOT persist between executions, except files in a shared workspace.`
 
 	return mcp.NewTool(
 		"execute-python",
@@ -35,15 +34,65 @@ Note: Code runs in ephemeral containers - modules and state do NOT persist betwe
 			mcp.Description("The Python code to execute"),
 			mcp.Required(),
 		),
-		mcp.WithString(
+		WithDependencies(
 			"modules",
-			mcp.Description(`Comma-separated list of Python modules to install (e.g., 'requests,beautifulsoup4,pandas').
+			mcp.Description(`JSON array or comma-separated list of Python modules to install (e.g., 'requests,beautifulsoup4,pandas').
 Modules are installed automatically via pip before code execution.`),
 		),
+		WithEnv(envDescription("Python code")),
+		WithTimeLocale(),
+		mcp.WithString(
+			"mounts",
+			mcp.Description(mountsDescription),
+		),
+		mcp.WithString(
+			"network",
+			mcp.Description(networkDescription),
+		),
+		mcp.WithString(
+			"snapshot",
+			mcp.Description(snapshotDescription),
+		),
+		mcp.WithNumber(
+			"timeout",
+			mcp.Description(timeoutDescription),
+		),
+		mcp.WithString(
+			"workspace",
+			mcp.Description(workspaceDescription),
+		),
+		mcp.WithString(
+			StdinFromArgument,
+			mcp.Description(stdinFromDescription),
+		),
+		mcp.WithString(
+			"priority",
+			mcp.Description(priorityDescription),
+			mcp.Enum("interactive", "batch"),
+		),
+		mcp.WithBoolean(
+			"parse_output",
+			mcp.Description(parseOutputDescription),
+		),
+		mcp.WithBoolean(
+			"check_only",
+			mcp.Description(checkOnlyDescription),
+		),
+		mcp.WithBoolean(
+			"deterministic",
+			mcp.Description(deterministicDescription),
+		),
+		mcp.WithString(
+			"runtime_version",
+			mcp.Description(runtimeVersionDescription),
+		),
+		mcp.WithString(
+			"profile",
+			mcp.Description(profileDescription),
+		),
 		mcp.WithString(
-			"env",
-			mcp.Description(`Comma-separated list of environment variables in KEY=VALUE format (e.g., 'API_KEY=secret,DEBUG=true').
-These will be available to your Python code.`),
+			"dependency_file",
+			mcp.Description(dependencyFileDescription("requirements.txt")),
 		),
 	)
 }
@@ -57,43 +106,36 @@ func (p *PythonTool) HandleExecution(
 	code, err := request.RequireString("code")
 	if err != nil {
 		logger.Debug("Python tool execution failed: missing code argument")
-		return mcp.NewToolResultError("Missing or invalid code argument"), nil
+		return ErrorResult(executor.ErrorPolicyViolation, "Missing or invalid code argument"), nil
 	}
 
-	var modules []string
-	if modulesStr := request.GetString("modules", ""); modulesStr != "" {
-		modules = strings.Split(modulesStr, ",")
-		logger.Debug("Python modules requested: %v", modules)
+	args, err := parseExecutionArgs(request, argumentSet{dependencies: "modules", container: true, runtimeVersion: true, dependencyFile: true, timeLocale: true, deterministic: true})
+	if err != nil {
+		logger.Debug("Python tool execution failed: %v", err)
+		return ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
 	}
-
-	// Parse environment variables
-	envVars := make(map[string]string)
-	if envStr := request.GetString("env", ""); envStr != "" {
-		envPairs := strings.SplitSeq(envStr, ",")
-		for pair := range envPairs {
-			pair = strings.TrimSpace(pair)
-			if equalIndex := strings.Index(pair, "="); equalIndex > 0 {
-				key := strings.TrimSpace(pair[:equalIndex])
-				value := strings.TrimSpace(pair[equalIndex+1:])
-				envVars[key] = value
-			}
-		}
-		logger.Debug("Python environment variables: %v", envVars)
+	if len(args.dependencies) > 0 {
+		logger.Debug("Python modules requested: %v", args.dependencies)
+	}
+	if len(args.env) > 0 {
+		logger.Debug("Python environment variables: %v", args.env)
 	}
 
-	output, err := p.executor.Execute(ctx, code, modules, envVars)
+	output, err := p.executor.Execute(ctx, code, args.dependencies, args.env, args.options...)
 	if err != nil {
 		logger.Debug("Python execution failed: %v", err)
-		return mcp.NewToolResultError(err.Error()), nil
+		return executionErrorResult(err), nil
 	}
 
 	logger.Debug("Python execution completed successfully")
-	return mcp.NewToolResultText(output), nil
+	return executionResult(request, output), nil
 }
 
-// SubprocessPythonTool executes Python code on the host system without module installation support
+// SubprocessPythonTool executes Python code on the host system, with module
+// installation support only when its executor provides isolated environments
 type SubprocessPythonTool struct {
 	executor executor.Executor
+	modules  bool
 }
 
 func NewSubprocessPythonTool(exec executor.Executor) *SubprocessPythonTool {
@@ -102,26 +144,83 @@ func NewSubprocessPythonTool(exec executor.Executor) *SubprocessPythonTool {
 	}
 }
 
+// NewSubprocessPythonToolWithModules returns a subprocess Python tool accepting
+// modules, for executors installing them into throwaway environments.
+func NewSubprocessPythonToolWithModules(exec executor.Executor) *SubprocessPythonTool {
+	return &SubprocessPythonTool{
+		executor: exec,
+		modules:  true,
+	}
+}
+
 func (p *SubprocessPythonTool) CreateTool() mcp.Tool {
 	description := `Execute Python code directly on the host system. Only standard library and pre-installed packages are available.
 Use this tool when you need real-time information and don't require external dependencies.
 Only output printed to stdout or stderr is returned so ALWAYS use print statements!
 Note: Code runs on the host system with user permissions.`
+	if p.modules {
+		description = `Execute Python code directly on the host system. External modules can be installed into a throwaway environment.
+Use this tool when you need real-time information or require external Python packages.
+Only output printed to stdout or stderr is returned so ALWAYS use print statements!
+Note: Code runs on the host system with user permissions; installed modules do NOT persist between executions.`
+	}
 
-	return mcp.NewTool(
-		"execute-python",
+	options := []mcp.ToolOption{
 		mcp.WithDescription(description),
 		mcp.WithString(
 			"code",
 			mcp.Description("The Python code to execute"),
 			mcp.Required(),
 		),
+		WithEnv(envDescription("Python code")),
+		WithTimeLocale(),
+		mcp.WithNumber(
+			"timeout",
+			mcp.Description(timeoutDescription),
+		),
 		mcp.WithString(
-			"env",
-			mcp.Description(`Comma-separated list of environment variables in KEY=VALUE format (e.g., 'API_KEY=secret,DEBUG=true').
-These will be available to your Python code.`),
+			"workspace",
+			mcp.Description(workspaceDescription),
 		),
-	)
+		mcp.WithString(
+			StdinFromArgument,
+			mcp.Description(stdinFromDescription),
+		),
+		mcp.WithString(
+			"priority",
+			mcp.Description(priorityDescription),
+			mcp.Enum("interactive", "batch"),
+		),
+		mcp.WithBoolean(
+			"parse_output",
+			mcp.Description(parseOutputDescription),
+		),
+		mcp.WithBoolean(
+			"check_only",
+			mcp.Description(checkOnlyDescription),
+		),
+		mcp.WithBoolean(
+			"deterministic",
+			mcp.Description(deterministicDescription),
+		),
+		mcp.WithString(
+			"runtime_version",
+			mcp.Description(runtimeVersionDescription),
+		),
+	}
+	if p.modules {
+		options = append(options, WithDependencies(
+			"modules",
+			mcp.Description(`JSON array or comma-separated list of Python modules to install (e.g., 'requests,beautifulsoup4,pandas').
+Modules are installed into an ephemeral environment before code execution.`),
+		))
+		options = append(options, mcp.WithString(
+			"dependency_file",
+			mcp.Description(dependencyFileDescription("requirements.txt")),
+		))
+	}
+
+	return mcp.NewTool("execute-python", options...)
 }
 
 func (p *SubprocessPythonTool) HandleExecution(
@@ -133,31 +232,32 @@ func (p *SubprocessPythonTool) HandleExecution(
 	code, err := request.RequireString("code")
 	if err != nil {
 		logger.Debug("Subprocess Python tool execution failed: missing code argument")
-		return mcp.NewToolResultError("Missing or invalid code argument"), nil
-	}
-
-	// Parse environment variables
-	envVars := make(map[string]string)
-	if envStr := request.GetString("env", ""); envStr != "" {
-		envPairs := strings.SplitSeq(envStr, ",")
-		for pair := range envPairs {
-			pair = strings.TrimSpace(pair)
-			if equalIndex := strings.Index(pair, "="); equalIndex > 0 {
-				key := strings.TrimSpace(pair[:equalIndex])
-				value := strings.TrimSpace(pair[equalIndex+1:])
-				envVars[key] = value
-			}
-		}
-		logger.Debug("Subprocess Python environment variables: %v", envVars)
-	}
-
-	// No module installation for subprocess mode - pass empty slice
-	output, err := p.executor.Execute(ctx, code, nil, envVars)
+		return ErrorResult(executor.ErrorPolicyViolation, "Missing or invalid code argument"), nil
+	}
+
+	// Modules are only installed by executors with throwaway environments
+	set := argumentSet{runtimeVersion: true, dependencyFile: true, timeLocale: true, deterministic: true}
+	if p.modules {
+		set.dependencies = "modules"
+	}
+	args, err := parseExecutionArgs(request, set)
+	if err != nil {
+		logger.Debug("Subprocess Python tool execution failed: %v", err)
+		return ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+	}
+	if len(args.dependencies) > 0 {
+		logger.Debug("Subprocess Python modules requested: %v", args.dependencies)
+	}
+	if len(args.env) > 0 {
+		logger.Debug("Subprocess Python environment variables: %v", args.env)
+	}
+
+	output, err := p.executor.Execute(ctx, code, args.dependencies, args.env, args.options...)
 	if err != nil {
 		logger.Debug("Subprocess Python execution failed: %v", err)
-		return mcp.NewToolResultError(err.Error()), nil
+		return executionErrorResult(err), nil
 	}
 
 	logger.Debug("Subprocess Python execution completed successfully")
-	return mcp.NewToolResultText(output), nil
+	return executionResult(request, output), nil
 }
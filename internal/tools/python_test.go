@@ -2,10 +2,15 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/executor"
 )
 
 // mockExecutor implements the executor.Executor interface for testing
@@ -14,12 +19,14 @@ type mockExecutor struct {
 	lastCode    string
 	lastDeps    []string
 	lastEnvVars map[string]string
+	lastOptions executor.Options
 }
 
-func (m *mockExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string) (string, error) {
+func (m *mockExecutor) Execute(ctx context.Context, code string, dependencies []string, envVars map[string]string, opts ...executor.Option) (string, error) {
 	m.lastCode = code
 	m.lastDeps = dependencies
 	m.lastEnvVars = envVars
+	m.lastOptions = executor.NewOptions(opts...)
 
 	if m.executeFunc != nil {
 		return m.executeFunc(ctx, code, dependencies, envVars)
@@ -134,7 +141,28 @@ func TestPythonTool_HandleExecution(t *testing.T) {
 			mockError:  nil,
 			wantErr:    false,
 			wantResult: "success",
-			checkDeps:  []string{"requests ", " numpy ", " pandas"},
+			checkDeps:  []string{"requests", "numpy", "pandas"},
+		},
+		{
+			name: "with modules as array",
+			params: map[string]any{
+				"code":    `import requests`,
+				"modules": []any{"requests==2.32.0", " numpy ", ""},
+			},
+			mockOutput: "success",
+			mockError:  nil,
+			wantErr:    false,
+			wantResult: "success",
+			checkDeps:  []string{"requests==2.32.0", "numpy"},
+		},
+		{
+			name: "with invalid modules array",
+			params: map[string]any{
+				"code":    `import requests`,
+				"modules": []any{"requests", 2.0},
+			},
+			wantErr:    false,
+			wantResult: "invalid modules",
 		},
 		{
 			name: "with single env var",
@@ -397,6 +425,289 @@ func TestPythonTool_HandleExecution_EmptyEnv(t *testing.T) {
 	}
 }
 
+func TestPythonTool_HandleExecution_Mounts(t *testing.T) {
+	tests := []struct {
+		name       string
+		mounts     string
+		wantErr    bool
+		wantMounts []executor.Mount
+	}{
+		{
+			name:       "read-only by default",
+			mounts:     "/data:/mnt/data",
+			wantMounts: []executor.Mount{{Source: "/data", Target: "/mnt/data", ReadOnly: true}},
+		},
+		{
+			name:   "multiple with explicit modes",
+			mounts: "/data:/mnt/data:ro , /out:/mnt/out:rw",
+			wantMounts: []executor.Mount{
+				{Source: "/data", Target: "/mnt/data", ReadOnly: true},
+				{Source: "/out", Target: "/mnt/out", ReadOnly: false},
+			},
+		},
+		{
+			name:    "relative container path",
+			mounts:  "/data:mnt",
+			wantErr: true,
+		},
+		{
+			name:    "invalid mode",
+			mounts:  "/data:/mnt/data:rx",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExec := &mockExecutor{}
+			pythonTool := NewPythonTool(mockExec)
+
+			request := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name: "execute-python",
+					Arguments: map[string]any{
+						"code":   `print("test")`,
+						"mounts": tt.mounts,
+					},
+				},
+			}
+
+			result, err := pythonTool.HandleExecution(context.Background(), request)
+			if err != nil {
+				t.Fatalf("HandleExecution() returned error: %v", err)
+			}
+
+			if result.IsError != tt.wantErr {
+				t.Fatalf("HandleExecution() IsError = %v, want %v", result.IsError, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got := mockExec.lastOptions.Mounts
+			if len(got) != len(tt.wantMounts) {
+				t.Fatalf("Mounts = %v, want %v", got, tt.wantMounts)
+			}
+			for i, want := range tt.wantMounts {
+				if got[i] != want {
+					t.Errorf("Mount[%d] = %+v, want %+v", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestPythonTool_HandleExecution_Timeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		timeout     any
+		wantErr     bool
+		wantTimeout time.Duration
+	}{
+		{name: "not set", timeout: nil, wantTimeout: 0},
+		{name: "whole seconds", timeout: 30.0, wantTimeout: 30 * time.Second},
+		{name: "fractional seconds", timeout: 1.5, wantTimeout: 1500 * time.Millisecond},
+		{name: "negative", timeout: -1.0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExec := &mockExecutor{}
+			tools := []interface {
+				HandleExecution(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+			}{NewPythonTool(mockExec), NewSubprocessPythonTool(mockExec)}
+
+			for _, tool := range tools {
+				arguments := map[string]any{"code": `print("test")`}
+				if tt.timeout != nil {
+					arguments["timeout"] = tt.timeout
+				}
+				result, err := tool.HandleExecution(context.Background(), mcp.CallToolRequest{
+					Params: mcp.CallToolParams{Name: "execute-python", Arguments: arguments},
+				})
+				if err != nil {
+					t.Fatalf("HandleExecution() returned error: %v", err)
+				}
+
+				if result.IsError != tt.wantErr {
+					t.Fatalf("HandleExecution() IsError = %v, want %v", result.IsError, tt.wantErr)
+				}
+				if !tt.wantErr && mockExec.lastOptions.Timeout != tt.wantTimeout {
+					t.Errorf("Timeout = %v, want %v", mockExec.lastOptions.Timeout, tt.wantTimeout)
+				}
+			}
+		})
+	}
+}
+
+func TestPythonTool_HandleExecution_Terminated(t *testing.T) {
+	mockExec := &mockExecutor{
+		executeFunc: func(ctx context.Context, code string, dependencies []string, envVars map[string]string) (string, error) {
+			return "", &executor.TerminationError{
+				Reason:  executor.TerminationTimeout,
+				Signal:  "killed",
+				Message: "execution timed out after 1s",
+				Output:  "step 1\n",
+			}
+		},
+	}
+
+	result, err := NewPythonTool(mockExec).HandleExecution(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "execute-python", Arguments: map[string]any{"code": "step()"}},
+	})
+	if err != nil {
+		t.Fatalf("HandleExecution() returned error: %v", err)
+	}
+
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "step 1") {
+		t.Errorf("Result should be an error keeping the partial output, got %+v", result)
+	}
+	want := map[string]any{"reason": "timeout", "signal": "killed"}
+	if result.Meta == nil || !reflect.DeepEqual(result.Meta.AdditionalFields[terminationMetaKey], want) {
+		t.Errorf("Result _meta = %+v, want %v under %s", result.Meta, want, terminationMetaKey)
+	}
+	if code := result.Meta.AdditionalFields[errorMetaKey]; !reflect.DeepEqual(code, map[string]any{"code": "timeout"}) {
+		t.Errorf("Result error code = %v, want timeout", code)
+	}
+}
+
+func TestPythonTool_HandleExecution_ErrorCode(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments map[string]any
+		err       error
+		wantCode  string
+	}{
+		{"missing code", map[string]any{}, nil, executor.ErrorPolicyViolation},
+		{"install failed", map[string]any{"code": "import rich"}, executor.NewExecutionError(executor.ErrorInstallFailed, "failed to install dependencies"), executor.ErrorInstallFailed},
+		{"unclassified", map[string]any{"code": "print(1)"}, errors.New("execution failed: docker not found"), executor.ErrorInfrastructure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExec := &mockExecutor{
+				executeFunc: func(ctx context.Context, code string, dependencies []string, envVars map[string]string) (string, error) {
+					return "", tt.err
+				},
+			}
+			result, err := NewPythonTool(mockExec).HandleExecution(context.Background(), mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: "execute-python", Arguments: tt.arguments},
+			})
+			if err != nil {
+				t.Fatalf("HandleExecution() returned error: %v", err)
+			}
+			if !result.IsError || result.Meta == nil {
+				t.Fatalf("Result = %+v, want an error with _meta", result)
+			}
+			if code := result.Meta.AdditionalFields[errorMetaKey]; !reflect.DeepEqual(code, map[string]any{"code": tt.wantCode}) {
+				t.Errorf("Result error code = %v, want %s", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestPythonTool_HandleExecution_InstallError(t *testing.T) {
+	mockExec := &mockExecutor{
+		executeFunc: func(ctx context.Context, code string, dependencies []string, envVars map[string]string) (string, error) {
+			return "", &executor.InstallError{
+				Message:     "failed to install python dependencies",
+				Package:     "reqeusts",
+				Reason:      "ERROR: No matching distribution found for reqeusts",
+				Suggestions: []string{"requests"},
+				Output:      "ERROR: No matching distribution found for reqeusts",
+			}
+		},
+	}
+
+	result, err := NewPythonTool(mockExec).HandleExecution(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Name: "execute-python", Arguments: map[string]any{"code": "import requests", "modules": "reqeusts"}},
+	})
+	if err != nil {
+		t.Fatalf("HandleExecution() returned error: %v", err)
+	}
+	want := map[string]any{"code": executor.ErrorInstallFailed, "install_error": map[string]any{
+		"package":     "reqeusts",
+		"reason":      "ERROR: No matching distribution found for reqeusts",
+		"suggestions": []string{"requests"},
+		"output":      "ERROR: No matching distribution found for reqeusts",
+	}}
+	if !result.IsError || result.Meta == nil || !reflect.DeepEqual(result.Meta.AdditionalFields[errorMetaKey], want) {
+		t.Errorf("Result = %+v, want an install error with the installer output in the _meta", result)
+	}
+}
+
+func TestPythonTool_ParseOutput(t *testing.T) {
+	tests := []struct {
+		name        string
+		output      string
+		parseOutput any
+		want        any
+	}{
+		{"object", "{\"rows\": 3, \"ok\": true}\n", true, map[string]any{"rows": json.Number("3"), "ok": true}},
+		{"array", "[1, 2]", true, map[string]any{"value": []any{json.Number("1"), json.Number("2")}}},
+		{"string argument", "{\"a\": null}", "true", map[string]any{"a": nil}},
+		{"not requested", "{\"a\": 1}", nil, nil},
+		{"not json", "Done: 3 rows\n", true, nil},
+		{"several documents", "{\"a\": 1}\n{\"b\": 2}\n", true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExec := &mockExecutor{
+				executeFunc: func(ctx context.Context, code string, dependencies []string, envVars map[string]string) (string, error) {
+					return tt.output, nil
+				},
+			}
+			arguments := map[string]any{"code": "print(data)"}
+			if tt.parseOutput != nil {
+				arguments["parse_output"] = tt.parseOutput
+			}
+			result, err := NewPythonTool(mockExec).HandleExecution(context.Background(), mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: "execute-python", Arguments: arguments},
+			})
+			if err != nil {
+				t.Fatalf("HandleExecution() returned error: %v", err)
+			}
+			if !reflect.DeepEqual(result.StructuredContent, tt.want) {
+				t.Errorf("StructuredContent = %#v, want %#v", result.StructuredContent, tt.want)
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; text != tt.output {
+				t.Errorf("Text = %q, want the output %q", text, tt.output)
+			}
+		})
+	}
+}
+
+func TestPythonTool_DependencyFile(t *testing.T) {
+	requirements := "requests==2.32.0\npandas>=2\n"
+	mockExec := &mockExecutor{}
+	tools := []interface {
+		CreateTool() mcp.Tool
+		HandleExecution(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+	}{NewPythonTool(mockExec), NewSubprocessPythonToolWithModules(mockExec)}
+
+	for _, tool := range tools {
+		if _, ok := tool.CreateTool().InputSchema.Properties["dependency_file"]; !ok {
+			t.Errorf("%T should have 'dependency_file' parameter", tool)
+		}
+		_, err := tool.HandleExecution(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name:      "execute-python",
+				Arguments: map[string]any{"code": `import requests`, "dependency_file": requirements},
+			},
+		})
+		if err != nil {
+			t.Fatalf("HandleExecution() returned error: %v", err)
+		}
+		if mockExec.lastOptions.DependencyFile != requirements {
+			t.Errorf("%T DependencyFile = %q, want %q", tool, mockExec.lastOptions.DependencyFile, requirements)
+		}
+	}
+
+	if _, ok := NewSubprocessPythonTool(mockExec).CreateTool().InputSchema.Properties["dependency_file"]; ok {
+		t.Error("SubprocessPythonTool without module support should NOT have 'dependency_file' parameter")
+	}
+}
+
 // ExecutorError is a simple error type for testing
 type ExecutorError struct {
 	Message string
@@ -594,3 +905,29 @@ func TestSubprocessPythonTool_NoDependencies(t *testing.T) {
 		t.Errorf("SubprocessPythonTool must pass nil dependencies to prevent pip install, got: %v", mockExec.lastDeps)
 	}
 }
+
+func TestSubprocessPythonToolWithModules(t *testing.T) {
+	mockExec := &mockExecutor{}
+	pythonTool := NewSubprocessPythonToolWithModules(mockExec)
+
+	tool := pythonTool.CreateTool()
+	if _, hasModules := tool.InputSchema.Properties["modules"]; !hasModules {
+		t.Error("Tool with module support should have 'modules' parameter")
+	}
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "execute-python",
+			Arguments: map[string]any{
+				"code":    `import requests`,
+				"modules": "requests,rich",
+			},
+		},
+	}
+	if _, err := pythonTool.HandleExecution(context.Background(), request); err != nil {
+		t.Fatalf("HandleExecution() returned error: %v", err)
+	}
+	if strings.Join(mockExec.lastDeps, ",") != "requests,rich" {
+		t.Errorf("Dependencies = %v, want [requests rich]", mockExec.lastDeps)
+	}
+}
@@ -0,0 +1,151 @@
+// Package tools provides MCP tool implementations for executing code
+// with the render-page tool rendering web pages as screenshots or PDFs.
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/browse"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// RenderTool renders web pages with Playwright, through the executor of the
+// Python execute tool.
+type RenderTool struct {
+	executor executor.Executor
+}
+
+func NewRenderTool(exec executor.Executor) *RenderTool {
+	return &RenderTool{
+		executor: exec,
+	}
+}
+
+func (r *RenderTool) CreateTool() mcp.Tool {
+	description := `Render a web page with a headless Chromium browser as a PNG screenshot or a PDF document.
+Use it to see how a page looks, e.g. to check a layout at a given viewport size, or to save a page as a PDF.
+The screenshot is returned as an image, the PDF as an embedded resource, together with the title, final URL and HTTP status of the page.
+To read the text of a page, use browse-web instead.`
+
+	return mcp.NewTool(
+		"render-page",
+		mcp.WithDescription(description),
+		mcp.WithString(
+			"url",
+			mcp.Description("The http or https URL of the page to render"),
+			mcp.Required(),
+		),
+		mcp.WithString(
+			"format",
+			mcp.Description("Format of the rendering: a PNG screenshot (default) or a PDF document of the whole page"),
+			mcp.Enum(browse.FormatPNG, browse.FormatPDF),
+		),
+		mcp.WithNumber(
+			"width",
+			mcp.Description(fmt.Sprintf("Width of the viewport in CSS pixels, at most %d (default %d)", browse.MaxViewport, browse.DefaultWidth)),
+		),
+		mcp.WithNumber(
+			"height",
+			mcp.Description(fmt.Sprintf("Height of the viewport in CSS pixels, at most %d (default %d)", browse.MaxViewport, browse.DefaultHeight)),
+		),
+		mcp.WithBoolean(
+			"full_page",
+			mcp.Description("Take the screenshot of the whole scrollable page instead of the viewport"),
+		),
+		mcp.WithString(
+			"wait_until",
+			mcp.Description("Load state to render the page after: the load event (default), DOMContentLoaded, or no network connections for 500ms"),
+			mcp.Enum(browse.WaitUntil...),
+		),
+		mcp.WithString(
+			"wait_for",
+			mcp.Description("CSS selector of an element to wait for after the page loaded, for pages rendering their content with JavaScript"),
+		),
+		mcp.WithNumber(
+			"delay",
+			mcp.Description(fmt.Sprintf("Seconds to wait before rendering, after the page loaded, e.g. for animations; at most %d", int(browse.MaxDelay.Seconds()))),
+		),
+		mcp.WithNumber(
+			"timeout",
+			mcp.Description(timeoutDescription),
+		),
+		mcp.WithString(
+			"priority",
+			mcp.Description(priorityDescription),
+			mcp.Enum("interactive", "batch"),
+		),
+	)
+}
+
+func (r *RenderTool) HandleExecution(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	logger.Debug("Render tool execution requested")
+
+	target, err := request.RequireString("url")
+	if err != nil {
+		logger.Debug("Render tool execution failed: missing url argument")
+		return ErrorResult(executor.ErrorPolicyViolation, "Missing or invalid url argument"), nil
+	}
+	width, height := request.GetFloat("width", 0), request.GetFloat("height", 0)
+	if width != float64(int(width)) || height != float64(int(height)) {
+		logger.Debug("Render tool execution failed: invalid viewport")
+		return ErrorResult(executor.ErrorPolicyViolation, fmt.Sprintf("invalid viewport %vx%v: width and height must be whole numbers", width, height)), nil
+	}
+	program, err := browse.RenderProgram(browse.RenderRequest{
+		URL:       strings.TrimSpace(target),
+		Format:    request.GetString("format", ""),
+		Width:     int(width),
+		Height:    int(height),
+		FullPage:  request.GetBool("full_page", false),
+		WaitUntil: request.GetString("wait_until", ""),
+		WaitFor:   strings.TrimSpace(request.GetString("wait_for", "")),
+		Delay:     time.Duration(request.GetFloat("delay", 0) * float64(time.Second)),
+	})
+	if err != nil {
+		logger.Debug("Render tool execution failed: %v", err)
+		return ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+	}
+
+	args, err := parseExecutionArgs(request, argumentSet{})
+	if err != nil {
+		logger.Debug("Render tool execution failed: %v", err)
+		return ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+	}
+
+	output, err := r.executor.Execute(ctx, program, nil, args.env, args.options...)
+	if err != nil {
+		logger.Debug("Render execution failed: %v", err)
+		return executionErrorResult(err), nil
+	}
+	rendering, programOutput, err := browse.ParseRendering(output)
+	if err != nil {
+		logger.Debug("Render execution failed: %v", err)
+		return executionErrorResult(executor.NewExecutionError(executor.ErrorRuntime, "%v: %s", err, output)), nil
+	}
+
+	logger.Debug("Render execution completed: %s", rendering.URL)
+	text := rendering.Summary()
+	if programOutput != "" {
+		text += "\nOutput:\n" + programOutput + "\n"
+	}
+	result := mcp.NewToolResultStructured(rendering, text)
+	data := base64.StdEncoding.EncodeToString(rendering.Data)
+	if rendering.Format == browse.FormatPDF {
+		result.Content = append(result.Content, mcp.NewEmbeddedResource(mcp.BlobResourceContents{
+			URI:      rendering.URL,
+			MIMEType: rendering.MIMEType,
+			Blob:     data,
+		}))
+	} else {
+		result.Content = append(result.Content, mcp.NewImageContent(data, rendering.MIMEType))
+	}
+	return result, nil
+}
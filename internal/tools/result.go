@@ -0,0 +1,88 @@
+// Package tools provides MCP tool implementations for executing code
+// with the shared results of successful and failed executions.
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+)
+
+// terminationMetaKey holds the termination reason in the _meta of tool results.
+const terminationMetaKey = "mcp-executor/termination"
+
+// errorMetaKey holds the error code in the _meta of failed tool results.
+const errorMetaKey = "mcp-executor/error"
+
+const parseOutputDescription = `Parse the output as JSON and return it as structured content in addition to
+the text. The program must print a single JSON document and nothing else; other output is returned as
+text only. Values that are not objects are returned as {"value": ...}.`
+
+// executionResult returns the tool result of a successful execution. With
+// parse_output, output holding a single JSON document is also returned as
+// structured content, which must be an object: other values are wrapped in
+// {"value": ...}.
+func executionResult(request mcp.CallToolRequest, output string) *mcp.CallToolResult {
+	output = checkOutput(request, output)
+	if !request.GetBool("parse_output", false) {
+		return mcp.NewToolResultText(output)
+	}
+	var parsed any
+	decoder := json.NewDecoder(strings.NewReader(output))
+	decoder.UseNumber()
+	if err := decoder.Decode(&parsed); err != nil || decoder.More() {
+		logger.Debug("Returning output as text only: it is not a single JSON document")
+		return mcp.NewToolResultText(output)
+	}
+	if _, ok := parsed.(map[string]any); !ok {
+		parsed = map[string]any{"value": parsed}
+	}
+	return mcp.NewToolResultStructured(parsed, output)
+}
+
+// executionErrorResult returns the tool result of a failed execution, with
+// the error code in the _meta and, for code that did not compile, the
+// diagnostics of the compiler or, for dependencies that were not installed,
+// the install_error with the output of the installer, the package it failed on
+// and the packages likely meant. For an execution stopped before it finished,
+// the message keeps the output produced so far and the _meta also reports the
+// reason (timeout, oom, signal or disk) and signal.
+func executionErrorResult(err error) *mcp.CallToolResult {
+	result := ErrorResult(executor.ErrorCode(err), err.Error())
+	var terminated *executor.TerminationError
+	if errors.As(err, &terminated) {
+		termination := map[string]any{"reason": terminated.Reason}
+		if terminated.Signal != "" {
+			termination["signal"] = terminated.Signal
+		}
+		result.Meta.AdditionalFields[terminationMetaKey] = termination
+	}
+	var compile *executor.CompileError
+	if errors.As(err, &compile) && len(compile.Diagnostics) > 0 {
+		result.Meta.AdditionalFields[errorMetaKey].(map[string]any)["diagnostics"] = compile.Diagnostics
+	}
+	var install *executor.InstallError
+	if errors.As(err, &install) {
+		installError := map[string]any{"output": install.Output}
+		if install.Package != "" {
+			installError["package"], installError["reason"] = install.Package, install.Reason
+		}
+		if len(install.Suggestions) > 0 {
+			installError["suggestions"] = install.Suggestions
+		}
+		result.Meta.AdditionalFields[errorMetaKey].(map[string]any)["install_error"] = installError
+	}
+	return result
+}
+
+// ErrorResult returns a failed tool result with message and code, one of the
+// executor.Error* codes, in the _meta.
+func ErrorResult(code, message string) *mcp.CallToolResult {
+	result := mcp.NewToolResultError(message)
+	result.Meta = &mcp.Meta{AdditionalFields: map[string]any{errorMetaKey: map[string]any{"code": code}}}
+	return result
+}
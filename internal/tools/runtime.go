@@ -0,0 +1,17 @@
+// Package tools provides MCP tool implementations for executing code
+// with shared helpers for the runtime version parameter.
+package tools
+
+import (
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const runtimeVersionDescription = `Language version to run, e.g. '3.12' for Python, '22' for Node.js or '1.23' for Go.
+In Docker mode it selects one of the operator's configured images; on the host it selects a toolchain installed with pyenv, asdf, mise, nvm or Go's ~/sdk. Defaults to the server's default runtime.`
+
+// parseRuntimeVersion reads the optional "runtime_version" argument.
+func parseRuntimeVersion(request mcp.CallToolRequest) string {
+	return strings.TrimPrefix(strings.TrimSpace(request.GetString("runtime_version", "")), "v")
+}
@@ -0,0 +1,7 @@
+// Package tools provides MCP tool implementations for executing code
+// with the shared snapshot parameter of Docker mode.
+package tools
+
+const snapshotDescription = `Save the container of this execution, with the packages it installed and the files it wrote, as a snapshot of this name
+once the code succeeded (up to 64 letters, digits, '.', '_' or '-'). A snapshot of the same name is replaced.
+Sessions run their later code in it after calling restore-snapshot.`
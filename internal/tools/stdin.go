@@ -0,0 +1,12 @@
+// Package tools provides MCP tool implementations for executing code
+// with the shared parameter piping the output of a previous execution to stdin.
+package tools
+
+// StdinFromArgument names the argument selecting a previous execution whose
+// output the code reads on stdin. The server resolves it from the execution
+// history, see executor.WithStdin.
+const StdinFromArgument = "stdin_from"
+
+const stdinFromDescription = `ID of a previous successful execution, in any language, whose output is passed to this code on stdin
+(e.g. JSON printed by a bash download, parsed by Python from sys.stdin), so intermediate data need not be sent again.
+The ID is in the execution:// link of its result; executions are kept in the server's recent history only.`
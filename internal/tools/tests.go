@@ -0,0 +1,141 @@
+// Package tools provides MCP tool implementations for executing code
+// with the execute-tests tool running the test suites of Python, Go and
+// TypeScript code.
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/logger"
+	"github.com/ylchen07/mcp-executor/internal/testrun"
+)
+
+// TestsTool runs test suites with the test runner of their language, using
+// the executors of the execute tools.
+type TestsTool struct {
+	executors map[string]executor.Executor // By language
+}
+
+func NewTestsTool(executors map[string]executor.Executor) *TestsTool {
+	return &TestsTool{
+		executors: executors,
+	}
+}
+
+// languages returns the languages of the tool: those with a test runner and an executor.
+func (t *TestsTool) languages() []string {
+	var languages []string
+	for _, language := range testrun.Languages() {
+		if t.executors[language] != nil {
+			languages = append(languages, language)
+		}
+	}
+	return languages
+}
+
+func (t *TestsTool) CreateTool() mcp.Tool {
+	description := `Run unit tests with pytest (Python), go test (Go) or vitest (TypeScript) and get structured results.
+Pass the source and test files keyed by their relative path; they are written to a fresh directory and the runner collects the tests there.
+The result reports the passed, failed, skipped and errored test counts with the message and details of each failure, followed by the output of the runner.
+pytest and vitest are installed with the packages where the execution mode installs dependencies; otherwise they must be available on the host.
+Set coverage to also get the statement coverage of each file, measured with coverage.py, go test -cover or c8; the report of the coverage tool is attached as a coverage.md artifact.`
+
+	return mcp.NewTool(
+		"execute-tests",
+		mcp.WithDescription(description),
+		mcp.WithString(
+			"language",
+			mcp.Description("Language of the files, selecting the test runner"),
+			mcp.Enum(t.languages()...),
+			mcp.Required(),
+		),
+		mcp.WithObject(
+			"files",
+			mcp.Description(`Source and test files keyed by relative path, e.g. {"calc.py": "...", "test_calc.py": "..."}.
+Go files need no go.mod: a module named "tests" is created when none is given.`),
+			mcp.AdditionalProperties(map[string]any{"type": "string"}),
+			mcp.Required(),
+		),
+		WithDependencies(
+			"packages",
+			mcp.Description("JSON array or comma-separated list of packages the code under test needs, installed as by the execute tool of the language"),
+		),
+		mcp.WithBoolean(
+			"coverage",
+			mcp.Description("Also measure the statement coverage of each file (coverage.py, go test -cover or c8)"),
+		),
+		WithEnv(envDescription("tests")),
+		WithTimeLocale(),
+		mcp.WithBoolean(
+			"deterministic",
+			mcp.Description(deterministicDescription),
+		),
+		mcp.WithNumber(
+			"timeout",
+			mcp.Description(timeoutDescription),
+		),
+		mcp.WithString(
+			"workspace",
+			mcp.Description(workspaceDescription),
+		),
+		mcp.WithString(
+			"priority",
+			mcp.Description(priorityDescription),
+			mcp.Enum("interactive", "batch"),
+		),
+	)
+}
+
+func (t *TestsTool) HandleExecution(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	logger.Debug("Tests tool execution requested")
+
+	language, err := request.RequireString("language")
+	if err != nil || !slices.Contains(t.languages(), language) {
+		logger.Debug("Tests tool execution failed: invalid language argument")
+		return ErrorResult(executor.ErrorPolicyViolation, fmt.Sprintf("Missing or invalid language argument (expected one of %v)", t.languages())), nil
+	}
+	files, err := parseStringObject(request, "files", "file paths to contents")
+	if err != nil {
+		logger.Debug("Tests tool execution failed: %v", err)
+		return ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+	}
+	coverage := request.GetBool("coverage", false)
+	program, err := testrun.Program(language, files, coverage)
+	if err != nil {
+		logger.Debug("Tests tool execution failed: %v", err)
+		return ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+	}
+
+	args, err := parseExecutionArgs(request, argumentSet{dependencies: "packages", timeLocale: true, deterministic: true})
+	if err != nil {
+		logger.Debug("Tests tool execution failed: %v", err)
+		return ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+	}
+	framework := testrun.Frameworks[language]
+	dependencies := slices.Clone(framework.Dependencies)
+	if coverage {
+		dependencies = append(dependencies, framework.CoverageDependencies...)
+	}
+	dependencies = append(dependencies, args.dependencies...)
+
+	output, err := t.executors[language].Execute(ctx, program, dependencies, args.env, args.options...)
+	if err != nil {
+		logger.Debug("Tests execution failed: %v", err)
+		return executionErrorResult(err), nil
+	}
+	report, runnerOutput, err := testrun.Parse(language, output)
+	if err != nil {
+		logger.Debug("Tests execution failed: %v", err)
+		return executionErrorResult(executor.NewExecutionError(executor.ErrorRuntime, "%v: %s", err, output)), nil
+	}
+
+	logger.Debug("Tests execution completed: %s", report.Summary())
+	return mcp.NewToolResultStructured(report, report.Summary()+"\n\n"+runnerOutput), nil
+}
@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+	"github.com/ylchen07/mcp-executor/internal/testrun"
+)
+
+func TestTestsTool_HandleExecution(t *testing.T) {
+	var gotDependencies []string
+	mockExec := &mockExecutor{
+		executeFunc: func(ctx context.Context, code string, dependencies []string, envVars map[string]string) (string, error) {
+			gotDependencies = dependencies
+			return "1 passed\n" + testrun.ReportMarker + `<testsuite><testcase classname="test_calc" name="test_add"/></testsuite>`, nil
+		},
+	}
+	tool := NewTestsTool(map[string]executor.Executor{"python": mockExec})
+
+	tests := []struct {
+		name             string
+		arguments        map[string]any
+		wantCode         string
+		wantDependencies []string
+	}{
+		{"passed", map[string]any{"language": "python", "files": map[string]any{"test_calc.py": "def test_add(): pass"}, "packages": "requests"}, "", []string{"pytest", "requests"}},
+		{"coverage", map[string]any{"language": "python", "files": map[string]any{"test_calc.py": "def test_add(): pass"}, "coverage": true}, "", []string{"pytest", "coverage"}},
+		{"language without executor", map[string]any{"language": "go", "files": map[string]any{"calc_test.go": ""}}, executor.ErrorPolicyViolation, nil},
+		{"missing files", map[string]any{"language": "python"}, executor.ErrorPolicyViolation, nil},
+		{"invalid path", map[string]any{"language": "python", "files": map[string]any{"../test_calc.py": ""}}, executor.ErrorPolicyViolation, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.HandleExecution(context.Background(), mcp.CallToolRequest{
+				Params: mcp.CallToolParams{Name: "execute-tests", Arguments: tt.arguments},
+			})
+			if err != nil {
+				t.Fatalf("HandleExecution() returned error: %v", err)
+			}
+			if tt.wantCode != "" {
+				if !result.IsError || !reflect.DeepEqual(result.Meta.AdditionalFields[errorMetaKey], map[string]any{"code": tt.wantCode}) {
+					t.Errorf("Result = %+v, want a %s error", result, tt.wantCode)
+				}
+				return
+			}
+			report, ok := result.StructuredContent.(testrun.Report)
+			if result.IsError || !ok || report.Passed != 1 || report.Total != 1 {
+				t.Errorf("Result = %+v, want a report of one passed test", result)
+			}
+			if !reflect.DeepEqual(gotDependencies, tt.wantDependencies) {
+				t.Errorf("Dependencies = %v, want %v", gotDependencies, tt.wantDependencies)
+			}
+		})
+	}
+}
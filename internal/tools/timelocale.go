@@ -0,0 +1,62 @@
+// Package tools provides MCP tool implementations for executing code
+// with shared helpers for the time zone, locale and fake time parameters.
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+)
+
+const timezoneDescription = `Time zone of the execution, set as TZ: an IANA name such as 'UTC' or 'Europe/Berlin'.
+Defaults to the server's configured time zone.`
+
+const localeDescription = `Locale of the execution, set as LANG and LC_ALL, e.g. 'C.UTF-8' or 'de_DE.UTF-8'.
+The locale must be installed in the environment; C.UTF-8 always is.`
+
+const fakeTimeDescription = `Start the clock of the execution at this RFC 3339 time, e.g. '2024-02-29T23:59:50Z', using libfaketime,
+so date-sensitive code can be tested. Only available when the operator configured libfaketime; statically linked programs,
+such as Go binaries, keep the real time.`
+
+// WithTimeLocale adds the "timezone", "locale" and "fake_time" parameters.
+func WithTimeLocale() mcp.ToolOption {
+	parameters := []mcp.ToolOption{
+		mcp.WithString("timezone", mcp.Description(timezoneDescription)),
+		mcp.WithString("locale", mcp.Description(localeDescription)),
+		mcp.WithString("fake_time", mcp.Description(fakeTimeDescription)),
+	}
+	return func(tool *mcp.Tool) {
+		for _, parameter := range parameters {
+			parameter(tool)
+		}
+	}
+}
+
+// parseTimeLocale reads and validates the optional timezone, locale and
+// fake_time arguments.
+func parseTimeLocale(request mcp.CallToolRequest) ([]executor.Option, error) {
+	var options []executor.Option
+	if timezone := strings.TrimSpace(request.GetString("timezone", "")); timezone != "" {
+		if err := executor.CheckTimezone(timezone); err != nil {
+			return nil, err
+		}
+		options = append(options, executor.WithTimezone(timezone))
+	}
+	if locale := strings.TrimSpace(request.GetString("locale", "")); locale != "" {
+		if err := executor.CheckLocale(locale); err != nil {
+			return nil, err
+		}
+		options = append(options, executor.WithLocale(locale))
+	}
+	if value := strings.TrimSpace(request.GetString("fake_time", "")); value != "" {
+		fakeTime, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fake_time %q: expected an RFC 3339 time such as 2024-02-29T23:59:50Z", value)
+		}
+		options = append(options, executor.WithFakeTime(fakeTime))
+	}
+	return options, nil
+}
@@ -0,0 +1,22 @@
+// Package tools provides MCP tool implementations for executing code
+// with shared helpers for the per-call timeout parameter.
+package tools
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const timeoutDescription = `Maximum execution time in seconds. Defaults to the server's configured timeout
+and may not exceed the operator's maximum.`
+
+// parseTimeout reads the optional "timeout" argument (seconds) as a duration.
+func parseTimeout(request mcp.CallToolRequest) (time.Duration, error) {
+	seconds := request.GetFloat("timeout", 0)
+	if seconds < 0 {
+		return 0, fmt.Errorf("invalid timeout %v: must not be negative", seconds)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
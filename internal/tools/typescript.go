@@ -4,7 +4,6 @@ package tools
 
 import (
 	"context"
-	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/ylchen07/mcp-executor/internal/executor"
@@ -25,7 +24,7 @@ func (t *TypeScriptTool) CreateTool() mcp.Tool {
 	description := `Execute TypeScript code in an isolated Docker container with tsx runtime.
 External packages can be dynamically installed via npm. Use this tool when you need real-time information or require external npm packages.
 Only output printed to stdout or stderr is returned so ALWAYS use console.log() statements!
-Note: Code runs in ephemeral containers - packages and state do NOT persist between executions.`
+Note: Code runs in ephemeral containers - packages and state do NOT persist between executions, except files in a shared workspace.`
 
 	return mcp.NewTool(
 		"execute-typescript",
@@ -35,15 +34,66 @@ Note: Code runs in ephemeral containers - packages and state do NOT persist betw
 			mcp.Description("The TypeScript code to execute"),
 			mcp.Required(),
 		),
-		mcp.WithString(
+		WithDependencies(
 			"packages",
-			mcp.Description(`Comma-separated list of npm packages to install (e.g., 'axios,lodash,date-fns').
+			mcp.Description(`JSON array or comma-separated list of npm packages to install (e.g., 'axios,lodash,date-fns').
 Packages are installed automatically via npm before code execution.`),
 		),
+		WithEnv(envDescription("TypeScript code")),
+		WithTimeLocale(),
+		mcp.WithString(
+			"mounts",
+			mcp.Description(mountsDescription),
+		),
+		mcp.WithString(
+			"network",
+			mcp.Description(networkDescription),
+		),
+		mcp.WithString(
+			"snapshot",
+			mcp.Description(snapshotDescription),
+		),
+		mcp.WithNumber(
+			"timeout",
+			mcp.Description(timeoutDescription),
+		),
+		mcp.WithString(
+			"workspace",
+			mcp.Description(workspaceDescription),
+		),
 		mcp.WithString(
-			"env",
-			mcp.Description(`Comma-separated list of environment variables in KEY=VALUE format (e.g., 'API_KEY=secret,DEBUG=true').
-These will be available to your TypeScript code.`),
+			StdinFromArgument,
+			mcp.Description(stdinFromDescription),
+		),
+		mcp.WithString(
+			"priority",
+			mcp.Description(priorityDescription),
+			mcp.Enum("interactive", "batch"),
+		),
+		mcp.WithBoolean(
+			"parse_output",
+			mcp.Description(parseOutputDescription),
+		),
+		mcp.WithBoolean(
+			"check_only",
+			mcp.Description(checkOnlyDescription),
+		),
+		mcp.WithBoolean(
+			"deterministic",
+			mcp.Description(deterministicDescription),
+		),
+		mcp.WithString(
+			"runtime_version",
+			mcp.Description(runtimeVersionDescription),
+		),
+		mcp.WithString(
+			"profile",
+			mcp.Description(profileDescription),
+		),
+		mcp.WithString(
+			"dependency_file",
+			mcp.Description(dependencyFileDescription("package.json")+`
+The code then runs as index.ts next to it, so settings such as "type": "module" apply.`),
 		),
 	)
 }
@@ -57,43 +107,36 @@ func (t *TypeScriptTool) HandleExecution(
 	code, err := request.RequireString("code")
 	if err != nil {
 		logger.Debug("TypeScript tool execution failed: missing code argument")
-		return mcp.NewToolResultError("Missing or invalid code argument"), nil
+		return ErrorResult(executor.ErrorPolicyViolation, "Missing or invalid code argument"), nil
 	}
 
-	var packages []string
-	if packagesStr := request.GetString("packages", ""); packagesStr != "" {
-		packages = strings.Split(packagesStr, ",")
-		logger.Debug("TypeScript packages requested: %v", packages)
+	args, err := parseExecutionArgs(request, argumentSet{dependencies: "packages", container: true, runtimeVersion: true, dependencyFile: true, timeLocale: true, deterministic: true})
+	if err != nil {
+		logger.Debug("TypeScript tool execution failed: %v", err)
+		return ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
 	}
-
-	// Parse environment variables
-	envVars := make(map[string]string)
-	if envStr := request.GetString("env", ""); envStr != "" {
-		envPairs := strings.SplitSeq(envStr, ",")
-		for pair := range envPairs {
-			pair = strings.TrimSpace(pair)
-			if equalIndex := strings.Index(pair, "="); equalIndex > 0 {
-				key := strings.TrimSpace(pair[:equalIndex])
-				value := strings.TrimSpace(pair[equalIndex+1:])
-				envVars[key] = value
-			}
-		}
-		logger.Debug("TypeScript environment variables: %v", envVars)
+	if len(args.dependencies) > 0 {
+		logger.Debug("TypeScript packages requested: %v", args.dependencies)
+	}
+	if len(args.env) > 0 {
+		logger.Debug("TypeScript environment variables: %v", args.env)
 	}
 
-	output, err := t.executor.Execute(ctx, code, packages, envVars)
+	output, err := t.executor.Execute(ctx, code, args.dependencies, args.env, args.options...)
 	if err != nil {
 		logger.Debug("TypeScript execution failed: %v", err)
-		return mcp.NewToolResultError(err.Error()), nil
+		return executionErrorResult(err), nil
 	}
 
 	logger.Debug("TypeScript execution completed successfully")
-	return mcp.NewToolResultText(output), nil
+	return executionResult(request, output), nil
 }
 
-// SubprocessTypeScriptTool executes TypeScript code on the host system without package installation support
+// SubprocessTypeScriptTool executes TypeScript code on the host system, with packages only
+// when its executor provides them in a throwaway environment
 type SubprocessTypeScriptTool struct {
 	executor executor.Executor
+	packages bool
 }
 
 func NewSubprocessTypeScriptTool(exec executor.Executor) *SubprocessTypeScriptTool {
@@ -102,26 +145,73 @@ func NewSubprocessTypeScriptTool(exec executor.Executor) *SubprocessTypeScriptTo
 	}
 }
 
+// NewSubprocessTypeScriptToolWithPackages returns a subprocess TypeScript tool accepting packages, for
+// executors providing them in throwaway environments such as nix-shell.
+func NewSubprocessTypeScriptToolWithPackages(exec executor.Executor) *SubprocessTypeScriptTool {
+	return &SubprocessTypeScriptTool{
+		executor: exec,
+		packages: true,
+	}
+}
+
 func (t *SubprocessTypeScriptTool) CreateTool() mcp.Tool {
 	description := `Execute TypeScript code directly on the host system using ts-node or tsx. Only standard library and pre-installed packages are available.
 Use this tool when you need real-time information and don't require external dependencies.
 Only output printed to stdout or stderr is returned so ALWAYS use console.log() statements!
 Note: Code runs on the host system with user permissions. Requires ts-node or tsx to be installed.`
 
-	return mcp.NewTool(
-		"execute-typescript",
+	options := []mcp.ToolOption{
 		mcp.WithDescription(description),
 		mcp.WithString(
 			"code",
 			mcp.Description("The TypeScript code to execute"),
 			mcp.Required(),
 		),
+		WithEnv(envDescription("TypeScript code")),
+		WithTimeLocale(),
+		mcp.WithNumber(
+			"timeout",
+			mcp.Description(timeoutDescription),
+		),
 		mcp.WithString(
-			"env",
-			mcp.Description(`Comma-separated list of environment variables in KEY=VALUE format (e.g., 'API_KEY=secret,DEBUG=true').
-These will be available to your TypeScript code.`),
+			"workspace",
+			mcp.Description(workspaceDescription),
 		),
-	)
+		mcp.WithString(
+			StdinFromArgument,
+			mcp.Description(stdinFromDescription),
+		),
+		mcp.WithString(
+			"priority",
+			mcp.Description(priorityDescription),
+			mcp.Enum("interactive", "batch"),
+		),
+		mcp.WithBoolean(
+			"parse_output",
+			mcp.Description(parseOutputDescription),
+		),
+		mcp.WithBoolean(
+			"check_only",
+			mcp.Description(checkOnlyDescription),
+		),
+		mcp.WithBoolean(
+			"deterministic",
+			mcp.Description(deterministicDescription),
+		),
+		mcp.WithString(
+			"runtime_version",
+			mcp.Description(runtimeVersionDescription),
+		),
+	}
+	if t.packages {
+		options = append(options, WithDependencies(
+			"packages",
+			mcp.Description(`JSON array or comma-separated list of Nix packages (e.g. 'nodePackages.prettier') provided in a throwaway nix-shell.
+They are available to your code and do NOT persist between executions.`),
+		))
+	}
+
+	return mcp.NewTool("execute-typescript", options...)
 }
 
 func (t *SubprocessTypeScriptTool) HandleExecution(
@@ -133,31 +223,32 @@ func (t *SubprocessTypeScriptTool) HandleExecution(
 	code, err := request.RequireString("code")
 	if err != nil {
 		logger.Debug("Subprocess TypeScript tool execution failed: missing code argument")
-		return mcp.NewToolResultError("Missing or invalid code argument"), nil
-	}
-
-	// Parse environment variables
-	envVars := make(map[string]string)
-	if envStr := request.GetString("env", ""); envStr != "" {
-		envPairs := strings.SplitSeq(envStr, ",")
-		for pair := range envPairs {
-			pair = strings.TrimSpace(pair)
-			if equalIndex := strings.Index(pair, "="); equalIndex > 0 {
-				key := strings.TrimSpace(pair[:equalIndex])
-				value := strings.TrimSpace(pair[equalIndex+1:])
-				envVars[key] = value
-			}
-		}
-		logger.Debug("Subprocess TypeScript environment variables: %v", envVars)
-	}
-
-	// No package installation for subprocess mode - pass empty slice
-	output, err := t.executor.Execute(ctx, code, nil, envVars)
+		return ErrorResult(executor.ErrorPolicyViolation, "Missing or invalid code argument"), nil
+	}
+
+	// Packages are only provided by executors with throwaway environments
+	set := argumentSet{runtimeVersion: true, timeLocale: true, deterministic: true}
+	if t.packages {
+		set.dependencies = "packages"
+	}
+	args, err := parseExecutionArgs(request, set)
+	if err != nil {
+		logger.Debug("Subprocess TypeScript tool execution failed: %v", err)
+		return ErrorResult(executor.ErrorPolicyViolation, err.Error()), nil
+	}
+	if len(args.dependencies) > 0 {
+		logger.Debug("Subprocess TypeScript packages requested: %v", args.dependencies)
+	}
+	if len(args.env) > 0 {
+		logger.Debug("Subprocess TypeScript environment variables: %v", args.env)
+	}
+
+	output, err := t.executor.Execute(ctx, code, args.dependencies, args.env, args.options...)
 	if err != nil {
 		logger.Debug("Subprocess TypeScript execution failed: %v", err)
-		return mcp.NewToolResultError(err.Error()), nil
+		return executionErrorResult(err), nil
 	}
 
 	logger.Debug("Subprocess TypeScript execution completed successfully")
-	return mcp.NewToolResultText(output), nil
+	return executionResult(request, output), nil
 }
@@ -0,0 +1,26 @@
+// Package tools provides MCP tool implementations for executing code
+// with shared helpers for the named workspace parameter.
+package tools
+
+import (
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ylchen07/mcp-executor/internal/executor"
+)
+
+const workspaceDescription = `Name of a workspace directory shared by the executions of this session that use the same name, in any language
+(e.g. 'pipeline': a bash download read by a Python analysis). Its path is in $MCP_WORKSPACE (/workspace in Docker mode);
+files persist until the session ends. Omit for a fresh, empty workspace.`
+
+// parseWorkspace reads and validates the optional "workspace" argument.
+func parseWorkspace(request mcp.CallToolRequest) (string, error) {
+	name := strings.TrimSpace(request.GetString("workspace", ""))
+	if name == "" {
+		return "", nil
+	}
+	if err := executor.CheckWorkspaceName(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}